@@ -22,8 +22,13 @@ func NewAnalyzeCmd() *cobra.Command {
 
 	// Add subcommands
 	cmd.AddCommand(NewAnalyzeContentCmd())
+	cmd.AddCommand(NewAnalyzeDateCmd())
 	cmd.AddCommand(NewAnalyzeDuplicatesCmd())
 	cmd.AddCommand(NewAnalyzeGroupCmd())
+	cmd.AddCommand(NewAnalyzeScreenshotCmd())
+	cmd.AddCommand(NewAnalyzeSimilarCmd())
+	cmd.AddCommand(NewAnalyzeTagsCmd())
+	cmd.AddCommand(NewAnalyzeVideoCmd())
 
 	return cmd
 }
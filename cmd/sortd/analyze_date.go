@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sortd/internal/analysis"
+
+	"github.com/spf13/cobra"
+)
+
+// NewAnalyzeDateCmd creates the command that shows the date documents are about
+func NewAnalyzeDateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "date [file...]",
+		Short: "Find the date a document is about",
+		Long: `Find the date a PDF, text file or (with settings.documents.ocr) scan is about,
+such as a bank statement's closing date. Workflows can file documents by it with
+{doc_date}, {doc_month} or {doc_year} in move, copy and rename targets.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			engine := analysis.NewWithConfig(cfg)
+			for _, path := range args {
+				info, err := engine.Analyze(path)
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("%s: %v", path, err)))
+					continue
+				}
+				if date, ok := info.Metadata["doc_date"]; ok {
+					fmt.Printf("  %s  %s\n", date, filepath.Base(path))
+				} else {
+					fmt.Printf("  %-10s  %s\n", "(no date)", filepath.Base(path))
+				}
+			}
+		},
+	}
+}
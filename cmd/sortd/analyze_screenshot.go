@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sortd/internal/screenshot"
+
+	"github.com/spf13/cobra"
+)
+
+// NewAnalyzeScreenshotCmd creates the command that shows how screenshots would be filed
+func NewAnalyzeScreenshotCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "screenshot [file...]",
+		Short: "Show whether files are screenshots and where they would be filed",
+		Long: `Show whether each file is a screenshot, by its name or PNG metadata, and the
+{date}-{app or keyword} name and month folder settings.screenshots files it
+under. With settings.screenshots.ocr, screenshots whose name and metadata don't
+name an app get the most frequent word tesseract reads in them.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ocr := cfg != nil && cfg.Settings.Screenshots.OCR
+			for _, path := range args {
+				info, ok := screenshot.Detect(path, ocr)
+				if !ok {
+					fmt.Printf("  %s  not a screenshot\n", filepath.Base(path))
+					continue
+				}
+				fmt.Printf("  %s -> %s (by %s)\n", filepath.Base(path), screenshot.Destination(cfg, info, nil), info.By)
+			}
+			if !screenshot.Enabled(cfg) {
+				fmt.Println(infoText("Screenshot filing is off; turn it on with settings.screenshots.enabled"))
+			}
+		},
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"sortd/internal/embeddings"
+
+	"github.com/spf13/cobra"
+)
+
+// NewAnalyzeSimilarCmd creates the command that finds files similar in meaning to a file
+func NewAnalyzeSimilarCmd() *cobra.Command {
+	var dirs []string
+	var limit int
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "similar [file]",
+		Short: "Find files with similar content using an embedding model",
+		Long: `Embed the text of a file with the model configured under settings.embeddings
+and list the indexed files closest in meaning. Directories given with --in are
+indexed first; vectors are cached in a SQLite database and only recomputed for
+files that changed.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if cfg == nil {
+				fmt.Println(errorText("Configuration not loaded. Cannot find embedding settings."))
+				return
+			}
+
+			provider, err := embeddings.NewProvider(cfg.Settings.Embeddings)
+			if err != nil {
+				fmt.Println(errorText(err.Error()))
+				return
+			}
+
+			index, err := embeddings.OpenIndex(embeddings.DefaultIndexPath(cfg))
+			if err != nil {
+				fmt.Println(errorText(err.Error()))
+				return
+			}
+			defer index.Close()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			indexer := embeddings.NewIndexer(provider, index)
+			if len(dirs) == 0 {
+				dirs = []string{filepath.Dir(args[0])}
+			}
+			for _, dir := range dirs {
+				result, err := indexer.IndexDirectory(ctx, dir)
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error indexing %s: %v", dir, err)))
+					return
+				}
+				if !jsonOutput && result.Embedded > 0 {
+					fmt.Println(infoText(fmt.Sprintf("Indexed %d new or changed files in %s", result.Embedded, dir)))
+				}
+			}
+
+			matches, err := indexer.FindSimilar(ctx, args[0], limit)
+			if err != nil {
+				fmt.Println(errorText(err.Error()))
+				return
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(matches, "", "  ")
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error encoding results: %v", err)))
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			fmt.Println(primaryText(fmt.Sprintf("🔎 Files like %s", filepath.Base(args[0]))))
+			if len(matches) == 0 {
+				fmt.Println(infoText("No other files are indexed yet; use --in to index a directory"))
+				return
+			}
+			for _, match := range matches {
+				fmt.Printf("  %5.1f%%  %s\n", match.Score*100, match.Path)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&dirs, "in", nil, "Directories to index and search (default: the file's directory)")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "Maximum number of results")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output results as JSON")
+
+	return cmd
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sortd/internal/analysis"
+	"sortd/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// imageTags is the tagging result for one image
+type imageTags struct {
+	Path string   `json:"path"`
+	Tags []string `json:"tags"`
+}
+
+// NewAnalyzeTagsCmd creates the command that tags images and searches them by tag
+func NewAnalyzeTagsCmd() *cobra.Command {
+	var tag string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "tags [directory]",
+		Short: "Tag images by content and search them by tag",
+		Long: `Tag the images in a directory with what they show, such as "screenshot" or
+"photo". When settings.image_tagging.endpoint points at a classifier running on
+this machine, its labels ("receipt", "person", "landscape", ...) are added too.
+Images are never sent anywhere but a loopback address.
+
+Use --tag to list only the images with a given tag.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			var settings config.ImageTaggingSettings
+			if cfg != nil {
+				settings = cfg.Settings.ImageTagging
+			}
+			tagger, err := analysis.NewImageTagger(settings)
+			if err != nil {
+				fmt.Println(errorText(err.Error()))
+				return
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error reading directory: %v", err)))
+				return
+			}
+
+			results := []imageTags{}
+			for _, entry := range entries {
+				if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+					continue
+				}
+				path := filepath.Join(dir, entry.Name())
+				if !isImageFile(path) {
+					continue
+				}
+
+				tags, err := tagger.TagImage(path)
+				if err != nil && len(tags) == 0 {
+					fmt.Fprintln(os.Stderr, warningText(fmt.Sprintf("Could not tag %s: %v", entry.Name(), err)))
+					continue
+				}
+				if tag != "" && !hasTag(tags, tag) {
+					continue
+				}
+				results = append(results, imageTags{Path: path, Tags: tags})
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error encoding results: %v", err)))
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			if len(results) == 0 {
+				fmt.Println(infoText("No matching images found"))
+				return
+			}
+			fmt.Println(primaryText(fmt.Sprintf("🏷️  Image tags in %s", dir)))
+			for _, result := range results {
+				tags := strings.Join(result.Tags, ", ")
+				if tags == "" {
+					tags = "(none)"
+				}
+				fmt.Printf("  %s: %s\n", filepath.Base(result.Path), tags)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&tag, "tag", "t", "", "Only list images with this tag")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output results as JSON")
+
+	return cmd
+}
+
+// isImageFile sniffs the start of a file for an image content type
+func isImageFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, _ := f.Read(buf)
+	return strings.HasPrefix(http.DetectContentType(buf[:n]), "image/")
+}
+
+// hasTag reports whether tags contains tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
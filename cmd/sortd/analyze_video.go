@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sortd/internal/analysis"
+
+	"github.com/spf13/cobra"
+)
+
+// NewAnalyzeVideoCmd creates the command that shows the metadata of videos
+func NewAnalyzeVideoCmd() *cobra.Command {
+	var thumbnail bool
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "video [file...]",
+		Short: "Show video duration, resolution and codecs",
+		Long: `Read video metadata with ffprobe. The values shown can be used in workflow
+conditions (type: metadata, field: resolution) and in move, copy and rename
+targets as placeholders such as {resolution} or {duration_bucket}.
+
+With --thumbnail a preview frame is also saved with ffmpeg.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			thumbnailDir := ""
+			if thumbnail {
+				if cfg == nil || cfg.Directories.Default == "" {
+					fmt.Println(errorText("A default directory must be configured to store thumbnails"))
+					return
+				}
+				thumbnailDir = analysis.DefaultThumbnailDir(cfg.Directories.Default)
+			}
+
+			results := make(map[string]map[string]string)
+			for _, path := range args {
+				video, err := analysis.ProbeVideo(path)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, errorText(err.Error()))
+					continue
+				}
+				metadata := video.Metadata()
+				if thumbnailDir != "" {
+					if thumb, err := analysis.GenerateThumbnail(path, thumbnailDir, video); err != nil {
+						fmt.Fprintln(os.Stderr, warningText(err.Error()))
+					} else {
+						metadata["thumbnail"] = thumb
+					}
+				}
+				results[path] = metadata
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error encoding results: %v", err)))
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			for _, path := range args {
+				metadata, ok := results[path]
+				if !ok {
+					continue
+				}
+				fmt.Println(primaryText(fmt.Sprintf("🎬 %s", filepath.Base(path))))
+				keys := make([]string, 0, len(metadata))
+				for key := range metadata {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					fmt.Printf("  %-16s %s\n", key, metadata[key])
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&thumbnail, "thumbnail", "t", false, "Save a preview frame (needs ffmpeg)")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output results as JSON")
+
+	return cmd
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"sortd/internal/app"
+	"sortd/internal/audit"
+	"sortd/internal/units"
+
+	"github.com/spf13/cobra"
+)
+
+// auditListLimit is how many files each section of the text report lists
+const auditListLimit = 10
+
+// NewAuditCmd creates the audit command, which reports on a directory without
+// changing it
+func NewAuditCmd() *cobra.Command {
+	var (
+		recursive  bool
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit [DIRECTORY]",
+		Short: "Report what sortd would do to a directory, changing nothing",
+		Long: `Evaluate sortd on a directory, such as a shared or team drive, without
+giving it a chance to change anything: the report lists the moves your rules
+would make, the files no rule matches, sets of duplicate files, and moves whose
+destination is already taken.
+
+The audit runs in audit mode, in which the organize engine and workflows
+refuse to write. Set it for every sortd process, the watcher included, with
+
+  settings:
+    audit: true
+
+The watcher then logs what it would do with each new file instead of doing it,
+and unlike read_only, 'sortd daemon resume' can't switch it off.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil {
+				return fmt.Errorf("configuration not loaded")
+			}
+
+			dir, err := determineTargetPath(args, "")
+			if err != nil {
+				return err
+			}
+
+			// Audit mode for this run, whatever the config says
+			cfg.Settings.Audit = true
+			service := app.New(cfg)
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			report, err := audit.Run(ctx, service, dir, recursive)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			printAuditReport(report)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Include files in subdirectories")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the report as JSON")
+
+	return cmd
+}
+
+// printAuditReport prints an audit report for the terminal
+func printAuditReport(report *audit.Report) {
+	fmt.Println(primaryText(fmt.Sprintf("Audit of %s: %d files (nothing was changed)", report.Root, report.Files)))
+	if report.Truncated {
+		fmt.Println(warningText(" A listing limit left files out (settings.max_files, settings.max_depth)"))
+	}
+
+	fmt.Printf("\nWould move %d files\n", len(report.Moves))
+	destinations := report.Destinations()
+	dirs := make([]string, 0, len(destinations))
+	for dir := range destinations {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if destinations[dirs[i]] != destinations[dirs[j]] {
+			return destinations[dirs[i]] > destinations[dirs[j]]
+		}
+		return dirs[i] < dirs[j]
+	})
+	for _, dir := range dirs {
+		fmt.Printf("  %5d → %s\n", destinations[dir], dir)
+	}
+
+	fmt.Printf("\nNo rule matches %d files\n", len(report.Unmatched))
+	printAuditList(report.Unmatched)
+
+	fmt.Printf("\n%d sets of duplicates, %s in extra copies\n", len(report.Duplicates), units.FormatSize(report.DuplicateBytes()))
+	for i, duplicate := range report.Duplicates {
+		if i == auditListLimit {
+			fmt.Printf("  … and %d more sets\n", len(report.Duplicates)-auditListLimit)
+			break
+		}
+		fmt.Printf("  %d × %s: %s\n", len(duplicate.Paths), units.FormatSize(duplicate.Size), filepath.Base(duplicate.Paths[0]))
+	}
+
+	if len(report.Redundant) > 0 {
+		fmt.Printf("\n%d files already have an identical copy at their destination\n", len(report.Redundant))
+		printAuditList(auditSources(report.Redundant))
+	}
+	if len(report.Collisions) > 0 {
+		fmt.Printf("\n%d files would collide with a different file (collision: %s)\n", len(report.Collisions), cfg.Settings.Collision)
+		printAuditList(auditSources(report.Collisions))
+	}
+}
+
+// printAuditList prints up to auditListLimit paths
+func printAuditList(paths []string) {
+	for i, path := range paths {
+		if i == auditListLimit {
+			fmt.Printf("  … and %d more\n", len(paths)-auditListLimit)
+			return
+		}
+		fmt.Println("  " + path)
+	}
+}
+
+// auditSources returns the source of each move
+func auditSources(moves []app.Move) []string {
+	sources := make([]string, len(moves))
+	for i, move := range moves {
+		sources[i] = move.Source
+	}
+	return sources
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"sortd/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// NewBookmarkCmd creates the command for managing bookmarked directories
+func NewBookmarkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bookmark",
+		Short: "Manage bookmarked directories",
+		Long: `Bookmarks are named directories stored in the config file and shared with the
+GUI. Jump to one from your shell with: cd "$(sortd go <name>)"`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "add <name> [directory]",
+		Short: "Bookmark a directory (default: the current directory)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 2 {
+				dir = args[1]
+			}
+			if err := cfg.SetBookmark(args[0], dir); err != nil {
+				return err
+			}
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("error saving config: %w", err)
+			}
+			fmt.Println(successText(fmt.Sprintf("Bookmarked %s as %s", cfg.Bookmarks[args[0]], args[0])))
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List bookmarks",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			names := cfg.BookmarkNames()
+			if len(names) == 0 {
+				fmt.Println(infoText("No bookmarks yet; add one with 'sortd bookmark add <name>'"))
+				return
+			}
+			for _, name := range names {
+				fmt.Printf("%-16s %s\n", primaryText(name), cfg.Bookmarks[name])
+			}
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a bookmark",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cfg.RemoveBookmark(args[0]) {
+				return fmt.Errorf("no bookmark named %q", args[0])
+			}
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("error saving config: %w", err)
+			}
+			fmt.Println(successText(fmt.Sprintf("Removed bookmark %s", args[0])))
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// NewGoCmd creates the command that prints a bookmark's directory, for shells
+func NewGoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "go <bookmark>",
+		Short: "Print a bookmarked directory",
+		Long: `Print the directory a bookmark points at and nothing else, so a shell can
+change into it: cd "$(sortd go docs)"`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			loaded := cfg
+			if loaded == nil {
+				// Completion runs without the root command's config loading
+				var err error
+				if loaded, err = config.LoadConfig(); err != nil {
+					return nil, cobra.ShellCompDirectiveNoFileComp
+				}
+			}
+			return loaded.BookmarkNames(), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := cfg.ResolveBookmark(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("bookmark %s: %w", args[0], err)
+			}
+			fmt.Println(path)
+			return nil
+		},
+	}
+}
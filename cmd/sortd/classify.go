@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"sortd/internal/classify"
+	"sortd/pkg/types"
+
+	"github.com/spf13/cobra"
+)
+
+// NewClassifyCmd creates the classify command
+func NewClassifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "classify",
+		Short: "Work with file classifications",
+		Long: `File classifications describe kinds of files by weighted criteria: keywords,
+MIME type prefixes, file name patterns and size ranges. They are defined under
+"classifications" in the config file or in a separate YAML or JSON file.`,
+	}
+
+	cmd.AddCommand(NewClassifyTestCmd())
+	return cmd
+}
+
+// NewClassifyTestCmd creates the command that shows how a file scores against each classification
+func NewClassifyTestCmd() *cobra.Command {
+	var criteriaFile string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "test [file]",
+		Short: "Show how a file scores against each classification",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var classifications []types.FileClassification
+			switch {
+			case criteriaFile != "":
+				loaded, err := classify.LoadFile(criteriaFile)
+				if err != nil {
+					fmt.Println(errorText(err.Error()))
+					return
+				}
+				classifications = loaded
+			case cfg != nil:
+				classifications = cfg.Classifications
+			}
+			if len(classifications) == 0 {
+				fmt.Println(warningText("No classifications defined; add some under \"classifications\" or pass --criteria"))
+				return
+			}
+
+			evaluator, err := classify.New(classifications)
+			if err != nil {
+				fmt.Println(errorText(err.Error()))
+				return
+			}
+			results, err := evaluator.Evaluate(args[0])
+			if err != nil {
+				fmt.Println(errorText(err.Error()))
+				return
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error encoding results: %v", err)))
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			fmt.Println(primaryText(fmt.Sprintf("🧪 Classifying %s", filepath.Base(args[0]))))
+			for _, result := range results {
+				verdict := infoText("no match")
+				if result.Matched {
+					verdict = successText("MATCH")
+				}
+				fmt.Printf("\n%s  %.0f%% (threshold %.0f%%)  %s\n",
+					emphasisText(result.Classification), result.Score*100, result.Threshold*100, verdict)
+				for _, c := range result.Contributions {
+					mark, weight := "✗", 0.0
+					if c.Matched {
+						mark, weight = "✓", c.Weight
+					}
+					fmt.Printf("  %s %-17s %-30q %+5.1f of %+.1f\n", mark, c.Criterion, c.Value, weight, c.Weight)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&criteriaFile, "criteria", "c", "", "YAML or JSON file of classifications to test instead of the configured ones")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output results as JSON")
+
+	return cmd
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sortd/internal/app"
 	"sortd/internal/config"
 	"sortd/internal/organize"
 	"sortd/internal/watch"
@@ -19,21 +20,6 @@ var (
 	cfg     *config.Config
 )
 
-// findDestination finds the destination pattern for a file
-func findDestination(engine *organize.Engine, filename string) (string, bool) {
-	// This is a simplified implementation for matching rules
-	basename := filepath.Base(filename)
-
-	for _, rule := range cfg.Rules {
-		matched, err := filepath.Match(rule.Pattern, basename)
-		if err == nil && matched {
-			return rule.Target, true
-		}
-	}
-
-	return "", false
-}
-
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:   "sortd",
@@ -212,52 +198,16 @@ var OrganizeCmd = &cobra.Command{
 			}
 		}
 
-		// Organize the files using our engine
-		var results []struct {
-			SourcePath      string
-			DestinationPath string
-			Moved           bool
-			Error           error
+		// Plan and move through the service the other frontends share
+		service := app.NewWithEngine(cfg, engine)
+		plan, err := service.PlanFiles(cmd.Context(), files)
+		if err != nil {
+			PrintError(fmt.Sprintf("Error planning organization: %v", err))
+			os.Exit(1)
 		}
-
-		// Organize each file individually for better control and reporting
-		for _, file := range files {
-			// Skip directories just to be safe
-			fileInfo, err := os.Stat(file)
-			if err != nil || fileInfo.IsDir() {
-				continue
-			}
-
-			// Find destination and apply rules
-			result := struct {
-				SourcePath      string
-				DestinationPath string
-				Moved           bool
-				Error           error
-			}{
-				SourcePath: file,
-			}
-
-			// Use findDestination helper to match rules
-			destPattern, found := findDestination(engine, file)
-			if found {
-				destPath := filepath.Join(destPattern, filepath.Base(file))
-				result.DestinationPath = destPath
-
-				// Move file (or simulate in dry run)
-				if dryRun {
-					result.Moved = false
-				} else {
-					err := engine.MoveFile(file, destPath)
-					if err != nil {
-						result.Error = err
-					} else {
-						result.Moved = true
-					}
-				}
-			}
-
-			results = append(results, result)
+		results, err := service.Execute(cmd.Context(), plan)
+		if err != nil {
+			PrintError(fmt.Sprintf("Organization interrupted: %v", err))
 		}
 
 		// Display results summary
@@ -271,7 +221,7 @@ var OrganizeCmd = &cobra.Command{
 		for _, result := range results {
 			if result.Error != nil {
 				errorCount++
-			} else if result.Moved {
+			} else if result.Moved || dryRun {
 				organized++
 			}
 		}
@@ -342,6 +292,10 @@ var WatchCmd = &cobra.Command{
 		// Set confirmation requirement if specified
 		daemon.SetRequireConfirmation(requireConfirm)
 
+		// Publish statistics and activity for 'sortd daemon stats' and 'sortd digest'
+		daemon.SetStatsFile(watch.StatsFilePath(cfg))
+		daemon.SetActivityFile(watch.ActivityFilePath(cfg))
+
 		// Add watch directories
 		for _, dir := range watchDirs {
 			if err := daemon.AddWatchDirectory(dir); err != nil {
@@ -434,13 +388,8 @@ var SetupCmd = &cobra.Command{
 			if RunGumConfirm("Would you like to configure watch mode?") {
 				cfg.WatchMode.Enabled = true
 
-				// Configure watch interval
-				intervalInput := RunGumInput("Check interval in seconds", "300")
-				if interval, err := strconv.Atoi(intervalInput); err == nil && interval > 0 {
-					// cfg.WatchMode.Interval = interval // REMOVED
-				} else {
-					// cfg.WatchMode.Interval = 300 // Default to 5 minutes // REMOVED
-				}
+				// Configure how the watched directories are followed
+				cfg.WatchMode.WatchSettings = AskWatchSettings()
 
 				// Add watch directories
 				PrintInfo("Add directories to watch")
@@ -462,14 +411,14 @@ var SetupCmd = &cobra.Command{
 						// Check if already in the list
 						isDuplicate := false
 						for _, dir := range cfg.Directories.Watch {
-							if dir == watchDir {
+							if dir.Path == watchDir {
 								isDuplicate = true
 								break
 							}
 						}
 
 						if !isDuplicate {
-							cfg.Directories.Watch = append(cfg.Directories.Watch, watchDir)
+							cfg.Directories.Watch = append(cfg.Directories.Watch, config.WatchDir{Path: watchDir})
 							PrintSuccess(fmt.Sprintf("Added %s to watch list", watchDir))
 						} else {
 							PrintWarning(fmt.Sprintf("%s is already in the watch list", watchDir))
@@ -517,10 +466,7 @@ var SetupCmd = &cobra.Command{
 
 						// Add rule
 						if pattern != "" && target != "" {
-							cfg.Rules = append(cfg.Rules, struct {
-								Pattern string `yaml:"pattern"`
-								Target  string `yaml:"target"`
-							}{
+							cfg.Rules = append(cfg.Rules, config.Rule{
 								Pattern: pattern,
 								Target:  target,
 							})
@@ -583,6 +529,33 @@ var GUICmd = &cobra.Command{
 	},
 }
 
+// AskWatchSettings asks how watched directories should be followed, asking
+// again until each answer is a valid whole number
+func AskWatchSettings() config.WatchSettings {
+	var settings config.WatchSettings
+	settings.IntervalSeconds = askNumber("Rescan interval in seconds, to catch missed changes (0 = never)", "300", 0)
+	settings.DebounceSeconds = askNumber("Seconds a file must stay unchanged before it is handled", "0", 0)
+	settings.BatchSize = askNumber("How many files to handle at once", strconv.Itoa(config.DefaultBatchSize), 1)
+	settings.Recursive = RunGumConfirm("Watch subdirectories too?")
+
+	for err := settings.Validate(); err != nil; err = settings.Validate() {
+		PrintWarning(err.Error())
+		settings.BatchSize = askNumber("How many files to handle at once", strconv.Itoa(config.DefaultBatchSize), 1)
+	}
+	return settings
+}
+
+// askNumber asks for a whole number no smaller than min
+func askNumber(prompt, defaultValue string, min int) int {
+	for {
+		input := RunGumInput(prompt, defaultValue)
+		if n, err := strconv.Atoi(strings.TrimSpace(input)); err == nil && n >= min {
+			return n
+		}
+		PrintWarning(fmt.Sprintf("Please enter a whole number of at least %d", min))
+	}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately
 func Execute() error {
 	// Add subcommands
@@ -592,7 +565,7 @@ func Execute() error {
 	RootCmd.AddCommand(GUICmd)
 
 	// Add global flags
-	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/sortd/config.yaml)")
+	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $SORTD_CONFIG or $XDG_CONFIG_HOME/sortd/config.yaml)")
 
 	// Add organize command flags
 	OrganizeCmd.Flags().BoolP("dry-run", "d", false, "Simulate operations without making changes")
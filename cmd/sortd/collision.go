@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sortd/internal/organize"
+	"sortd/internal/pending"
+)
+
+// canPrompt reports whether the user can be asked questions on this run
+func canPrompt() bool {
+	return !isNonInteractive() && os.Getenv("TESTMODE") != "true" && isTerminal(os.Stdin)
+}
+
+// askCollision answers collisions under the "ask" collision strategy. The user
+// is asked on the terminal; runs that can't ask, such as --non-interactive
+// ones, queue the move for 'sortd pending' instead of skipping it.
+func askCollision(src, dest string) (string, error) {
+	if !canPrompt() {
+		return queueCollision(src, dest)
+	}
+	return promptCollision(src, dest)
+}
+
+// promptCollision asks the user what to do about a taken destination, with gum
+// when it's installed. It fails rather than guess when there is no one to ask,
+// so approving a queued collision leaves it queued.
+func promptCollision(src, dest string) (string, error) {
+	if !canPrompt() {
+		return "", fmt.Errorf("%s already exists and there is no terminal to ask what to do", dest)
+	}
+
+	fmt.Println(warningText(fmt.Sprintf(" %s already exists", dest)))
+	var answer string
+	if _, err := exec.LookPath("gum"); err == nil {
+		answer = runGumChoose("Rename", "Skip", "Overwrite")
+	} else {
+		fmt.Printf(" Move %s anyway? [r]ename, [s]kip, [o]verwrite: ", filepath.Base(src))
+		fmt.Scanln(&answer)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "r", "rename":
+		return organize.CollisionRename, nil
+	case "o", "overwrite":
+		return organize.CollisionOverwrite, nil
+	default:
+		return organize.CollisionSkip, nil
+	}
+}
+
+// queueCollision stages a colliding move in the pending queue and skips it for now
+func queueCollision(src, dest string) (string, error) {
+	if cfg == nil {
+		return organize.CollisionSkip, nil
+	}
+
+	queue, err := pending.Open(pending.DefaultPath(cfg))
+	if err != nil {
+		return "", fmt.Errorf("failed to open pending queue: %w", err)
+	}
+	item, err := queue.Add(pending.Item{Path: src, Destination: dest, Reason: pending.ReasonCollision})
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Println(infoText(fmt.Sprintf(" %s is taken; queued as pending move %s ('sortd pending approve %s' to decide)", dest, item.ID, item.ID)))
+	return organize.CollisionSkip, nil
+}
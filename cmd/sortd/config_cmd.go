@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"sortd/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// NewConfigCmd creates the command for inspecting how configuration is resolved
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show where configuration comes from",
+		Long: `Configuration is layered: the config file, then SORTD_* environment
+variables, then --set flags. Any key listed by 'sortd config keys' can be
+overridden, e.g. SORTD_SETTINGS_DRY_RUN=false or --set settings.collision=skip.`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "keys",
+		Short: "List the keys that can be overridden and their environment variables",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, key := range config.Keys() {
+				fmt.Printf("%-40s %s\n", key, config.EnvName(key))
+			}
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "path",
+		Short: "Print the config file in use",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := config.ConfigPath()
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+			return nil
+		},
+	})
+
+	cmd.AddCommand(newConfigMigrateGlobsCmd())
+
+	return cmd
+}
+
+// newConfigMigrateGlobsCmd creates 'config migrate-globs'
+func newConfigMigrateGlobsCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate-globs",
+		Short: "Rewrite globs from older versions in the config file",
+		Long: `Older versions matched patterns with a simpler glob engine. Patterns are
+rewritten to keep their meaning whenever the config is loaded: [^abc] becomes
+[!abc], and braces without a comma, which used to be literal, are escaped.
+This command shows those rewrites and saves them to the config file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			migrations := cfg.GlobMigrations()
+			if len(migrations) == 0 {
+				fmt.Println(successText("✓ All globs are up to date"))
+				return nil
+			}
+
+			for _, m := range migrations {
+				fmt.Printf("%s: %q → %q\n", m.Field, m.Old, m.New)
+			}
+			if dryRun {
+				fmt.Println(infoText(fmt.Sprintf("Dry run: %d globs would be rewritten", len(migrations))))
+				return nil
+			}
+
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+			fmt.Println(successText(fmt.Sprintf("✓ Rewrote %d globs", len(migrations))))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the rewrites without saving them")
+	return cmd
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"time"
 
+	"sortd/internal/watch"
+
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +31,9 @@ func NewDaemonCmd() *cobra.Command {
 	cmd.AddCommand(newDaemonStopCmd())
 	cmd.AddCommand(newDaemonStatusCmd())
 	cmd.AddCommand(newDaemonRestartCmd())
+	cmd.AddCommand(newDaemonStatsCmd())
+	cmd.AddCommand(newDaemonPauseCmd())
+	cmd.AddCommand(newDaemonResumeCmd())
 
 	return cmd
 }
@@ -162,6 +168,121 @@ func newDaemonRestartCmd() *cobra.Command {
 	}
 }
 
+// newDaemonStatsCmd creates the 'daemon stats' command
+func newDaemonStatsCmd() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show per-directory watch statistics",
+		Long:  `Show events seen, files organized, skipped and failed, and last activity for each watched directory.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if cfg == nil {
+				fmt.Println(errorText("Configuration not loaded. Cannot read daemon statistics."))
+				return
+			}
+
+			stats, err := watch.LoadStats(cfg)
+			if err != nil {
+				if os.IsNotExist(err) {
+					fmt.Println(warningText("No statistics available yet"))
+					fmt.Println(infoText("Statistics are published once 'sortd watch' is running"))
+					return
+				}
+				fmt.Println(errorText(fmt.Sprintf("Error reading daemon statistics: %v", err)))
+				return
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(stats, "", "  ")
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error encoding statistics: %v", err)))
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			fmt.Println(primaryText("📊 Watch Statistics"))
+			for _, dir := range stats {
+				fmt.Println("")
+				fmt.Println(emphasisText(dir.Path))
+				fmt.Printf("  Events seen:     %d\n", dir.EventsSeen)
+				fmt.Printf("  Files organized: %d\n", dir.FilesOrganized)
+				fmt.Printf("  Skipped:         %d\n", dir.Skipped)
+				if dir.Unmatched > 0 {
+					fmt.Println("  Unmatched:       " + warningText(fmt.Sprintf("%d", dir.Unmatched)))
+				} else {
+					fmt.Printf("  Unmatched:       %d\n", dir.Unmatched)
+				}
+				if dir.Errors > 0 {
+					fmt.Println("  Errors:          " + errorText(fmt.Sprintf("%d", dir.Errors)))
+				} else {
+					fmt.Printf("  Errors:          %d\n", dir.Errors)
+				}
+				if dir.LastActivity.IsZero() {
+					fmt.Println("  Last activity:   never")
+				} else {
+					fmt.Printf("  Last activity:   %s\n", dir.LastActivity.Format(time.RFC1123))
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output statistics in JSON format")
+
+	return cmd
+}
+
+// newDaemonPauseCmd creates the 'daemon pause' command
+func newDaemonPauseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause",
+		Short: "Switch on read-only mode",
+		Long: `Switch on read-only mode for every sortd process using this configuration.
+Running daemons keep watching but leave every file where it is, and organize
+runs only show what they would do, until 'sortd daemon resume'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil {
+				return fmt.Errorf("configuration not loaded")
+			}
+			if err := watch.SetReadOnly(cfg, true); err != nil {
+				return err
+			}
+			fmt.Println(successText("Read-only mode on: sortd will not move any files"))
+			fmt.Println(infoText("Use 'sortd daemon resume' to allow changes again"))
+			return nil
+		},
+	}
+}
+
+// newDaemonResumeCmd creates the 'daemon resume' command
+func newDaemonResumeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume",
+		Short: "Switch off read-only mode",
+		Long:  `Switch off read-only mode set with 'sortd daemon pause'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil {
+				return fmt.Errorf("configuration not loaded")
+			}
+			if err := watch.SetReadOnly(cfg, false); err != nil {
+				return err
+			}
+			if cfg.Settings.ReadOnly {
+				fmt.Println(warningText("settings.read_only is set in the config file; sortd stays read-only until it is removed"))
+				return nil
+			}
+			if cfg.Settings.Audit {
+				fmt.Println(warningText("settings.audit is set in the config file; sortd only observes until it is removed"))
+				return nil
+			}
+			fmt.Println(successText("Read-only mode off"))
+			return nil
+		},
+	}
+}
+
 // showDaemonStatus displays the status of the daemon
 func showDaemonStatus() error {
 	// This is a simplified implementation - a production version would
@@ -180,6 +301,9 @@ func showDaemonStatus() error {
 		fmt.Println(warningText("Daemon status: Not running"))
 		fmt.Println(infoText("Use 'sortd daemon start' to start the daemon"))
 	}
+	if cfg != nil && watch.ReadOnly(cfg) {
+		fmt.Println(warningText("Read-only mode: on ('sortd daemon resume' to allow changes)"))
+	}
 
 	return nil
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"sortd/internal/crash"
+
+	"github.com/spf13/cobra"
+)
+
+// recoverCrash turns a panic in main into a diagnostic bundle and
+// instructions for reporting it, instead of a bare stack trace
+func recoverCrash() {
+	value := recover()
+	if value == nil {
+		return
+	}
+	path, err := crash.Capture("sortd "+commandLine(), value, cfg)
+	fmt.Fprintln(os.Stderr, errorText(crash.Instructions(value, path, err)))
+	os.Exit(exitCrashed)
+}
+
+// commandLine returns the command being run, e.g. "organize"; the bundle has
+// the full command line
+func commandLine() string {
+	for _, arg := range os.Args[1:] {
+		if len(arg) > 0 && arg[0] != '-' {
+			return arg
+		}
+	}
+	return ""
+}
+
+// NewDebugCmd creates the command with tools for bug reports
+func NewDebugCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Tools for reporting bugs",
+	}
+
+	cmd.AddCommand(newDebugBundleCmd())
+	return cmd
+}
+
+// newDebugBundleCmd creates 'debug bundle'
+func newDebugBundleCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Write a diagnostic bundle to attach to a bug report",
+		Long: `Write the same diagnostic bundle sortd leaves behind when it crashes: the
+version and platform, the latest log entries and the configuration with
+passwords, tokens and webhook URLs redacted. It is a plain text file; look it
+over before attaching it to a bug report.
+
+Bundles go to the crashes directory in the state directory unless --dir is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				var err error
+				if dir, err = crash.DefaultDir(); err != nil {
+					return fmt.Errorf("failed to find the state directory: %w", err)
+				}
+			}
+
+			path, err := crash.Write(dir, crash.Bundle{Reason: "requested with 'sortd debug bundle'", Config: cfg})
+			if err != nil {
+				return fmt.Errorf("failed to write the bundle: %w", err)
+			}
+			fmt.Println(successText("Diagnostic bundle written to " + path))
+			fmt.Println(infoText("Look it over, then attach it to a bug report at " + crash.IssuesURL))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "", "Directory to write the bundle to")
+	return cmd
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"sortd/internal/digest"
+	"sortd/internal/watch"
+
+	"github.com/spf13/cobra"
+)
+
+// NewDigestCmd creates the digest command
+func NewDigestCmd() *cobra.Command {
+	var frequency string
+	var send bool
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Summarise recent watch activity",
+		Long: `Summarise what the watch daemon organized over the last day or week:
+files organized, top destinations and failures. Use --send to deliver the
+digest through the channels configured under settings.digest.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if cfg == nil {
+				fmt.Println(errorText("Configuration not loaded. Cannot read activity."))
+				return
+			}
+
+			if frequency == "" {
+				frequency = cfg.Settings.Digest.Frequency
+			}
+			if frequency != "" && frequency != "daily" && frequency != "weekly" {
+				fmt.Println(errorText(fmt.Sprintf("Invalid period %q: use daily or weekly", frequency)))
+				return
+			}
+
+			until := time.Now()
+			since := until.Add(-digest.Period(frequency))
+
+			entries, err := watch.LoadActivity(cfg, since)
+			if err != nil && !os.IsNotExist(err) {
+				fmt.Println(errorText(fmt.Sprintf("Error reading activity: %v", err)))
+				return
+			}
+			d := digest.Build(entries, since, until)
+			d.Lingering = watch.Overdue(cfg, until)
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(d, "", "  ")
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error encoding digest: %v", err)))
+					return
+				}
+				fmt.Println(string(data))
+			} else {
+				fmt.Println(primaryText("📬 " + d.Subject()))
+				fmt.Println(d.Text())
+			}
+
+			if send {
+				if err := digest.Deliver(cfg.Settings.Digest, d); err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error delivering digest: %v", err)))
+					return
+				}
+				fmt.Println(successText("Digest delivered"))
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&frequency, "period", "p", "", "Period to summarise: daily or weekly (default from settings)")
+	cmd.Flags().BoolVar(&send, "send", false, "Deliver the digest through the configured channels")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output the digest in JSON format")
+
+	return cmd
+}
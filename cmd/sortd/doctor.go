@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"sortd/internal/config"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+	"sortd/pkg/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorCmd creates the command that checks the setup for problems the
+// daemon would run into
+func NewDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the configuration for problems before the daemon hits them",
+		Long: `Check that the configuration loads, that the watch directories exist, and that
+no rule or workflow sends files back into a watched directory where they would
+be picked up and moved again (a feedback loop). The watch daemon refuses to
+start over feedback loops unless watch_mode.allow_feedback_loops is set.
+
+Exits non-zero when a problem is found.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			problems := 0
+			report := func(ok bool, text string) {
+				if ok {
+					fmt.Println(successText("✓ " + text))
+					return
+				}
+				problems++
+				fmt.Println(errorText("✗ " + text))
+			}
+
+			path, _ := config.ConfigPath()
+			if cfgFile != "" {
+				path = cfgFile
+			}
+			if configLoadErr != nil {
+				report(false, fmt.Sprintf("Configuration %s: %v", path, configLoadErr))
+			} else {
+				report(true, "Configuration "+path+" loads")
+			}
+
+			dirs := cfg.WatchPaths()
+			if len(dirs) == 0 {
+				fmt.Println(infoText("No watch directories configured"))
+			}
+			for _, dir := range dirs {
+				info, err := os.Stat(dir)
+				switch {
+				case err != nil:
+					report(false, fmt.Sprintf("Watch directory %s: %v", dir, err))
+				case !info.IsDir():
+					report(false, fmt.Sprintf("Watch directory %s is not a directory", dir))
+				default:
+					report(true, "Watch directory "+dir+" exists")
+				}
+			}
+
+			loops := watch.FeedbackLoops(cfg, doctorWorkflows())
+			if len(loops) == 0 {
+				report(true, "No rule or workflow sends files back into a watched directory")
+			}
+			for _, loop := range loops {
+				if cfg.WatchMode.AllowFeedbackLoops {
+					fmt.Println(warningText("! Feedback loop, allowed by watch_mode.allow_feedback_loops: " + loop.String()))
+					continue
+				}
+				report(false, "Feedback loop: "+loop.String())
+			}
+
+			if problems > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d problem(s)", problems)
+			}
+			return nil
+		},
+	}
+}
+
+// doctorWorkflows loads the workflows the daemon would run, or none if they
+// can't be read
+func doctorWorkflows() []types.Workflow {
+	dir, err := workflow.DefaultDir()
+	if err != nil {
+		return nil
+	}
+	manager, err := workflow.NewManager(dir)
+	if err != nil {
+		fmt.Println(warningText(fmt.Sprintf("! Workflows in %s couldn't be loaded: %v", dir, err)))
+		return nil
+	}
+	return manager.GetWorkflows()
+}
+
+// warnFeedbackLoops points at 'sortd doctor' when rules send files back into
+// watched directories, which keeps the daemon from starting
+func warnFeedbackLoops(cmd *cobra.Command) {
+	if len(cfg.WatchPaths()) == 0 || cfg.WatchMode.AllowFeedbackLoops {
+		return
+	}
+	if loops := watch.FeedbackLoops(cfg, nil); len(loops) > 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), warningText(fmt.Sprintf("Warning: %d rule(s) send files back into watched directories, e.g. %s", len(loops), loops[0])))
+		fmt.Fprintln(cmd.ErrOrStderr(), infoText("The watch daemon won't start until they're fixed or allowed; run 'sortd doctor' for details."))
+	}
+}
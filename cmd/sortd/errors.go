@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	serr "sortd/internal/errors"
+)
+
+// Formats for --errors
+const (
+	errorsText = "text"
+	errorsJSON = "json"
+)
+
+// errorsFormat is how failures are reported: as readable text, or with
+// --errors json as one JSON record per line on stderr for scripts
+var errorsFormat = errorsText
+
+// errorRecord is a line of --errors json output
+type errorRecord struct {
+	Command string `json:"command,omitempty"`
+	serr.Details
+}
+
+// reportError reports the error a command failed with
+func reportError(command string, err error) {
+	if errorsFormat == errorsJSON {
+		writeErrorRecord(command, err)
+		return
+	}
+
+	// Print to both stderr and stdout to ensure tests can capture it
+	errMsg := fmt.Sprintf("Error: %s", err)
+	fmt.Fprintln(os.Stderr, errMsg)
+	fmt.Println(errMsg)
+	printErrorDetails(serr.Describe(err))
+}
+
+// reportFileError reports a failure for one file of many, for commands that
+// carry on with the rest
+func reportFileError(command string, err error) {
+	if errorsFormat == errorsJSON {
+		writeErrorRecord(command, err)
+		return
+	}
+
+	d := serr.Describe(err)
+	if d.Path != "" {
+		fmt.Println(errorText(fmt.Sprintf(" ✗ %s: %s", d.Path, d.Message)))
+	} else {
+		fmt.Println(errorText(" ✗ " + d.Error))
+	}
+	printErrorDetails(d)
+}
+
+// printErrorDetails prints the likely cause and the fix, where known
+func printErrorDetails(d serr.Details) {
+	if d.Cause != "" {
+		fmt.Printf("   Cause: %s\n", d.Cause)
+	}
+	if d.Suggestion != "" {
+		fmt.Println(infoText("   Fix:   " + d.Suggestion))
+	}
+}
+
+// writeErrorRecord writes err as a JSON line to stderr
+func writeErrorRecord(command string, err error) {
+	data, jsonErr := json.Marshal(errorRecord{Command: command, Details: serr.Describe(err)})
+	if jsonErr != nil {
+		fmt.Fprintf(os.Stderr, "{\"kind\":\"unknown\",\"error\":%q}\n", err.Error())
+		return
+	}
+	fmt.Fprintln(os.Stderr, string(data))
+}
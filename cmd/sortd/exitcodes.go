@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"sortd/internal/watch"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of organize, watch and workflow commands, for wrappers and cron
+// jobs. Anything else that goes wrong exits with exitFailed.
+const (
+	exitOK          = 0
+	exitFailed      = 1 // The command couldn't run, e.g. a missing path
+	exitPartial     = 2 // It ran, but some files failed
+	exitConfig      = 3 // The configuration couldn't be read or is invalid
+	exitNoneMatched = 4 // It ran on files, but none matched a rule or workflow
+	exitCrashed     = 5 // sortd crashed and wrote a diagnostic bundle
+)
+
+// exitStatuses name the exit codes in the summary line
+var exitStatuses = map[int]string{
+	exitOK:          "ok",
+	exitFailed:      "failed",
+	exitPartial:     "partial",
+	exitConfig:      "config_error",
+	exitNoneMatched: "none_matched",
+	exitCrashed:     "crashed",
+}
+
+// exitError carries an exit code out of a command, with the summary main
+// prints after reporting err. Without err nothing is reported beyond the
+// summary line.
+type exitError struct {
+	code    int
+	err     error
+	summary *runSummary
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return exitStatuses[e.code]
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns the code the process exits with after err
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	if err != nil {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// silentExit reports whether err only carries an exit code, with nothing to report
+func silentExit(err error) bool {
+	var exitErr *exitError
+	return errors.As(err, &exitErr) && exitErr.err == nil
+}
+
+// printExitSummary prints the summary a failed run left in err, if any, so it
+// comes after the error itself
+func printExitSummary(err error) {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) && exitErr.summary != nil {
+		printSummary(exitErr.summary)
+	}
+}
+
+// requireValidConfig fails with exitConfig when the configuration couldn't be
+// loaded, instead of carrying on with the defaults other commands fall back to
+func requireValidConfig() error {
+	if cfg == nil {
+		return &exitError{code: exitConfig, err: fmt.Errorf("configuration not loaded")}
+	}
+	if configLoadErr != nil {
+		return &exitError{code: exitConfig, err: fmt.Errorf("invalid configuration: %w", configLoadErr)}
+	}
+	return nil
+}
+
+// runSummary counts what a run did, for the line it ends with
+type runSummary struct {
+	Command   string `json:"command"`
+	Status    string `json:"status"`
+	Exit      int    `json:"exit"`
+	Files     int    `json:"files"`     // Files the run looked at
+	Done      int    `json:"done"`      // Moved, copied or acted on; planned, in a dry run
+	Skipped   int    `json:"skipped"`   // Matched but left alone, e.g. on a collision
+	Unmatched int    `json:"unmatched"` // Matched no rule, or didn't meet a workflow's conditions
+	Failed    int    `json:"failed"`
+	DryRun    bool   `json:"dry_run"`
+}
+
+// newRunSummary starts the summary of a command, e.g. "workflow run"
+func newRunSummary(cmd *cobra.Command) *runSummary {
+	return &runSummary{Command: strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")}
+}
+
+// finishRun ends an organize, watch or workflow command: it works out the
+// exit code from the counts and the error the run returned, and prints the
+// summary line, or leaves it to main with the error to exit with
+func finishRun(cmd *cobra.Command, summary *runSummary, err error) error {
+	code := exitCode(err)
+	switch {
+	case code != exitOK && code != exitFailed:
+	case summary.Failed > 0:
+		code = exitPartial
+	case err != nil:
+	case summary.Files > 0 && summary.Done == 0 && summary.Skipped == 0:
+		code = exitNoneMatched
+	}
+	summary.Exit = code
+	summary.Status = exitStatuses[code]
+
+	if code == exitOK {
+		printSummary(summary)
+		return nil
+	}
+	// Usage doesn't help with a run that went wrong half way
+	cmd.SilenceUsage = true
+	if err == nil {
+		cmd.SilenceErrors = true
+	}
+	return &exitError{code: code, err: err, summary: summary}
+}
+
+// countDaemon adds what a watch daemon did, over all its directories, to the summary
+func countDaemon(summary *runSummary, status watch.DaemonStatus) {
+	for _, dir := range status.Directories {
+		summary.Files += dir.EventsSeen
+		summary.Done += dir.FilesOrganized
+		summary.Skipped += dir.Skipped
+		summary.Unmatched += dir.Unmatched
+		summary.Failed += dir.Errors
+	}
+}
+
+// printSummary writes the summary as the last line on stderr: key=value pairs,
+// or a JSON record with --errors json
+func printSummary(summary *runSummary) {
+	if errorsFormat == errorsJSON {
+		data, err := json.Marshal(map[string]*runSummary{"summary": summary})
+		if err == nil {
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+		return
+	}
+
+	fields := []string{
+		"command=" + strings.ReplaceAll(summary.Command, " ", "-"),
+		"status=" + summary.Status,
+		fmt.Sprintf("exit=%d", summary.Exit),
+		fmt.Sprintf("files=%d", summary.Files),
+		fmt.Sprintf("done=%d", summary.Done),
+		fmt.Sprintf("skipped=%d", summary.Skipped),
+		fmt.Sprintf("unmatched=%d", summary.Unmatched),
+		fmt.Sprintf("failed=%d", summary.Failed),
+		fmt.Sprintf("dry_run=%t", summary.DryRun),
+	}
+	fmt.Fprintln(os.Stderr, "sortd-summary "+strings.Join(fields, " "))
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"sortd/internal/app"
+	"sortd/internal/failures"
+	"sortd/pkg/workflow"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// NewFailuresCmd creates the failures command for the daemon's dead-letter queue
+func NewFailuresCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "failures",
+		Short: "Review files the watch daemon failed to organize",
+		Long: `Moves and workflow actions that fail on transient errors, such as a file
+busy in another program or a network share that is briefly away, are retried
+with a growing pause (settings.retry). Files that still fail are kept here
+until you retry them, and nothing is retried behind your back after that.`,
+	}
+
+	cmd.AddCommand(newFailuresListCmd())
+	cmd.AddCommand(newFailuresRetryCmd())
+
+	return cmd
+}
+
+// newFailuresListCmd lists the failed files
+func newFailuresListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List failed files",
+		Run: func(cmd *cobra.Command, args []string) {
+			queue, ok := openFailureQueue()
+			if !ok {
+				return
+			}
+
+			items, err := queue.List()
+			if err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error reading failure queue: %v", err)))
+				return
+			}
+			if len(items) == 0 {
+				fmt.Println(infoText("No failed files"))
+				return
+			}
+
+			for _, item := range items {
+				fmt.Printf("%s  %s\n", primaryText(item.ID), item.Path)
+				fmt.Printf("          %s\n", errorText(item.Error))
+				details := ""
+				if item.Attempts > 0 {
+					details = fmt.Sprintf(" after %d tries", item.Attempts)
+				}
+				if item.Trace != "" {
+					details += ", trace " + item.Trace
+				}
+				fmt.Printf("          %s failed %s%s\n", item.Source, item.Failed.Format("2006-01-02 15:04"), details)
+			}
+		},
+	}
+}
+
+// newFailuresRetryCmd runs failed files through the workflows and rules again
+func newFailuresRetryCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "retry [id...]",
+		Short: "Organize failed files again",
+		Long: `Run failed files through the workflows and rules again, as the daemon would.
+Files that succeed, or that no longer exist, leave the queue.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			queue, ok := openFailureQueue()
+			if !ok {
+				return
+			}
+
+			ids := args
+			if all {
+				items, err := queue.List()
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error reading failure queue: %v", err)))
+					return
+				}
+				ids = nil
+				for _, item := range items {
+					ids = append(ids, item.ID)
+				}
+			}
+			if len(ids) == 0 {
+				fmt.Println(warningText("No failures given; pass IDs or --all"))
+				return
+			}
+
+			service := app.New(cfg)
+			service.Engine().SetCollisionResolver(promptCollision)
+			handle := retryHandler(cmd.Context(), service, loadWorkflowManager())
+			for _, id := range ids {
+				if err := queue.Retry(id, handle); err != nil {
+					reportFileError(cmd.CommandPath(), err)
+					continue
+				}
+				fmt.Println(successText(fmt.Sprintf("✓ retried %s", id)))
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Retry every failed file")
+	return cmd
+}
+
+// retryHandler returns a function organizing a file as the daemon does: the
+// workflows get it first, then the rules and the unmatched policy
+func retryHandler(ctx context.Context, service *app.Service, manager *workflow.Manager) func(string) error {
+	return func(path string) error {
+		if manager != nil {
+			processed, err := manager.ProcessEvent(fsnotify.Event{Name: path, Op: fsnotify.Create})
+			if processed || err != nil {
+				return err
+			}
+		}
+
+		plan, err := service.PlanFiles(ctx, []string{path})
+		if err != nil {
+			return err
+		}
+		if len(plan.Moves) == 0 {
+			return fmt.Errorf("no rule or workflow matches %s", path)
+		}
+		results, err := service.Execute(ctx, plan)
+		if err != nil {
+			return err
+		}
+		return results[0].Error
+	}
+}
+
+// loadWorkflowManager loads the workflows from the default directory, or
+// returns nil when they can't be loaded
+func loadWorkflowManager() *workflow.Manager {
+	dir, err := workflow.DefaultDir()
+	if err != nil {
+		return nil
+	}
+	manager, err := workflow.NewManager(dir)
+	if err != nil {
+		fmt.Println(warningText(fmt.Sprintf("Workflows not loaded: %v", err)))
+		return nil
+	}
+	manager.SetDuplicates(cfg.Settings.Duplicates)
+	return manager
+}
+
+// openFailureQueue opens the dead-letter queue in the state directory
+func openFailureQueue() (*failures.Queue, bool) {
+	if cfg == nil {
+		fmt.Println(errorText("Configuration not loaded. Cannot read failed files."))
+		return nil, false
+	}
+
+	queue, err := failures.Open(failures.DefaultPath(cfg))
+	if err != nil {
+		fmt.Println(errorText(fmt.Sprintf("Error opening failure queue: %v", err)))
+		return nil, false
+	}
+	return queue, true
+}
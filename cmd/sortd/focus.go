@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sortd/internal/app"
+	"sortd/internal/focus"
+
+	"github.com/spf13/cobra"
+)
+
+// NewFocusCmd creates the focus command, which clears a directory a small
+// batch of files at a time
+func NewFocusCmd() *cobra.Command {
+	var (
+		batchSize int
+		timer     time.Duration
+		autoYes   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "focus [DIRECTORY]",
+		Short: "Clear a messy directory a few files at a time",
+		Long: `Work through a messy directory in small batches (10 files unless --batch
+says otherwise). Each batch shows where its files go; organize it, or stop
+and pick up where you left off next time. Progress and a daily streak are
+kept in the state directory and shared with the GUI's Focus tab.
+
+With --timer, batches keep coming until the time is up.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil {
+				return fmt.Errorf("configuration not loaded")
+			}
+
+			dir, err := determineTargetPath(args, "")
+			if err != nil {
+				return err
+			}
+			if dir, err = filepath.Abs(dir); err != nil {
+				return err
+			}
+
+			store, err := focus.Open(focus.DefaultPath(cfg))
+			if err != nil {
+				return err
+			}
+			session, err := store.Start(dir, batchSize, time.Now())
+			if err != nil {
+				return err
+			}
+
+			service := app.New(cfg)
+			service.Engine().SetCollisionResolver(askCollision)
+			previewIfReadOnly(service)
+
+			var deadline time.Time
+			if timer > 0 {
+				deadline = time.Now().Add(timer)
+				fmt.Println(infoText(fmt.Sprintf("⏱ Focusing for %s", timer)))
+			}
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			for {
+				plan, err := service.PlanOrganize(ctx, dir, app.PlanOptions{})
+				if err != nil {
+					return err
+				}
+				waiting := session.Waiting(plan.Sources())
+				batch := plan.Only(focus.Batch(waiting, session.BatchSize))
+				remaining := len(waiting) - len(batch.Moves)
+
+				if len(batch.Moves) == 0 {
+					if session.Done == 0 {
+						fmt.Println(infoText("Nothing here for your rules to organize."))
+					} else {
+						celebrateFocus(store, session)
+					}
+					return nil
+				}
+
+				fmt.Printf("\n%s  %s\n", focusProgress(session.Progress(len(waiting))),
+					primaryText(fmt.Sprintf("Batch %d", session.Batches+1)))
+				for _, move := range batch.Moves {
+					fmt.Printf("  %s → %s\n", filepath.Base(move.Source), filepath.Dir(move.Destination))
+				}
+				if service.DryRun() {
+					return nil
+				}
+				if !autoYes && !runGumConfirm(fmt.Sprintf("Organize these %d files?", len(batch.Moves))) {
+					fmt.Println(infoText("Stopped; 'sortd focus' picks up where you left off."))
+					return nil
+				}
+
+				results, err := service.Execute(ctx, batch)
+				organized := 0
+				for _, result := range results {
+					if result.Error != nil {
+						fmt.Println(errorText(fmt.Sprintf(" %s: %v", filepath.Base(result.SourcePath), result.Error)))
+						continue
+					}
+					organized++
+				}
+				if err != nil {
+					return err
+				}
+
+				failed := len(batch.Moves) - organized
+				session, err = store.FinishBatch(dir, organized, nil, remaining+failed, time.Now())
+				if err != nil {
+					return err
+				}
+				current, _ := store.Streak(time.Now())
+				fmt.Println(successText(fmt.Sprintf("✓ %d files organized, %d to go. 🔥 %d-day streak",
+					organized, remaining+failed, current)))
+
+				if !session.Completed.IsZero() {
+					celebrateFocus(store, session)
+					return nil
+				}
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					fmt.Println(infoText("⏰ Time's up. Nice work; take a break."))
+					return nil
+				}
+				if deadline.IsZero() && !autoYes && !runGumConfirm("Another batch?") {
+					return nil
+				}
+			}
+		},
+	}
+
+	cmd.Flags().IntVarP(&batchSize, "batch", "b", 0, fmt.Sprintf("Files per batch (default %d, or the size last used for the directory)", focus.DefaultBatchSize))
+	cmd.Flags().DurationVarP(&timer, "timer", "t", 0, "Keep offering batches for this long, e.g. 15m")
+	cmd.Flags().BoolVarP(&autoYes, "yes", "y", false, "Organize each batch without asking")
+
+	return cmd
+}
+
+// focusProgress draws a progress bar for a share from 0 to 1
+func focusProgress(progress float64) string {
+	const width = 20
+	filled := int(progress * width)
+	return fmt.Sprintf("[%s%s] %3.0f%%", strings.Repeat("█", filled), strings.Repeat("░", width-filled), progress*100)
+}
+
+// celebrateFocus congratulates the user on clearing a directory
+func celebrateFocus(store *focus.Store, session focus.Session) {
+	fmt.Println(successText(fmt.Sprintf("\n🎉 %s is clear! %d files in %d batches.",
+		filepath.Base(session.Directory), session.Done, session.Batches)))
+	if current, best := store.Streak(time.Now()); current > 1 {
+		fmt.Println(successText(fmt.Sprintf("   %d-day streak (best: %d)", current, best)))
+	}
+}
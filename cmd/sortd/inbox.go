@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sortd/internal/digest"
+	"sortd/internal/watch"
+
+	"github.com/spf13/cobra"
+)
+
+// NewInboxCmd creates the inbox command, which reports how far the inbox
+// directories are from empty
+func NewInboxCmd() *cobra.Command {
+	var send bool
+	var triage bool
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "inbox",
+		Short: "Count the files left in your inbox directories",
+		Long: `Count the files still waiting at the top level of each inbox directory
+(settings.inbox_zero.directories, or the watch directories), fullest first,
+with the change since the last report the watch daemon sent.
+
+Use --triage to start triage on the fullest inbox, and --send to deliver the
+report through the channels configured under settings.digest.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil {
+				return fmt.Errorf("configuration not loaded")
+			}
+
+			report := watch.InboxReport(cfg, time.Now())
+			if len(report.Inboxes) == 0 {
+				fmt.Println(infoText("No inbox directories: set settings.inbox_zero.directories or add a watch directory."))
+				return nil
+			}
+
+			if triage {
+				fullest := report.Fullest()
+				if fullest == "" {
+					fmt.Println(successText("🎉 Inbox zero: nothing to triage."))
+					return nil
+				}
+				triageCmd := NewTriageCmd()
+				triageCmd.SetContext(cmd.Context())
+				return triageCmd.RunE(triageCmd, []string{fullest})
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("error encoding report: %w", err)
+				}
+				fmt.Println(string(data))
+			} else {
+				fmt.Println(primaryText("📥 " + report.Subject()))
+				fmt.Print(report.Text())
+			}
+
+			if send {
+				if err := digest.Deliver(cfg.Settings.Digest, report); err != nil {
+					return fmt.Errorf("error delivering report: %w", err)
+				}
+				fmt.Println(successText("Report delivered"))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&send, "send", false, "Deliver the report through the configured digest channels")
+	cmd.Flags().BoolVarP(&triage, "triage", "t", false, "Start triage on the fullest inbox")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output the report in JSON format")
+
+	return cmd
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"sortd/internal/atomicfile"
+
+	"github.com/spf13/cobra"
+)
+
+// fileManagerMenuName is the context menu entry every file manager shows
+const fileManagerMenuName = "Organize with sortd"
+
+// integrationFile is a file an integration installs
+type integrationFile struct {
+	path    string
+	content string
+	mode    os.FileMode
+}
+
+// fileManager is a file manager sortd can add a context menu entry to
+type fileManager struct {
+	goos   string                                   // The system it runs on
+	where  string                                   // Where the entry shows up, for the user
+	remove func(home string) []string               // What --uninstall removes
+	files  func(home, exe string) []integrationFile // What installing writes
+}
+
+// fileManagers are the supported file managers, by name
+var fileManagers = map[string]fileManager{
+	"nautilus": {
+		goos:  "linux",
+		where: "right-click files → Scripts → " + fileManagerMenuName,
+		remove: func(home string) []string {
+			return []string{filepath.Join(home, ".local", "share", "nautilus", "scripts", fileManagerMenuName)}
+		},
+		files: func(home, exe string) []integrationFile {
+			script := strings.ReplaceAll(shellSnippets["nautilus"], "sortd organize", shellQuote(exe)+" organize")
+			return []integrationFile{{filepath.Join(home, ".local", "share", "nautilus", "scripts", fileManagerMenuName), script, 0755}}
+		},
+	},
+	"dolphin": {
+		goos:  "linux",
+		where: "right-click files → " + fileManagerMenuName + " (restart Dolphin first)",
+		remove: func(home string) []string {
+			return []string{
+				filepath.Join(home, ".local", "share", "kio", "servicemenus", "sortd.desktop"),
+				filepath.Join(home, ".local", "share", "sortd", "organize-selected"),
+			}
+		},
+		files: func(home, exe string) []integrationFile {
+			helper := filepath.Join(home, ".local", "share", "sortd", "organize-selected")
+			return []integrationFile{
+				{helper, organizeSelectedScript(exe), 0755},
+				{filepath.Join(home, ".local", "share", "kio", "servicemenus", "sortd.desktop"), dolphinServiceMenu(helper), 0755},
+			}
+		},
+	},
+	"finder": {
+		goos:  "darwin",
+		where: "right-click files → Quick Actions → " + fileManagerMenuName,
+		remove: func(home string) []string {
+			return []string{filepath.Join(home, "Library", "Services", fileManagerMenuName+".workflow")}
+		},
+		files: func(home, exe string) []integrationFile {
+			bundle := filepath.Join(home, "Library", "Services", fileManagerMenuName+".workflow", "Contents")
+			return []integrationFile{
+				{filepath.Join(bundle, "Info.plist"), finderInfoPlist, 0644},
+				{filepath.Join(bundle, "document.wflow"), finderWorkflow(exe), 0644},
+			}
+		},
+	},
+}
+
+// NewIntegrateCmd creates the integrate command hooking sortd into desktop tools
+func NewIntegrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "integrate",
+		Short: "Add sortd to other programs",
+		Long:  `Add sortd to the desktop's file managers, so files can be organized from them.`,
+	}
+
+	cmd.AddCommand(newIntegrateFileManagerCmd())
+	return cmd
+}
+
+// newIntegrateFileManagerCmd creates the 'integrate filemanager' command
+func newIntegrateFileManagerCmd() *cobra.Command {
+	var (
+		only      []string
+		uninstall bool
+	)
+
+	names := make([]string, 0, len(fileManagers))
+	for name := range fileManagers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cmd := &cobra.Command{
+		Use:   "filemanager",
+		Short: "Add \"" + fileManagerMenuName + "\" to file manager context menus",
+		Long: `Install a context menu entry that runs 'sortd organize' on the selected
+files: a Nautilus script and a Dolphin service menu on Linux, a Finder quick
+action on macOS. The entries call this sortd executable by its full path, so
+install again after moving it. --uninstall removes them.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+
+			targets := only
+			if len(targets) == 0 {
+				for _, name := range names {
+					if fileManagers[name].goos == runtime.GOOS {
+						targets = append(targets, name)
+					}
+				}
+				if len(targets) == 0 {
+					return fmt.Errorf("no supported file managers on %s (supported: %s)", runtime.GOOS, strings.Join(names, ", "))
+				}
+			}
+
+			var exe string
+			if !uninstall {
+				if exe, err = os.Executable(); err != nil {
+					return fmt.Errorf("can't find the sortd executable: %w", err)
+				}
+				if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+					exe = resolved
+				}
+			}
+
+			for _, name := range targets {
+				manager, ok := fileManagers[name]
+				if !ok {
+					return fmt.Errorf("unknown file manager %q (use one of %s)", name, strings.Join(names, ", "))
+				}
+
+				if uninstall {
+					for _, path := range manager.remove(home) {
+						if err := os.RemoveAll(path); err != nil {
+							return err
+						}
+					}
+					fmt.Println(successText(fmt.Sprintf("✓ %s: removed", name)))
+					continue
+				}
+
+				for _, file := range manager.files(home, exe) {
+					if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+						return err
+					}
+					if err := atomicfile.WriteFile(file.path, []byte(file.content), file.mode); err != nil {
+						return err
+					}
+				}
+				fmt.Println(successText(fmt.Sprintf("✓ %s: %s", name, manager.where)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&only, "only", nil, "File managers to set up: "+strings.Join(names, ", ")+" (default: those of this system)")
+	cmd.Flags().BoolVar(&uninstall, "uninstall", false, "Remove the context menu entries")
+	return cmd
+}
+
+// organizeSelectedScript is a helper organizing each file it is given, for
+// file managers passing the whole selection to one command
+func organizeSelectedScript(exe string) string {
+	return `#!/bin/sh
+# sortd: organizes the files given by a file manager's context menu
+for f in "$@"; do
+    ` + shellQuote(exe) + ` organize --non-interactive "$f"
+done
+`
+}
+
+// dolphinServiceMenu is the KDE service menu running helper on the selection
+func dolphinServiceMenu(helper string) string {
+	return `[Desktop Entry]
+Type=Service
+MimeType=all/allfiles;
+X-KDE-ServiceTypes=KonqPopupMenu/Plugin
+X-KDE-Priority=TopLevel
+Actions=organizeWithSortd
+
+[Desktop Action organizeWithSortd]
+Name=` + fileManagerMenuName + `
+Icon=folder-documents
+Exec="` + strings.ReplaceAll(helper, `"`, `\\"`) + `" %F
+`
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// finderInfoPlist declares the quick action as a Finder service for files and folders
+const finderInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>NSServices</key>
+	<array>
+		<dict>
+			<key>NSMenuItem</key>
+			<dict>
+				<key>default</key>
+				<string>` + fileManagerMenuName + `</string>
+			</dict>
+			<key>NSMessage</key>
+			<string>runWorkflowAsService</string>
+			<key>NSRequiredContext</key>
+			<dict>
+				<key>NSApplicationIdentifier</key>
+				<string>com.apple.finder</string>
+			</dict>
+			<key>NSSendFileTypes</key>
+			<array>
+				<string>public.item</string>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+// finderWorkflow is the Automator document of the quick action: a single
+// Run Shell Script action getting the selected files as arguments
+func finderWorkflow(exe string) string {
+	command := `for f in "$@"; do ` + shellQuote(exe) + ` organize --non-interactive "$f"; done`
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(command))
+
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>AMApplicationBuild</key>
+	<string>523</string>
+	<key>AMApplicationVersion</key>
+	<string>2.10</string>
+	<key>AMDocumentVersion</key>
+	<string>2</string>
+	<key>actions</key>
+	<array>
+		<dict>
+			<key>action</key>
+			<dict>
+				<key>AMAccepts</key>
+				<dict>
+					<key>Container</key>
+					<string>List</string>
+					<key>Optional</key>
+					<true/>
+					<key>Types</key>
+					<array>
+						<string>com.apple.cocoa.string</string>
+					</array>
+				</dict>
+				<key>AMActionVersion</key>
+				<string>2.0.3</string>
+				<key>AMApplication</key>
+				<array>
+					<string>Automator</string>
+				</array>
+				<key>AMParameterProperties</key>
+				<dict>
+					<key>COMMAND_STRING</key>
+					<dict/>
+					<key>CheckedForUserDefaultShell</key>
+					<dict/>
+					<key>inputMethod</key>
+					<dict/>
+					<key>shell</key>
+					<dict/>
+					<key>source</key>
+					<dict/>
+				</dict>
+				<key>AMProvides</key>
+				<dict>
+					<key>Container</key>
+					<string>List</string>
+					<key>Types</key>
+					<array>
+						<string>com.apple.cocoa.string</string>
+					</array>
+				</dict>
+				<key>ActionBundlePath</key>
+				<string>/System/Library/Automator/Run Shell Script.action</string>
+				<key>ActionName</key>
+				<string>Run Shell Script</string>
+				<key>ActionParameters</key>
+				<dict>
+					<key>COMMAND_STRING</key>
+					<string>` + escaped.String() + `</string>
+					<key>CheckedForUserDefaultShell</key>
+					<true/>
+					<key>inputMethod</key>
+					<integer>1</integer>
+					<key>shell</key>
+					<string>/bin/sh</string>
+					<key>source</key>
+					<string></string>
+				</dict>
+				<key>BundleIdentifier</key>
+				<string>com.apple.RunShellScript</string>
+				<key>CFBundleVersion</key>
+				<string>2.0.3</string>
+				<key>CanShowSelectedItemsWhenRun</key>
+				<false/>
+				<key>CanShowWhenRun</key>
+				<true/>
+				<key>Category</key>
+				<array>
+					<string>AMCategoryUtilities</string>
+				</array>
+				<key>Class Name</key>
+				<string>RunShellScriptAction</string>
+				<key>InputUUID</key>
+				<string>8F4B8D5C-3C8E-4B0E-9D7A-5A1C0F3E2B01</string>
+				<key>Keywords</key>
+				<array>
+					<string>Shell</string>
+					<string>Script</string>
+				</array>
+				<key>OutputUUID</key>
+				<string>1D2E3F40-5A6B-4C7D-8E9F-0A1B2C3D4E02</string>
+				<key>UUID</key>
+				<string>6C7D8E9F-0A1B-4C2D-9E3F-4A5B6C7D8E03</string>
+				<key>UnlocalizedApplications</key>
+				<array>
+					<string>Automator</string>
+				</array>
+				<key>arguments</key>
+				<dict/>
+				<key>isViewVisible</key>
+				<integer>1</integer>
+				<key>location</key>
+				<string>309.000000:305.000000</string>
+				<key>nibPath</key>
+				<string>/System/Library/Automator/Run Shell Script.action/Contents/Resources/Base.lproj/main.nib</string>
+			</dict>
+			<key>isViewVisible</key>
+			<integer>1</integer>
+		</dict>
+	</array>
+	<key>connectors</key>
+	<dict/>
+	<key>workflowMetaData</key>
+	<dict>
+		<key>applicationBundleIDsByPath</key>
+		<dict/>
+		<key>applicationPaths</key>
+		<array/>
+		<key>inputTypeIdentifier</key>
+		<string>com.apple.Automator.fileSystemObject</string>
+		<key>outputTypeIdentifier</key>
+		<string>com.apple.Automator.nothing</string>
+		<key>presentationMode</key>
+		<integer>15</integer>
+		<key>processesInput</key>
+		<false/>
+		<key>serviceInputTypeIdentifier</key>
+		<string>com.apple.Automator.fileSystemObject</string>
+		<key>serviceOutputTypeIdentifier</key>
+		<string>com.apple.Automator.nothing</string>
+		<key>serviceProcessesInput</key>
+		<false/>
+		<key>systemImageName</key>
+		<string>NSActionTemplate</string>
+		<key>useAutomaticInputType</key>
+		<false/>
+		<key>workflowTypeIdentifier</key>
+		<string>com.apple.Automator.servicesMenu</string>
+	</dict>
+</dict>
+</plist>
+`
+}
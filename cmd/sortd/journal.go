@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+	"sortd/internal/watch"
+
+	"github.com/spf13/cobra"
+)
+
+// NewJournalCmd creates the command for maintaining the watch daemon's
+// activity log, the record of every file it has organized
+func NewJournalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "Verify, compact and export the activity log",
+		Long: `The watch daemon records every file it organizes in an activity log. Each
+entry is chained to the one before it by a hash, so manual edits can be detected.
+Set settings.journal.keep_days or keep_entries to have the daemon trim it.`,
+	}
+
+	cmd.AddCommand(newJournalVerifyCmd())
+	cmd.AddCommand(newJournalCompactCmd())
+	cmd.AddCommand(newJournalExportCmd())
+	return cmd
+}
+
+// newJournalVerifyCmd creates 'journal verify'
+func newJournalVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Check the activity log for signs of tampering",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := watch.ActivityFilePath(cfg)
+			check, err := watch.VerifyActivity(path)
+			if err != nil {
+				return fmt.Errorf("failed to read activity log: %w", err)
+			}
+
+			fmt.Printf("%s: %d entries", path, check.Entries)
+			if check.Unsealed > 0 {
+				fmt.Printf(", %d from before the log was sealed", check.Unsealed)
+			}
+			if check.Torn > 0 {
+				fmt.Printf(", %d unreadable lines skipped", check.Torn)
+			}
+			fmt.Println()
+
+			if !check.OK() {
+				return fmt.Errorf("integrity check failed at line %d: %s", check.Line, check.Problem)
+			}
+			fmt.Println(successText("✓ Hash chain intact"))
+			return nil
+		},
+	}
+}
+
+// newJournalCompactCmd creates 'journal compact'
+func newJournalCompactCmd() *cobra.Command {
+	var keepDays, keepEntries int
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Drop old activity log entries",
+		Long: `Drop entries beyond the retention and re-seal the rest. Without flags the
+configured settings.journal retention is used. A log that fails its integrity
+check is left alone unless --force is given.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			retention := cfg.Settings.Journal
+			if cmd.Flags().Changed("keep-days") {
+				retention.KeepDays = keepDays
+			}
+			if cmd.Flags().Changed("keep-entries") {
+				retention.KeepEntries = keepEntries
+			}
+			if retention == (config.JournalSettings{}) {
+				return fmt.Errorf("no retention configured; pass --keep-days or --keep-entries")
+			}
+
+			kept, removed, err := watch.CompactActivity(watch.ActivityFilePath(cfg), retention, time.Now(), force)
+			if err != nil {
+				return err
+			}
+			fmt.Println(successText(fmt.Sprintf("Kept %d entries, removed %d", kept, removed)))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&keepDays, "keep-days", 0, "Drop entries older than this many days")
+	cmd.Flags().IntVar(&keepEntries, "keep-entries", 0, "Keep at most this many of the newest entries")
+	cmd.Flags().BoolVar(&force, "force", false, "Compact and re-seal even if the integrity check fails")
+	return cmd
+}
+
+// newJournalExportCmd creates 'journal export'
+func newJournalExportCmd() *cobra.Command {
+	var format, output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the activity log as CSV or JSON",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := watch.ReadJournal(watch.ActivityFilePath(cfg))
+			if err != nil {
+				return fmt.Errorf("failed to read activity log: %w", err)
+			}
+
+			if output == "" {
+				return watch.ExportActivity(os.Stdout, entries, format)
+			}
+
+			var buf bytes.Buffer
+			if err := watch.ExportActivity(&buf, entries, format); err != nil {
+				return err
+			}
+			if err := atomicfile.WriteFile(output, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Fprintln(cmd.ErrOrStderr(), successText(fmt.Sprintf("Exported %d entries to %s", len(entries), output)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "csv", "Export format: csv or json")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to a file instead of stdout")
+	return cmd
+}
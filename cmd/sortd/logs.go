@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	sortdlog "sortd/internal/log"
+	"sortd/internal/logview"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// logFollowInterval is how often --follow looks for new entries
+const logFollowInterval = 500 * time.Millisecond
+
+// startLogFile copies both loggers' entries to the structured log the log
+// viewers read. A log that can't be opened is skipped: it mustn't stop the
+// command that was asked for.
+func startLogFile() {
+	path, err := logview.DefaultPath()
+	if err != nil {
+		return
+	}
+	file, err := logview.OpenFile(path)
+	if err != nil {
+		return
+	}
+	log.AddHook(logview.NewHook(file))
+	sortdlog.SetSink(file)
+}
+
+// NewLogsCmd creates the command that shows sortd's structured log
+func NewLogsCmd() *cobra.Command {
+	var (
+		filter logview.Filter
+		lines  int
+		follow bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show sortd's log, filtered by level and text",
+		Long: `Show the last entries of the log every sortd command, the watch daemon and the
+GUI write to, so you can see why a file wasn't organized. The log lives in the
+state directory ($SORTD_STATE_DIR, or ~/.local/state/sortd on Linux).
+
+--level hides entries less severe than the one given (debug, info, warn,
+error). --grep keeps entries whose message or fields contain the text, e.g. a
+file name. --follow keeps printing new entries until Ctrl+C.`,
+		Example: `  sortd logs --level warn
+  sortd logs --grep invoice.pdf
+  sortd logs -f --level error`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := logview.DefaultPath()
+			if err != nil {
+				return fmt.Errorf("failed to find the log: %w", err)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			return showLogs(ctx, cmd.OutOrStdout(), path, filter, lines, follow)
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.Level, "level", "", "Least severe level to show: debug, info, warn or error")
+	cmd.Flags().StringVar(&filter.Search, "grep", "", "Only show entries containing this text")
+	cmd.Flags().IntVarP(&lines, "lines", "n", 50, "Number of entries to show (0 for all)")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep showing new entries as they are written")
+	return cmd
+}
+
+// showLogs prints the last entries of the log at path that pass filter and,
+// when following, the ones written after until ctx is done
+func showLogs(ctx context.Context, out io.Writer, path string, filter logview.Filter, lines int, follow bool) error {
+	tail := logview.NewTail(path)
+	entries, err := logview.Read(path, filter, lines)
+	if err != nil {
+		return fmt.Errorf("failed to read the log: %w", err)
+	}
+	if len(entries) == 0 && !follow {
+		fmt.Fprintln(out, infoText(fmt.Sprintf("No log entries to show in %s", path)))
+		return nil
+	}
+	for _, entry := range entries {
+		fmt.Fprintln(out, logLine(entry))
+	}
+	if !follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			added, err := tail.Next()
+			if err != nil {
+				return fmt.Errorf("failed to read the log: %w", err)
+			}
+			for _, entry := range added {
+				if filter.Match(entry) {
+					fmt.Fprintln(out, logLine(entry))
+				}
+			}
+		}
+	}
+}
+
+// logLine formats an entry, coloured by its level
+func logLine(entry logview.Entry) string {
+	switch entry.Level {
+	case "ERROR", "FATAL":
+		return errorText(entry.String())
+	case "WARN":
+		return warningText(entry.String())
+	}
+	return entry.String()
+}
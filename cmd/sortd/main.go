@@ -10,6 +10,7 @@ import (
 	"sortd/internal/watch"
 
 	"sortd/internal/config"
+	"sortd/internal/crash"
 	"sortd/internal/gui"
 
 	"sortd/cmd/sortd/cli"
@@ -24,6 +25,10 @@ var (
 
 // Entry point for the application
 func main() {
+	// A panic anywhere in a command, the GUI included, leaves a bundle for the bug report
+	defer recoverCrash()
+	crash.Version = version
+
 	// Get the root command from the factory function in root.go
 	rootCmd := NewRootCmd()
 
@@ -35,108 +40,29 @@ func main() {
 	rootCmd.SetUsageTemplate(helpTemplate)
 	rootCmd.SetHelpTemplate(helpTemplate)
 
-	// Add commands that are only defined in main.go
+	// Add commands that are only defined in main.go (organize and watch are
+	// registered in root.go only, so there is no second copy for cobra to pick)
 	rootCmd.AddCommand(analyzeCmd())
-	rootCmd.AddCommand(organizeCmd())
 	// rootCmd.AddCommand(tuiCmd())
 	rootCmd.AddCommand(guiCmd())
-	rootCmd.AddCommand(watchCmd())
 	rootCmd.AddCommand(daemonCmd)
 
 	// Initialize workflow commands
 	initWorkflowCommands(rootCmd)
+	addWorkflowHistoryCmd(rootCmd)
+	addWorkflowImportCmd(rootCmd)
+	addWorkflowRunCmd(rootCmd)
 
 	// Execute the command with improved error handling
-	if err := rootCmd.Execute(); err != nil {
-		// Print to both stderr and stdout to ensure tests can capture it
-		errMsg := fmt.Sprintf("Error: %s", err)
-		fmt.Fprintln(os.Stderr, errMsg)
-		fmt.Println(errMsg) // Also print to stdout for test capturing
-		os.Exit(1)
+	if cmd, err := rootCmd.ExecuteC(); err != nil {
+		if !silentExit(err) {
+			reportError(cmd.CommandPath(), err)
+		}
+		printExitSummary(err)
+		os.Exit(exitCode(err))
 	}
 }
 
-// organizeCmd represents the organize command - REINSTATED
-func organizeCmd() *cobra.Command {
-	var dir string
-	var dryRun bool
-
-	cmd := &cobra.Command{
-		Use:   "organize [directory]",
-		Short: "Organize files in a directory",
-		Long:  `Organize files in a directory based on patterns and content analysis.`,
-		Args:  cobra.MaximumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			// Determine the target directory
-			targetDir := dir // Use flag first
-			if targetDir == "" && len(args) > 0 {
-				targetDir = args[0] // Use argument if flag not set
-			}
-			if targetDir == "" {
-				var err error
-				targetDir, err = os.Getwd() // Default to current directory
-				if err != nil {
-					return fmt.Errorf("error getting current directory: %w", err)
-				}
-			}
-
-			// Load configuration
-			cfg, err := config.LoadConfig()
-			if err != nil {
-				fmt.Printf("Warning: Could not load config: %v. Using default settings.\n", err)
-				cfg = config.New()
-			}
-			if cmd.Flags().Changed("dry-run") {
-				cfg.Settings.DryRun = dryRun
-			}
-
-			// Create the organize engine
-			engine := organize.NewWithConfig(cfg)
-
-			// Perform organization
-			if cfg.Settings.DryRun {
-				fmt.Printf("Dry run: Planning organization for directory '%s'\n", targetDir)
-			} else {
-				fmt.Printf("Organizing directory '%s'\n", targetDir)
-			}
-
-			results, err := engine.OrganizeDirectory(targetDir)
-			if err != nil {
-				return fmt.Errorf("error organizing directory: %w", err)
-			}
-
-			// Print results
-			if len(results) == 0 {
-				fmt.Println("No files needed organization.")
-			} else {
-				fmt.Printf("Organization Summary (%d actions taken):\n", len(results))
-				for _, res := range results {
-					status := "Moved"
-					if !res.Moved {
-						status = "Skipped"
-						if res.Error != nil {
-							status = fmt.Sprintf("Error: %v", res.Error)
-						}
-					}
-					fmt.Printf("  - %s -> %s (%s)\n", res.SourcePath, res.DestinationPath, status)
-				}
-			}
-
-			if cfg.Settings.DryRun {
-				fmt.Println("\nDry run complete. No files were moved.")
-			} else {
-				fmt.Println("\nOrganization complete.")
-			}
-			return nil
-		},
-	}
-
-	cmd.Flags().StringVarP(&dir, "directory", "d", "", "Directory to organize (overrides argument, defaults to current directory)")
-	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what would be done without actually moving files")
-
-	return cmd
-}
-
 // runGUI launches the GUI directly
 func runGUI() error {
 	// Load configuration or use defaults
@@ -196,9 +122,9 @@ func analyzeCmd() *cobra.Command {
 				}
 			}
 
-			// Create the analysis engine and run the analysis
-			engine := analysis.New()
-			result, err := engine.ScanDirectory(dir)
+			// Analyze with the user's config, as organize and watch do
+			engine := analysis.NewWithConfig(cfg)
+			result, err := engine.ScanDirectoryContext(cmd.Context(), dir)
 			if err != nil {
 				fmt.Printf("Error analyzing directory: %v\n", err)
 				return
@@ -282,74 +208,6 @@ func analyzeCmd() *cobra.Command {
 // 	return cmd
 // }
 
-// watchCmd creates a command for watch mode
-func watchCmd() *cobra.Command {
-	var background bool // Keep background flag for now
-
-	cmd := &cobra.Command{
-		Use:   "watch",
-		Short: "Watch configured directories for changes and organize automatically",
-		Long: `Watch directories specified in the configuration for new or modified files
-and organize them automatically based on defined rules and patterns.`,
-		Run: func(cmd *cobra.Command, args []string) {
-			// Load configuration
-			cfg, err := config.LoadConfig()
-			if err != nil {
-				fmt.Printf("Error loading config: %v. Using default settings.\n", err)
-				cfg = config.New()
-				// Ensure some watch directories exist, maybe default to Downloads?
-				// For now, let's just warn if none are configured.
-				if len(cfg.WatchDirectories) == 0 {
-					fmt.Println("Warning: No watch directories configured. Exiting.")
-					os.Exit(1)
-				}
-			} else if len(cfg.WatchDirectories) == 0 {
-				fmt.Println("No watch directories specified in the configuration. Nothing to watch.")
-				fmt.Println("Please add directories to the 'watch_directories' section of your config file.")
-				os.Exit(0)
-			}
-
-			// Create the watch daemon - Pass only config, returns (*Daemon, error)
-			_, err = watch.NewDaemon(cfg)
-			if err != nil {
-				log.Errorf("Failed to create watch daemon: %v", err)
-				return
-			}
-
-			// Handle background mode (basic implementation)
-			if background {
-				fmt.Println("Starting watch daemon in background...")
-				if err := watch.DaemonControl(cfg, false); err != nil {
-					log.Errorf("Failed to start daemon in background: %v", err)
-					return
-				}
-				fmt.Println("Daemon started. Logs will be written to sortd.log")
-				fmt.Println("Use 'sortd daemon stop' to stop the daemon")
-				return // Exit after starting the daemon
-			}
-
-			// Run watch mode in foreground
-			fmt.Println("Starting watch daemon in foreground. Press Ctrl+C to stop.")
-			fmt.Printf("Watching directories: %v\n", cfg.WatchDirectories)
-
-			// Start the daemon in foreground mode
-			if err := watch.DaemonControl(cfg, true); err != nil {
-				log.Errorf("Failed to start daemon in foreground: %v", err)
-				return
-			}
-		},
-	}
-
-	// Remove old flags
-	// cmd.Flags().StringVarP(&dir, "dir", "d", "", "Directory to watch (default is current directory)") // Removed
-	// cmd.Flags().IntVarP(&interval, "interval", "i", 300, "Watch interval in seconds (default 300)") // Removed
-
-	// Keep background flag for now, although true daemonization is needed later
-	cmd.Flags().BoolVarP(&background, "background", "b", false, "Run in background mode (basic implementation)")
-
-	return cmd
-}
-
 // Add daemon control commands
 var daemonCmd = &cobra.Command{
 	Use:   "daemon",
@@ -377,6 +235,13 @@ var daemonStatusCmd = &cobra.Command{
 		fmt.Printf("  Watch Directories: %v\n", status.WatchDirectories)
 		fmt.Printf("  Last Activity: %v\n", status.LastActivity)
 		fmt.Printf("  Files Processed: %d\n", status.FilesProcessed)
+		if status.Paused != "" {
+			fmt.Printf("  Paused: %s\n", warningText(status.Paused))
+		}
+		for _, dir := range status.Directories {
+			fmt.Printf("  %s: %d events, %d organized, %d skipped, %d unmatched, %d errors\n",
+				dir.Path, dir.EventsSeen, dir.FilesOrganized, dir.Skipped, dir.Unmatched, dir.Errors)
+		}
 	},
 }
 
@@ -400,4 +265,6 @@ var daemonStopCmd = &cobra.Command{
 func init() {
 	daemonCmd.AddCommand(daemonStatusCmd)
 	daemonCmd.AddCommand(daemonStopCmd)
+	daemonCmd.AddCommand(newDaemonPauseCmd())
+	daemonCmd.AddCommand(newDaemonResumeCmd())
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"sortd/internal/app"
+	"sortd/internal/flowmap"
+
+	"github.com/spf13/cobra"
+)
+
+// NewMapCmd creates the map command, which renders where organizing a
+// directory would send its files
+func NewMapCmd() *cobra.Command {
+	var (
+		format    string
+		output    string
+		recursive bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "map [DIRECTORY]",
+		Short: "Draw where your rules send a directory's files",
+		Long: `Show where organizing a directory would send its files, by file type, rule
+and destination, without moving anything.
+
+--format html (the default) writes a self-contained page to review or share,
+e.g. with everyone using the same shared drive. --format dot writes a
+Graphviz graph:
+
+  sortd map ~/Downloads -o map.html
+  sortd map ~/Downloads --format dot | dot -Tsvg > map.svg`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil {
+				return fmt.Errorf("configuration not loaded")
+			}
+
+			dir, err := determineTargetPath(args, "")
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			plan, err := app.New(cfg).PlanOrganize(ctx, dir, app.PlanOptions{Recursive: recursive})
+			if err != nil {
+				return err
+			}
+			flows := flowmap.Build(plan, cfg.Organize.Patterns)
+
+			if output == "" {
+				return flows.Write(os.Stdout, format)
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			if err := flows.Write(f, format); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Println(successText("✓ Map written to " + output))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", flowmap.FormatHTML, "Output format: html or dot")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write the map to (default: standard output)")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Include files in subdirectories")
+
+	return cmd
+}
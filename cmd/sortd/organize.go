@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 
+	"sortd/internal/app"
 	"sortd/internal/organize"
+	"sortd/pkg/types"
 
 	"github.com/spf13/cobra"
 )
@@ -62,13 +65,56 @@ func selectFilesInteractive(files []string) []string {
 }
 
 // printOrganizePlan prints the organization plan for dry run mode
-func printOrganizePlan(organizer *organize.Engine, files []string) {
-	fmt.Printf("Would organize %d files:\n", len(files))
-	for _, file := range files {
-		fmt.Printf("  %s would be moved\n", file)
+func printOrganizePlan(plan *app.Plan) {
+	fmt.Printf("Would organize %d files:\n", len(plan.Moves))
+	for _, move := range plan.Moves {
+		if move.Unmatched {
+			fmt.Printf("  %s  %s -> %s (no rule matches it)\n", move.ID, move.Source, move.Destination)
+			continue
+		}
+		if move.Screenshot {
+			fmt.Printf("  %s  %s -> %s (screenshot)\n", move.ID, move.Source, move.Destination)
+			continue
+		}
+		if move.Copy {
+			fmt.Printf("  %s  %s -> %s (copy)\n", move.ID, move.Source, move.Destination)
+			continue
+		}
+		fmt.Printf("  %s  %s -> %s\n", move.ID, move.Source, move.Destination)
+	}
+	if len(plan.Unmatched) > 0 {
+		fmt.Printf("No rule matches %d files (settings.unmatched.policy: %s)\n", len(plan.Unmatched), organize.UnmatchedPolicy(cfg))
 	}
 }
 
+// reportUnmatched applies the review policy to the files no rule matched and
+// tells the user about them, so they don't pile up unnoticed
+func reportUnmatched(service *app.Service, plan *app.Plan, results []types.OrganizeResult) error {
+	moved := 0
+	for i, result := range results {
+		if result.Moved && plan.Moves[i].Unmatched {
+			moved++
+		}
+	}
+	if moved > 0 {
+		fmt.Printf(" Moved %d files no rule matches to %s\n", moved, organize.UnsortedFolder(cfg))
+	}
+	if len(plan.Unmatched) == 0 {
+		return nil
+	}
+
+	queued, err := service.ReviewUnmatched(plan)
+	if err != nil {
+		return fmt.Errorf("error queueing unmatched files: %w", err)
+	}
+	if queued > 0 {
+		fmt.Println(infoText(fmt.Sprintf(" Queued %d files no rule matches for review ('sortd pending' to decide)", queued)))
+	} else {
+		fmt.Println(warningText(fmt.Sprintf(" No rule matches %d files; they were left in place", len(plan.Unmatched))))
+	}
+	return nil
+}
+
 // NewOrganizeCmd creates the organize command
 func NewOrganizeCmd() *cobra.Command {
 	var (
@@ -77,20 +123,96 @@ func NewOrganizeCmd() *cobra.Command {
 		verbose        bool
 		recursive      bool
 		nonInteractive bool
+		by             string
+		library        string
+		fingerprint    bool
+		only           []string
+		maxDepth       int
+		maxFiles       int
+		copyFiles      bool
+		stdin          bool
+		null           bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "organize [directory|file]",
 		Short: "Organize files in a directory",
-		Long:  `Organize files according to your rules, with a fun interactive interface.`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx := cmd.Context()
+		Long: `Organize files according to your rules, with a fun interactive interface.
+
+With --by music, audio files are filed by their tags into Artist/Album folders
+under the library (the target directory unless --library is given), and
+re-downloads of tracks already in the library go to its Duplicates folder.
+
+Every planned move has an ID derived from the file, its destination and the
+rule, so it stays the same between runs. --dry-run lists them, and --only
+carries out just the moves with the given IDs.
+
+--stdin organizes the files named on stdin instead of a directory, so other
+tools can pick them: find ~/Downloads -name '*.pdf' -mtime +30 | sortd organize --stdin
+Use --null with find -print0 for names with newlines in them. Directories among
+the paths are skipped, and nothing is asked interactively.
+
+--copy copies files instead of moving them, as settings.copy or a rule's copy
+option do, leaving the originals untouched. Files whose content is already in
+their destination folder aren't copied again.
+
+The exit code tells scripts how it went: 0 when files were organized, 2 when
+some failed, 3 when the configuration is invalid and 4 when no file matched a
+rule. The last line on stderr sums the run up, e.g.
+  sortd-summary command=organize status=ok exit=0 files=12 done=10 skipped=0 unmatched=2 failed=0 dry_run=false`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			// End with the summary line and the exit code scripts look at
+			summary := newRunSummary(cmd)
+			defer func() { err = finishRun(cmd, summary, err) }()
+			if err := requireValidConfig(); err != nil {
+				return err
+			}
+
+			// Ctrl+C stops after the file being moved instead of mid-run
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
 
 			// Set non-interactive mode in environment for consistent access across functions
 			if nonInteractive {
 				os.Setenv("SORTD_NON_INTERACTIVE", "true")
 			}
 
+			if cmd.Flags().Changed("max-depth") {
+				cfg.Settings.MaxDepth = maxDepth
+			}
+			if cmd.Flags().Changed("max-files") {
+				cfg.Settings.MaxFiles = maxFiles
+			}
+
+			if stdin {
+				if len(args) > 0 || directory != "" {
+					return fmt.Errorf("--stdin reads the files to organize from stdin; don't give a path as well")
+				}
+				if by != "" && by != "rules" {
+					return fmt.Errorf("--stdin only organizes by rules")
+				}
+				// Stdin carries the paths, so there's nothing to answer prompts with
+				os.Setenv("SORTD_NON_INTERACTIVE", "true")
+			}
+
+			// Plan and move through the same service the GUI and daemon use
+			service := app.New(cfg)
+			service.Engine().SetCollisionResolver(askCollision)
+
+			// Override dry run if specified
+			if dryRun {
+				service.SetDryRun(true)
+			}
+			if copyFiles {
+				service.Engine().SetCopy(true)
+			}
+			previewIfReadOnly(service)
+			summary.DryRun = service.DryRun()
+
+			if stdin {
+				return organizeStdin(ctx, service, cmd.InOrStdin(), null, verbose, only, summary)
+			}
+
 			// Determine target path
 			targetPath, err := determineTargetPath(args, directory)
 			if err != nil {
@@ -108,20 +230,20 @@ func NewOrganizeCmd() *cobra.Command {
 				return fmt.Errorf("error accessing path: %w", err)
 			}
 
-			// Setup engine using the organize package directly
-			organizeEngine := organize.NewWithConfig(cfg)
-
-			// Override dry run if specified
-			if dryRun {
-				organizeEngine.SetDryRun(true)
+			switch by {
+			case "", "rules":
+			case "music":
+				return organizeMusic(ctx, service.Engine(), targetPath, info.IsDir(), library, recursive, fingerprint, verbose, summary)
+			default:
+				return fmt.Errorf("unknown organize mode %q (use rules or music)", by)
 			}
 
 			// Handle organization based on whether the target is a file or directory
 			if !info.IsDir() {
-				return organizeSingleFile(ctx, organizeEngine, targetPath, verbose)
+				return organizeSingleFile(ctx, service, targetPath, verbose, summary)
 			}
 
-			return organizeDirectory(ctx, organizeEngine, targetPath, recursive, verbose)
+			return organizeDirectory(ctx, service, targetPath, recursive, verbose, only, summary)
 		},
 	}
 
@@ -131,6 +253,15 @@ func NewOrganizeCmd() *cobra.Command {
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Recursively organize subdirectories")
 	cmd.Flags().BoolVarP(&nonInteractive, "non-interactive", "N", false, "Run in non-interactive mode (no user prompts)")
+	cmd.Flags().StringVar(&by, "by", "rules", "How to organize: rules (configured patterns) or music (Artist/Album from audio tags)")
+	cmd.Flags().StringVar(&library, "library", "", "Music library to file tracks into (default: the target directory)")
+	cmd.Flags().StringArrayVar(&only, "only", nil, "Only carry out the planned move with this ID, as listed by --dry-run (repeatable)")
+	cmd.Flags().IntVar(&maxDepth, "max-depth", 0, "Directory levels a recursive run searches (default settings.max_depth; negative for no limit)")
+	cmd.Flags().IntVar(&maxFiles, "max-files", 0, "Files a run takes in at most (default settings.max_files; negative for no limit)")
+	cmd.Flags().BoolVar(&copyFiles, "copy", false, "Copy files instead of moving them, leaving the originals in place")
+	cmd.Flags().BoolVar(&stdin, "stdin", false, "Organize the files whose paths are read from stdin, one per line, e.g. from find")
+	cmd.Flags().BoolVarP(&null, "null", "0", false, "With --stdin, paths are separated by NUL bytes, as find -print0 writes them")
+	cmd.Flags().BoolVar(&fingerprint, "fingerprint", false, "Also match re-encoded duplicates by acoustic fingerprint (needs Chromaprint's fpcalc)")
 
 	return cmd
 }
@@ -172,11 +303,13 @@ func determineTargetPath(args []string, flagDirectory string) (string, error) {
 }
 
 // organizeSingleFile organizes a single file according to configured patterns
-func organizeSingleFile(ctx context.Context, engine *organize.Engine, filePath string, verbose bool) error {
+func organizeSingleFile(ctx context.Context, service *app.Service, filePath string, verbose bool, summary *runSummary) error {
 	// Set dry run mode if in test mode to prevent actual file modification
 	if os.Getenv("TESTMODE") == "true" {
-		engine.SetDryRun(true)
+		service.SetDryRun(true)
 	}
+	summary.DryRun = service.DryRun()
+	summary.Files = 1
 
 	// Check for context cancellation
 	if err := ctx.Err(); err != nil {
@@ -196,7 +329,7 @@ func organizeSingleFile(ctx context.Context, engine *organize.Engine, filePath s
 		// Print configuration info
 		if cfg != nil {
 			fmt.Printf(" Collision strategy: %s\n", cfg.Settings.Collision)
-			for i, pattern := range cfg.Organize.Patterns {
+			for i, pattern := range organize.OrderPatterns(cfg.Organize.Patterns) {
 				fmt.Printf(" Pattern %d: %s -> %s\n", i+1, pattern.Match, pattern.Target)
 			}
 		}
@@ -204,36 +337,43 @@ func organizeSingleFile(ctx context.Context, engine *organize.Engine, filePath s
 		fmt.Printf(" Note: %s is a file, not a directory\n", filePath)
 	}
 
-	// Find matching pattern
-	destDir, matched := findMatchingPattern(filePath)
-
-	// If no pattern matched, inform the user
-	if !matched {
-		return fmt.Errorf("no pattern matched for file: %s", filePath)
+	// Find where the matching pattern sends it
+	plan, err := service.PlanOrganize(ctx, filePath, app.PlanOptions{})
+	if err != nil {
+		return err
 	}
 
-	// Build destination path
-	var fullDestDir string
-	if filepath.IsAbs(destDir) {
-		fullDestDir = destDir
-	} else {
-		// If relative, make it relative to parent directory of the file
-		parentDir := filepath.Dir(filePath)
-		fullDestDir = filepath.Join(parentDir, destDir)
+	// If no pattern matched, inform the user, or queue it for review
+	if len(plan.Moves) == 0 {
+		summary.Unmatched = 1
+		if organize.UnmatchedPolicy(cfg) == organize.UnmatchedReview && !service.DryRun() {
+			return reportUnmatched(service, plan, nil)
+		}
+		return &exitError{code: exitNoneMatched, err: fmt.Errorf("no pattern matched for file: %s", filePath)}
 	}
+	move := plan.Moves[0]
 
-	destPath := filepath.Join(fullDestDir, filepath.Base(filePath))
+	verb, doing := "move", "Moving"
+	if move.Copy {
+		verb, doing = "copy", "Copying"
+	}
 
 	// Check for dry run
-	if engine.IsDryRun() {
-		fmt.Printf(" Would move: %s -> %s\n", filePath, destPath)
+	if service.DryRun() {
+		fmt.Printf(" Would %s: %s -> %s\n", verb, move.Source, move.Destination)
+		countResults(summary, plan, nil)
 		return nil
 	}
 
 	// Perform the move
-	fmt.Printf(" Moving: %s -> %s\n", filePath, destPath)
-	if err := engine.MoveFile(filePath, destPath); err != nil {
-		return fmt.Errorf("error moving file: %w", err)
+	fmt.Printf(" %s: %s -> %s\n", doing, move.Source, move.Destination)
+	results, err := service.Execute(ctx, plan)
+	if err != nil {
+		return fmt.Errorf("operation cancelled: %w", err)
+	}
+	countResults(summary, plan, results)
+	if results[0].Error != nil {
+		return fmt.Errorf("error moving file: %w", results[0].Error)
 	}
 
 	fmt.Println(successText(" File organized successfully"))
@@ -241,11 +381,12 @@ func organizeSingleFile(ctx context.Context, engine *organize.Engine, filePath s
 }
 
 // organizeDirectory organizes all files in a directory
-func organizeDirectory(ctx context.Context, engine *organize.Engine, dirPath string, recursive bool, verbose bool) error {
+func organizeDirectory(ctx context.Context, service *app.Service, dirPath string, recursive bool, verbose bool, only []string, summary *runSummary) error {
 	// Set dry run mode if in test mode to prevent actual file modification
 	if os.Getenv("TESTMODE") == "true" {
-		engine.SetDryRun(true)
+		service.SetDryRun(true)
 	}
+	summary.DryRun = service.DryRun()
 
 	// Check for context cancellation
 	if err := ctx.Err(); err != nil {
@@ -253,95 +394,114 @@ func organizeDirectory(ctx context.Context, engine *organize.Engine, dirPath str
 	}
 
 	fmt.Printf(" Organizing directory: %s\n", dirPath)
+	service.Engine().CleanStaging(dirPath)
 
-	// Find files to organize
-	var files []string
-	var err error
-
-	if recursive {
-		files, err = findFilesRecursive(dirPath)
-	} else {
-		files, err = findFiles(dirPath)
-	}
-
+	// Work out where each file goes
+	plan, err := service.PlanOrganize(ctx, dirPath, app.PlanOptions{Recursive: recursive})
 	if err != nil {
-		return fmt.Errorf("error finding files: %w", err)
+		return err
 	}
 
-	fmt.Printf(" Found %d files to organize\n", len(files))
+	fmt.Printf(" Found %d files to organize\n", len(plan.Listing.Files))
+	reportTruncation(plan.Listing, organize.LimitsFromConfig(cfg))
 
-	// Allow interactive selection if not in test mode or non-interactive mode
-	if os.Getenv("TESTMODE") != "true" && !isNonInteractive() && !recursive {
-		files = selectFilesInteractive(files)
-		fmt.Printf(" Selected %d files to organize\n", len(files))
+	// Moves picked by ID were already chosen, so skip interactive selection
+	if len(only) > 0 {
+		var missing []string
+		if plan, missing = plan.OnlyIDs(only); len(missing) > 0 {
+			return fmt.Errorf("no planned move has ID %s; the files or rules may have changed since", strings.Join(missing, ", "))
+		}
+		fmt.Printf(" Selected %d files to organize\n", len(plan.Moves))
+	} else if os.Getenv("TESTMODE") != "true" && !isNonInteractive() && !recursive {
+		// Allow interactive selection if not in test mode or non-interactive mode
+		plan = plan.Only(selectFilesInteractive(plan.Sources()))
+		fmt.Printf(" Selected %d files to organize\n", len(plan.Moves))
 	} else if isNonInteractive() {
 		fmt.Println(" Running in non-interactive mode, processing all files")
 	}
 
+	return executeOrganizePlan(ctx, service, plan, verbose, summary)
+}
+
+// executeOrganizePlan carries out a planned organize run, or prints it for a
+// dry run, and reports how it went
+func executeOrganizePlan(ctx context.Context, service *app.Service, plan *app.Plan, verbose bool, summary *runSummary) error {
 	// Check for dry run
-	if engine.IsDryRun() {
-		printOrganizePlan(engine, files)
+	summary.Files = len(plan.Moves) + len(plan.Unmatched)
+	if service.DryRun() {
+		printOrganizePlan(plan)
+		countResults(summary, plan, nil)
 		return nil
 	}
 
 	// Perform organization
-	err = engine.OrganizeByPatterns(files)
+	results, err := service.Execute(ctx, plan)
+	countResults(summary, plan, results)
 	if err != nil {
 		return fmt.Errorf("error organizing files: %w", err)
 	}
 
 	// Print results
-	fmt.Printf(" Organized %d files\n", len(files))
-	if verbose {
-		for i, file := range files {
-			fmt.Printf(" %d. Organized: %s\n", i+1, file)
+	var moved, copied, failed int
+	for i, result := range results {
+		if result.Error != nil {
+			failed++
+			reportFileError("sortd organize", result.Error)
+			continue
 		}
+		moved++
+		if result.Copied {
+			copied++
+		}
+		if verbose {
+			fmt.Printf(" %d. Organized: %s -> %s\n", i+1, result.SourcePath, result.DestinationPath)
+		}
+	}
+	if copied > 0 {
+		fmt.Printf(" Organized %d files (%d copied, originals left in place)\n", moved, copied)
+	} else {
+		fmt.Printf(" Organized %d files\n", moved)
+	}
+	if err := reportUnmatched(service, plan, results); err != nil {
+		return err
 	}
 
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files could not be organized", failed, len(results))
+	}
 	return nil
 }
 
-// findMatchingPattern finds a pattern that matches the given file
-func findMatchingPattern(filePath string) (string, bool) {
-	if cfg == nil || len(cfg.Organize.Patterns) == 0 {
-		return "", false
-	}
-
-	for _, pattern := range cfg.Organize.Patterns {
-		isMatch, err := filepath.Match(pattern.Match, filepath.Base(filePath))
-		if err == nil && isMatch {
-			return pattern.Target, true
+// countResults adds a plan's moves to the summary: what was done, skipped,
+// left unmatched or failed, or without results what a dry run would do
+func countResults(summary *runSummary, plan *app.Plan, results []types.OrganizeResult) {
+	summary.Unmatched += len(plan.Unmatched)
+	for i, move := range plan.Moves {
+		switch {
+		case move.Unmatched:
+			summary.Unmatched++
+		case results == nil:
+			summary.Done++
+		case i >= len(results):
+			// Cancelled before this move
+		case results[i].Error != nil:
+			summary.Failed++
+		case results[i].Moved:
+			summary.Done++
+		default:
+			summary.Skipped++
 		}
 	}
-
-	return "", false
 }
 
-// findFilesRecursive finds all files in a directory and its subdirectories
-func findFilesRecursive(root string) ([]string, error) {
-	var files []string
-
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		// Skip the root directory itself
-		if path == root {
-			return nil
-		}
-
-		// Skip errors and directories
-		if err != nil || info.IsDir() {
-			return nil
-		}
-
-		// Add the file to our list
-		files = append(files, path)
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("error walking directory: %w", err)
+// reportTruncation tells the user when a limit left files out
+func reportTruncation(listing organize.Listing, limits organize.Limits) {
+	if listing.HitMaxFiles {
+		fmt.Println(warningText(fmt.Sprintf(" Truncated at %d files (settings.max_files); use --max-files to raise the limit", limits.MaxFiles)))
+	}
+	if listing.HitMaxDepth {
+		fmt.Println(warningText(fmt.Sprintf(" Skipped directories more than %d levels deep (settings.max_depth); use --max-depth to go deeper", limits.MaxDepth)))
 	}
-
-	return files, nil
 }
 
 // analyzeFileTypes returns a list of unique file extensions found in the files
@@ -366,18 +526,3 @@ func analyzeFileTypes(files []string) []string {
 
 	return result
 }
-
-// findFiles recursively finds all files in a directory
-func findFiles(dir string) ([]string, error) {
-	var files []string
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			files = append(files, path)
-		}
-		return nil
-	})
-	return files, err
-}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"sortd/internal/organize"
+	"sortd/internal/watch"
+
+	"github.com/spf13/cobra"
+)
+
+// NewOrganizeLastDownloadCmd creates the command that organizes only the most
+// recently added file in the watch directories
+func NewOrganizeLastDownloadCmd() *cobra.Command {
+	var dryRun bool
+	var within time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "organize-last-download",
+		Short: "Organize the file you just downloaded",
+		Long: `Find the most recently added file in the configured watch directories and
+organize just that file by your rules. Bind it to a global hotkey in your
+desktop environment to file a download the moment it lands.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil {
+				return fmt.Errorf("configuration not loaded")
+			}
+
+			path, err := watch.LastAdded(cfg, within)
+			if err != nil {
+				return err
+			}
+
+			engine := organize.NewWithConfig(cfg)
+			engine.SetCollisionResolver(askCollision)
+			if dryRun {
+				engine.SetDryRun(true)
+			}
+
+			destDir, ok := engine.DestinationDir(path)
+			if !ok {
+				return fmt.Errorf("no pattern matched for file: %s", path)
+			}
+			dest := filepath.Join(destDir, filepath.Base(path))
+
+			if engine.IsDryRun() {
+				fmt.Println(infoText(fmt.Sprintf("Would move: %s -> %s", path, dest)))
+				return nil
+			}
+			if err := engine.MoveFile(path, dest); err != nil {
+				return fmt.Errorf("error moving file: %w", err)
+			}
+			fmt.Println(successText(fmt.Sprintf("Moved %s -> %s", filepath.Base(path), destDir)))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show where the file would go without moving it")
+	cmd.Flags().DurationVar(&within, "within", 0, "Only consider files added this recently, e.g. 10m (default: any age)")
+
+	return cmd
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sortd/internal/music"
+	"sortd/internal/organize"
+)
+
+// organizeMusic files audio tracks into Artist/Album folders of a library,
+// moving re-downloads of tracks it already holds into its Duplicates folder
+func organizeMusic(ctx context.Context, engine *organize.Engine, targetPath string, isDir bool, library string, recursive, fingerprint, verbose bool, summary *runSummary) error {
+	if os.Getenv("TESTMODE") == "true" {
+		engine.SetDryRun(true)
+	}
+	summary.DryRun = engine.IsDryRun()
+
+	files := []string{targetPath}
+	if isDir {
+		limits := organize.LimitsFromConfig(cfg)
+		listing, err := organize.ListFiles(ctx, targetPath, recursive, limits)
+		if err != nil {
+			return fmt.Errorf("error finding files: %w", err)
+		}
+		files = listing.Files
+		reportTruncation(listing, limits)
+	}
+
+	if library == "" {
+		library = targetPath
+		if !isDir {
+			library = filepath.Dir(targetPath)
+		}
+	}
+
+	var fingerprinter music.Fingerprinter
+	if fingerprint {
+		chromaprint, err := music.NewChromaprint()
+		if err != nil {
+			return err
+		}
+		fingerprinter = chromaprint
+	}
+
+	fmt.Printf(" Reading audio tags in %s\n", targetPath)
+	plan, err := music.Plan(files, library, fingerprinter)
+	if err != nil {
+		return fmt.Errorf("error planning music organization: %w", err)
+	}
+	for path, reason := range plan.Skipped {
+		fmt.Println(warningText(fmt.Sprintf(" Skipped %s: %s", filepath.Base(path), reason)))
+	}
+	summary.Files = len(files)
+	summary.Unmatched = len(plan.Skipped)
+	summary.Skipped = plan.InPlace
+
+	// Artist/Album folders are the point of this mode, so always create them
+	engine.SetCreateDirs(true)
+
+	moved, duplicates := 0, 0
+	for _, move := range plan.Moves {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("operation cancelled: %w", err)
+		}
+
+		rel, relErr := filepath.Rel(library, move.Destination)
+		if relErr != nil {
+			rel = move.Destination
+		}
+		switch {
+		case move.DuplicateOf != "" && engine.IsDryRun():
+			fmt.Printf(" Would move duplicate: %s -> %s (same recording as %s)\n", filepath.Base(move.Source), rel, move.DuplicateOf)
+		case move.DuplicateOf != "":
+			fmt.Printf(" Duplicate: %s -> %s (same recording as %s)\n", filepath.Base(move.Source), rel, move.DuplicateOf)
+		case engine.IsDryRun():
+			fmt.Printf(" Would move: %s -> %s\n", filepath.Base(move.Source), rel)
+		case verbose:
+			fmt.Printf(" Moving: %s -> %s\n", filepath.Base(move.Source), rel)
+		}
+
+		if engine.IsDryRun() {
+			summary.Done++
+			continue
+		}
+		if err := engine.MoveFile(move.Source, move.Destination); err != nil {
+			fmt.Println(errorText(fmt.Sprintf(" Error moving %s: %v", move.Source, err)))
+			summary.Failed++
+			continue
+		}
+		summary.Done++
+		if move.DuplicateOf != "" {
+			duplicates++
+		} else {
+			moved++
+		}
+	}
+
+	if engine.IsDryRun() {
+		fmt.Printf(" Would organize %d tracks (%d already in place)\n", len(plan.Moves), plan.InPlace)
+		return nil
+	}
+	fmt.Println(successText(fmt.Sprintf(" Filed %d tracks, moved %d duplicates, %d already in place", moved, duplicates, plan.InPlace)))
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sortd/internal/app"
+)
+
+// readPaths reads file paths from r, one per line, or separated by NUL bytes
+// with null, as 'find -print0' writes them. Blank entries are skipped.
+func readPaths(r io.Reader, null bool) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	if null {
+		scanner.Split(splitNull)
+	}
+
+	var paths []string
+	for scanner.Scan() {
+		path := scanner.Text()
+		if !null {
+			path = strings.TrimRight(path, "\r")
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// splitNull is a bufio.SplitFunc for NUL-separated entries
+func splitNull(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// organizeStdin organizes the files named on stdin, e.g. by find. Paths that
+// don't exist count as failed; directories are skipped, as find lists them
+// along with their files.
+func organizeStdin(ctx context.Context, service *app.Service, stdin io.Reader, null, verbose bool, only []string, summary *runSummary) error {
+	if os.Getenv("TESTMODE") == "true" {
+		service.SetDryRun(true)
+	}
+	summary.DryRun = service.DryRun()
+
+	paths, err := readPaths(stdin, null)
+	if err != nil {
+		return fmt.Errorf("error reading paths from stdin: %w", err)
+	}
+
+	var files []string
+	var missing int
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			missing++
+			reportFileError("sortd organize", fmt.Errorf("error accessing %s: %w", path, err))
+		case info.IsDir():
+			if verbose {
+				fmt.Printf(" Skipping directory: %s\n", path)
+			}
+		default:
+			files = append(files, path)
+		}
+	}
+	fmt.Printf(" Read %d files from stdin\n", len(files))
+
+	plan, err := service.PlanFiles(ctx, files)
+	if err != nil {
+		return err
+	}
+	if len(only) > 0 {
+		var unknown []string
+		if plan, unknown = plan.OnlyIDs(only); len(unknown) > 0 {
+			return fmt.Errorf("no planned move has ID %s; the files or rules may have changed since", strings.Join(unknown, ", "))
+		}
+		fmt.Printf(" Selected %d files to organize\n", len(plan.Moves))
+	}
+
+	err = executeOrganizePlan(ctx, service, plan, verbose, summary)
+	summary.Files += missing
+	summary.Failed += missing
+	if err == nil && missing > 0 {
+		err = fmt.Errorf("%d of the paths on stdin could not be read", missing)
+	}
+	return err
+}
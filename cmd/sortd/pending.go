@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"sortd/internal/learning"
+	"sortd/internal/organize"
+	"sortd/internal/pending"
+
+	"github.com/spf13/cobra"
+)
+
+// NewPendingCmd creates the pending command for reviewing staged moves
+func NewPendingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pending",
+		Short: "Review moves the watch daemon staged instead of making",
+		Long: `While settings.training is enabled, the watch daemon stages the moves its
+rules would make instead of carrying them out. With settings.auto_apply_confidence
+set, moves by patterns whose confidence is below the threshold are staged too.
+Approving a move performs it; rejecting it leaves the file in place. Both
+outcomes are recorded so each rule builds up a confidence score.`,
+	}
+
+	cmd.AddCommand(newPendingListCmd())
+	cmd.AddCommand(newPendingResolveCmd("approve", "Carry out staged moves", true))
+	cmd.AddCommand(newPendingResolveCmd("reject", "Discard staged moves, leaving the files in place", false))
+
+	return cmd
+}
+
+// newPendingListCmd lists the staged moves along with the training status
+func newPendingListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List staged moves",
+		Run: func(cmd *cobra.Command, args []string) {
+			queue, store, ok := openPendingStores()
+			if !ok {
+				return
+			}
+
+			items, err := queue.List()
+			if err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error reading pending queue: %v", err)))
+				return
+			}
+
+			printTrainingStatus(queue)
+
+			if len(items) == 0 {
+				fmt.Println(infoText("No pending moves"))
+				return
+			}
+
+			for _, item := range items {
+				fmt.Printf("%s  %s\n", primaryText(item.ID), item.Path)
+				fmt.Printf("          → %s\n", item.Destination)
+				if item.Rule != "" {
+					fmt.Printf("          rule %s (confidence %.0f%%), staged %s (%s)\n",
+						item.Rule, store.Confidence(item.Rule)*100, item.Created.Format("2006-01-02 15:04"), item.Reason)
+				}
+			}
+		},
+	}
+}
+
+// newPendingResolveCmd approves or rejects the staged moves with the given IDs
+func newPendingResolveCmd(use, short string, approve bool) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   use + " [id...]",
+		Short: short,
+		Run: func(cmd *cobra.Command, args []string) {
+			queue, store, ok := openPendingStores()
+			if !ok {
+				return
+			}
+
+			ids := args
+			if all {
+				items, err := queue.List()
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error reading pending queue: %v", err)))
+					return
+				}
+				ids = nil
+				for _, item := range items {
+					ids = append(ids, item.ID)
+				}
+			}
+			if len(ids) == 0 {
+				fmt.Println(warningText("No pending moves given; pass IDs or --all"))
+				return
+			}
+
+			engine := organize.NewWithConfig(cfg)
+			engine.SetCollisionResolver(promptCollision)
+			for _, id := range ids {
+				var err error
+				if approve {
+					err = queue.Approve(id, engine.MoveFile, store)
+				} else {
+					err = queue.Reject(id, store)
+				}
+				if err != nil {
+					reportFileError(cmd.CommandPath(), err)
+					continue
+				}
+				fmt.Println(successText(fmt.Sprintf("✓ %sd %s", use, id)))
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Apply to every pending move")
+	return cmd
+}
+
+// openPendingStores opens the pending queue and the learning store
+func openPendingStores() (*pending.Queue, *learning.Store, bool) {
+	if cfg == nil {
+		fmt.Println(errorText("Configuration not loaded. Cannot read pending moves."))
+		return nil, nil, false
+	}
+
+	queue, err := pending.Open(pending.DefaultPath(cfg))
+	if err != nil {
+		fmt.Println(errorText(fmt.Sprintf("Error opening pending queue: %v", err)))
+		return nil, nil, false
+	}
+	store, err := learning.Open(learning.DefaultPath(cfg))
+	if err != nil {
+		fmt.Println(errorText(fmt.Sprintf("Error opening learning data: %v", err)))
+		return nil, nil, false
+	}
+	return queue, store, true
+}
+
+// printTrainingStatus reports how much of the training period remains
+func printTrainingStatus(queue *pending.Queue) {
+	training := cfg.Settings.Training
+	if !training.Enabled {
+		fmt.Println(infoText("Training period: disabled"))
+		return
+	}
+
+	started := training.Started
+	if started.IsZero() {
+		started = queue.TrainingStart()
+	}
+	if started.IsZero() {
+		fmt.Println(infoText("Training period: starts when the watch daemon first handles a file"))
+		return
+	}
+
+	ends := started.Add(training.Period())
+	if !training.ActiveAt(started, time.Now()) {
+		fmt.Println(infoText(fmt.Sprintf("Training period: ended %s", ends.Format("2006-01-02"))))
+		return
+	}
+	fmt.Println(warningText(fmt.Sprintf("Training period: active until %s", ends.Format("2006-01-02"))))
+}
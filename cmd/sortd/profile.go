@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sortd/internal/profile"
+
+	"github.com/spf13/cobra"
+)
+
+// profileStatus is a profile's status with what belongs to it, as 'sortd
+// profile status --json' prints it
+type profileStatus struct {
+	profile.Status
+	Patterns    []string `json:"patterns,omitempty"`
+	Directories []string `json:"directories,omitempty"`
+	Workflows   []string `json:"workflows,omitempty"`
+}
+
+// NewProfileCmd creates the profile command for config profiles
+func NewProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Show which config profiles are active",
+		Long: `Config profiles are contexts like docked at the desk or out with the
+laptop, defined under profiles by hostname, connected displays and mounted
+drives. Patterns, watch directories and workflows with a profile only apply
+while it is active.`,
+	}
+	cmd.AddCommand(newProfileStatusCmd())
+	return cmd
+}
+
+// newProfileStatusCmd creates the command showing what's active and why
+func newProfileStatusCmd() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show which profiles are active and why",
+		Long: `Detect this machine's context and show, for each profile, whether it is
+active, which of its conditions hold, and the patterns, watch directories and
+workflows that belong to it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil {
+				return fmt.Errorf("configuration not loaded")
+			}
+
+			var workflowProfiles []string
+			belongs := make(map[string]*profileStatus)
+			entry := func(name string) *profileStatus {
+				if belongs[name] == nil {
+					belongs[name] = &profileStatus{}
+				}
+				return belongs[name]
+			}
+			for _, pattern := range cfg.Organize.Patterns {
+				if pattern.Profile != "" {
+					e := entry(pattern.Profile)
+					e.Patterns = append(e.Patterns, pattern.Match+" → "+pattern.Target)
+				}
+			}
+			for _, dir := range cfg.WatchDirs() {
+				if dir.Profile != "" {
+					e := entry(dir.Profile)
+					e.Directories = append(e.Directories, dir.Path)
+				}
+			}
+			if manager := loadWorkflowManager(); manager != nil {
+				for _, wf := range manager.GetWorkflows() {
+					if wf.Profile != "" {
+						workflowProfiles = append(workflowProfiles, wf.Profile)
+						e := entry(wf.Profile)
+						e.Workflows = append(e.Workflows, wf.ID)
+					}
+				}
+			}
+
+			ctx := profile.Detect()
+			statuses := profile.Statuses(cfg, workflowProfiles, ctx)
+			results := make([]profileStatus, 0, len(statuses))
+			for _, status := range statuses {
+				result := profileStatus{Status: status}
+				if e := belongs[status.Name]; e != nil {
+					result.Patterns, result.Directories, result.Workflows = e.Patterns, e.Directories, e.Workflows
+				}
+				results = append(results, result)
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					return fmt.Errorf("error encoding profiles: %w", err)
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+
+			fmt.Println(infoText(describeContext(ctx)))
+			if len(results) == 0 {
+				fmt.Println(infoText("No profiles configured (profiles)"))
+				return nil
+			}
+			for _, result := range results {
+				printProfileStatus(result)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output the profiles in JSON format")
+	return cmd
+}
+
+// describeContext sums up what was detected, e.g. "thinkpad: 2 displays, 23 mounts"
+func describeContext(ctx profile.Context) string {
+	displays := "displays unknown"
+	if ctx.Displays >= 0 {
+		displays = fmt.Sprintf("%d displays", ctx.Displays)
+	}
+	mounts := "mounts unknown"
+	if ctx.Mounts != nil {
+		mounts = fmt.Sprintf("%d mounts", len(ctx.Mounts))
+	}
+	return fmt.Sprintf("%s: %s, %s", ctx.Hostname, displays, mounts)
+}
+
+// printProfileStatus prints whether a profile is active, why, and what belongs to it
+func printProfileStatus(result profileStatus) {
+	state := warningText("inactive")
+	if result.Active {
+		state = successText("active")
+	}
+	fmt.Printf("\n%s  %s\n", primaryText(result.Name), state)
+
+	switch {
+	case !result.Defined:
+		fmt.Println("  not defined under profiles, so always active")
+	case len(result.Checks) == 0:
+		fmt.Println("  no conditions, so always active")
+	}
+	for _, check := range result.Checks {
+		mark := errorText("✗")
+		if check.Met {
+			mark = successText("✓")
+		}
+		fmt.Printf("  %s %s\n", mark, check.Reason)
+	}
+
+	for _, list := range []struct {
+		label string
+		items []string
+	}{
+		{"patterns", result.Patterns},
+		{"watch directories", result.Directories},
+		{"workflows", result.Workflows},
+	} {
+		if len(list.items) > 0 {
+			fmt.Printf("  %s: %s\n", list.label, strings.Join(list.items, ", "))
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"sortd/internal/quota"
+
+	"github.com/spf13/cobra"
+)
+
+// NewQuotasCmd creates the quotas command showing how full limited destinations are
+func NewQuotasCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "quotas",
+		Short: "Show how full the destinations with quotas are",
+		Long: `Show what each directory in settings.quotas holds against its limits.
+
+A directory over its quota stops taking files from rules and workflows, or
+lets them through and notifies or runs a workflow on its oldest files,
+depending on the quota's action.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if cfg == nil {
+				fmt.Println(errorText("Configuration not loaded. Cannot check quotas."))
+				return
+			}
+
+			quotas := quota.New(cfg.Settings.Quotas)
+			limits := quotas.Limits()
+			if len(limits) == 0 {
+				fmt.Println(infoText("No quotas configured (settings.quotas)"))
+				return
+			}
+
+			for _, limit := range limits {
+				usage, err := quotas.Usage(limit)
+				if err != nil {
+					fmt.Printf("%s  %s\n", primaryText(limit.Path), errorText(fmt.Sprintf("error: %v", err)))
+					continue
+				}
+				status := successText("ok")
+				if limit.Exceeded(usage) {
+					status = warningText("over quota")
+				}
+				fmt.Printf("%s  %s\n", primaryText(limit.Path), status)
+				action := limit.Action()
+				if action == quota.ActionWorkflow {
+					action += " " + limit.Workflow
+				}
+				fmt.Printf("          %s; when over: %s\n", limit.Describe(usage), action)
+			}
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+
+	"sortd/internal/app"
+)
+
+// previewIfReadOnly turns a run into a dry run while read-only mode is on, so
+// the user sees what would happen instead of a failure for every file
+func previewIfReadOnly(service *app.Service) {
+	if !service.ReadOnly() || service.DryRun() {
+		return
+	}
+	service.SetDryRun(true)
+	fmt.Println(warningText(" Read-only mode is on; showing what would happen ('sortd daemon resume' to allow changes)"))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"sortd/internal/classify"
+
+	"github.com/spf13/cobra"
+)
+
+// NewReindexCmd creates the command that re-evaluates known files after rules change
+func NewReindexCmd() *cobra.Command {
+	var classifications bool
+	var dirs []string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "reindex",
+		Short: "Re-evaluate known files after classifications change",
+		Long: `Re-evaluate every file with a stored classification against the current
+classifications, update the stored matches, and report files whose
+classification changed. Files in directories given with --in are classified
+too. The watch daemon does this by itself in the background when it starts
+with edited classifications.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !classifications {
+				fmt.Println(warningText("Nothing to reindex; use --classifications"))
+				return
+			}
+			if cfg == nil {
+				fmt.Println(errorText("Configuration not loaded. Cannot reindex."))
+				return
+			}
+
+			evaluator, err := classify.New(cfg.Classifications)
+			if err != nil {
+				fmt.Println(errorText(err.Error()))
+				return
+			}
+			store, err := classify.OpenStore(cfg.DataFile(classify.StoreFile))
+			if err != nil {
+				fmt.Println(errorText(err.Error()))
+				return
+			}
+			defer store.Close()
+
+			paths, err := store.Paths()
+			if err != nil {
+				fmt.Println(errorText(err.Error()))
+				return
+			}
+			for _, dir := range dirs {
+				entries, err := os.ReadDir(dir)
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error reading %s: %v", dir, err)))
+					return
+				}
+				for _, entry := range entries {
+					if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+						paths = append(paths, filepath.Join(dir, entry.Name()))
+					}
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			result, err := classify.Reindex(ctx, evaluator, store, paths)
+			if err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Reindex stopped: %v", err)))
+				return
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error encoding results: %v", err)))
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			for _, change := range result.Changed {
+				fmt.Printf("  %s: %s → %s\n", change.Path, classificationLabel(change.From), classificationLabel(change.To))
+			}
+			fmt.Println(successText(fmt.Sprintf("Re-evaluated %d files: %d changed, %d new, %d unchanged, %d removed",
+				result.Evaluated, len(result.Changed), result.Added, result.Unchanged, result.Removed)))
+		},
+	}
+
+	cmd.Flags().BoolVar(&classifications, "classifications", false, "Re-evaluate file classifications")
+	cmd.Flags().StringSliceVar(&dirs, "in", nil, "Also classify the files in these directories")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output results as JSON")
+
+	return cmd
+}
+
+// classificationLabel names a classification, or says there is none
+func classificationLabel(name string) string {
+	if name == "" {
+		return "(none)"
+	}
+	return name
+}
@@ -12,9 +12,13 @@ import (
 )
 
 var (
-	cfgFile string
-	cfg     *config.Config
-	Version = "0.1.0" // Adding Version definition
+	cfgFile   string
+	overrides []string
+	cfg       *config.Config
+	Version   = "0.1.0" // Adding Version definition
+
+	// Why the config file couldn't be used, if it couldn't; cfg then holds the defaults
+	configLoadErr error
 )
 
 // Note: During the transition to the idiomatic approach, we use a factory pattern
@@ -42,47 +46,93 @@ func NewRootCmd() *cobra.Command {
 ` + successText("QUICK START:") + `
   • Run ` + emphasisText("sortd setup") + ` to configure your preferences
   • Use ` + emphasisText("sortd organize ~/Downloads") + ` to organize a directory
-  • Start ` + emphasisText("sortd") + ` without arguments to enter the TUI file browser
+  • List what's in a directory with ` + emphasisText("sortd scan ~/Downloads") + `
   • Try ` + emphasisText("sortd watch") + ` to automatically organize files as they arrive
   • Launch the GUI with ` + emphasisText("sortd gui") + `
 
-` + infoText("TIP:") + ` The TUI uses vim-style keybindings (j/k navigate, space selects files, ? for help)
+` + infoText("TIP:") + ` Preview any organize run with ` + emphasisText("--dry-run") + `
 			`
 			return logo
 		}(),
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// Scripts reading --errors json get the records and nothing else
+			switch errorsFormat {
+			case errorsText:
+			case errorsJSON:
+				cmd.Root().SilenceErrors = true
+				cmd.Root().SilenceUsage = true
+			default:
+				fmt.Fprintln(cmd.ErrOrStderr(), warningText(fmt.Sprintf("Unknown --errors format %q; using text", errorsFormat)))
+				errorsFormat = errorsText
+			}
+
 			// Check if we're in a test environment, but only skip interactive features
 			inTestMode := os.Getenv("TESTMODE") == "true"
 
-			// Check if gum is installed (skip in test mode)
-			if !inTestMode {
+			// Check if gum is installed (skip in test mode and headless). Warnings go
+			// to stderr so commands like 'sortd go' can be used in $(...)
+			headless := cmd.Name() == "serve" || cmd.Name() == "health"
+			if !inTestMode && !headless {
 				_, err := exec.LookPath("gum")
 				if err != nil {
-					fmt.Println(warningText("Gum is not installed! Some interactive features won't work."))
-					fmt.Println(infoText("Install Gum from https://github.com/charmbracelet/gum"))
+					fmt.Fprintln(cmd.ErrOrStderr(), warningText("Gum is not installed! Some interactive features won't work."))
+					fmt.Fprintln(cmd.ErrOrStderr(), infoText("Install Gum from https://github.com/charmbracelet/gum"))
 				}
 			}
 
+			// Keep the structured log the log viewers read
+			if !inTestMode {
+				startLogFile()
+			}
+
 			// Load config (always do this, even in test mode)
 			var configErr error
 			if cfgFile != "" {
+				// Commands that save the config write back to the same file
+				os.Setenv(config.ConfigFileEnv, cfgFile)
 				cfg, configErr = config.LoadConfigFile(cfgFile)
 			} else {
 				cfg, configErr = config.LoadConfig()
 			}
 
+			configLoadErr = configErr
 			if configErr != nil {
 				if !inTestMode {
-					fmt.Println(warningText(fmt.Sprintf("Warning: %v", configErr)))
-					fmt.Println(infoText("Using default settings. Run 'sortd setup' to configure."))
+					fmt.Fprintln(cmd.ErrOrStderr(), warningText(fmt.Sprintf("Warning: %v", configErr)))
+					fmt.Fprintln(cmd.ErrOrStderr(), infoText("Using default settings. Run 'sortd setup' to configure."))
 				}
 				cfg = config.New()
 			}
+
+			// --set overrides win over the file and the environment
+			for _, override := range overrides {
+				key, value, ok := strings.Cut(override, "=")
+				if !ok {
+					fmt.Fprintln(cmd.ErrOrStderr(), warningText(fmt.Sprintf("Ignoring --set %q: expected key=value", override)))
+					continue
+				}
+				if err := cfg.Set(key, value); err != nil {
+					fmt.Fprintln(cmd.ErrOrStderr(), warningText(fmt.Sprintf("Ignoring --set: %v", err)))
+				}
+			}
+			if len(overrides) > 0 {
+				if err := cfg.Validate(); err != nil {
+					fmt.Fprintln(cmd.ErrOrStderr(), warningText(fmt.Sprintf("Warning: --set made the configuration invalid: %v", err)))
+					configLoadErr = err
+				}
+			}
+
+			// Rules sending files back into watched directories stop the daemon
+			if !inTestMode && cmd.Name() != "doctor" {
+				warnFeedbackLoops(cmd)
+			}
 		},
 		Version: Version, // Add version to the root command
 	}
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/sortd/config.yaml)")
+	rootCmd.PersistentFlags().StringArrayVar(&overrides, "set", nil, "Override a config key, e.g. --set settings.dry_run=true (repeatable; see 'sortd config keys')")
+	rootCmd.PersistentFlags().StringVar(&errorsFormat, "errors", errorsText, "How to report errors: text, or json for one machine-readable record per line on stderr")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $SORTD_CONFIG or $XDG_CONFIG_HOME/sortd/config.yaml)")
 
 	// Add built-in commands from this file
 	rootCmd.AddCommand(NewSetupCmd())
@@ -95,7 +145,33 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.AddCommand(NewCloudCmd())
 	rootCmd.AddCommand(NewAnalyzeCmd())
 	rootCmd.AddCommand(NewScanCmd())
+	rootCmd.AddCommand(NewMapCmd())
+	rootCmd.AddCommand(NewAuditCmd())
 	rootCmd.AddCommand(NewConfirmCmd())
+	rootCmd.AddCommand(NewDigestCmd())
+	rootCmd.AddCommand(NewInboxCmd())
+	rootCmd.AddCommand(NewPendingCmd())
+	rootCmd.AddCommand(NewFailuresCmd())
+	rootCmd.AddCommand(NewQuotasCmd())
+	rootCmd.AddCommand(NewProfileCmd())
+	rootCmd.AddCommand(NewFocusCmd())
+	rootCmd.AddCommand(NewTriageCmd())
+	rootCmd.AddCommand(NewClassifyCmd())
+	rootCmd.AddCommand(NewReindexCmd())
+	rootCmd.AddCommand(NewOrganizeLastDownloadCmd())
+	rootCmd.AddCommand(NewBookmarkCmd())
+	rootCmd.AddCommand(NewGoCmd())
+	rootCmd.AddCommand(NewShellInitCmd())
+	rootCmd.AddCommand(NewIntegrateCmd())
+	rootCmd.AddCommand(NewConfigCmd())
+	rootCmd.AddCommand(NewServeCmd())
+	rootCmd.AddCommand(NewHealthCmd())
+	rootCmd.AddCommand(NewJournalCmd())
+	rootCmd.AddCommand(NewLogsCmd())
+	rootCmd.AddCommand(NewDebugCmd())
+	rootCmd.AddCommand(NewDoctorCmd())
+	rootCmd.AddCommand(NewVerifyCmd())
+	rootCmd.AddCommand(NewSelfUpdateCmd())
 
 	// Note: Commands defined in main.go will be added there
 
@@ -147,7 +223,7 @@ func NewThemeCmd() *cobra.Command {
 					// Check if gum is installed
 					if _, err := exec.LookPath("gum"); err != nil {
 						fmt.Println(errorText("Interactive mode requires gum to be installed."))
-						fmt.Println(infoText("Install Gum from https://github.com/charmbracelet/gum"))
+						fmt.Fprintln(cmd.ErrOrStderr(), infoText("Install Gum from https://github.com/charmbracelet/gum"))
 						return
 					}
 
@@ -7,6 +7,9 @@ import (
 	"strconv"
 	"strings"
 
+	"sortd/internal/config"
+	"sortd/internal/globs"
+
 	"github.com/spf13/cobra"
 )
 
@@ -27,6 +30,11 @@ func NewRulesCmd() *cobra.Command {
 	cmd.AddCommand(newRulesListCmd())
 	cmd.AddCommand(newRulesRemoveCmd())
 	cmd.AddCommand(newRulesTestCmd())
+	cmd.AddCommand(newRulesOrderCmd())
+	cmd.AddCommand(newRulesSuggestCmd())
+	cmd.AddCommand(newRulesImportCmd())
+	cmd.AddCommand(newRulesPresetCmd())
+	cmd.AddCommand(newRulesReplCmd())
 
 	return cmd
 }
@@ -52,10 +60,7 @@ func newRulesAddCmd() *cobra.Command {
 
 				// Add the rule to config
 				if cfg != nil {
-					cfg.Rules = append(cfg.Rules, struct {
-						Pattern string `yaml:"pattern"`
-						Target  string `yaml:"target"`
-					}{
+					cfg.Rules = append(cfg.Rules, config.Rule{
 						Pattern: pattern,
 						Target:  target,
 					})
@@ -136,10 +141,7 @@ func newRulesAddCmd() *cobra.Command {
 
 			// Add the rule to config
 			if cfg != nil {
-				cfg.Rules = append(cfg.Rules, struct {
-					Pattern string `yaml:"pattern"`
-					Target  string `yaml:"target"`
-				}{
+				cfg.Rules = append(cfg.Rules, config.Rule{
 					Pattern: pattern,
 					Target:  target,
 				})
@@ -282,10 +284,10 @@ func newRulesTestCmd() *cobra.Command {
 				return
 			}
 
-			// Check each rule
+			// Check each rule, in the order they are tried
 			matchFound := false
-			for i, rule := range cfg.Rules {
-				matched, err := filepath.Match(rule.Pattern, fileName)
+			for i, rule := range cfg.OrderedRules() {
+				matched, err := globs.Match(rule.Pattern, fileName)
 				if err != nil {
 					fmt.Println(errorText(fmt.Sprintf("Error in pattern %q: %v", rule.Pattern, err)))
 					continue
@@ -323,5 +325,8 @@ func listRules() {
 		fmt.Println(emphasisText(fmt.Sprintf("Rule %d:", i)))
 		fmt.Println("  Pattern: " + infoText(rule.Pattern))
 		fmt.Println("  Target:  " + infoText(rule.Target))
+		if rule.Priority != 0 {
+			fmt.Println("  Priority: " + infoText(strconv.Itoa(rule.Priority)))
+		}
 	}
 }
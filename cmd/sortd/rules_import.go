@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+
+	"sortd/internal/importer"
+
+	"github.com/spf13/cobra"
+)
+
+// newRulesImportCmd creates the 'rules import' command
+func newRulesImportCmd() *cobra.Command {
+	var (
+		format string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import rules from a CSV or JSON file",
+		Long: `Add organize patterns in bulk from a CSV or JSON file, e.g. a rule set
+brought over from another organizer such as Hazel, File Juggler or DropIt.
+
+CSV files need a header row. Each row needs a destination (or target/folder)
+and either a pattern (or match) or an extension; several extensions can
+share a cell ("jpg;png"). Optional columns are type (glob or regex),
+ignore_case, exclude and priority:
+
+  extension,destination
+  pdf,~/Documents/PDFs
+  jpg;jpeg;png,~/Pictures
+
+JSON files hold an array of objects with the same keys, or that array under
+"rules". Patterns already configured with the same target are skipped.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if cfg == nil {
+				fmt.Println(errorText("Configuration not loaded. Cannot import rules."))
+				return
+			}
+
+			patterns, err := importer.Rules(args[0], format)
+			if err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error importing %s: %v", args[0], err)))
+				return
+			}
+
+			existing := make(map[string]bool, len(cfg.Organize.Patterns))
+			for _, pattern := range cfg.Organize.Patterns {
+				existing[pattern.Match+"\x00"+pattern.Target] = true
+			}
+
+			added, skipped := 0, 0
+			for _, pattern := range patterns {
+				key := pattern.Match + "\x00" + pattern.Target
+				if existing[key] {
+					skipped++
+					continue
+				}
+				existing[key] = true
+				cfg.Organize.Patterns = append(cfg.Organize.Patterns, pattern)
+				added++
+				fmt.Printf("  %s → %s\n", primaryText(pattern.Match), pattern.Target)
+			}
+
+			if added == 0 {
+				fmt.Println(infoText(fmt.Sprintf("Nothing to import (%d rules already configured)", skipped)))
+				return
+			}
+			summary := fmt.Sprintf("%d rules imported", added)
+			if skipped > 0 {
+				summary += fmt.Sprintf(", %d already configured", skipped)
+			}
+			if dryRun {
+				fmt.Println(infoText("[DRY RUN] " + summary + "; nothing saved"))
+				return
+			}
+
+			if err := cfg.Validate(); err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Imported rules make the configuration invalid: %v", err)))
+				return
+			}
+			if err := cfg.Save(); err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error saving config: %v", err)))
+				return
+			}
+			fmt.Println(successText("✓ " + summary))
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "", "File format: csv or json (default: from the file extension)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the rules that would be imported without saving them")
+	return cmd
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// newRulesOrderCmd creates the 'rules order' command
+func newRulesOrderCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "order",
+		Short: "Show the order patterns and rules are tried in",
+		Long: `Show the effective precedence of patterns and rules. The first one that
+matches a file decides where it goes. Entries with a higher priority are tried
+first; entries with the same priority are tried in the order they appear in the
+config file.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			patterns, rules := cfg.Organize.Patterns, cfg.Rules
+			if len(patterns) == 0 && len(rules) == 0 {
+				fmt.Println(infoText("No patterns or rules defined"))
+				return
+			}
+
+			if len(patterns) > 0 {
+				fmt.Println(primaryText("Patterns (organize and watch):"))
+				order := precedence(len(patterns), func(i int) int { return patterns[i].Priority })
+				for n, i := range order {
+					p := patterns[i]
+					fmt.Printf("  %2d. %-32s → %s%s\n", n+1, p.Match, p.Target, orderNote(i, p.Priority))
+				}
+			}
+
+			if len(rules) > 0 {
+				fmt.Println(primaryText("Rules (rules test):"))
+				order := precedence(len(rules), func(i int) int { return rules[i].Priority })
+				for n, i := range order {
+					r := rules[i]
+					fmt.Printf("  %2d. %-32s → %s%s\n", n+1, r.Pattern, r.Target, orderNote(i, r.Priority))
+				}
+			}
+		},
+	}
+}
+
+// precedence returns the indexes of n entries in the order they are tried,
+// the same stable priority order organize.OrderPatterns and OrderedRules use
+func precedence(n int, priority func(int) int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return priority(order[a]) > priority(order[b])
+	})
+	return order
+}
+
+// orderNote says where an entry sits in the config file and its priority
+func orderNote(index, priority int) string {
+	note := fmt.Sprintf("  (#%d in config", index+1)
+	if priority != 0 {
+		note += fmt.Sprintf(", priority %d", priority)
+	}
+	return infoText(note + ")")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"sortd/internal/organize"
+	"sortd/pkg/types"
+
+	"github.com/spf13/cobra"
+)
+
+// newRulesPresetCmd creates the 'rules preset' command
+func newRulesPresetCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "preset [NAME]",
+		Short: "List or add built-in rule presets",
+		Long: `Add a built-in set of organize patterns, or list the presets without a name.
+
+The developer preset routes ISO, dmg and AppImage installers into Dev, release
+archives and downloaded binaries into a folder per tool (Dev/Tools/go), and
+source tarballs into Dev/Source. Checksum and signature files such as
+SHA256SUMS or foo.iso.asc go wherever their file goes, and nothing inside
+build outputs like node_modules, target or dist is touched.
+
+Patterns already configured with the same target are skipped.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				for _, preset := range organize.Presets() {
+					fmt.Printf("%s  %s\n", primaryText(preset.Name), preset.Description)
+				}
+				return
+			}
+			if cfg == nil {
+				fmt.Println(errorText("Configuration not loaded. Cannot add a preset."))
+				return
+			}
+
+			preset, ok := organize.PresetNamed(args[0])
+			if !ok {
+				fmt.Println(errorText(fmt.Sprintf("No preset named %q; run 'sortd rules preset' to list them", args[0])))
+				return
+			}
+
+			before := cfg.Organize.Patterns
+			cfg.Organize.Patterns = append([]types.Pattern(nil), before...)
+			added := organize.AddPreset(cfg, preset)
+			for _, pattern := range cfg.Organize.Patterns[len(before):] {
+				fmt.Printf("  %s → %s\n", primaryText(pattern.Match), pattern.Target)
+			}
+
+			if added == 0 {
+				fmt.Println(infoText(fmt.Sprintf("The %s preset is already configured", preset.Name)))
+				return
+			}
+			summary := fmt.Sprintf("%d rules added from the %s preset", added, preset.Name)
+			if dryRun {
+				cfg.Organize.Patterns = before
+				fmt.Println(infoText("[DRY RUN] " + summary + "; nothing saved"))
+				return
+			}
+
+			if err := cfg.Validate(); err != nil {
+				cfg.Organize.Patterns = before
+				fmt.Println(errorText(fmt.Sprintf("The preset makes the configuration invalid: %v", err)))
+				return
+			}
+			if err := cfg.Save(); err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error saving config: %v", err)))
+				return
+			}
+			fmt.Println(successText("✓ " + summary))
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the rules that would be added without saving them")
+	return cmd
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/logview"
+	"sortd/internal/organize"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+	"sortd/pkg/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+// newRulesReplCmd creates the 'rules repl' command
+func newRulesReplCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "repl",
+		Short: "Type file names and see which rule or workflow would handle them",
+		Long: `Type a file name or path and see what the watch daemon would do with it:
+whether the watch filters let it through, which workflows would act and what
+they would do (placeholders filled in), which pattern applies and where the file
+would end up, and why everything else didn't match. Nothing is moved.
+
+Bare names are taken to be in --dir (the first watch directory by default).
+Files don't have to exist, but conditions on size, age, tags or metadata can
+only be checked for files that do.
+
+Commands: :reload re-reads the config and workflows after you edit them,
+:logs [level] [text] shows the latest log entries, e.g. ":logs warn report.pdf"
+to see why a file wasn't organized, :quit (or Ctrl+D) exits.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				dir = replDefaultDir(cfg)
+			}
+			session, err := newReplSession(cfg, dir)
+			if err != nil {
+				return err
+			}
+
+			prompt := isTerminal(os.Stdin)
+			if prompt {
+				fmt.Println(infoText(fmt.Sprintf("Testing against %s. Type a file name, :reload, :logs or :quit.", dir)))
+			}
+			return session.run(os.Stdin, os.Stdout, prompt)
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "", "Directory bare file names are taken to be in")
+	return cmd
+}
+
+// replSession holds the rules and workflows a REPL tests against
+type replSession struct {
+	cfg     *config.Config
+	dir     string
+	engine  *organize.Engine
+	manager *workflow.Manager
+}
+
+// newReplSession loads the patterns and workflows to test
+func newReplSession(cfg *config.Config, dir string) (*replSession, error) {
+	s := &replSession{cfg: cfg, dir: dir}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load (re)builds the engine and workflow manager from s.cfg
+func (s *replSession) load() error {
+	s.engine = organize.NewWithConfig(s.cfg)
+
+	workflowsDir, err := workflow.DefaultDir()
+	if err != nil {
+		return err
+	}
+	manager, err := workflow.NewManager(workflowsDir)
+	if err != nil {
+		return fmt.Errorf("failed to load workflows: %w", err)
+	}
+	manager.SetMetadata(watch.WorkflowMetadata(s.cfg))
+	s.manager = manager
+	return nil
+}
+
+// run reads file names until EOF or :quit
+func (s *replSession) run(in io.Reader, out io.Writer, prompt bool) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		if prompt {
+			fmt.Fprint(out, primaryText("rules> "))
+		}
+		if !scanner.Scan() {
+			if prompt {
+				fmt.Fprintln(out)
+			}
+			return scanner.Err()
+		}
+
+		input := strings.TrimSpace(scanner.Text())
+		switch input {
+		case "":
+			continue
+		case ":q", ":quit", ":exit":
+			return nil
+		case ":reload":
+			if err := s.reload(); err != nil {
+				fmt.Fprintln(out, errorText(fmt.Sprintf("Reload failed: %v", err)))
+			} else {
+				fmt.Fprintln(out, successText("Reloaded config and workflows"))
+			}
+			continue
+		}
+
+		if input == ":logs" || strings.HasPrefix(input, ":logs ") {
+			s.logs(out, strings.Fields(input)[1:])
+			continue
+		}
+
+		s.explain(out, input)
+	}
+}
+
+// replLogEntries is how many log entries :logs shows
+const replLogEntries = 20
+
+// logs shows the latest log entries for :logs. A first argument naming a
+// level filters by it; the rest is text to search for.
+func (s *replSession) logs(out io.Writer, args []string) {
+	path, err := logview.DefaultPath()
+	if err != nil {
+		fmt.Fprintln(out, errorText(fmt.Sprintf("Failed to find the log: %v", err)))
+		return
+	}
+
+	var filter logview.Filter
+	if len(args) > 0 {
+		for _, level := range logview.Levels {
+			if strings.EqualFold(args[0], level) {
+				filter.Level = level
+				args = args[1:]
+				break
+			}
+		}
+	}
+	filter.Search = strings.Join(args, " ")
+
+	if err := showLogs(context.Background(), out, path, filter, replLogEntries, false); err != nil {
+		fmt.Fprintln(out, errorText(err.Error()))
+	}
+}
+
+// reload re-reads the config file and workflows
+func (s *replSession) reload() error {
+	var fresh *config.Config
+	var err error
+	if path := os.Getenv(config.ConfigFileEnv); path != "" {
+		fresh, err = config.LoadConfigFile(path)
+	} else {
+		fresh, err = config.LoadConfig()
+	}
+	if err != nil {
+		return err
+	}
+	s.cfg = fresh
+	return s.load()
+}
+
+// explain prints what the watch daemon would do with a file
+func (s *replSession) explain(out io.Writer, input string) {
+	path := input
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.dir, path)
+	}
+
+	where := path
+	if _, err := os.Stat(path); err != nil {
+		where += ", not on disk"
+	}
+	fmt.Fprintf(out, "%s  %s\n", emphasisText(filepath.Base(path)), infoText("("+where+")"))
+
+	if reason := watch.IgnoreReason(s.cfg, path); reason != "" {
+		fmt.Fprintln(out, warningText("  Ignored by the watcher: "+reason))
+		fmt.Fprintln(out)
+		return
+	}
+
+	// Workflows go first; any that acts (and isn't a shadow) keeps patterns out
+	handledBy := ""
+	explanations := s.manager.Explain(path, time.Now())
+	if len(explanations) > 0 {
+		fmt.Fprintln(out, "  Workflows:")
+	}
+	for _, e := range explanations {
+		label := fmt.Sprintf("%s (%s)", e.Workflow.Name, e.Workflow.ID)
+		if !e.Matched {
+			fmt.Fprintf(out, "    %s %s: %s\n", errorText("✗"), label, e.Reason)
+			continue
+		}
+
+		shadow := e.Workflow.Mode == types.ShadowMode
+		note := ""
+		if shadow {
+			note = " (shadow: only observes)"
+		}
+		fmt.Fprintf(out, "    %s %s%s\n", successText("✓"), label, note)
+		for _, action := range e.Actions {
+			fmt.Fprintf(out, "        %s\n", action)
+		}
+		if !shadow && handledBy == "" {
+			handledBy = fmt.Sprintf("workflow %s handles it", e.Workflow.ID)
+		}
+	}
+
+	matches := s.engine.ExplainPatterns(path)
+	switch {
+	case len(matches) == 0:
+	case handledBy != "":
+		fmt.Fprintln(out, "  Patterns (not consulted, a workflow acts first):")
+	default:
+		fmt.Fprintln(out, "  Patterns:")
+	}
+	for i, m := range matches {
+		rule := fmt.Sprintf("%d  %s → %s", i+1, m.Pattern.Match, m.Pattern.Target)
+		switch {
+		case m.Applies:
+			fmt.Fprintf(out, "    %s %s  ⇒ %s\n", successText("✓"), rule, m.Destination)
+			if handledBy == "" {
+				handledBy = fmt.Sprintf("pattern %d moves it to %s", i+1, m.Destination)
+			}
+		case m.Matched:
+			fmt.Fprintf(out, "    %s %s: %s\n", warningText("·"), rule, m.Reason)
+		default:
+			fmt.Fprintf(out, "    %s %s: %s\n", errorText("✗"), rule, m.Reason)
+		}
+	}
+
+	if handledBy == "" {
+		handledBy = "nothing; the file stays where it is"
+	}
+	fmt.Fprintln(out, "  Result: "+primaryText(handledBy))
+	fmt.Fprintln(out)
+}
+
+// replDefaultDir is where bare file names are assumed to arrive
+func replDefaultDir(cfg *config.Config) string {
+	if dirs := cfg.WatchPaths(); len(dirs) > 0 {
+		return dirs[0]
+	}
+	if cfg.Directories.Default != "" {
+		if abs, err := filepath.Abs(cfg.Directories.Default); err == nil {
+			return abs
+		}
+	}
+	wd, _ := os.Getwd()
+	return wd
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
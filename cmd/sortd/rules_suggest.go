@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"sortd/internal/learning"
+
+	"github.com/spf13/cobra"
+)
+
+// newRulesSuggestCmd creates the 'rules suggest' command
+func newRulesSuggestCmd() *cobra.Command {
+	var apply bool
+
+	cmd := &cobra.Command{
+		Use:   "suggest",
+		Short: "Suggest rule changes learned from your corrections",
+		Long: `When you move a file out of the directory sortd organized it into, the watch
+daemon records a correction and lowers the confidence of the pattern responsible.
+Once files matching a pattern have been moved to the same directory several
+times, this command suggests retargeting the pattern there. Use --apply to
+update the configuration.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if cfg == nil {
+				fmt.Println(errorText("Configuration not loaded. Cannot read learning data."))
+				return
+			}
+
+			store, err := learning.Open(learning.DefaultPath(cfg))
+			if err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error opening learning data: %v", err)))
+				return
+			}
+
+			suggestions := store.Suggestions()
+			if len(suggestions) == 0 {
+				fmt.Println(infoText(fmt.Sprintf("No suggestions yet (%d corrections recorded)", len(store.Corrections()))))
+				return
+			}
+
+			changed := false
+			for _, suggestion := range suggestions {
+				fmt.Println(primaryText(suggestion.Rule) + fmt.Sprintf("  (confidence %.0f%%)", suggestion.Confidence*100))
+				fmt.Println("  " + infoText(suggestion.String()))
+
+				if !apply {
+					continue
+				}
+				for i := range cfg.Organize.Patterns {
+					if cfg.Organize.Patterns[i].Match == suggestion.Rule {
+						cfg.Organize.Patterns[i].Target = suggestion.Directory
+						changed = true
+						fmt.Println(successText(fmt.Sprintf("  ✓ Target changed to %s", suggestion.Directory)))
+					}
+				}
+			}
+
+			if changed {
+				if err := cfg.Save(); err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error saving config: %v", err)))
+					return
+				}
+			} else if !apply {
+				fmt.Println(infoText("\nRun 'sortd rules suggest --apply' to retarget these patterns"))
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&apply, "apply", false, "Change each pattern's target to the suggested directory")
+	return cmd
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"sortd/internal/analysis"
 	"sortd/internal/config"
+	"sortd/internal/organize"
+	"sortd/pkg/types"
 
 	"github.com/spf13/cobra"
 )
@@ -16,30 +21,33 @@ func NewScanCmd() *cobra.Command {
 	var detailedScan bool
 
 	cmd := &cobra.Command{
-		Use:   "scan [file]",
+		Use:   "scan [file|directory]",
 		Short: "Scan a file for basic information",
-		Long:  `Scan a file to get its type, size, and other basic metadata.`,
-		Args:  cobra.MinimumNArgs(1),
+		Long: `Scan a file to get its type, size, and other basic metadata. Given a
+directory, every entry in it is scanned and printed as soon as it is done (one
+JSON object per line with --json); Ctrl+C stops the scan.`,
+		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			path := args[0]
 
 			// Check if file exists
-			_, err := os.Stat(path)
+			info, err := os.Stat(path)
 			if err != nil {
 				fmt.Println(errorText(fmt.Sprintf("Error: %v", err)))
 				return
 			}
 
-			// Create a new analysis engine
-			engine := analysis.New()
+			// Create an analysis engine configured like the rest of sortd,
+			// falling back to the defaults if no configuration is loaded
+			scanCfg := cfg
+			if scanCfg == nil {
+				scanCfg = config.New()
+			}
+			engine := analysis.NewWithConfig(scanCfg)
 
-			// If we have a configuration, set it
-			if cfg != nil {
-				engine.SetConfig(cfg)
-			} else {
-				// Create a default config if none is available
-				defaultCfg := config.New()
-				engine.SetConfig(defaultCfg)
+			if info.IsDir() {
+				scanDirectory(cmd.Context(), engine, path, jsonOutput)
+				return
 			}
 
 			// Scan the file
@@ -64,3 +72,39 @@ func NewScanCmd() *cobra.Command {
 
 	return cmd
 }
+
+// scanDirectory streams the entries of a directory as they are scanned
+func scanDirectory(ctx context.Context, engine *analysis.Engine, dir string, jsonOutput bool) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	errLimit := errors.New("file limit reached")
+	limit := organize.LimitsFromConfig(cfg).MaxFiles
+	count := 0
+	err := engine.ScanDirectoryFunc(ctx, dir, func(info *types.FileInfo) error {
+		if limit >= 0 && count >= limit {
+			return errLimit
+		}
+		count++
+		if jsonOutput {
+			fmt.Println(info.ToJSON())
+			return nil
+		}
+		size := fmt.Sprintf("%d bytes", info.Size)
+		if info.IsDir {
+			size = "directory"
+		}
+		fmt.Printf("%-40s %-32s %s\n", info.Name(), info.ContentType, size)
+		return nil
+	})
+	switch {
+	case errors.Is(err, errLimit):
+		fmt.Fprintln(os.Stderr, warningText(fmt.Sprintf("Truncated at %d entries (settings.max_files)", limit)))
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintln(os.Stderr, warningText(fmt.Sprintf("Scan stopped after %d entries", count)))
+	case err != nil:
+		fmt.Println(errorText(fmt.Sprintf("Error scanning directory: %v", err)))
+	case !jsonOutput:
+		fmt.Println(infoText(fmt.Sprintf("%d entries scanned", count)))
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"sortd/internal/update"
+
+	"github.com/spf13/cobra"
+)
+
+// NewSelfUpdateCmd creates the self-update command
+func NewSelfUpdateCmd() *cobra.Command {
+	var channel string
+	var check bool
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update sortd to the latest release",
+		Long: `Download the latest sortd release for this platform, verify it against the
+release's SHA256 checksums and replace the running binary with it.
+
+The stable channel only offers full releases; beta offers pre-releases too.
+Set $SORTD_UPDATE_URL to use a mirror of the release list.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			updater := update.New()
+
+			fmt.Println(infoText(fmt.Sprintf("Checking for %s releases...", channel)))
+			release, err := updater.Latest(cmd.Context(), channel)
+			if err != nil {
+				return err
+			}
+
+			if !update.Newer(release.Version(), Version) && !force {
+				fmt.Println(successText(fmt.Sprintf("sortd %s is up to date (latest %s release: %s)", Version, channel, release.Version())))
+				return nil
+			}
+			fmt.Printf("Update available: %s -> %s\n", Version, release.Version())
+			if check {
+				return nil
+			}
+
+			exe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("can't find the running binary: %w", err)
+			}
+
+			fmt.Println(infoText(fmt.Sprintf("Downloading %s...", update.AssetName(runtime.GOOS, runtime.GOARCH))))
+			binary, err := updater.Download(cmd.Context(), release)
+			if err != nil {
+				return err
+			}
+			if err := update.Replace(exe, binary); err != nil {
+				return fmt.Errorf("failed to replace %s: %w", exe, err)
+			}
+
+			fmt.Println(successText(fmt.Sprintf("Updated sortd to %s (checksum verified)", release.Version())))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&channel, "channel", update.ChannelStable, "Release channel: stable or beta")
+	cmd.Flags().BoolVar(&check, "check", false, "Only report whether an update is available")
+	cmd.Flags().BoolVar(&force, "force", false, "Reinstall even when already up to date")
+
+	return cmd
+}
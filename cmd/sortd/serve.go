@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"sortd/internal/httpsec"
+	sortdlog "sortd/internal/log"
+	"sortd/internal/watch"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewServeCmd creates the headless watch command used in containers and
+// service managers.
+//
+// Headless mode reads its config from the usual file (mount one and point
+// SORTD_CONFIG at it) layered with SORTD_* variables, logs JSON to stdout,
+// never prompts, serves watch_mode.health_listen if set, and on SIGTERM stops
+// taking events and lets the workers finish the files they hold before
+// exiting. Volumes mounted from macOS or Windows hosts don't deliver change
+// events; set SORTD_WATCH_MODE_BACKEND=poll for those.
+func NewServeCmd() *cobra.Command {
+	var textLogs bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the watch daemon headless (for Docker and service managers)",
+		Long: `Run the watch daemon in the foreground without any interactive output:
+logs are JSON lines on stdout, SIGTERM shuts down gracefully, and a health
+endpoint is served when watch_mode.health_listen is set.
+
+  docker run -v ~/Downloads:/watch -e SORTD_WATCH_DIRECTORIES=/watch \
+    -e SORTD_WATCH_MODE_BACKEND=poll sortd`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !textLogs {
+				configureJSONLogs(os.Stdout)
+			}
+			os.Setenv("SORTD_NON_INTERACTIVE", "true")
+
+			if len(cfg.WatchPaths()) == 0 {
+				return fmt.Errorf("no watch directories configured; set watch_directories or SORTD_WATCH_DIRECTORIES")
+			}
+
+			daemon, err := watch.NewDaemon(cfg)
+			if err != nil {
+				return err
+			}
+			if cfg.Directories.Default != "" {
+				daemon.SetStatsFile(watch.StatsFilePath(cfg))
+				daemon.SetActivityFile(watch.ActivityFilePath(cfg))
+			}
+			daemon.SetDryRun(cfg.Settings.DryRun)
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := daemon.Start(); err != nil {
+				return err
+			}
+			log.WithFields(log.Fields{
+				"watch_directories": cfg.WatchPaths(),
+				"dry_run":           cfg.Settings.DryRun,
+			}).Info("sortd serving")
+
+			<-ctx.Done()
+			log.Info("Shutdown requested, finishing in-flight files")
+			daemon.Stop()
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&textLogs, "text-logs", false, "Log human-readable text instead of JSON")
+
+	return cmd
+}
+
+// configureJSONLogs sends both loggers' output to w as JSON lines
+func configureJSONLogs(w io.Writer) {
+	log.SetFormatter(&log.JSONFormatter{})
+	log.SetOutput(w)
+	sortdlog.Configure(sortdlog.WithJSON(), sortdlog.WithOutput(w))
+}
+
+// NewHealthCmd creates the command that probes a running daemon's health
+// endpoint, for Docker HEALTHCHECK and similar
+func NewHealthCmd() *cobra.Command {
+	var addr string
+	var certFile, keyFile string
+
+	cmd := &cobra.Command{
+		Use:   "health",
+		Short: "Check a running 'sortd serve' through its health endpoint",
+		Long: `Request the health endpoint of a running daemon and exit non-zero unless it
+reports healthy. The address defaults to watch_mode.health_listen, and the
+token and TLS settings come from watch_mode.http. When the daemon requires
+client certificates, pass one with --cert and --key.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			settings := cfg.WatchMode.HTTP
+			if addr == "" {
+				addr = cfg.WatchMode.HealthListen
+			}
+			if addr == "" {
+				return fmt.Errorf("no health endpoint; set watch_mode.health_listen or --addr")
+			}
+			host, port, err := net.SplitHostPort(httpsec.Addr(addr, settings.Bind))
+			if err != nil {
+				return fmt.Errorf("invalid health address %q: %w", addr, err)
+			}
+			if host == "0.0.0.0" || host == "::" {
+				host = "127.0.0.1"
+			}
+
+			client := &http.Client{Timeout: 5 * time.Second}
+			if settings.TLS {
+				tlsConfig, err := httpsec.ClientTLS(settings, cfg.StatePath(), certFile, keyFile)
+				if err != nil {
+					return err
+				}
+				client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+			}
+
+			url := httpsec.Scheme(settings) + "://" + net.JoinHostPort(host, port) + watch.HealthPath
+			req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			if settings.Token != "" {
+				req.Header.Set("Authorization", "Bearer "+settings.Token)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			fmt.Print(string(body))
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("daemon unhealthy: %s", resp.Status)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "", "Health endpoint address (default: watch_mode.health_listen)")
+	cmd.Flags().StringVar(&certFile, "cert", "", "Client certificate to present when the daemon requires one")
+	cmd.Flags().StringVar(&keyFile, "key", "", "Private key of the client certificate")
+
+	return cmd
+}
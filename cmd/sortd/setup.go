@@ -9,6 +9,7 @@ import (
 	"strings"
 	"syscall"
 
+	"sortd/cmd/sortd/cli"
 	"sortd/internal/config"
 
 	"github.com/spf13/cobra"
@@ -17,16 +18,35 @@ import (
 // NewSetupCmd creates the setup command
 func NewSetupCmd() *cobra.Command {
 	var dryRun bool
+	var quick, yes bool
 
 	cmd := &cobra.Command{
-		Use:   "setup",
+		Use:   "setup [directory]",
 		Short: "Interactive setup wizard for sortd",
-		Long:  `A fun, interactive setup wizard to configure sortd with your preferences.`,
+		Long: `A fun, interactive setup wizard to configure sortd with your preferences.
+
+With --quick, sortd looks at the most common file extensions in a directory
+(the default directory unless one is given) and suggests where each should go.
+Edit the suggestions on one screen and they become rules.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			if quick {
+				dir := ""
+				if len(args) > 0 {
+					dir = args[0]
+				}
+				if err := runQuickSetup(dir, yes); err != nil {
+					fmt.Println(errorText(err.Error()))
+					os.Exit(1)
+				}
+				return
+			}
+
 			// Handle dry run mode
 			if dryRun {
 				fmt.Println(infoText("Dry run: This would configure your sortd configuration"))
-				fmt.Println(infoText("Configuration would be saved to: " + filepath.Join(getHomeDir(), ".config", "sortd", "config.yaml")))
+				configPath, _ := config.ConfigPath()
+				fmt.Println(infoText("Configuration would be saved to: " + configPath))
 				return
 			}
 
@@ -142,14 +162,14 @@ func NewSetupCmd() *cobra.Command {
 					watchDir = filepath.Clean(watchDir)
 					exists := false
 					for _, dir := range newConfig.Directories.Watch {
-						if dir == watchDir {
+						if dir.Path == watchDir {
 							exists = true
 							break
 						}
 					}
 
 					if !exists {
-						newConfig.Directories.Watch = append(newConfig.Directories.Watch, watchDir)
+						newConfig.Directories.Watch = append(newConfig.Directories.Watch, config.WatchDir{Path: watchDir})
 						fmt.Println(successText("Added directory: " + watchDir))
 					} else {
 						fmt.Println(warningText("Directory already added: " + watchDir))
@@ -159,6 +179,10 @@ func NewSetupCmd() *cobra.Command {
 						addMoreDirs = runGumConfirm("Add another directory to watch?")
 					}
 				}
+
+				// Configure how the watched directories are followed
+				fmt.Println(infoText("\n⏱  Configure watching:"))
+				newConfig.WatchMode.WatchSettings = cli.AskWatchSettings()
 			}
 
 			// Add rules - enhanced interactive rule builder
@@ -221,10 +245,7 @@ func NewSetupCmd() *cobra.Command {
 				fmt.Println("  Target:  " + primaryText(target))
 
 				if runGumConfirm("Add this rule?") {
-					newConfig.Rules = append(newConfig.Rules, struct {
-						Pattern string `yaml:"pattern"`
-						Target  string `yaml:"target"`
-					}{
+					newConfig.Rules = append(newConfig.Rules, config.Rule{
 						Pattern: pattern,
 						Target:  target,
 					})
@@ -238,9 +259,11 @@ func NewSetupCmd() *cobra.Command {
 			}
 
 			// Save config
-			configDir := filepath.Join(getHomeDir(), ".config", "sortd")
-			os.MkdirAll(configDir, 0755)
-			configPath := filepath.Join(configDir, "config.yaml")
+			configPath, err := config.ConfigPath()
+			if err != nil {
+				fmt.Printf(errorText("Error locating config: %v"), err)
+				os.Exit(1)
+			}
 
 			// Save the configuration using the Save method
 			if err := newConfig.Save(); err != nil {
@@ -275,6 +298,8 @@ func NewSetupCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be done without making changes")
+	cmd.Flags().BoolVar(&quick, "quick", false, "Map the most common extensions to destinations in one step")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "With --quick, add the suggested rules without asking")
 
 	return cmd
 }
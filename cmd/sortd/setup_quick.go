@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"sortd/internal/organize"
+)
+
+// runQuickSetup maps the most common extensions in a directory to destinations
+// and turns the mapping into patterns. With gum the whole mapping is edited on
+// one screen; with --yes the suggestions are taken as they are.
+func runQuickSetup(dir string, yes bool) error {
+	if dir == "" {
+		dir = cfg.Directories.Default
+	}
+
+	exts, err := organize.TopExtensions(dir, organize.QuickSetupLimit)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	if len(exts) == 0 {
+		fmt.Println(infoText("No files with extensions found in " + dir))
+		return nil
+	}
+
+	fmt.Println(primaryText(fmt.Sprintf("Top %d extensions in %s:", len(exts), dir)))
+	for _, ext := range exts {
+		dest := ext.Destination
+		if dest == "" {
+			dest = "(leave alone)"
+		}
+		fmt.Printf("  %-10s %5d files  → %s\n", ext.Extension, ext.Count, dest)
+	}
+
+	_, gumErr := exec.LookPath("gum")
+	interactive := !yes && gumErr == nil && !isNonInteractive() && os.Getenv("TESTMODE") != "true"
+	if !yes && !interactive {
+		fmt.Println(infoText("\nRun again with --yes to add these rules, or install gum to edit them first."))
+		return nil
+	}
+
+	if interactive {
+		edited, ok := runGumWrite("One extension per line: extension destination. Delete a line or its destination to skip it.", quickSetupText(exts))
+		if !ok {
+			fmt.Println(infoText("Quick setup cancelled. No changes were made."))
+			return nil
+		}
+		exts = parseQuickSetupText(edited, exts)
+	}
+
+	changed := organize.AddExtensionPatterns(cfg, exts)
+	if changed == 0 {
+		fmt.Println(infoText("Your rules already cover these extensions."))
+		return nil
+	}
+	if interactive && !runGumConfirm(fmt.Sprintf("Add or update %d rules?", changed)) {
+		fmt.Println(infoText("Quick setup cancelled. No changes were made."))
+		return nil
+	}
+
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Println(successText(fmt.Sprintf("✓ Added or updated %d rules. Try 'sortd organize %s --dry-run'", changed, dir)))
+	return nil
+}
+
+// quickSetupText renders the mapping for editing, one "extension destination" per line
+func quickSetupText(exts []organize.ExtensionCount) string {
+	var b strings.Builder
+	for _, ext := range exts {
+		fmt.Fprintf(&b, "%-10s %s\n", ext.Extension, ext.Destination)
+	}
+	return b.String()
+}
+
+// parseQuickSetupText reads an edited mapping back. Lines for extensions that
+// weren't offered are ignored, as are removed lines and empty destinations.
+func parseQuickSetupText(text string, offered []organize.ExtensionCount) []organize.ExtensionCount {
+	byExt := make(map[string]organize.ExtensionCount, len(offered))
+	for _, ext := range offered {
+		byExt[ext.Extension] = ext
+	}
+
+	var result []organize.ExtensionCount
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		ext, ok := byExt[strings.ToLower(strings.TrimPrefix(fields[0], "."))]
+		if !ok {
+			continue
+		}
+		ext.Destination = strings.Join(fields[1:], " ")
+		result = append(result, ext)
+	}
+	return result
+}
+
+// runGumWrite opens gum's multi-line editor with a starting value. It reports
+// false when the user cancels.
+func runGumWrite(header, value string) (string, bool) {
+	cmd := exec.Command("gum", "write",
+		"--header", header,
+		"--value", value,
+		"--width", "70",
+		"--height", "14",
+		"--show-line-numbers")
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
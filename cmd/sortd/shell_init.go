@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sortd/internal/atomicfile"
+
+	"github.com/spf13/cobra"
+)
+
+// shellSnippets are printed by 'sortd shell-init <target>'. The shell wrappers
+// let 'sortd go <bookmark>' change the calling shell's directory, which a
+// child process can't do on its own.
+var shellSnippets = map[string]string{
+	"bash": `# sortd: eval "$(sortd shell-init bash)" in ~/.bashrc
+sortd() {
+  if [ "$1" = "go" ] && [ $# -eq 2 ]; then
+    local dir
+    dir="$(command sortd go "$2")" && cd "$dir"
+  else
+    command sortd "$@"
+  fi
+}
+source <(command sortd completion bash)
+`,
+	"zsh": `# sortd: eval "$(sortd shell-init zsh)" in ~/.zshrc
+sortd() {
+  if [[ "$1" == "go" && $# -eq 2 ]]; then
+    local dir
+    dir="$(command sortd go "$2")" && cd "$dir"
+  else
+    command sortd "$@"
+  fi
+}
+source <(command sortd completion zsh); compdef _sortd sortd
+`,
+	"fish": `# sortd: sortd shell-init fish | source   in ~/.config/fish/config.fish
+function sortd --wraps sortd
+    if test (count $argv) -eq 2 -a "$argv[1]" = go
+        set -l dir (command sortd go $argv[2]); and cd $dir
+    else
+        command sortd $argv
+    end
+end
+command sortd completion fish | source
+`,
+	"ranger": `# sortd: add to ~/.config/ranger/commands.py
+from ranger.api.commands import Command
+
+class sortd(Command):
+    """:sortd - organize the selected files by your sortd rules"""
+    def execute(self):
+        for f in self.fm.thistab.get_selection():
+            self.fm.execute_command(["sortd", "organize", "--non-interactive", f.path])
+        self.fm.reload_cwd()
+
+# and bind it in ~/.config/ranger/rc.conf:
+# map so sortd
+`,
+	"lf": `# sortd: add to ~/.config/lf/lfrc, then press "so" on selected files
+cmd sortd ${{
+    printf '%s\n' "$fx" | while IFS= read -r f; do
+        sortd organize --non-interactive "$f"
+    done
+}}
+map so sortd
+`,
+	"nautilus": `#!/bin/sh
+# sortd: Nautilus script; save to ~/.local/share/nautilus/scripts/Organize with sortd
+# (or run 'sortd shell-init nautilus --install') and mark it executable
+printf '%s' "$NAUTILUS_SCRIPT_SELECTED_FILE_PATHS" | while IFS= read -r f; do
+    [ -n "$f" ] && sortd organize --non-interactive "$f"
+done
+`,
+}
+
+// nautilusScriptName is the menu entry the Nautilus script appears under
+const nautilusScriptName = "Organize with sortd"
+
+// NewShellInitCmd creates the command printing shell and file manager integration
+func NewShellInitCmd() *cobra.Command {
+	var install bool
+
+	targets := make([]string, 0, len(shellSnippets))
+	for target := range shellSnippets {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	cmd := &cobra.Command{
+		Use:   "shell-init <bash|zsh|fish|ranger|lf|nautilus>",
+		Short: "Print shell and file manager integration",
+		Long: `Print integration code for a shell or file manager.
+
+For shells, evaluate the output in your shell's startup file. It wraps sortd so
+'sortd go <bookmark>' changes into the bookmarked directory, and loads tab
+completion:
+
+  eval "$(sortd shell-init bash)"     # ~/.bashrc
+  eval "$(sortd shell-init zsh)"      # ~/.zshrc
+  sortd shell-init fish | source      # ~/.config/fish/config.fish
+
+For ranger, lf and Nautilus, the output adds a command that organizes the
+selected files by your rules. --install writes the Nautilus script into place.`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: targets,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snippet, ok := shellSnippets[args[0]]
+			if !ok {
+				return fmt.Errorf("unknown target %q (use one of %v)", args[0], targets)
+			}
+
+			if !install {
+				fmt.Print(snippet)
+				return nil
+			}
+			if args[0] != "nautilus" {
+				return fmt.Errorf("--install is only supported for nautilus; add the printed code to your %s config", args[0])
+			}
+
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+			dir := filepath.Join(home, ".local", "share", "nautilus", "scripts")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			path := filepath.Join(dir, nautilusScriptName)
+			if err := atomicfile.WriteFile(path, []byte(snippet), 0755); err != nil {
+				return err
+			}
+			fmt.Println(successText(fmt.Sprintf("Installed %s; right-click files → Scripts → %s", path, nautilusScriptName)))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&install, "install", false, "Write the integration into place (nautilus only)")
+
+	return cmd
+}
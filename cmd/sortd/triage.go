@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sortd/internal/app"
+	"sortd/internal/learning"
+	"sortd/internal/trash"
+	"sortd/internal/units"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// maxAlternatives is how many learned destinations triage offers besides the
+// suggested one, on keys 1 to 3
+const maxAlternatives = 3
+
+// triageItem is a file waiting for a decision
+type triageItem struct {
+	path        string
+	destination string // Suggested full destination; empty when no rule matches
+	rule        string // Pattern that suggested it
+}
+
+// triageScore keeps the tally shown while triaging and at the end
+type triageScore struct {
+	started   time.Time
+	filed     int // Sent where sortd suggested
+	elsewhere int // Sent to a learned alternative
+	trashed   int
+	skipped   int
+	combo     int // Decisions in a row without skipping
+	bestCombo int
+}
+
+// decided counts a decision other than skipping
+func (s *triageScore) decided() {
+	s.combo++
+	if s.combo > s.bestCombo {
+		s.bestCombo = s.combo
+	}
+}
+
+// pace returns the files handled per minute so far
+func (s *triageScore) pace() float64 {
+	minutes := time.Since(s.started).Minutes()
+	if minutes < 1 {
+		minutes = 1
+	}
+	return float64(s.filed+s.elsewhere+s.trashed+s.skipped) / minutes
+}
+
+// NewTriageCmd creates the triage command, which clears a directory one file
+// and one key press at a time
+func NewTriageCmd() *cobra.Command {
+	var recursive bool
+
+	cmd := &cobra.Command{
+		Use:   "triage [DIRECTORY]",
+		Short: "Decide about files one at a time with single keys",
+		Long: `Clear out a pile of old downloads fast: triage shows one file at a time,
+with a short preview and where your rules would send it, and waits for a
+single key:
+
+  enter/a  accept the suggested destination
+  1-3      send it to a destination you've used for files like it instead
+  s        skip it, leaving it where it is
+  t        move it to the trash
+  q        stop
+
+Alternatives are learned from files you moved after sortd placed them, and
+each choice teaches sortd: accepting raises the rule's confidence, picking
+an alternative counts as a correction ('sortd rules suggest').`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil {
+				return fmt.Errorf("configuration not loaded")
+			}
+
+			dir, err := determineTargetPath(args, "")
+			if err != nil {
+				return err
+			}
+
+			service := app.New(cfg)
+			service.Engine().SetCollisionResolver(askCollision)
+			if service.ReadOnly() || service.DryRun() {
+				return fmt.Errorf("triage moves files; turn read-only mode off first ('sortd daemon resume')")
+			}
+
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			plan, err := service.PlanOrganize(ctx, dir, app.PlanOptions{Recursive: recursive})
+			if err != nil {
+				return err
+			}
+			var items []triageItem
+			for _, move := range plan.Moves {
+				items = append(items, triageItem{path: move.Source, destination: move.Destination, rule: move.Pattern})
+			}
+			for _, file := range plan.Unmatched {
+				items = append(items, triageItem{path: file})
+			}
+			if len(items) == 0 {
+				fmt.Println(infoText("Nothing to triage here."))
+				return nil
+			}
+
+			store, err := learning.Open(learning.DefaultPath(cfg))
+			if err != nil {
+				return err
+			}
+
+			score := &triageScore{started: time.Now()}
+			keys := newKeyReader()
+		triage:
+			for i, item := range items {
+				var alternatives []string
+				if item.destination != "" {
+					alternatives = store.Alternatives(item.rule, item.path, filepath.Dir(item.destination), maxAlternatives)
+				} else {
+					alternatives = store.Alternatives("", item.path, "", maxAlternatives)
+				}
+				printTriageItem(i, len(items), item, alternatives, score)
+
+				for {
+					key, err := keys.read()
+					if err != nil {
+						return err
+					}
+
+					var dest string
+					switch {
+					case key == 'q' || key == 3 || key == 27: // q, ctrl-c, escape
+						break triage
+					case key == 's' || key == ' ':
+						score.skipped++
+						score.combo = 0
+						fmt.Println(infoText("  skipped"))
+						continue triage
+					case key == 't':
+						if _, err := trash.Move(item.path); err != nil {
+							fmt.Println(errorText(fmt.Sprintf("  %v", err)))
+							continue triage
+						}
+						score.trashed++
+						score.decided()
+						fmt.Println(warningText("  🗑 trashed"))
+						continue triage
+					case (key == 'a' || key == '\r' || key == '\n') && item.destination != "":
+						dest = item.destination
+					case key >= '1' && int(key-'1') < len(alternatives):
+						dest = filepath.Join(alternatives[key-'1'], filepath.Base(item.path))
+					default:
+						continue
+					}
+
+					if err := service.Engine().MoveFile(item.path, dest); err != nil {
+						fmt.Println(errorText(fmt.Sprintf("  %v", err)))
+						continue triage
+					}
+					if dest == item.destination {
+						score.filed++
+						if item.rule != "" {
+							store.RecordApproval(item.rule)
+						}
+					} else {
+						score.elsewhere++
+						if item.rule != "" {
+							store.RecordCorrection(item.rule, item.destination, dest)
+						}
+					}
+					score.decided()
+					fmt.Println(successText("  ✓ → " + filepath.Dir(dest)))
+					continue triage
+				}
+			}
+
+			handled := score.filed + score.elsewhere + score.trashed + score.skipped
+			fmt.Println(successText(fmt.Sprintf("\n🏁 %d of %d files in %s (%.0f/min): %d filed, %d elsewhere, %d trashed, %d skipped. Best combo: %d",
+				handled, len(items), time.Since(score.started).Round(time.Second), score.pace(),
+				score.filed, score.elsewhere, score.trashed, score.skipped, score.bestCombo)))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Include files in subdirectories")
+
+	return cmd
+}
+
+// printTriageItem shows a file, its preview and the keys that decide about it
+func printTriageItem(i, total int, item triageItem, alternatives []string, score *triageScore) {
+	status := fmt.Sprintf("[%d/%d]", i+1, total)
+	if score.combo > 1 {
+		status += fmt.Sprintf("  ⚡ combo %d", score.combo)
+	}
+	if i > 0 {
+		status += fmt.Sprintf("  %.0f/min", score.pace())
+	}
+	fmt.Printf("\n%s\n%s\n", infoText(status), primaryText(filepath.Base(item.path)))
+	for _, line := range previewFile(item.path) {
+		fmt.Println("  " + line)
+	}
+
+	if item.destination != "" {
+		fmt.Printf("  [a] → %s  (%s)\n", filepath.Dir(item.destination), item.rule)
+	} else {
+		fmt.Println(warningText("  no rule matches this file"))
+	}
+	for n, alternative := range alternatives {
+		fmt.Printf("  [%d] → %s\n", n+1, alternative)
+	}
+	fmt.Println("  [s] skip  [t] trash  [q] quit")
+}
+
+// previewFile describes a file in a few lines: size, age and type, and the
+// first lines of text files
+func previewFile(path string) []string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	head := make([]byte, 512)
+	contentType := "unknown type"
+	if f, err := os.Open(path); err == nil {
+		n, _ := f.Read(head)
+		f.Close()
+		head = head[:n]
+		contentType = http.DetectContentType(head)
+	}
+
+	lines := []string{fmt.Sprintf("%s, modified %s, %s",
+		units.FormatSize(info.Size()), info.ModTime().Format("2006-01-02"), strings.Split(contentType, ";")[0])}
+	if !strings.HasPrefix(contentType, "text/") {
+		return lines
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(head)))
+	for shown := 0; shown < 3 && scanner.Scan(); {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if len(line) > 72 {
+			line = line[:72] + "…"
+		}
+		lines = append(lines, "│ "+line)
+		shown++
+	}
+	return lines
+}
+
+// keyReader reads single key presses, or the first character of each line
+// when stdin isn't a terminal
+type keyReader struct {
+	raw   bool
+	lines *bufio.Reader
+}
+
+// newKeyReader creates a key reader for stdin
+func newKeyReader() *keyReader {
+	return &keyReader{
+		raw:   term.IsTerminal(os.Stdin.Fd()),
+		lines: bufio.NewReader(os.Stdin),
+	}
+}
+
+// read waits for the next key. A blank line reads as enter.
+func (k *keyReader) read() (byte, error) {
+	if k.raw {
+		state, err := term.MakeRaw(os.Stdin.Fd())
+		if err == nil {
+			defer term.Restore(os.Stdin.Fd(), state)
+			key := make([]byte, 1)
+			if _, err := os.Stdin.Read(key); err != nil {
+				return 0, err
+			}
+			return key[0], nil
+		}
+		k.raw = false
+	}
+
+	line, err := k.lines.ReadString('\n')
+	if err != nil && line == "" {
+		// Out of input counts as quitting
+		return 'q', nil
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return '\n', nil
+	}
+	return line[0], nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"sortd/internal/manifest"
+
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyCmd creates the command that checks archive directories against
+// their checksum manifests
+func NewVerifyCmd() *cobra.Command {
+	var record, quiet bool
+
+	cmd := &cobra.Command{
+		Use:   "verify [DIRECTORY...]",
+		Short: "Check archived files against their SHA256SUMS manifests",
+		Long: `Check every file in the archive directories against the SHA256SUMS manifest
+of its folder, to catch bit rot and files changed or lost in cold storage.
+Directories listed under settings.manifests are checked unless others are given.
+
+sortd adds a file to the manifest of its folder when a rule or workflow moves or
+copies it into one of those directories. Files that were there before aren't
+listed until you run verify with --record, which adds them. The manifests are
+in the format of sha256sum, so 'sha256sum -c SHA256SUMS' checks a folder too.
+
+Exits non-zero when a file changed, is missing or couldn't be read.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dirs := args
+			if len(dirs) == 0 {
+				dirs = cfg.Settings.Manifests
+			}
+			if len(dirs) == 0 {
+				return fmt.Errorf("no directories to verify: list them under settings.manifests or give them as arguments")
+			}
+
+			counts := make(map[manifest.Status]int)
+			for _, dir := range dirs {
+				results, err := manifest.Verify(dir, record)
+				for _, result := range results {
+					counts[result.Status]++
+					switch result.Status {
+					case manifest.StatusOK:
+						if !quiet {
+							fmt.Println(successText("✓ " + result.Path))
+						}
+					case manifest.StatusRecorded:
+						fmt.Println(infoText("+ " + result.Path + " recorded"))
+					case manifest.StatusUnlisted:
+						if !quiet {
+							fmt.Println(warningText("? " + result.Path + " isn't in the manifest"))
+						}
+					case manifest.StatusError:
+						fmt.Println(errorText(fmt.Sprintf("✗ %s couldn't be read: %v", result.Path, result.Err)))
+					default:
+						fmt.Println(errorText("✗ " + result.Path + " " + string(result.Status)))
+					}
+				}
+				if err != nil {
+					return fmt.Errorf("failed to verify %s: %w", dir, err)
+				}
+			}
+
+			fmt.Printf("\n%d ok, %d changed, %d missing", counts[manifest.StatusOK], counts[manifest.StatusChanged], counts[manifest.StatusMissing])
+			if counts[manifest.StatusError] > 0 {
+				fmt.Printf(", %d unreadable", counts[manifest.StatusError])
+			}
+			if counts[manifest.StatusRecorded] > 0 {
+				fmt.Printf(", %d recorded", counts[manifest.StatusRecorded])
+			}
+			if counts[manifest.StatusUnlisted] > 0 {
+				fmt.Printf(", %d not in a manifest (add them with --record)", counts[manifest.StatusUnlisted])
+			}
+			fmt.Println()
+
+			if failed := counts[manifest.StatusChanged] + counts[manifest.StatusMissing] + counts[manifest.StatusError]; failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d file(s) failed verification", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&record, "record", false, "Add files that aren't in their folder's manifest yet")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only list files that failed or were recorded")
+	return cmd
+}
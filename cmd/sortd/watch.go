@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"sortd/internal/config"
 	"sortd/internal/watch"
 	"strings"
 	"syscall"
@@ -24,36 +25,45 @@ func NewWatchCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "watch",
 		Short: "Watch directories for file changes",
-		Long:  `Watch specified directories for changes and organize files automatically based on configuration.`,
-		Run: func(cmd *cobra.Command, args []string) {
+		Long: `Watch specified directories for changes and organize files automatically based on configuration.
+
+In the foreground, stopping with Ctrl+C ends with a sortd-summary line on
+stderr and the exit codes of 'sortd organize': 2 when some files failed, 3 when
+the configuration is invalid or lists no watch directories, 4 when files
+arrived but none matched a rule.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			summary := newRunSummary(cmd)
+			summary.DryRun = cfg != nil && cfg.Settings.DryRun
+
 			// 1. Ensure configuration is loaded (should be done by root PersistentPreRun)
-			if cfg == nil {
-				fmt.Println(errorText("Configuration not loaded. Cannot start watch command."))
+			if err := requireValidConfig(); err != nil {
 				fmt.Println(infoText("Try running 'sortd setup' or specify a valid config with --config"))
-				return
+				return finishRun(cmd, summary, err)
 			}
 
 			// 2. Check if watch directories are configured
-			if len(cfg.WatchDirectories) == 0 {
-				fmt.Println(errorText("No watch directories specified in the configuration file."))
-				fmt.Println(infoText("Please add directories under 'watch_directories:' in your config."))
-				return
+			if len(cfg.WatchDirs()) == 0 {
+				fmt.Println(infoText("Please add directories under 'directories.watch:' or 'watch_directories:' in your config."))
+				return finishRun(cmd, summary, &exitError{code: exitConfig, err: fmt.Errorf("no watch directories specified in the configuration file")})
 			}
 			fmt.Println(infoText("Using watch directories from configuration:"))
-			for _, dir := range cfg.WatchDirectories {
-				fmt.Printf("  - %s\n", dir)
+			for _, dir := range cfg.WatchDirs() {
+				fmt.Printf("  - %s%s\n", dir.Path, describeWatchDir(dir))
 			}
 
 			// Create the watch daemon - Pass only config, returns (*Daemon, error)
 			daemon, err := watch.NewDaemon(cfg)
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
-				return
+				return finishRun(cmd, summary, err)
 			}
 
 			// Set confirmation requirement
 			daemon.SetRequireConfirmation(requireConfirm)
 
+			// Publish statistics and activity for 'sortd daemon stats' and 'sortd digest'
+			daemon.SetStatsFile(watch.StatsFilePath(cfg))
+			daemon.SetActivityFile(watch.ActivityFilePath(cfg))
+
 			// Set dry run from config
 			if cfg.Settings.DryRun {
 				daemon.SetDryRun(true)
@@ -97,26 +107,23 @@ func NewWatchCmd() *cobra.Command {
 			if background {
 				fmt.Println("Starting watch daemon in background...")
 				if err := watch.DaemonControl(cfg, true); err != nil {
-					fmt.Printf("Error: %v\n", err)
-					return
+					return err
 				}
 				fmt.Println("Daemon started. Logs will be written to sortd.log")
 				fmt.Println("Use 'sortd daemon stop' to stop the daemon")
-				return // Exit after starting the daemon
+				return nil // Exit after starting the daemon
 			}
 
 			// Run watch mode in foreground
 			fmt.Println("Starting watch daemon in foreground. Press Ctrl+C to stop.")
-			fmt.Printf("Watching directories: %v\n", cfg.WatchDirectories)
-
-			// Start the daemon in foreground mode
-			if err := watch.DaemonControl(cfg, false); err != nil {
-				fmt.Printf("Error: %v\n", err)
-				return
-			}
+			fmt.Printf("Watching directories: %v\n", cfg.WatchPaths())
 
 			// 4. Handle foreground/background
 			if foreground {
+				// Run the daemon in this process, so the summary can count what it did
+				if err := daemon.Start(); err != nil {
+					return finishRun(cmd, summary, err)
+				}
 				fmt.Println(infoText("Running in foreground mode. Press Ctrl+C to stop."))
 
 				sigChan := make(chan os.Signal, 1)
@@ -127,10 +134,17 @@ func NewWatchCmd() *cobra.Command {
 				fmt.Println(infoText("\nStopping watch daemon..."))
 				daemon.Stop()
 				fmt.Println(successText("Watch daemon stopped"))
-			} else {
-				fmt.Println(infoText("Watch daemon running in background"))
-				fmt.Println(infoText("Use 'sortd daemon stop' to stop the daemon"))
+				countDaemon(summary, daemon.Status())
+				return finishRun(cmd, summary, nil)
+			}
+
+			// Otherwise hand over to a daemon process
+			if err := watch.DaemonControl(cfg, false); err != nil {
+				return err
 			}
+			fmt.Println(infoText("Watch daemon running in background"))
+			fmt.Println(infoText("Use 'sortd daemon stop' to stop the daemon"))
+			return nil
 		},
 	}
 
@@ -141,3 +155,28 @@ func NewWatchCmd() *cobra.Command {
 
 	return cmd
 }
+
+// describeWatchDir summarizes a watch directory entry's own settings, e.g.
+// " (recursive, poll, profile work)", or "" for a plain path
+func describeWatchDir(dir config.WatchDir) string {
+	var parts []string
+	if dir.Recursive {
+		parts = append(parts, "recursive")
+	}
+	if dir.Backend != "" {
+		parts = append(parts, dir.Backend)
+	}
+	if dir.DebounceSeconds > 0 {
+		parts = append(parts, fmt.Sprintf("debounce %ds", dir.DebounceSeconds))
+	}
+	if len(dir.Include) > 0 || len(dir.Exclude) > 0 {
+		parts = append(parts, "filtered")
+	}
+	if dir.Profile != "" {
+		parts = append(parts, "profile "+dir.Profile)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sortd/pkg/types"
+	"sortd/pkg/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+// addWorkflowHistoryCmd attaches 'workflow history' to the workflow command
+func addWorkflowHistoryCmd(rootCmd *cobra.Command) {
+	workflowCommand(rootCmd).AddCommand(newWorkflowHistoryCmd())
+}
+
+// addWorkflowImportCmd attaches 'workflow import' to the workflow command
+func addWorkflowImportCmd(rootCmd *cobra.Command) {
+	workflowCommand(rootCmd).AddCommand(newWorkflowImportCmd())
+}
+
+// addWorkflowRunCmd attaches 'workflow run' to the workflow command
+func addWorkflowRunCmd(rootCmd *cobra.Command) {
+	workflowCommand(rootCmd).AddCommand(newWorkflowRunCmd())
+}
+
+// workflowCommand returns the workflow command, creating it if it hasn't
+// been registered
+func workflowCommand(rootCmd *cobra.Command) *cobra.Command {
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == "workflow" {
+			return cmd
+		}
+	}
+
+	workflowCmd := &cobra.Command{
+		Use:   "workflow",
+		Short: "Inspect and run workflows",
+		Long:  `Inspect workflows and what they have done, or run them by hand.`,
+	}
+	rootCmd.AddCommand(workflowCmd)
+	return workflowCmd
+}
+
+// newWorkflowHistoryCmd creates the 'workflow history' command
+func newWorkflowHistoryCmd() *cobra.Command {
+	var jsonOutput bool
+	var shadowOnly bool
+	var workflowID string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show recent workflow runs",
+		Long: `Show what workflows did, or would have done, to each file.
+Use --shadow to review workflows running in shadow mode before enabling them.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			dir, err := workflow.DefaultDir()
+			if err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error locating workflows: %v", err)))
+				return
+			}
+
+			records, err := workflow.ReadHistory(dir)
+			if err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error reading workflow history: %v", err)))
+				return
+			}
+
+			var filtered []types.WorkflowRunRecord
+			for _, record := range records {
+				if shadowOnly && record.Mode != types.ShadowMode {
+					continue
+				}
+				if workflowID != "" && record.WorkflowID != workflowID {
+					continue
+				}
+				filtered = append(filtered, record)
+			}
+			if limit > 0 && len(filtered) > limit {
+				filtered = filtered[len(filtered)-limit:]
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(filtered, "", "  ")
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error encoding history: %v", err)))
+					return
+				}
+				fmt.Println(string(data))
+				return
+			}
+
+			if len(filtered) == 0 {
+				fmt.Println(warningText("No workflow runs recorded"))
+				return
+			}
+
+			fmt.Println(primaryText("📜 Workflow History"))
+			for _, record := range filtered {
+				fmt.Println("")
+				status := successText("ok")
+				if !record.Success {
+					status = errorText("failed: " + record.Error)
+				}
+				fmt.Printf("%s  %s [%s] %s\n", record.Time.Format(time.RFC1123), emphasisText(record.WorkflowID), record.Mode, status)
+				for _, action := range record.Actions {
+					if record.Mode == types.ActiveMode {
+						fmt.Printf("  • %s\n", action)
+					} else {
+						fmt.Printf("  • would %s\n", action)
+					}
+				}
+				if len(record.Actions) == 0 {
+					fmt.Printf("  • %s\n", record.FilePath)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output history in JSON format")
+	cmd.Flags().BoolVar(&shadowOnly, "shadow", false, "Only show shadow-mode runs")
+	cmd.Flags().StringVarP(&workflowID, "workflow", "w", "", "Only show runs of this workflow ID")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Show at most this many recent runs (0 for all)")
+
+	return cmd
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"sortd/internal/importer"
+	"sortd/pkg/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+// newWorkflowImportCmd creates the 'workflow import' command
+func newWorkflowImportCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Translate another organizer's rules into workflows",
+		Long: `Translate the rules of the organize tool (organize-tool, config.yaml) into
+workflows, one per rule, saved in the workflows directory.
+
+Filters and actions sortd can't express, such as mimetype or exif filters or
+trash actions, are listed; a workflow missing any of them is saved disabled,
+so you can review it before it touches a file. Rules run on the directories
+sortd watches rather than their own locations.
+
+Hazel rule exports are recognized, but Hazel saves them in an undocumented
+format sortd can't read.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			imported, err := importer.Workflows(args[0])
+			if err != nil {
+				fmt.Println(errorText(fmt.Sprintf("Error importing %s: %v", args[0], err)))
+				return
+			}
+
+			var manager *workflow.Manager
+			if !dryRun {
+				dir, err := workflow.DefaultDir()
+				if err == nil {
+					err = os.MkdirAll(dir, 0755)
+				}
+				if err == nil {
+					manager, err = workflow.NewManager(dir)
+				}
+				if err != nil {
+					fmt.Println(errorText(fmt.Sprintf("Error loading workflows: %v", err)))
+					return
+				}
+			}
+
+			saved, disabled := 0, 0
+			for _, rule := range imported {
+				wf := rule.Workflow
+				switch {
+				case rule.Skipped():
+					fmt.Printf("%s  %s\n", warningText("skipped"), wf.Name)
+				case !wf.Enabled:
+					fmt.Printf("%s  %s\n", warningText("disabled"), wf.Name)
+				default:
+					fmt.Printf("%s  %s\n", successText("ready"), wf.Name)
+				}
+				for _, unsupported := range rule.Unsupported {
+					fmt.Println("          " + warningText("not translated: "+unsupported))
+				}
+				for _, note := range rule.Notes {
+					fmt.Println("          " + infoText(note))
+				}
+				if rule.Skipped() || dryRun {
+					continue
+				}
+
+				wf.ID = manager.UniqueID(wf.ID)
+				if err := manager.AddWorkflow(wf); err != nil {
+					fmt.Println("          " + errorText(fmt.Sprintf("Error saving workflow: %v", err)))
+					continue
+				}
+				fmt.Println("          saved as " + primaryText(wf.ID))
+				saved++
+				if !wf.Enabled {
+					disabled++
+				}
+			}
+
+			if dryRun {
+				fmt.Println(infoText("[DRY RUN] Nothing saved"))
+				return
+			}
+			summary := fmt.Sprintf("%d workflows imported", saved)
+			if disabled > 0 {
+				summary += fmt.Sprintf(", %d disabled until you review them", disabled)
+			}
+			fmt.Println(successText("✓ " + summary))
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the translation without saving workflows")
+	return cmd
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"sortd/internal/manifest"
+	"sortd/internal/watch"
+	"sortd/pkg/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+// newWorkflowRunCmd creates the 'workflow run' command
+func newWorkflowRunCmd() *cobra.Command {
+	var dryRun bool
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "run ID [PATH...]",
+		Short: "Run a workflow on files now",
+		Long: `Run a workflow by hand on files or folders, whatever its trigger. This is how
+workflows with a manual trigger run. A folder stands for the files directly
+inside it; without paths the workflow runs on the watched directories.
+
+Time windows don't apply, but conditions do: files that don't meet them are
+skipped and listed.
+
+Like 'sortd organize', it exits with 2 when the workflow failed on some files,
+3 when the configuration is invalid and 4 when no file met its conditions, and
+ends with a sortd-summary line on stderr.`,
+		Example: `  sortd workflow run tidy-invoices ~/Downloads/invoice.pdf
+  sortd workflow run photos ~/Desktop --dry-run`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			summary := newRunSummary(cmd)
+			summary.DryRun = dryRun
+			defer func() { err = finishRun(cmd, summary, err) }()
+			if err := requireValidConfig(); err != nil {
+				return err
+			}
+			manager := loadWorkflowManager()
+			if manager == nil {
+				return fmt.Errorf("workflows could not be loaded")
+			}
+			manager.SetRetry(cfg.Settings.Retry)
+			manager.SetManifests(manifest.New(cfg.Settings.Manifests))
+			manager.SetRemoteBandwidth(cfg.Settings.Priority.BandwidthLimit())
+			manager.SetMetadata(watch.WorkflowMetadata(cfg))
+			manager.SetDryRun(dryRun)
+
+			paths := args[1:]
+			if len(paths) == 0 {
+				paths = cfg.WatchPaths()
+			}
+
+			progress := func(done, total int, path string) {
+				fmt.Printf("[%d/%d] %s\n", done, total, filepath.Base(path))
+			}
+			if jsonOutput {
+				progress = nil
+			}
+			run, err := manager.RunManual(args[0], paths, progress)
+			if err != nil {
+				return err
+			}
+			summary.Files = len(run.Results) + len(run.Skipped)
+			summary.Failed = run.Failed()
+			summary.Done = len(run.Results) - summary.Failed
+			summary.Unmatched = len(run.Skipped)
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(manualRunJSON(run), "", "  ")
+				if err != nil {
+					return fmt.Errorf("error encoding results: %w", err)
+				}
+				fmt.Println(string(data))
+			} else {
+				printManualRun(run, dryRun)
+			}
+
+			if failed := run.Failed(); failed > 0 {
+				return fmt.Errorf("workflow failed on %d of %d files", failed, len(run.Results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Show what the workflow would do without doing it")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output results in JSON format")
+	return cmd
+}
+
+// printManualRun shows what a manual workflow run did to each file
+func printManualRun(run *workflow.ManualRun, dryRun bool) {
+	fmt.Println("")
+	for _, result := range run.Results {
+		if result.Success {
+			fmt.Printf("%s  %s  %s\n", successText("ok"), result.FilePath, result.Message)
+		} else {
+			fmt.Printf("%s  %s  %v\n", errorText("failed"), result.FilePath, result.Error)
+		}
+	}
+	for _, skipped := range run.Skipped {
+		fmt.Printf("%s  %s  conditions not met\n", warningText("skipped"), skipped)
+	}
+
+	summary := fmt.Sprintf("Ran on %d files, %d failed, %d skipped", len(run.Results), run.Failed(), len(run.Skipped))
+	if dryRun {
+		summary = "[DRY RUN] " + summary
+	}
+	if run.Failed() > 0 {
+		fmt.Println(errorText(summary))
+		return
+	}
+	fmt.Println(successText("✓ " + summary))
+}
+
+// manualRunResult is a manual run's result for one file as JSON
+type manualRunResult struct {
+	File    string `json:"file"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// manualRunJSON turns a manual run into what --json prints, with errors as text
+func manualRunJSON(run *workflow.ManualRun) map[string]interface{} {
+	results := make([]manualRunResult, 0, len(run.Results))
+	for _, result := range run.Results {
+		entry := manualRunResult{File: result.FilePath, Success: result.Success, Message: result.Message}
+		if result.Error != nil {
+			entry.Error = result.Error.Error()
+		}
+		results = append(results, entry)
+	}
+	skipped := run.Skipped
+	if skipped == nil {
+		skipped = []string{}
+	}
+	return map[string]interface{}{"results": results, "skipped": skipped}
+}
@@ -0,0 +1,267 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	log "sortd/internal/log"
+	"sortd/pkg/types"
+)
+
+// maxDocumentText bounds how much extracted text is searched for a date
+const maxDocumentText = 64 * 1024
+
+// dateKeywords mark the date a document is about, as opposed to dates it mentions
+var dateKeywords = regexp.MustCompile(`(?i)(statement|invoice|bill|issue|issued|document|closing|period|period ending|as of|date)\s*(date)?\s*[:\-]?\s*$`)
+
+// Date formats found in documents. Month names are matched by their first three letters.
+var (
+	isoDatePattern     = regexp.MustCompile(`\b(\d{4})[-/.](\d{1,2})[-/.](\d{1,2})\b`)
+	numericDatePattern = regexp.MustCompile(`\b(\d{1,2})[-/.](\d{1,2})[-/.](\d{4})\b`)
+	dayMonthPattern    = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?[ \-]+(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?[ \-,]+(\d{4})\b`)
+	monthDayPattern    = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.? +(\d{1,2})(?:st|nd|rd|th)?,? +(\d{4})\b`)
+	monthYearPattern   = regexp.MustCompile(`(?i)\b(january|february|march|april|may|june|july|august|september|october|november|december) +(\d{4})\b`)
+)
+
+// months maps three-letter month prefixes to months
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// dateCandidate is a date found in text, with where it starts
+type dateCandidate struct {
+	date   time.Time
+	offset int
+}
+
+// ExtractDocumentDate finds the date a document is about in its text: a date
+// right after a label such as "Statement date:" wins, otherwise the first
+// date in the text. Ambiguous numeric dates like 03/04/2024 are read
+// month-first unless dayFirst is set.
+func ExtractDocumentDate(text string, dayFirst bool) (time.Time, bool) {
+	candidates := findDates(text, dayFirst)
+	if len(candidates) == 0 {
+		return time.Time{}, false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].offset < candidates[j].offset })
+	for _, candidate := range candidates {
+		start := max(0, candidate.offset-40)
+		if dateKeywords.MatchString(text[start:candidate.offset]) {
+			return candidate.date, true
+		}
+	}
+	return candidates[0].date, true
+}
+
+// findDates returns every plausible date in the text
+func findDates(text string, dayFirst bool) []dateCandidate {
+	var candidates []dateCandidate
+	add := func(offset, year int, month time.Month, day int) {
+		if date, ok := makeDate(year, month, day); ok {
+			candidates = append(candidates, dateCandidate{date: date, offset: offset})
+		}
+	}
+
+	for _, m := range isoDatePattern.FindAllStringSubmatchIndex(text, -1) {
+		add(m[0], atoi(text[m[2]:m[3]]), time.Month(atoi(text[m[4]:m[5]])), atoi(text[m[6]:m[7]]))
+	}
+	for _, m := range numericDatePattern.FindAllStringSubmatchIndex(text, -1) {
+		a, b, year := atoi(text[m[2]:m[3]]), atoi(text[m[4]:m[5]]), atoi(text[m[6]:m[7]])
+		// Prefer the reading that is valid; fall back to the configured order
+		if a > 12 || (dayFirst && b <= 12) {
+			a, b = b, a
+		}
+		add(m[0], year, time.Month(a), b)
+	}
+	for _, m := range dayMonthPattern.FindAllStringSubmatchIndex(text, -1) {
+		add(m[0], atoi(text[m[6]:m[7]]), months[strings.ToLower(text[m[4]:m[5]])], atoi(text[m[2]:m[3]]))
+	}
+	for _, m := range monthDayPattern.FindAllStringSubmatchIndex(text, -1) {
+		add(m[0], atoi(text[m[6]:m[7]]), months[strings.ToLower(text[m[2]:m[3]])], atoi(text[m[4]:m[5]]))
+	}
+	for _, m := range monthYearPattern.FindAllStringSubmatchIndex(text, -1) {
+		add(m[0], atoi(text[m[4]:m[5]]), months[strings.ToLower(text[m[2]:m[2]+3])], 1)
+	}
+
+	return candidates
+}
+
+// makeDate builds a date, rejecting impossible days and implausible years
+func makeDate(year int, month time.Month, day int) (time.Time, bool) {
+	if month < time.January || month > time.December || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	if year < 1970 || year > time.Now().Year()+1 {
+		return time.Time{}, false
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return time.Time{}, false // e.g. February 30
+	}
+	return date, true
+}
+
+// atoi parses digits matched by a pattern
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// documentDateMetadata returns the {doc_date}, {doc_month} and {doc_year}
+// metadata for a document's text
+func documentDateMetadata(text string, dayFirst bool) map[string]string {
+	date, ok := ExtractDocumentDate(text, dayFirst)
+	if !ok {
+		return nil
+	}
+	return map[string]string{
+		"doc_date":  date.Format("2006-01-02"),
+		"doc_month": date.Format("2006-01"),
+		"doc_year":  date.Format("2006"),
+	}
+}
+
+// ExtractText returns the text of a document. Text files are read directly,
+// PDFs with pdftotext; with ocr set, scanned PDFs and images are read with
+// tesseract.
+func ExtractText(path, contentType string, ocr bool) (string, error) {
+	switch {
+	case strings.HasPrefix(contentType, "text/"):
+		data, err := readPrefix(path, maxDocumentText)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+
+	case contentType == "application/pdf":
+		text, err := pdfText(path)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(text) == "" && ocr {
+			return ocrPDF(path)
+		}
+		return text, nil
+
+	case strings.HasPrefix(contentType, "image/") && ocr:
+		return ocrImage(path)
+
+	default:
+		return "", fmt.Errorf("no text extractor for %s", contentType)
+	}
+}
+
+// readPrefix reads up to limit bytes of a file, dropping a cut-off UTF-8 sequence
+func readPrefix(path string, limit int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, limit)
+	n, _ := f.Read(buf)
+	buf = buf[:n]
+	for i := 0; i < utf8.UTFMax && len(buf) > 0; i++ {
+		if r, size := utf8.DecodeLastRune(buf); r != utf8.RuneError || size != 1 {
+			break
+		}
+		buf = buf[:len(buf)-1]
+	}
+	return buf, nil
+}
+
+// pdfText extracts the text layer of the first pages of a PDF
+func pdfText(path string) (string, error) {
+	pdftotext, err := exec.LookPath("pdftotext")
+	if err != nil {
+		return "", fmt.Errorf("pdftotext not found; install Poppler to read PDF text")
+	}
+	out, err := exec.Command(pdftotext, "-q", "-l", "3", "-layout", path, "-").Output()
+	if err != nil {
+		return "", fmt.Errorf("pdftotext failed for %s: %w", path, err)
+	}
+	return string(out[:min(len(out), maxDocumentText)]), nil
+}
+
+// ocrPDF renders the first page of a scanned PDF and reads it with tesseract
+func ocrPDF(path string) (string, error) {
+	pdftoppm, err := exec.LookPath("pdftoppm")
+	if err != nil {
+		return "", fmt.Errorf("pdftoppm not found; install Poppler to OCR scanned PDFs")
+	}
+
+	dir, err := os.MkdirTemp("", "sortd-ocr-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	prefix := filepath.Join(dir, "page")
+	if out, err := exec.Command(pdftoppm, "-q", "-r", "200", "-f", "1", "-l", "1", "-png", "-singlefile", path, prefix).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("pdftoppm failed for %s: %v: %s", path, err, strings.TrimSpace(string(out)))
+	}
+	return ocrImage(prefix + ".png")
+}
+
+// ocrImage reads the text in an image with tesseract
+func ocrImage(path string) (string, error) {
+	tesseract, err := exec.LookPath("tesseract")
+	if err != nil {
+		return "", fmt.Errorf("tesseract not found; install it to read scanned documents")
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(tesseract, path, "stdout")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("tesseract failed for %s: %v: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out[:min(len(out), maxDocumentText)]), nil
+}
+
+// DocumentAnalyzer finds the date a PDF or text document is about, so it can
+// be filed by {doc_date}, {doc_month} or {doc_year} rather than when it was scanned
+type DocumentAnalyzer struct {
+	OCR      bool // Read scanned PDFs with tesseract
+	DayFirst bool // Read 03/04/2024 as 3 April
+}
+
+// CanHandle checks if the content type is a PDF or plain text
+func (a *DocumentAnalyzer) CanHandle(contentType string) bool {
+	return contentType == "application/pdf" || strings.HasPrefix(contentType, "text/plain")
+}
+
+// Analyze adds the document date to the metadata
+func (a *DocumentAnalyzer) Analyze(path string, info *types.FileInfo) (*types.FileInfo, error) {
+	logger := log.LogWithFields(log.F("path", path))
+
+	if !contains(info.Tags, "document") {
+		info.Tags = append(info.Tags, "document")
+	}
+	if info.Metadata == nil {
+		info.Metadata = make(map[string]string)
+	}
+
+	text, err := ExtractText(path, info.ContentType, a.OCR)
+	if err != nil {
+		return info, err
+	}
+	for key, value := range documentDateMetadata(text, a.DayFirst) {
+		info.Metadata[key] = value
+	}
+	logger.Debugf("Document date: %q", info.Metadata["doc_date"])
+	return info, nil
+}
@@ -0,0 +1,62 @@
+package analysis_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/analysis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractDocumentDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		dayFirst bool
+		want     string
+	}{
+		{"ISO", "Generated 2024-03-15 by the bank", false, "2024-03-15"},
+		{"MonthFirst", "Paid on 03/04/2024", false, "2024-03-04"},
+		{"DayFirst", "Paid on 03/04/2024", true, "2024-04-03"},
+		{"UnambiguousDay", "Paid on 28/02/2024", false, "2024-02-28"},
+		{"DayMonthName", "Issued 5th Jan 2023", false, "2023-01-05"},
+		{"MonthNameDay", "Due September 30, 2023", false, "2023-09-30"},
+		{"MonthYear", "Your statement for March 2024", false, "2024-03-01"},
+		{
+			"LabelledDateWins",
+			"01/02/2024 Coffee  3.50\n01/09/2024 Groceries  52.10\nStatement date: 01/31/2024\n",
+			false,
+			"2024-01-31",
+		},
+		{"ImpossibleDateSkipped", "Ref 31/02/2024, printed 2024-02-29", false, "2024-02-29"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, ok := analysis.ExtractDocumentDate(tt.text, tt.dayFirst)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, date.Format("2006-01-02"))
+		})
+	}
+
+	_, ok := analysis.ExtractDocumentDate("No dates here, just 12345 and 1/2", false)
+	assert.False(t, ok)
+
+	_, ok = analysis.ExtractDocumentDate(time.Now().AddDate(5, 0, 0).Format("2006-01-02"), false)
+	assert.False(t, ok, "Dates far in the future are not document dates")
+}
+
+func TestDocumentAnalyzer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "statement.txt")
+	require.NoError(t, os.WriteFile(path, []byte("ACME Bank\nStatement date: 2024-01-31\n"), 0644))
+
+	info, err := analysis.New().Analyze(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-31", info.Metadata["doc_date"])
+	assert.Equal(t, "2024-01", info.Metadata["doc_month"])
+	assert.Equal(t, "2024", info.Metadata["doc_year"])
+}
@@ -1,11 +1,13 @@
 package analysis
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/rwcarlsen/goexif/exif"
@@ -27,8 +29,16 @@ type Analyzer interface {
 
 // --- Concrete Analyzer Implementations ---
 
-// ImageAnalyzer handles analysis for image files using EXIF data
-type ImageAnalyzer struct{}
+// ImageAnalyzer handles analysis for image files using EXIF data and, when a
+// tagger is set, content tags such as "screenshot" or "receipt"
+type ImageAnalyzer struct {
+	Tagger ImageTagger
+
+	// With OCR set, the text in images (scanned documents) is read with
+	// tesseract for a {doc_date}
+	OCR      bool
+	DayFirst bool
+}
 
 // CanHandle checks if the content type is an image type that might contain EXIF data
 func (a *ImageAnalyzer) CanHandle(contentType string) bool {
@@ -51,6 +61,28 @@ func (a *ImageAnalyzer) Analyze(path string, info *types.FileInfo) (*types.FileI
 		info.Metadata = make(map[string]string)
 	}
 
+	if a.Tagger != nil {
+		tags, err := a.Tagger.TagImage(path)
+		if err != nil {
+			logger.Debugf("Image tagging failed for %s: %v", path, err)
+		}
+		for _, tag := range tags {
+			if !contains(info.Tags, tag) {
+				info.Tags = append(info.Tags, tag)
+			}
+		}
+	}
+
+	if a.OCR {
+		if text, err := ocrImage(path); err != nil {
+			logger.Debugf("OCR failed for %s: %v", path, err)
+		} else {
+			for key, value := range documentDateMetadata(text, a.DayFirst) {
+				info.Metadata[key] = value
+			}
+		}
+	}
+
 	file, err := os.Open(path)
 	if err != nil {
 		return info, fmt.Errorf("failed to open image file for exif: %w", err)
@@ -108,7 +140,9 @@ func (e *Engine) registerAnalyzer(analyzer Analyzer) {
 func New() *Engine {
 	exif.RegisterParsers(mknote.All...)
 	engine := &Engine{}
-	engine.registerAnalyzer(&ImageAnalyzer{}) // Register image analyzer
+	engine.registerAnalyzer(&ImageAnalyzer{})    // Register image analyzer
+	engine.registerAnalyzer(&VideoAnalyzer{})    // Register video analyzer
+	engine.registerAnalyzer(&DocumentAnalyzer{}) // Register document analyzer
 	// TODO: Register other analyzers when implemented
 	return engine
 }
@@ -117,6 +151,40 @@ func New() *Engine {
 func NewWithConfig(cfg *config.Config) *Engine {
 	engine := New()
 	engine.config = cfg
+
+	if cfg != nil && cfg.Settings.Video.Thumbnails && cfg.Directories.Default != "" {
+		for _, analyzer := range engine.analyzers {
+			if video, ok := analyzer.(*VideoAnalyzer); ok {
+				video.ThumbnailDir = DefaultThumbnailDir(cfg.Directories.Default)
+			}
+		}
+	}
+
+	if cfg != nil {
+		for _, analyzer := range engine.analyzers {
+			switch a := analyzer.(type) {
+			case *DocumentAnalyzer:
+				a.OCR = cfg.Settings.Documents.OCR
+				a.DayFirst = cfg.Settings.Documents.DayFirst
+			case *ImageAnalyzer:
+				a.OCR = cfg.Settings.Documents.OCR
+				a.DayFirst = cfg.Settings.Documents.DayFirst
+			}
+		}
+	}
+
+	if cfg != nil && cfg.Settings.ImageTagging.Enabled {
+		tagger, err := NewImageTagger(cfg.Settings.ImageTagging)
+		if err != nil {
+			log.Warnf("Image tagging disabled: %v", err)
+		} else {
+			for _, analyzer := range engine.analyzers {
+				if image, ok := analyzer.(*ImageAnalyzer); ok {
+					image.Tagger = tagger
+				}
+			}
+		}
+	}
 	return engine
 }
 
@@ -178,12 +246,10 @@ func (e *Engine) Scan(path string) (*types.FileInfo, error) {
 	}
 
 	logger.Info("File scanned successfully")
-	return &types.FileInfo{
-		Path:        path,
-		ContentType: contentType,
-		Size:        info.Size(),
-		Tags:        tags,
-	}, nil
+	fileInfo := types.NewFileInfo(path, info)
+	fileInfo.ContentType = contentType
+	fileInfo.Tags = tags
+	return fileInfo, nil
 }
 
 // Process performs file analysis with additional processing
@@ -210,46 +276,93 @@ func (e *Engine) Process(path string) (*types.FileInfo, error) {
 
 // ScanDirectory performs analysis on entries (files and dirs) in a single directory level.
 func (e *Engine) ScanDirectory(dir string) ([]*types.FileInfo, error) {
+	return e.ScanDirectoryContext(context.Background(), dir)
+}
+
+// ScanDirectoryContext is ScanDirectory that stops when ctx is cancelled,
+// returning the entries scanned so far along with ctx's error
+func (e *Engine) ScanDirectoryContext(ctx context.Context, dir string) ([]*types.FileInfo, error) {
+	var results []*types.FileInfo
+	err := e.ScanDirectoryFunc(ctx, dir, func(fileInfo *types.FileInfo) error {
+		results = append(results, fileInfo)
+		return nil
+	})
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results, err
+}
+
+// scanBatchSize is how many directory entries are read at a time when streaming
+const scanBatchSize = 256
+
+// ScanDirectoryFunc scans a single directory level and hands each entry to fn
+// as soon as it has been scanned, so callers can show results while a large
+// directory is still being read. Entries come in directory order, not sorted.
+// Entries that can't be scanned are skipped. Scanning stops at the first error
+// fn returns, or when ctx is cancelled, and that error is returned.
+func (e *Engine) ScanDirectoryFunc(ctx context.Context, dir string, fn func(*types.FileInfo) error) error {
 	logger := log.LogWithFields(log.F("directory", dir))
 
-	entries, err := os.ReadDir(dir)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d, err := os.Open(dir)
 	if err != nil {
-		return nil, serr.NewFileError("failed to read directory", dir, serr.FileAccessDenied, err)
+		return serr.NewFileError("failed to read directory", dir, serr.FileAccessDenied, err)
 	}
+	defer d.Close()
 
-	var results []*types.FileInfo
-	for _, entry := range entries {
-		path := filepath.Join(dir, entry.Name())
-		var fileInfo *types.FileInfo
-		var scanErr error
-
-		if entry.IsDir() {
-			// Create a FileInfo for the directory
-			fileInfo = &types.FileInfo{
-				Path:        path,
-				ContentType: "inode/directory",     // Convention for directories
-				Size:        0,                     // Directories don't have a size in this context
-				Tags:        []string{"directory"}, // Add a 'directory' tag
+	for {
+		entries, readErr := d.ReadDir(scanBatchSize)
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
-		} else {
-			// It's a file, use the Scan method
-			fileInfo, scanErr = e.Scan(path)
-			if scanErr != nil {
-				logger.ErrorWithStack(scanErr, "Error scanning file")
-				continue // Skip this file
+
+			path := filepath.Join(dir, entry.Name())
+			var fileInfo *types.FileInfo
+			if entry.IsDir() {
+				// Directories aren't scanned; the entry already knows enough
+				info, err := entry.Info()
+				if err != nil {
+					continue // Removed since the directory was read
+				}
+				fileInfo = types.NewFileInfo(path, info)
+			} else {
+				// It's a file, use the Scan method
+				var scanErr error
+				fileInfo, scanErr = e.Scan(path)
+				if scanErr != nil {
+					logger.ErrorWithStack(scanErr, "Error scanning file")
+					continue // Skip this file
+				}
+			}
+
+			if err := fn(fileInfo); err != nil {
+				return err
 			}
 		}
 
-		results = append(results, fileInfo)
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return serr.NewFileError("failed to read directory", dir, serr.FileAccessDenied, readErr)
+		}
 	}
-
-	// Note: Sorting is handled by the caller (e.g., TUI) if needed.
-	return results, nil
 }
 
 // Analyze performs analysis by delegating to registered analyzers
 func (e *Engine) Analyze(path string) (*types.FileInfo, error) {
+	return e.AnalyzeContext(context.Background(), path)
+}
+
+// AnalyzeContext is Analyze that gives up before the slower analyzers run
+// if ctx has been cancelled
+func (e *Engine) AnalyzeContext(ctx context.Context, path string) (*types.FileInfo, error) {
 	logger := log.LogWithFields(log.F("path", path))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	fileInfo, err := e.Scan(path)
 	if err != nil {
 		return nil, err
@@ -262,6 +375,9 @@ func (e *Engine) Analyze(path string) (*types.FileInfo, error) {
 	foundAnalyzer := false
 	for _, analyzer := range e.analyzers {
 		if analyzer.CanHandle(fileInfo.ContentType) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			foundAnalyzer = true
 			logger.Debugf("Using analyzer %T for content type %s", analyzer, fileInfo.ContentType)
 			fileInfo, analysisErr = analyzer.Analyze(path, fileInfo)
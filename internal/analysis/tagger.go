@@ -0,0 +1,234 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF for image.DecodeConfig
+	_ "image/jpeg" // Register JPEG for image.DecodeConfig
+	_ "image/png"  // Register PNG for image.DecodeConfig
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+
+	"sortd/internal/config"
+)
+
+// ImageTagger describes what an image shows with short tags such as
+// "receipt", "screenshot", "person" or "landscape"
+type ImageTagger interface {
+	TagImage(path string) ([]string, error)
+}
+
+// maxTaggedImageBytes bounds the size of an image sent to the local model
+const maxTaggedImageBytes = 32 << 20
+
+// defaultMinScore is the confidence below which model labels are ignored
+const defaultMinScore = 0.5
+
+// NewImageTagger creates the tagger described by the settings: the built-in
+// heuristics, plus a local model when an endpoint is configured
+func NewImageTagger(settings config.ImageTaggingSettings) (ImageTagger, error) {
+	taggers := multiTagger{HeuristicTagger{}}
+	if settings.Endpoint != "" {
+		model, err := NewLocalModelTagger(settings.Endpoint, settings.MinScore)
+		if err != nil {
+			return nil, err
+		}
+		taggers = append(taggers, model)
+	}
+	return taggers, nil
+}
+
+// multiTagger merges the tags of several taggers. A failing tagger doesn't
+// discard the tags the others found.
+type multiTagger []ImageTagger
+
+// TagImage returns the sorted union of every tagger's tags
+func (m multiTagger) TagImage(path string) ([]string, error) {
+	seen := make(map[string]bool)
+	var firstErr error
+	for _, tagger := range m {
+		tags, err := tagger.TagImage(path)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		for _, tag := range tags {
+			seen[tag] = true
+		}
+	}
+
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, firstErr
+}
+
+// HeuristicTagger recognises screenshots and camera photos without a model
+type HeuristicTagger struct{}
+
+// screenAspects are the width:height ratios of common displays, both orientations
+var screenAspects = []float64{16.0 / 9, 16.0 / 10, 4.0 / 3, 3.0 / 2, 19.5 / 9, 9.0 / 16, 10.0 / 16, 3.0 / 4, 2.0 / 3, 9.0 / 19.5}
+
+// TagImage tags images with camera EXIF data as "photo" and screen-shaped
+// images without it (or named like one) as "screenshot"
+func (HeuristicTagger) TagImage(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg, format, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, nil // Not an image we can read
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	hasCamera := false
+	if x, err := exif.Decode(f); err == nil {
+		if _, err := x.Get(exif.Model); err == nil {
+			hasCamera = true
+		}
+	}
+	if hasCamera {
+		return []string{"photo"}, nil
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	if strings.Contains(name, "screenshot") || strings.Contains(name, "screen shot") {
+		return []string{"screenshot"}, nil
+	}
+	if format == "png" && cfg.Width >= 640 && cfg.Height >= 640 && isScreenAspect(cfg.Width, cfg.Height) {
+		return []string{"screenshot"}, nil
+	}
+	return nil, nil
+}
+
+// isScreenAspect reports whether the dimensions match a common display shape
+func isScreenAspect(width, height int) bool {
+	ratio := float64(width) / float64(height)
+	for _, aspect := range screenAspects {
+		if ratio > aspect*0.98 && ratio < aspect*1.02 {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalModelTagger asks an image classifier running on this machine (for
+// example MobileNet served through ONNX Runtime) for labels. Images never
+// leave the machine: the endpoint must be a loopback address, and the client
+// refuses to connect anywhere else, redirects included.
+type LocalModelTagger struct {
+	endpoint string
+	minScore float64
+	client   *http.Client
+}
+
+// modelLabel is one label returned by the local model
+type modelLabel struct {
+	Label string  `json:"label"`
+	Score float64 `json:"score"`
+}
+
+// NewLocalModelTagger creates a tagger for a classifier listening on loopback
+func NewLocalModelTagger(endpoint string, minScore float64) (*LocalModelTagger, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image tagging endpoint: %w", err)
+	}
+	if !isLoopbackHost(u.Hostname()) {
+		return nil, fmt.Errorf("image tagging endpoint %s is not on this machine; only loopback addresses are allowed", u.Host)
+	}
+	if minScore <= 0 {
+		minScore = defaultMinScore
+	}
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport := &http.Transport{
+		Proxy: nil, // Never route images through a proxy
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			for _, ip := range ips {
+				if !ip.IP.IsLoopback() {
+					return nil, fmt.Errorf("refusing to send image to non-loopback address %s", ip.IP)
+				}
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+
+	return &LocalModelTagger{
+		endpoint: endpoint,
+		minScore: minScore,
+		client:   &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}, nil
+}
+
+// isLoopbackHost reports whether a host name or address refers to this machine
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// TagImage posts the image to the model and returns the labels scoring at
+// least the minimum score
+func (t *LocalModelTagger) TagImage(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTaggedImageBytes {
+		return nil, fmt.Errorf("image too large to tag: %s", path)
+	}
+
+	resp, err := t.client.Post(t.endpoint, http.DetectContentType(data), bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("image tagging request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("image tagging model returned %s", resp.Status)
+	}
+
+	var parsed struct {
+		Labels []modelLabel `json:"labels"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse image tagging response: %w", err)
+	}
+
+	var tags []string
+	for _, label := range parsed.Labels {
+		if label.Score >= t.minScore && label.Label != "" {
+			tags = append(tags, strings.ToLower(strings.TrimSpace(label.Label)))
+		}
+	}
+	return tags, nil
+}
@@ -0,0 +1,90 @@
+package analysis_test
+
+import (
+	"encoding/json"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/analysis"
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePNG creates a blank PNG of the given size
+func writePNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, png.Encode(f, image.NewGray(image.Rect(0, 0, width, height))))
+}
+
+func TestHeuristicTagger(t *testing.T) {
+	dir := t.TempDir()
+	tagger := analysis.HeuristicTagger{}
+
+	screen := filepath.Join(dir, "capture.png")
+	writePNG(t, screen, 1920, 1080)
+	tags, err := tagger.TagImage(screen)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"screenshot"}, tags)
+
+	named := filepath.Join(dir, "Screenshot 2024-01-01.png")
+	writePNG(t, named, 300, 200)
+	tags, err = tagger.TagImage(named)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"screenshot"}, tags)
+
+	icon := filepath.Join(dir, "icon.png")
+	writePNG(t, icon, 64, 64)
+	tags, err = tagger.TagImage(icon)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+}
+
+func TestLocalModelTagger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.NotEmpty(t, body, "The image should be posted to the model")
+		json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{
+			{"label": "Receipt", "score": 0.91},
+			{"label": "person", "score": 0.2},
+		}})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "scan.png")
+	writePNG(t, path, 64, 64)
+
+	tagger, err := analysis.NewImageTagger(config.ImageTaggingSettings{Enabled: true, Endpoint: server.URL, MinScore: 0.5})
+	require.NoError(t, err)
+	tags, err := tagger.TagImage(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"receipt"}, tags, "Labels below the minimum score should be dropped")
+}
+
+func TestLocalModelTaggerIsOfflineOnly(t *testing.T) {
+	_, err := analysis.NewLocalModelTagger("https://vision.example.com/classify", 0.5)
+	assert.Error(t, err, "A remote endpoint must be rejected")
+
+	_, err = analysis.NewLocalModelTagger("http://localhost:8765/classify", 0.5)
+	assert.NoError(t, err)
+}
+
+func TestImageTaggingConfigValidation(t *testing.T) {
+	cfg := config.New()
+	cfg.Settings.Collision = "rename"
+	cfg.Settings.ImageTagging = config.ImageTaggingSettings{Enabled: true, Endpoint: "http://10.0.0.5/classify"}
+	assert.Error(t, cfg.Validate())
+
+	cfg.Settings.ImageTagging.Endpoint = "http://127.0.0.1:8765/classify"
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,230 @@
+package analysis
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "sortd/internal/log"
+	"sortd/pkg/types"
+)
+
+// thumbnailDir is the directory, in the default directory, video thumbnails are kept in
+const thumbnailDir = ".sortd.thumbnails"
+
+// thumbnailWidth is the width of generated thumbnails in pixels
+const thumbnailWidth = 320
+
+// VideoInfo is what ffprobe reports about a video
+type VideoInfo struct {
+	Duration   time.Duration
+	Width      int
+	Height     int
+	VideoCodec string
+	AudioCodec string
+}
+
+// Resolution labels the video by its shorter side: "4K", "1080p", "720p", ...
+func (v *VideoInfo) Resolution() string {
+	side := min(v.Width, v.Height)
+	switch {
+	case side >= 2160:
+		return "4K"
+	case side >= 1440:
+		return "1440p"
+	case side >= 1080:
+		return "1080p"
+	case side >= 720:
+		return "720p"
+	case side >= 480:
+		return "480p"
+	case side > 0:
+		return "SD"
+	default:
+		return ""
+	}
+}
+
+// DurationBucket groups the video by length: clip, short, medium, long or feature
+func (v *VideoInfo) DurationBucket() string {
+	switch {
+	case v.Duration <= 0:
+		return ""
+	case v.Duration < time.Minute:
+		return "clip"
+	case v.Duration < 10*time.Minute:
+		return "short"
+	case v.Duration < 30*time.Minute:
+		return "medium"
+	case v.Duration < 90*time.Minute:
+		return "long"
+	default:
+		return "feature"
+	}
+}
+
+// Metadata returns the video's properties as FileInfo metadata, also usable as
+// {resolution} or {duration_bucket} in workflow targets
+func (v *VideoInfo) Metadata() map[string]string {
+	metadata := map[string]string{
+		"duration":        strconv.Itoa(int(v.Duration.Seconds())),
+		"width":           strconv.Itoa(v.Width),
+		"height":          strconv.Itoa(v.Height),
+		"resolution":      v.Resolution(),
+		"duration_bucket": v.DurationBucket(),
+		"video_codec":     v.VideoCodec,
+		"audio_codec":     v.AudioCodec,
+	}
+	for key, value := range metadata {
+		if value == "" {
+			delete(metadata, key)
+		}
+	}
+	return metadata
+}
+
+// ParseProbeOutput reads the JSON written by
+// ffprobe -print_format json -show_format -show_streams
+func ParseProbeOutput(data []byte) (*VideoInfo, error) {
+	var probe struct {
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	info := &VideoInfo{}
+	if seconds, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	for _, stream := range probe.Streams {
+		switch {
+		case stream.CodecType == "video" && info.VideoCodec == "":
+			info.VideoCodec = stream.CodecName
+			info.Width = stream.Width
+			info.Height = stream.Height
+		case stream.CodecType == "audio" && info.AudioCodec == "":
+			info.AudioCodec = stream.CodecName
+		}
+	}
+	if info.VideoCodec == "" {
+		return nil, fmt.Errorf("no video stream found")
+	}
+	return info, nil
+}
+
+// ProbeVideo runs ffprobe on a video
+func ProbeVideo(path string) (*VideoInfo, error) {
+	ffprobe, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe not found; install FFmpeg to read video metadata")
+	}
+
+	out, err := exec.Command(ffprobe, "-v", "error", "-print_format", "json", "-show_format", "-show_streams", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed for %s: %w", path, err)
+	}
+	return ParseProbeOutput(out)
+}
+
+// DefaultThumbnailDir returns the thumbnail directory for a default directory
+func DefaultThumbnailDir(defaultDir string) string {
+	return filepath.Join(defaultDir, thumbnailDir)
+}
+
+// ThumbnailPath returns where the thumbnail of a file is stored. The name
+// depends on the file's size and modification time, so edits get a new one.
+func ThumbnailPath(dir, path string, info os.FileInfo) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s\x00%d\x00%d", path, info.Size(), info.ModTime().UnixNano())))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".jpg")
+}
+
+// GenerateThumbnail saves a frame from a tenth of the way into the video as a
+// JPEG in dir, unless a current one exists, and returns its path
+func GenerateThumbnail(path, dir string, video *VideoInfo) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	thumbnail := ThumbnailPath(dir, path, info)
+	if _, err := os.Stat(thumbnail); err == nil {
+		return thumbnail, nil
+	}
+
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return "", fmt.Errorf("ffmpeg not found; install FFmpeg to generate thumbnails")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create thumbnail directory: %w", err)
+	}
+
+	offset := fmt.Sprintf("%.3f", (video.Duration / 10).Seconds())
+	cmd := exec.Command(ffmpeg, "-v", "error", "-y", "-ss", offset, "-i", path,
+		"-frames:v", "1", "-vf", fmt.Sprintf("scale=%d:-2", thumbnailWidth), thumbnail)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(thumbnail)
+		return "", fmt.Errorf("ffmpeg failed for %s: %v: %s", path, err, strings.TrimSpace(string(out)))
+	}
+	return thumbnail, nil
+}
+
+// VideoAnalyzer reads duration, resolution and codecs with ffprobe and, when
+// ThumbnailDir is set, stores a preview frame there
+type VideoAnalyzer struct {
+	ThumbnailDir string
+}
+
+// CanHandle checks if the content type is a video
+func (a *VideoAnalyzer) CanHandle(contentType string) bool {
+	return strings.HasPrefix(contentType, "video/")
+}
+
+// Analyze adds the video's properties to the metadata
+func (a *VideoAnalyzer) Analyze(path string, info *types.FileInfo) (*types.FileInfo, error) {
+	logger := log.LogWithFields(log.F("path", path))
+
+	if !contains(info.Tags, "video") {
+		info.Tags = append(info.Tags, "video")
+	}
+	if info.Metadata == nil {
+		info.Metadata = make(map[string]string)
+	}
+
+	video, err := ProbeVideo(path)
+	if err != nil {
+		return info, err
+	}
+	for key, value := range video.Metadata() {
+		info.Metadata[key] = value
+	}
+
+	if a.ThumbnailDir != "" {
+		thumbnail, err := GenerateThumbnail(path, a.ThumbnailDir, video)
+		if err != nil {
+			logger.Debugf("No thumbnail for %s: %v", path, err)
+		} else {
+			info.Metadata["thumbnail"] = thumbnail
+		}
+	}
+
+	return info, nil
+}
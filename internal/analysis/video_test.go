@@ -0,0 +1,54 @@
+package analysis_test
+
+import (
+	"testing"
+	"time"
+
+	"sortd/internal/analysis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProbeOutput(t *testing.T) {
+	output := []byte(`{
+		"streams": [
+			{"codec_type": "audio", "codec_name": "aac"},
+			{"codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080}
+		],
+		"format": {"duration": "754.320000"}
+	}`)
+
+	video, err := analysis.ParseProbeOutput(output)
+	require.NoError(t, err)
+	assert.Equal(t, "h264", video.VideoCodec)
+	assert.Equal(t, "aac", video.AudioCodec)
+
+	metadata := video.Metadata()
+	assert.Equal(t, "1080p", metadata["resolution"])
+	assert.Equal(t, "medium", metadata["duration_bucket"])
+	assert.Equal(t, "754", metadata["duration"])
+
+	_, err = analysis.ParseProbeOutput([]byte(`{"streams": [{"codec_type": "audio", "codec_name": "mp3"}]}`))
+	assert.Error(t, err, "Audio-only files are not videos")
+}
+
+func TestVideoLabels(t *testing.T) {
+	tests := []struct {
+		width, height int
+		duration      time.Duration
+		resolution    string
+		bucket        string
+	}{
+		{3840, 2160, 2 * time.Hour, "4K", "feature"},
+		{1080, 1920, 30 * time.Second, "1080p", "clip"}, // Portrait phone video
+		{1280, 720, 5 * time.Minute, "720p", "short"},
+		{640, 360, 45 * time.Minute, "SD", "long"},
+	}
+
+	for _, tt := range tests {
+		video := &analysis.VideoInfo{Width: tt.width, Height: tt.height, Duration: tt.duration}
+		assert.Equal(t, tt.resolution, video.Resolution(), "%dx%d", tt.width, tt.height)
+		assert.Equal(t, tt.bucket, video.DurationBucket(), "%v", tt.duration)
+	}
+}
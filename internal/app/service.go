@@ -0,0 +1,400 @@
+// Package app is the application core shared by sortd's frontends. The CLI,
+// the GUI and the daemon plan and run organize jobs and control the watcher
+// through a Service rather than each matching rules on their own.
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/organize"
+	"sortd/internal/pending"
+	"sortd/internal/screenshot"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+)
+
+// Move is a single planned move
+type Move struct {
+	ID          string // Stable ID of the move, from its source, destination and pattern
+	Source      string
+	Destination string // Full destination path, before collision handling
+	Pattern     string // The match of the pattern that placed the file
+	Unmatched   bool   // No pattern matched; settings.unmatched.policy sends it to the unsorted folder
+	Screenshot  bool   // Renamed and filed by month by settings.screenshots rather than a pattern
+	Copy        bool   // The file is copied and the original left in place
+}
+
+// Plan is what organizing a file or directory would do
+type Plan struct {
+	Root      string
+	Moves     []Move
+	Unmatched []string         // Files no pattern applies to that stay where they are
+	Listing   organize.Listing // The files considered, and whether a limit cut them short
+}
+
+// Only returns a copy of the plan narrowed to moves of the given sources,
+// for frontends that let the user pick files after planning
+func (p *Plan) Only(sources []string) *Plan {
+	keep := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		keep[source] = true
+	}
+
+	narrowed := &Plan{Root: p.Root, Unmatched: p.Unmatched, Listing: p.Listing}
+	for _, move := range p.Moves {
+		if keep[move.Source] {
+			narrowed.Moves = append(narrowed.Moves, move)
+		}
+	}
+	return narrowed
+}
+
+// OnlyIDs returns a copy of the plan narrowed to the moves with the given IDs,
+// along with any of the IDs no move in the plan has
+func (p *Plan) OnlyIDs(ids []string) (*Plan, []string) {
+	var sources, missing []string
+	for _, id := range ids {
+		move, ok := p.Move(id)
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		sources = append(sources, move.Source)
+	}
+	return p.Only(sources), missing
+}
+
+// Move returns the planned move with the given ID
+func (p *Plan) Move(id string) (Move, bool) {
+	for _, move := range p.Moves {
+		if move.ID == id {
+			return move, true
+		}
+	}
+	return Move{}, false
+}
+
+// sort puts moves and unmatched files in path order
+func (p *Plan) sort() {
+	sort.SliceStable(p.Moves, func(i, j int) bool {
+		if p.Moves[i].Source != p.Moves[j].Source {
+			return p.Moves[i].Source < p.Moves[j].Source
+		}
+		return p.Moves[i].Destination < p.Moves[j].Destination
+	})
+	sort.Strings(p.Unmatched)
+}
+
+// Sources returns the files the plan moves, in order
+func (p *Plan) Sources() []string {
+	sources := make([]string, len(p.Moves))
+	for i, move := range p.Moves {
+		sources[i] = move.Source
+	}
+	return sources
+}
+
+// PlanOptions control which files PlanOrganize considers
+type PlanOptions struct {
+	Recursive bool // Include subdirectories, within settings.max_depth
+}
+
+// Service plans and runs organize jobs and controls the watch daemon
+type Service struct {
+	cfg    *config.Config
+	engine *organize.Engine
+
+	watchMu sync.Mutex
+	daemon  *watch.Daemon
+}
+
+// New creates a service with an organize engine built from cfg
+func New(cfg *config.Config) *Service {
+	return NewWithEngine(cfg, organize.NewWithConfig(cfg))
+}
+
+// NewWithEngine creates a service around an existing organize engine
+func NewWithEngine(cfg *config.Config, engine *organize.Engine) *Service {
+	return &Service{cfg: cfg, engine: engine}
+}
+
+// Engine returns the organize engine the service moves files with
+func (s *Service) Engine() *organize.Engine {
+	return s.engine
+}
+
+// SetDryRun sets whether Execute only reports what it would move
+func (s *Service) SetDryRun(dryRun bool) {
+	s.engine.SetDryRun(dryRun)
+}
+
+// DryRun reports whether Execute only reports what it would move
+func (s *Service) DryRun() bool {
+	return s.engine.IsDryRun()
+}
+
+// PlanOrganize works out where the configured patterns send each file under
+// path, or path itself if it's a file. Nothing is moved.
+func (s *Service) PlanOrganize(ctx context.Context, path string, opts PlanOptions) (*Plan, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error accessing %s: %w", path, err)
+	}
+
+	plan := &Plan{Root: path}
+	files := []string{path}
+	if info.IsDir() {
+		// Pattern depths count from the directory being organized
+		s.engine.SetRoots([]string{path})
+		plan.Listing, err = organize.ListFiles(ctx, path, opts.Recursive, organize.LimitsFromConfig(s.cfg))
+		if err != nil {
+			return nil, fmt.Errorf("error finding files: %w", err)
+		}
+		files = plan.Listing.Files
+	} else {
+		plan.Listing = organize.Listing{Files: files}
+	}
+
+	if err := s.planFiles(ctx, plan, files); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// PlanFiles works out where the configured patterns send each of the given files,
+// for frontends that gather files themselves. Nothing is moved.
+func (s *Service) PlanFiles(ctx context.Context, files []string) (*Plan, error) {
+	plan := &Plan{Listing: organize.Listing{Files: files}}
+	if err := s.planFiles(ctx, plan, files); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planFiles adds a move or an unmatched entry to plan for each file. Moves and
+// unmatched files are sorted by path, so planning the same files twice gives
+// plans that diff cleanly whatever order the files arrived in.
+func (s *Service) planFiles(ctx context.Context, plan *Plan, files []string) error {
+	reserved := make(map[string]bool)
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// With settings.screenshots on, screenshots are filed ahead of any
+		// rule, and those already filed stay put
+		if screenshot.Enabled(s.cfg) {
+			if screenshot.InFolder(s.cfg, file) {
+				continue
+			}
+			if info, ok := screenshot.Detect(file, s.cfg.Settings.Screenshots.OCR); ok {
+				dest := screenshot.Destination(s.cfg, info, reserved)
+				plan.Moves = append(plan.Moves, Move{
+					ID:          types.OperationID(file, dest, ""),
+					Source:      file,
+					Destination: dest,
+					Screenshot:  true,
+					Copy:        s.engine.Copies(file),
+				})
+				continue
+			}
+		}
+
+		pattern, found := s.engine.MatchingPattern(file)
+		if !found {
+			if organize.UnmatchedPolicy(s.cfg) == organize.UnmatchedMove && !organize.InUnsorted(s.cfg, file) {
+				dest := organize.UnsortedDestination(s.cfg, file, time.Now())
+				plan.Moves = append(plan.Moves, Move{
+					ID:          types.OperationID(file, dest, ""),
+					Source:      file,
+					Destination: dest,
+					Unmatched:   true,
+					Copy:        s.engine.Copies(file),
+				})
+				continue
+			}
+			plan.Unmatched = append(plan.Unmatched, file)
+			continue
+		}
+		destDir, _ := s.engine.DestinationDir(file)
+		dest := filepath.Join(destDir, filepath.Base(file))
+		plan.Moves = append(plan.Moves, Move{
+			ID:          types.OperationID(file, dest, pattern.Match),
+			Source:      file,
+			Destination: dest,
+			Pattern:     pattern.Match,
+			Copy:        s.engine.Copies(file),
+		})
+	}
+	plan.sort()
+	return nil
+}
+
+// Execute carries out a plan, one result per move. It stops before the next
+// move once ctx is cancelled, returning the results so far with ctx's error.
+// In dry-run mode nothing is moved and no result is marked Moved; in read-only
+// mode every move fails with a ReadOnlyMode error.
+func (s *Service) Execute(ctx context.Context, plan *Plan) ([]types.OrganizeResult, error) {
+	// Read-only mode may have been switched on by another process since the
+	// engine was built
+	s.engine.SetReadOnly(s.ReadOnly())
+
+	results := make([]types.OrganizeResult, 0, len(plan.Moves))
+	for _, move := range plan.Moves {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result := types.OrganizeResult{ID: move.ID, SourcePath: move.Source, DestinationPath: move.Destination}
+		if final, err := s.engine.MoveFileTo(ctx, move.Source, move.Destination); err != nil {
+			result.Error = err
+		} else if final != "" {
+			result.DestinationPath = final
+			result.Moved = !s.engine.IsDryRun()
+			result.Copied = result.Moved && move.Copy
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Undo reverses the moves in results, last first, so a run started from a
+// frontend can be taken back in one step. It returns a result per file put
+// back, with source and destination the way round the file travelled this
+// time; results that moved nothing are skipped.
+func (s *Service) Undo(ctx context.Context, results []types.OrganizeResult) ([]types.OrganizeResult, error) {
+	s.engine.SetReadOnly(s.ReadOnly())
+
+	undone := make([]types.OrganizeResult, 0, len(results))
+	for i := len(results) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return undone, err
+		}
+		result := results[i]
+		if !result.Moved || result.Error != nil {
+			continue
+		}
+
+		back := types.OrganizeResult{ID: result.ID, SourcePath: result.DestinationPath, DestinationPath: result.SourcePath}
+		if err := s.engine.Undo(result); err != nil {
+			back.Error = err
+		} else {
+			back.Moved = !s.engine.IsDryRun()
+		}
+		undone = append(undone, back)
+	}
+	return undone, nil
+}
+
+// ReviewUnmatched queues a move to the unsorted folder in the pending queue for
+// each of the plan's unmatched files when settings.unmatched.policy is
+// "review", returning how many were queued. Files already in the unsorted
+// folder aren't queued again.
+func (s *Service) ReviewUnmatched(plan *Plan) (int, error) {
+	if organize.UnmatchedPolicy(s.cfg) != organize.UnmatchedReview || len(plan.Unmatched) == 0 {
+		return 0, nil
+	}
+
+	queue, err := pending.Open(pending.DefaultPath(s.cfg))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open pending queue: %w", err)
+	}
+	queued := 0
+	for _, file := range plan.Unmatched {
+		if organize.InUnsorted(s.cfg, file) {
+			continue
+		}
+		item := pending.Item{
+			Path:        file,
+			Destination: organize.UnsortedDestination(s.cfg, file, time.Now()),
+			Reason:      pending.ReasonUnmatched,
+		}
+		if _, err := queue.Add(item); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// Organize plans and executes in one step
+func (s *Service) Organize(ctx context.Context, path string, opts PlanOptions) ([]types.OrganizeResult, error) {
+	plan, err := s.PlanOrganize(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.Execute(ctx, plan)
+}
+
+// ReadOnly reports whether read-only mode is on
+func (s *Service) ReadOnly() bool {
+	return watch.ReadOnly(s.cfg)
+}
+
+// SetReadOnly switches read-only mode on or off for every sortd process
+// sharing the configuration, including a running watcher
+func (s *Service) SetReadOnly(readOnly bool) error {
+	if err := watch.SetReadOnly(s.cfg, readOnly); err != nil {
+		return err
+	}
+	s.engine.SetReadOnly(s.ReadOnly())
+
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	if s.daemon != nil {
+		s.daemon.SetReadOnly(readOnly)
+	}
+	return nil
+}
+
+// StartWatch starts a watch daemon for the configured watch directories,
+// honouring the current dry-run setting. A stopped watcher can be started again.
+func (s *Service) StartWatch() error {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	if s.daemon != nil && s.daemon.Status().Running {
+		return fmt.Errorf("watch mode is already running")
+	}
+
+	daemon, err := watch.NewDaemon(s.cfg)
+	if err != nil {
+		return err
+	}
+	daemon.SetDryRun(s.engine.IsDryRun())
+	if err := daemon.Start(); err != nil {
+		return err
+	}
+	s.daemon = daemon
+	return nil
+}
+
+// StopWatch stops the watch daemon, if running
+func (s *Service) StopWatch() {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	if s.daemon != nil {
+		s.daemon.Stop()
+		s.daemon = nil
+	}
+}
+
+// WatchStatus returns the status of the watch daemon; Running is false when
+// none has been started
+func (s *Service) WatchStatus() watch.DaemonStatus {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	if s.daemon == nil {
+		return watch.DaemonStatus{}
+	}
+	return s.daemon.Status()
+}
@@ -0,0 +1,345 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/errors"
+	"sortd/internal/organize"
+	"sortd/internal/pending"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestService returns a service for a directory holding report.pdf, photo.jpg and notes.txt
+func newTestService(t *testing.T) (*Service, string, string) {
+	t.Helper()
+	t.Setenv(config.StateDirEnv, t.TempDir())
+	t.Setenv(config.DataDirEnv, t.TempDir())
+	dir := t.TempDir()
+	photos := filepath.Join(t.TempDir(), "Photos")
+	for _, name := range []string{"report.pdf", "photo.jpg", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+
+	cfg := config.New()
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.DryRun = false
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "Documents"},
+		{Match: "*.jpg", Target: photos},
+	}
+	return New(cfg), dir, photos
+}
+
+func TestPlanOrganize(t *testing.T) {
+	service, dir, photos := newTestService(t)
+
+	plan, err := service.PlanOrganize(context.Background(), dir, PlanOptions{})
+	require.NoError(t, err)
+
+	photo := Move{Source: filepath.Join(dir, "photo.jpg"), Destination: filepath.Join(photos, "photo.jpg"), Pattern: "*.jpg"}
+	photo.ID = types.OperationID(photo.Source, photo.Destination, photo.Pattern)
+	report := Move{Source: filepath.Join(dir, "report.pdf"), Destination: filepath.Join(dir, "Documents", "report.pdf"), Pattern: "*.pdf"}
+	report.ID = types.OperationID(report.Source, report.Destination, report.Pattern)
+	assert.Equal(t, []Move{photo, report}, plan.Moves, "Relative targets resolve against the file's directory")
+	assert.Equal(t, []string{filepath.Join(dir, "notes.txt")}, plan.Unmatched)
+
+	// Planning moves nothing
+	_, err = os.Stat(filepath.Join(dir, "report.pdf"))
+	assert.NoError(t, err)
+
+	t.Run("a single file", func(t *testing.T) {
+		plan, err := service.PlanOrganize(context.Background(), filepath.Join(dir, "photo.jpg"), PlanOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(dir, "photo.jpg")}, plan.Sources())
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		_, err := service.PlanOrganize(context.Background(), filepath.Join(dir, "missing"), PlanOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("depth scoped patterns", func(t *testing.T) {
+		// A top-level cleanup rule leaves organized subfolders alone in a recursive run
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "Taxes", "2023"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "Taxes", "2023", "return.pdf"), nil, 0644))
+		service.Engine().AddPattern(types.Pattern{Match: "*.pdf", Target: "Archive", MinDepth: 3, Priority: 1})
+		service.Engine().AddPattern(types.Pattern{Match: "*.pdf", Target: "Inbox", MaxDepth: 1, Priority: 1})
+
+		plan, err := service.PlanOrganize(context.Background(), dir, PlanOptions{Recursive: true})
+		require.NoError(t, err)
+		destinations := make(map[string]string)
+		for _, move := range plan.Moves {
+			destinations[filepath.Base(move.Source)] = move.Destination
+		}
+		assert.Equal(t, filepath.Join(dir, "Inbox", "report.pdf"), destinations["report.pdf"])
+		assert.Equal(t, filepath.Join(dir, "Taxes", "2023", "Archive", "return.pdf"), destinations["return.pdf"])
+	})
+}
+
+func TestPlanIDs(t *testing.T) {
+	service, dir, _ := newTestService(t)
+	files := []string{
+		filepath.Join(dir, "report.pdf"),
+		filepath.Join(dir, "notes.txt"),
+		filepath.Join(dir, "photo.jpg"),
+	}
+
+	plan, err := service.PlanFiles(context.Background(), files)
+	require.NoError(t, err)
+	reversed, err := service.PlanFiles(context.Background(), []string{files[2], files[1], files[0]})
+	require.NoError(t, err)
+	assert.Equal(t, plan.Moves, reversed.Moves, "The order files arrive in doesn't change the plan")
+	assert.Equal(t, []string{files[2], files[0]}, plan.Sources())
+
+	require.Len(t, plan.Moves, 2)
+	assert.Len(t, plan.Moves[0].ID, 12)
+	assert.NotEqual(t, plan.Moves[0].ID, plan.Moves[1].ID)
+	assert.NotEqual(t, plan.Moves[0].ID, types.OperationID(plan.Moves[0].Source, plan.Moves[0].Destination, "*.png"),
+		"The rule is part of the ID")
+
+	narrowed, missing := plan.OnlyIDs([]string{plan.Moves[1].ID, "nosuchid"})
+	assert.Equal(t, []string{files[0]}, narrowed.Sources())
+	assert.Equal(t, []string{"nosuchid"}, missing)
+
+	results, err := service.Execute(context.Background(), narrowed)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, plan.Moves[1].ID, results[0].ID, "Results carry the ID of their move")
+}
+
+func TestExecute(t *testing.T) {
+	service, dir, photos := newTestService(t)
+
+	plan, err := service.PlanOrganize(context.Background(), dir, PlanOptions{})
+	require.NoError(t, err)
+
+	// Only the picked files are moved
+	plan = plan.Only([]string{filepath.Join(dir, "photo.jpg")})
+	results, err := service.Execute(context.Background(), plan)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Moved)
+	assert.NoError(t, results[0].Error)
+
+	_, err = os.Stat(filepath.Join(photos, "photo.jpg"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "report.pdf"))
+	assert.NoError(t, err, "Files left out of the plan should stay put")
+}
+
+func TestExecute_DryRun(t *testing.T) {
+	service, dir, _ := newTestService(t)
+	service.SetDryRun(true)
+
+	results, err := service.Organize(context.Background(), dir, PlanOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.False(t, result.Moved)
+		assert.NoError(t, result.Error)
+		_, err := os.Stat(result.SourcePath)
+		assert.NoError(t, err, "Dry run should not move %s", result.SourcePath)
+	}
+}
+
+func TestExecute_ReadOnly(t *testing.T) {
+	service, dir, _ := newTestService(t)
+	service.cfg.Directories.Default = t.TempDir()
+
+	require.NoError(t, service.SetReadOnly(true))
+	assert.True(t, service.ReadOnly())
+
+	results, err := service.Organize(context.Background(), dir, PlanOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.False(t, result.Moved)
+		assert.True(t, errors.IsReadOnlyMode(result.Error), "got %v", result.Error)
+		assert.FileExists(t, result.SourcePath)
+	}
+
+	// A second service sharing the config sees the mode until it is switched off
+	other := New(service.cfg)
+	assert.True(t, other.ReadOnly())
+	require.NoError(t, other.SetReadOnly(false))
+	assert.False(t, service.ReadOnly())
+
+	results, err = service.Organize(context.Background(), dir, PlanOptions{})
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.True(t, result.Moved)
+	}
+}
+
+func TestExecute_Cancelled(t *testing.T) {
+	service, dir, _ := newTestService(t)
+
+	plan, err := service.PlanFiles(context.Background(), []string{filepath.Join(dir, "report.pdf")})
+	require.NoError(t, err)
+	require.Len(t, plan.Moves, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := service.Execute(ctx, plan)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, results)
+}
+
+func TestUndo(t *testing.T) {
+	service, dir, photos := newTestService(t)
+	service.cfg.Settings.Collision = "rename"
+	service = New(service.cfg)
+
+	// A photo of the same name is already there, so the moved one is renamed
+	require.NoError(t, os.MkdirAll(photos, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(photos, "photo.jpg"), []byte("older"), 0644))
+
+	results, err := service.Organize(context.Background(), dir, PlanOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		require.True(t, result.Moved)
+		assert.FileExists(t, result.DestinationPath, "Results should say where the file ended up")
+	}
+
+	undone, err := service.Undo(context.Background(), results)
+	require.NoError(t, err)
+	require.Len(t, undone, 2)
+	for _, result := range undone {
+		assert.True(t, result.Moved)
+		assert.NoError(t, result.Error)
+	}
+	for _, name := range []string{"report.pdf", "photo.jpg"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, name, string(data))
+	}
+	data, err := os.ReadFile(filepath.Join(photos, "photo.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "older", string(data), "The file that was there first should stay")
+
+	// Undoing again finds nothing where the run left it, and overwrites nothing
+	undone, err = service.Undo(context.Background(), results)
+	require.NoError(t, err)
+	for _, result := range undone {
+		assert.Error(t, result.Error)
+	}
+	assert.FileExists(t, filepath.Join(dir, "photo.jpg"))
+}
+
+func TestWatchControl(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SORTD_CONFIG_DIR", "")
+
+	service, dir, _ := newTestService(t)
+	service.cfg.WatchDirectories = []string{dir}
+
+	assert.False(t, service.WatchStatus().Running)
+
+	require.NoError(t, service.StartWatch())
+	assert.True(t, service.WatchStatus().Running)
+	assert.Error(t, service.StartWatch(), "Starting twice should fail")
+
+	service.StopWatch()
+	assert.False(t, service.WatchStatus().Running)
+
+	// A stopped watcher can be started again
+	require.NoError(t, service.StartWatch())
+	assert.True(t, service.WatchStatus().Running)
+	service.StopWatch()
+}
+
+func TestUnmatchedPolicy(t *testing.T) {
+	t.Run("move", func(t *testing.T) {
+		service, dir, _ := newTestService(t)
+		service.cfg.Directories.Default = dir
+		service.cfg.Settings.Unmatched.Policy = organize.UnmatchedMove
+
+		plan, err := service.PlanOrganize(context.Background(), dir, PlanOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, plan.Unmatched)
+
+		results, err := service.Execute(context.Background(), plan)
+		require.NoError(t, err)
+		for _, result := range results {
+			require.NoError(t, result.Error)
+		}
+		dest := organize.UnsortedDestination(service.cfg, "notes.txt", time.Now())
+		assert.FileExists(t, dest)
+
+		// Files already in the unsorted folder stay there
+		plan, err = service.PlanOrganize(context.Background(), dest, PlanOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, plan.Moves)
+	})
+
+	t.Run("review", func(t *testing.T) {
+		service, dir, _ := newTestService(t)
+		service.cfg.Directories.Default = dir
+		service.cfg.Settings.Unmatched.Policy = organize.UnmatchedReview
+
+		plan, err := service.PlanOrganize(context.Background(), dir, PlanOptions{})
+		require.NoError(t, err)
+		queued, err := service.ReviewUnmatched(plan)
+		require.NoError(t, err)
+		assert.Equal(t, 1, queued)
+
+		queue, err := pending.Open(pending.DefaultPath(service.cfg))
+		require.NoError(t, err)
+		items, err := queue.List()
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, filepath.Join(dir, "notes.txt"), items[0].Path)
+		assert.Equal(t, pending.ReasonUnmatched, items[0].Reason)
+	})
+}
+
+func TestScreenshots(t *testing.T) {
+	service, dir, photos := newTestService(t)
+	service.cfg.Directories.Default = dir
+	service.cfg.Settings.Screenshots.Enabled = true
+	service.Engine().SetCreateDirs(false) // Month folders are created regardless
+	service.Engine().AddPattern(types.Pattern{Match: "*.png", Target: "Images"})
+	for _, folder := range []string{filepath.Join(dir, "Images"), filepath.Join(dir, "Documents"), photos} {
+		require.NoError(t, os.MkdirAll(folder, 0755))
+	}
+	for _, name := range []string{"Screenshot_20240301-102233_Chrome.png", "Screenshot_20240301-112233_Chrome.png", "holiday.png"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+
+	plan, err := service.PlanOrganize(context.Background(), dir, PlanOptions{})
+	require.NoError(t, err)
+	month := filepath.Join(dir, "Screenshots", "2024-03")
+	var screenshots []string
+	for _, move := range plan.Moves {
+		if move.Screenshot {
+			screenshots = append(screenshots, move.Destination)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		filepath.Join(month, "2024-03-01-chrome.png"),
+		filepath.Join(month, "2024-03-01-chrome-2.png"),
+	}, screenshots, "Screenshots are renamed and come before rules")
+
+	results, err := service.Execute(context.Background(), plan)
+	require.NoError(t, err)
+	for _, result := range results {
+		require.NoError(t, result.Error)
+	}
+	assert.FileExists(t, filepath.Join(month, "2024-03-01-chrome-2.png"))
+	assert.FileExists(t, filepath.Join(dir, "Images", "holiday.png"), "Other images still follow the rules")
+
+	// Filed screenshots stay where they are
+	plan, err = service.PlanOrganize(context.Background(), filepath.Join(month, "2024-03-01-chrome.png"), PlanOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Moves)
+	assert.Empty(t, plan.Unmatched)
+}
@@ -0,0 +1,120 @@
+// Package atomicfile creates files through a temporary file in the same
+// directory and a rename, so a crash or power loss leaves either no file or
+// the complete one at the destination, never a half-written copy.
+package atomicfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TempPrefix starts the name of every file being staged. The leading dot keeps
+// staged files out of directory listings and away from the watcher.
+const TempPrefix = ".sortd-tmp-"
+
+// OrphanAge is how long a staged file must go untouched before CleanOrphans
+// treats it as left behind by a crash rather than a write in progress
+const OrphanAge = 5 * time.Minute
+
+// WriteFile is os.WriteFile, staged
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return Write(path, bytes.NewReader(data), perm)
+}
+
+// Write stages everything read from r next to path, flushes it to disk and
+// renames it into place, replacing any existing file
+func Write(path string, r io.Reader, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, TempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	committed := false
+	defer func() {
+		if !committed {
+			f.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	committed = true
+
+	// Persist the rename itself; not every platform can sync a directory
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// CopyFile copies src to dst with src's permissions
+func CopyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	return Write(dst, in, info.Mode().Perm())
+}
+
+// IsTemp reports whether path names a file being staged
+func IsTemp(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), TempPrefix)
+}
+
+// CleanOrphans removes staged files in dir that haven't been written to for
+// olderThan, returning how many it removed. A missing dir is not an error.
+func CleanOrphans(dir string, olderThan time.Duration) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-olderThan)
+	for _, entry := range entries {
+		if entry.IsDir() || !IsTemp(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove orphaned temp file %s: %w", path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
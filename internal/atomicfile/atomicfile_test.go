@@ -0,0 +1,110 @@
+package atomicfile_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/atomicfile"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReader returns some data and then an error, like a copy cut short
+type failingReader struct{ sent bool }
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.sent {
+		return 0, errors.New("device went away")
+	}
+	r.sent = true
+	return copy(p, "partial"), nil
+}
+
+func entries(t *testing.T, dir string) []string {
+	t.Helper()
+	list, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range list {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestWriteFile_ReplacesWithoutLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.txt")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	require.NoError(t, atomicfile.WriteFile(path, []byte("new"), 0600))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.Equal(t, []string{"report.txt"}, entries(t, dir))
+}
+
+func TestWrite_FailureKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "photo.jpg")
+	require.NoError(t, os.WriteFile(path, []byte("original"), 0644))
+
+	err := atomicfile.Write(path, &failingReader{}, 0644)
+	require.Error(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(data), "A failed write must not touch the destination")
+	assert.Equal(t, []string{"photo.jpg"}, entries(t, dir), "The staged file should be removed")
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "run.sh")
+	require.NoError(t, os.WriteFile(src, []byte("#!/bin/sh\n"), 0755))
+
+	dst := filepath.Join(dir, "copy.sh")
+	require.NoError(t, atomicfile.CopyFile(src, dst))
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n", string(data))
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
+
+func TestCleanOrphans(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, atomicfile.TempPrefix+"123")
+	fresh := filepath.Join(dir, atomicfile.TempPrefix+"456")
+	other := filepath.Join(dir, "keep.txt")
+	for _, path := range []string{stale, fresh, other} {
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+	}
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(stale, old, old))
+	require.NoError(t, os.Chtimes(other, old, old))
+
+	removed, err := atomicfile.CleanOrphans(dir, atomicfile.OrphanAge)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.ElementsMatch(t, []string{filepath.Base(fresh), "keep.txt"}, entries(t, dir),
+		"Only staged files untouched for OrphanAge should go")
+
+	removed, err = atomicfile.CleanOrphans(filepath.Join(dir, "missing"), atomicfile.OrphanAge)
+	assert.NoError(t, err)
+	assert.Zero(t, removed)
+}
+
+func TestIsTemp(t *testing.T) {
+	assert.True(t, atomicfile.IsTemp("/data/"+atomicfile.TempPrefix+"abc"))
+	assert.False(t, atomicfile.IsTemp("/data/report.tmp"))
+}
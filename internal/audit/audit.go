@@ -0,0 +1,152 @@
+// Package audit reports what sortd would do to a directory, such as a
+// shared drive under evaluation, without changing anything: the moves its
+// rules would make, the files no rule matches, and duplicated content.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sortd/internal/app"
+	"sortd/internal/organize"
+)
+
+// Duplicate is a set of files with identical content
+type Duplicate struct {
+	Size  int64    `json:"size"`
+	Paths []string `json:"paths"`
+}
+
+// Report is what organizing a directory would do
+type Report struct {
+	Root       string      `json:"root"`
+	Files      int         `json:"files"`
+	Moves      []app.Move  `json:"moves"`
+	Unmatched  []string    `json:"unmatched"`
+	Duplicates []Duplicate `json:"duplicates"` // Identical files within the directory, largest first
+	Redundant  []app.Move  `json:"redundant"`  // Moves whose destination already holds an identical file
+	Collisions []app.Move  `json:"collisions"` // Moves whose destination holds a different file
+	Truncated  bool        `json:"truncated"`  // A listing limit left files out
+}
+
+// DuplicateBytes returns the space taken by copies beyond the first of each
+// set of duplicates
+func (r *Report) DuplicateBytes() int64 {
+	var wasted int64
+	for _, duplicate := range r.Duplicates {
+		wasted += duplicate.Size * int64(len(duplicate.Paths)-1)
+	}
+	return wasted
+}
+
+// Destinations returns how many files would go to each destination directory
+func (r *Report) Destinations() map[string]int {
+	counts := make(map[string]int)
+	for _, move := range r.Moves {
+		counts[filepath.Dir(move.Destination)]++
+	}
+	return counts
+}
+
+// Run audits a directory. service should be in dry run or audit mode; Run
+// only plans and reads.
+func Run(ctx context.Context, service *app.Service, dir string, recursive bool) (*Report, error) {
+	plan, err := service.PlanOrganize(ctx, dir, app.PlanOptions{Recursive: recursive})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Root:      plan.Root,
+		Files:     len(plan.Listing.Files),
+		Moves:     plan.Moves,
+		Unmatched: plan.Unmatched,
+		Truncated: plan.Listing.Truncated(),
+	}
+
+	for _, move := range plan.Moves {
+		if _, err := os.Stat(move.Destination); err != nil {
+			continue
+		}
+		same, err := organize.SameContent(move.Source, move.Destination)
+		if err != nil {
+			continue
+		}
+		if same {
+			report.Redundant = append(report.Redundant, move)
+		} else {
+			report.Collisions = append(report.Collisions, move)
+		}
+	}
+
+	report.Duplicates, err = FindDuplicates(ctx, plan.Listing.Files)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// FindDuplicates groups files with identical content, largest first. Only
+// files sharing a size are read.
+func FindDuplicates(ctx context.Context, files []string) ([]Duplicate, error) {
+	bySize := make(map[int64][]string)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || !info.Mode().IsRegular() || info.Size() == 0 {
+			continue
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], file)
+	}
+
+	var duplicates []Duplicate
+	for size, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+		byHash := make(map[string][]string)
+		for _, file := range candidates {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			sum, err := hashFile(file)
+			if err != nil {
+				continue
+			}
+			byHash[sum] = append(byHash[sum], file)
+		}
+		for _, paths := range byHash {
+			if len(paths) > 1 {
+				sort.Strings(paths)
+				duplicates = append(duplicates, Duplicate{Size: size, Paths: paths})
+			}
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Size != duplicates[j].Size {
+			return duplicates[i].Size > duplicates[j].Size
+		}
+		return duplicates[i].Paths[0] < duplicates[j].Paths[0]
+	})
+	return duplicates, nil
+}
+
+// hashFile returns the SHA-256 of a file's content
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
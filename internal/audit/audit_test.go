@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/app"
+	"sortd/internal/config"
+	"sortd/internal/errors"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	docs := filepath.Join(dir, "docs")
+	drive := filepath.Join(dir, "drive")
+	require.NoError(t, os.MkdirAll(docs, 0755))
+	require.NoError(t, os.MkdirAll(drive, 0755))
+
+	write := func(path, content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write(filepath.Join(drive, "a.pdf"), "same")
+	write(filepath.Join(drive, "copy of a.pdf"), "same")
+	write(filepath.Join(drive, "b.pdf"), "bbb")
+	write(filepath.Join(drive, "c.pdf"), "ccc")
+	write(filepath.Join(drive, "notes.txt"), "n")
+	write(filepath.Join(docs, "b.pdf"), "bbb")
+	write(filepath.Join(docs, "c.pdf"), "other")
+
+	cfg := config.New()
+	cfg.Directories.Default = dir
+	cfg.Settings.DryRun = false
+	cfg.Settings.Audit = true
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: docs}}
+	service := app.New(cfg)
+
+	report, err := Run(context.Background(), service, drive, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, report.Files)
+	assert.Len(t, report.Moves, 4)
+	assert.Equal(t, map[string]int{docs: 4}, report.Destinations())
+	assert.Equal(t, []string{filepath.Join(drive, "notes.txt")}, report.Unmatched)
+	assert.Equal(t, []Duplicate{{Size: 4, Paths: []string{filepath.Join(drive, "a.pdf"), filepath.Join(drive, "copy of a.pdf")}}},
+		report.Duplicates)
+	assert.Equal(t, int64(4), report.DuplicateBytes())
+	require.Len(t, report.Redundant, 1)
+	assert.Equal(t, filepath.Join(drive, "b.pdf"), report.Redundant[0].Source)
+	require.Len(t, report.Collisions, 1)
+	assert.Equal(t, filepath.Join(drive, "c.pdf"), report.Collisions[0].Source)
+
+	// Audit mode can't be lifted to carry out the moves
+	service.Engine().SetReadOnly(false)
+	err = service.Engine().MoveFile(filepath.Join(drive, "a.pdf"), filepath.Join(docs, "a.pdf"))
+	assert.True(t, errors.IsReadOnlyMode(err))
+	assert.FileExists(t, filepath.Join(drive, "a.pdf"))
+}
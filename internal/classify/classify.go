@@ -0,0 +1,277 @@
+// Package classify scores files against FileClassification criteria: weighted
+// keywords, MIME type prefixes, file name patterns and size ranges.
+package classify
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sortd/internal/units"
+	"sortd/pkg/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultThreshold applies to classifications without a confidence threshold
+const defaultThreshold = 0.5
+
+// maxTextBytes bounds how much of a text file is searched for keywords
+const maxTextBytes = 64 * 1024
+
+// Criterion kinds, as reported in contributions
+const (
+	KeywordCriterion  = "keyword"
+	MimeCriterion     = "mime_prefix"
+	FilenameCriterion = "filename_pattern"
+	SizeCriterion     = "size_range"
+)
+
+// Contribution is how one criterion scored for a file
+type Contribution struct {
+	Criterion string  `json:"criterion"`
+	Value     string  `json:"value"`
+	Weight    float64 `json:"weight"`
+	Matched   bool    `json:"matched"`
+}
+
+// Result is how a file scored against one classification
+type Result struct {
+	Classification string         `json:"classification"`
+	Target         string         `json:"target,omitempty"`
+	Score          float64        `json:"score"` // Matched weight over total positive weight, 0-1
+	Threshold      float64        `json:"threshold"`
+	Matched        bool           `json:"matched"`
+	Contributions  []Contribution `json:"contributions"`
+}
+
+// compiled is a classification with its patterns and sizes parsed
+type compiled struct {
+	types.FileClassification
+	keywords  []*regexp.Regexp
+	filenames []*regexp.Regexp
+	sizes     [][2]int64 // min, max; -1 is open
+}
+
+// Evaluator scores files against a set of classifications
+type Evaluator struct {
+	classifications []compiled
+	fingerprint     string
+}
+
+// New compiles the classifications, failing on invalid patterns, sizes or thresholds
+func New(classifications []types.FileClassification) (*Evaluator, error) {
+	e := &Evaluator{fingerprint: Fingerprint(classifications)}
+	for _, c := range classifications {
+		if strings.TrimSpace(c.Name) == "" {
+			return nil, fmt.Errorf("classification name is required")
+		}
+		if c.ConfidenceThreshold < 0 || c.ConfidenceThreshold > 1 {
+			return nil, fmt.Errorf("classification %s: confidence_threshold must be between 0 and 1", c.Name)
+		}
+
+		cc := compiled{FileClassification: c}
+		for _, keyword := range c.Criteria.Keywords {
+			if strings.TrimSpace(keyword.Value) == "" {
+				return nil, fmt.Errorf("classification %s: empty keyword", c.Name)
+			}
+			cc.keywords = append(cc.keywords, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(keyword.Value)+`\b`))
+		}
+		for _, pattern := range c.Criteria.FilenamePatterns {
+			re, err := regexp.Compile(pattern.Value)
+			if err != nil {
+				return nil, fmt.Errorf("classification %s: invalid filename pattern %q: %w", c.Name, pattern.Value, err)
+			}
+			cc.filenames = append(cc.filenames, re)
+		}
+		for _, r := range c.Criteria.SizeRanges {
+			lo, err := parseBound(r.Min)
+			if err != nil {
+				return nil, fmt.Errorf("classification %s: invalid size %q: %w", c.Name, r.Min, err)
+			}
+			hi, err := parseBound(r.Max)
+			if err != nil {
+				return nil, fmt.Errorf("classification %s: invalid size %q: %w", c.Name, r.Max, err)
+			}
+			cc.sizes = append(cc.sizes, [2]int64{lo, hi})
+		}
+		e.classifications = append(e.classifications, cc)
+	}
+	return e, nil
+}
+
+// Fingerprint identifies the classifications the evaluator was created with
+func (e *Evaluator) Fingerprint() string {
+	return e.fingerprint
+}
+
+// Validate checks classifications without keeping the compiled result
+func Validate(classifications []types.FileClassification) error {
+	_, err := New(classifications)
+	return err
+}
+
+// LoadFile reads classifications from a YAML or JSON file, either as a list
+// or under a top-level "classifications" key
+func LoadFile(path string) ([]types.FileClassification, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classification file: %w", err)
+	}
+
+	var list []types.FileClassification
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		var doc struct {
+			Classifications []types.FileClassification `yaml:"classifications"`
+		}
+		if docErr := yaml.Unmarshal(data, &doc); docErr != nil {
+			return nil, fmt.Errorf("failed to parse classification file: %w", docErr)
+		}
+		list = doc.Classifications
+	}
+
+	if err := Validate(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// fileFacts is what criteria are tested against
+type fileFacts struct {
+	name string // Base name with punctuation turned into spaces, for keyword matching
+	base string
+	mime string
+	size int64
+	text string
+}
+
+// Evaluate scores the file against every classification, best first
+func (e *Evaluator) Evaluate(path string) ([]Result, error) {
+	facts, err := readFacts(path)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(e.classifications))
+	for _, c := range e.classifications {
+		results = append(results, c.evaluate(facts))
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results, nil
+}
+
+// Classify returns the best-scoring classification the file meets
+func (e *Evaluator) Classify(path string) (Result, bool, error) {
+	results, err := e.Evaluate(path)
+	if err != nil {
+		return Result{}, false, err
+	}
+	for _, result := range results {
+		if result.Matched {
+			return result, true, nil
+		}
+	}
+	return Result{}, false, nil
+}
+
+// evaluate scores the facts against the classification's criteria
+func (c *compiled) evaluate(facts fileFacts) Result {
+	result := Result{Classification: c.Name, Target: c.Target, Threshold: c.ConfidenceThreshold}
+	if result.Threshold == 0 {
+		result.Threshold = defaultThreshold
+	}
+
+	var total, matched float64
+	add := func(criterion, value string, weight float64, ok bool) {
+		result.Contributions = append(result.Contributions, Contribution{Criterion: criterion, Value: value, Weight: weight, Matched: ok})
+		if weight > 0 {
+			total += weight
+		}
+		if ok {
+			matched += weight
+		}
+	}
+
+	for i, keyword := range c.Criteria.Keywords {
+		re := c.keywords[i]
+		add(KeywordCriterion, keyword.Value, keyword.Weight, re.MatchString(facts.name) || re.MatchString(facts.text))
+	}
+	for _, prefix := range c.Criteria.MimePrefixes {
+		add(MimeCriterion, prefix.Value, prefix.Weight, strings.HasPrefix(facts.mime, prefix.Value))
+	}
+	for i, pattern := range c.Criteria.FilenamePatterns {
+		add(FilenameCriterion, pattern.Value, pattern.Weight, c.filenames[i].MatchString(facts.base))
+	}
+	for i, r := range c.Criteria.SizeRanges {
+		bounds := c.sizes[i]
+		ok := (bounds[0] < 0 || facts.size >= bounds[0]) && (bounds[1] < 0 || facts.size <= bounds[1])
+		add(SizeCriterion, r.Min+".."+r.Max, r.Weight, ok)
+	}
+
+	if total > 0 {
+		result.Score = min(max(matched/total, 0), 1)
+	}
+	result.Matched = total > 0 && result.Score >= result.Threshold
+	return result
+}
+
+// readFacts gathers a file's name, type, size and, for text files, contents
+func readFacts(path string) (fileFacts, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileFacts{}, err
+	}
+	if info.IsDir() {
+		return fileFacts{}, fmt.Errorf("%s is a directory", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fileFacts{}, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, maxTextBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return fileFacts{}, err
+	}
+	buf = buf[:n]
+
+	base := filepath.Base(path)
+	facts := fileFacts{
+		name: strings.Map(func(r rune) rune {
+			if r == '_' || r == '-' || r == '.' {
+				return ' '
+			}
+			return r
+		}, base),
+		base: base,
+		mime: http.DetectContentType(buf),
+		size: info.Size(),
+	}
+
+	// Sniffing can't tell many formats apart; the extension is more specific
+	if byExt := mime.TypeByExtension(filepath.Ext(path)); byExt != "" &&
+		(facts.mime == "application/octet-stream" || strings.HasPrefix(facts.mime, "text/plain")) {
+		facts.mime = byExt
+	}
+	if strings.HasPrefix(facts.mime, "text/") {
+		facts.text = string(buf)
+	}
+	return facts, nil
+}
+
+// parseBound parses a size range bound; empty is open (-1)
+func parseBound(s string) (int64, error) {
+	if strings.TrimSpace(s) == "" {
+		return -1, nil
+	}
+	return units.ParseSize(s)
+}
@@ -0,0 +1,92 @@
+package classify_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/classify"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// invoice is a classification used across the tests
+var invoice = types.FileClassification{
+	Name:                "invoice",
+	ConfidenceThreshold: 0.6,
+	Criteria: types.ClassificationCriteria{
+		Keywords:         []types.WeightedCriterion{{Value: "invoice", Weight: 3}, {Value: "amount due", Weight: 2}, {Value: "draft", Weight: -2}},
+		MimePrefixes:     []types.WeightedCriterion{{Value: "text/", Weight: 1}},
+		FilenamePatterns: []types.WeightedCriterion{{Value: `^INV-\d+`, Weight: 2}},
+		SizeRanges:       []types.SizeRange{{Max: "1MB", Weight: 1}},
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	dir := t.TempDir()
+	evaluator, err := classify.New([]types.FileClassification{invoice})
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "INV-1001_invoice.txt")
+	require.NoError(t, os.WriteFile(path, []byte("Amount due: $120"), 0644))
+
+	results, err := evaluator.Evaluate(path)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Matched)
+	assert.InDelta(t, 1.0, results[0].Score, 0.001, "Every positive criterion matched")
+	require.Len(t, results[0].Contributions, 6)
+	assert.Equal(t, classify.Contribution{Criterion: classify.KeywordCriterion, Value: "draft", Weight: -2}, results[0].Contributions[2])
+
+	draft := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(draft, []byte("DRAFT invoice"), 0644))
+	result, ok, err := evaluator.Classify(draft)
+	require.NoError(t, err)
+	assert.False(t, ok, "A draft with few criteria met should not be classified, got %+v", result)
+}
+
+func TestNewRejectsInvalidCriteria(t *testing.T) {
+	bad := invoice
+	bad.Criteria.FilenamePatterns = []types.WeightedCriterion{{Value: "([", Weight: 1}}
+	_, err := classify.New([]types.FileClassification{bad})
+	assert.Error(t, err)
+
+	bad = invoice
+	bad.Criteria.SizeRanges = []types.SizeRange{{Min: "lots", Weight: 1}}
+	_, err = classify.New([]types.FileClassification{bad})
+	assert.Error(t, err)
+
+	bad = invoice
+	bad.ConfidenceThreshold = 1.5
+	_, err = classify.New([]types.FileClassification{bad})
+	assert.Error(t, err)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "criteria.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`
+classifications:
+  - name: receipt
+    confidence_threshold: 0.5
+    criteria:
+      keywords:
+        - {value: receipt, weight: 2}
+      size_ranges:
+        - {min: 1KB, max: 5MB, weight: 1}
+`), 0644))
+	loaded, err := classify.LoadFile(yamlPath)
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "5MB", loaded[0].Criteria.SizeRanges[0].Max)
+
+	jsonPath := filepath.Join(dir, "criteria.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`[{"name": "photo", "criteria": {"mime_prefixes": [{"value": "image/", "weight": 1}]}}]`), 0644))
+	loaded, err = classify.LoadFile(jsonPath)
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "photo", loaded[0].Name)
+}
@@ -0,0 +1,81 @@
+package classify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Change is a file whose classification changed during a reindex
+type Change struct {
+	Path string `json:"path"`
+	From string `json:"from"` // Empty when it had none
+	To   string `json:"to"`   // Empty when it has none now
+}
+
+// ReindexResult summarises a reindex
+type ReindexResult struct {
+	Evaluated int      `json:"evaluated"`
+	Added     int      `json:"added"` // Files classified for the first time
+	Unchanged int      `json:"unchanged"`
+	Removed   int      `json:"removed"` // Stored files that no longer exist
+	Changed   []Change `json:"changed"`
+}
+
+// Reindex re-evaluates the files against the evaluator's classifications,
+// updates their stored matches and reports the ones whose classification
+// changed. Stored files that are gone are forgotten. Once every file is done
+// the store is marked as current for these classifications.
+func Reindex(ctx context.Context, e *Evaluator, store *Store, paths []string) (ReindexResult, error) {
+	var result ReindexResult
+	now := time.Now()
+
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+
+		previous, known, err := store.Get(path)
+		if err != nil {
+			return result, err
+		}
+
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			if known {
+				if err := store.Remove(path); err != nil {
+					return result, err
+				}
+				result.Removed++
+			}
+			continue
+		}
+
+		match := Match{Path: path, Updated: now}
+		if best, ok, err := e.Classify(path); err != nil {
+			continue
+		} else if ok {
+			match.Classification = best.Classification
+			match.Score = best.Score
+		}
+		if err := store.Put(match); err != nil {
+			return result, err
+		}
+
+		result.Evaluated++
+		switch {
+		case !known:
+			result.Added++
+		case previous.Classification != match.Classification:
+			result.Changed = append(result.Changed, Change{Path: path, From: previous.Classification, To: match.Classification})
+		default:
+			result.Unchanged++
+		}
+	}
+
+	return result, store.SetFingerprint(e.Fingerprint())
+}
@@ -0,0 +1,66 @@
+package classify_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/classify"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReindex(t *testing.T) {
+	dir := t.TempDir()
+	store, err := classify.OpenStore(filepath.Join(dir, classify.StoreFile))
+	require.NoError(t, err)
+	defer store.Close()
+
+	receipt := filepath.Join(dir, "receipt.txt")
+	report := filepath.Join(dir, "report.txt")
+	gone := filepath.Join(dir, "gone.txt")
+	for _, path := range []string{receipt, report, gone} {
+		require.NoError(t, os.WriteFile(path, []byte("Total paid"), 0644))
+	}
+
+	byKeyword := func(name, keyword string) types.FileClassification {
+		return types.FileClassification{Name: name, Criteria: types.ClassificationCriteria{
+			Keywords: []types.WeightedCriterion{{Value: keyword, Weight: 1}},
+		}}
+	}
+
+	before, err := classify.New([]types.FileClassification{byKeyword("receipt", "receipt")})
+	require.NoError(t, err)
+	result, err := classify.Reindex(context.Background(), before, store, []string{receipt, report, gone})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Added)
+
+	fingerprint, err := store.Fingerprint()
+	require.NoError(t, err)
+	assert.Equal(t, before.Fingerprint(), fingerprint)
+
+	// Rules change: receipts are now "expense", and anything saying "paid" is too
+	after, err := classify.New([]types.FileClassification{byKeyword("expense", "paid")})
+	require.NoError(t, err)
+	assert.NotEqual(t, before.Fingerprint(), after.Fingerprint())
+
+	require.NoError(t, os.Remove(gone))
+	paths, err := store.Paths()
+	require.NoError(t, err)
+	result, err = classify.Reindex(context.Background(), after, store, paths)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Removed)
+	assert.ElementsMatch(t, []classify.Change{
+		{Path: receipt, From: "receipt", To: "expense"},
+		{Path: report, From: "", To: "expense"},
+	}, result.Changed)
+
+	match, ok, err := store.Get(report)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "expense", match.Classification)
+}
@@ -0,0 +1,147 @@
+package classify
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sortd/pkg/types"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver
+)
+
+// StoreFile is the database, in the data directory, classification matches
+// are kept in. The config package depends on this one, so callers resolve it
+// with config's DataFile.
+const StoreFile = "classifications.db"
+
+// storeSchema creates the match table and a key/value table for the
+// fingerprint of the classifications the matches were computed with
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS classification_matches (
+	path           TEXT PRIMARY KEY,
+	classification TEXT NOT NULL,
+	score          REAL NOT NULL,
+	updated        INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS classification_meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+)`
+
+// fingerprintKey is the meta key holding the classifications' fingerprint
+const fingerprintKey = "fingerprint"
+
+// Match is the stored classification of a file. Classification is empty when
+// the file met none.
+type Match struct {
+	Path           string    `json:"path"`
+	Classification string    `json:"classification"`
+	Score          float64   `json:"score"`
+	Updated        time.Time `json:"updated"`
+}
+
+// Store keeps the classification of known files in SQLite
+type Store struct {
+	db *sql.DB
+}
+
+// OpenStore opens or creates the store at path
+func OpenStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open classification store: %w", err)
+	}
+	db.SetMaxOpenConns(1) // The daemon's workers write concurrently; serialise them
+	if _, err := db.Exec(storeSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise classification store: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Put stores the classification of a file
+func (s *Store) Put(match Match) error {
+	_, err := s.db.Exec(
+		`INSERT OR REPLACE INTO classification_matches (path, classification, score, updated) VALUES (?, ?, ?, ?)`,
+		match.Path, match.Classification, match.Score, match.Updated.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to store classification of %s: %w", match.Path, err)
+	}
+	return nil
+}
+
+// Get returns the stored classification of a file
+func (s *Store) Get(path string) (Match, bool, error) {
+	match := Match{Path: path}
+	var updated int64
+	err := s.db.QueryRow(`SELECT classification, score, updated FROM classification_matches WHERE path = ?`, path).
+		Scan(&match.Classification, &match.Score, &updated)
+	if err == sql.ErrNoRows {
+		return Match{}, false, nil
+	}
+	if err != nil {
+		return Match{}, false, fmt.Errorf("failed to read classification of %s: %w", path, err)
+	}
+	match.Updated = time.Unix(0, updated)
+	return match, true, nil
+}
+
+// Remove forgets a file
+func (s *Store) Remove(path string) error {
+	if _, err := s.db.Exec(`DELETE FROM classification_matches WHERE path = ?`, path); err != nil {
+		return fmt.Errorf("failed to remove classification of %s: %w", path, err)
+	}
+	return nil
+}
+
+// Paths returns every file with a stored classification
+func (s *Store) Paths() ([]string, error) {
+	rows, err := s.db.Query(`SELECT path FROM classification_matches ORDER BY path`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list classifications: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// Fingerprint returns the fingerprint of the classifications the stored
+// matches were last computed with
+func (s *Store) Fingerprint() (string, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM classification_meta WHERE key = ?`, fingerprintKey).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetFingerprint records the fingerprint of the classifications in use
+func (s *Store) SetFingerprint(fingerprint string) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO classification_meta (key, value) VALUES (?, ?)`, fingerprintKey, fingerprint)
+	return err
+}
+
+// Fingerprint identifies a set of classifications, so edits can be detected
+func Fingerprint(classifications []types.FileClassification) string {
+	data, _ := json.Marshal(classifications)
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,5 +1,7 @@
 package common
 
+import "sortd/pkg/types"
+
 // Mode represents the current mode of the TUI
 type Mode int
 
@@ -16,19 +18,10 @@ const (
 
 // ModelReader defines the interface that views use to read model state
 type ModelReader interface {
-	Files() []FileEntry
+	Files() []*types.FileInfo
 	IsSelected(name string) bool
 	Cursor() int
 	ShowHelp() bool
 	Mode() Mode
 	CurrentDir() string
 }
-
-// FileEntry represents a file in the current directory
-type FileEntry struct {
-	Name        string
-	Path        string
-	ContentType string
-	Size        int64
-	Tags        []string
-}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SetBookmark saves a directory under a short name, replacing any bookmark
+// with the same name. Relative paths are made absolute.
+func (c *Config) SetBookmark(name, path string) error {
+	if err := validateBookmarkName(name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(path) == "" {
+		return fmt.Errorf("bookmark %q: path cannot be empty", name)
+	}
+	if !strings.HasPrefix(path, "~") {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		path = abs
+	}
+
+	if c.Bookmarks == nil {
+		c.Bookmarks = make(map[string]string)
+	}
+	c.Bookmarks[name] = path
+	return nil
+}
+
+// RemoveBookmark deletes a bookmark, reporting whether it existed
+func (c *Config) RemoveBookmark(name string) bool {
+	if _, ok := c.Bookmarks[name]; !ok {
+		return false
+	}
+	delete(c.Bookmarks, name)
+	return true
+}
+
+// BookmarkNames returns the bookmark names in alphabetical order
+func (c *Config) BookmarkNames() []string {
+	names := make([]string, 0, len(c.Bookmarks))
+	for name := range c.Bookmarks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveBookmark returns the directory a bookmark points at, with a leading
+// ~ expanded to the home directory
+func (c *Config) ResolveBookmark(name string) (string, error) {
+	path, ok := c.Bookmarks[name]
+	if !ok {
+		return "", fmt.Errorf("no bookmark named %q", name)
+	}
+	return ExpandHome(path), nil
+}
+
+// validateBookmarkName rejects names that are awkward to type in a shell
+func validateBookmarkName(name string) error {
+	if name == "" {
+		return fmt.Errorf("bookmark name cannot be empty")
+	}
+	if strings.ContainsAny(name, " \t/\\") {
+		return fmt.Errorf("bookmark name %q cannot contain spaces or slashes", name)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookmarks(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	cfg := config.New()
+	require.NoError(t, cfg.SetBookmark("work", dir))
+	require.NoError(t, cfg.SetBookmark("docs", "~/Documents"))
+	assert.Error(t, cfg.SetBookmark("my docs", dir))
+	assert.Error(t, cfg.SetBookmark("empty", ""))
+
+	assert.Equal(t, []string{"docs", "work"}, cfg.BookmarkNames())
+
+	path, err := cfg.ResolveBookmark("docs")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "Documents"), path)
+
+	path, err = cfg.ResolveBookmark("work")
+	require.NoError(t, err)
+	assert.Equal(t, dir, path)
+
+	assert.True(t, cfg.RemoveBookmark("work"))
+	assert.False(t, cfg.RemoveBookmark("work"))
+	_, err = cfg.ResolveBookmark("work")
+	assert.Error(t, err)
+}
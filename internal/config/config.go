@@ -2,11 +2,17 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"sortd/internal/atomicfile"
+	"sortd/internal/classify"
+	"sortd/internal/globs"
+	"sortd/internal/units"
 	"sortd/pkg/types"
 
 	"gopkg.in/yaml.v3"
@@ -20,34 +26,395 @@ type Config struct {
 	} `yaml:"organize"`
 	Settings    Settings `yaml:"settings"`
 	Directories struct {
-		Default string   `yaml:"default"` // Default working directory
-		Watch   []string `yaml:"watch"`   // Directories to watch
+		Default string     `yaml:"default"` // Default working directory
+		Watch   []WatchDir `yaml:"watch"`   // Directories to watch, each a path or a mapping with its own settings
 	} `yaml:"directories"`
-	Rules []struct {
-		Pattern string `yaml:"pattern"` // Pattern to match
-		Target  string `yaml:"target"`  // Target directory
-	} `yaml:"rules"`
+	Rules     []Rule `yaml:"rules"`
 	WatchMode struct {
-		Enabled bool `yaml:"enabled"` // Enable watch mode using fsnotify for event detection.
-		// Note: User notification logic (e.g., debouncing, specific triggers)
-		// is handled separately by the watch daemon/GUI, not via a config interval.
+		Enabled       bool `yaml:"enabled"` // Enable watch mode using fsnotify for event detection.
+		WatchSettings `yaml:",inline"`
+
+		Filters   []WatchFilter     `yaml:"filters,omitempty"`   // Include/exclude globs applied before rules and workflows
+		Stability []StabilityWindow `yaml:"stability,omitempty"` // Per-pattern settings for waiting until a file is fully written
+		Webhook   WebhookServer     `yaml:"webhook,omitempty"`   // Endpoint external systems call to trigger workflows
+
+		WaitForClose bool `yaml:"wait_for_close,omitempty"` // Wait until no other process has a file open, with or without a stability window
+
+		PauseOnBattery bool `yaml:"pause_on_battery,omitempty"` // Leave files alone while on battery or in battery saver, until back on AC
+		PauseOnMetered bool `yaml:"pause_on_metered,omitempty"` // Hold back moves to network file systems while the connection is metered
+
+		Backend      string `yaml:"backend,omitempty"`       // "fsnotify" (default) or "poll" for mounts without change events
+		PollSeconds  int    `yaml:"poll_seconds,omitempty"`  // How often the poll backend rescans (default 5)
+		HealthListen string `yaml:"health_listen,omitempty"` // Address serving GET /healthz, e.g. ":8080" (empty disables)
+		WebListen    string `yaml:"web_listen,omitempty"`    // Address serving the read-only web UI, e.g. ":8484" (empty disables)
+
+		HTTP HTTPSettings `yaml:"http,omitempty"` // Binding, token and TLS for the webhook, health and web UI endpoints
+
+		SuperviseSeconds int `yaml:"supervise_seconds,omitempty"`  // How often watchers are checked and failed ones retried (default 5)
+		DropAfterSeconds int `yaml:"drop_after_seconds,omitempty"` // How long a missing directory is retried before it is dropped (default 3600)
+
+		LandingZones []LandingZone `yaml:"landing_zones,omitempty"` // Directories every file must leave within a deadline
+
+		AllowFeedbackLoops bool `yaml:"allow_feedback_loops,omitempty"` // Start even though rules send files back into watched directories
 	} `yaml:"watch_mode"`
 	WatchDirectories []string         `yaml:"watch_directories"` // List of directories to monitor
 	Workflows        []types.Workflow `yaml:"workflows"`         // User-defined workflows
+
+	Classifications []types.FileClassification `yaml:"classifications,omitempty"` // Weighted criteria describing kinds of files
+	Profiles        []Profile                  `yaml:"profiles,omitempty"`        // Contexts like docked or laptop that patterns, watch directories and workflows can belong to
+	Bookmarks       map[string]string          `yaml:"bookmarks,omitempty"`       // Named directories for quick jumps
+
+	globMigrations []GlobMigration // Globs rewritten when the file was loaded
 }
 
 // Settings contains global configuration settings
 type Settings struct {
 	DryRun              bool   `yaml:"dry_run"`              // Run in dry run mode
+	ReadOnly            bool   `yaml:"read_only,omitempty"`  // Change nothing, as if paused with 'sortd daemon pause'
+	Audit               bool   `yaml:"audit,omitempty"`      // Only observe and report; unlike read_only, nothing at runtime can allow changes
 	CreateDirs          bool   `yaml:"create_dirs"`          // Create target directories if they don't exist
 	Confirm             bool   `yaml:"confirm"`              // Require confirmation before organizing files
-	MaxDepth            int    `yaml:"max_depth"`            // Maximum depth to search for files
+	MaxDepth            int    `yaml:"max_depth"`            // Maximum depth to search for files (0 = default of 10, negative = no limit)
+	MaxFiles            int    `yaml:"max_files,omitempty"`  // Maximum files a scan or organize run takes in (0 = default of 10000, negative = no limit)
 	FollowSymlinks      bool   `yaml:"follow_symlinks"`      // Follow symbolic links
 	IgnoreHidden        bool   `yaml:"ignore_hidden"`        // Ignore hidden files and directories
 	LogLevel            string `yaml:"log_level"`            // Log level (debug, info, warn, error)
 	Backup              bool   `yaml:"backup"`               // Create backups before moving
+	Copy                bool   `yaml:"copy,omitempty"`       // Copy files instead of moving them, leaving originals untouched
 	Collision           string `yaml:"collision"`            // Collision strategy: rename, skip, or ask
 	EnableNotifications bool   `yaml:"enable_notifications"` // Enable system notifications
+
+	Duplicates DuplicateSettings `yaml:"duplicates,omitempty"` // Content comparison and naming when a destination is taken
+	Unmatched  UnmatchedSettings `yaml:"unmatched,omitempty"`  // What happens to files no rule or workflow matches
+	Retry      RetrySettings     `yaml:"retry,omitempty"`      // Retries of moves and workflow actions that fail on transient errors
+	Priority   PrioritySettings  `yaml:"priority,omitempty"`   // CPU and disk priority of background work, and copy speed to network file systems
+
+	Quotas []Quota `yaml:"quotas,omitempty"` // Limits on how much destination directories may hold
+
+	// Archive directories whose folders keep a SHA256SUMS of the files moved
+	// or copied into them, checked with 'sortd verify'
+	Manifests []string `yaml:"manifests,omitempty"`
+
+	Digest   DigestSettings   `yaml:"digest,omitempty"`     // Periodic activity summaries
+	Inbox    InboxSettings    `yaml:"inbox_zero,omitempty"` // Nudges about files left in inbox directories
+	Training TrainingSettings `yaml:"training,omitempty"`   // Stage automatic moves for review while trust is built
+
+	// Automatic moves by patterns whose learned confidence is below this level
+	// (0-1) are staged in the pending queue instead. 0 disables the check.
+	AutoApplyConfidence float64 `yaml:"auto_apply_confidence,omitempty"`
+
+	Embeddings   EmbeddingSettings    `yaml:"embeddings,omitempty"`    // Optional semantic similarity search
+	ImageTagging ImageTaggingSettings `yaml:"image_tagging,omitempty"` // Optional on-device image tags
+	Video        VideoSettings        `yaml:"video,omitempty"`         // Video metadata via ffprobe
+	Documents    DocumentSettings     `yaml:"documents,omitempty"`     // Document date extraction
+	Screenshots  ScreenshotSettings   `yaml:"screenshots,omitempty"`   // Renaming screenshots and filing them by month
+
+	Journal JournalSettings `yaml:"journal,omitempty"` // Retention of the activity log
+}
+
+// DuplicateSettings refine collisions: an incoming file that is byte-for-byte
+// the one already at its destination can be dropped instead of kept twice, and
+// copies kept side by side get versioned names.
+type DuplicateSettings struct {
+	CompareContent  bool   `yaml:"compare_content,omitempty"`  // Hash both files; identical ones are skipped whatever the collision strategy
+	DeleteIdentical bool   `yaml:"delete_identical,omitempty"` // Also delete the skipped source (needs compare_content)
+	Versioning      string `yaml:"versioning,omitempty"`       // Names of renamed copies: "" for name_(1).ext, "counter" for name (2).ext, "date" for name_2024-05-01.ext
+}
+
+// UnmatchedSettings decide what becomes of files that no pattern or workflow
+// matches, so they don't pile up unnoticed in watched directories
+type UnmatchedSettings struct {
+	Policy string `yaml:"policy,omitempty"` // "leave" (the default), "move" to a dated folder under folder, or "review" in the pending queue
+	Folder string `yaml:"folder,omitempty"` // Where "move" and approved reviews put files; relative to the default directory (default "Unsorted")
+}
+
+// RetrySettings control how the watch daemon retries moves and workflow
+// actions that fail on errors likely to clear up by themselves, such as a file
+// busy in another program or a network share that is briefly unreachable
+type RetrySettings struct {
+	Attempts     int `yaml:"attempts,omitempty"`      // Retries after the first try (0 = default of 3, negative = none)
+	DelaySeconds int `yaml:"delay_seconds,omitempty"` // Pause before the first retry, doubling for each one after (default 1)
+}
+
+// PrioritySettings keep background organization from making the desktop
+// stutter: the watch daemon's workers run at a lower CPU and disk priority,
+// and copies to network file systems are held to a bandwidth
+type PrioritySettings struct {
+	Nice            int    `yaml:"nice,omitempty"`             // CPU niceness of the workers, 0 (normal) to 19 (lowest)
+	IOClass         string `yaml:"io_class,omitempty"`         // Linux disk scheduling class: "best-effort", or "idle" to use the disk only when nothing else does
+	IOLevel         int    `yaml:"io_level,omitempty"`         // Priority within best-effort, 0 (highest) to 7 (lowest)
+	RemoteBandwidth string `yaml:"remote_bandwidth,omitempty"` // Most each copy to a network file system sends per second, e.g. "10MB"
+}
+
+// Disk scheduling classes for settings.priority.io_class
+const (
+	IOClassBestEffort = "best-effort"
+	IOClassIdle       = "idle"
+)
+
+// BandwidthLimit returns the bytes per second copies to network file systems
+// are held to, 0 for no limit
+func (p PrioritySettings) BandwidthLimit() int64 {
+	if p.RemoteBandwidth == "" {
+		return 0
+	}
+	limit, _ := units.ParseSize(p.RemoteBandwidth)
+	return limit
+}
+
+// Validate checks the priorities are in range and the bandwidth is a size
+func (p PrioritySettings) Validate() error {
+	if p.Nice < 0 || p.Nice > 19 {
+		return fmt.Errorf("priority nice must be between 0 and 19")
+	}
+	switch p.IOClass {
+	case "", IOClassBestEffort, IOClassIdle:
+	default:
+		return fmt.Errorf("invalid priority io_class %q: must be best-effort or idle", p.IOClass)
+	}
+	if p.IOLevel < 0 || p.IOLevel > 7 {
+		return fmt.Errorf("priority io_level must be between 0 and 7")
+	}
+	if p.RemoteBandwidth != "" {
+		if limit, err := units.ParseSize(p.RemoteBandwidth); err != nil || limit <= 0 {
+			return fmt.Errorf("invalid priority remote_bandwidth %q: must be a size such as 10MB", p.RemoteBandwidth)
+		}
+	}
+	return nil
+}
+
+// Retry defaults, and the longest pause between two attempts
+const (
+	DefaultRetryAttempts = 3
+	maxRetryDelay        = 5 * time.Minute
+)
+
+// MaxRetries returns how many times a failed operation is retried
+func (r RetrySettings) MaxRetries() int {
+	switch {
+	case r.Attempts < 0:
+		return 0
+	case r.Attempts == 0:
+		return DefaultRetryAttempts
+	}
+	return r.Attempts
+}
+
+// Delay returns the pause before the given retry, counting from 1
+func (r RetrySettings) Delay(retry int) time.Duration {
+	delay := time.Second
+	if r.DelaySeconds > 0 {
+		delay = time.Duration(r.DelaySeconds) * time.Second
+	}
+	for i := 1; i < retry && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// Quota limits what a destination directory, with its subdirectories, may
+// hold, e.g. an archive on a small drive. Moves and copies into it by rules and
+// workflows are checked against it.
+type Quota struct {
+	Directory string `yaml:"directory"`
+	MaxSize   string `yaml:"max_size,omitempty"`  // e.g. "50GB"; empty for no size limit
+	MaxFiles  int    `yaml:"max_files,omitempty"` // 0 for no file count limit
+	Action    string `yaml:"action,omitempty"`    // "stop" routing there (the default), "notify", or run "workflow"
+	Workflow  string `yaml:"workflow,omitempty"`  // ID of the workflow run on the oldest files when the action is workflow
+}
+
+// JournalSettings bounds the activity log the watch daemon keeps of everything
+// it organizes. Either limit alone applies; with neither, the log is kept whole.
+type JournalSettings struct {
+	KeepDays    int `yaml:"keep_days,omitempty"`    // Drop entries older than this many days
+	KeepEntries int `yaml:"keep_entries,omitempty"` // Keep at most this many of the newest entries
+}
+
+// DocumentSettings configures how the date a document is about is found.
+// PDF text is read with pdftotext; OCR uses tesseract.
+type DocumentSettings struct {
+	OCR      bool `yaml:"ocr"`       // Read scanned PDFs and images with OCR
+	DayFirst bool `yaml:"day_first"` // Read ambiguous dates like 03/04/2024 as 3 April
+}
+
+// ScreenshotSettings turn on sortd's own handling of screenshots. Images
+// named the way macOS, Windows, GNOME, KDE, Android and other tools name
+// screenshots, or whose PNG metadata says a screenshot tool made them, are
+// renamed to {date}-{app or keyword}.png and filed by month, ahead of any rule.
+type ScreenshotSettings struct {
+	Enabled bool   `yaml:"enabled"`
+	Folder  string `yaml:"folder,omitempty"` // Where the month folders go; relative to the default directory (default "Screenshots")
+	OCR     bool   `yaml:"ocr,omitempty"`    // Read a keyword from the screenshot with tesseract when neither name nor metadata names the app
+}
+
+// VideoSettings configures video analysis. Metadata is read with ffprobe when
+// it is installed; thumbnails additionally need ffmpeg.
+type VideoSettings struct {
+	Thumbnails bool `yaml:"thumbnails"` // Store a preview frame of each analyzed video in the default directory
+}
+
+// ImageTaggingSettings configures image tagging. Tags always come from built-in
+// heuristics; a classifier served on this machine can add more. Images are
+// never sent off the machine, so the endpoint must be a loopback address.
+type ImageTaggingSettings struct {
+	Enabled  bool    `yaml:"enabled"`
+	Endpoint string  `yaml:"endpoint,omitempty"`  // e.g. http://127.0.0.1:8765/classify
+	MinScore float64 `yaml:"min_score,omitempty"` // Labels scoring below this (0-1) are ignored (default 0.5)
+}
+
+// EmbeddingSettings configures the embedding model used to find similar files.
+// Any OpenAI-compatible /embeddings endpoint works, including local model servers.
+type EmbeddingSettings struct {
+	Enabled   bool   `yaml:"enabled"`
+	Endpoint  string `yaml:"endpoint,omitempty"`    // e.g. http://localhost:11434/v1 for Ollama
+	Model     string `yaml:"model,omitempty"`       // e.g. nomic-embed-text
+	APIKey    string `yaml:"api_key,omitempty"`     // Sent as a bearer token, if set
+	APIKeyEnv string `yaml:"api_key_env,omitempty"` // Read the key from this environment variable instead
+}
+
+// TrainingSettings configures the training period: while it lasts, moves the watch
+// daemon would make automatically are staged in the pending queue for approval.
+type TrainingSettings struct {
+	Enabled bool      `yaml:"enabled"`
+	Days    int       `yaml:"days,omitempty"`    // Length of the period (default 30)
+	Started time.Time `yaml:"started,omitempty"` // Start of the period (default: first time the daemon stages a move)
+}
+
+// DefaultTrainingDays is the length of the training period when none is configured
+const DefaultTrainingDays = 30
+
+// Period returns the length of the training period
+func (t TrainingSettings) Period() time.Duration {
+	days := t.Days
+	if days <= 0 {
+		days = DefaultTrainingDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// ActiveAt reports whether the training period that began at started is still
+// running at now. A configured Started takes precedence over the given start.
+func (t TrainingSettings) ActiveAt(started, now time.Time) bool {
+	if !t.Enabled {
+		return false
+	}
+	if !t.Started.IsZero() {
+		started = t.Started
+	}
+	if started.IsZero() {
+		return true
+	}
+	return now.Before(started.Add(t.Period()))
+}
+
+// DigestSettings configures the periodic summary of watch activity and where it is delivered
+type DigestSettings struct {
+	Enabled   bool          `yaml:"enabled"`           // Send digests while the watch daemon runs
+	Frequency string        `yaml:"frequency"`         // daily or weekly (default daily)
+	Desktop   bool          `yaml:"desktop"`           // Deliver as a desktop notification
+	Webhook   string        `yaml:"webhook,omitempty"` // POST the digest as JSON to this URL
+	Email     EmailSettings `yaml:"email,omitempty"`   // Deliver by email when Host and To are set
+}
+
+// InboxSettings configures the inbox zero report: how many files are still
+// waiting in each inbox directory, sent through the digest's channels
+type InboxSettings struct {
+	Enabled     bool     `yaml:"enabled"`               // Send reports while the watch daemon runs
+	Frequency   string   `yaml:"frequency,omitempty"`   // daily or weekly (default weekly)
+	Directories []string `yaml:"directories,omitempty"` // Inboxes to count; defaults to the watch directories
+}
+
+// EmailSettings holds the SMTP details used to send digests
+type EmailSettings struct {
+	Host     string   `yaml:"host,omitempty"`
+	Port     int      `yaml:"port,omitempty"` // Defaults to 587
+	Username string   `yaml:"username,omitempty"`
+	Password string   `yaml:"password,omitempty"`
+	From     string   `yaml:"from,omitempty"`
+	To       []string `yaml:"to,omitempty"`
+}
+
+// WatchSettings tune how watched directories are followed. Watch directory
+// entries take the debounce and recursion from here unless they set their own.
+type WatchSettings struct {
+	IntervalSeconds int  `yaml:"interval_seconds,omitempty"` // How often watched directories are rescanned for files change events missed (0 = never)
+	DebounceSeconds int  `yaml:"debounce_seconds,omitempty"` // Wait until a file has been unchanged this long before handling it
+	BatchSize       int  `yaml:"batch_size,omitempty"`       // How many files are handled at once (0 = default of 4)
+	Recursive       bool `yaml:"recursive,omitempty"`        // Also watch the subdirectories of every watch directory
+}
+
+// DefaultBatchSize is how many files the watch daemon handles at once by default
+const DefaultBatchSize = 4
+
+// maxBatchSize keeps a typo from starting thousands of workers
+const maxBatchSize = 64
+
+// Workers returns how many files are handled at once
+func (w WatchSettings) Workers() int {
+	if w.BatchSize > 0 {
+		return w.BatchSize
+	}
+	return DefaultBatchSize
+}
+
+// Validate checks that no setting is negative or out of range
+func (w WatchSettings) Validate() error {
+	if w.IntervalSeconds < 0 || w.DebounceSeconds < 0 || w.BatchSize < 0 {
+		return fmt.Errorf("watch interval_seconds, debounce_seconds and batch_size cannot be negative")
+	}
+	if w.BatchSize > maxBatchSize {
+		return fmt.Errorf("watch batch_size cannot be more than %d", maxBatchSize)
+	}
+	return nil
+}
+
+// WatchFilter restricts which files in a watched directory reach rules and workflows.
+// Globs are matched against the file name, like organize patterns.
+type WatchFilter struct {
+	Directory string   `yaml:"directory,omitempty"` // Watch directory the filter applies to (empty applies to all)
+	Include   []string `yaml:"include,omitempty"`   // Only react to files matching one of these globs
+	Exclude   []string `yaml:"exclude,omitempty"`   // Ignore files matching any of these globs
+}
+
+// LandingZone is a directory files are only meant to pass through, like a
+// scanner inbox. The watch daemon alerts about files still there after
+// MaxHours, which usually means no rule covers them.
+type LandingZone struct {
+	Directory string `yaml:"directory"` // The watched directory
+	MaxHours  int    `yaml:"max_hours"` // How long a file may stay
+}
+
+// StabilityWindow describes how long the watcher waits before treating a file as
+// complete. The first window whose pattern matches the file name is used.
+type StabilityWindow struct {
+	Pattern        string   `yaml:"pattern"`                    // Glob matched against the file name
+	StableSeconds  int      `yaml:"stable_seconds,omitempty"`   // Wait until size and mtime are unchanged for this long
+	TempSuffixes   []string `yaml:"temp_suffixes,omitempty"`    // Wait while a temporary sibling (e.g. file.pdf.part) exists
+	WaitForLock    bool     `yaml:"wait_for_lock,omitempty"`    // Wait until no other process holds a lock on the file
+	WaitForClose   bool     `yaml:"wait_for_close,omitempty"`   // Wait until no other process has the file open (lsof on macOS and BSD)
+	MaxWaitSeconds int      `yaml:"max_wait_seconds,omitempty"` // Give up after this long (0 uses the default)
+}
+
+// WebhookServer configures the daemon's webhook trigger endpoint
+type WebhookServer struct {
+	Listen string `yaml:"listen,omitempty"` // Address to listen on, e.g. "127.0.0.1:8787" (empty disables)
+	Token  string `yaml:"token,omitempty"`  // Bearer token callers must present (empty allows any caller)
+}
+
+// HTTPSettings secure the daemon's network endpoints: the webhook trigger, the
+// health check and the web UI
+type HTTPSettings struct {
+	Bind         string `yaml:"bind,omitempty"`           // Host for listen addresses that only give a port (default 127.0.0.1)
+	Token        string `yaml:"token,omitempty"`          // Bearer token every endpoint requires (empty allows any caller)
+	TLS          bool   `yaml:"tls,omitempty"`            // Serve HTTPS, with a generated self-signed certificate unless cert_file is set
+	CertFile     string `yaml:"cert_file,omitempty"`      // PEM certificate to serve instead of the generated one
+	KeyFile      string `yaml:"key_file,omitempty"`       // Its private key
+	ClientCAFile string `yaml:"client_ca_file,omitempty"` // Require client certificates signed by this CA (mutual TLS)
+
+	AllowInsecure bool `yaml:"allow_insecure,omitempty"` // Serve addresses off this machine without a token or client certificates
 }
 
 // DaemonStatus represents the status of the watch daemon
@@ -56,22 +423,22 @@ type DaemonStatus struct {
 	WatchDirectories []string
 	LastActivity     time.Time
 	FilesProcessed   int
+	Directories      []types.DirectoryStats
+	Paused           string // Why work is held back, e.g. "on battery"; "" when it isn't
 }
 
-// LoadConfig loads configuration from the default location
-// (~/.config/sortd/config.yaml).
+// LoadConfig loads configuration from the default location (see ConfigPath).
 func LoadConfig() (*Config, error) {
-	home, err := os.UserHomeDir()
+	configPath, err := ConfigPath()
 	if err != nil {
 		return nil, err
 	}
-
-	configPath := filepath.Join(home, ".config", "sortd", "config.yaml")
 	return LoadConfigFile(configPath)
 }
 
 // LoadConfigFile loads configuration from a specific file path.
-// If the file doesn't exist, returns default configuration.
+// If the file doesn't exist, returns default configuration. Either way,
+// SORTD_* environment variables override individual keys.
 func LoadConfigFile(path string) (*Config, error) {
 	// Start with default configuration
 	cfg := defaultConfig()
@@ -80,7 +447,11 @@ func LoadConfigFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return cfg, nil // Return defaults if file doesn't exist
+			// Use defaults if the file doesn't exist, still honoring SORTD_* variables
+			if err := cfg.ApplyEnv(); err != nil {
+				return nil, err
+			}
+			return cfg, nil
 		}
 		return nil, fmt.Errorf("error reading config file: %w", err)
 	}
@@ -111,7 +482,21 @@ func LoadConfigFile(path string) (*Config, error) {
 		cfg.WatchDirectories = tempCfg.WatchDirectories
 	}
 
-	cfg.WatchMode.Enabled = tempCfg.WatchMode.Enabled
+	// Watch mode has no defaults to keep, so it is taken whole and new
+	// settings can't be left behind
+	cfg.WatchMode = tempCfg.WatchMode
+
+	cfg.Classifications = tempCfg.Classifications
+	cfg.Profiles = tempCfg.Profiles
+	cfg.Bookmarks = tempCfg.Bookmarks
+
+	// Rewrite globs written for the old matcher so they keep their meaning
+	cfg.globMigrations = cfg.MigrateGlobs()
+
+	// SORTD_* environment variables take precedence over the file
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
 
 	// Validate the final configuration
 	if err := cfg.Validate(); err != nil {
@@ -144,13 +529,10 @@ func defaultConfig() *Config {
 
 	// Initialize directories struct
 	cfg.Directories.Default = "." // Current directory by default
-	cfg.Directories.Watch = []string{}
+	cfg.Directories.Watch = []WatchDir{}
 
 	// Initialize empty rules slice
-	cfg.Rules = []struct {
-		Pattern string `yaml:"pattern"`
-		Target  string `yaml:"target"`
-	}{}
+	cfg.Rules = []Rule{}
 
 	// Initialize empty watch directories slice
 	cfg.WatchDirectories = []string{}
@@ -161,30 +543,27 @@ func defaultConfig() *Config {
 	return cfg
 }
 
-// Save saves the configuration to the default location.
+// Save saves the configuration to the default location (see ConfigPath).
 // Creates the config directory if it doesn't exist.
 func (c *Config) Save() error {
 	if c == nil {
 		return fmt.Errorf("nil config")
 	}
 
-	home, err := os.UserHomeDir()
+	configPath, err := ConfigPath()
 	if err != nil {
 		return err
 	}
-
-	configDir := filepath.Join(home, ".config", "sortd")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
 		return err
 	}
 
-	configPath := filepath.Join(configDir, "config.yaml")
 	data, err := yaml.Marshal(c)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	return atomicfile.WriteFile(configPath, data, 0644)
 }
 
 // Validate checks if the configuration is valid.
@@ -199,6 +578,46 @@ func (c *Config) Validate() error {
 	if !validCollisions[c.Settings.Collision] {
 		return fmt.Errorf("invalid collision setting: %s", c.Settings.Collision)
 	}
+	switch c.Settings.Duplicates.Versioning {
+	case "", "counter", "date":
+	default:
+		return fmt.Errorf("invalid duplicates versioning %q: must be counter or date", c.Settings.Duplicates.Versioning)
+	}
+	switch c.Settings.Unmatched.Policy {
+	case "", "leave", "move", "review":
+	default:
+		return fmt.Errorf("invalid unmatched policy %q: must be leave, move or review", c.Settings.Unmatched.Policy)
+	}
+	for i, quota := range c.Settings.Quotas {
+		if strings.TrimSpace(quota.Directory) == "" {
+			return fmt.Errorf("quota %d: directory cannot be empty", i)
+		}
+		if quota.MaxSize == "" && quota.MaxFiles <= 0 {
+			return fmt.Errorf("quota %d: needs max_size or max_files", i)
+		}
+		if quota.MaxSize != "" {
+			if _, err := units.ParseSize(quota.MaxSize); err != nil {
+				return fmt.Errorf("quota %d: invalid max_size: %w", i, err)
+			}
+		}
+		switch quota.Action {
+		case "", "stop", "notify":
+		case "workflow":
+			if quota.Workflow == "" {
+				return fmt.Errorf("quota %d: the workflow action needs a workflow ID", i)
+			}
+		default:
+			return fmt.Errorf("quota %d: invalid action %q: must be stop, notify or workflow", i, quota.Action)
+		}
+	}
+	for i, dir := range c.Settings.Manifests {
+		if strings.TrimSpace(dir) == "" {
+			return fmt.Errorf("manifest %d: directory cannot be empty", i)
+		}
+	}
+	if c.Settings.Duplicates.DeleteIdentical && !c.Settings.Duplicates.CompareContent {
+		return fmt.Errorf("duplicates delete_identical needs compare_content")
+	}
 
 	// Validate patterns
 	for i, pattern := range c.Organize.Patterns {
@@ -208,6 +627,47 @@ func (c *Config) Validate() error {
 		if strings.TrimSpace(pattern.Target) == "" {
 			return fmt.Errorf("pattern %d: target directory cannot be empty", i)
 		}
+		switch pattern.Type {
+		case "", types.GlobPattern:
+			if err := globs.Validate(pattern.Match); err != nil {
+				return fmt.Errorf("pattern %d: %w", i, err)
+			}
+		case types.RegexPattern:
+			if err := globs.ValidateRegex(pattern.Match, pattern.Target); err != nil {
+				return fmt.Errorf("pattern %d: %w", i, err)
+			}
+		default:
+			return fmt.Errorf("pattern %d: invalid pattern_type %q: must be glob or regex", i, pattern.Type)
+		}
+		for _, exclude := range pattern.Exclude {
+			if err := globs.Validate(exclude); err != nil {
+				return fmt.Errorf("pattern %d: exclude: %w", i, err)
+			}
+		}
+		for j, tier := range pattern.Tiers {
+			if _, err := units.ParseSize(tier.MinSize); err != nil {
+				return fmt.Errorf("pattern %d: tier %d: min_size: %w", i, j, err)
+			}
+			if strings.TrimSpace(tier.Target) == "" {
+				return fmt.Errorf("pattern %d: tier %d: target directory cannot be empty", i, j)
+			}
+			if pattern.Type == types.RegexPattern {
+				if err := globs.ValidateRegex(pattern.Match, tier.Target); err != nil {
+					return fmt.Errorf("pattern %d: tier %d: %w", i, j, err)
+				}
+			}
+		}
+		if pattern.MinDepth < 0 || pattern.MaxDepth < 0 {
+			return fmt.Errorf("pattern %d: min_depth and max_depth cannot be negative", i)
+		}
+		if pattern.MaxDepth > 0 && pattern.MinDepth > pattern.MaxDepth {
+			return fmt.Errorf("pattern %d: min_depth %d is deeper than max_depth %d", i, pattern.MinDepth, pattern.MaxDepth)
+		}
+	}
+
+	// Validate profiles
+	if err := c.validateProfiles(); err != nil {
+		return err
 	}
 
 	// Validate rules
@@ -218,6 +678,9 @@ func (c *Config) Validate() error {
 		if rule.Target == "" {
 			return fmt.Errorf("rule %d: target is required", i)
 		}
+		if err := globs.Validate(rule.Pattern); err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
 	}
 
 	// Validate directories
@@ -233,12 +696,141 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate bookmarks
+	for name, path := range c.Bookmarks {
+		if err := validateBookmarkName(name); err != nil {
+			return err
+		}
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("bookmark %q: path cannot be empty", name)
+		}
+	}
+
 	// Validate watch directories
 	for i, dir := range c.WatchDirectories {
 		if strings.TrimSpace(dir) == "" {
 			return fmt.Errorf("watch directory %d: path cannot be empty", i)
 		}
 	}
+	for i, dir := range c.Directories.Watch {
+		if err := dir.validate(); err != nil {
+			return fmt.Errorf("directories.watch %d: %w", i, err)
+		}
+	}
+
+	// Validate watch filters
+	for i, filter := range c.WatchMode.Filters {
+		for _, pattern := range append(append([]string{}, filter.Include...), filter.Exclude...) {
+			if err := globs.Validate(pattern); err != nil {
+				return fmt.Errorf("watch filter %d: %w", i, err)
+			}
+		}
+	}
+
+	// Validate the watch backend
+	switch c.WatchMode.Backend {
+	case "", "fsnotify", "poll":
+	default:
+		return fmt.Errorf("invalid watch backend %q: must be fsnotify or poll", c.WatchMode.Backend)
+	}
+	if err := c.WatchMode.WatchSettings.Validate(); err != nil {
+		return err
+	}
+	if c.WatchMode.PollSeconds < 0 {
+		return fmt.Errorf("watch poll_seconds cannot be negative")
+	}
+	if c.WatchMode.SuperviseSeconds < 0 || c.WatchMode.DropAfterSeconds < 0 {
+		return fmt.Errorf("watch supervise_seconds and drop_after_seconds cannot be negative")
+	}
+
+	// Validate landing zones
+	for i, zone := range c.WatchMode.LandingZones {
+		if strings.TrimSpace(zone.Directory) == "" {
+			return fmt.Errorf("landing zone %d: directory cannot be empty", i)
+		}
+		if zone.MaxHours <= 0 {
+			return fmt.Errorf("landing zone %s: max_hours must be positive", zone.Directory)
+		}
+	}
+
+	// Validate the network endpoints' TLS settings
+	httpSettings := c.WatchMode.HTTP
+	if (httpSettings.CertFile == "") != (httpSettings.KeyFile == "") {
+		return fmt.Errorf("watch_mode.http: cert_file and key_file must be set together")
+	}
+	if !httpSettings.TLS && (httpSettings.CertFile != "" || httpSettings.ClientCAFile != "") {
+		return fmt.Errorf("watch_mode.http: cert_file and client_ca_file need tls: true")
+	}
+
+	// Validate digest settings
+	switch c.Settings.Digest.Frequency {
+	case "", "daily", "weekly":
+	default:
+		return fmt.Errorf("invalid digest frequency: %s", c.Settings.Digest.Frequency)
+	}
+	if c.Settings.Digest.Email.Host != "" && len(c.Settings.Digest.Email.To) == 0 {
+		return fmt.Errorf("digest email: at least one recipient is required")
+	}
+	switch c.Settings.Inbox.Frequency {
+	case "", "daily", "weekly":
+	default:
+		return fmt.Errorf("invalid inbox_zero frequency: %s", c.Settings.Inbox.Frequency)
+	}
+
+	// Validate training settings
+	if c.Settings.Training.Days < 0 {
+		return fmt.Errorf("training days cannot be negative")
+	}
+
+	if err := c.Settings.Priority.Validate(); err != nil {
+		return err
+	}
+
+	if c.Settings.Journal.KeepDays < 0 || c.Settings.Journal.KeepEntries < 0 {
+		return fmt.Errorf("journal keep_days and keep_entries cannot be negative")
+	}
+
+	if c.Settings.AutoApplyConfidence < 0 || c.Settings.AutoApplyConfidence > 1 {
+		return fmt.Errorf("auto_apply_confidence must be between 0 and 1")
+	}
+
+	// Validate embedding settings
+	if c.Settings.Embeddings.Enabled && (c.Settings.Embeddings.Endpoint == "" || c.Settings.Embeddings.Model == "") {
+		return fmt.Errorf("embeddings: endpoint and model are required when enabled")
+	}
+
+	// Validate image tagging settings
+	if c.Settings.ImageTagging.MinScore < 0 || c.Settings.ImageTagging.MinScore > 1 {
+		return fmt.Errorf("image_tagging: min_score must be between 0 and 1")
+	}
+	if endpoint := c.Settings.ImageTagging.Endpoint; endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("image_tagging: invalid endpoint: %w", err)
+		}
+		if host := u.Hostname(); host != "localhost" {
+			if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+				return fmt.Errorf("image_tagging: endpoint must be on this machine (localhost or a loopback address)")
+			}
+		}
+	}
+
+	if err := classify.Validate(c.Classifications); err != nil {
+		return err
+	}
+
+	// Validate stability windows
+	for i, window := range c.WatchMode.Stability {
+		if strings.TrimSpace(window.Pattern) == "" {
+			return fmt.Errorf("stability window %d: pattern is required", i)
+		}
+		if err := globs.Validate(window.Pattern); err != nil {
+			return fmt.Errorf("stability window %d: %w", i, err)
+		}
+		if window.StableSeconds < 0 || window.MaxWaitSeconds < 0 {
+			return fmt.Errorf("stability window %d: durations cannot be negative", i)
+		}
+	}
 
 	return nil
 }
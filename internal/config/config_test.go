@@ -4,12 +4,14 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"sortd/internal/config"
 	"sortd/pkg/types" // Needed for patterns in assertions
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // Helper function to create a temporary YAML config file
@@ -59,6 +61,141 @@ settings:
 watch_directories:
   - ""
   - "/valid/path"
+`
+	watchFiltersYAML = `
+settings:
+  collision: "rename"
+watch_directories:
+  - "/home/test/scans"
+watch_mode:
+  enabled: true
+  filters:
+    - directory: "/home/test/scans"
+      include: ["*.pdf"]
+    - exclude: ["*.part", "*.crdownload"]
+`
+	watchDirsYAML = `
+settings:
+  collision: "rename"
+directories:
+  watch:
+    - "/home/test/desktop"
+    - path: "/home/test/downloads"
+      recursive: true
+      include: ["*.pdf"]
+      exclude: ["*.part"]
+      debounce_seconds: 3
+      backend: "poll"
+      profile: "work"
+watch_directories:
+  - "/home/test/desktop"
+  - "/home/test/scans"
+`
+	invalidWatchDirYAML = `
+settings:
+  collision: "rename"
+directories:
+  watch:
+    - path: "/home/test/downloads"
+      backend: "inotify"
+`
+	watchSettingsYAML = `
+settings:
+  collision: "rename"
+directories:
+  watch:
+    - "/home/test/desktop"
+    - path: "/home/test/downloads"
+      debounce_seconds: 3
+watch_mode:
+  interval_seconds: 300
+  debounce_seconds: 2
+  batch_size: 8
+  recursive: true
+`
+	stabilityYAML = `
+settings:
+  collision: "rename"
+watch_mode:
+  stability:
+    - pattern: "*.pdf"
+      stable_seconds: 5
+      temp_suffixes: [".part", ".crdownload"]
+      wait_for_lock: true
+`
+	invalidStabilityYAML = `
+settings:
+  collision: "rename"
+watch_mode:
+  stability:
+    - stable_seconds: 5
+`
+	invalidWatchFilterYAML = `
+settings:
+  collision: "rename"
+watch_mode:
+  filters:
+    - include: ["[unclosed"]
+`
+	legacyGlobsYAML = `
+settings:
+  collision: "rename"
+organize:
+  patterns:
+    - match: "[^.]*.pdf"
+      target: "Documents"
+    - match: "*.{jpg,png}"
+      target: "Images"
+watch_mode:
+  filters:
+    - exclude: ["{draft}*"]
+`
+	invalidPatternGlobYAML = `
+settings:
+  collision: "rename"
+organize:
+  patterns:
+    - match: "*.{jpg,png"
+      target: "Images"
+`
+	regexPatternYAML = `
+settings:
+  collision: "rename"
+organize:
+  patterns:
+    - match: '(?P<show>.+)\.S(?P<season>\d+)E\d+.*'
+      pattern_type: regex
+      target: "TV/{show}/Season {season}"
+`
+	invalidRegexTargetYAML = `
+settings:
+  collision: "rename"
+organize:
+  patterns:
+    - match: '(?P<show>.+)\.mkv'
+      pattern_type: regex
+      target: "TV/{show}/Season {season}"
+`
+	classificationsYAML = `
+settings:
+  collision: "rename"
+classifications:
+  - name: invoice
+    criteria:
+      keywords: [{value: invoice, weight: 2}]
+`
+	profilesYAML = `
+settings:
+  collision: "rename"
+profiles:
+  - name: docked
+    min_displays: 2
+    mounted: [/mnt/nas]
+organize:
+  patterns:
+    - match: "*.mkv"
+      target: /mnt/nas/Videos
+      profile: docked
 `
 )
 
@@ -76,8 +213,8 @@ func TestLoadConfigFile(t *testing.T) {
 		assert.Equal(t, "/path/to/images", cfg.Organize.Patterns[0].Target)
 		assert.Equal(t, "rename", cfg.Settings.Collision)
 		assert.Equal(t, "/home/test", cfg.Directories.Default)
-		assert.Equal(t, "/home/test/docs", cfg.Directories.Watch[0])
-		assert.Equal(t, "/home/test/images", cfg.Directories.Watch[1])
+		assert.Equal(t, "/home/test/docs", cfg.Directories.Watch[0].Path)
+		assert.Equal(t, "/home/test/images", cfg.Directories.Watch[1].Path)
 		assert.Equal(t, false, cfg.Settings.DryRun)
 		assert.Equal(t, true, cfg.Settings.CreateDirs)
 		assert.Equal(t, true, cfg.Settings.Backup)
@@ -126,7 +263,287 @@ func TestLoadConfigFile(t *testing.T) {
 	})
 }
 
+func TestLoadConfigFile_WatchFilters(t *testing.T) {
+	t.Run("load filters", func(t *testing.T) {
+		configFile := createTestYAML(t, watchFiltersYAML)
+		cfg, err := config.LoadConfigFile(configFile)
+		require.NoError(t, err)
+
+		require.Len(t, cfg.WatchMode.Filters, 2)
+		assert.Equal(t, "/home/test/scans", cfg.WatchMode.Filters[0].Directory)
+		assert.Equal(t, []string{"*.pdf"}, cfg.WatchMode.Filters[0].Include)
+		assert.Empty(t, cfg.WatchMode.Filters[1].Directory)
+		assert.Equal(t, []string{"*.part", "*.crdownload"}, cfg.WatchMode.Filters[1].Exclude)
+	})
+
+	t.Run("reject invalid glob", func(t *testing.T) {
+		configFile := createTestYAML(t, invalidWatchFilterYAML)
+		_, err := config.LoadConfigFile(configFile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid glob")
+	})
+}
+
+func TestLoadConfigFile_RoundTrip(t *testing.T) {
+	cfg := config.New()
+	cfg.WatchMode.Enabled = true
+	cfg.WatchMode.WaitForClose = true
+	cfg.WatchMode.PauseOnBattery = true
+	cfg.WatchMode.PauseOnMetered = true
+	cfg.WatchMode.Backend = "poll"
+	cfg.WatchMode.PollSeconds = 10
+	cfg.WatchMode.SuperviseSeconds = 7
+	cfg.WatchMode.DropAfterSeconds = 120
+	cfg.WatchMode.AllowFeedbackLoops = true
+
+	data, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	loaded, err := config.LoadConfigFile(createTestYAML(t, string(data)))
+	require.NoError(t, err)
+
+	assert.Equal(t, cfg.WatchMode, loaded.WatchMode, "Every watch mode setting survives a save and load")
+	assert.Equal(t, cfg.Settings, loaded.Settings)
+}
+
+func TestLoadConfigFile_WatchDirs(t *testing.T) {
+	t.Run("load plain and structured entries", func(t *testing.T) {
+		configFile := createTestYAML(t, watchDirsYAML)
+		cfg, err := config.LoadConfigFile(configFile)
+		require.NoError(t, err)
+
+		require.Len(t, cfg.Directories.Watch, 2)
+		assert.Equal(t, config.WatchDir{Path: "/home/test/desktop"}, cfg.Directories.Watch[0])
+		assert.Equal(t, config.WatchDir{
+			Path:            "/home/test/downloads",
+			Recursive:       true,
+			Include:         []string{"*.pdf"},
+			Exclude:         []string{"*.part"},
+			DebounceSeconds: 3,
+			Backend:         "poll",
+			Profile:         "work",
+		}, cfg.Directories.Watch[1])
+
+		assert.Equal(t, []string{"/home/test/desktop", "/home/test/downloads", "/home/test/scans"}, cfg.WatchPaths(),
+			"watch_directories adds the paths not already listed")
+
+		dir, ok := cfg.WatchDirFor("/home/test/downloads/2024/may")
+		assert.True(t, ok)
+		assert.Equal(t, "/home/test/downloads", dir.Path)
+		_, ok = cfg.WatchDirFor("/home/test/desktop/old")
+		assert.False(t, ok, "Plain entries don't cover subdirectories")
+
+		assert.Contains(t, cfg.WatchFilters(), config.WatchFilter{
+			Directory: "/home/test/downloads", Include: []string{"*.pdf"}, Exclude: []string{"*.part"},
+		})
+	})
+
+	t.Run("save plain entries in the short form", func(t *testing.T) {
+		cfg := config.New()
+		cfg.Directories.Watch = []config.WatchDir{{Path: "/a"}, {Path: "/b", Recursive: true}}
+
+		data, err := yaml.Marshal(cfg)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "watch:\n        - /a\n        - path: /b\n          recursive: true\n")
+	})
+
+	t.Run("reject invalid backend", func(t *testing.T) {
+		configFile := createTestYAML(t, invalidWatchDirYAML)
+		_, err := config.LoadConfigFile(configFile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "directories.watch 0: invalid backend")
+	})
+}
+
+func TestLoadConfigFile_WatchSettings(t *testing.T) {
+	t.Run("watch directories take the defaults", func(t *testing.T) {
+		configFile := createTestYAML(t, watchSettingsYAML)
+		cfg, err := config.LoadConfigFile(configFile)
+		require.NoError(t, err)
+
+		assert.Equal(t, config.WatchSettings{IntervalSeconds: 300, DebounceSeconds: 2, BatchSize: 8, Recursive: true}, cfg.WatchMode.WatchSettings)
+		assert.Equal(t, 8, cfg.WatchMode.Workers())
+
+		dirs := cfg.WatchDirs()
+		require.Len(t, dirs, 2)
+		assert.Equal(t, config.WatchDir{Path: "/home/test/desktop", Recursive: true, DebounceSeconds: 2}, dirs[0])
+		assert.Equal(t, 3, dirs[1].DebounceSeconds, "Entries keep their own debounce")
+		assert.Equal(t, config.WatchDir{Path: "/home/test/desktop"}, cfg.Directories.Watch[0], "The defaults aren't written into the entries")
+	})
+
+	t.Run("reject out of range values", func(t *testing.T) {
+		cfg := config.New()
+		cfg.WatchMode.BatchSize = -1
+		assert.Error(t, cfg.Validate())
+		cfg.WatchMode.BatchSize = 1000
+		assert.Error(t, cfg.Validate())
+	})
+
+	t.Run("override from the environment", func(t *testing.T) {
+		cfg := config.New()
+		require.NoError(t, cfg.Set("watch_mode.interval_seconds", "60"))
+		assert.Equal(t, 60, cfg.WatchMode.IntervalSeconds)
+		assert.Equal(t, config.DefaultBatchSize, cfg.WatchMode.Workers())
+	})
+}
+
+func TestLoadConfigFile_Classifications(t *testing.T) {
+	configFile := createTestYAML(t, classificationsYAML)
+	cfg, err := config.LoadConfigFile(configFile)
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Classifications, 1)
+	assert.Equal(t, "invoice", cfg.Classifications[0].Name)
+	require.Len(t, cfg.Classifications[0].Criteria.Keywords, 1)
+	assert.Equal(t, "invoice", cfg.Classifications[0].Criteria.Keywords[0].Value)
+}
+
+func TestLoadConfigFile_Profiles(t *testing.T) {
+	cfg, err := config.LoadConfigFile(createTestYAML(t, profilesYAML))
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Profiles, 1)
+	docked, ok := cfg.ProfileNamed("docked")
+	require.True(t, ok)
+	assert.Equal(t, 2, docked.MinDisplays)
+	assert.Equal(t, []string{"/mnt/nas"}, docked.Mounted)
+	assert.Equal(t, "docked", cfg.Organize.Patterns[0].Profile)
+
+	cfg.Profiles = append(cfg.Profiles, config.Profile{Name: "docked"})
+	assert.ErrorContains(t, cfg.Validate(), "defined twice")
+	cfg.Profiles = []config.Profile{{Name: "docked", MinDisplays: 3, MaxDisplays: 2}}
+	assert.Error(t, cfg.Validate())
+	cfg.Profiles = []config.Profile{{Name: "docked", Hostnames: []string{"["}}}
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidate_SizeTiers(t *testing.T) {
+	cfg := config.New()
+	cfg.Organize.Patterns = []types.Pattern{{
+		Match:  "*.mp4",
+		Target: "/ssd/Videos",
+		Tiers:  []types.SizeTier{{MinSize: "2GB", Target: "/nas/Videos"}},
+	}}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Organize.Patterns[0].Tiers[0].MinSize = "large"
+	assert.ErrorContains(t, cfg.Validate(), "min_size")
+	cfg.Organize.Patterns[0].Tiers[0].MinSize = ""
+	assert.ErrorContains(t, cfg.Validate(), "min_size")
+}
+
+func TestLoadConfigFile_StabilityWindows(t *testing.T) {
+	t.Run("load windows", func(t *testing.T) {
+		configFile := createTestYAML(t, stabilityYAML)
+		cfg, err := config.LoadConfigFile(configFile)
+		require.NoError(t, err)
+
+		require.Len(t, cfg.WatchMode.Stability, 1)
+		window := cfg.WatchMode.Stability[0]
+		assert.Equal(t, "*.pdf", window.Pattern)
+		assert.Equal(t, 5, window.StableSeconds)
+		assert.Equal(t, []string{".part", ".crdownload"}, window.TempSuffixes)
+		assert.True(t, window.WaitForLock)
+	})
+
+	t.Run("reject missing pattern", func(t *testing.T) {
+		configFile := createTestYAML(t, invalidStabilityYAML)
+		_, err := config.LoadConfigFile(configFile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pattern is required")
+	})
+}
+
+func TestLoadConfigFile_GlobMigration(t *testing.T) {
+	t.Run("rewrite legacy globs", func(t *testing.T) {
+		configFile := createTestYAML(t, legacyGlobsYAML)
+		cfg, err := config.LoadConfigFile(configFile)
+		require.NoError(t, err)
+
+		assert.Equal(t, "[!.]*.pdf", cfg.Organize.Patterns[0].Match)
+		assert.Equal(t, "*.{jpg,png}", cfg.Organize.Patterns[1].Match)
+		assert.Equal(t, `\{draft\}*`, cfg.WatchMode.Filters[0].Exclude[0])
+		assert.Equal(t, []config.GlobMigration{
+			{Field: "organize.patterns[0].match", Old: "[^.]*.pdf", New: "[!.]*.pdf"},
+			{Field: "watch_mode.filters[0].exclude[0]", Old: "{draft}*", New: `\{draft\}*`},
+		}, cfg.GlobMigrations())
+	})
+
+	t.Run("reject invalid pattern glob", func(t *testing.T) {
+		configFile := createTestYAML(t, invalidPatternGlobYAML)
+		_, err := config.LoadConfigFile(configFile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unclosed '{'")
+	})
+}
+
+func TestLoadConfigFile_RegexPatterns(t *testing.T) {
+	t.Run("load regex pattern", func(t *testing.T) {
+		cfg, err := config.LoadConfigFile(createTestYAML(t, regexPatternYAML))
+		require.NoError(t, err)
+		require.Len(t, cfg.Organize.Patterns, 1)
+		assert.Equal(t, types.RegexPattern, cfg.Organize.Patterns[0].Type)
+		assert.Equal(t, `(?P<show>.+)\.S(?P<season>\d+)E\d+.*`, cfg.Organize.Patterns[0].Match)
+		assert.Empty(t, cfg.GlobMigrations(), "Regexes aren't globs and aren't migrated")
+	})
+
+	t.Run("reject unknown group in target", func(t *testing.T) {
+		_, err := config.LoadConfigFile(createTestYAML(t, invalidRegexTargetYAML))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "{season}")
+	})
+}
+
+func TestOrderedRules(t *testing.T) {
+	cfg := &config.Config{Rules: []config.Rule{
+		{Pattern: "*.pdf", Target: "Documents"},
+		{Pattern: "*invoice*", Target: "Invoices", Priority: 5},
+		{Pattern: "*.txt", Target: "Notes"},
+	}}
+
+	ordered := cfg.OrderedRules()
+	assert.Equal(t, []string{"Invoices", "Documents", "Notes"},
+		[]string{ordered[0].Target, ordered[1].Target, ordered[2].Target})
+	assert.Equal(t, "Documents", cfg.Rules[0].Target, "The config keeps its order")
+}
+
+func TestTrainingSettings_ActiveAt(t *testing.T) {
+	now := time.Now()
+
+	training := config.TrainingSettings{Enabled: true, Days: 30}
+	assert.True(t, training.ActiveAt(time.Time{}, now), "An unstarted period is active")
+	assert.True(t, training.ActiveAt(now.Add(-29*24*time.Hour), now))
+	assert.False(t, training.ActiveAt(now.Add(-31*24*time.Hour), now))
+
+	training.Started = now.Add(-31 * 24 * time.Hour)
+	assert.False(t, training.ActiveAt(now, now), "A configured start takes precedence")
+
+	assert.Equal(t, config.DefaultTrainingDays*24*time.Hour, config.TrainingSettings{}.Period())
+	assert.False(t, config.TrainingSettings{}.ActiveAt(now, now), "Disabled training is never active")
+}
+
+func TestRetrySettings(t *testing.T) {
+	assert.Equal(t, config.DefaultRetryAttempts, config.RetrySettings{}.MaxRetries())
+	assert.Equal(t, 0, config.RetrySettings{Attempts: -1}.MaxRetries())
+
+	retry := config.RetrySettings{DelaySeconds: 2}
+	assert.Equal(t, 2*time.Second, retry.Delay(1))
+	assert.Equal(t, 8*time.Second, retry.Delay(3), "The pause doubles with each retry")
+	assert.Equal(t, 5*time.Minute, retry.Delay(20), "The pause is capped")
+}
+
 // Moved from tests/config_test.go
+func TestPrioritySettings(t *testing.T) {
+	assert.Zero(t, config.PrioritySettings{}.BandwidthLimit())
+	assert.Equal(t, int64(10*1024*1024), config.PrioritySettings{RemoteBandwidth: "10MB"}.BandwidthLimit())
+
+	assert.NoError(t, config.PrioritySettings{Nice: 10, IOClass: "idle", RemoteBandwidth: "512KB"}.Validate())
+	assert.Error(t, config.PrioritySettings{Nice: -5}.Validate(), "Raising priority needs privileges")
+	assert.Error(t, config.PrioritySettings{Nice: 20}.Validate())
+	assert.Error(t, config.PrioritySettings{IOClass: "realtime"}.Validate())
+	assert.Error(t, config.PrioritySettings{IOLevel: 8}.Validate())
+	assert.Error(t, config.PrioritySettings{RemoteBandwidth: "fast"}.Validate())
+}
+
 func TestConfigValidation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -148,11 +565,11 @@ func TestConfigValidation(t *testing.T) {
 					Collision:  "overwrite",
 				},
 				Directories: struct {
-					Default string   `yaml:"default"`
-					Watch   []string `yaml:"watch"`
+					Default string            `yaml:"default"`
+					Watch   []config.WatchDir `yaml:"watch"`
 				}{
 					Default: "/home/test",
-					Watch:   []string{"/home/test/docs", "/home/test/images"},
+					Watch:   []config.WatchDir{{Path: "/home/test/docs"}, {Path: "/home/test/images"}},
 				},
 				WatchDirectories: []string{"/valid/watch/dir"},
 			},
@@ -173,11 +590,11 @@ func TestConfigValidation(t *testing.T) {
 					Patterns: []types.Pattern{{Match: "*", Target: "/dest"}},
 				},
 				Directories: struct {
-					Default string   `yaml:"default"`
-					Watch   []string `yaml:"watch"`
+					Default string            `yaml:"default"`
+					Watch   []config.WatchDir `yaml:"watch"`
 				}{
 					Default: "/home/test",
-					Watch:   []string{"/home/test/docs", "/home/test/images"},
+					Watch:   []config.WatchDir{{Path: "/home/test/docs"}, {Path: "/home/test/images"}},
 				},
 				WatchDirectories: []string{"/valid/watch/dir"},
 			},
@@ -198,11 +615,11 @@ func TestConfigValidation(t *testing.T) {
 					Collision:  "skip",
 				},
 				Directories: struct {
-					Default string   `yaml:"default"`
-					Watch   []string `yaml:"watch"`
+					Default string            `yaml:"default"`
+					Watch   []config.WatchDir `yaml:"watch"`
 				}{
 					Default: "/home/test",
-					Watch:   []string{"/home/test/docs", "/home/test/images"},
+					Watch:   []config.WatchDir{{Path: "/home/test/docs"}, {Path: "/home/test/images"}},
 				},
 				WatchDirectories: []string{"/valid/watch/dir"},
 			},
@@ -223,11 +640,11 @@ func TestConfigValidation(t *testing.T) {
 					Collision:  "rename",
 				},
 				Directories: struct {
-					Default string   `yaml:"default"`
-					Watch   []string `yaml:"watch"`
+					Default string            `yaml:"default"`
+					Watch   []config.WatchDir `yaml:"watch"`
 				}{
 					Default: "/home/test",
-					Watch:   []string{"/home/test/docs", "/home/test/images"},
+					Watch:   []config.WatchDir{{Path: "/home/test/docs"}, {Path: "/home/test/images"}},
 				},
 				WatchDirectories: []string{"/valid/watch/dir"},
 			},
@@ -248,16 +665,36 @@ func TestConfigValidation(t *testing.T) {
 					Collision:  "rename",
 				},
 				Directories: struct {
-					Default string   `yaml:"default"`
-					Watch   []string `yaml:"watch"`
+					Default string            `yaml:"default"`
+					Watch   []config.WatchDir `yaml:"watch"`
 				}{
 					Default: "/home/test",
-					Watch:   []string{},
+					Watch:   []config.WatchDir{},
 				},
 				WatchDirectories: []string{""},
 			},
 			wantErr: true,
 		},
+		{
+			name: "quota without a limit",
+			config: &config.Config{
+				Settings: config.Settings{
+					Collision: "rename",
+					Quotas:    []config.Quota{{Directory: "/archive", Action: "notify"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "quota workflow action without a workflow",
+			config: &config.Config{
+				Settings: config.Settings{
+					Collision: "rename",
+					Quotas:    []config.Quota{{Directory: "/archive", MaxSize: "50GB", Action: "workflow"}},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+
+	"sortd/internal/globs"
+	"sortd/pkg/types"
+)
+
+// GlobMigration records a glob rewritten for the current matcher
+type GlobMigration struct {
+	Field string // Where the glob lives, e.g. "organize.patterns[2].match"
+	Old   string
+	New   string
+}
+
+// MigrateGlobs rewrites globs written for the matcher older versions used
+// (see globs.Migrate) and returns what changed
+func (c *Config) MigrateGlobs() []GlobMigration {
+	var migrations []GlobMigration
+	migrate := func(field string, pattern *string) {
+		if migrated := globs.Migrate(*pattern); migrated != *pattern {
+			migrations = append(migrations, GlobMigration{Field: field, Old: *pattern, New: migrated})
+			*pattern = migrated
+		}
+	}
+
+	for i := range c.Organize.Patterns {
+		pattern := &c.Organize.Patterns[i]
+		if pattern.Type != types.RegexPattern {
+			migrate(fmt.Sprintf("organize.patterns[%d].match", i), &pattern.Match)
+		}
+		for j := range pattern.Exclude {
+			migrate(fmt.Sprintf("organize.patterns[%d].exclude[%d]", i, j), &pattern.Exclude[j])
+		}
+	}
+	for i := range c.Rules {
+		migrate(fmt.Sprintf("rules[%d].pattern", i), &c.Rules[i].Pattern)
+	}
+	for i := range c.WatchMode.Filters {
+		filter := &c.WatchMode.Filters[i]
+		for j := range filter.Include {
+			migrate(fmt.Sprintf("watch_mode.filters[%d].include[%d]", i, j), &filter.Include[j])
+		}
+		for j := range filter.Exclude {
+			migrate(fmt.Sprintf("watch_mode.filters[%d].exclude[%d]", i, j), &filter.Exclude[j])
+		}
+	}
+	for i := range c.Directories.Watch {
+		dir := &c.Directories.Watch[i]
+		for j := range dir.Include {
+			migrate(fmt.Sprintf("directories.watch[%d].include[%d]", i, j), &dir.Include[j])
+		}
+		for j := range dir.Exclude {
+			migrate(fmt.Sprintf("directories.watch[%d].exclude[%d]", i, j), &dir.Exclude[j])
+		}
+	}
+	for i := range c.WatchMode.Stability {
+		migrate(fmt.Sprintf("watch_mode.stability[%d].pattern", i), &c.WatchMode.Stability[i].Pattern)
+	}
+	return migrations
+}
+
+// GlobMigrations returns the globs rewritten when the config was loaded.
+// They are only saved to the file by the next Save.
+func (c *Config) GlobMigrations() []GlobMigration {
+	return c.globMigrations
+}
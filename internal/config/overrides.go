@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts the environment variables that override config keys:
+// settings.dry_run is SORTD_SETTINGS_DRY_RUN
+const EnvPrefix = "SORTD_"
+
+// timeType is handled as a single value rather than a struct
+var timeType = reflect.TypeOf(time.Time{})
+
+// watchDirsType is set from a list of paths, like the older []string form
+var watchDirsType = reflect.TypeOf([]WatchDir{})
+
+// Keys returns every config key that can be overridden, in dotted form such as
+// "settings.dry_run". Lists of strings and string maps are included; lists of
+// structs (patterns, workflows, filters) can only be set in the file.
+func Keys() []string {
+	var keys []string
+	walkKeys(reflect.TypeOf(Config{}), "", func(key string, _ []int) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// EnvName returns the environment variable that overrides a key
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Set overrides a single key with a value given as text. Booleans accept
+// true/false/1/0, durations "90s", times RFC 3339, lists are comma separated
+// and maps are "name=value" pairs separated by commas.
+func (c *Config) Set(key, value string) error {
+	var index []int
+	walkKeys(reflect.TypeOf(Config{}), "", func(k string, i []int) {
+		if k == key {
+			index = i
+		}
+	})
+	if index == nil {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+
+	field := reflect.ValueOf(c).Elem().FieldByIndex(index)
+	if err := setValue(field, value); err != nil {
+		return fmt.Errorf("config key %s: %w", key, err)
+	}
+	return nil
+}
+
+// ApplyEnv overrides config keys from SORTD_* environment variables, e.g.
+// SORTD_SETTINGS_DRY_RUN=false
+func (c *Config) ApplyEnv() error {
+	for _, key := range Keys() {
+		value, ok := os.LookupEnv(EnvName(key))
+		if !ok {
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			return fmt.Errorf("%s: %w", EnvName(key), err)
+		}
+	}
+	return nil
+}
+
+// walkKeys calls fn with the dotted yaml key and field index of every
+// settable leaf field of t
+func walkKeys(t reflect.Type, prefix string, fn func(key string, index []int)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		if tag == ",inline" && field.Type.Kind() == reflect.Struct {
+			// Inlined fields sit at the level of the struct holding them
+			walkKeys(field.Type, prefix, func(k string, index []int) {
+				fn(k, append([]int{i}, index...))
+			})
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch {
+		case field.Type.Kind() == reflect.Struct && field.Type != timeType:
+			walkKeys(field.Type, key, func(k string, index []int) {
+				fn(k, append([]int{i}, index...))
+			})
+		case isSettable(field.Type):
+			fn(key, []int{i})
+		}
+	}
+}
+
+// isSettable reports whether a value of the type can be parsed from text
+func isSettable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String || t == watchDirsType
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String
+	}
+	return t == timeType
+}
+
+// setValue parses text into a field
+func setValue(field reflect.Value, value string) error {
+	if field.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("%q is not an RFC 3339 time", value)
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		if field.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a duration", value)
+			}
+			field.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type() == watchDirsType {
+			var dirs []WatchDir
+			for _, path := range splitList(value) {
+				dirs = append(dirs, WatchDir{Path: path})
+			}
+			field.Set(reflect.ValueOf(dirs))
+			return nil
+		}
+		field.Set(reflect.ValueOf(splitList(value)))
+	case reflect.Map:
+		m := make(map[string]string)
+		for _, pair := range splitList(value) {
+			name, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("%q is not a name=value pair", pair)
+			}
+			m[strings.TrimSpace(name)] = strings.TrimSpace(v)
+		}
+		field.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("cannot be set from text")
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
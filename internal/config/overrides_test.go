@@ -0,0 +1,55 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet(t *testing.T) {
+	cfg := config.New()
+
+	require.NoError(t, cfg.Set("settings.dry_run", "false"))
+	require.NoError(t, cfg.Set("settings.max_depth", "3"))
+	require.NoError(t, cfg.Set("settings.auto_apply_confidence", "0.8"))
+	require.NoError(t, cfg.Set("settings.digest.email.to", "a@example.com, b@example.com"))
+	require.NoError(t, cfg.Set("settings.training.started", "2024-05-01T00:00:00Z"))
+	require.NoError(t, cfg.Set("bookmarks", "docs=~/Documents,dl=~/Downloads"))
+	require.NoError(t, cfg.Set("directories.watch", "/a,/b"))
+
+	assert.False(t, cfg.Settings.DryRun)
+	assert.Equal(t, 3, cfg.Settings.MaxDepth)
+	assert.Equal(t, 0.8, cfg.Settings.AutoApplyConfidence)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, cfg.Settings.Digest.Email.To)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), cfg.Settings.Training.Started)
+	assert.Equal(t, map[string]string{"docs": "~/Documents", "dl": "~/Downloads"}, cfg.Bookmarks)
+	assert.Equal(t, []config.WatchDir{{Path: "/a"}, {Path: "/b"}}, cfg.Directories.Watch, "watch entries can be given as paths")
+
+	assert.Error(t, cfg.Set("settings.dry_run", "maybe"))
+	assert.Error(t, cfg.Set("settings.nope", "1"))
+	assert.Error(t, cfg.Set("organize.patterns", "*.pdf"), "lists of structs are file-only")
+}
+
+func TestLoadConfigFile_EnvOverrides(t *testing.T) {
+	t.Setenv("SORTD_SETTINGS_COLLISION", "skip")
+	t.Setenv("SORTD_WATCH_DIRECTORIES", "/a,/b")
+
+	configFile := createTestYAML(t, watchFiltersYAML)
+	cfg, err := config.LoadConfigFile(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "skip", cfg.Settings.Collision)
+	assert.Equal(t, []string{"/a", "/b"}, cfg.WatchDirectories)
+
+	t.Setenv("SORTD_SETTINGS_COLLISION", "sideways")
+	_, err = config.LoadConfigFile(configFile)
+	assert.Error(t, err, "overrides are validated like the file")
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "SORTD_SETTINGS_DRY_RUN", config.EnvName("settings.dry_run"))
+	assert.Contains(t, config.Keys(), "settings.embeddings.endpoint")
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// appName names sortd's directory inside each base directory
+const appName = "sortd"
+
+// Environment variables that override where sortd keeps its files
+const (
+	// ConfigFileEnv names the config file to load and save
+	ConfigFileEnv = "SORTD_CONFIG"
+	// ConfigDirEnv overrides the directory holding config.yaml and workflows
+	ConfigDirEnv = "SORTD_CONFIG_DIR"
+	// DataDirEnv overrides the directory for data sortd creates
+	DataDirEnv = "SORTD_DATA_DIR"
+	// StateDirEnv overrides the directory for logs, history and other state
+	StateDirEnv = "SORTD_STATE_DIR"
+)
+
+// ConfigDir returns the directory holding config.yaml and the workflows. In
+// order: $SORTD_CONFIG_DIR, $XDG_CONFIG_HOME/sortd, an existing ~/.config/sortd
+// (where sortd has always looked), then the platform's config directory.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return dir, nil
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, appName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(home, ".config", appName)
+	if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+		return legacy, nil
+	}
+
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return legacy, nil
+	}
+	return filepath.Join(base, appName), nil
+}
+
+// ConfigPath returns the config file: $SORTD_CONFIG, or config.yaml in ConfigDir
+func ConfigPath() (string, error) {
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		return path, nil
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// WorkflowsDir returns the directory workflow definitions are loaded from
+func WorkflowsDir() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "workflows"), nil
+}
+
+// DataDir returns the directory for data sortd creates: $SORTD_DATA_DIR,
+// $XDG_DATA_HOME/sortd, or the platform equivalent (~/.local/share/sortd on Linux)
+func DataDir() (string, error) {
+	return baseDir(DataDirEnv, "XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// StateDir returns the directory for logs and history: $SORTD_STATE_DIR,
+// $XDG_STATE_HOME/sortd, or the platform equivalent (~/.local/state/sortd on Linux)
+func StateDir() (string, error) {
+	return baseDir(StateDirEnv, "XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// baseDir resolves a sortd directory from an override, an XDG variable, or
+// the platform default. macOS and Windows keep data and state together in the
+// user's application data directory.
+func baseDir(overrideEnv, xdgEnv, unixDefault string) (string, error) {
+	if dir := os.Getenv(overrideEnv); dir != "" {
+		return dir, nil
+	}
+	if xdg := os.Getenv(xdgEnv); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, appName), nil
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, "Library", "Application Support", appName), nil
+	case "windows":
+		if local := os.Getenv("LocalAppData"); local != "" {
+			return filepath.Join(local, appName), nil
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, unixDefault, appName), nil
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory. Paths
+// without one, and any path when the home directory is unknown, are kept.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// legacyStatePrefix starts the names state and data files had when sortd
+// kept them in the default directory, e.g. .sortd.stats.json
+const legacyStatePrefix = ".sortd."
+
+// StateFile returns the path of the state file with the given name, e.g.
+// "stats.json", in StateDir. A file sortd kept in the default directory
+// before, .sortd.stats.json for that name, is moved there the first time.
+// Without a state directory the file stays in the default directory.
+func (c *Config) StateFile(name string) string {
+	return c.baseFile(StateDir, name)
+}
+
+// DataFile returns the path of the data file with the given name, e.g.
+// "learning.json", in DataDir, moving an earlier file from the default
+// directory like StateFile does
+func (c *Config) DataFile(name string) string {
+	return c.baseFile(DataDir, name)
+}
+
+// StatePath returns the state directory, creating it, or the default
+// directory when there is none
+func (c *Config) StatePath() string {
+	dir, err := StateDir()
+	if err != nil || os.MkdirAll(dir, 0700) != nil {
+		return c.Directories.Default
+	}
+	return dir
+}
+
+// baseFile returns the path of a file in the directory base returns, creating
+// the directory and moving the file there from the default directory if it
+// is still only there. A file that can't be moved is used where it is.
+func (c *Config) baseFile(base func() (string, error), name string) string {
+	legacy := filepath.Join(c.Directories.Default, legacyStatePrefix+name)
+	dir, err := base()
+	if err != nil || os.MkdirAll(dir, 0700) != nil {
+		return legacy
+	}
+
+	path := filepath.Join(dir, name)
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		return path
+	}
+	if _, err := os.Lstat(legacy); err != nil {
+		return path
+	}
+	if err := os.Rename(legacy, path); err != nil {
+		return legacy
+	}
+	// A lock file kept next to it has nothing left to guard
+	os.Remove(legacy + ".lock")
+	return path
+}
@@ -0,0 +1,108 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.ConfigDirEnv, "")
+	t.Setenv(config.ConfigFileEnv, "")
+
+	t.Run("xdg", func(t *testing.T) {
+		xdg := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", xdg)
+		dir, err := config.ConfigDir()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(xdg, "sortd"), dir)
+	})
+
+	t.Run("legacy directory", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+		legacy := filepath.Join(home, ".config", "sortd")
+		require.NoError(t, os.MkdirAll(legacy, 0755))
+		dir, err := config.ConfigDir()
+		require.NoError(t, err)
+		assert.Equal(t, legacy, dir)
+
+		workflows, err := config.WorkflowsDir()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(legacy, "workflows"), workflows)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv(config.ConfigDirEnv, "/etc/sortd")
+		dir, err := config.ConfigDir()
+		require.NoError(t, err)
+		assert.Equal(t, "/etc/sortd", dir)
+
+		path, err := config.ConfigPath()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("/etc/sortd", "config.yaml"), path)
+
+		t.Setenv(config.ConfigFileEnv, "/srv/sortd.yaml")
+		path, err = config.ConfigPath()
+		require.NoError(t, err)
+		assert.Equal(t, "/srv/sortd.yaml", path)
+	})
+}
+
+func TestStateAndDataDirs(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	t.Setenv(config.StateDirEnv, "")
+	dir, err := config.StateDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/xdg/state", "sortd"), dir)
+
+	t.Setenv(config.DataDirEnv, "/data")
+	dir, err = config.DataDir()
+	require.NoError(t, err)
+	assert.Equal(t, "/data", dir)
+}
+
+func TestStateFileMigration(t *testing.T) {
+	stateDir, dataDir := t.TempDir(), t.TempDir()
+	t.Setenv(config.StateDirEnv, stateDir)
+	t.Setenv(config.DataDirEnv, dataDir)
+
+	cfg := config.New()
+	cfg.Directories.Default = t.TempDir()
+	legacy := filepath.Join(cfg.Directories.Default, ".sortd.pending.json")
+	require.NoError(t, os.WriteFile(legacy, []byte("[]"), 0644))
+	require.NoError(t, os.WriteFile(legacy+".lock", nil, 0644))
+
+	path := cfg.StateFile("pending.json")
+	assert.Equal(t, filepath.Join(stateDir, "pending.json"), path)
+	assert.FileExists(t, path, "The file kept in the default directory moves to the state directory")
+	assert.NoFileExists(t, legacy)
+	assert.NoFileExists(t, legacy+".lock")
+
+	// A file already in the state directory wins over a stale one left behind
+	require.NoError(t, os.WriteFile(legacy, []byte("stale"), 0644))
+	assert.Equal(t, path, cfg.StateFile("pending.json"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(data))
+
+	assert.Equal(t, filepath.Join(dataDir, "learning.json"), cfg.DataFile("learning.json"))
+	assert.DirExists(t, dataDir)
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	assert.Equal(t, home, config.ExpandHome("~"))
+	assert.Equal(t, filepath.Join(home, "Downloads"), config.ExpandHome("~/Downloads"))
+	assert.Equal(t, "~other/Downloads", config.ExpandHome("~other/Downloads"))
+	assert.Equal(t, "/srv/files", config.ExpandHome("/srv/files"))
+	assert.Equal(t, "relative", config.ExpandHome("relative"))
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Profile is a context sortd can be in, such as docked at the desk or out
+// with the laptop. Patterns, watch directories and workflows that name a
+// profile only apply while it is active, which it is when every condition it
+// sets holds; a profile without conditions is always active.
+//
+//	profiles:
+//	  - name: docked
+//	    min_displays: 2
+//	    mounted: [/mnt/nas]
+//	  - name: laptop
+//	    hostnames: ["thinkpad*"]
+//	    max_displays: 1
+type Profile struct {
+	Name        string   `yaml:"name"`
+	Hostnames   []string `yaml:"hostnames,omitempty"`    // Globs for the machine's hostname; one must match
+	MinDisplays int      `yaml:"min_displays,omitempty"` // At least this many displays connected
+	MaxDisplays int      `yaml:"max_displays,omitempty"` // At most this many displays connected
+	Mounted     []string `yaml:"mounted,omitempty"`      // Paths that must all be mount points, e.g. a NAS share
+	NotMounted  []string `yaml:"not_mounted,omitempty"`  // Paths that must not be mounted
+}
+
+// HasConditions reports whether the profile checks anything at all
+func (p Profile) HasConditions() bool {
+	return len(p.Hostnames) > 0 || p.MinDisplays > 0 || p.MaxDisplays > 0 ||
+		len(p.Mounted) > 0 || len(p.NotMounted) > 0
+}
+
+// validate checks the profile's name, hostname globs and display counts
+func (p Profile) validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	for _, hostname := range p.Hostnames {
+		if _, err := filepath.Match(hostname, ""); err != nil {
+			return fmt.Errorf("invalid hostname glob %q: %w", hostname, err)
+		}
+	}
+	if p.MinDisplays < 0 || p.MaxDisplays < 0 {
+		return fmt.Errorf("min_displays and max_displays cannot be negative")
+	}
+	if p.MaxDisplays > 0 && p.MinDisplays > p.MaxDisplays {
+		return fmt.Errorf("min_displays %d is more than max_displays %d", p.MinDisplays, p.MaxDisplays)
+	}
+	for _, path := range append(append([]string{}, p.Mounted...), p.NotMounted...) {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("mount paths cannot be empty")
+		}
+	}
+	return nil
+}
+
+// validateProfiles checks every profile and that no two share a name
+func (c *Config) validateProfiles() error {
+	seen := make(map[string]bool, len(c.Profiles))
+	for i, profile := range c.Profiles {
+		if err := profile.validate(); err != nil {
+			return fmt.Errorf("profile %d: %w", i, err)
+		}
+		if seen[profile.Name] {
+			return fmt.Errorf("profile %q is defined twice", profile.Name)
+		}
+		seen[profile.Name] = true
+	}
+	return nil
+}
+
+// ProfileNamed returns the profile with the given name
+func (c *Config) ProfileNamed(name string) (Profile, bool) {
+	for _, profile := range c.Profiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
@@ -0,0 +1,20 @@
+package config
+
+import "sort"
+
+// Rule sends files whose names match Pattern to Target
+type Rule struct {
+	Pattern  string `yaml:"pattern"`            // Pattern to match
+	Target   string `yaml:"target"`             // Target directory
+	Priority int    `yaml:"priority,omitempty"` // Higher priorities are tried first; equal ones keep their order
+}
+
+// OrderedRules returns the rules in the order they are tried: by priority,
+// highest first, and in file order among equal priorities
+func (c *Config) OrderedRules() []Rule {
+	rules := append([]Rule(nil), c.Rules...)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority > rules[j].Priority
+	})
+	return rules
+}
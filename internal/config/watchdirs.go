@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sortd/internal/globs"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WatchDir is a directory the watch daemon monitors, with settings of its own.
+// In YAML an entry is either a mapping or, as in older configs, just the path:
+//
+//	directories:
+//	  watch:
+//	    - ~/Desktop
+//	    - path: ~/Downloads
+//	      recursive: true
+//	      exclude: ["*.part"]
+type WatchDir struct {
+	Path            string   `yaml:"path"`
+	Recursive       bool     `yaml:"recursive,omitempty"`        // Also watch subdirectories, including ones created later
+	Include         []string `yaml:"include,omitempty"`          // Only react to files matching one of these globs
+	Exclude         []string `yaml:"exclude,omitempty"`          // Ignore files matching any of these globs
+	DebounceSeconds int      `yaml:"debounce_seconds,omitempty"` // Wait until a file has been unchanged this long (stability windows take precedence)
+	Backend         string   `yaml:"backend,omitempty"`          // "fsnotify" or "poll", overriding watch_mode.backend
+	Profile         string   `yaml:"profile,omitempty"`          // Name of the config profile the directory belongs to
+}
+
+// UnmarshalYAML reads an entry from either a plain path or a mapping
+func (w *WatchDir) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*w = WatchDir{}
+		return node.Decode(&w.Path)
+	}
+
+	// A distinct type keeps Decode from calling this method again
+	type plain WatchDir
+	return node.Decode((*plain)(w))
+}
+
+// MarshalYAML writes entries with nothing but a path in the short form, so
+// saving an older config leaves the list as it was
+func (w WatchDir) MarshalYAML() (interface{}, error) {
+	if w.isPlain() {
+		return w.Path, nil
+	}
+	type plain WatchDir
+	return plain(w), nil
+}
+
+// isPlain reports whether the entry sets nothing but its path
+func (w WatchDir) isPlain() bool {
+	return !w.Recursive && len(w.Include) == 0 && len(w.Exclude) == 0 &&
+		w.DebounceSeconds == 0 && w.Backend == "" && w.Profile == ""
+}
+
+// Contains reports whether the entry watches files in dir: its own path, or
+// any directory below it when it is recursive
+func (w WatchDir) Contains(dir string) bool {
+	root := filepath.Clean(w.Path)
+	dir = filepath.Clean(dir)
+	if dir == root {
+		return true
+	}
+	return w.Recursive && strings.HasPrefix(dir, root+string(filepath.Separator))
+}
+
+// validate checks the entry's path, globs and backend
+func (w WatchDir) validate() error {
+	if strings.TrimSpace(w.Path) == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	for _, pattern := range append(append([]string{}, w.Include...), w.Exclude...) {
+		if err := globs.Validate(pattern); err != nil {
+			return err
+		}
+	}
+	switch w.Backend {
+	case "", "fsnotify", "poll":
+	default:
+		return fmt.Errorf("invalid backend %q: must be fsnotify or poll", w.Backend)
+	}
+	if w.DebounceSeconds < 0 {
+		return fmt.Errorf("debounce_seconds cannot be negative")
+	}
+	return nil
+}
+
+// WatchDirs returns every directory to watch: the entries under
+// directories.watch, then those listed only under watch_directories. Entries
+// that don't set their own debounce or recursion get watch_mode's.
+func (c *Config) WatchDirs() []WatchDir {
+	dirs := append([]WatchDir(nil), c.Directories.Watch...)
+	seen := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		seen[filepath.Clean(dir.Path)] = true
+	}
+	for _, path := range c.WatchDirectories {
+		if !seen[filepath.Clean(path)] {
+			seen[filepath.Clean(path)] = true
+			dirs = append(dirs, WatchDir{Path: path})
+		}
+	}
+	for i := range dirs {
+		dirs[i].Recursive = dirs[i].Recursive || c.WatchMode.Recursive
+		if dirs[i].DebounceSeconds == 0 {
+			dirs[i].DebounceSeconds = c.WatchMode.DebounceSeconds
+		}
+	}
+	return dirs
+}
+
+// WatchPaths returns the path of every directory to watch
+func (c *Config) WatchPaths() []string {
+	dirs := c.WatchDirs()
+	paths := make([]string, len(dirs))
+	for i, dir := range dirs {
+		paths[i] = dir.Path
+	}
+	return paths
+}
+
+// WatchDirFor returns the entry watching files in dir, preferring the
+// deepest one when recursive entries overlap
+func (c *Config) WatchDirFor(dir string) (WatchDir, bool) {
+	var found WatchDir
+	ok := false
+	for _, entry := range c.WatchDirs() {
+		if entry.Contains(dir) && (!ok || len(filepath.Clean(entry.Path)) > len(filepath.Clean(found.Path))) {
+			found, ok = entry, true
+		}
+	}
+	return found, ok
+}
+
+// WatchFilters returns the configured watch filters together with those the
+// include and exclude lists of watch directory entries make up
+func (c *Config) WatchFilters() []WatchFilter {
+	filters := append([]WatchFilter(nil), c.WatchMode.Filters...)
+	for _, dir := range c.Directories.Watch {
+		if len(dir.Include) > 0 || len(dir.Exclude) > 0 {
+			filters = append(filters, WatchFilter{Directory: dir.Path, Include: dir.Include, Exclude: dir.Exclude})
+		}
+	}
+	return filters
+}
@@ -0,0 +1,198 @@
+// Package crash writes diagnostic bundles for bug reports: what went wrong,
+// the stack, the latest log entries and the configuration with its secrets
+// taken out. sortd writes one when it panics, and on request.
+package crash
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/logview"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IssuesURL is where bundles are meant to go
+const IssuesURL = "https://github.com/Jeff-Barlow-Spady/sortd/issues"
+
+// logEntries is how many of the latest log entries a bundle includes
+const logEntries = 200
+
+// redacted replaces secrets in bundles
+const redacted = "[redacted]"
+
+// Version is the sortd version bundles report; main sets it
+var Version = "dev"
+
+// secretKey matches config keys and flags whose values are secrets, or URLs
+// that may carry one
+var secretKey = regexp.MustCompile(`(?i)(password|token|secret|api_key|apikey|webhook|auth)`)
+
+// Bundle is what goes into a diagnostic bundle
+type Bundle struct {
+	Reason string         // What happened, e.g. the panic value
+	Where  string         // The command or daemon task that was running
+	Stack  []byte         // Stack of the panic; nil for bundles made on request
+	Config *config.Config // Written with secrets redacted
+}
+
+// DefaultDir returns the directory bundles are written to, in the state directory
+func DefaultDir() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "crashes"), nil
+}
+
+// Capture writes a bundle for a panic recovered in where, returning its path
+func Capture(where string, value interface{}, cfg *config.Config) (string, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return "", err
+	}
+	return Write(dir, Bundle{
+		Reason: fmt.Sprintf("panic: %v", value),
+		Where:  where,
+		Stack:  debug.Stack(),
+		Config: cfg,
+	})
+}
+
+// Instructions tells the user what crashed and what to do with the bundle at
+// path. Without one, err says why it couldn't be written.
+func Instructions(value interface{}, path string, err error) string {
+	if err != nil {
+		return fmt.Sprintf("sortd crashed: %v\nA diagnostic bundle couldn't be written (%v). Please report the crash at %s with the output above.",
+			value, err, IssuesURL)
+	}
+	return fmt.Sprintf("sortd crashed: %v\nA diagnostic bundle was written to %s\nPlease look it over and attach it to a bug report at %s",
+		value, path, IssuesURL)
+}
+
+// Write writes a bundle to a new file in dir and returns its path
+func Write(dir string, bundle Bundle) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "sortd diagnostic bundle\n\n")
+	fmt.Fprintf(&b, "Time:    %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version: %s\n", Version)
+	fmt.Fprintf(&b, "Go:      %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Command: %s\n", strings.Join(sanitizeArgs(os.Args), " "))
+	if bundle.Where != "" {
+		fmt.Fprintf(&b, "Where:   %s\n", bundle.Where)
+	}
+	fmt.Fprintf(&b, "Reason:  %s\n", bundle.Reason)
+
+	if len(bundle.Stack) > 0 {
+		fmt.Fprintf(&b, "\n== Stack ==\n%s\n", bundle.Stack)
+	}
+
+	b.WriteString("\n== Recent log ==\n")
+	b.WriteString(recentLog())
+
+	b.WriteString("\n== Configuration (secrets redacted) ==\n")
+	b.WriteString(sanitizedConfig(bundle.Config))
+
+	path := filepath.Join(dir, fmt.Sprintf("sortd-crash-%s.txt", time.Now().Format("20060102-150405.000")))
+	if err := os.WriteFile(path, b.Bytes(), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// recentLog returns the latest entries of the structured log
+func recentLog() string {
+	path, err := logview.DefaultPath()
+	if err != nil {
+		return fmt.Sprintf("(unavailable: %v)\n", err)
+	}
+	entries, err := logview.Read(path, logview.Filter{}, logEntries)
+	if err != nil {
+		return fmt.Sprintf("(unavailable: %v)\n", err)
+	}
+	if len(entries) == 0 {
+		return "(empty)\n"
+	}
+
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(entry.String() + "\n")
+	}
+	return b.String()
+}
+
+// sanitizedConfig returns cfg as YAML with secrets redacted and the home
+// directory shortened to ~
+func sanitizedConfig(cfg *config.Config) string {
+	if cfg == nil {
+		return "(not loaded)\n"
+	}
+	var node yaml.Node
+	data, err := yaml.Marshal(cfg)
+	if err == nil {
+		err = yaml.Unmarshal(data, &node)
+	}
+	if err != nil {
+		return fmt.Sprintf("(unavailable: %v)\n", err)
+	}
+
+	redactNode(&node)
+	data, err = yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Sprintf("(unavailable: %v)\n", err)
+	}
+	return shortenHome(string(data))
+}
+
+// redactNode blanks the values of secret keys throughout a YAML document
+func redactNode(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if secretKey.MatchString(key.Value) && value.Kind == yaml.ScalarNode && value.Value != "" {
+				value.Value = redacted
+				value.Tag = "!!str"
+				continue
+			}
+			redactNode(value)
+		}
+		return
+	}
+	for _, child := range node.Content {
+		redactNode(child)
+	}
+}
+
+// sanitizeArgs redacts secret values in a command line, such as
+// --set settings.digest.email.password=...
+func sanitizeArgs(args []string) []string {
+	sanitized := make([]string, len(args))
+	for i, arg := range args {
+		sanitized[i] = arg
+		if key, _, ok := strings.Cut(arg, "="); ok && secretKey.MatchString(key) {
+			sanitized[i] = key + "=" + redacted
+		}
+	}
+	return sanitized
+}
+
+// shortenHome replaces the home directory with ~, keeping user names out of bundles
+func shortenHome(text string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == "/" {
+		return text
+	}
+	return strings.ReplaceAll(text, home, "~")
+}
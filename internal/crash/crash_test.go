@@ -0,0 +1,42 @@
+package crash
+
+import (
+	"os"
+	"testing"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	t.Setenv(config.StateDirEnv, t.TempDir())
+
+	cfg := config.New()
+	cfg.Settings.Digest.Webhook = "https://example.com/hooks/abc123"
+	cfg.Settings.Digest.Email.Password = "hunter2"
+	cfg.Directories.Default = "/srv/sorted"
+
+	path, err := Capture("watch worker", "index out of range", cfg)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	bundle := string(data)
+
+	assert.Contains(t, bundle, "Where:   watch worker")
+	assert.Contains(t, bundle, "panic: index out of range")
+	assert.Contains(t, bundle, "== Stack ==")
+	assert.Contains(t, bundle, "/srv/sorted", "The configuration should be included")
+	assert.NotContains(t, bundle, "hunter2")
+	assert.NotContains(t, bundle, "abc123")
+	assert.Contains(t, bundle, redacted)
+
+	assert.Contains(t, Instructions("index out of range", path, nil), path)
+}
+
+func TestSanitizeArgs(t *testing.T) {
+	args := sanitizeArgs([]string{"sortd", "--set", "settings.digest.email.password=hunter2", "--set", "settings.dry_run=true"})
+	assert.Equal(t, "settings.digest.email.password="+redacted, args[2])
+	assert.Equal(t, "settings.dry_run=true", args[4])
+}
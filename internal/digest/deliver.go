@@ -0,0 +1,138 @@
+package digest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"sortd/internal/config"
+)
+
+// webhookTimeout bounds how long a webhook delivery may take
+const webhookTimeout = 10 * time.Second
+
+// Report is anything delivered through the digest channels: the activity
+// digest or the inbox report. Webhooks receive it encoded as JSON.
+type Report interface {
+	Subject() string
+	Text() string
+}
+
+// Deliver sends the report through every channel enabled in the settings.
+// All channels are attempted; the first error encountered is returned.
+func Deliver(settings config.DigestSettings, d Report) error {
+	var firstErr error
+	record := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if settings.Desktop {
+		record(sendDesktop(d.Subject(), d.Text()))
+	}
+	if settings.Webhook != "" {
+		record(sendWebhook(settings.Webhook, d))
+	}
+	if settings.Email.Host != "" {
+		record(sendEmail(settings.Email, d))
+	}
+
+	return firstErr
+}
+
+// Alert is an event that shouldn't wait for the next digest
+type Alert struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// Notify sends an alert right away through the digest's desktop and webhook
+// channels. Email is left for the digest itself.
+func Notify(settings config.DigestSettings, alert Alert) error {
+	var firstErr error
+	if settings.Desktop {
+		firstErr = sendDesktop(alert.Title, alert.Text)
+	}
+	if settings.Webhook != "" {
+		if err := postJSON(settings.Webhook, alert); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// sendDesktop shows a desktop notification
+func sendDesktop(title, text string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", text, title)
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		cmd = exec.Command("notify-send", title, text)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("desktop notification failed: %w", err)
+	}
+	return nil
+}
+
+// sendWebhook POSTs the report as JSON
+func sendWebhook(url string, d Report) error {
+	return postJSON(url, d)
+}
+
+// postJSON POSTs v to a webhook as JSON
+func postJSON(url string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode digest: %w", err)
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("digest webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("digest webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// sendEmail sends the report over SMTP, authenticating when a username is set
+func sendEmail(settings config.EmailSettings, d Report) error {
+	port := settings.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := fmt.Sprintf("%s:%d", settings.Host, port)
+
+	from := settings.From
+	if from == "" {
+		from = settings.Username
+	}
+
+	var auth smtp.Auth
+	if settings.Username != "" {
+		auth = smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
+		from, strings.Join(settings.To, ", "), d.Subject(), strings.ReplaceAll(d.Text(), "\n", "\r\n"))
+
+	if err := smtp.SendMail(addr, auth, from, settings.To, []byte(msg)); err != nil {
+		return fmt.Errorf("digest email failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Package digest summarises watch daemon activity over a period and delivers
+// the summary by desktop notification, email or webhook.
+package digest
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sortd/pkg/types"
+)
+
+// maxListed bounds how many destinations and failures a digest lists
+const maxListed = 5
+
+// DestinationCount is the number of files organized into one directory
+type DestinationCount struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// Failure describes a file that could not be organized
+type Failure struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Lingering is a file that has stayed in a landing zone past its deadline
+type Lingering struct {
+	Path      string        `json:"path"`
+	Zone      string        `json:"zone"`
+	Landed    time.Time     `json:"landed"` // When the file arrived, by its modification time
+	Age       time.Duration `json:"-"`
+	Unmatched bool          `json:"unmatched"` // No rule or workflow applies to it: a gap in the rules
+}
+
+// String describes the lingering file in a line
+func (l Lingering) String() string {
+	line := fmt.Sprintf("%s has been in %s for %s", filepath.Base(l.Path), l.Zone, l.Age.Truncate(time.Minute))
+	if l.Unmatched {
+		line += " (no rule matches it)"
+	}
+	return line
+}
+
+// Digest aggregates activity between Since and Until
+type Digest struct {
+	Since           time.Time          `json:"since"`
+	Until           time.Time          `json:"until"`
+	Organized       int                `json:"organized"`
+	Failed          int                `json:"failed"`
+	TopDestinations []DestinationCount `json:"top_destinations"`
+	Failures        []Failure          `json:"failures,omitempty"`
+	Lingering       []Lingering        `json:"lingering,omitempty"` // Files overdue in landing zones when the digest was built
+}
+
+// Period returns how far back a digest of the given frequency looks
+func Period(frequency string) time.Duration {
+	if frequency == "weekly" {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Build aggregates the activity entries recorded between since and until
+func Build(entries []types.ActivityEntry, since, until time.Time) Digest {
+	d := Digest{Since: since, Until: until}
+
+	destinations := make(map[string]int)
+	for _, entry := range entries {
+		if entry.Time.Before(since) || entry.Time.After(until) {
+			continue
+		}
+
+		if entry.Error != "" {
+			d.Failed++
+			if len(d.Failures) < maxListed {
+				d.Failures = append(d.Failures, Failure{Path: entry.Path, Error: entry.Error})
+			}
+			continue
+		}
+
+		d.Organized++
+		if entry.Destination != "" {
+			destinations[entry.Destination]++
+		}
+	}
+
+	for path, count := range destinations {
+		d.TopDestinations = append(d.TopDestinations, DestinationCount{Path: path, Count: count})
+	}
+	sort.Slice(d.TopDestinations, func(i, j int) bool {
+		if d.TopDestinations[i].Count != d.TopDestinations[j].Count {
+			return d.TopDestinations[i].Count > d.TopDestinations[j].Count
+		}
+		return d.TopDestinations[i].Path < d.TopDestinations[j].Path
+	})
+	if len(d.TopDestinations) > maxListed {
+		d.TopDestinations = d.TopDestinations[:maxListed]
+	}
+
+	return d
+}
+
+// Subject returns a one-line summary suitable for a notification title or email subject
+func (d Digest) Subject() string {
+	subject := fmt.Sprintf("sortd: %d files organized, %d failed", d.Organized, d.Failed)
+	if len(d.Lingering) > 0 {
+		subject += fmt.Sprintf(", %d overdue", len(d.Lingering))
+	}
+	return subject
+}
+
+// Text renders the digest as plain text
+func (d Digest) Text() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Activity from %s to %s\n\n", d.Since.Format("Mon Jan 2 15:04"), d.Until.Format("Mon Jan 2 15:04"))
+	fmt.Fprintf(&b, "Files organized: %d\n", d.Organized)
+	fmt.Fprintf(&b, "Failures:        %d\n", d.Failed)
+
+	if len(d.TopDestinations) > 0 {
+		b.WriteString("\nTop destinations:\n")
+		for _, dest := range d.TopDestinations {
+			fmt.Fprintf(&b, "  %4d  %s\n", dest.Count, dest.Path)
+		}
+	}
+
+	if len(d.Failures) > 0 {
+		b.WriteString("\nRecent failures:\n")
+		for _, failure := range d.Failures {
+			fmt.Fprintf(&b, "  %s: %s\n", filepath.Base(failure.Path), failure.Error)
+		}
+	}
+
+	if len(d.Lingering) > 0 {
+		b.WriteString("\nOverdue in landing zones:\n")
+		for _, lingering := range d.Lingering {
+			fmt.Fprintf(&b, "  %s\n", lingering)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package digest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/digest"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	until := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	since := until.Add(-digest.Period("daily"))
+
+	entries := []types.ActivityEntry{
+		{Time: since.Add(-time.Hour), Path: "/dl/old.pdf", Destination: "/docs"}, // before the period
+		{Time: since.Add(time.Hour), Path: "/dl/a.pdf", Destination: "/docs"},
+		{Time: since.Add(2 * time.Hour), Path: "/dl/b.pdf", Destination: "/docs"},
+		{Time: since.Add(3 * time.Hour), Path: "/dl/c.jpg", Destination: "/pics"},
+		{Time: since.Add(4 * time.Hour), Path: "/dl/d.zip", Source: "workflow"},
+		{Time: since.Add(5 * time.Hour), Path: "/dl/e.iso", Destination: "/isos", Error: "disk full"},
+	}
+
+	d := digest.Build(entries, since, until)
+
+	assert.Equal(t, 4, d.Organized)
+	assert.Equal(t, 1, d.Failed)
+	assert.Equal(t, []digest.DestinationCount{{Path: "/docs", Count: 2}, {Path: "/pics", Count: 1}}, d.TopDestinations)
+	assert.Equal(t, []digest.Failure{{Path: "/dl/e.iso", Error: "disk full"}}, d.Failures)
+	assert.Contains(t, d.Text(), "Files organized: 4")
+	assert.Equal(t, "sortd: 4 files organized, 1 failed", d.Subject())
+}
+
+func TestLingering(t *testing.T) {
+	d := digest.Digest{
+		Organized: 2,
+		Lingering: []digest.Lingering{
+			{Path: "/scans/a.pdf", Zone: "/scans", Age: 30 * time.Hour, Unmatched: true},
+		},
+	}
+	assert.Equal(t, "sortd: 2 files organized, 0 failed, 1 overdue", d.Subject())
+	assert.Contains(t, d.Text(), "Overdue in landing zones:")
+	assert.Contains(t, d.Text(), "no rule matches it")
+}
+
+func TestDeliverWebhook(t *testing.T) {
+	var received digest.Digest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	d := digest.Digest{Organized: 3, Failed: 1}
+	require.NoError(t, digest.Deliver(config.DigestSettings{Webhook: server.URL}, d))
+	assert.Equal(t, 3, received.Organized)
+	assert.Equal(t, 1, received.Failed)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	assert.Error(t, digest.Deliver(config.DigestSettings{Webhook: failing.URL}, d))
+}
+
+func TestCountInboxes(t *testing.T) {
+	downloads := t.TempDir()
+	scans := t.TempDir()
+	for _, name := range []string{"a.pdf", "b.zip", ".DS_Store"} {
+		require.NoError(t, os.WriteFile(filepath.Join(downloads, name), nil, 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(downloads, "Sorted"), 0755))
+	missing := filepath.Join(scans, "gone")
+
+	now := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	report := digest.CountInboxes([]string{scans, downloads, missing}, map[string]int{downloads: 5}, now)
+
+	require.Len(t, report.Inboxes, 3)
+	assert.Equal(t, digest.InboxCount{Directory: downloads, Files: 2, Previous: 5}, report.Inboxes[0])
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, downloads, report.Fullest())
+	assert.NotEmpty(t, report.Inboxes[2].Error)
+	assert.Equal(t, map[string]int{downloads: 2, scans: 0}, report.Counts())
+	assert.Equal(t, "sortd: 2 files waiting in "+filepath.Base(downloads), report.Subject())
+	assert.Contains(t, report.Text(), "(-3 since last time)")
+	assert.Contains(t, report.Text(), "sortd triage "+downloads)
+
+	empty := digest.CountInboxes([]string{scans}, nil, now)
+	assert.Equal(t, "", empty.Fullest())
+	assert.Equal(t, "sortd: inbox zero 🎉", empty.Subject())
+}
@@ -0,0 +1,130 @@
+package digest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InboxCount is how many files are waiting in one inbox directory
+type InboxCount struct {
+	Directory string `json:"directory"`
+	Files     int    `json:"files"`
+	Previous  int    `json:"previous"` // Files at the last report; -1 when there was none
+	Error     string `json:"error,omitempty"`
+}
+
+// InboxReport counts the files left in the inbox directories, for the
+// inbox zero nudge
+type InboxReport struct {
+	Time    time.Time    `json:"time"`
+	Inboxes []InboxCount `json:"inboxes"`
+	Total   int          `json:"total"`
+}
+
+// CountInboxes counts the files at the top level of each directory, ignoring
+// subfolders and hidden files. previous holds the counts from the last
+// report, keyed by directory, and may be nil.
+func CountInboxes(dirs []string, previous map[string]int, now time.Time) InboxReport {
+	report := InboxReport{Time: now}
+
+	for _, dir := range dirs {
+		count := InboxCount{Directory: dir, Previous: -1}
+		if n, ok := previous[dir]; ok {
+			count.Previous = n
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			count.Error = err.Error()
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			count.Files++
+		}
+
+		report.Total += count.Files
+		report.Inboxes = append(report.Inboxes, count)
+	}
+
+	// Fullest first: that's where triage should start
+	sort.SliceStable(report.Inboxes, func(i, j int) bool {
+		return report.Inboxes[i].Files > report.Inboxes[j].Files
+	})
+
+	return report
+}
+
+// Counts returns the file count of each inbox, to compare against next time
+func (r InboxReport) Counts() map[string]int {
+	counts := make(map[string]int, len(r.Inboxes))
+	for _, inbox := range r.Inboxes {
+		if inbox.Error == "" {
+			counts[inbox.Directory] = inbox.Files
+		}
+	}
+	return counts
+}
+
+// Fullest returns the inbox with the most files, or "" when all are empty
+func (r InboxReport) Fullest() string {
+	if len(r.Inboxes) == 0 || r.Inboxes[0].Files == 0 {
+		return ""
+	}
+	return r.Inboxes[0].Directory
+}
+
+// Subject returns a one-line summary suitable for a notification title or email subject
+func (r InboxReport) Subject() string {
+	if r.Total == 0 {
+		return "sortd: inbox zero 🎉"
+	}
+	inboxes := 0
+	for _, inbox := range r.Inboxes {
+		if inbox.Files > 0 {
+			inboxes++
+		}
+	}
+	if inboxes == 1 {
+		return fmt.Sprintf("sortd: %d files waiting in %s", r.Total, filepath.Base(r.Fullest()))
+	}
+	return fmt.Sprintf("sortd: %d files waiting in %d inboxes", r.Total, inboxes)
+}
+
+// Text renders the report as plain text, ending with the command that opens
+// triage on the fullest inbox
+func (r InboxReport) Text() string {
+	var b strings.Builder
+
+	for _, inbox := range r.Inboxes {
+		switch {
+		case inbox.Error != "":
+			fmt.Fprintf(&b, "     ?  %s (%s)\n", inbox.Directory, inbox.Error)
+		case inbox.Previous >= 0 && inbox.Previous != inbox.Files:
+			fmt.Fprintf(&b, "  %4d  %s (%+d since last time)\n", inbox.Files, inbox.Directory, inbox.Files-inbox.Previous)
+		default:
+			fmt.Fprintf(&b, "  %4d  %s\n", inbox.Files, inbox.Directory)
+		}
+	}
+
+	if fullest := r.Fullest(); fullest != "" {
+		fmt.Fprintf(&b, "\nClear it out: sortd inbox --triage   (or sortd triage %s)\n", quoteArg(fullest))
+	} else {
+		b.WriteString("\nEvery inbox is empty. Nice work.\n")
+	}
+
+	return b.String()
+}
+
+// quoteArg quotes a path for a shell command line when it needs it
+func quoteArg(path string) string {
+	if strings.ContainsAny(path, " \t'\"$&;|<>()*?") {
+		return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,105 @@
+package embeddings_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"sortd/internal/embeddings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// topics are the dimensions of the fake model: each counts mentions of a word
+var topics = []string{"invoice", "recipe", "holiday"}
+
+// fakeEmbeddingServer answers /embeddings like an OpenAI-compatible API and
+// counts how many texts it has embedded
+func fakeEmbeddingServer(t *testing.T, embedded *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		atomic.AddInt32(embedded, int32(len(req.Input)))
+
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var data []item
+		for i, text := range req.Input {
+			vector := make([]float32, len(topics))
+			for j, topic := range topics {
+				vector[j] = float32(strings.Count(strings.ToLower(text), topic))
+			}
+			data = append(data, item{Index: i, Embedding: vector})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+}
+
+func TestFindSimilar(t *testing.T) {
+	var embedded int32
+	server := fakeEmbeddingServer(t, &embedded)
+	defer server.Close()
+
+	dir := t.TempDir()
+	docs := filepath.Join(dir, "docs")
+	require.NoError(t, os.Mkdir(docs, 0755))
+	files := map[string]string{
+		"a.txt":     "Invoice 1001: amount due. Please pay this invoice.",
+		"b.txt":     "Invoice 1002 for consulting; invoice total attached.",
+		"soup.md":   "A recipe for soup. This recipe serves four.",
+		"trip.txt":  "Holiday plans: flights and hotels for the holiday.",
+		"photo.png": "\x89PNG\r\n\x1a\nbinary",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(docs, name), []byte(content), 0644))
+	}
+
+	index, err := embeddings.OpenIndex(filepath.Join(dir, "index.db"))
+	require.NoError(t, err)
+	defer index.Close()
+
+	provider := embeddings.NewHTTPProvider(server.URL+"/v1/", "fake-model", "secret")
+	indexer := embeddings.NewIndexer(provider, index)
+
+	result, err := indexer.IndexDirectory(context.Background(), docs)
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Embedded)
+	assert.Equal(t, 1, result.Skipped, "Binary files are not embedded")
+
+	matches, err := indexer.FindSimilar(context.Background(), filepath.Join(docs, "a.txt"), 2)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, filepath.Join(docs, "b.txt"), matches[0].Path, "The other invoice should be closest")
+	assert.InDelta(t, 1.0, matches[0].Score, 0.001)
+	assert.Less(t, matches[1].Score, 0.5)
+
+	// Unchanged files are not sent to the model again
+	before := atomic.LoadInt32(&embedded)
+	result, err = indexer.IndexDirectory(context.Background(), docs)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Embedded)
+	assert.Equal(t, 4, result.Unchanged)
+	assert.Equal(t, before, atomic.LoadInt32(&embedded))
+}
+
+func TestCosine(t *testing.T) {
+	assert.InDelta(t, 1.0, embeddings.Cosine([]float32{1, 2}, []float32{2, 4}), 0.0001)
+	assert.InDelta(t, 0.0, embeddings.Cosine([]float32{1, 0}, []float32{0, 1}), 0.0001)
+	assert.Equal(t, 0.0, embeddings.Cosine([]float32{1}, []float32{1, 2}), "Mismatched lengths never match")
+	assert.Equal(t, 0.0, embeddings.Cosine([]float32{0, 0}, []float32{1, 2}))
+}
@@ -0,0 +1,190 @@
+package embeddings
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver
+
+	"sortd/internal/config"
+)
+
+// indexFile is the database, in the data directory, embeddings are kept in
+const indexFile = "embeddings.db"
+
+// schema creates the embeddings table. A file has one vector per model.
+const schema = `
+CREATE TABLE IF NOT EXISTS embeddings (
+	path     TEXT NOT NULL,
+	model    TEXT NOT NULL,
+	size     INTEGER NOT NULL,
+	mod_time INTEGER NOT NULL,
+	vector   BLOB NOT NULL,
+	PRIMARY KEY (path, model)
+)`
+
+// Match is a file similar to a query, with its cosine similarity (1 is identical)
+type Match struct {
+	Path  string  `json:"path"`
+	Score float64 `json:"score"`
+}
+
+// Index stores embeddings in SQLite
+type Index struct {
+	db *sql.DB
+}
+
+// DefaultIndexPath returns the path of the index
+func DefaultIndexPath(cfg *config.Config) string {
+	return cfg.DataFile(indexFile)
+}
+
+// OpenIndex opens or creates the index database at path
+func OpenIndex(path string) (*Index, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedding index: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise embedding index: %w", err)
+	}
+	return &Index{db: db}, nil
+}
+
+// Close closes the database
+func (i *Index) Close() error {
+	return i.db.Close()
+}
+
+// Put stores the vector of a file, replacing any earlier one for the model
+func (i *Index) Put(path, model string, size int64, modTime time.Time, vector []float32) error {
+	_, err := i.db.Exec(
+		`INSERT OR REPLACE INTO embeddings (path, model, size, mod_time, vector) VALUES (?, ?, ?, ?, ?)`,
+		path, model, size, modTime.UnixNano(), encodeVector(vector))
+	if err != nil {
+		return fmt.Errorf("failed to store embedding for %s: %w", path, err)
+	}
+	return nil
+}
+
+// Get returns the stored vector of a file, if it is still current: the file
+// must have the same size and modification time as when it was embedded
+func (i *Index) Get(path, model string, size int64, modTime time.Time) ([]float32, bool, error) {
+	var blob []byte
+	err := i.db.QueryRow(
+		`SELECT vector FROM embeddings WHERE path = ? AND model = ? AND size = ? AND mod_time = ?`,
+		path, model, size, modTime.UnixNano()).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read embedding for %s: %w", path, err)
+	}
+	return decodeVector(blob), true, nil
+}
+
+// Remove forgets a file
+func (i *Index) Remove(path string) error {
+	if _, err := i.db.Exec(`DELETE FROM embeddings WHERE path = ?`, path); err != nil {
+		return fmt.Errorf("failed to remove embedding for %s: %w", path, err)
+	}
+	return nil
+}
+
+// Paths returns every indexed path for the model
+func (i *Index) Paths(model string) ([]string, error) {
+	rows, err := i.db.Query(`SELECT path FROM embeddings WHERE model = ? ORDER BY path`, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// Similar returns the files whose vectors are closest to the query, best first.
+// The query file itself, if indexed, is excluded by passing its path.
+func (i *Index) Similar(model string, query []float32, exclude string, limit int) ([]Match, error) {
+	rows, err := i.db.Query(`SELECT path, vector FROM embeddings WHERE model = ?`, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []Match
+	for rows.Next() {
+		var path string
+		var blob []byte
+		if err := rows.Scan(&path, &blob); err != nil {
+			return nil, err
+		}
+		if path == exclude {
+			continue
+		}
+		matches = append(matches, Match{Path: path, Score: Cosine(query, decodeVector(blob))})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].Path < matches[b].Path
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 if they differ in
+// length or either is zero
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// encodeVector packs a vector as little-endian float32s
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// decodeVector unpacks a vector written by encodeVector
+func decodeVector(buf []byte) []float32 {
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vector
+}
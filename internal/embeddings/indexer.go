@@ -0,0 +1,192 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on what is sent to the model
+const (
+	maxSignatureBytes = 8 * 1024
+	batchSize         = 16
+)
+
+// textExtensions are embedded even when content sniffing can't tell they are text
+var textExtensions = map[string]bool{
+	".txt": true, ".md": true, ".csv": true, ".json": true, ".yaml": true, ".yml": true,
+	".xml": true, ".html": true, ".htm": true, ".log": true, ".rst": true, ".tex": true,
+}
+
+// Signature returns the text that represents a file to the model: its name
+// followed by the start of its contents. ok is false for files that aren't text.
+func Signature(path string) (string, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, maxSignatureBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", false, err
+	}
+	buf = trimPartialRune(buf[:n])
+	if len(buf) == 0 {
+		return "", false, nil
+	}
+
+	isText := strings.HasPrefix(http.DetectContentType(buf), "text/") ||
+		textExtensions[strings.ToLower(filepath.Ext(path))]
+	if !isText || !utf8.Valid(buf) {
+		return "", false, nil
+	}
+
+	return filepath.Base(path) + "\n\n" + string(buf), true, nil
+}
+
+// trimPartialRune drops a UTF-8 sequence cut off by the read limit
+func trimPartialRune(buf []byte) []byte {
+	for i := 0; i < utf8.UTFMax && len(buf) > 0; i++ {
+		if r, size := utf8.DecodeLastRune(buf); r != utf8.RuneError || size != 1 {
+			break
+		}
+		buf = buf[:len(buf)-1]
+	}
+	return buf
+}
+
+// Indexer keeps the index up to date with the files it is given
+type Indexer struct {
+	provider Provider
+	index    *Index
+}
+
+// NewIndexer creates an indexer that embeds with provider and stores into index
+func NewIndexer(provider Provider, index *Index) *Indexer {
+	return &Indexer{provider: provider, index: index}
+}
+
+// IndexResult counts what an indexing run did
+type IndexResult struct {
+	Embedded  int
+	Unchanged int
+	Skipped   int // Not text
+}
+
+// pendingFile is a file waiting in a batch to be embedded
+type pendingFile struct {
+	path string
+	info os.FileInfo
+	text string
+}
+
+// IndexFiles embeds every text file that is new or changed since it was last embedded
+func (x *Indexer) IndexFiles(ctx context.Context, paths []string) (IndexResult, error) {
+	var result IndexResult
+	var batch []pendingFile
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		texts := make([]string, len(batch))
+		for i, file := range batch {
+			texts[i] = file.text
+		}
+		vectors, err := x.provider.Embed(ctx, texts)
+		if err != nil {
+			return err
+		}
+		for i, file := range batch {
+			if err := x.index.Put(file.path, x.provider.Model(), file.info.Size(), file.info.ModTime(), vectors[i]); err != nil {
+				return err
+			}
+			result.Embedded++
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for _, path := range paths {
+		// Index by absolute path so lookups don't depend on the working directory
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			result.Skipped++
+			continue
+		}
+
+		if _, current, err := x.index.Get(path, x.provider.Model(), info.Size(), info.ModTime()); err != nil {
+			return result, err
+		} else if current {
+			result.Unchanged++
+			continue
+		}
+
+		text, ok, err := Signature(path)
+		if err != nil || !ok {
+			result.Skipped++
+			continue
+		}
+
+		batch = append(batch, pendingFile{path: path, info: info, text: text})
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, flush()
+}
+
+// IndexDirectory indexes the regular, non-hidden files directly inside dir
+func (x *Indexer) IndexDirectory(ctx context.Context, dir string) (IndexResult, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return IndexResult{}, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	return x.IndexFiles(ctx, paths)
+}
+
+// FindSimilar embeds the file if needed and returns the indexed files most like it
+func (x *Indexer) FindSimilar(ctx context.Context, path string, limit int) ([]Match, error) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if _, err := x.IndexFiles(ctx, []string{path}); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	vector, ok, err := x.index.Get(path, x.provider.Model(), info.Size(), info.ModTime())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s has no text content to compare", path)
+	}
+
+	return x.index.Similar(x.provider.Model(), vector, path, limit)
+}
@@ -0,0 +1,128 @@
+// Package embeddings turns file contents into vectors with an embedding model
+// and keeps them in a SQLite index, so that files can be found by meaning
+// rather than by shared words.
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"sortd/internal/config"
+)
+
+// Provider generates embeddings for text
+type Provider interface {
+	// Model identifies the model; vectors from different models are never compared
+	Model() string
+	// Embed returns one vector per input text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// requestTimeout bounds a single embedding request
+const requestTimeout = 60 * time.Second
+
+// HTTPProvider calls an OpenAI-compatible /embeddings endpoint. Hosted APIs and
+// local model servers (Ollama, llama.cpp, LM Studio) all speak this protocol.
+type HTTPProvider struct {
+	endpoint string
+	model    string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewHTTPProvider creates a provider for the endpoint, e.g. "http://localhost:11434/v1"
+func NewHTTPProvider(endpoint, model, apiKey string) *HTTPProvider {
+	return &HTTPProvider{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		model:    model,
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// NewProvider creates the provider configured in the settings
+func NewProvider(settings config.EmbeddingSettings) (Provider, error) {
+	if !settings.Enabled {
+		return nil, fmt.Errorf("embeddings are disabled; set settings.embeddings.enabled")
+	}
+	if settings.Endpoint == "" || settings.Model == "" {
+		return nil, fmt.Errorf("embeddings need an endpoint and a model")
+	}
+
+	apiKey := settings.APIKey
+	if settings.APIKeyEnv != "" {
+		apiKey = os.Getenv(settings.APIKeyEnv)
+	}
+	return NewHTTPProvider(settings.Endpoint, settings.Model, apiKey), nil
+}
+
+// Model returns the configured model name
+func (p *HTTPProvider) Model() string {
+	return p.model
+}
+
+// embeddingRequest is the body of an /embeddings call
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// embeddingResponse is the part of the /embeddings reply we use
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed sends the texts in one request
+func (p *HTTPProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(embeddingRequest{Model: p.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embedding request returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var parsed embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse embedding response: %w", err)
+	}
+	if len(parsed.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding response has %d vectors for %d inputs", len(parsed.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range parsed.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding response has out-of-range index %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,157 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// kindNames are the stable names of error kinds in machine-readable output
+var kindNames = map[ErrorKind]string{
+	Unknown:             "unknown",
+	FileNotFound:        "file_not_found",
+	FileAccessDenied:    "file_access_denied",
+	InvalidPath:         "invalid_path",
+	FileCreateFailed:    "file_create_failed",
+	FileOperationFailed: "file_operation_failed",
+	InvalidOperation:    "invalid_operation",
+	InvalidConfig:       "invalid_config",
+	ConfigNotFound:      "config_not_found",
+	ConfigNotSet:        "config_not_set",
+	InvalidRule:         "invalid_rule",
+	RuleNotFound:        "rule_not_found",
+	PermissionDenied:    "permission_denied",
+	ReadOnlyMode:        "read_only",
+	QuotaExceeded:       "quota_exceeded",
+}
+
+// Name returns the kind's stable name, e.g. "permission_denied"
+func (k ErrorKind) Name() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind_%d", int(k))
+}
+
+// Details describes an error for people and scripts: what failed, on which
+// file, rule or setting, the likely cause and how to fix it
+type Details struct {
+	Kind       string `json:"kind"`
+	Error      string `json:"error"`           // The full error message
+	Message    string `json:"message"`         // What failed, e.g. "failed to move file"
+	Path       string `json:"path,omitempty"`  // The file involved
+	Param      string `json:"param,omitempty"` // The setting involved
+	Rule       string `json:"rule,omitempty"`  // The rule involved
+	Cause      string `json:"cause,omitempty"` // The underlying error, e.g. "permission denied"
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Describe breaks err down into Details. Errors that aren't sortd's own are
+// classified by the operating system error they wrap, if any.
+func Describe(err error) Details {
+	if err == nil {
+		return Details{}
+	}
+
+	d := Details{Kind: KindOf(err).Name(), Error: err.Error(), Path: pathOf(err), Suggestion: Suggestion(err)}
+	for e := err; e != nil; e = Unwrap(e) {
+		if m, ok := e.(interface{ Message() string }); ok && d.Message == "" {
+			d.Message = m.Message()
+		}
+		if p, ok := e.(interface{ Param() string }); ok && d.Param == "" {
+			d.Param = p.Param()
+		}
+		if r, ok := e.(interface{ RuleName() string }); ok && d.Rule == "" {
+			d.Rule = r.RuleName()
+		}
+		// The innermost error is the one that explains the failure
+		if _, ours := e.(interface{ Kind() ErrorKind }); Unwrap(e) == nil && e != err && !ours {
+			d.Cause = e.Error()
+		}
+	}
+	if d.Message == "" {
+		d.Message = d.Error
+	}
+	return d
+}
+
+// pathOf returns the file an error is about, from sortd's file errors or the
+// operating system's path errors
+func pathOf(err error) string {
+	for e := err; e != nil; e = Unwrap(e) {
+		if p, ok := e.(interface{ Path() string }); ok && p.Path() != "" {
+			return p.Path()
+		}
+	}
+
+	var pathErr *os.PathError
+	if As(err, &pathErr) {
+		return pathErr.Path
+	}
+	var linkErr *os.LinkError
+	if As(err, &linkErr) {
+		return linkErr.Old
+	}
+	return ""
+}
+
+// KindOf returns the kind of the outermost sortd error in err's chain that has
+// one, or the kind matching the operating system error it wraps
+func KindOf(err error) ErrorKind {
+	for e := err; e != nil; e = Unwrap(e) {
+		if k, ok := e.(interface{ Kind() ErrorKind }); ok && k.Kind() != Unknown {
+			return k.Kind()
+		}
+	}
+
+	switch {
+	case err == nil:
+		return Unknown
+	case Is(err, os.ErrPermission):
+		return PermissionDenied
+	case Is(err, os.ErrNotExist):
+		return FileNotFound
+	}
+	return Unknown
+}
+
+// Suggestion returns a hint on how to fix err, or "" when there is none
+func Suggestion(err error) string {
+	if err == nil {
+		return ""
+	}
+	path := pathOf(err)
+
+	// Conditions the operating system reports apply whatever sortd made of them
+	switch {
+	case Is(err, syscall.ENOSPC):
+		return "The disk is full. Free up some space and try again."
+	case Is(err, syscall.ENAMETOOLONG):
+		return "The destination path is too long for the file system; use a shorter target folder or file name."
+	case Is(err, syscall.EROFS):
+		return fmt.Sprintf("%s is on a read-only file system; remount it writable or pick another target.", path)
+	}
+
+	switch KindOf(err) {
+	case PermissionDenied:
+		// EPERM is refused regardless of file modes, e.g. for immutable files
+		// or protected folders; EACCES is about the modes themselves
+		if Is(err, syscall.EPERM) {
+			return fmt.Sprintf("The system doesn't allow changing %s. It may be immutable (see chattr or chflags) or in a protected folder; on macOS, give sortd Full Disk Access.", path)
+		}
+		return fmt.Sprintf("Check who owns %s and its folder (ls -ld), or run sortd as a user that can write to both.", path)
+	case ReadOnlyMode:
+		return "Run 'sortd daemon resume' to allow changes again."
+	case QuotaExceeded:
+		return fmt.Sprintf("%s is over its quota; archive or delete files there, or raise the limit in settings.quotas ('sortd quotas' shows usage).", path)
+	case FileNotFound:
+		return "The file may have been moved, renamed or deleted since sortd looked for it; check the path and try again."
+	case InvalidConfig:
+		return "Fix the setting in your config file ('sortd config path' shows where it is)."
+	case ConfigNotFound, ConfigNotSet:
+		return "Run 'sortd setup' to create a configuration."
+	case InvalidRule, RuleNotFound:
+		return "Run 'sortd rules list' to see the rules that are configured."
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	// A failed move, wrapped on its way up to the command
+	osErr := &os.LinkError{Op: "rename", Old: "/inbox/a.pdf", New: "/docs/a.pdf", Err: syscall.EACCES}
+	err := fmt.Errorf("error organizing files: %w", NewOSFileError("failed to move file", "/inbox/a.pdf", FileOperationFailed, osErr))
+
+	d := Describe(err)
+	assert.Equal(t, "permission_denied", d.Kind)
+	assert.Equal(t, err.Error(), d.Error)
+	assert.Equal(t, "failed to move file", d.Message)
+	assert.Equal(t, "/inbox/a.pdf", d.Path)
+	assert.Equal(t, "permission denied", d.Cause)
+	assert.NotEmpty(t, d.Suggestion)
+
+	// Records are stable for scripts
+	data, jsonErr := json.Marshal(d)
+	require.NoError(t, jsonErr)
+	assert.Contains(t, string(data), `"kind":"permission_denied"`)
+	assert.Contains(t, string(data), `"path":"/inbox/a.pdf"`)
+
+	// Config and rule errors name their setting or rule
+	d = Describe(NewConfigError("invalid value", "settings.collision", InvalidConfig, nil))
+	assert.Equal(t, "invalid_config", d.Kind)
+	assert.Equal(t, "settings.collision", d.Param)
+	assert.Empty(t, d.Cause)
+	assert.Contains(t, d.Suggestion, "sortd config path")
+
+	d = Describe(Wrap(NewRuleError("rule not found", "pdfs", RuleNotFound, nil), "failed to apply rule"))
+	assert.Equal(t, "rule_not_found", d.Kind)
+	assert.Equal(t, "failed to apply rule", d.Message)
+	assert.Equal(t, "pdfs", d.Rule)
+
+	// Errors from elsewhere are classified by the OS error they carry
+	_, statErr := os.Stat("/does/not/exist")
+	d = Describe(fmt.Errorf("error accessing path: %w", statErr))
+	assert.Equal(t, "file_not_found", d.Kind)
+	assert.Equal(t, "/does/not/exist", d.Path)
+	assert.Equal(t, "no such file or directory", d.Cause)
+
+	d = Describe(fmt.Errorf("plain"))
+	assert.Equal(t, "unknown", d.Kind)
+	assert.Equal(t, "plain", d.Message)
+	assert.Empty(t, d.Suggestion)
+
+	assert.Equal(t, Details{}, Describe(nil))
+}
+
+func TestSuggestion_SystemConditions(t *testing.T) {
+	err := NewOSFileError("failed to move file", "/inbox/a.pdf", FileOperationFailed, &os.PathError{Op: "write", Path: "/inbox/a.pdf", Err: syscall.ENOSPC})
+	assert.Contains(t, Suggestion(err), "disk is full")
+	assert.Equal(t, "file_operation_failed", Describe(err).Kind)
+}
+
+func TestIsTransient(t *testing.T) {
+	busy := NewOSFileError("failed to move file", "/inbox/a.pdf", FileOperationFailed,
+		&os.LinkError{Op: "rename", Old: "/inbox/a.pdf", New: "/docs/a.pdf", Err: syscall.EBUSY})
+	assert.True(t, IsTransient(busy))
+	assert.True(t, IsTransient(fmt.Errorf("upload: %w", syscall.ECONNREFUSED)))
+
+	assert.False(t, IsTransient(nil))
+	assert.False(t, IsTransient(&os.PathError{Op: "open", Path: "/a", Err: syscall.EACCES}))
+	assert.False(t, IsTransient(New("no rule matches")))
+}
@@ -6,6 +6,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"os"
 )
 
 // Standard errors package errors that we re-export for convenience
@@ -23,6 +24,7 @@ var (
 	ErrFileNotFound  = NewFileError("file not found", "", FileNotFound, nil)
 	ErrFileAccess    = NewFileError("file access denied", "", FileAccessDenied, nil)
 	ErrInvalidPath   = NewFileError("invalid file path", "", InvalidPath, nil)
+	ErrReadOnly      = NewFileError("read-only mode is on", "", ReadOnlyMode, nil)
 	ErrInvalidConfig = NewConfigError("invalid configuration", "", InvalidConfig, nil)
 	ErrInvalidRule   = NewRuleError("invalid rule", "", InvalidRule, nil)
 )
@@ -47,6 +49,10 @@ const (
 	// Rule error kinds
 	InvalidRule
 	RuleNotFound
+	// File error kinds added later, after the rest so logged kinds keep their numbers
+	PermissionDenied // The operating system refused access (EACCES or EPERM)
+	ReadOnlyMode     // sortd's read-only mode is on
+	QuotaExceeded    // The destination directory is over its quota
 )
 
 // ApplicationError is the base error type for all application errors
@@ -74,6 +80,11 @@ func (e *ApplicationError) Kind() ErrorKind {
 	return e.kind
 }
 
+// Message returns what failed, without the wrapped error
+func (e *ApplicationError) Message() string {
+	return e.msg
+}
+
 // FileError represents errors related to file operations
 type FileError struct {
 	ApplicationError
@@ -108,6 +119,16 @@ func (e *FileError) Path() string {
 	return e.path
 }
 
+// NewOSFileError creates a file error for a failed file system call. Errors
+// the operating system refused for lack of permission get the PermissionDenied
+// kind, whatever kind is passed, so they can be told apart from other failures.
+func NewOSFileError(msg string, path string, kind ErrorKind, err error) *FileError {
+	if errors.Is(err, os.ErrPermission) {
+		kind = PermissionDenied
+	}
+	return NewFileError(msg, path, kind, err)
+}
+
 // ConfigError represents errors related to configuration
 type ConfigError struct {
 	ApplicationError
@@ -234,6 +255,34 @@ func IsFileAccessDenied(err error) bool {
 	return false
 }
 
+// IsPermissionDenied checks if the error is a permission error from the
+// operating system
+func IsPermissionDenied(err error) bool {
+	var fileErr *FileError
+	if errors.As(err, &fileErr) {
+		return fileErr.Kind() == PermissionDenied
+	}
+	return false
+}
+
+// IsReadOnlyMode checks if the error was caused by read-only mode
+func IsReadOnlyMode(err error) bool {
+	var fileErr *FileError
+	if errors.As(err, &fileErr) {
+		return fileErr.Kind() == ReadOnlyMode
+	}
+	return false
+}
+
+// IsQuotaExceeded checks if the error was caused by a full destination quota
+func IsQuotaExceeded(err error) bool {
+	var fileErr *FileError
+	if errors.As(err, &fileErr) {
+		return fileErr.Kind() == QuotaExceeded
+	}
+	return false
+}
+
 // IsInvalidConfig checks if the error is an invalid configuration error
 func IsInvalidConfig(err error) bool {
 	var configErr *ConfigError
@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"os"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -88,6 +90,30 @@ func TestFileError(t *testing.T) {
 	assert.Equal(t, "/path/to/file", fe.Path())
 }
 
+func TestPermissionDenied(t *testing.T) {
+	// Permission errors from the OS get their own kind, whatever kind is asked for
+	accessErr := &os.PathError{Op: "rename", Path: "/inbox/a.pdf", Err: syscall.EACCES}
+	err := NewOSFileError("failed to move file", "/inbox/a.pdf", FileOperationFailed, accessErr)
+	assert.Equal(t, PermissionDenied, err.Kind())
+	assert.True(t, IsPermissionDenied(fmt.Errorf("wrapped: %w", err)))
+	assert.Contains(t, Suggestion(err), "ls -ld")
+
+	permErr := &os.PathError{Op: "rename", Path: "/inbox/a.pdf", Err: syscall.EPERM}
+	err = NewOSFileError("failed to move file", "/inbox/a.pdf", FileOperationFailed, permErr)
+	assert.True(t, IsPermissionDenied(err))
+	assert.Contains(t, Suggestion(err), "immutable")
+
+	// Other failures keep the given kind and have no suggestion
+	err = NewOSFileError("failed to move file", "/inbox/a.pdf", FileOperationFailed, syscall.EXDEV)
+	assert.Equal(t, FileOperationFailed, err.Kind())
+	assert.False(t, IsPermissionDenied(err))
+	assert.Empty(t, Suggestion(err))
+	assert.Empty(t, Suggestion(errors.New("plain")))
+
+	assert.True(t, IsReadOnlyMode(ErrReadOnly))
+	assert.NotEmpty(t, Suggestion(ErrReadOnly))
+}
+
 func TestConfigError(t *testing.T) {
 	// Test creating a config error
 	configErr := NewConfigError("invalid value", "timeout", InvalidConfig, nil)
@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"context"
+	"net"
+	"syscall"
+)
+
+// transientErrnos are operating system errors that tend to clear up by
+// themselves: busy or locked files, interrupted calls and unreachable network
+// targets
+var transientErrnos = []syscall.Errno{
+	syscall.EBUSY,
+	syscall.ETXTBSY,
+	syscall.EAGAIN,
+	syscall.EINTR,
+	syscall.ETIMEDOUT,
+	syscall.ECONNREFUSED,
+	syscall.ECONNRESET,
+	syscall.EHOSTUNREACH,
+	syscall.ENETUNREACH,
+	syscall.ENETDOWN,
+	syscall.ESTALE,
+}
+
+// IsTransient reports whether err is likely to go away if the operation is
+// tried again a little later
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, errno := range transientErrnos {
+		if Is(err, errno) {
+			return true
+		}
+	}
+
+	var netErr net.Error
+	if As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return Is(err, context.DeadlineExceeded)
+}
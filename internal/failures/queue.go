@@ -0,0 +1,181 @@
+// Package failures is the dead-letter queue of the watch daemon: files it
+// gave up on after retrying, kept until the user retries or dismisses them.
+package failures
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+)
+
+// queueFile is the file, in the state directory, the queue is kept in
+const queueFile = "failures.json"
+
+// Item is a file the daemon failed to handle
+type Item struct {
+	ID       string    `json:"id"`
+	Path     string    `json:"path"`
+	Source   string    `json:"source"` // "rules", "workflow", "unmatched" or "screenshot", as in the activity log
+	Error    string    `json:"error"`
+	Kind     string    `json:"kind,omitempty"`     // Error kind, e.g. PermissionDenied
+	Attempts int       `json:"attempts,omitempty"` // Tries made, retries included; 0 when unknown
+	Trace    string    `json:"trace,omitempty"`    // ID of the failed try, as in the logs
+	Failed   time.Time `json:"failed"`             // When the last try failed
+}
+
+// Queue is a persistent list of failed files. Like the pending queue, the file
+// is re-read before and rewritten after every change, so the daemon and the
+// CLI can each hold their own Queue for the same file.
+type Queue struct {
+	path  string
+	mu    sync.Mutex
+	items []Item
+}
+
+// DefaultPath returns the path of the failure queue
+func DefaultPath(cfg *config.Config) string {
+	return cfg.StateFile(queueFile)
+}
+
+// Open loads the queue at path. A missing file yields an empty queue.
+func Open(path string) (*Queue, error) {
+	q := &Queue{path: path}
+	if err := q.loadLocked(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Add records a failure. A file that already failed keeps a single entry,
+// updated to the latest failure.
+func (q *Queue) Add(item Item) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return item, err
+	}
+
+	if item.Failed.IsZero() {
+		item.Failed = time.Now()
+	}
+	item.ID = itemID(item.Path)
+
+	for i, existing := range q.items {
+		if existing.ID == item.ID {
+			q.items[i] = item
+			return item, q.saveLocked()
+		}
+	}
+	q.items = append(q.items, item)
+	return item, q.saveLocked()
+}
+
+// List returns the failures, oldest first
+func (q *Queue) List() ([]Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return nil, err
+	}
+	return append([]Item(nil), q.items...), nil
+}
+
+// Get returns the failure with the given ID
+func (q *Queue) Get(id string) (Item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return Item{}, false
+	}
+	for _, item := range q.items {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// Remove drops a failure, e.g. once a retry has succeeded
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return err
+	}
+
+	for i, item := range q.items {
+		if item.ID == id {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			return q.saveLocked()
+		}
+	}
+	return fmt.Errorf("no failure with ID %s", id)
+}
+
+// Retry runs handle on a failed file and removes the entry when it succeeds.
+// A file that no longer exists is removed without calling handle.
+func (q *Queue) Retry(id string, handle func(path string) error) error {
+	item, ok := q.Get(id)
+	if !ok {
+		return fmt.Errorf("no failure with ID %s", id)
+	}
+
+	if _, err := os.Stat(item.Path); os.IsNotExist(err) {
+		return q.Remove(id)
+	}
+	if err := handle(item.Path); err != nil {
+		return err
+	}
+	return q.Remove(id)
+}
+
+// itemID derives a short stable ID from the file path, so that a file failing
+// again replaces its entry
+func itemID(path string) string {
+	sum := sha1.Sum([]byte(filepath.Clean(path)))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+// loadLocked re-reads the queue from disk. The caller must hold q.mu.
+func (q *Queue) loadLocked() error {
+	data, err := os.ReadFile(q.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			q.items = nil
+			return nil
+		}
+		return fmt.Errorf("failed to read failure queue: %w", err)
+	}
+
+	var loaded []Item
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed to parse failure queue: %w", err)
+	}
+	q.items = loaded
+	return nil
+}
+
+// saveLocked writes the queue to disk. The caller must hold q.mu.
+func (q *Queue) saveLocked() error {
+	data, err := json.MarshalIndent(q.items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode failure queue: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(q.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save failure queue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+// Package flowmap summarises where organizing a directory sends its files,
+// by file type, rule and destination, and renders the summary as a Graphviz
+// graph or a self-contained HTML report that can be shared.
+package flowmap
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sortd/internal/app"
+	"sortd/internal/units"
+	"sortd/pkg/types"
+)
+
+// Format names accepted by Write
+const (
+	FormatHTML = "html"
+	FormatDOT  = "dot"
+)
+
+// Labels for files that no rule places
+const (
+	noExtension = "(no extension)"
+	unsorted    = "(unsorted)"
+	screenshots = "(screenshots)"
+	stays       = "(stays in place)"
+)
+
+// Flow is a group of files of one type that one rule sends to one directory
+type Flow struct {
+	Type        string // Extension without the dot, lowercased
+	Rule        string // Pattern match; "(unsorted)" for files no rule matches
+	Destination string // Directory the files go to; "(stays in place)" when they don't move
+	Files       int
+	Size        int64
+}
+
+// Map is where a directory's files flow
+type Map struct {
+	Root      string
+	Generated time.Time
+	Flows     []Flow          // Largest first
+	Idle      []types.Pattern // Configured rules no file in the directory matches
+}
+
+// Build summarises a plan. patterns are the configured rules, to list those
+// that don't apply to any file.
+func Build(plan *app.Plan, patterns []types.Pattern) *Map {
+	type key struct{ typ, rule, dest string }
+	flows := make(map[key]*Flow)
+	add := func(file, rule, dest string) {
+		k := key{fileType(file), rule, dest}
+		flow, ok := flows[k]
+		if !ok {
+			flow = &Flow{Type: k.typ, Rule: rule, Destination: dest}
+			flows[k] = flow
+		}
+		flow.Files++
+		if info, err := os.Stat(file); err == nil {
+			flow.Size += info.Size()
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, move := range plan.Moves {
+		rule := move.Pattern
+		switch {
+		case move.Unmatched:
+			rule = unsorted
+		case move.Screenshot:
+			rule = screenshots
+		}
+		used[rule] = true
+		add(move.Source, rule, filepath.Dir(move.Destination))
+	}
+	for _, file := range plan.Unmatched {
+		add(file, unsorted, stays)
+	}
+
+	m := &Map{Root: plan.Root, Generated: time.Now()}
+	for _, flow := range flows {
+		m.Flows = append(m.Flows, *flow)
+	}
+	sort.Slice(m.Flows, func(i, j int) bool {
+		a, b := m.Flows[i], m.Flows[j]
+		if a.Files != b.Files {
+			return a.Files > b.Files
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Rule < b.Rule
+	})
+	for _, pattern := range patterns {
+		if !used[pattern.Match] {
+			m.Idle = append(m.Idle, pattern)
+		}
+	}
+	return m
+}
+
+// fileType returns the lowercased extension of a file, without the dot
+func fileType(path string) string {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if ext == "" {
+		return noExtension
+	}
+	return ext
+}
+
+// Write renders the map in the given format
+func (m *Map) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case FormatHTML, "":
+		return m.WriteHTML(w)
+	case FormatDOT:
+		return m.WriteDOT(w)
+	default:
+		return fmt.Errorf("unknown map format %q (use html or dot)", format)
+	}
+}
+
+// WriteDOT renders the map as a Graphviz graph flowing left to right from
+// file types through rules to destinations. Edges are labelled with file
+// counts; rules no file matches are drawn dashed.
+func (m *Map) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph sortd {\n")
+	fmt.Fprintf(&b, "  label=%s;\n  labelloc=t;\n  rankdir=LR;\n", quoteDOT("sortd map of "+m.Root))
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+
+	nodes := make(map[string]bool)
+	node := func(id, label, attrs string) string {
+		if !nodes[id] {
+			nodes[id] = true
+			fmt.Fprintf(&b, "  %s [label=%s %s];\n", quoteDOT(id), quoteDOT(label), attrs)
+		}
+		return quoteDOT(id)
+	}
+	edges := make(map[[2]string]int)
+	var order [][2]string
+	edge := func(from, to string, files int) {
+		k := [2]string{from, to}
+		if _, ok := edges[k]; !ok {
+			order = append(order, k)
+		}
+		edges[k] += files
+	}
+
+	for _, flow := range m.Flows {
+		typ := node("type:"+flow.Type, flow.Type, "shape=note style=filled fillcolor=\"#fff3d6\"")
+		rule := node("rule:"+flow.Rule, flow.Rule, "shape=box style=rounded")
+		dest := node("dest:"+flow.Destination, shortenHome(flow.Destination), "shape=folder style=filled fillcolor=\"#dbeafe\"")
+		edge(typ, rule, flow.Files)
+		edge(rule, dest, flow.Files)
+	}
+	for _, k := range order {
+		fmt.Fprintf(&b, "  %s -> %s [label=\"%d\" penwidth=%.1f];\n", k[0], k[1], edges[k], penWidth(edges[k]))
+	}
+
+	for _, pattern := range m.Idle {
+		rule := node("rule:"+pattern.Match, pattern.Match, "shape=box style=\"rounded,dashed\" fontcolor=gray")
+		dest := node("dest:"+pattern.Target, shortenHome(pattern.Target), "shape=folder style=dashed fontcolor=gray")
+		fmt.Fprintf(&b, "  %s -> %s [style=dashed color=gray];\n", rule, dest)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quoteDOT quotes a string as a DOT ID
+func quoteDOT(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// penWidth thickens edges carrying more files
+func penWidth(files int) float64 {
+	width := 1.0
+	for n := files; n > 1; n /= 4 {
+		width += 0.5
+	}
+	return width
+}
+
+// shortenHome writes paths under the home directory with ~
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rel, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return "~/" + rel
+	}
+	return path
+}
+
+// htmlFlow is a flow as shown in the HTML report
+type htmlFlow struct {
+	Flow
+	Destination string
+	Size        string
+	Share       float64 // Percent of the files, for the bar
+}
+
+// WriteHTML renders the map as a single HTML page with no outside resources,
+// so it can be mailed or dropped on a shared drive
+func (m *Map) WriteHTML(w io.Writer) error {
+	total := 0
+	var size int64
+	for _, flow := range m.Flows {
+		total += flow.Files
+		size += flow.Size
+	}
+
+	data := struct {
+		Root, Generated, Size string
+		Total                 int
+		Flows                 []htmlFlow
+		Idle                  []types.Pattern
+	}{
+		Root:      shortenHome(m.Root),
+		Generated: m.Generated.Format("2006-01-02 15:04"),
+		Size:      units.FormatSize(size),
+		Total:     total,
+		Idle:      m.Idle,
+	}
+	for _, flow := range m.Flows {
+		data.Flows = append(data.Flows, htmlFlow{
+			Flow:        flow,
+			Destination: shortenHome(flow.Destination),
+			Size:        units.FormatSize(flow.Size),
+			Share:       100 * float64(flow.Files) / float64(total),
+		})
+	}
+	return htmlReport.Execute(w, data)
+}
+
+var htmlReport = template.Must(template.New("map").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>sortd map of {{.Root}}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Helvetica, sans-serif; margin: 2em auto; max-width: 60em; color: #1f2937; }
+  h1 { font-size: 1.4em; } h2 { font-size: 1.1em; margin-top: 2em; }
+  .meta { color: #6b7280; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; padding: .4em .6em; border-bottom: 1px solid #e5e7eb; vertical-align: middle; }
+  th { color: #6b7280; font-weight: 600; }
+  .type { font-family: monospace; background: #fff3d6; padding: .1em .4em; border-radius: 3px; }
+  .rule { font-family: monospace; }
+  .dest { font-family: monospace; color: #1d4ed8; }
+  .arrow { color: #9ca3af; }
+  .num { text-align: right; white-space: nowrap; }
+  .bar { background: #f3f4f6; width: 8em; } .bar div { background: #f59e0b; height: .8em; border-radius: 2px; }
+  .idle { color: #6b7280; }
+</style>
+</head>
+<body>
+<h1>Where files in {{.Root}} go</h1>
+<p class="meta">{{.Total}} files, {{.Size}} · generated by sortd on {{.Generated}}</p>
+{{if .Flows}}
+<table>
+  <tr><th>Type</th><th></th><th>Rule</th><th></th><th>Destination</th><th class="num">Files</th><th class="num">Size</th><th></th></tr>
+  {{range .Flows}}
+  <tr>
+    <td><span class="type">{{.Type}}</span></td><td class="arrow">→</td>
+    <td class="rule">{{.Rule}}</td><td class="arrow">→</td>
+    <td class="dest">{{.Destination}}</td>
+    <td class="num">{{.Files}}</td><td class="num">{{.Size}}</td>
+    <td class="bar"><div style="width: {{printf "%.1f" .Share}}%"></div></td>
+  </tr>
+  {{end}}
+</table>
+{{else}}
+<p>No files to organize.</p>
+{{end}}
+{{if .Idle}}
+<h2>Rules that no file here matches</h2>
+<table class="idle">
+  {{range .Idle}}<tr><td class="rule">{{.Match}}</td><td class="arrow">→</td><td class="dest">{{.Target}}</td></tr>
+  {{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,81 @@
+package flowmap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sortd/internal/app"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPlan returns a plan over real files, so sizes can be measured
+func testPlan(t *testing.T) *app.Plan {
+	t.Helper()
+	dir := t.TempDir()
+	file := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	return &app.Plan{
+		Root: dir,
+		Moves: []app.Move{
+			{Source: file("a.pdf", "aaaa"), Destination: "/docs/a.pdf", Pattern: "*.pdf"},
+			{Source: file("b.PDF", "bb"), Destination: "/docs/b.PDF", Pattern: "*.pdf"},
+			{Source: file("c.jpg", "c"), Destination: "/pics/c.jpg", Pattern: "*.jpg"},
+			{Source: file("d.zip", "d"), Destination: "/unsorted/d.zip", Unmatched: true},
+		},
+		Unmatched: []string{file("README", "r")},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	patterns := []types.Pattern{
+		{Match: "*.pdf", Target: "/docs"},
+		{Match: "*.jpg", Target: "/pics"},
+		{Match: "*.mp3", Target: "/music"},
+	}
+	m := Build(testPlan(t), patterns)
+
+	require.Len(t, m.Flows, 4)
+	assert.Equal(t, Flow{Type: "pdf", Rule: "*.pdf", Destination: "/docs", Files: 2, Size: 6}, m.Flows[0],
+		"Extensions are grouped regardless of case")
+	assert.Contains(t, m.Flows, Flow{Type: "zip", Rule: unsorted, Destination: "/unsorted", Files: 1, Size: 1})
+	assert.Contains(t, m.Flows, Flow{Type: noExtension, Rule: unsorted, Destination: stays, Files: 1, Size: 1})
+	assert.Equal(t, []types.Pattern{{Match: "*.mp3", Target: "/music"}}, m.Idle)
+}
+
+func TestWriteDOT(t *testing.T) {
+	m := Build(testPlan(t), []types.Pattern{{Match: `say "hi"`, Target: "/quotes"}})
+
+	var b strings.Builder
+	require.NoError(t, m.Write(&b, FormatDOT))
+	dot := b.String()
+
+	assert.True(t, strings.HasPrefix(dot, "digraph sortd {"))
+	assert.Contains(t, dot, `"type:pdf" -> "rule:*.pdf" [label="2"`)
+	assert.Contains(t, dot, `"rule:*.pdf" -> "dest:/docs" [label="2"`)
+	assert.Contains(t, dot, `"rule:say \"hi\"" -> "dest:/quotes" [style=dashed`, "Idle rules are drawn dashed, with quotes escaped")
+}
+
+func TestWriteHTML(t *testing.T) {
+	m := Build(testPlan(t), []types.Pattern{{Match: "<script>", Target: "/x"}})
+
+	var b strings.Builder
+	require.NoError(t, m.Write(&b, FormatHTML))
+	page := b.String()
+
+	assert.Contains(t, page, "<title>sortd map of ")
+	assert.Contains(t, page, `<td class="dest">/docs</td>`)
+	assert.Contains(t, page, "width: 40.0%", "Two of five files flow through the pdf rule")
+	assert.Contains(t, page, "&lt;script&gt;")
+	assert.NotContains(t, page, "<script>")
+
+	assert.Error(t, m.Write(&b, "svg"))
+}
@@ -0,0 +1,272 @@
+// Package focus keeps the state of focus mode: working through a messy
+// directory a small batch of files at a time, with a daily streak to keep
+// coming back. Sessions survive restarts, so a directory can be cleared
+// over several days.
+package focus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+)
+
+// stateFile is the file, in the state directory, focus state is kept in
+const stateFile = "focus.json"
+
+// DefaultBatchSize is the number of files in a batch unless the user picks
+// another size
+const DefaultBatchSize = 10
+
+// dayLayout identifies calendar days for the streak
+const dayLayout = "2006-01-02"
+
+// Session is the progress made on one directory
+type Session struct {
+	Directory string    `json:"directory"`
+	BatchSize int       `json:"batch_size"`
+	Done      int       `json:"done"`              // Files organized or skipped so far
+	Batches   int       `json:"batches"`           // Batches finished
+	Skipped   []string  `json:"skipped,omitempty"` // Files the user chose to leave where they are
+	Started   time.Time `json:"started"`
+	Updated   time.Time `json:"updated"`
+	Completed time.Time `json:"completed,omitempty"` // When the directory was cleared
+}
+
+// Progress returns the share of the directory handled, given the files still
+// waiting, from 0 to 1
+func (s Session) Progress(remaining int) float64 {
+	total := s.Done + remaining
+	if total == 0 {
+		return 1
+	}
+	return float64(s.Done) / float64(total)
+}
+
+// Waiting returns the files still to work through, leaving out skipped ones
+func (s Session) Waiting(files []string) []string {
+	if len(s.Skipped) == 0 {
+		return files
+	}
+	skipped := make(map[string]bool, len(s.Skipped))
+	for _, file := range s.Skipped {
+		skipped[file] = true
+	}
+	var waiting []string
+	for _, file := range files {
+		if !skipped[file] {
+			waiting = append(waiting, file)
+		}
+	}
+	return waiting
+}
+
+// state is what the state file holds
+type state struct {
+	Sessions   []Session `json:"sessions"`
+	Streak     int       `json:"streak"`      // Consecutive days with a finished batch
+	BestStreak int       `json:"best_streak"` // Longest streak so far
+	LastDay    string    `json:"last_day,omitempty"`
+}
+
+// Store is the persistent focus state. Like the pending queue, the file is
+// re-read before and rewritten after every change, so the GUI and the CLI
+// can each hold their own Store for the same file.
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	state state
+}
+
+// DefaultPath returns the path of the focus state
+func DefaultPath(cfg *config.Config) string {
+	return cfg.StateFile(stateFile)
+}
+
+// Open loads the focus state at path. A missing file yields an empty state.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := s.loadLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Start returns the session for dir, resuming an unfinished one. A batch size
+// of zero or less keeps the session's size, or DefaultBatchSize for a new one.
+func (s *Store) Start(dir string, batchSize int, now time.Time) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return Session{}, err
+	}
+
+	dir = filepath.Clean(dir)
+	if i := s.indexLocked(dir); i >= 0 && s.state.Sessions[i].Completed.IsZero() {
+		session := &s.state.Sessions[i]
+		if batchSize > 0 && batchSize != session.BatchSize {
+			session.BatchSize = batchSize
+			session.Updated = now
+			return *session, s.saveLocked()
+		}
+		return *session, nil
+	} else if i >= 0 {
+		// A cleared directory got messy again; start over
+		s.state.Sessions = append(s.state.Sessions[:i], s.state.Sessions[i+1:]...)
+	}
+
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	session := Session{Directory: dir, BatchSize: batchSize, Started: now, Updated: now}
+	s.state.Sessions = append(s.state.Sessions, session)
+	return session, s.saveLocked()
+}
+
+// Session returns the session for dir
+func (s *Store) Session(dir string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return Session{}, false
+	}
+	if i := s.indexLocked(filepath.Clean(dir)); i >= 0 {
+		return s.state.Sessions[i], true
+	}
+	return Session{}, false
+}
+
+// Sessions returns all sessions, oldest first
+func (s *Store) Sessions() ([]Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return nil, err
+	}
+	return append([]Session(nil), s.state.Sessions...), nil
+}
+
+// FinishBatch records a finished batch in dir, in which organized files were
+// moved and skipped ones left alone, with remaining files still waiting, and
+// extends the streak. The session is completed once nothing remains.
+func (s *Store) FinishBatch(dir string, organized int, skipped []string, remaining int, now time.Time) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return Session{}, err
+	}
+
+	i := s.indexLocked(filepath.Clean(dir))
+	if i < 0 {
+		return Session{}, fmt.Errorf("no focus session for %s", dir)
+	}
+	session := &s.state.Sessions[i]
+	session.Done += organized + len(skipped)
+	session.Skipped = append(session.Skipped, skipped...)
+	session.Batches++
+	session.Updated = now
+	if remaining <= 0 {
+		session.Completed = now
+	}
+
+	s.extendStreakLocked(now)
+	return *session, s.saveLocked()
+}
+
+// Streak returns the number of consecutive days, up to today, with a finished
+// batch, and the longest streak so far. A streak survives until the end of the
+// day after its last batch.
+func (s *Store) Streak(now time.Time) (current, best int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return 0, 0
+	}
+	today := now.Format(dayLayout)
+	yesterday := now.AddDate(0, 0, -1).Format(dayLayout)
+	if s.state.LastDay != today && s.state.LastDay != yesterday {
+		return 0, s.state.BestStreak
+	}
+	return s.state.Streak, s.state.BestStreak
+}
+
+// extendStreakLocked counts today towards the streak. The caller must hold s.mu.
+func (s *Store) extendStreakLocked(now time.Time) {
+	today := now.Format(dayLayout)
+	switch s.state.LastDay {
+	case today:
+		return
+	case now.AddDate(0, 0, -1).Format(dayLayout):
+		s.state.Streak++
+	default:
+		s.state.Streak = 1
+	}
+	s.state.LastDay = today
+	if s.state.Streak > s.state.BestStreak {
+		s.state.BestStreak = s.state.Streak
+	}
+}
+
+// Batch returns the next batch of files to work on
+func Batch(files []string, size int) []string {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	if len(files) > size {
+		files = files[:size]
+	}
+	return files
+}
+
+// indexLocked returns the index of the session for dir, or -1. The caller
+// must hold s.mu.
+func (s *Store) indexLocked(dir string) int {
+	for i, session := range s.state.Sessions {
+		if session.Directory == dir {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadLocked re-reads the state from disk. The caller must hold s.mu.
+func (s *Store) loadLocked() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.state = state{}
+			return nil
+		}
+		return fmt.Errorf("failed to read focus state: %w", err)
+	}
+
+	var loaded state
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed to parse focus state: %w", err)
+	}
+	s.state = loaded
+	return nil
+}
+
+// saveLocked writes the state to disk. The caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode focus state: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save focus state: %w", err)
+	}
+	return nil
+}
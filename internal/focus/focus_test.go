@@ -0,0 +1,94 @@
+package focus_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/focus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "focus.json")
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+
+	store, err := focus.Open(path)
+	require.NoError(t, err)
+	session, err := store.Start("/home/me/Downloads", 0, now)
+	require.NoError(t, err)
+	assert.Equal(t, focus.DefaultBatchSize, session.BatchSize)
+
+	session, err = store.FinishBatch("/home/me/Downloads", 9, []string{"/home/me/Downloads/keep.txt"}, 30, now)
+	require.NoError(t, err)
+	assert.Equal(t, 10, session.Done)
+	assert.Equal(t, 1, session.Batches)
+	assert.InDelta(t, 0.25, session.Progress(30), 0.001)
+	assert.True(t, session.Completed.IsZero())
+
+	// A new store, as in the next session, resumes where the last one stopped
+	reopened, err := focus.Open(path)
+	require.NoError(t, err)
+	session, err = reopened.Start("/home/me/Downloads/", 5, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 10, session.Done)
+	assert.Equal(t, 5, session.BatchSize)
+	assert.Equal(t, []string{"/home/me/Downloads/a.pdf"},
+		session.Waiting([]string{"/home/me/Downloads/keep.txt", "/home/me/Downloads/a.pdf"}),
+		"skipped files aren't offered again")
+
+	session, err = reopened.FinishBatch("/home/me/Downloads", 5, nil, 0, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, session.Completed.IsZero())
+	assert.Equal(t, 1.0, session.Progress(0))
+
+	// Once cleared, the next start begins a fresh session
+	session, err = reopened.Start("/home/me/Downloads", 0, now.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 0, session.Done)
+	assert.Equal(t, focus.DefaultBatchSize, session.BatchSize)
+}
+
+func TestStreak(t *testing.T) {
+	store, err := focus.Open(filepath.Join(t.TempDir(), "focus.json"))
+	require.NoError(t, err)
+	day := time.Date(2024, 3, 1, 20, 0, 0, 0, time.Local)
+
+	_, err = store.Start("/tmp/messy", 10, day)
+	require.NoError(t, err)
+
+	current, best := store.Streak(day)
+	assert.Equal(t, 0, current)
+	assert.Equal(t, 0, best)
+
+	for i := 0; i < 3; i++ {
+		_, err = store.FinishBatch("/tmp/messy", 10, nil, 100, day.AddDate(0, 0, i))
+		require.NoError(t, err)
+	}
+	// A second batch on the same day doesn't count twice
+	_, err = store.FinishBatch("/tmp/messy", 10, nil, 90, day.AddDate(0, 0, 2))
+	require.NoError(t, err)
+
+	current, best = store.Streak(day.AddDate(0, 0, 3))
+	assert.Equal(t, 3, current, "the streak lasts through the following day")
+	assert.Equal(t, 3, best)
+
+	current, best = store.Streak(day.AddDate(0, 0, 4))
+	assert.Equal(t, 0, current)
+	assert.Equal(t, 3, best)
+
+	_, err = store.FinishBatch("/tmp/messy", 10, nil, 80, day.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	current, best = store.Streak(day.AddDate(0, 0, 5))
+	assert.Equal(t, 1, current)
+	assert.Equal(t, 3, best)
+}
+
+func TestBatch(t *testing.T) {
+	files := []string{"a", "b", "c"}
+	assert.Equal(t, []string{"a", "b"}, focus.Batch(files, 2))
+	assert.Equal(t, files, focus.Batch(files, 10))
+	assert.Equal(t, files, focus.Batch(files, 0))
+}
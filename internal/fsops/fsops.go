@@ -0,0 +1,141 @@
+// Package fsops is the one layer through which organize runs and workflows
+// change files: moves, copies, deletes and new directories. Dry runs swap the
+// real file system for a layer that changes nothing, so a code path that
+// forgets to check for a dry run still can't touch the disk.
+package fsops
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"sortd/internal/atomicfile"
+)
+
+// FS makes the changes organize runs and workflows make to files
+type FS interface {
+	MkdirAll(path string, perm os.FileMode) error
+	Rename(oldpath, newpath string) error // Copies and removes the original between filesystems
+	Remove(path string) error
+	CopyFile(src, dst string) error // Staged, so a crash never leaves a partial copy
+	Chtimes(path string, atime, mtime time.Time) error
+}
+
+// OS is the real file system
+var OS FS = osFS{}
+
+// DryRun changes nothing and reports success, standing in for the file
+// system during dry runs
+var DryRun FS = dryRunFS{}
+
+// For returns the layer changes go through: DryRun for dry runs, otherwise fs
+func For(fs FS, dryRun bool) FS {
+	if dryRun {
+		return DryRun
+	}
+	return fs
+}
+
+type osFS struct{}
+
+func (osFS) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+func (osFS) Rename(oldpath, newpath string) error         { return move(oldpath, newpath, atomicfile.CopyFile) }
+func (osFS) Remove(path string) error                     { return os.Remove(path) }
+func (osFS) CopyFile(src, dst string) error               { return atomicfile.CopyFile(src, dst) }
+func (osFS) Chtimes(path string, atime, mtime time.Time) error {
+	return os.Chtimes(path, atime, mtime)
+}
+
+// rename is os.Rename; a variable so tests can make it fail across devices
+var rename = os.Rename
+
+// move renames oldpath to newpath. A file can't be renamed onto another
+// filesystem, so there it is copied with copyFile, which flushes the copy to
+// disk, and the original removed once the copy is complete.
+func move(oldpath, newpath string, copyFile func(src, dst string) error) error {
+	err := rename(oldpath, newpath)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	info, statErr := os.Lstat(oldpath)
+	if statErr != nil || !info.Mode().IsRegular() {
+		return err
+	}
+	if err := copyFile(oldpath, newpath); err != nil {
+		return err
+	}
+	if err := os.Chtimes(newpath, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Remove(oldpath)
+}
+
+type dryRunFS struct{}
+
+func (dryRunFS) MkdirAll(string, os.FileMode) error         { return nil }
+func (dryRunFS) Rename(string, string) error                { return nil }
+func (dryRunFS) Remove(string) error                        { return nil }
+func (dryRunFS) CopyFile(string, string) error              { return nil }
+func (dryRunFS) Chtimes(string, time.Time, time.Time) error { return nil }
+
+// Op is a change made through a Recorder
+type Op struct {
+	Name   string // "mkdir", "rename", "remove", "copy" or "chtimes"
+	Path   string
+	Target string // The new path of a rename, or the destination of a copy
+}
+
+// Recorder passes changes on to another FS and records each of them, for
+// tests that check what reached the disk
+type Recorder struct {
+	fs FS
+
+	mu  sync.Mutex
+	ops []Op
+}
+
+// NewRecorder returns a Recorder in front of fs
+func NewRecorder(fs FS) *Recorder {
+	return &Recorder{fs: fs}
+}
+
+// Ops returns the changes recorded so far
+func (r *Recorder) Ops() []Op {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Op(nil), r.ops...)
+}
+
+func (r *Recorder) record(op Op) {
+	r.mu.Lock()
+	r.ops = append(r.ops, op)
+	r.mu.Unlock()
+}
+
+func (r *Recorder) MkdirAll(path string, perm os.FileMode) error {
+	r.record(Op{Name: "mkdir", Path: path})
+	return r.fs.MkdirAll(path, perm)
+}
+
+func (r *Recorder) Rename(oldpath, newpath string) error {
+	r.record(Op{Name: "rename", Path: oldpath, Target: newpath})
+	return r.fs.Rename(oldpath, newpath)
+}
+
+func (r *Recorder) Remove(path string) error {
+	r.record(Op{Name: "remove", Path: path})
+	return r.fs.Remove(path)
+}
+
+func (r *Recorder) CopyFile(src, dst string) error {
+	r.record(Op{Name: "copy", Path: src, Target: dst})
+	return r.fs.CopyFile(src, dst)
+}
+
+func (r *Recorder) Chtimes(path string, atime, mtime time.Time) error {
+	r.record(Op{Name: "chtimes", Path: path})
+	return r.fs.Chtimes(path, atime, mtime)
+}
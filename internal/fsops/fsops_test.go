@@ -0,0 +1,94 @@
+package fsops
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFor(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	require.NoError(t, os.WriteFile(file, []byte("a"), 0644))
+
+	recorder := NewRecorder(OS)
+	dry := For(recorder, true)
+	require.NoError(t, dry.MkdirAll(filepath.Join(dir, "new"), 0755))
+	require.NoError(t, dry.CopyFile(file, filepath.Join(dir, "b.txt")))
+	require.NoError(t, dry.Chtimes(file, time.Now(), time.Now()))
+	require.NoError(t, dry.Rename(file, filepath.Join(dir, "c.txt")))
+	require.NoError(t, dry.Remove(file))
+	assert.Empty(t, recorder.Ops())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "A dry run leaves the directory alone")
+
+	live := For(recorder, false)
+	require.NoError(t, live.Rename(file, filepath.Join(dir, "c.txt")))
+	assert.Equal(t, []Op{{Name: "rename", Path: file, Target: filepath.Join(dir, "c.txt")}}, recorder.Ops())
+	assert.FileExists(t, filepath.Join(dir, "c.txt"))
+}
+
+func TestRenameAcrossDevices(t *testing.T) {
+	old := rename
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	t.Cleanup(func() { rename = old })
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "film.mkv")
+	dst := filepath.Join(dir, "nas", "film.mkv")
+	require.NoError(t, os.WriteFile(src, []byte("film"), 0640))
+	mtime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(src, mtime, mtime))
+	require.NoError(t, os.Mkdir(filepath.Dir(dst), 0755))
+
+	require.NoError(t, OS.Rename(src, dst))
+	assert.NoFileExists(t, src, "The original goes once the copy is complete")
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "film", string(data))
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	assert.True(t, info.ModTime().Equal(mtime), "The copy keeps the modification time")
+
+	// Other errors are returned as they are, and the original stays
+	rename = func(string, string) error { return os.ErrPermission }
+	require.NoError(t, os.WriteFile(src, []byte("film"), 0644))
+	assert.ErrorIs(t, OS.Rename(src, filepath.Join(dir, "other.mkv")), os.ErrPermission)
+	assert.FileExists(t, src)
+}
+
+func TestThrottled(t *testing.T) {
+	assert.Equal(t, OS, Throttled(0))
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote")
+	require.NoError(t, os.Mkdir(remote, 0755))
+	old := isRemote
+	isRemote = func(path string) bool { return path == remote }
+	t.Cleanup(func() { isRemote = old })
+
+	src := filepath.Join(dir, "a.bin")
+	data := make([]byte, 4096)
+	require.NoError(t, os.WriteFile(src, data, 0644))
+	throttled := Throttled(16 * 1024)
+
+	start := time.Now()
+	require.NoError(t, throttled.CopyFile(src, filepath.Join(dir, "local.bin")))
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "Local copies aren't held back")
+
+	start = time.Now()
+	require.NoError(t, throttled.CopyFile(src, filepath.Join(remote, "a.bin")))
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond, "4KB at 16KB/s takes a quarter second")
+	copied, err := os.ReadFile(filepath.Join(remote, "a.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, data, copied)
+}
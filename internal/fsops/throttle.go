@@ -0,0 +1,75 @@
+package fsops
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/power"
+)
+
+// isRemote tells whether a directory is on a network file system; a variable
+// so tests can pick which directories count
+var isRemote = power.IsRemote
+
+// Throttled returns the real file system with each copy to a network file
+// system held to bytesPerSecond, so a large copy leaves bandwidth for
+// everything else. 0 returns OS unthrottled.
+func Throttled(bytesPerSecond int64) FS {
+	if bytesPerSecond <= 0 {
+		return OS
+	}
+	return throttledFS{FS: OS, rate: bytesPerSecond}
+}
+
+type throttledFS struct {
+	FS
+	rate int64
+}
+
+func (t throttledFS) Rename(oldpath, newpath string) error {
+	return move(oldpath, newpath, t.CopyFile)
+}
+
+func (t throttledFS) CopyFile(src, dst string) error {
+	if !isRemote(filepath.Dir(dst)) {
+		return t.FS.CopyFile(src, dst)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(dst, &throttledReader{r: in, rate: t.rate, start: time.Now()}, info.Mode().Perm())
+}
+
+// throttledReader reads no faster than rate bytes per second on average
+type throttledReader struct {
+	r     io.Reader
+	rate  int64
+	start time.Time
+	read  int64
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	// Read at most a second's worth at once, so the pace stays even
+	if int64(len(p)) > t.rate {
+		p = p[:t.rate]
+	}
+	n, err := t.r.Read(p)
+	t.read += int64(n)
+
+	due := time.Duration(float64(t.read) / float64(t.rate) * float64(time.Second))
+	if wait := due - time.Since(t.start); wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
+}
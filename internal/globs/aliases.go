@@ -0,0 +1,56 @@
+package globs
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// extensionAliases lists extensions that are spellings of the same format
+var extensionAliases = [][]string{
+	{"jpg", "jpeg", "jpe"},
+	{"tif", "tiff"},
+	{"htm", "html"},
+	{"yml", "yaml"},
+	{"mpg", "mpeg"},
+	{"mid", "midi"},
+	{"md", "markdown"},
+}
+
+// AliasPaths returns path with its extension swapped for each of its aliases
+// (photo.jpeg gives photo.jpg and photo.jpe), keeping the extension's case
+// when it is all upper case. Paths without a known extension give nothing.
+func AliasPaths(path string) []string {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "" {
+		return nil
+	}
+	upper := ext == strings.ToUpper(ext) && ext != strings.ToLower(ext)
+	stem := strings.TrimSuffix(path, ext)
+
+	var paths []string
+	for _, group := range extensionAliases {
+		if !containsFold(group, ext) {
+			continue
+		}
+		for _, alias := range group {
+			if strings.EqualFold(alias, ext) {
+				continue
+			}
+			if upper {
+				alias = strings.ToUpper(alias)
+			}
+			paths = append(paths, stem+alias)
+		}
+	}
+	return paths
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
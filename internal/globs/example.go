@@ -0,0 +1,99 @@
+package globs
+
+import (
+	"strings"
+)
+
+// exampleChars are tried in turn for wildcards and negated classes
+const exampleChars = "xyz0_"
+
+// Example makes up a name, or a path for patterns with a slash, that pattern
+// matches, e.g. "x.pdf" for "*.pdf", for checks that need a file to reason
+// about. It returns false when it can't come up with one.
+func Example(pattern string) (string, bool) {
+	pattern = firstAlternatives(pattern)
+
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteByte(pattern[i])
+			}
+		case '*':
+			// "**/" can stand for no directories at all
+			if strings.HasPrefix(pattern[i:], "**/") {
+				i += 2
+				continue
+			}
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			b.WriteByte('x')
+		case '?':
+			b.WriteByte('x')
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", false
+			}
+			class := pattern[i+1 : i+1+end]
+			i += end + 1
+			if strings.HasPrefix(class, "!") {
+				pick := strings.IndexFunc(exampleChars, func(r rune) bool { return !strings.ContainsRune(class, r) })
+				if pick < 0 {
+					return "", false
+				}
+				b.WriteByte(exampleChars[pick])
+			} else if class != "" {
+				b.WriteByte(class[0])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	example := b.String()
+	if matched, err := Match(pattern, example); err != nil || !matched || example == "" {
+		return "", false
+	}
+	return example, true
+}
+
+// firstAlternatives replaces each {a,b} with its first alternative
+func firstAlternatives(pattern string) string {
+	for {
+		start, end, depth := -1, -1, 0
+		commas := []int{}
+		for i := 0; i < len(pattern) && end < 0; i++ {
+			switch pattern[i] {
+			case '\\':
+				i++
+			case '{':
+				if depth == 0 {
+					start = i
+				}
+				depth++
+			case ',':
+				if depth == 1 {
+					commas = append(commas, i)
+				}
+			case '}':
+				depth--
+				if depth == 0 && start >= 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			return pattern
+		}
+
+		first := end
+		if len(commas) > 0 {
+			first = commas[0]
+		}
+		pattern = pattern[:start] + pattern[start+1:first] + pattern[end+1:]
+	}
+}
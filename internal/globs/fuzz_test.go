@@ -0,0 +1,69 @@
+package globs_test
+
+import (
+	"strings"
+	"testing"
+
+	"sortd/internal/globs"
+)
+
+func FuzzMatch(f *testing.F) {
+	for _, seed := range []struct{ pattern, path string }{
+		{"*.pdf", "/home/me/report.pdf"},
+		{"*.{jpg,png}", "photo.PNG"},
+		{"**/Screenshots/*.png", "/home/me/Pictures/Screenshots/a.png"},
+		{"[!.]*", ".hidden"},
+		{`\{draft\}*`, "{draft} notes.txt"},
+		{"{a,{b,c}}", "c"},
+		{"[", "["},
+		{"{", ""},
+		{"c{,}", "c"},
+	} {
+		f.Add(seed.pattern, seed.path)
+	}
+
+	f.Fuzz(func(t *testing.T, pattern, path string) {
+		m, err := globs.Compile(pattern)
+		if err != nil {
+			if globs.Validate(pattern) == nil {
+				t.Fatalf("Validate accepts %q, which Compile rejects", pattern)
+			}
+			return
+		}
+		matched := m.Match(path)
+		if again, err := globs.Match(pattern, path); err != nil || again != matched {
+			t.Fatalf("Match(%q, %q) = %v, %v; compiled matcher said %v", pattern, path, again, err, matched)
+		}
+		_ = globs.Migrate(pattern)
+	})
+}
+
+func FuzzMatchRegex(f *testing.F) {
+	for _, seed := range []struct{ expr, path, target string }{
+		{`IMG_(?P<year>\d{4})\d{4}_.*\.jpg`, "/p/IMG_20240501_1.jpg", "Photos/{year}"},
+		{`(?P<client>[^/]+)/invoice-.*\.pdf`, "acme/invoice-1.pdf", "Clients/{client}"},
+		{`(?P<x>.*)`, "a/b", "{x}/{y}"},
+		{`(`, "", ""},
+	} {
+		f.Add(seed.expr, seed.path, seed.target)
+	}
+
+	f.Fuzz(func(t *testing.T, expr, path, target string) {
+		groups, matched, err := globs.MatchRegex(expr, path)
+		if err != nil {
+			if globs.ValidateRegex(expr, "") == nil {
+				t.Fatalf("ValidateRegex accepts %q, which MatchRegex rejects", expr)
+			}
+			return
+		}
+		if !matched {
+			return
+		}
+		if globs.ValidateRegex(expr, target) == nil {
+			expanded := globs.ExpandGroups(target, groups)
+			if strings.Count(expanded, "/") != strings.Count(target, "/") {
+				t.Fatalf("ExpandGroups(%q) = %q added directory levels", target, expanded)
+			}
+		}
+	})
+}
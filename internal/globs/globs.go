@@ -0,0 +1,126 @@
+// Package globs matches file names and paths against the glob patterns used by
+// rules, workflows, watch filters and selections. Besides *, ? and [abc] (and
+// [!abc]), patterns support {jpg,png} alternatives and ** for any number of
+// directories. Patterns without a slash match the file's name; patterns with
+// one match its whole path, e.g. "**/Screenshots/*.png". Rules can also use
+// regular expressions, whose named groups fill in the rule's target.
+package globs
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gobwas/glob"
+)
+
+// Matcher is a compiled pattern
+type Matcher struct {
+	glob glob.Glob
+	path bool // Match the whole path rather than the name
+}
+
+// compiled caches matchers by pattern; rules are matched against every file
+var compiled sync.Map
+
+// Compile parses a pattern
+func Compile(pattern string) (*Matcher, error) {
+	if m, ok := compiled.Load(pattern); ok {
+		return m.(*Matcher), nil
+	}
+
+	// The glob parser indexes runes and panics on malformed UTF-8
+	if !utf8.ValidString(pattern) {
+		return nil, fmt.Errorf("invalid glob %q: not valid UTF-8", pattern)
+	}
+	if err := checkBraces(pattern); err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	g, err := glob.Compile(pattern, '/')
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	m := &Matcher{glob: g, path: IsPathPattern(pattern)}
+	compiled.Store(pattern, m)
+	return m, nil
+}
+
+// checkBraces catches unbalanced braces, which the glob parser accepts
+// silently and then never matches, and empty ones, which offer nothing to
+// choose from
+func checkBraces(pattern string) error {
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			if i+1 < len(pattern) && pattern[i+1] == '}' {
+				return fmt.Errorf("empty '{}'")
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				return fmt.Errorf("unexpected '}'")
+			}
+			depth--
+		}
+	}
+	if depth > 0 {
+		return fmt.Errorf("unclosed '{'")
+	}
+	return nil
+}
+
+// Match reports whether the file at path matches
+func (m *Matcher) Match(path string) (matched bool) {
+	// The glob matcher can index out of range on alternatives that are all
+	// empty, e.g. "c{,}"; such a pattern matches nothing rather than crashing
+	defer func() {
+		if recover() != nil {
+			matched = false
+		}
+	}()
+
+	if m.path {
+		return m.glob.Match(filepath.ToSlash(path))
+	}
+	return m.glob.Match(filepath.Base(path))
+}
+
+// Match reports whether the file at path matches pattern
+func Match(pattern, path string) (bool, error) {
+	m, err := Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return m.Match(path), nil
+}
+
+// IsPathPattern reports whether pattern is matched against whole paths
+// rather than file names
+func IsPathPattern(pattern string) bool {
+	return strings.Contains(pattern, "/")
+}
+
+// Validate reports whether pattern is a valid glob
+func Validate(pattern string) error {
+	_, err := Compile(pattern)
+	return err
+}
+
+// literalBraces finds braces without alternatives, which older versions of
+// sortd matched literally
+var literalBraces = regexp.MustCompile(`\{([^{},]*)\}`)
+
+// Migrate rewrites a pattern written for the older matcher so it means the
+// same thing here: [^abc] becomes [!abc], and braces without a comma, which
+// used to be literal, are escaped. Patterns that need no change are returned
+// as they are.
+func Migrate(pattern string) string {
+	migrated := strings.ReplaceAll(pattern, "[^", "[!")
+	return literalBraces.ReplaceAllString(migrated, `\{$1\}`)
+}
@@ -0,0 +1,106 @@
+package globs_test
+
+import (
+	"testing"
+
+	"sortd/internal/globs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.pdf", "/home/me/Downloads/report.pdf", true},
+		{"*.pdf", "/home/me/report.pdf/notes.txt", false},
+		{"*.{jpg,png}", "/tmp/cat.png", true},
+		{"*.{jpg,png}", "/tmp/cat.gif", false},
+		{"IMG_????.jpg", "/tmp/IMG_0042.jpg", true},
+		{"[!.]*", "/tmp/visible", true},
+		{"[!.]*", "/tmp/.hidden", false},
+		{"**/Screenshots/*.png", "/home/me/Pictures/Screenshots/s.png", true},
+		{"**/Screenshots/*.png", "/home/me/Pictures/Screenshots/old/s.png", false},
+		{"**/Screenshots/**", "/home/me/Pictures/Screenshots/old/s.png", true},
+		{"/home/*/Downloads/*.zip", "/home/me/Downloads/a.zip", true},
+		{"/home/*/Downloads/*.zip", "/home/me/x/Downloads/a.zip", false},
+	}
+	for _, tt := range tests {
+		got, err := globs.Match(tt.pattern, tt.path)
+		require.NoError(t, err, tt.pattern)
+		assert.Equal(t, tt.want, got, "%s vs %s", tt.pattern, tt.path)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, globs.Validate("*.{jpg,png}"))
+	assert.Error(t, globs.Validate("*.[jpg"))
+	assert.Error(t, globs.Validate("*.{jpg,png"))
+	assert.Error(t, globs.Validate("*.jpg}"))
+	assert.NoError(t, globs.Validate(`\{draft\}*`))
+	assert.Error(t, globs.Validate("c{}"))
+	assert.Error(t, globs.Validate("report\xff*"))
+
+	m, err := globs.Compile("c{,}")
+	require.NoError(t, err)
+	assert.NotPanics(t, func() { m.Match("c") })
+}
+
+func TestMigrate(t *testing.T) {
+	assert.Equal(t, "[!.]*", globs.Migrate("[^.]*"))
+	assert.Equal(t, `\{draft\}*.md`, globs.Migrate("{draft}*.md"))
+	assert.Equal(t, "*.{jpg,png}", globs.Migrate("*.{jpg,png}"))
+	assert.Equal(t, "*.pdf", globs.Migrate("*.pdf"))
+
+	// A migrated pattern still means what it used to
+	matched, err := globs.Match(globs.Migrate("{draft}*.md"), "/notes/{draft} plan.md")
+	require.NoError(t, err)
+	assert.True(t, matched)
+}
+
+func TestExample(t *testing.T) {
+	for _, pattern := range []string{"*.pdf", "IMG_????.{jpg,jpeg}", "[!.]*", "*.{tar.{gz,xz},zip}", `\{draft\}*.md`, "report[0-9].txt"} {
+		example, ok := globs.Example(pattern)
+		require.True(t, ok, pattern)
+		matched, err := globs.Match(pattern, example)
+		require.NoError(t, err)
+		assert.True(t, matched, "%s should match its example %s", pattern, example)
+	}
+
+	example, ok := globs.Example("*.pdf")
+	require.True(t, ok)
+	assert.Equal(t, "x.pdf", example)
+
+	_, ok = globs.Example("[!xyz0_]")
+	assert.False(t, ok)
+}
+
+func TestMatchRegex(t *testing.T) {
+	groups, matched, err := globs.MatchRegex(`(?P<year>\d{4})-(?P<month>\d{2})-.*\.jpg`, "/photos/2024-06-beach.jpg")
+	require.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, map[string]string{"year": "2024", "month": "06"}, groups)
+	assert.Equal(t, "Photos/2024/06/{day}", globs.ExpandGroups("Photos/{year}/{month}/{day}", groups))
+
+	// Captured values can't add directory levels
+	groups, matched, err = globs.MatchRegex(`/inbox/(?P<rest>.+)`, "/inbox/a/b.txt")
+	require.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, "Sorted/a_b.txt", globs.ExpandGroups("Sorted/{rest}", groups))
+}
+
+func TestValidateRegex(t *testing.T) {
+	assert.NoError(t, globs.ValidateRegex(`(?P<show>.+)\.mkv`, "TV/{show}"))
+	assert.Error(t, globs.ValidateRegex(`(?P<show>.+\.mkv`, "TV"))
+	assert.ErrorContains(t, globs.ValidateRegex(`(?P<show>.+)\.mkv`, "TV/{season}"), "{season}")
+}
+
+func TestAliasPaths(t *testing.T) {
+	assert.Equal(t, []string{"/in/photo.jpg", "/in/photo.jpe"}, globs.AliasPaths("/in/photo.jpeg"))
+	assert.Equal(t, []string{"/in/SCAN.TIFF"}, globs.AliasPaths("/in/SCAN.TIF"))
+	assert.Empty(t, globs.AliasPaths("/in/notes.txt"))
+	assert.Empty(t, globs.AliasPaths("/in/README"))
+}
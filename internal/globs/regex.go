@@ -0,0 +1,89 @@
+package globs
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// compiledRegexes caches anchored regular expressions by source
+var compiledRegexes sync.Map
+
+// groupRef matches {name} references to named capture groups in a target
+var groupRef = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// CompileRegex parses a regular expression rule. It must match the whole name
+// (or, if it contains a slash, the whole path), not just part of it.
+func CompileRegex(expr string) (*regexp.Regexp, error) {
+	if re, ok := compiledRegexes.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", expr, err)
+	}
+	compiledRegexes.Store(expr, re)
+	return re, nil
+}
+
+// MatchRegex matches the file at path against expr and returns the values of
+// its named capture groups
+func MatchRegex(expr, path string) (map[string]string, bool, error) {
+	re, err := CompileRegex(expr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	subject := filepath.Base(path)
+	if IsPathPattern(expr) {
+		subject = filepath.ToSlash(path)
+	}
+	submatches := re.FindStringSubmatch(subject)
+	if submatches == nil {
+		return nil, false, nil
+	}
+
+	groups := make(map[string]string)
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			groups[name] = submatches[i]
+		}
+	}
+	return groups, true, nil
+}
+
+// ExpandGroups replaces {name} in target with the named group's value.
+// Values can't add directory levels: separators in them become underscores.
+func ExpandGroups(target string, groups map[string]string) string {
+	return groupRef.ReplaceAllStringFunc(target, func(ref string) string {
+		value, ok := groups[groupRef.FindStringSubmatch(ref)[1]]
+		if !ok {
+			return ref
+		}
+		value = strings.TrimSpace(value)
+		return strings.NewReplacer("/", "_", `\`, "_").Replace(value)
+	})
+}
+
+// ValidateRegex checks a regular expression rule and that every {name} in its
+// target refers to one of its named groups
+func ValidateRegex(expr, target string) error {
+	re, err := CompileRegex(expr)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, name := range re.SubexpNames() {
+		names[name] = true
+	}
+	for _, ref := range groupRef.FindAllStringSubmatch(target, -1) {
+		if !names[ref[1]] {
+			return fmt.Errorf("target refers to {%s}, which isn't a named group in %q", ref[1], expr)
+		}
+	}
+	return nil
+}
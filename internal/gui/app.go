@@ -1,17 +1,20 @@
 package gui
 
 import (
+	"fmt"
 	"image/color"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"sortd/internal/app"
 	"sortd/internal/config"
+	"sortd/internal/errors"
 	"sortd/internal/log"
 	"sortd/internal/organize"
-	"sortd/internal/watch"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/app"
+	fyneapp "fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
@@ -27,9 +30,10 @@ type App struct {
 	mainWindow     fyne.Window
 	cfg            *config.Config
 	organizeEngine *organize.Engine
-	watchDaemon    *watch.Daemon
-	pathLabel      *widget.Label // Reference to the path display label
-	statusUpdater  func()        // Function to update system tray status
+	service        *app.Service   // Organizes and controls watch mode, as the CLI does
+	pathLabel      *widget.Label  // Reference to the path display label
+	statusUpdater  func()         // Function to update system tray status
+	windows        *windowManager // Windows opened besides the main one
 
 	// Track selected items in lists
 	selectedPatternIndex  int // Index of the selected pattern in the organize tab list
@@ -43,7 +47,7 @@ type App struct {
 // NewApp creates a new GUI application
 func NewApp(cfg *config.Config, organizeEngine *organize.Engine) *App {
 	// Create app with a unique ID for preferences storage
-	fyneApp := app.NewWithID("io.github.sortd")
+	fyneApp := fyneapp.NewWithID("io.github.sortd")
 
 	// Load the app icon
 	iconPath := "s.png"
@@ -60,19 +64,11 @@ func NewApp(cfg *config.Config, organizeEngine *organize.Engine) *App {
 		log.Warnf("Could not load app icon from %s: %v", iconPath, err)
 	}
 
-	// Create and start the watch daemon
-	watchDaemon, err := watch.NewDaemon(cfg)
-	if err != nil {
-		log.Errorf("Failed to create watch daemon: %v", err)
-		// Don't exit - GUI can still be used without daemon
-		watchDaemon = nil
-	}
-
 	a := &App{
 		fyneApp:               fyneApp,
 		cfg:                   cfg,
 		organizeEngine:        organizeEngine,
-		watchDaemon:           watchDaemon,
+		service:               app.NewWithEngine(cfg, organizeEngine),
 		selectedPatternIndex:  -1, // Initialize to -1 (no selection)
 		selectedWatchDirIndex: -1, // Initialize to -1 (no selection)
 		accentColor:           color.NRGBA{R: 255, G: 165, B: 0, A: 255},
@@ -81,6 +77,18 @@ func NewApp(cfg *config.Config, organizeEngine *organize.Engine) *App {
 
 	a.mainWindow = a.fyneApp.NewWindow("Sortd")
 
+	// Other windows go when the main one does, instead of lingering on their own
+	a.windows = newWindowManager(fyneApp)
+	a.mainWindow.SetOnClosed(a.windows.closeAll)
+
+	// Collisions under the "ask" strategy are decided in a dialog
+	organizeEngine.SetCollisionResolver(a.askCollision)
+
+	// Files and folders dropped on the window are organized by the rules
+	a.mainWindow.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		a.organizeDropped(uris)
+	})
+
 	if appIcon, err := fyne.LoadResourceFromPath(iconPath); err == nil {
 		a.mainWindow.SetIcon(appIcon)
 	} else {
@@ -99,19 +107,12 @@ func (a *App) GetMainWindow() fyne.Window {
 
 // IsDaemonRunning checks if the watch daemon is running
 func (a *App) IsDaemonRunning() bool {
-	if a.watchDaemon == nil {
-		return false
-	}
-	return a.watchDaemon.Status().Running
+	return a.service.WatchStatus().Running
 }
 
 // GetDaemonStatus returns the current daemon status
 func (a *App) GetDaemonStatus() string {
-	if a.watchDaemon == nil {
-		return "Daemon not initialized"
-	}
-	status := a.watchDaemon.Status()
-	if status.Running {
+	if a.service.WatchStatus().Running {
 		return "Running"
 	}
 	return "Stopped"
@@ -125,7 +126,7 @@ func (a *App) setupSystemTray() {
 
 		// Function to create/update the menu items
 		updateMenuFunc = func() []*fyne.MenuItem {
-			status := a.watchDaemon.Status()
+			status := a.service.WatchStatus()
 			items := []*fyne.MenuItem{
 				fyne.NewMenuItem("Show Sortd", func() {
 					a.mainWindow.Show()
@@ -147,6 +148,7 @@ func (a *App) setupSystemTray() {
 			}
 			items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Exit", func() {
 				a.stopWatchMode()
+				a.windows.closeAll()
 				a.fyneApp.Quit()
 			}))
 			return items
@@ -201,7 +203,7 @@ func (a *App) setupMainWindow() {
 	// Create the main toolbar
 	toolbar := widget.NewToolbar(
 		widget.NewToolbarAction(theme.DocumentCreateIcon(), func() {
-			NewWorkflowWizard(a).Show()
+			a.openWorkflowWizard()
 		}),
 		widget.NewToolbarSeparator(),
 		widget.NewToolbarAction(theme.ViewRefreshIcon(), func() {
@@ -221,6 +223,10 @@ func (a *App) setupMainWindow() {
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Organize", a.createOrganizeTab()),
 		container.NewTabItem("Workflows", a.createWorkflowsTab()),
+		container.NewTabItem("Pending", a.createPendingTab()),
+		container.NewTabItem("Focus", a.createFocusTab()),
+		container.NewTabItem("Inspector", a.createInspectorTab()),
+		container.NewTabItem("Logs", a.createLogsTab()),
 		container.NewTabItem("Cloud", a.createCloudTab()),
 		container.NewTabItem("Settings", a.createSettingsTab()),
 	)
@@ -247,23 +253,40 @@ func (a *App) setupMainWindow() {
 func (a *App) createStatusBar() fyne.CanvasObject {
 	daemonStatus := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{})
 
+	// Read-only mode pauses the watcher and every other sortd process
+	readOnlyCheck := widget.NewCheck("Read-only", nil)
+
 	// Update status text based on daemon state
 	updateStatusText := func() {
-		if a.watchDaemon == nil {
-			daemonStatus.SetText("Watch Daemon: Not initialized")
-			return
+		text := "Watch Daemon: Stopped"
+		if a.service.WatchStatus().Running {
+			text = "Watch Daemon: Running"
 		}
-
-		status := a.watchDaemon.Status()
-		if status.Running {
-			daemonStatus.SetText("Watch Daemon: Running")
-		} else {
-			daemonStatus.SetText("Watch Daemon: Stopped")
+		switch {
+		case a.cfg.Settings.Audit:
+			text += " (audit mode, nothing is changed)"
+		case a.service.ReadOnly():
+			text += " (read-only, nothing is moved)"
 		}
+		daemonStatus.SetText(text)
+		readOnlyCheck.SetChecked(a.service.ReadOnly())
 	}
 
 	// Initial update
 	updateStatusText()
+	if a.cfg.Settings.Audit {
+		// Audit mode is set in the config file and can't be lifted here
+		readOnlyCheck.Disable()
+	}
+	readOnlyCheck.OnChanged = func(on bool) {
+		if on == a.service.ReadOnly() {
+			return
+		}
+		if err := a.service.SetReadOnly(on); err != nil {
+			a.ShowError("Failed to change read-only mode", err)
+		}
+		updateStatusText()
+	}
 
 	// Create refresh button
 	refreshButton := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
@@ -273,6 +296,7 @@ func (a *App) createStatusBar() fyne.CanvasObject {
 	return container.NewHBox(
 		daemonStatus,
 		layout.NewSpacer(),
+		readOnlyCheck,
 		refreshButton,
 	)
 }
@@ -291,12 +315,35 @@ func (a *App) ShowError(title string, err error) {
 	if err == nil {
 		return
 	}
-	dialog.ShowError(err, a.mainWindow)
+
+	// Say which file failed, why and how to fix it, where that is known
+	dialog.ShowError(fmt.Errorf("%s", errorDetailsText(errors.Describe(err))), a.mainWindow)
 
 	// Also send to system notification
 	a.ShowNotification("Error: "+title, err.Error())
 }
 
+// errorDetailsText lays out what failed, on which file, the cause and the fix
+func errorDetailsText(d errors.Details) string {
+	lines := []string{d.Error}
+	if d.Path != "" {
+		lines = append(lines, "File: "+d.Path)
+	}
+	if d.Param != "" {
+		lines = append(lines, "Setting: "+d.Param)
+	}
+	if d.Rule != "" {
+		lines = append(lines, "Rule: "+d.Rule)
+	}
+	if d.Cause != "" {
+		lines = append(lines, "Cause: "+d.Cause)
+	}
+	if d.Suggestion != "" {
+		lines = append(lines, "", d.Suggestion)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // ShowInfo displays an information dialog
 func (a *App) ShowInfo(message string) {
 	dialog.ShowInformation("Information", message, a.mainWindow)
@@ -315,10 +362,10 @@ func (a *App) ShowNotification(title, content string) {
 
 // startWatchMode starts the watch mode
 func (a *App) startWatchMode() {
-	// Daemon uses config from initialization. Set dynamic options if needed.
-	a.watchDaemon.SetDryRun(a.cfg.Settings.DryRun)
+	// Watch with the dry-run setting currently shown in the GUI
+	a.service.SetDryRun(a.cfg.Settings.DryRun)
 
-	err := a.watchDaemon.Start()
+	err := a.service.StartWatch()
 	if err != nil {
 		a.ShowError("Failed to start watch mode", err)
 	} else {
@@ -332,11 +379,11 @@ func (a *App) startWatchMode() {
 
 // stopWatchMode stops the watch mode
 func (a *App) stopWatchMode() {
-	if !a.watchDaemon.Status().Running {
+	if !a.service.WatchStatus().Running {
 		return
 	}
 
-	a.watchDaemon.Stop()
+	a.service.StopWatch()
 	a.ShowInfo("Watch mode stopped.")
 	a.ShowNotification("Watch Mode", "Watch mode has been stopped")
 	if a.statusUpdater != nil {
@@ -398,7 +445,11 @@ func (a *App) createWorkflowsTab() fyne.CanvasObject {
 
 	// Create button actions
 	newButton := widget.NewButtonWithIcon("New Workflow", theme.ContentAddIcon(), func() {
-		NewWorkflowWizard(a).Show()
+		a.openWorkflowWizard()
+	})
+
+	importButton := widget.NewButtonWithIcon("Import Workflow…", theme.FolderOpenIcon(), func() {
+		a.importWorkflow()
 	})
 
 	editButton := widget.NewButtonWithIcon("Edit", theme.DocumentCreateIcon(), func() {
@@ -411,6 +462,14 @@ func (a *App) createWorkflowsTab() fyne.CanvasObject {
 		a.ShowInfo("Edit workflow functionality coming soon")
 	})
 
+	runButton := widget.NewButtonWithIcon("Run", theme.MediaPlayIcon(), func() {
+		if selectedWorkflowIndex < 0 || selectedWorkflowIndex >= len(a.cfg.Workflows) {
+			a.ShowInfo("Please select a workflow to run.")
+			return
+		}
+		a.chooseWorkflowRunTarget(a.cfg.Workflows[selectedWorkflowIndex].ID)
+	})
+
 	deleteButton := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), func() {
 		if selectedWorkflowIndex < 0 || selectedWorkflowIndex >= len(a.cfg.Workflows) {
 			a.ShowInfo("Please select a workflow to delete.")
@@ -465,14 +524,16 @@ func (a *App) createWorkflowsTab() fyne.CanvasObject {
 	// Create button container
 	buttonContainer := container.NewHBox(
 		newButton,
+		importButton,
 		layout.NewSpacer(),
+		runButton,
 		editButton,
 		toggleButton,
 		deleteButton,
 	)
 
 	// Create help text
-	helpText := widget.NewRichTextFromMarkdown("# Working with Workflows\n\nWorkflows allow you to automate file organization based on triggers and conditions.\n\n- **Create a new workflow** with the New Workflow button\n- **Edit a workflow** by selecting it and clicking Edit\n- **Enable/Disable a workflow** to control when it runs\n\nWorkflows are processed in order of priority.")
+	helpText := widget.NewRichTextFromMarkdown("# Working with Workflows\n\nWorkflows allow you to automate file organization based on triggers and conditions.\n\n- **Create a new workflow** with the New Workflow button\n- **Import a workflow** from a YAML file, checked and previewed before it's saved\n- **Edit a workflow** by selecting it and clicking Edit\n- **Run a workflow** on a file or folder now with the Run button\n- **Enable/Disable a workflow** to control when it runs\n\nWorkflows are processed in order of priority.")
 
 	helpCard := widget.NewCard("Help", "", helpText)
 
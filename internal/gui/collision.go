@@ -0,0 +1,52 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sortd/internal/organize"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// askCollision answers collisions under the "ask" collision strategy with a
+// dialog. It blocks until the user picks, so moves that may collide must run
+// off the UI goroutine. Closing the dialog skips the file.
+func (a *App) askCollision(src, dest string) (string, error) {
+	choice := make(chan string, 1)
+	answer := func(c string) {
+		select {
+		case choice <- c:
+		default:
+		}
+	}
+
+	var d dialog.Dialog
+	button := func(label, c string) *widget.Button {
+		return widget.NewButton(label, func() {
+			answer(c)
+			d.Hide()
+		})
+	}
+
+	message := widget.NewLabel(fmt.Sprintf("%s already exists in %s.\nWhat should happen to the new %s?",
+		filepath.Base(dest), filepath.Dir(dest), filepath.Base(src)))
+	message.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(
+		message,
+		container.NewHBox(
+			button("Keep Both", organize.CollisionRename),
+			button("Replace", organize.CollisionOverwrite),
+			button("Skip", organize.CollisionSkip),
+		),
+	)
+
+	d = dialog.NewCustomWithoutButtons("File Already Exists", content, a.mainWindow)
+	d.SetOnClosed(func() { answer(organize.CollisionSkip) })
+	d.Show()
+
+	return <-choice, nil
+}
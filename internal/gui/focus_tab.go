@@ -0,0 +1,290 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sortd/internal/app"
+	"sortd/internal/config"
+	"sortd/internal/focus"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// focusTimers are the session lengths offered in the focus tab
+var focusTimers = []string{"No timer", "5 minutes", "10 minutes", "15 minutes", "25 minutes"}
+
+// createFocusTab creates the tab for working through a messy directory a
+// small batch at a time
+func (a *App) createFocusTab() fyne.CanvasObject {
+	store, err := focus.Open(focus.DefaultPath(a.cfg))
+	if err != nil {
+		return widget.NewLabel(fmt.Sprintf("Focus mode is unavailable: %v", err))
+	}
+
+	var (
+		session   focus.Session
+		batch     []app.Move
+		remaining int // Files waiting after the current batch
+		skipped   = make(map[string]bool)
+		started   bool
+		stopTimer chan struct{}
+	)
+
+	dirEntry := widget.NewEntry()
+	dirEntry.SetPlaceHolder("Directory to clear, e.g. ~/Downloads")
+	dirEntry.SetText(a.cfg.Directories.Default)
+	browseButton := widget.NewButton("Browse...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			dirEntry.SetText(uri.Path())
+		}, a.mainWindow)
+	})
+
+	sizeSelect := widget.NewSelect([]string{"5", "10", "20"}, nil)
+	sizeSelect.SetSelected(strconv.Itoa(focus.DefaultBatchSize))
+	timerSelect := widget.NewSelect(focusTimers, nil)
+	timerSelect.SetSelected(focusTimers[0])
+
+	progressBar := widget.NewProgressBar()
+	progressLabel := widget.NewLabel("Pick a directory and start a session.")
+	streakLabel := widget.NewLabel("")
+	timerLabel := widget.NewLabel("")
+
+	showStreak := func() {
+		current, best := store.Streak(time.Now())
+		switch {
+		case current == 0:
+			streakLabel.SetText("No streak yet. Finish a batch today to start one.")
+		case current == best:
+			streakLabel.SetText(fmt.Sprintf("🔥 %d-day streak, your best yet", current))
+		default:
+			streakLabel.SetText(fmt.Sprintf("🔥 %d-day streak (best: %d)", current, best))
+		}
+	}
+
+	batchList := widget.NewList(
+		func() int {
+			return len(batch)
+		},
+		func() fyne.CanvasObject {
+			return container.NewVBox(
+				widget.NewLabelWithStyle("Template file name", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				widget.NewLabel("Template destination"),
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(batch) {
+				return
+			}
+
+			move := batch[id]
+			labels := obj.(*fyne.Container).Objects
+			labels[0].(*widget.Label).SetText(filepath.Base(move.Source))
+			if skipped[move.Source] {
+				labels[1].(*widget.Label).SetText("(skipped, stays where it is)")
+				return
+			}
+			labels[1].(*widget.Label).SetText("→ " + filepath.Dir(move.Destination))
+		},
+	)
+
+	// loadBatch plans the directory and picks the next batch of files
+	loadBatch := func() {
+		plan, err := a.service.PlanOrganize(context.Background(), session.Directory, app.PlanOptions{})
+		if err != nil {
+			a.ShowError("Failed to plan the directory", err)
+			return
+		}
+
+		waiting := session.Waiting(plan.Sources())
+		plan = plan.Only(focus.Batch(waiting, session.BatchSize))
+		batch = plan.Moves
+		remaining = len(waiting) - len(batch)
+		skipped = make(map[string]bool)
+
+		progressBar.SetValue(session.Progress(len(waiting)))
+		progressLabel.SetText(fmt.Sprintf("Batch %d: %d files here, %d more after this. %d done so far.",
+			session.Batches+1, len(batch), remaining, session.Done))
+		batchList.UnselectAll()
+		batchList.Refresh()
+		showStreak()
+
+		switch {
+		case len(batch) > 0:
+		case session.Done == 0:
+			progressLabel.SetText("Nothing here for your rules to organize.")
+		default:
+			a.celebrateFocus(store, session)
+		}
+	}
+
+	var selectedIndex = -1
+	batchList.OnSelected = func(id widget.ListItemID) {
+		selectedIndex = int(id)
+	}
+	batchList.OnUnselected = func(id widget.ListItemID) {
+		if selectedIndex == int(id) {
+			selectedIndex = -1
+		}
+	}
+
+	// startTimer counts down the chosen session length, if any
+	startTimer := func() {
+		if stopTimer != nil {
+			close(stopTimer)
+			stopTimer = nil
+		}
+		count, _, _ := strings.Cut(timerSelect.Selected, " ")
+		minutes, err := strconv.Atoi(count)
+		if err != nil {
+			timerLabel.SetText("")
+			return
+		}
+
+		stop := make(chan struct{})
+		stopTimer = stop
+		end := time.Now().Add(time.Duration(minutes) * time.Minute)
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				left := time.Until(end).Round(time.Second)
+				if left <= 0 {
+					timerLabel.SetText("⏰ Time's up")
+					dialog.ShowInformation("Time's Up",
+						"Nice work! Take a break, or start another round when you're ready.",
+						a.mainWindow)
+					return
+				}
+				timerLabel.SetText(fmt.Sprintf("⏱ %s left", left))
+				select {
+				case <-ticker.C:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	startButton := widget.NewButtonWithIcon("Start", theme.MediaPlayIcon(), func() {
+		dir := config.ExpandHome(dirEntry.Text)
+		if dir == "" {
+			a.ShowInfo("Please pick a directory to clear.")
+			return
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		size, _ := strconv.Atoi(sizeSelect.Selected)
+
+		var err error
+		session, err = store.Start(dir, size, time.Now())
+		if err != nil {
+			a.ShowError("Failed to start a focus session", err)
+			return
+		}
+		started = true
+		startTimer()
+		loadBatch()
+	})
+
+	skipButton := widget.NewButtonWithIcon("Skip", theme.MediaSkipNextIcon(), func() {
+		if selectedIndex < 0 || selectedIndex >= len(batch) {
+			a.ShowInfo("Please select a file to leave where it is.")
+			return
+		}
+		source := batch[selectedIndex].Source
+		skipped[source] = !skipped[source]
+		batchList.Refresh()
+	})
+
+	organizeButton := widget.NewButtonWithIcon("Organize Batch", theme.ConfirmIcon(), func() {
+		if !started || len(batch) == 0 {
+			a.ShowInfo("Start a session to get a batch of files.")
+			return
+		}
+
+		var sources, left []string
+		for _, move := range batch {
+			if skipped[move.Source] {
+				left = append(left, move.Source)
+			} else {
+				sources = append(sources, move.Source)
+			}
+		}
+
+		// Moving may ask about a collision, which can't block the UI goroutine
+		go func() {
+			plan := (&app.Plan{Moves: batch}).Only(sources)
+			results, err := a.service.Execute(context.Background(), plan)
+			if err != nil {
+				a.ShowError("Failed to organize the batch", err)
+			}
+
+			organized := 0
+			for _, result := range results {
+				if result.Error != nil {
+					// A failed file is left for a later batch rather than counted
+					a.ShowError("Failed to organize "+filepath.Base(result.SourcePath), result.Error)
+					continue
+				}
+				organized++
+			}
+
+			failed := len(sources) - organized
+			session, err = store.FinishBatch(session.Directory, organized, left, remaining+failed, time.Now())
+			if err != nil {
+				a.ShowError("Failed to save focus progress", err)
+				return
+			}
+			loadBatch()
+		}()
+	})
+
+	showStreak()
+
+	settings := widget.NewForm(
+		widget.NewFormItem("Directory", container.NewBorder(nil, nil, nil, browseButton, dirEntry)),
+		widget.NewFormItem("Files per batch", sizeSelect),
+		widget.NewFormItem("Timer", timerSelect),
+	)
+
+	return container.NewBorder(
+		container.NewVBox(
+			widget.NewLabelWithStyle("Focus Mode", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			widget.NewLabel("Clear a messy directory a few files at a time. Progress is saved, so you can stop whenever you like."),
+			settings,
+			container.NewHBox(startButton, layout.NewSpacer(), timerLabel),
+			progressBar,
+			progressLabel,
+			streakLabel,
+		),
+		container.NewHBox(skipButton, layout.NewSpacer(), organizeButton),
+		nil,
+		nil,
+		container.NewScroll(batchList),
+	)
+}
+
+// celebrateFocus congratulates the user on clearing a directory
+func (a *App) celebrateFocus(store *focus.Store, session focus.Session) {
+	current, _ := store.Streak(time.Now())
+	message := fmt.Sprintf("🎉 %s is clear!\n\n%d files in %d batches.",
+		filepath.Base(session.Directory), session.Done, session.Batches)
+	if current > 1 {
+		message += fmt.Sprintf("\nYou're on a %d-day streak.", current)
+	}
+	dialog.ShowInformation("All Done", message, a.mainWindow)
+}
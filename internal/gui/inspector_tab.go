@@ -0,0 +1,196 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sortd/internal/inspect"
+	"sortd/internal/units"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// relatedLimit is how many related files the inspector lists
+const relatedLimit = 8
+
+// createInspectorTab creates the tab showing everything sortd knows about one file
+func (a *App) createInspectorTab() fyne.CanvasObject {
+	pathEntry := widget.NewEntry()
+	pathEntry.SetPlaceHolder("File to inspect")
+
+	details := container.NewVBox(widget.NewLabel("Choose a file to see how sortd sees it."))
+
+	var inspectPath func(path string)
+	inspectPath = func(path string) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			a.ShowInfo("Please choose a file to inspect.")
+			return
+		}
+		pathEntry.SetText(path)
+
+		report, err := inspect.Inspect(a.cfg, path)
+		if err != nil {
+			a.ShowError("Failed to inspect file", err)
+			return
+		}
+
+		relatedBox := container.NewVBox(widget.NewLabel("Looking for related files…"))
+		details.Objects = []fyne.CanvasObject{
+			fileInfoCard(report),
+			widget.NewCard("Keywords", "", keywordCloud(report.Keywords)),
+			widget.NewCard("Classifications", "", classificationList(report)),
+			widget.NewCard("Related Files", "Similar in meaning, from the embeddings index", relatedBox),
+			widget.NewCard("Last Touched", "", widget.NewLabel(lastTouchedText(report.LastTouched))),
+		}
+		details.Refresh()
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			matches, err := inspect.Related(ctx, a.cfg, report.Path, relatedLimit)
+			var rows []fyne.CanvasObject
+			switch {
+			case err != nil:
+				rows = append(rows, widget.NewLabel(fmt.Sprintf("Unavailable: %v", err)))
+			case len(matches) == 0:
+				rows = append(rows, widget.NewLabel("No related files in the index."))
+			}
+			for _, match := range matches {
+				target := match.Path
+				rows = append(rows, container.NewBorder(nil, nil, nil,
+					widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
+						inspectPath(target)
+					}),
+					widget.NewLabel(fmt.Sprintf("%.0f%%  %s", match.Score*100, target)),
+				))
+			}
+			relatedBox.Objects = rows
+			relatedBox.Refresh()
+		}()
+	}
+
+	browseButton := widget.NewButtonWithIcon("Browse", theme.FolderOpenIcon(), func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+			inspectPath(reader.URI().Path())
+		}, a.mainWindow)
+	})
+	inspectButton := widget.NewButtonWithIcon("Inspect", theme.SearchIcon(), func() {
+		inspectPath(pathEntry.Text)
+	})
+	pathEntry.OnSubmitted = inspectPath
+
+	return container.NewBorder(
+		container.NewVBox(
+			widget.NewLabelWithStyle("File Inspector", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			container.NewBorder(nil, nil, nil, container.NewHBox(browseButton, inspectButton), pathEntry),
+		),
+		nil,
+		nil,
+		nil,
+		container.NewVScroll(details),
+	)
+}
+
+// fileInfoCard shows the detected type, size and metadata of the file
+func fileInfoCard(report *inspect.Report) fyne.CanvasObject {
+	info := report.Info
+	form := widget.NewForm(
+		widget.NewFormItem("Name", widget.NewLabel(filepath.Base(report.Path))),
+		widget.NewFormItem("MIME type", widget.NewLabel(info.ContentType)),
+		widget.NewFormItem("Size", widget.NewLabel(units.FormatSize(info.Size))),
+		widget.NewFormItem("Modified", widget.NewLabel(info.ModTime.Format("2006-01-02 15:04"))),
+	)
+	if len(info.Tags) > 0 {
+		form.Append("Tags", widget.NewLabel(strings.Join(info.Tags, ", ")))
+	}
+
+	keys := make([]string, 0, len(info.Metadata))
+	for key := range info.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		form.Append(key, widget.NewLabel(info.Metadata[key]))
+	}
+
+	return widget.NewCard("File", report.Path, form)
+}
+
+// keywordCloud draws the keywords with the most frequent ones largest
+func keywordCloud(keywords []inspect.Keyword) fyne.CanvasObject {
+	if len(keywords) == 0 {
+		return widget.NewLabel("No keywords found.")
+	}
+
+	maxCount := keywords[0].Count
+	base := theme.TextSize()
+	words := make([]fyne.CanvasObject, 0, len(keywords))
+	for _, keyword := range keywords {
+		text := canvas.NewText(keyword.Word, theme.ForegroundColor())
+		text.TextSize = base + base*float32(keyword.Count)/float32(maxCount)
+		text.TextStyle = fyne.TextStyle{Bold: keyword.Count == maxCount}
+		words = append(words, text)
+	}
+	return container.NewGridWrap(fyne.NewSize(base*9, base*2.5), words...)
+}
+
+// classificationList shows each classification's score against its threshold
+func classificationList(report *inspect.Report) fyne.CanvasObject {
+	if len(report.Classifications) == 0 {
+		return widget.NewLabel("No classifications are configured.")
+	}
+
+	rows := container.NewVBox()
+	for _, result := range report.Classifications {
+		bar := widget.NewProgressBar()
+		bar.SetValue(result.Score)
+		label := result.Classification
+		if result.Matched {
+			label += " ✓"
+		}
+		rows.Add(container.NewBorder(nil, nil,
+			widget.NewLabelWithStyle(label, fyne.TextAlignLeading, fyne.TextStyle{Bold: result.Matched}),
+			widget.NewLabel(fmt.Sprintf("needs %.0f%%", result.Threshold*100)),
+			bar,
+		))
+	}
+	return rows
+}
+
+// lastTouchedText describes the last rule or workflow that moved the file
+func lastTouchedText(touch *inspect.Touch) string {
+	if touch == nil {
+		return "sortd hasn't moved this file."
+	}
+
+	by := "a rule"
+	switch {
+	case touch.Rule != "":
+		by = fmt.Sprintf("rule %s", touch.Rule)
+	case touch.Workflow != "":
+		by = fmt.Sprintf("workflow %q", touch.Workflow)
+	case touch.Source == "workflow":
+		by = "a workflow"
+	}
+
+	text := fmt.Sprintf("Moved by %s on %s", by, touch.Time.Format("2006-01-02 15:04"))
+	if touch.From != "" {
+		text += fmt.Sprintf("\nfrom %s", touch.From)
+	}
+	return text
+}
@@ -0,0 +1,152 @@
+package gui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"sortd/internal/logview"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// logsTabEntries is how many of the latest matching entries the logs tab shows
+	logsTabEntries = 500
+	// logsTabInterval is how often the logs tab looks for new entries
+	logsTabInterval = 2 * time.Second
+	// allLevels is the level choice that filters nothing
+	allLevels = "All levels"
+)
+
+// createLogsTab creates the tab that tails sortd's structured log, filtered
+// by level and text, to see why a file wasn't organized
+func (a *App) createLogsTab() fyne.CanvasObject {
+	path, err := logview.DefaultPath()
+	if err != nil {
+		return widget.NewLabel(fmt.Sprintf("The log is unavailable: %v", err))
+	}
+
+	var (
+		entries []logview.Entry
+		filter  logview.Filter
+		tail    *logview.Tail
+		mu      sync.Mutex // Guards the above against the polling below
+	)
+
+	statusLabel := widget.NewLabel("")
+	logList := widget.NewList(
+		func() int {
+			return len(entries)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabelWithStyle("Template log entry", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+			label.Truncation = fyne.TextTruncateEllipsis
+			return container.NewBorder(nil, nil, widget.NewIcon(theme.InfoIcon()), nil, label)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(entries) {
+				return
+			}
+
+			entry := entries[id]
+			objects := obj.(*fyne.Container).Objects
+			objects[0].(*widget.Label).SetText(entry.String())
+			objects[1].(*widget.Icon).SetResource(logLevelIcon(entry.Level))
+		},
+	)
+	// The whole entry, fields and all, is a click away
+	logList.OnSelected = func(id widget.ListItemID) {
+		if id >= 0 && id < len(entries) {
+			a.ShowInfo(entries[id].String())
+		}
+		logList.UnselectAll()
+	}
+
+	// reload reads the latest matching entries again, after the filter changes
+	reload := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		tail = logview.NewTail(path)
+		read, err := logview.Read(path, filter, logsTabEntries)
+		if err != nil {
+			statusLabel.SetText(fmt.Sprintf("Failed to read %s: %v", path, err))
+			return
+		}
+		entries = read
+		statusLabel.SetText(fmt.Sprintf("%d entries from %s", len(entries), path))
+		logList.Refresh()
+		logList.ScrollToBottom()
+	}
+
+	levelSelect := widget.NewSelect(append([]string{allLevels}, logview.Levels...), func(level string) {
+		mu.Lock()
+		filter.Level = level
+		if level == allLevels {
+			filter.Level = ""
+		}
+		mu.Unlock()
+		reload()
+	})
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search messages and fields, e.g. a file name")
+	searchEntry.OnChanged = func(text string) {
+		mu.Lock()
+		filter.Search = text
+		mu.Unlock()
+		reload()
+	}
+	refreshButton := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), reload)
+
+	levelSelect.SetSelected(allLevels)
+
+	// Entries the app and its watch daemon write show up as they happen
+	go func() {
+		ticker := time.NewTicker(logsTabInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			mu.Lock()
+			added, err := tail.Next()
+			if err != nil {
+				mu.Unlock()
+				continue
+			}
+			matched := false
+			for _, entry := range added {
+				if filter.Match(entry) {
+					entries = append(entries, entry)
+					matched = true
+				}
+			}
+			if len(entries) > logsTabEntries {
+				entries = entries[len(entries)-logsTabEntries:]
+			}
+			count := len(entries)
+			mu.Unlock()
+			if !matched {
+				continue
+			}
+
+			statusLabel.SetText(fmt.Sprintf("%d entries from %s", count, path))
+			logList.Refresh()
+			logList.ScrollToBottom()
+		}
+	}()
+
+	filters := container.NewBorder(nil, nil, levelSelect, refreshButton, searchEntry)
+	return container.NewBorder(filters, statusLabel, nil, nil, logList)
+}
+
+// logLevelIcon marks entries by severity
+func logLevelIcon(level string) fyne.Resource {
+	switch level {
+	case "ERROR", "FATAL":
+		return theme.ErrorIcon()
+	case "WARN":
+		return theme.WarningIcon()
+	}
+	return theme.InfoIcon()
+}
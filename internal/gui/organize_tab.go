@@ -1,17 +1,21 @@
 package gui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"sortd/internal/app"
+	"sortd/internal/organize"
 	"sortd/pkg/types"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -24,88 +28,86 @@ func (a *App) createOrganizeTab() fyne.CanvasObject {
 		a.cfg.Directories.Default = path
 	}
 
+	dirPreview := widget.NewLabel("Select a directory above to see contents")
+	a.pathLabel = widget.NewLabel("")
+
+	// showDirectory selects a directory to organize and previews its contents
+	showDirectory := func(path string) {
+		if pathEntry.Text != path {
+			pathEntry.SetText(path)
+		}
+		a.cfg.Directories.Default = path
+
+		a.pathLabel.SetText(fmt.Sprintf("Location: %s", path))
+		preview, _ := refreshDirectoryPreview(path)
+		dirPreview.SetText(preview)
+	}
+
 	browseButton := widget.NewButton("Browse...", func() {
 		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
 			if err != nil || uri == nil {
 				return
 			}
-			path := uri.Path()
-			pathEntry.SetText(path)
-			a.cfg.Directories.Default = path
+			showDirectory(uri.Path())
 		}, a.mainWindow)
 	})
 
+	// Bookmarks are shared with 'sortd bookmark' and 'sortd go'
+	bookmarkSelect := widget.NewSelect(a.cfg.BookmarkNames(), nil)
+	bookmarkSelect.PlaceHolder = "Bookmarks"
+	bookmarkSelect.OnChanged = func(name string) {
+		if name == "" {
+			return
+		}
+		path, err := a.cfg.ResolveBookmark(name)
+		if err != nil {
+			a.ShowError("Bookmark not found", err)
+			return
+		}
+		bookmarkSelect.ClearSelected()
+		showDirectory(path)
+	}
+	bookmarkButton := widget.NewButtonWithIcon("", theme.ContentAddIcon(), func() {
+		dir := a.cfg.Directories.Default
+		if dir == "" {
+			a.ShowInfo("Please select a directory to bookmark.")
+			return
+		}
+		nameEntry := widget.NewEntry()
+		nameEntry.SetText(filepath.Base(dir))
+		dialog.ShowForm("Bookmark Directory", "Save", "Cancel",
+			[]*widget.FormItem{widget.NewFormItem("Name", nameEntry)},
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				if err := a.cfg.SetBookmark(nameEntry.Text, dir); err != nil {
+					a.ShowError("Invalid bookmark", err)
+					return
+				}
+				a.saveConfig()
+				bookmarkSelect.SetOptions(a.cfg.BookmarkNames())
+			},
+			a.mainWindow)
+	})
+
 	pathContainer := container.NewBorder(
-		nil, nil, nil, browseButton,
+		nil, nil, container.NewHBox(bookmarkSelect, bookmarkButton), browseButton,
 		pathEntry,
 	)
 
-	// Directory preview
-	dirPreview := widget.NewLabel("Select a directory above to see contents")
-	a.pathLabel = widget.NewLabel("")
-	if a.cfg.Directories.Default != "" {
-		a.pathLabel.SetText(fmt.Sprintf("Location: %s", a.cfg.Directories.Default))
-		// Update preview
-		files, err := filepath.Glob(filepath.Join(a.cfg.Directories.Default, "*"))
-		if err == nil && len(files) > 0 {
-			sb := strings.Builder{}
-			sb.WriteString("Directory Contents:\n")
-			for i, file := range files {
-				if i >= 10 {
-					sb.WriteString(fmt.Sprintf("... and %d more files\n", len(files)-10))
-					break
-				}
-				info, err := os.Stat(file)
-				if err == nil {
-					fileType := "File"
-					if info.IsDir() {
-						fileType = "Dir"
-					}
-					sb.WriteString(fmt.Sprintf("%s: %s (%d bytes)\n", fileType, filepath.Base(file), info.Size()))
-				}
-			}
-			dirPreview.SetText(sb.String())
-		} else if err == nil {
-			dirPreview.SetText("Directory is empty")
-		} else {
-			dirPreview.SetText(fmt.Sprintf("Error reading directory: %v", err))
-		}
-	}
-
 	// Refresh button
 	refreshButton := widget.NewButton("Refresh Preview", func() {
 		if a.cfg.Directories.Default == "" {
 			return
 		}
-
-		a.pathLabel.SetText(fmt.Sprintf("Location: %s", a.cfg.Directories.Default))
-		// Update preview
-		files, err := filepath.Glob(filepath.Join(a.cfg.Directories.Default, "*"))
-		if err == nil && len(files) > 0 {
-			sb := strings.Builder{}
-			sb.WriteString("Directory Contents:\n")
-			for i, file := range files {
-				if i >= 10 {
-					sb.WriteString(fmt.Sprintf("... and %d more files\n", len(files)-10))
-					break
-				}
-				info, err := os.Stat(file)
-				if err == nil {
-					fileType := "File"
-					if info.IsDir() {
-						fileType = "Dir"
-					}
-					sb.WriteString(fmt.Sprintf("%s: %s (%d bytes)\n", fileType, filepath.Base(file), info.Size()))
-				}
-			}
-			dirPreview.SetText(sb.String())
-		} else if err == nil {
-			dirPreview.SetText("Directory is empty")
-		} else {
-			dirPreview.SetText(fmt.Sprintf("Error reading directory: %v", err))
-		}
+		showDirectory(a.cfg.Directories.Default)
 	})
 
+	if a.cfg.Directories.Default != "" {
+		showDirectory(a.cfg.Directories.Default)
+	}
+
 	// Create organization patterns list
 	patternData := make([]string, 0, len(a.cfg.Organize.Patterns))
 	for _, pattern := range a.cfg.Organize.Patterns {
@@ -240,6 +242,26 @@ func (a *App) createOrganizeTab() fyne.CanvasObject {
 		patternsList.Refresh()
 	})
 
+	developerPresetButton := widget.NewButton("Developer", func() {
+		a.applyBuiltinPreset("developer")
+		// Refresh the pattern list
+		patternData = make([]string, 0, len(a.cfg.Organize.Patterns))
+		for _, pattern := range a.cfg.Organize.Patterns {
+			patternData = append(patternData, fmt.Sprintf("%s -> %s", pattern.Match, pattern.Target))
+		}
+		patternsList.Refresh()
+	})
+
+	quickSetupButton := widget.NewButton("Quick Setup…", func() {
+		a.showQuickSetup(func() {
+			patternData = make([]string, 0, len(a.cfg.Organize.Patterns))
+			for _, pattern := range a.cfg.Organize.Patterns {
+				patternData = append(patternData, fmt.Sprintf("%s -> %s", pattern.Match, pattern.Target))
+			}
+			patternsList.Refresh()
+		})
+	})
+
 	// AI-powered organization
 	aiOrganizerInput := widget.NewMultiLineEntry()
 	aiOrganizerInput.SetPlaceHolder("e.g., Organize all my photos into a Photos folder")
@@ -268,37 +290,23 @@ func (a *App) createOrganizeTab() fyne.CanvasObject {
 			return
 		}
 
-		// Set the engine's dry run mode from the config
-		a.organizeEngine.SetDryRun(a.cfg.Settings.DryRun)
+		// Set the dry run mode from the config
+		a.service.SetDryRun(a.cfg.Settings.DryRun)
 
-		// Run organization
-		results, err := a.organizeEngine.OrganizeDirectory(a.cfg.Directories.Default)
-		if err != nil {
-			a.ShowError("Organization Failed", err)
-			return
-		}
-
-		// Count successful and failed operations
-		var movedCount, errorCount int
-		for _, result := range results {
-			if result.Error != nil {
-				errorCount++
-			} else if result.Moved {
-				movedCount++
+		// Run organization off the UI goroutine, which "ask" collision dialogs need
+		go func() {
+			results, err := a.service.Organize(context.Background(), a.cfg.Directories.Default, app.PlanOptions{})
+			if err != nil {
+				a.ShowError("Organization Failed", err)
+				return
 			}
-		}
 
-		// Show results
-		if errorCount > 0 {
-			a.ShowError("Organization Partially Completed", fmt.Errorf("moved %d files, encountered %d errors", movedCount, errorCount))
-		} else if a.cfg.Settings.DryRun {
-			a.ShowInfo(fmt.Sprintf("Dry run complete. Would organize %d files.", movedCount))
-		} else {
-			a.ShowInfo(fmt.Sprintf("Organization complete. %d files organized.", movedCount))
-		}
+			// Summarise the run, with the details and a way to take it back
+			a.showOrganizeSummary(results, a.service.DryRun(), refreshButton.OnTapped)
 
-		// Refresh the directory preview
-		refreshButton.OnTapped()
+			// Refresh the directory preview
+			refreshButton.OnTapped()
+		}()
 	})
 
 	// Watch mode toggle button
@@ -332,6 +340,8 @@ func (a *App) createOrganizeTab() fyne.CanvasObject {
 		docsPresetButton,
 		videosPresetButton,
 		audioPresetButton,
+		developerPresetButton,
+		quickSetupButton,
 	)
 
 	// Create the layout for the organize tab
@@ -415,35 +425,71 @@ func (a *App) applyPresetRule(ruleType string) {
 	a.ShowInfo("Added '" + ruleType + "' preset rule.")
 }
 
+// applyBuiltinPreset adds the patterns of one of the organize package's
+// built-in presets, such as developer, that aren't configured yet
+func (a *App) applyBuiltinPreset(name string) {
+	preset, ok := organize.PresetNamed(name)
+	if !ok {
+		a.ShowError("Invalid Preset", fmt.Errorf("unknown preset: %s", name))
+		return
+	}
+
+	before := a.cfg.Organize.Patterns
+	a.cfg.Organize.Patterns = append([]types.Pattern(nil), before...)
+	added := organize.AddPreset(a.cfg, preset)
+	if added == 0 {
+		a.ShowInfo("The '" + name + "' preset is already configured.")
+		return
+	}
+	if err := a.cfg.Validate(); err != nil {
+		a.cfg.Organize.Patterns = before
+		a.ShowError("Invalid Preset", err)
+		return
+	}
+
+	a.saveConfig()
+
+	a.ShowInfo(fmt.Sprintf("Added %d rules from the '%s' preset.", added, name))
+}
+
+// organizeDropped organizes files and folders dropped on the window, then
+// shows the summary card for all of them together
+func (a *App) organizeDropped(uris []fyne.URI) {
+	a.service.SetDryRun(a.cfg.Settings.DryRun)
+
+	// Off the UI goroutine, which "ask" collision dialogs need
+	go func() {
+		var results []types.OrganizeResult
+		for _, uri := range uris {
+			if uri.Scheme() != "file" {
+				continue
+			}
+			dropped, err := a.service.Organize(context.Background(), uri.Path(), app.PlanOptions{})
+			if err != nil {
+				// Keep going, and list the drop that failed among the rest
+				results = append(results, types.OrganizeResult{SourcePath: uri.Path(), Error: err})
+				continue
+			}
+			results = append(results, dropped...)
+		}
+		a.showOrganizeSummary(results, a.service.DryRun(), nil)
+	}()
+}
+
 // handleNaturalLanguageCommand processes natural language commands
 func (a *App) handleNaturalLanguageCommand(command string) {
 	lowerCmd := strings.ToLower(command)
 
 	if strings.Contains(lowerCmd, "organize") {
-		a.organizeEngine.SetDryRun(a.cfg.Settings.DryRun)
-		results, err := a.organizeEngine.OrganizeDirectory(a.cfg.Directories.Default)
-		if err != nil {
-			a.ShowError("Natural Language Organize Failed", err)
-		} else {
-			var movedCount, errorCount int
-			var errors []string
-			for _, res := range results {
-				if res.Error != nil {
-					errorCount++
-					errors = append(errors, fmt.Sprintf("%s: %v", filepath.Base(res.SourcePath), res.Error))
-				} else if res.Moved {
-					movedCount++
-				}
-			}
-			msg := fmt.Sprintf("Organization complete. %d files processed/moved.", movedCount)
-			if errorCount > 0 {
-				errorMsg := fmt.Sprintf("Encountered %d errors:\\n%s", errorCount, strings.Join(errors, "\\n"))
-				msg += "\\n" + errorMsg
-				a.ShowError("Organization encountered errors", fmt.Errorf(strings.Join(errors, "\\n"))) // Show first error
-			} else {
-				a.ShowInfo(msg)
+		a.service.SetDryRun(a.cfg.Settings.DryRun)
+		go func() {
+			results, err := a.service.Organize(context.Background(), a.cfg.Directories.Default, app.PlanOptions{})
+			if err != nil {
+				a.ShowError("Natural Language Organize Failed", err)
+				return
 			}
-		}
+			a.showOrganizeSummary(results, a.service.DryRun(), nil)
+		}()
 	} else if strings.Contains(lowerCmd, "watch") {
 		if strings.Contains(lowerCmd, "start") {
 			a.startWatchMode()
@@ -0,0 +1,231 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sortd/internal/learning"
+	"sortd/internal/pending"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createPendingTab creates the tab for reviewing moves staged during the training period
+func (a *App) createPendingTab() fyne.CanvasObject {
+	queue, queueErr := pending.Open(pending.DefaultPath(a.cfg))
+	store, storeErr := learning.Open(learning.DefaultPath(a.cfg))
+	if queueErr != nil || storeErr != nil {
+		err := queueErr
+		if err == nil {
+			err = storeErr
+		}
+		return widget.NewLabel(fmt.Sprintf("Pending moves are unavailable: %v", err))
+	}
+
+	var items []pending.Item
+	statusLabel := widget.NewLabel("")
+	suggestionsLabel := widget.NewLabel("")
+	suggestionsLabel.Wrapping = fyne.TextWrapWord
+
+	pendingList := widget.NewList(
+		func() int {
+			return len(items)
+		},
+		func() fyne.CanvasObject {
+			return container.NewVBox(
+				widget.NewLabelWithStyle("Template file name", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				widget.NewLabel("Template destination"),
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(items) {
+				return
+			}
+
+			item := items[id]
+			labels := obj.(*fyne.Container).Objects
+			labels[0].(*widget.Label).SetText(filepath.Base(item.Path))
+			labels[1].(*widget.Label).SetText(fmt.Sprintf("→ %s   (rule %s, confidence %.0f%%, %s)",
+				filepath.Dir(item.Destination), item.Rule, store.Confidence(item.Rule)*100, item.Reason))
+		},
+	)
+
+	refresh := func() {
+		var err error
+		items, err = queue.List()
+		if err != nil {
+			a.ShowError("Failed to read pending moves", err)
+		}
+		statusLabel.SetText(a.trainingStatus(queue, len(items)))
+		suggestionsLabel.SetText(ruleSuggestionsText(store))
+		pendingList.UnselectAll()
+		pendingList.Refresh()
+	}
+
+	// Track selected index
+	var selectedIndex = -1
+	pendingList.OnSelected = func(id widget.ListItemID) {
+		selectedIndex = int(id)
+	}
+	pendingList.OnUnselected = func(id widget.ListItemID) {
+		if selectedIndex == int(id) {
+			selectedIndex = -1
+		}
+	}
+
+	resolve := func(approve bool) {
+		if selectedIndex < 0 || selectedIndex >= len(items) {
+			a.ShowInfo("Please select a pending move.")
+			return
+		}
+
+		// Approving may ask about a collision, which can't block the UI goroutine
+		item := items[selectedIndex]
+		go func() {
+			var err error
+			if approve {
+				err = queue.Approve(item.ID, a.organizeEngine.MoveFile, store)
+			} else {
+				err = queue.Reject(item.ID, store)
+			}
+			if err != nil {
+				a.ShowError("Failed to resolve pending move", err)
+			}
+			refresh()
+		}()
+	}
+
+	approveButton := widget.NewButtonWithIcon("Approve", theme.ConfirmIcon(), func() {
+		resolve(true)
+	})
+	rejectButton := widget.NewButtonWithIcon("Reject", theme.CancelIcon(), func() {
+		resolve(false)
+	})
+	approveAllButton := widget.NewButtonWithIcon("Approve All", theme.MailSendIcon(), func() {
+		dialog.ShowConfirm("Approve All",
+			fmt.Sprintf("Carry out all %d pending moves?", len(items)),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				go func() {
+					for _, item := range items {
+						if err := queue.Approve(item.ID, a.organizeEngine.MoveFile, store); err != nil {
+							a.ShowError("Failed to resolve pending move", err)
+							break
+						}
+					}
+					refresh()
+				}()
+			},
+			a.mainWindow)
+	})
+	refreshButton := widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), refresh)
+
+	// Training period controls
+	trainingCheck := widget.NewCheck("Stage automatic moves for review (training period)", nil)
+	trainingCheck.SetChecked(a.cfg.Settings.Training.Enabled)
+	daysEntry := widget.NewEntry()
+	daysEntry.SetText(strconv.Itoa(int(a.cfg.Settings.Training.Period().Hours() / 24)))
+	thresholdEntry := widget.NewEntry()
+	thresholdEntry.SetPlaceHolder("0 = always apply")
+	thresholdEntry.SetText(strconv.Itoa(int(a.cfg.Settings.AutoApplyConfidence * 100)))
+	saveTrainingButton := widget.NewButton("Save", func() {
+		days, err := strconv.Atoi(daysEntry.Text)
+		if err != nil || days <= 0 {
+			a.ShowError("Invalid training period", fmt.Errorf("days must be a positive number"))
+			return
+		}
+		threshold, err := strconv.Atoi(thresholdEntry.Text)
+		if err != nil || threshold < 0 || threshold > 100 {
+			a.ShowError("Invalid confidence threshold", fmt.Errorf("threshold must be a percentage from 0 to 100"))
+			return
+		}
+		a.cfg.Settings.Training.Enabled = trainingCheck.Checked
+		a.cfg.Settings.Training.Days = days
+		a.cfg.Settings.AutoApplyConfidence = float64(threshold) / 100
+		a.saveConfig()
+		refresh()
+	})
+
+	trainingCard := widget.NewCard("Training Period", "",
+		container.NewVBox(
+			trainingCheck,
+			widget.NewForm(
+				widget.NewFormItem("Days", daysEntry),
+				widget.NewFormItem("Auto-apply above confidence (%)", thresholdEntry),
+			),
+			saveTrainingButton,
+			statusLabel,
+		),
+	)
+
+	suggestionsCard := widget.NewCard("Rule Suggestions", "Learned from files you moved after sortd organized them", suggestionsLabel)
+
+	buttonContainer := container.NewHBox(
+		approveButton,
+		rejectButton,
+		layout.NewSpacer(),
+		approveAllButton,
+		refreshButton,
+	)
+
+	refresh()
+
+	return container.NewBorder(
+		widget.NewLabelWithStyle("Pending Moves", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		container.NewVBox(
+			buttonContainer,
+			trainingCard,
+			suggestionsCard,
+		),
+		nil,
+		nil,
+		container.NewScroll(pendingList),
+	)
+}
+
+// trainingStatus describes the state of the training period for the pending tab
+func (a *App) trainingStatus(queue *pending.Queue, count int) string {
+	training := a.cfg.Settings.Training
+	if !training.Enabled {
+		return fmt.Sprintf("Training is off; moves happen automatically. %d pending.", count)
+	}
+
+	started := training.Started
+	if started.IsZero() {
+		started = queue.TrainingStart()
+	}
+	if started.IsZero() {
+		return "Training starts when the watch daemon first handles a file."
+	}
+
+	ends := started.Add(training.Period())
+	if !training.ActiveAt(started, time.Now()) {
+		return fmt.Sprintf("Training ended %s. %d pending.", ends.Format("Jan 2"), count)
+	}
+	return fmt.Sprintf("Training until %s: %d moves waiting for review.", ends.Format("Jan 2"), count)
+}
+
+// ruleSuggestionsText lists the rule changes suggested by recorded corrections
+func ruleSuggestionsText(store *learning.Store) string {
+	suggestions := store.Suggestions()
+	if len(suggestions) == 0 {
+		return "No suggestions yet."
+	}
+
+	var b strings.Builder
+	for _, suggestion := range suggestions {
+		fmt.Fprintf(&b, "• %s\n", suggestion)
+	}
+	b.WriteString("Run 'sortd rules suggest --apply' to retarget these patterns.")
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package gui
+
+import (
+	"fmt"
+
+	"sortd/internal/organize"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showQuickSetup offers a destination for each of the most common extensions in
+// the default directory and turns the accepted ones into patterns. onDone runs
+// after the patterns change.
+func (a *App) showQuickSetup(onDone func()) {
+	dir := a.cfg.Directories.Default
+	exts, err := organize.TopExtensions(dir, organize.QuickSetupLimit)
+	if err != nil {
+		a.ShowError("Quick setup failed", err)
+		return
+	}
+	if len(exts) == 0 {
+		a.ShowInfo("No files with extensions found in " + dir)
+		return
+	}
+
+	checks := make([]*widget.Check, len(exts))
+	entries := make([]*widget.Entry, len(exts))
+	form := container.NewGridWithColumns(2)
+	for i, ext := range exts {
+		checks[i] = widget.NewCheck(fmt.Sprintf(".%s  (%d files)", ext.Extension, ext.Count), nil)
+		checks[i].SetChecked(ext.Destination != "")
+		entries[i] = widget.NewEntry()
+		entries[i].SetPlaceHolder("Destination folder")
+		entries[i].SetText(ext.Destination)
+
+		check := checks[i]
+		entries[i].OnChanged = func(text string) {
+			check.SetChecked(text != "")
+		}
+		form.Add(checks[i])
+		form.Add(entries[i])
+	}
+
+	content := container.NewBorder(
+		widget.NewLabel("Where should these go? Folders are relative to "+dir+"."),
+		nil, nil, nil,
+		container.NewVScroll(form),
+	)
+	d := dialog.NewCustomConfirm("Quick Setup", "Add Rules", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		var mapping []organize.ExtensionCount
+		for i, ext := range exts {
+			if !checks[i].Checked {
+				continue
+			}
+			ext.Destination = entries[i].Text
+			mapping = append(mapping, ext)
+		}
+
+		changed := organize.AddExtensionPatterns(a.cfg, mapping)
+		if changed == 0 {
+			a.ShowInfo("Your rules already cover these extensions.")
+			return
+		}
+		a.saveConfig()
+		if onDone != nil {
+			onDone()
+		}
+		a.ShowInfo(fmt.Sprintf("Added or updated %d rules.", changed))
+	}, a.mainWindow)
+	d.Resize(fyne.NewSize(560, 480))
+	d.Show()
+}
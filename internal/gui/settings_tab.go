@@ -27,6 +27,11 @@ func (a *App) createSettingsTab() fyne.CanvasObject {
 	})
 	backupCheck.SetChecked(a.cfg.Settings.Backup)
 
+	screenshotsCheck := widget.NewCheck("Rename Screenshots and File Them by Month", func(value bool) {
+		a.cfg.Settings.Screenshots.Enabled = value
+	})
+	screenshotsCheck.SetChecked(a.cfg.Settings.Screenshots.Enabled)
+
 	improvedCatCheck := widget.NewCheck("Use Improved Categorization", func(value bool) {
 		// Ignore - not in our config structure
 	})
@@ -49,6 +54,7 @@ func (a *App) createSettingsTab() fyne.CanvasObject {
 		dryRunCheck,
 		createDirsCheck,
 		backupCheck,
+		screenshotsCheck,
 		improvedCatCheck,
 		container.NewHBox(collisionLabel, collisionSelect),
 		container.NewHBox(defaultDirLabel, defaultDirEntry),
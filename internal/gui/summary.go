@@ -0,0 +1,143 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"sortd/pkg/types"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// summaryWidth is the width of the summary card shown after an organize run
+const summaryWidth = 420
+
+// organizeSummary tallies the results of an organize run
+type organizeSummary struct {
+	moved   int
+	skipped int // Left where they were, e.g. by collision handling
+	failed  int
+}
+
+// summarize counts moved, skipped and failed files
+func summarize(results []types.OrganizeResult) organizeSummary {
+	var s organizeSummary
+	for _, result := range results {
+		switch {
+		case result.Error != nil:
+			s.failed++
+		case result.Moved:
+			s.moved++
+		default:
+			s.skipped++
+		}
+	}
+	return s
+}
+
+// title is the card's headline. In a dry run nothing is moved, so every file
+// without an error is one that would be.
+func (s organizeSummary) title(dryRun bool) string {
+	if dryRun {
+		return fmt.Sprintf("Dry run: would move %d, %d errors", s.skipped, s.failed)
+	}
+	return fmt.Sprintf("%d moved, %d skipped, %d errors", s.moved, s.skipped, s.failed)
+}
+
+// resultLine describes what happened to one file
+func resultLine(result types.OrganizeResult, dryRun bool) string {
+	name := filepath.Base(result.SourcePath)
+	switch {
+	case result.Error != nil:
+		return fmt.Sprintf("✗ %s: %v", name, result.Error)
+	case result.Copied:
+		return fmt.Sprintf("✓ %s copied to %s", name, filepath.Dir(result.DestinationPath))
+	case result.Moved:
+		return fmt.Sprintf("✓ %s → %s", name, filepath.Dir(result.DestinationPath))
+	case dryRun:
+		return fmt.Sprintf("• %s would go to %s", name, filepath.Dir(result.DestinationPath))
+	default:
+		return fmt.Sprintf("– %s skipped", name)
+	}
+}
+
+// showOrganizeSummary shows a card in the corner of the main window with what
+// an organize run did, the per-file details on demand and an Undo button. It
+// stays until dismissed. onChange runs after an undo, e.g. to refresh a preview.
+func (a *App) showOrganizeSummary(results []types.OrganizeResult, dryRun bool, onChange func()) {
+	summary := summarize(results)
+	if len(results) == 0 {
+		a.ShowInfo("Nothing to organize: no files matched a rule.")
+		return
+	}
+
+	details := container.NewVBox()
+	for _, result := range results {
+		line := widget.NewLabel(resultLine(result, dryRun))
+		line.Wrapping = fyne.TextWrapWord
+		details.Add(line)
+	}
+	detailsScroll := container.NewVScroll(details)
+	detailsScroll.SetMinSize(fyne.NewSize(summaryWidth, 160))
+	accordion := widget.NewAccordion(widget.NewAccordionItem("Details", detailsScroll))
+
+	card := widget.NewCard(summary.title(dryRun), "", nil)
+	var popUp *widget.PopUp
+
+	undoButton := widget.NewButton("Undo", nil)
+	undoButton.OnTapped = func() {
+		undoButton.Disable()
+		card.SetSubTitle("Undoing…")
+		go func() {
+			undone, err := a.service.Undo(context.Background(), results)
+			if err != nil {
+				a.ShowError("Undo Failed", err)
+				return
+			}
+			back := summarize(undone)
+			if back.failed > 0 {
+				card.SetSubTitle(fmt.Sprintf("Put back %d files; %d couldn't be", back.moved, back.failed))
+				details.RemoveAll()
+				for _, result := range undone {
+					if result.Error != nil {
+						line := widget.NewLabel(resultLine(result, false))
+						line.Wrapping = fyne.TextWrapWord
+						details.Add(line)
+					}
+				}
+				accordion.Open(0)
+			} else {
+				card.SetSubTitle(fmt.Sprintf("Put back %d files", back.moved))
+			}
+			if onChange != nil {
+				onChange()
+			}
+		}()
+	}
+	if dryRun || summary.moved == 0 {
+		undoButton.Disable()
+	}
+
+	dismissButton := widget.NewButton("Dismiss", func() {
+		popUp.Hide()
+	})
+
+	card.SetContent(container.NewVBox(
+		accordion,
+		container.NewHBox(undoButton, layout.NewSpacer(), dismissButton),
+	))
+
+	c := a.mainWindow.Canvas()
+	popUp = widget.NewPopUp(card, c)
+	size := fyne.NewSize(summaryWidth, popUp.MinSize().Height)
+	popUp.Resize(size)
+	popUp.ShowAtPosition(fyne.NewPos(
+		c.Size().Width-size.Width-theme.Padding()*2,
+		c.Size().Height-size.Height-theme.Padding()*2,
+	))
+}
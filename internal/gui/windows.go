@@ -0,0 +1,79 @@
+package gui
+
+import (
+	"sync"
+
+	"fyne.io/fyne/v2"
+)
+
+// Keys of the windows opened besides the main one; each can be open once
+const (
+	workflowWizardWindow = "workflow-wizard"
+)
+
+// windowManager keeps track of the windows opened besides the main one, so
+// asking for one that is already open brings it to the front instead of
+// opening a duplicate, and closing the main window closes them all
+type windowManager struct {
+	app     fyne.App
+	mu      sync.Mutex
+	windows map[string]fyne.Window
+}
+
+// newWindowManager creates a manager for the app's windows
+func newWindowManager(app fyne.App) *windowManager {
+	return &windowManager{app: app, windows: make(map[string]fyne.Window)}
+}
+
+// focus shows and focuses the window open under key, reporting whether there
+// was one
+func (m *windowManager) focus(key string) bool {
+	m.mu.Lock()
+	window, ok := m.windows[key]
+	m.mu.Unlock()
+	if !ok {
+		return false
+	}
+	window.Show()
+	window.RequestFocus()
+	return true
+}
+
+// newWindow creates a window tracked under key until it closes. A window
+// already open under the key is closed first, so there is never more than one.
+func (m *windowManager) newWindow(key, title string) fyne.Window {
+	m.mu.Lock()
+	previous, ok := m.windows[key]
+	m.mu.Unlock()
+	if ok {
+		previous.Close()
+	}
+
+	window := m.app.NewWindow(title)
+	window.SetOnClosed(func() {
+		m.mu.Lock()
+		if m.windows[key] == window {
+			delete(m.windows, key)
+		}
+		m.mu.Unlock()
+	})
+
+	m.mu.Lock()
+	m.windows[key] = window
+	m.mu.Unlock()
+	return window
+}
+
+// closeAll closes every tracked window
+func (m *windowManager) closeAll() {
+	m.mu.Lock()
+	windows := make([]fyne.Window, 0, len(m.windows))
+	for _, window := range m.windows {
+		windows = append(windows, window)
+	}
+	m.mu.Unlock()
+
+	for _, window := range windows {
+		window.Close()
+	}
+}
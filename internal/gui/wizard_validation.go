@@ -0,0 +1,288 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"sortd/internal/config"
+	"sortd/internal/globs"
+	"sortd/pkg/types"
+	"sortd/pkg/workflow"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Types the wizard's selects offer, by label
+var (
+	wizardTriggerTypes = map[string]types.TriggerType{
+		"File Created":       types.FileCreated,
+		"File Modified":      types.FileModified,
+		"File Pattern Match": types.FilePatternMatch,
+		"Manual":             types.ManualTrigger,
+		"Scheduled":          types.ScheduledTrigger,
+		"Webhook":            types.WebhookTrigger,
+	}
+	wizardConditionTypes = map[string]types.ConditionType{
+		"File Size":       types.FileSizeCondition,
+		"File Type":       types.FileTypeCondition,
+		"File Name":       types.FileNameCondition,
+		"File Age":        types.FileAgeCondition,
+		"Last Opened":     types.LastOpenedCondition,
+		"Directory Count": types.DirectoryCountCondition,
+		"Custom":          types.CustomCondition,
+	}
+	wizardOperators = map[string]types.OperatorType{
+		"Equals":        types.Equals,
+		"Not Equals":    types.NotEquals,
+		"Contains":      types.Contains,
+		"Starts With":   types.StartsWith,
+		"Ends With":     types.EndsWith,
+		"Greater Than":  types.GreaterThan,
+		"Less Than":     types.LessThan,
+		"Matches Regex": types.MatchesRegex,
+	}
+	wizardActionTypes = map[string]types.ActionType{
+		"Move": types.MoveAction, "Move File": types.MoveAction,
+		"Copy": types.CopyAction, "Copy File": types.CopyAction,
+		"Rename": types.RenameAction, "Rename File": types.RenameAction,
+		"Tag": types.TagAction, "Tag File": types.TagAction,
+		"Delete": types.DeleteAction, "Delete File": types.DeleteAction,
+		"Execute": types.ExecuteAction, "Execute Command": types.ExecuteAction,
+	}
+)
+
+// conditionValueValidator checks a value entry against the condition the
+// selects currently describe
+func conditionValueValidator(conditionType, operator *widget.Select, unit *widget.Entry) func(string) error {
+	return func(value string) error {
+		return validateConditionValue(types.Condition{
+			Type:      wizardConditionTypes[conditionType.Selected],
+			Operator:  wizardOperators[operator.Selected],
+			Value:     value,
+			ValueUnit: unit.Text,
+		})
+	}
+}
+
+// usesVariables reports whether a value refers to workflow variables, which
+// can only be checked once the workflow is loaded
+func usesVariables(value string) bool {
+	return strings.Contains(value, "${")
+}
+
+// validatePatternField checks a trigger pattern as it is typed
+func validatePatternField(pattern string) error {
+	if strings.TrimSpace(pattern) == "" || usesVariables(pattern) {
+		return nil
+	}
+	return globs.Validate(pattern)
+}
+
+// validateScheduleField checks a cron schedule as it is typed
+func validateScheduleField(schedule string) error {
+	if strings.TrimSpace(schedule) == "" {
+		return nil
+	}
+	return workflow.ValidateSchedule(schedule)
+}
+
+// validateConditionValue checks a condition's value the way the workflow
+// will read it: sizes and ages must parse and regexes must compile
+func validateConditionValue(condition types.Condition) error {
+	if strings.TrimSpace(condition.Value) == "" {
+		return fmt.Errorf("enter a value")
+	}
+	if usesVariables(condition.Value) {
+		return nil
+	}
+	if condition.Operator == types.MatchesRegex {
+		if _, err := regexp.Compile(condition.Value); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+	return workflow.ValidateCondition(condition)
+}
+
+// needsTargetDir reports whether an action moves or copies into its target
+func needsTargetDir(actionType types.ActionType) bool {
+	return actionType == types.MoveAction || actionType == types.CopyAction
+}
+
+// checkTargetDir looks at a move or copy target. It reports missing when the
+// folder doesn't exist yet, which the wizard offers to fix, and an error for
+// targets that can't work as they are.
+func checkTargetDir(target string) (missing bool, err error) {
+	target = strings.TrimSpace(target)
+	switch {
+	case target == "":
+		return false, fmt.Errorf("enter a target folder")
+	case usesVariables(target) || workflow.HasPlaceholders(target):
+		return false, nil
+	}
+	target = config.ExpandHome(target)
+
+	info, err := os.Stat(target)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, fmt.Errorf("%s is a file, not a folder", target)
+	}
+	return false, nil
+}
+
+// targetDirValidator checks a target entry for actions of the type actionType
+// returns. A folder that doesn't exist yet passes; hint tells the user it will
+// be offered for creation.
+func targetDirValidator(actionType func() types.ActionType, hint *widget.Label) func(string) error {
+	return func(target string) error {
+		hint.SetText("")
+		if !needsTargetDir(actionType()) {
+			if strings.TrimSpace(target) == "" && actionType() != types.DeleteAction {
+				return fmt.Errorf("enter a target")
+			}
+			return nil
+		}
+		missing, err := checkTargetDir(target)
+		if missing {
+			hint.SetText(fmt.Sprintf("%s doesn't exist yet; you'll be offered to create it", target))
+		}
+		return err
+	}
+}
+
+// ensureTargetDir offers to create a missing move or copy target before the
+// action is added, calling add once the folder exists or the user chose to
+// have the workflow create it when it runs
+func (w *WorkflowWizard) ensureTargetDir(action types.Action, add func(types.Action)) {
+	if !needsTargetDir(action.Type) || action.Options["createTargetDir"] == "true" {
+		add(action)
+		return
+	}
+	missing, err := checkTargetDir(action.Target)
+	if err != nil {
+		w.app.ShowError("Invalid target", err)
+		return
+	}
+	if !missing {
+		add(action)
+		return
+	}
+
+	dialog.ShowConfirm("Create Folder?",
+		fmt.Sprintf("%s doesn't exist. Create it now?", action.Target),
+		func(create bool) {
+			if !create {
+				return
+			}
+			if err := os.MkdirAll(config.ExpandHome(action.Target), 0755); err != nil {
+				w.app.ShowError("Couldn't create folder", err)
+				return
+			}
+			add(action)
+		}, w.window)
+}
+
+// stepError returns what keeps the current step from being complete, or nil
+func (w *WorkflowWizard) stepError() error {
+	if w.currentStep >= len(w.steps) || w.steps[w.currentStep].validate == nil {
+		return nil
+	}
+	return w.steps[w.currentStep].validate()
+}
+
+// refreshNext enables Next only once the current step is complete, showing
+// what is missing otherwise
+func (w *WorkflowWizard) refreshNext() {
+	if w.nextButton == nil || w.stepHint == nil {
+		return
+	}
+	if err := w.stepError(); err != nil {
+		w.nextButton.Disable()
+		w.stepHint.SetText(err.Error())
+		return
+	}
+	w.nextButton.Enable()
+	w.stepHint.SetText("")
+}
+
+// validateTrigger checks the trigger step
+func (w *WorkflowWizard) validateTrigger() error {
+	if err := validateTriggerFields(w.workflowData.Trigger); err != nil {
+		return err
+	}
+	for i, trigger := range w.workflowData.Triggers {
+		if err := validateTriggerFields(trigger); err != nil {
+			return fmt.Errorf("trigger %d: %w", i+2, err)
+		}
+	}
+	return nil
+}
+
+// validateTriggerFields checks that a trigger has what its type needs
+func validateTriggerFields(trigger types.Trigger) error {
+	if trigger.Type == "" {
+		return fmt.Errorf("choose a trigger type")
+	}
+	if trigger.Type == types.FilePatternMatch && strings.TrimSpace(trigger.Pattern) == "" {
+		return fmt.Errorf("pattern triggers need a file pattern")
+	}
+	if err := validatePatternField(trigger.Pattern); err != nil {
+		return fmt.Errorf("file pattern: %w", err)
+	}
+	if trigger.Type == types.ScheduledTrigger {
+		if err := workflow.ValidateSchedule(trigger.Schedule); err != nil {
+			return fmt.Errorf("schedule: %w", err)
+		}
+	}
+	return nil
+}
+
+// describeTrigger summarises a trigger for lists, e.g. "scheduled @daily *.pdf"
+func describeTrigger(trigger types.Trigger) string {
+	parts := []string{string(trigger.Type)}
+	if trigger.Schedule != "" {
+		parts = append(parts, trigger.Schedule)
+	}
+	if trigger.Pattern != "" {
+		parts = append(parts, trigger.Pattern)
+	}
+	return strings.Join(parts, " ")
+}
+
+// validateConditions checks the conditions step, which matters when editing
+// a workflow whose conditions were written by hand
+func (w *WorkflowWizard) validateConditions() error {
+	for i, condition := range w.workflowData.Conditions {
+		if err := validateConditionValue(condition); err != nil {
+			return fmt.Errorf("condition %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validateActions checks the actions step
+func (w *WorkflowWizard) validateActions() error {
+	if len(w.workflowData.Actions) == 0 {
+		return fmt.Errorf("add at least one action")
+	}
+	for i, action := range w.workflowData.Actions {
+		if !needsTargetDir(action.Type) {
+			continue
+		}
+		missing, err := checkTargetDir(action.Target)
+		if err != nil {
+			return fmt.Errorf("action %d: %w", i+1, err)
+		}
+		if missing && action.Options["createTargetDir"] != "true" {
+			return fmt.Errorf("action %d: %s doesn't exist", i+1, action.Target)
+		}
+	}
+	return nil
+}
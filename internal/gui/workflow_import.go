@@ -0,0 +1,84 @@
+package gui
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"sortd/pkg/types"
+	"sortd/pkg/workflow"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// importWorkflow asks for a workflow YAML file, checks it the way the
+// workflow manager checks the workflows it loads, and opens it in the
+// wizard's review step with its preview. Finish saves it to the workflows
+// directory; Back leaves room for changes first.
+func (a *App) importWorkflow() {
+	if a.windows.focus(workflowWizardWindow) {
+		a.ShowInfo("Finish or close the workflow being edited before importing another.")
+		return
+	}
+
+	open := dialog.NewFileOpen(func(file fyne.URIReadCloser, err error) {
+		if err != nil {
+			a.ShowError("Import Failed", err)
+			return
+		}
+		if file == nil {
+			return // Canceled
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			a.ShowError("Import Failed", fmt.Errorf("failed to read %s: %w", file.URI().Name(), err))
+			return
+		}
+		imported, err := a.checkImportedWorkflow(data)
+		if err != nil {
+			a.ShowError("Invalid Workflow", fmt.Errorf("%s: %w", file.URI().Name(), err))
+			return
+		}
+
+		w := NewWorkflowWizard(a)
+		w.window.SetTitle("Import Workflow")
+		w.workflowData = imported
+		w.currentStep = len(w.steps) - 1
+		w.updateStepContent()
+		w.Show()
+	}, a.mainWindow)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml"}))
+	open.Show()
+}
+
+// checkImportedWorkflow parses and validates a workflow against the workflows
+// directory, whose shared variables and condition blocks it may use. An ID
+// that's taken gets a number appended, so importing never replaces a workflow.
+func (a *App) checkImportedWorkflow(data []byte) (types.Workflow, error) {
+	imported, err := workflow.ParseWorkflow(data)
+	if err != nil {
+		return imported, fmt.Errorf("not a workflow definition: %w", err)
+	}
+
+	dir, err := workflow.DefaultDir()
+	if err != nil {
+		return imported, fmt.Errorf("failed to locate workflows directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return imported, fmt.Errorf("failed to create workflows directory: %w", err)
+	}
+	manager, err := workflow.NewManager(dir)
+	if err != nil {
+		return imported, fmt.Errorf("failed to load workflows: %w", err)
+	}
+
+	if err := manager.Validate(imported); err != nil {
+		return imported, err
+	}
+	imported.ID = manager.UniqueID(imported.ID)
+	return imported, nil
+}
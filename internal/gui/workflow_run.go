@@ -0,0 +1,105 @@
+package gui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sortd/internal/manifest"
+	"sortd/internal/watch"
+	"sortd/pkg/workflow"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// chooseWorkflowRunTarget asks for a file or folder to run a workflow on by hand
+func (a *App) chooseWorkflowRunTarget(workflowID string) {
+	var chooser dialog.Dialog
+	fileButton := widget.NewButton("Choose File...", func() {
+		chooser.Hide()
+		dialog.ShowFileOpen(func(file fyne.URIReadCloser, err error) {
+			if err != nil || file == nil {
+				return
+			}
+			path := file.URI().Path()
+			file.Close()
+			a.runWorkflow(workflowID, path)
+		}, a.mainWindow)
+	})
+	folderButton := widget.NewButton("Choose Folder...", func() {
+		chooser.Hide()
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			a.runWorkflow(workflowID, uri.Path())
+		}, a.mainWindow)
+	})
+
+	chooser = dialog.NewCustom("Run Workflow", "Cancel", container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Run %s on a file, or on every file directly inside a folder.", workflowID)),
+		container.NewHBox(fileButton, folderButton),
+	), a.mainWindow)
+	chooser.Show()
+}
+
+// runWorkflow runs a workflow by hand on a file or folder, showing its
+// progress and then what it did
+func (a *App) runWorkflow(workflowID, path string) {
+	dir, err := workflow.DefaultDir()
+	if err != nil {
+		a.ShowError("Run Failed", fmt.Errorf("failed to locate workflows directory: %w", err))
+		return
+	}
+	manager, err := workflow.NewManager(dir)
+	if err != nil {
+		a.ShowError("Run Failed", fmt.Errorf("failed to load workflows: %w", err))
+		return
+	}
+	manager.SetDuplicates(a.cfg.Settings.Duplicates)
+	manager.SetRetry(a.cfg.Settings.Retry)
+	manager.SetManifests(manifest.New(a.cfg.Settings.Manifests))
+	manager.SetRemoteBandwidth(a.cfg.Settings.Priority.BandwidthLimit())
+	manager.SetMetadata(watch.WorkflowMetadata(a.cfg))
+	manager.SetDryRun(a.cfg.Settings.DryRun)
+
+	bar := widget.NewProgressBar()
+	status := widget.NewLabel("Starting...")
+	progress := dialog.NewCustomWithoutButtons("Running "+workflowID, container.NewVBox(status, bar), a.mainWindow)
+	progress.Show()
+
+	// Run off the UI goroutine so the progress bar can move
+	go func() {
+		run, err := manager.RunManual(workflowID, []string{path}, func(done, total int, file string) {
+			bar.SetValue(float64(done) / float64(total))
+			status.SetText(fmt.Sprintf("%d of %d: %s", done, total, filepath.Base(file)))
+		})
+		progress.Hide()
+		if err != nil {
+			a.ShowError("Run Failed", err)
+			return
+		}
+		dialog.ShowInformation("Workflow Run", manualRunSummary(run, a.cfg.Settings.DryRun), a.mainWindow)
+	}()
+}
+
+// manualRunSummary describes a manual run for the results dialog
+func manualRunSummary(run *workflow.ManualRun, dryRun bool) string {
+	var b strings.Builder
+	if dryRun {
+		b.WriteString("Dry run, nothing was changed.\n\n")
+	}
+	fmt.Fprintf(&b, "Ran on %d files: %d succeeded, %d failed.", len(run.Results), len(run.Results)-run.Failed(), run.Failed())
+	if len(run.Skipped) > 0 {
+		fmt.Fprintf(&b, "\n%d files skipped because they don't meet the workflow's conditions.", len(run.Skipped))
+	}
+	for _, result := range run.Results {
+		if !result.Success {
+			fmt.Fprintf(&b, "\n\n%s: %v", filepath.Base(result.FilePath), result.Error)
+		}
+	}
+	return b.String()
+}
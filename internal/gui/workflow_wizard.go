@@ -23,7 +23,8 @@ type WizardStep struct {
 	title       string
 	description string
 	content     fyne.CanvasObject
-	onNext      func() bool // Function to execute when moving to the next step, returns true if valid
+	onNext      func() bool  // Function to execute when moving to the next step, returns true if valid
+	validate    func() error // What keeps the step from being complete; Next stays disabled until it returns nil
 }
 
 // WorkflowWizard provides a step-by-step interface for creating workflows
@@ -47,6 +48,7 @@ type WorkflowWizard struct {
 	// Container for steps
 	contentContainer *fyne.Container
 	stepIndicator    *widget.Label
+	stepHint         *widget.Label // Why Next is disabled
 
 	// Workflow visualization
 	visualPreview *fyne.Container
@@ -62,7 +64,7 @@ type WorkflowWizard struct {
 func NewWorkflowWizard(app *App) *WorkflowWizard {
 	w := &WorkflowWizard{
 		app:         app,
-		window:      app.fyneApp.NewWindow("Create Workflow"),
+		window:      app.windows.newWindow(workflowWizardWindow, "Create Workflow"),
 		currentStep: 0,
 		workflowData: types.Workflow{
 			ID:          fmt.Sprintf("workflow-%d", time.Now().Unix()),
@@ -92,7 +94,14 @@ func NewWorkflowWizard(app *App) *WorkflowWizard {
 		}
 	})
 
+	w.stepHint = widget.NewLabel("")
+	w.stepHint.Importance = widget.DangerImportance
+
 	w.nextButton = widget.NewButtonWithIcon("Next", theme.NavigateNextIcon(), func() {
+		if err := w.stepError(); err != nil {
+			w.refreshNext()
+			return
+		}
 		// Run validation check for current step if available
 		if w.currentStep < len(w.steps) && w.steps[w.currentStep].onNext != nil {
 			if !w.steps[w.currentStep].onNext() {
@@ -131,36 +140,30 @@ func NewWorkflowWizard(app *App) *WorkflowWizard {
 		{
 			title:       "Basic Information",
 			description: "Enter the general information about this workflow",
-			onNext: func() bool {
-				// Validate name not empty
+			validate: func() error {
 				if strings.TrimSpace(w.workflowData.Name) == "" {
-					dialog.ShowError(fmt.Errorf("workflow name cannot be empty"), w.window)
-					return false
+					return fmt.Errorf("workflow name cannot be empty")
 				}
-				return true
+				if strings.TrimSpace(w.workflowData.ID) == "" {
+					return fmt.Errorf("workflow ID cannot be empty")
+				}
+				return nil
 			},
 		},
 		{
 			title:       "Triggers",
 			description: "Configure what will trigger this workflow",
-			onNext:      func() bool { return true }, // Always valid
+			validate:    w.validateTrigger,
 		},
 		{
 			title:       "Conditions",
 			description: "Add conditions to further refine when this workflow runs",
-			onNext:      func() bool { return true }, // Always valid
+			validate:    w.validateConditions,
 		},
 		{
 			title:       "Actions",
 			description: "Define what actions will be taken when triggered",
-			onNext: func() bool {
-				// Must have at least one action
-				if len(w.workflowData.Actions) == 0 {
-					dialog.ShowError(fmt.Errorf("workflow must have at least one action"), w.window)
-					return false
-				}
-				return true
-			},
+			validate:    w.validateActions,
 		},
 		{
 			title:       "Review",
@@ -292,6 +295,7 @@ func NewWorkflowWizard(app *App) *WorkflowWizard {
 			container.NewBorder(
 				nil, nil, nil, nil,
 				container.NewHBox(
+					w.stepHint,
 					layout.NewSpacer(),
 					w.cancelButton,
 					w.backButton,
@@ -313,6 +317,15 @@ func (w *WorkflowWizard) Show() {
 	w.window.Show()
 }
 
+// openWorkflowWizard shows the workflow wizard, bringing one already open to
+// the front instead of starting another
+func (a *App) openWorkflowWizard() {
+	if a.windows.focus(workflowWizardWindow) {
+		return
+	}
+	NewWorkflowWizard(a).Show()
+}
+
 // updateStepContent changes the content based on the current step
 func (w *WorkflowWizard) updateStepContent() {
 	// Update button states
@@ -349,6 +362,7 @@ func (w *WorkflowWizard) updateStepContent() {
 
 	w.contentContainer.Refresh()
 	w.updateVisualization()
+	w.refreshNext()
 }
 
 // createBasicInfoStep creates the basic workflow information step
@@ -363,12 +377,14 @@ func (w *WorkflowWizard) createBasicInfoStep() fyne.CanvasObject {
 	nameEntry.OnChanged = func(value string) {
 		w.workflowData.Name = value
 		w.updateVisualization()
+		w.refreshNext()
 	}
 
 	idEntry := widget.NewEntry()
 	idEntry.SetText(w.workflowData.ID)
 	idEntry.OnChanged = func(value string) {
 		w.workflowData.ID = value
+		w.refreshNext()
 	}
 
 	descEntry := widget.NewMultiLineEntry()
@@ -451,6 +467,7 @@ func (w *WorkflowWizard) createTriggerStep() fyne.CanvasObject {
 		case "Scheduled":
 			w.workflowData.Trigger.Type = types.ScheduledTrigger
 		}
+		w.refreshNext()
 	})
 
 	// Set initial selection based on current data
@@ -472,15 +489,19 @@ func (w *WorkflowWizard) createTriggerStep() fyne.CanvasObject {
 	patternEntry := widget.NewEntry()
 	patternEntry.SetText(w.workflowData.Trigger.Pattern)
 	patternEntry.SetPlaceHolder("e.g., *.{jpg,png,pdf}")
+	patternEntry.Validator = validatePatternField
 	patternEntry.OnChanged = func(value string) {
 		w.workflowData.Trigger.Pattern = value
+		w.refreshNext()
 	}
 
 	scheduleEntry := widget.NewEntry()
 	scheduleEntry.SetText(w.workflowData.Trigger.Schedule)
 	scheduleEntry.SetPlaceHolder("e.g., 0 * * * * (cron format)")
+	scheduleEntry.Validator = validateScheduleField
 	scheduleEntry.OnChanged = func(value string) {
 		w.workflowData.Trigger.Schedule = value
+		w.refreshNext()
 	}
 
 	helpText := widget.NewLabel("Pattern triggers use file glob patterns to match files.")
@@ -509,6 +530,92 @@ func (w *WorkflowWizard) createTriggerStep() fyne.CanvasObject {
 		),
 		helpText,
 		presetBox,
+		w.createExtraTriggers(),
+	)
+}
+
+// createExtraTriggers lists the workflow's further triggers, e.g. a nightly
+// schedule that sweeps up files the live trigger missed
+func (w *WorkflowWizard) createExtraTriggers() fyne.CanvasObject {
+	selected := -1
+	triggerList := widget.NewList(
+		func() int {
+			return len(w.workflowData.Triggers)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Trigger")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < 0 || id >= len(w.workflowData.Triggers) {
+				return
+			}
+			obj.(*widget.Label).SetText(describeTrigger(w.workflowData.Triggers[id]))
+		},
+	)
+	triggerList.OnSelected = func(id widget.ListItemID) { selected = int(id) }
+	triggerList.OnUnselected = func(id widget.ListItemID) {
+		if selected == int(id) {
+			selected = -1
+		}
+	}
+
+	typeSelect := widget.NewSelect([]string{"File Created", "File Modified", "File Pattern Match", "Scheduled", "Webhook"}, nil)
+	typeSelect.PlaceHolder = "Select trigger type..."
+
+	patternEntry := widget.NewEntry()
+	patternEntry.SetPlaceHolder("File pattern (optional)")
+	patternEntry.Validator = validatePatternField
+
+	scheduleEntry := widget.NewEntry()
+	scheduleEntry.SetPlaceHolder("Schedule, e.g. @daily")
+	scheduleEntry.Validator = validateScheduleField
+
+	changed := func() {
+		triggerList.Refresh()
+		w.updateVisualization()
+		w.refreshNext()
+	}
+
+	addButton := widget.NewButton("Add Trigger", func() {
+		trigger := types.Trigger{
+			Type:     wizardTriggerTypes[typeSelect.Selected],
+			Pattern:  strings.TrimSpace(patternEntry.Text),
+			Schedule: strings.TrimSpace(scheduleEntry.Text),
+		}
+		if err := validateTriggerFields(trigger); err != nil {
+			w.app.ShowError("Invalid Trigger", err)
+			return
+		}
+		w.workflowData.Triggers = append(w.workflowData.Triggers, trigger)
+		changed()
+
+		typeSelect.ClearSelected()
+		patternEntry.SetText("")
+		scheduleEntry.SetText("")
+	})
+
+	removeButton := widget.NewButton("Remove Selected", func() {
+		if selected < 0 || selected >= len(w.workflowData.Triggers) {
+			return
+		}
+		w.workflowData.Triggers = append(w.workflowData.Triggers[:selected], w.workflowData.Triggers[selected+1:]...)
+		triggerList.UnselectAll()
+		changed()
+	})
+
+	listScroll := container.NewVScroll(triggerList)
+	listScroll.SetMinSize(fyne.NewSize(0, 80))
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle("Also Trigger On", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel("Any one of these runs the workflow too."),
+		listScroll,
+		widget.NewForm(
+			widget.NewFormItem("Type", typeSelect),
+			widget.NewFormItem("File Pattern", patternEntry),
+			widget.NewFormItem("Schedule", scheduleEntry),
+		),
+		container.NewHBox(addButton, removeButton),
 	)
 }
 
@@ -566,6 +673,8 @@ func (w *WorkflowWizard) createConditionsStep() fyne.CanvasObject {
 		"File Type",
 		"File Name",
 		"File Age",
+		"Last Opened",
+		"Directory Count",
 	}
 
 	conditionTypeSelect := widget.NewSelect(conditionTypes, func(value string) {
@@ -590,17 +699,30 @@ func (w *WorkflowWizard) createConditionsStep() fyne.CanvasObject {
 	operatorSelect.PlaceHolder = "Select operator..."
 
 	valueEntry := widget.NewEntry()
-	valueEntry.SetPlaceHolder("Value")
+	valueEntry.SetPlaceHolder("Value, e.g. 10MB or 3 weeks")
 
 	unitEntry := widget.NewEntry()
 	unitEntry.SetPlaceHolder("Unit (e.g., MB, KB, days)")
 
+	// Re-check the value whenever what it is compared as changes
+	valueEntry.Validator = conditionValueValidator(conditionTypeSelect, operatorSelect, unitEntry)
+	conditionTypeSelect.OnChanged = func(value string) {
+		w.conditionType = value
+		_ = valueEntry.Validate()
+	}
+	operatorSelect.OnChanged = func(string) { _ = valueEntry.Validate() }
+	unitEntry.OnChanged = func(string) { _ = valueEntry.Validate() }
+
 	// Add button
 	addButton := widget.NewButton("Add Condition", func() {
 		if conditionTypeSelect.Selected == "" || operatorSelect.Selected == "" || valueEntry.Text == "" {
 			w.app.ShowError("Missing Fields", fmt.Errorf("please fill in all required fields"))
 			return
 		}
+		if err := valueEntry.Validate(); err != nil {
+			w.app.ShowError("Invalid Value", err)
+			return
+		}
 
 		var condType types.ConditionType
 		switch conditionTypeSelect.Selected {
@@ -616,6 +738,12 @@ func (w *WorkflowWizard) createConditionsStep() fyne.CanvasObject {
 		case "File Age":
 			condType = types.FileAgeCondition
 			fieldEntry.Text = "age"
+		case "Last Opened":
+			condType = types.LastOpenedCondition
+			fieldEntry.Text = "opened"
+		case "Directory Count":
+			// The field names the directory; empty counts the file's own
+			condType = types.DirectoryCountCondition
 		}
 
 		var opType types.OperatorType
@@ -647,6 +775,7 @@ func (w *WorkflowWizard) createConditionsStep() fyne.CanvasObject {
 		w.workflowData.Conditions = append(w.workflowData.Conditions, newCondition)
 		conditionList.Refresh()
 		w.updateVisualization()
+		w.refreshNext()
 
 		// Reset inputs
 		conditionTypeSelect.ClearSelected()
@@ -664,6 +793,7 @@ func (w *WorkflowWizard) createConditionsStep() fyne.CanvasObject {
 			)
 			conditionList.Refresh()
 			w.updateVisualization()
+			w.refreshNext()
 		}
 	})
 
@@ -766,13 +896,19 @@ func (w *WorkflowWizard) createActionsStep() fyne.CanvasObject {
 		"Execute Command",
 	}
 
+	targetEntry := widget.NewEntry()
+	targetEntry.SetPlaceHolder("Target path, name, or command")
+	targetHint := widget.NewLabel("")
+	targetHint.Importance = widget.WarningImportance
+
 	actionTypeSelect := widget.NewSelect(actionTypes, func(value string) {
 		w.actionType = value
+		_ = targetEntry.Validate()
 	})
 	actionTypeSelect.PlaceHolder = "Select action type..."
-
-	targetEntry := widget.NewEntry()
-	targetEntry.SetPlaceHolder("Target path, name, or command")
+	targetEntry.Validator = targetDirValidator(func() types.ActionType {
+		return wizardActionTypes[actionTypeSelect.Selected]
+	}, targetHint)
 
 	browseButton := widget.NewButton("Browse...", func() {
 		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
@@ -792,6 +928,10 @@ func (w *WorkflowWizard) createActionsStep() fyne.CanvasObject {
 			w.app.ShowError("Missing Fields", fmt.Errorf("please fill in all required fields"))
 			return
 		}
+		if err := targetEntry.Validate(); err != nil {
+			w.app.ShowError("Invalid Target", err)
+			return
+		}
 
 		var actionType types.ActionType
 		switch actionTypeSelect.Selected {
@@ -825,15 +965,18 @@ func (w *WorkflowWizard) createActionsStep() fyne.CanvasObject {
 			Options: options,
 		}
 
-		w.workflowData.Actions = append(w.workflowData.Actions, newAction)
-		actionList.Refresh()
-		w.updateVisualization()
-
-		// Reset inputs
-		actionTypeSelect.ClearSelected()
-		targetEntry.SetText("")
-		createDirCheck.SetChecked(false)
-		overwriteCheck.SetChecked(false)
+		w.ensureTargetDir(newAction, func(action types.Action) {
+			w.workflowData.Actions = append(w.workflowData.Actions, action)
+			actionList.Refresh()
+			w.updateVisualization()
+			w.refreshNext()
+
+			// Reset inputs
+			actionTypeSelect.ClearSelected()
+			targetEntry.SetText("")
+			createDirCheck.SetChecked(false)
+			overwriteCheck.SetChecked(false)
+		})
 	})
 
 	// Remove button (removes selected action)
@@ -845,6 +988,7 @@ func (w *WorkflowWizard) createActionsStep() fyne.CanvasObject {
 			)
 			actionList.Refresh()
 			w.updateVisualization()
+			w.refreshNext()
 		}
 	})
 
@@ -877,6 +1021,7 @@ func (w *WorkflowWizard) createActionsStep() fyne.CanvasObject {
 					widget.NewFormItem("Target", container.NewBorder(nil, nil, nil, browseButton, targetEntry)),
 				),
 			),
+			targetHint,
 			container.NewVBox(
 				createDirCheck,
 				overwriteCheck,
@@ -902,6 +1047,9 @@ func (w *WorkflowWizard) createReviewStep() fyne.CanvasObject {
 	idSummary := widget.NewLabel(fmt.Sprintf("ID: %s", w.workflowData.ID))
 	statusSummary := widget.NewLabel(fmt.Sprintf("Status: %s", map[bool]string{true: "Enabled", false: "Disabled"}[w.workflowData.Enabled]))
 	triggerSummary := widget.NewLabel(fmt.Sprintf("Trigger: %s", w.workflowData.Trigger.Type))
+	if extra := len(w.workflowData.Triggers); extra > 0 {
+		triggerSummary.SetText(fmt.Sprintf("Trigger: %s (+%d more)", w.workflowData.Trigger.Type, extra))
+	}
 	conditionsSummary := widget.NewLabel(fmt.Sprintf("Conditions: %d configured", len(w.workflowData.Conditions)))
 	actionsSummary := widget.NewLabel(fmt.Sprintf("Actions: %d configured", len(w.workflowData.Actions)))
 
@@ -939,14 +1087,12 @@ func (w *WorkflowWizard) saveWorkflow() {
 	}
 
 	// Get workflow directory from app config
-	home, err := os.UserHomeDir()
+	configDir, err := workflow.DefaultDir()
 	if err != nil {
-		w.app.ShowError("Error Saving Workflow", fmt.Errorf("failed to get home directory: %w", err))
+		w.app.ShowError("Error Saving Workflow", fmt.Errorf("failed to locate workflows directory: %w", err))
 		return
 	}
 
-	configDir := filepath.Join(home, ".config", "sortd", "workflows")
-
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		w.app.ShowError("Error Saving Workflow", fmt.Errorf("failed to create workflows directory: %w", err))
@@ -998,13 +1144,11 @@ func (w *WorkflowWizard) testWorkflow() {
 		file.Close()
 
 		// Create temporary workflow manager with dry run mode
-		home, err := os.UserHomeDir()
+		configDir, err := workflow.DefaultDir()
 		if err != nil {
-			w.app.ShowError("Test Error", fmt.Errorf("failed to get home directory: %w", err))
+			w.app.ShowError("Test Error", fmt.Errorf("failed to locate workflows directory: %w", err))
 			return
 		}
-
-		configDir := filepath.Join(home, ".config", "sortd", "workflows")
 		manager, err := workflow.NewManager(configDir)
 		if err != nil {
 			w.app.ShowError("Test Error", fmt.Errorf("failed to initialize workflow manager: %w", err))
@@ -1098,6 +1242,9 @@ func (w *WorkflowWizard) updateVisualization() {
 	if w.workflowData.Trigger.Schedule != "" {
 		w.visualPreview.Add(widget.NewLabel(fmt.Sprintf("  Schedule: %s", w.workflowData.Trigger.Schedule)))
 	}
+	for _, trigger := range w.workflowData.Triggers {
+		w.visualPreview.Add(widget.NewLabel(fmt.Sprintf("  Or: %s", describeTrigger(trigger))))
+	}
 
 	w.visualPreview.Add(widget.NewLabel("")) // Add spacing
 	w.visualPreview.Add(widget.NewSeparator())
@@ -1171,6 +1318,8 @@ func (w *WorkflowWizard) addNewCondition() {
 		"File Type",
 		"File Name",
 		"File Age",
+		"Last Opened",
+		"Directory Count",
 		"Custom",
 	}, nil)
 	conditionTypeSelect.PlaceHolder = "Select condition type..."
@@ -1198,17 +1347,22 @@ func (w *WorkflowWizard) addNewCondition() {
 	unitEntry := widget.NewEntry()
 	unitEntry.SetPlaceHolder("Unit (e.g., MB, KB, days)")
 
-	// Create a form for the dialog
-	form := widget.NewForm(
+	// Re-check the value whenever what it is compared as changes; Add stays
+	// disabled while it's invalid
+	valueEntry.Validator = conditionValueValidator(conditionTypeSelect, operatorSelect, unitEntry)
+	conditionTypeSelect.OnChanged = func(string) { _ = valueEntry.Validate() }
+	operatorSelect.OnChanged = func(string) { _ = valueEntry.Validate() }
+	unitEntry.OnChanged = func(string) { _ = valueEntry.Validate() }
+
+	items := []*widget.FormItem{
 		widget.NewFormItem("Condition Type", conditionTypeSelect),
 		widget.NewFormItem("Field", fieldEntry),
 		widget.NewFormItem("Operator", operatorSelect),
 		widget.NewFormItem("Value", valueEntry),
 		widget.NewFormItem("Unit (Optional)", unitEntry),
-	)
+	}
 
-	// Create a custom confirmation dialog
-	dialog.ShowCustomConfirm("Add New Condition", "Add", "Cancel", form, func(add bool) {
+	dialog.ShowForm("Add New Condition", "Add", "Cancel", items, func(add bool) {
 		if add {
 			// Create new condition based on form values
 			if conditionTypeSelect.Selected == "" {
@@ -1249,6 +1403,14 @@ func (w *WorkflowWizard) addNewCondition() {
 				if fieldEntry.Text == "" {
 					fieldEntry.SetText("age")
 				}
+			case "Last Opened":
+				condType = types.LastOpenedCondition
+				if fieldEntry.Text == "" {
+					fieldEntry.SetText("opened")
+				}
+			case "Directory Count":
+				// The field names the directory; empty counts the file's own
+				condType = types.DirectoryCountCondition
 			case "Custom":
 				condType = types.CustomCondition
 			default:
@@ -1320,16 +1482,23 @@ func (w *WorkflowWizard) addNewAction() {
 	commandEntry := widget.NewEntry()
 	commandEntry.SetPlaceHolder("Command to execute")
 
-	// Create a form for the dialog
-	form := widget.NewForm(
+	// Add stays disabled while the target can't work for the chosen action
+	targetHint := widget.NewLabel("")
+	targetHint.Importance = widget.WarningImportance
+	targetEntry.Validator = targetDirValidator(func() types.ActionType {
+		return wizardActionTypes[actionTypeSelect.Selected]
+	}, targetHint)
+	actionTypeSelect.OnChanged = func(string) { _ = targetEntry.Validate() }
+
+	items := []*widget.FormItem{
 		widget.NewFormItem("Action Type", actionTypeSelect),
 		widget.NewFormItem("Target", targetEntry),
+		widget.NewFormItem("", targetHint),
 		widget.NewFormItem("Format", formatEntry),
 		widget.NewFormItem("Command", commandEntry),
-	)
+	}
 
-	// Create a custom confirmation dialog
-	dialog.ShowCustomConfirm("Add New Action", "Add", "Cancel", form, func(add bool) {
+	dialog.ShowForm("Add New Action", "Add", "Cancel", items, func(add bool) {
 		if add {
 			// Validate inputs
 			if actionTypeSelect.Selected == "" {
@@ -1373,10 +1542,12 @@ func (w *WorkflowWizard) addNewAction() {
 			}
 
 			// Add the action and update the UI
-			w.workflowData.Actions = append(w.workflowData.Actions, newAction)
-			w.updateStepContent()
-			w.updateVisualization()
-			w.app.ShowInfo("The action has been added successfully.")
+			w.ensureTargetDir(newAction, func(action types.Action) {
+				w.workflowData.Actions = append(w.workflowData.Actions, action)
+				w.updateStepContent()
+				w.updateVisualization()
+				w.app.ShowInfo("The action has been added successfully.")
+			})
 		}
 	}, w.window)
 }
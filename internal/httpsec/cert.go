@@ -0,0 +1,122 @@
+package httpsec
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sortd/internal/atomicfile"
+)
+
+// Generated certificate files, kept in the state directory
+const (
+	certFileName = ".sortd-tls.crt"
+	keyFileName  = ".sortd-tls.key"
+)
+
+// certValidity is how long a generated certificate lasts; a new one is made
+// once it has expired or is about to
+const certValidity = 2 * 365 * 24 * time.Hour
+
+// renewBefore is how long before expiry a generated certificate is replaced
+const renewBefore = 30 * 24 * time.Hour
+
+// CertificatePaths returns where the generated certificate and key are kept
+func CertificatePaths(stateDir string) (certFile, keyFile string) {
+	return filepath.Join(stateDir, certFileName), filepath.Join(stateDir, keyFileName)
+}
+
+// ensureCertificate returns the generated certificate, making a new one when
+// there is none yet or it is about to expire
+func ensureCertificate(stateDir string) (string, string, error) {
+	certFile, keyFile := CertificatePaths(stateDir)
+	if certValid(certFile, time.Now()) {
+		if _, err := os.Stat(keyFile); err == nil {
+			return certFile, keyFile, nil
+		}
+	}
+	if err := generateCertificate(certFile, keyFile, time.Now()); err != nil {
+		return "", "", fmt.Errorf("failed to generate TLS certificate: %w", err)
+	}
+	return certFile, keyFile, nil
+}
+
+// certValid reports whether the certificate at path exists and isn't close to expiring
+func certValid(path string, now time.Time) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	return now.Add(renewBefore).Before(cert.NotAfter)
+}
+
+// generateCertificate writes a self-signed certificate for this machine's
+// names and addresses, and its key, readable only by the owner
+func generateCertificate(certFile, keyFile string, now time.Time) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"sortd"}, CommonName: "sortd"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		template.DNSNames = append(template.DNSNames, hostname, hostname+".local")
+	}
+	// The addresses other machines on the LAN reach this one by
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && !ipNet.IP.IsLinkLocalUnicast() {
+				template.IPAddresses = append(template.IPAddresses, ipNet.IP)
+			}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(certFile), 0755); err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
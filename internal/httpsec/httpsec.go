@@ -0,0 +1,190 @@
+// Package httpsec secures the daemon's network endpoints: which interface they
+// bind to, the bearer token callers present and TLS, with a self-signed
+// certificate generated on first use when none is configured.
+package httpsec
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"sortd/internal/config"
+)
+
+// DefaultBind is the host endpoints listen on when their address gives only a port
+const DefaultBind = "127.0.0.1"
+
+// Addr fills in the bind host for a listen address that only gives a port,
+// such as ":8484" or "8484". Addresses naming a host are kept as they are.
+func Addr(listen, bind string) string {
+	if bind == "" {
+		bind = DefaultBind
+	}
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		// A bare port
+		return net.JoinHostPort(bind, listen)
+	}
+	if host == "" {
+		return net.JoinHostPort(bind, port)
+	}
+	return listen
+}
+
+// IsLoopback reports whether a listen address only accepts connections from this machine
+func IsLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Authenticated reports whether callers of an endpoint must prove who they
+// are, with the endpoint's token or with a client certificate
+func Authenticated(token string, settings config.HTTPSettings) bool {
+	return token != "" || (settings.TLS && settings.ClientCAFile != "")
+}
+
+// Listen opens a listener on listen, with the bind host filled in, serving TLS
+// when the settings ask for it. stateDir holds the generated certificate.
+// Addresses off this machine are refused unless callers must authenticate,
+// with token (the endpoint's own or the shared one) or a client certificate,
+// or allow_insecure says to serve them anyway.
+func Listen(listen, token string, settings config.HTTPSettings, stateDir string) (net.Listener, error) {
+	addr := Addr(listen, settings.Bind)
+	if !IsLoopback(addr) && !Authenticated(token, settings) && !settings.AllowInsecure {
+		return nil, fmt.Errorf("%s would be reachable from the network by anyone; set watch_mode.http.token or client_ca_file, or allow_insecure to serve it anyway", addr)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.TLS {
+		return listener, nil
+	}
+
+	tlsConfig, err := ServerTLS(settings, stateDir)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return tls.NewListener(listener, tlsConfig), nil
+}
+
+// Scheme returns "https" when the settings serve TLS, "http" otherwise
+func Scheme(settings config.HTTPSettings) string {
+	if settings.TLS {
+		return "https"
+	}
+	return "http"
+}
+
+// ServerTLS returns the TLS configuration endpoints serve: the configured
+// certificate or the generated one, and client certificate checks when a
+// client CA is set
+func ServerTLS(settings config.HTTPSettings, stateDir string) (*tls.Config, error) {
+	certFile, keyFile := settings.CertFile, settings.KeyFile
+	if certFile == "" {
+		var err error
+		certFile, keyFile, err = ensureCertificate(stateDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	if settings.ClientCAFile != "" {
+		pool, err := loadPool(settings.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// ClientTLS returns the TLS configuration for calling the daemon's own
+// endpoints: it trusts the certificate they serve and presents the given
+// client certificate, if any, for mutual TLS
+func ClientTLS(settings config.HTTPSettings, stateDir, certFile, keyFile string) (*tls.Config, error) {
+	serverCert := settings.CertFile
+	if serverCert == "" {
+		serverCert, _ = CertificatePaths(stateDir)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	pool, err := loadPool(serverCert)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.RootCAs = pool
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// loadPool reads a PEM file of certificates into a pool
+func loadPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificates: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates in %s", path)
+	}
+	return pool, nil
+}
+
+// Authorized reports whether the request carries the bearer token. An empty
+// token authorizes every request.
+func Authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && Equal(given, token)
+}
+
+// Equal compares a presented token with the expected one in constant time
+func Equal(given, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// RequireToken answers 401 to requests without the bearer token
+func RequireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Authorized(r, token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="sortd"`)
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
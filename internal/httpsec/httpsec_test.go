@@ -0,0 +1,119 @@
+package httpsec_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/internal/httpsec"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddr(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:8484", httpsec.Addr(":8484", ""))
+	assert.Equal(t, "127.0.0.1:8484", httpsec.Addr("8484", ""))
+	assert.Equal(t, "0.0.0.0:8484", httpsec.Addr(":8484", "0.0.0.0"))
+	assert.Equal(t, "192.168.1.5:8484", httpsec.Addr("192.168.1.5:8484", "127.0.0.1"))
+	assert.Equal(t, "[::1]:8484", httpsec.Addr(":8484", "::1"))
+
+	assert.True(t, httpsec.IsLoopback("127.0.0.1:8484"))
+	assert.True(t, httpsec.IsLoopback("[::1]:8484"))
+	assert.True(t, httpsec.IsLoopback("localhost:8484"))
+	assert.False(t, httpsec.IsLoopback("0.0.0.0:8484"))
+	assert.False(t, httpsec.IsLoopback("[::]:8484"))
+}
+
+func TestListenRefusesOpenNetworkAddresses(t *testing.T) {
+	for name, test := range map[string]struct {
+		token    string
+		settings config.HTTPSettings
+		refused  bool
+	}{
+		"loopback without a token":    {settings: config.HTTPSettings{}},
+		"network without a token":     {settings: config.HTTPSettings{Bind: "0.0.0.0"}, refused: true},
+		"network with a token":        {token: "secret", settings: config.HTTPSettings{Bind: "0.0.0.0"}},
+		"client CA without TLS":       {settings: config.HTTPSettings{Bind: "0.0.0.0", ClientCAFile: "ca.crt"}, refused: true},
+		"network with allow_insecure": {settings: config.HTTPSettings{Bind: "0.0.0.0", AllowInsecure: true}},
+	} {
+		listener, err := httpsec.Listen(":0", test.token, test.settings, t.TempDir())
+		if test.refused {
+			assert.Error(t, err, name)
+			continue
+		}
+		require.NoError(t, err, name)
+		listener.Close()
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	handler := httpsec.RequireToken("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for header, want := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"secret":        http.StatusUnauthorized,
+		"Bearer secret": http.StatusNoContent,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, want, rec.Code, "Authorization %q", header)
+	}
+
+	// Without a token everything is let through
+	open := httpsec.RequireToken("", http.NotFoundHandler())
+	rec := httptest.NewRecorder()
+	open.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestGeneratedCertificate(t *testing.T) {
+	stateDir := t.TempDir()
+	settings := config.HTTPSettings{TLS: true}
+
+	listener, err := httpsec.Listen("127.0.0.1:0", "", settings, stateDir)
+	require.NoError(t, err)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	certFile, keyFile := httpsec.CertificatePaths(stateDir)
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the key should be private")
+
+	// The daemon's own clients trust the generated certificate
+	tlsConfig, err := httpsec.ClientTLS(settings, stateDir, "", "")
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Get("https://" + listener.Addr().String() + "/")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "hello", string(body))
+
+	// Other clients don't trust it
+	_, err = http.Get("https://" + listener.Addr().String() + "/")
+	assert.Error(t, err)
+
+	// The certificate is reused rather than regenerated
+	before, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	_, err = httpsec.ServerTLS(settings, stateDir)
+	require.NoError(t, err)
+	after, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
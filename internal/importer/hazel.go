@@ -0,0 +1,35 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrHazelArchive is returned for Hazel rule exports. Hazel saves rules as
+// keyed archives of its own classes, a format it doesn't document, so they
+// can't be translated reliably.
+var ErrHazelArchive = errors.New("Hazel rule exports (.hazelrules) are archives of Hazel's own undocumented format and can't be read; " +
+	"list the folders and extensions your rules sort by in a CSV file and use 'sortd rules import'")
+
+// Workflows translates the rules of another organizer in the file at path
+// into workflows. Configs of the organize tool are read; Hazel exports are
+// recognized and refused with ErrHazelArchive.
+func Workflows(path string) ([]Imported, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isHazelExport(path, data) {
+		return nil, ErrHazelArchive
+	}
+	return OrganizeTool(data)
+}
+
+// isHazelExport reports whether a file is a Hazel rule export: a binary
+// property list, usually named *.hazelrules
+func isHazelExport(path string, data []byte) bool {
+	return strings.EqualFold(filepath.Ext(path), ".hazelrules") || bytes.HasPrefix(data, []byte("bplist00"))
+}
@@ -0,0 +1,327 @@
+// Package importer reads rule sets exported from other file organizers, or
+// written by hand as spreadsheets, and turns them into sortd patterns and workflows.
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sortd/internal/globs"
+	"sortd/pkg/types"
+)
+
+// Formats of the files Rules reads
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Mapping is one imported rule: which files, and where they go. Columns of a
+// CSV file and keys of a JSON object have the field's name, or an alias.
+type Mapping struct {
+	Pattern     string   // A glob, or a regex when Type is regex
+	Extensions  []string // Extensions, used when there's no pattern
+	Destination string   // Target directory
+	Type        string   // "glob" (default) or "regex"
+	IgnoreCase  bool
+	Exclude     []string
+	Priority    int
+}
+
+// columnAliases maps the column and key names other tools and people use to
+// the Mapping field they fill
+var columnAliases = map[string]string{
+	"pattern": "pattern", "match": "pattern", "glob": "pattern", "filter": "pattern", "rule": "pattern",
+	"extension": "extensions", "extensions": "extensions", "ext": "extensions", "type_extension": "extensions",
+	"destination": "destination", "target": "destination", "folder": "destination", "dest": "destination", "to": "destination",
+	"type": "type", "pattern_type": "type",
+	"ignore_case": "ignore_case", "case_insensitive": "ignore_case",
+	"exclude": "exclude", "excludes": "exclude",
+	"priority": "priority",
+}
+
+// FormatOf guesses a file's format from its extension
+func FormatOf(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".tsv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("can't tell the format of %s; pass csv or json", path)
+}
+
+// Rules reads the mappings in the file at path, in the given format ("" to go
+// by the file's extension), and returns them as patterns
+func Rules(path, format string) ([]types.Pattern, error) {
+	if format == "" {
+		var err error
+		if format, err = FormatOf(path); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var mappings []Mapping
+	switch format {
+	case FormatCSV:
+		comma := ','
+		if strings.EqualFold(filepath.Ext(path), ".tsv") {
+			comma = '\t'
+		}
+		mappings, err = ParseCSV(file, comma)
+	case FormatJSON:
+		mappings, err = ParseJSON(file)
+	default:
+		return nil, fmt.Errorf("unknown format %q: must be csv or json", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Patterns(mappings)
+}
+
+// ParseCSV reads mappings from CSV with a header row naming the columns, e.g.
+// "extension,destination" or "pattern,destination,priority". Lists within a
+// cell are separated by ';' or '|', and extensions also by ',' or spaces.
+func ParseCSV(r io.Reader, comma rune) ([]Mapping, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = columnAliases[normalizeKey(name)]
+	}
+
+	var mappings []Mapping
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return mappings, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		var mapping Mapping
+		empty := true
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if i >= len(columns) || columns[i] == "" || value == "" {
+				continue
+			}
+			empty = false
+			if err := mapping.set(columns[i], value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if !empty {
+			mappings = append(mappings, mapping)
+		}
+	}
+}
+
+// ParseJSON reads mappings from a JSON array of objects, or an object holding
+// the array under "rules", "mappings" or "patterns". Keys are matched like CSV
+// columns, and lists may be arrays or separated strings.
+func ParseJSON(r io.Reader) ([]Mapping, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	var objects []map[string]any
+	if err := json.Unmarshal(raw, &objects); err != nil {
+		var wrapper map[string]json.RawMessage
+		if json.Unmarshal(raw, &wrapper) != nil {
+			return nil, fmt.Errorf("expected an array of rules: %w", err)
+		}
+		found := false
+		for _, key := range []string{"rules", "mappings", "patterns"} {
+			if list, ok := wrapper[key]; ok {
+				if err := json.Unmarshal(list, &objects); err != nil {
+					return nil, fmt.Errorf("%s: expected an array of rules: %w", key, err)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("expected an array of rules, or one under \"rules\"")
+		}
+	}
+
+	mappings := make([]Mapping, 0, len(objects))
+	for i, object := range objects {
+		var mapping Mapping
+		for key, value := range object {
+			field := columnAliases[normalizeKey(key)]
+			if field == "" {
+				continue
+			}
+			if err := mapping.set(field, jsonString(value)); err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i+1, err)
+			}
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// Patterns turns mappings into patterns, checking each one. Mappings by
+// extension match any file name ending in one of the extensions.
+func Patterns(mappings []Mapping) ([]types.Pattern, error) {
+	patterns := make([]types.Pattern, 0, len(mappings))
+	for i, mapping := range mappings {
+		pattern, err := mapping.ToPattern()
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// ToPattern returns the mapping as a pattern
+func (m Mapping) ToPattern() (types.Pattern, error) {
+	if m.Destination == "" {
+		return types.Pattern{}, fmt.Errorf("no destination")
+	}
+
+	match := m.Pattern
+	if match == "" {
+		var exts []string
+		for _, ext := range m.Extensions {
+			if ext = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(ext), "*"), "."); ext != "" {
+				exts = append(exts, ext)
+			}
+		}
+		switch len(exts) {
+		case 0:
+			return types.Pattern{}, fmt.Errorf("no pattern or extension")
+		case 1:
+			match = "*." + exts[0]
+		default:
+			match = "*.{" + strings.Join(exts, ",") + "}"
+		}
+	}
+
+	pattern := types.Pattern{
+		Match:      match,
+		Target:     m.Destination,
+		Type:       types.PatternType(strings.ToLower(m.Type)),
+		IgnoreCase: m.IgnoreCase,
+		Exclude:    m.Exclude,
+		Priority:   m.Priority,
+	}
+	switch pattern.Type {
+	case "", types.GlobPattern:
+		pattern.Type = ""
+		if err := globs.Validate(pattern.Match); err != nil {
+			return types.Pattern{}, err
+		}
+	case types.RegexPattern:
+		if err := globs.ValidateRegex(pattern.Match, pattern.Target); err != nil {
+			return types.Pattern{}, err
+		}
+	default:
+		return types.Pattern{}, fmt.Errorf("invalid type %q: must be glob or regex", m.Type)
+	}
+	for _, exclude := range pattern.Exclude {
+		if err := globs.Validate(exclude); err != nil {
+			return types.Pattern{}, fmt.Errorf("exclude: %w", err)
+		}
+	}
+	return pattern, nil
+}
+
+// set fills the field named by a column alias from a cell's text
+func (m *Mapping) set(field, value string) error {
+	switch field {
+	case "pattern":
+		m.Pattern = value
+	case "extensions":
+		m.Extensions = append(m.Extensions, splitList(value, ";|, ")...)
+	case "destination":
+		m.Destination = value
+	case "type":
+		m.Type = value
+	case "ignore_case":
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid ignore_case %q", value)
+		}
+		m.IgnoreCase = on
+	case "exclude":
+		m.Exclude = append(m.Exclude, splitList(value, ";|")...)
+	case "priority":
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid priority %q", value)
+		}
+		m.Priority = priority
+	}
+	return nil
+}
+
+// normalizeKey makes column names comparable: "Ignore Case" is ignore_case
+func normalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+}
+
+// splitList splits a cell holding several values at any of the separators,
+// e.g. "jpg; png" or "jpg|png"
+func splitList(value, separators string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return strings.ContainsRune(separators, r) }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// jsonString renders a decoded JSON value as cell text; arrays become ';'
+// separated lists
+func jsonString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, jsonString(item))
+		}
+		return strings.Join(items, ";")
+	}
+	return fmt.Sprint(value)
+}
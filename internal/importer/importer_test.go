@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules_CSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mappings.csv")
+	csv := `Extension,Destination,Priority
+# comments and blank lines are skipped
+
+pdf,~/Documents/PDFs,
+"jpg, .jpeg;png",~/Pictures,5
+`
+	require.NoError(t, os.WriteFile(path, []byte(csv), 0644))
+
+	patterns, err := Rules(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, []types.Pattern{
+		{Match: "*.pdf", Target: "~/Documents/PDFs"},
+		{Match: "*.{jpg,jpeg,png}", Target: "~/Pictures", Priority: 5},
+	}, patterns)
+}
+
+func TestParseJSON(t *testing.T) {
+	mappings, err := ParseJSON(strings.NewReader(`{"rules": [
+		{"match": "invoice_*.pdf", "target": "Finance", "exclude": ["*draft*"], "ignore_case": true},
+		{"pattern": "(?P<year>\\d{4})-.*\\.jpg", "type": "regex", "folder": "Photos/{year}", "comment": "unknown keys are ignored"}
+	]}`))
+	require.NoError(t, err)
+
+	patterns, err := Patterns(mappings)
+	require.NoError(t, err)
+	require.Len(t, patterns, 2)
+	assert.Equal(t, types.Pattern{Match: "invoice_*.pdf", Target: "Finance", Exclude: []string{"*draft*"}, IgnoreCase: true}, patterns[0])
+	assert.Equal(t, types.RegexPattern, patterns[1].Type)
+	assert.Equal(t, "Photos/{year}", patterns[1].Target)
+}
+
+func TestRules_Invalid(t *testing.T) {
+	_, err := Patterns([]Mapping{{Extensions: []string{"pdf"}}})
+	assert.ErrorContains(t, err, "no destination")
+
+	_, err = Patterns([]Mapping{{Destination: "Docs"}})
+	assert.ErrorContains(t, err, "no pattern or extension")
+
+	_, err = ParseCSV(strings.NewReader("pattern,target,priority\n*.pdf,Docs,high\n"), ',')
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = Rules("rules.xml", "")
+	assert.Error(t, err, "The format can't be told from the extension")
+}
@@ -0,0 +1,565 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"sortd/pkg/types"
+)
+
+// Imported is a rule of another organizer translated into a workflow, with
+// what couldn't be translated
+type Imported struct {
+	Workflow types.Workflow
+
+	// Filters and actions left out. A workflow missing any of them would do
+	// something other than the original, so it is imported disabled.
+	Unsupported []string
+
+	// Differences worth knowing that don't change which files are handled,
+	// e.g. that sortd watches its own directories
+	Notes []string
+}
+
+// Skipped reports whether nothing the rule does could be translated, so there
+// is no workflow to import
+func (i Imported) Skipped() bool {
+	return len(i.Workflow.Actions) == 0
+}
+
+// organizeRule is a rule of the organize tool (organize-tool on PyPI). The
+// keys of version 1 configs (folders, capitalized filter names) are accepted too.
+type organizeRule struct {
+	Name       string `yaml:"name"`
+	Enabled    *bool  `yaml:"enabled"`
+	Locations  any    `yaml:"locations"`
+	Folders    any    `yaml:"folders"`
+	Subfolders bool   `yaml:"subfolders"`
+	FilterMode string `yaml:"filter_mode"`
+	Targets    string `yaml:"targets"`
+	Filters    []any  `yaml:"filters"`
+	Actions    []any  `yaml:"actions"`
+}
+
+// OrganizeTool translates a config of the organize tool into workflows, one
+// per rule. Rules take files from the directories sortd watches, so their
+// locations are noted rather than translated.
+func OrganizeTool(data []byte) ([]Imported, error) {
+	var config struct {
+		Rules []organizeRule `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse organize config: %w", err)
+	}
+	if len(config.Rules) == 0 {
+		return nil, fmt.Errorf("no rules found; expected an organize config with a rules list")
+	}
+
+	imported := make([]Imported, 0, len(config.Rules))
+	for i, rule := range config.Rules {
+		imported = append(imported, translateOrganizeRule(i+1, rule))
+	}
+	return imported, nil
+}
+
+// translateOrganizeRule translates the nth rule
+func translateOrganizeRule(n int, rule organizeRule) Imported {
+	name := rule.Name
+	if name == "" {
+		name = fmt.Sprintf("Imported rule %d", n)
+	}
+	out := Imported{Workflow: types.Workflow{
+		ID:          fmt.Sprintf("organize-%d", n),
+		Name:        name,
+		Description: "Imported from organize",
+		Enabled:     rule.Enabled == nil || *rule.Enabled,
+		Trigger:     types.Trigger{Type: types.FileCreated},
+	}}
+	if slug := slugify(rule.Name); slug != "" {
+		out.Workflow.ID = "organize-" + slug
+	}
+
+	if locations := stringList(firstNonNil(rule.Locations, rule.Folders)); len(locations) > 0 {
+		out.Notes = append(out.Notes, fmt.Sprintf("runs on files in sortd's watch directories; organize looked in %s", strings.Join(locations, ", ")))
+	}
+	if rule.Targets == "dirs" {
+		out.Unsupported = append(out.Unsupported, "targets: dirs (workflows handle files)")
+	}
+	switch strings.ToLower(rule.FilterMode) {
+	case "", "all":
+	case "any":
+		if len(rule.Filters) > 1 {
+			out.Unsupported = append(out.Unsupported, "filter_mode: any (workflow conditions must all hold)")
+		}
+	default:
+		out.Unsupported = append(out.Unsupported, "filter_mode: "+rule.FilterMode)
+	}
+
+	for _, filter := range rule.Filters {
+		key, value := entry(filter)
+		conditions, err := organizeFilter(key, value)
+		if err != nil {
+			out.Unsupported = append(out.Unsupported, fmt.Sprintf("filter %s: %v", key, err))
+			continue
+		}
+		out.Workflow.Conditions = append(out.Workflow.Conditions, conditions...)
+	}
+
+	for _, action := range rule.Actions {
+		key, value := entry(action)
+		actions, note, err := organizeAction(key, value)
+		if err != nil {
+			out.Unsupported = append(out.Unsupported, fmt.Sprintf("action %s: %v", key, err))
+			continue
+		}
+		if note != "" {
+			out.Notes = append(out.Notes, note)
+		}
+		out.Workflow.Actions = append(out.Workflow.Actions, actions...)
+	}
+
+	if len(out.Unsupported) > 0 {
+		out.Workflow.Enabled = false
+		out.Workflow.Description += "; not translated: " + strings.Join(out.Unsupported, "; ")
+	}
+	return out
+}
+
+// organizeFilter translates a filter into conditions. Filters prefixed with
+// "not " are negated where sortd has the opposite operator.
+func organizeFilter(key string, value any) ([]types.Condition, error) {
+	negate := strings.HasPrefix(key, "not ")
+	key = strings.TrimPrefix(key, "not ")
+
+	var conditions []types.Condition
+	var err error
+	switch key {
+	case "extension":
+		conditions, err = extensionFilter(value)
+	case "name":
+		conditions, err = nameFilter(value)
+	case "regex":
+		expr, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected an expression")
+		}
+		if !strings.HasPrefix(expr, "^") {
+			expr = "^(?:" + expr + ")" // organize matches from the start of the name
+		}
+		conditions = []types.Condition{{Type: types.FileNameCondition, Field: "name", Operator: types.MatchesRegex, Value: expr}}
+	case "size":
+		conditions, err = sizeFilter(value)
+	case "lastmodified":
+		conditions, err = ageFilter(types.FileAgeCondition, value)
+	case "date_lastused":
+		conditions, err = ageFilter(types.LastOpenedCondition, value)
+	case "created":
+		return nil, fmt.Errorf("sortd ages go by modification time; use lastmodified")
+	default:
+		return nil, fmt.Errorf("no sortd equivalent")
+	}
+	if err != nil || !negate {
+		return conditions, err
+	}
+
+	if len(conditions) != 1 {
+		return nil, fmt.Errorf("can't be negated")
+	}
+	opposite := map[types.OperatorType]types.OperatorType{
+		types.Equals: types.NotEquals, types.NotEquals: types.Equals,
+		types.GreaterThan: types.LessThan, types.LessThan: types.GreaterThan,
+	}
+	op, ok := opposite[conditions[0].Operator]
+	if !ok {
+		return nil, fmt.Errorf("can't be negated")
+	}
+	conditions[0].Operator = op
+	return conditions, nil
+}
+
+// extensionFilter matches one or more extensions, in any case
+func extensionFilter(value any) ([]types.Condition, error) {
+	var exts []string
+	seen := make(map[string]bool)
+	for _, ext := range stringList(value) {
+		if ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), ".")); ext != "" && !seen[ext] {
+			seen[ext] = true
+			exts = append(exts, ext)
+		}
+	}
+	switch len(exts) {
+	case 0:
+		return nil, fmt.Errorf("no extensions")
+	case 1:
+		return []types.Condition{{Type: types.FileTypeCondition, Field: "extension", Operator: types.Equals, Value: exts[0]}}, nil
+	}
+	for i := range exts {
+		exts[i] = regexp.QuoteMeta(exts[i])
+	}
+	expr := `(?i)\.(` + strings.Join(exts, "|") + `)$`
+	return []types.Condition{{Type: types.FileNameCondition, Field: "name", Operator: types.MatchesRegex, Value: expr}}, nil
+}
+
+// nameFilter matches the name without its extension, as organize does: a
+// glob, or startswith, endswith, contains and match lists, any of which may
+// match, and case_sensitive
+func nameFilter(value any) ([]types.Condition, error) {
+	options, ok := value.(map[string]any)
+	if !ok {
+		options = map[string]any{"match": value}
+	}
+
+	caseSensitive := true
+	if on, ok := options["case_sensitive"].(bool); ok {
+		caseSensitive = on
+	}
+
+	var conditions []types.Condition
+	for _, key := range []string{"match", "startswith", "contains", "endswith"} {
+		values := stringList(options[key])
+		if len(values) == 0 {
+			continue
+		}
+		alternatives := make([]string, len(values))
+		for i, v := range values {
+			if key == "match" {
+				alternatives[i] = globToRegex(v)
+			} else {
+				alternatives[i] = regexp.QuoteMeta(v)
+			}
+		}
+		stem := "(?:" + strings.Join(alternatives, "|") + ")"
+		var expr string
+		switch key {
+		case "match":
+			expr = "^" + stem + `(\.[^.]*)?$`
+		case "startswith":
+			expr = "^" + stem
+		case "contains":
+			expr = stem + `.*\.[^.]*$|^[^.]*` + stem + `[^.]*$`
+		case "endswith":
+			expr = stem + `\.[^.]*$|^[^.]*` + stem + `$`
+		}
+		if !caseSensitive {
+			expr = "(?i)" + expr
+		}
+		conditions = append(conditions, types.Condition{Type: types.FileNameCondition, Field: "name", Operator: types.MatchesRegex, Value: expr})
+	}
+	for key := range options {
+		switch key {
+		case "match", "startswith", "contains", "endswith", "case_sensitive":
+		default:
+			return nil, fmt.Errorf("option %s has no sortd equivalent", key)
+		}
+	}
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("nothing to match")
+	}
+	return conditions, nil
+}
+
+// sizeExpr is a size comparison such as "> 1.5 MB" or "<=10kib"
+var sizeExpr = regexp.MustCompile(`^\s*(>=|<=|==|=|>|<)?\s*([\d.]+)\s*([a-zA-Z]*)\s*$`)
+
+// sizeUnitBytes are organize's size units: decimal unless marked binary
+var sizeUnitBytes = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1e3, "kb": 1e3, "m": 1e6, "mb": 1e6, "g": 1e9, "gb": 1e9, "t": 1e12, "tb": 1e12,
+	"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40,
+}
+
+// sizeFilter translates comparisons like "> 1 MB, < 10 MB" into size conditions in bytes
+func sizeFilter(value any) ([]types.Condition, error) {
+	var parts []string
+	for _, item := range stringList(value) {
+		parts = append(parts, strings.Split(item, ",")...)
+	}
+
+	var conditions []types.Condition
+	for _, part := range parts {
+		m := sizeExpr.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("can't read size %q", strings.TrimSpace(part))
+		}
+		n, err := strconv.ParseFloat(m[2], 64)
+		factor, known := sizeUnitBytes[strings.ToLower(m[3])]
+		if err != nil || !known {
+			return nil, fmt.Errorf("can't read size %q", strings.TrimSpace(part))
+		}
+		bytes := int64(math.Round(n * factor))
+
+		// sortd has strict comparisons only, which is the same for whole bytes
+		var op types.OperatorType
+		switch m[1] {
+		case ">":
+			op = types.GreaterThan
+		case ">=":
+			op, bytes = types.GreaterThan, bytes-1
+		case "<":
+			op = types.LessThan
+		case "<=":
+			op, bytes = types.LessThan, bytes+1
+		default:
+			op = types.Equals
+		}
+		conditions = append(conditions, types.Condition{Type: types.FileSizeCondition, Field: "size", Operator: op, Value: strconv.FormatInt(bytes, 10)})
+	}
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("no size given")
+	}
+	return conditions, nil
+}
+
+// ageUnitSeconds are the units organize's age filters count in
+var ageUnitSeconds = map[string]float64{
+	"years": 365 * 86400, "months": 30 * 86400, "weeks": 7 * 86400,
+	"days": 86400, "hours": 3600, "minutes": 60, "seconds": 1,
+}
+
+// ageFilter translates {days: 30, mode: older} into an age condition of the
+// given type
+func ageFilter(conditionType types.ConditionType, value any) ([]types.Condition, error) {
+	options, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected days, hours or other units")
+	}
+
+	seconds := 0.0
+	for key, v := range options {
+		if key == "mode" {
+			continue
+		}
+		factor, known := ageUnitSeconds[key]
+		n, isNumber := number(v)
+		if !known || !isNumber {
+			return nil, fmt.Errorf("option %s has no sortd equivalent", key)
+		}
+		seconds += n * factor
+	}
+	if seconds == 0 {
+		return nil, fmt.Errorf("no age given")
+	}
+
+	op := types.GreaterThan
+	switch options["mode"] {
+	case nil, "older":
+	case "newer":
+		op = types.LessThan
+	default:
+		return nil, fmt.Errorf("mode %v has no sortd equivalent", options["mode"])
+	}
+
+	condition := types.Condition{Type: conditionType, Field: "modified", Operator: op}
+	if conditionType == types.LastOpenedCondition {
+		condition.Field = "opened"
+	}
+	for _, unit := range []struct {
+		name    string
+		seconds float64
+	}{{"days", 86400}, {"hours", 3600}, {"minutes", 60}} {
+		if math.Mod(seconds, unit.seconds) == 0 {
+			condition.Value = strconv.FormatFloat(seconds/unit.seconds, 'f', -1, 64)
+			condition.ValueUnit = unit.name
+			return []types.Condition{condition}, nil
+		}
+	}
+	condition.Value = strconv.FormatFloat(seconds, 'f', -1, 64)
+	return []types.Condition{condition}, nil
+}
+
+// organizeAction translates an action into workflow actions, with a note on
+// any difference in behaviour
+func organizeAction(key string, value any) ([]types.Action, string, error) {
+	switch key {
+	case "move", "copy":
+		return transferAction(key, value)
+	case "rename":
+		newName, ok := value.(string)
+		if !ok {
+			if options, isMap := value.(map[string]any); isMap {
+				newName, ok = options["new_name"].(string)
+			}
+		}
+		if !ok || newName == "" {
+			return nil, "", fmt.Errorf("expected a new name")
+		}
+		if strings.Contains(newName, "{") {
+			return nil, "", fmt.Errorf("placeholders in %q have no sortd equivalent", newName)
+		}
+		return []types.Action{{Type: types.RenameAction, Target: newName}}, "", nil
+	case "delete":
+		return []types.Action{{Type: types.DeleteAction}}, "", nil
+	case "shell":
+		command, ok := value.(string)
+		if !ok {
+			if options, isMap := value.(map[string]any); isMap {
+				command, ok = options["cmd"].(string)
+			}
+		}
+		if !ok || command == "" {
+			return nil, "", fmt.Errorf("expected a command")
+		}
+		return []types.Action{{Type: types.ExecuteAction, Target: command}}, "", nil
+	case "macos_tags":
+		var actions []types.Action
+		for _, tag := range stringList(value) {
+			actions = append(actions, types.Action{Type: types.TagAction, Target: tag})
+		}
+		if len(actions) == 0 {
+			return nil, "", fmt.Errorf("no tags")
+		}
+		return actions, "", nil
+	case "echo":
+		return nil, "echo actions were dropped; sortd records workflow runs in its history", nil
+	case "trash":
+		return nil, "", fmt.Errorf("sortd can delete, but not move to the trash")
+	}
+	return nil, "", fmt.Errorf("no sortd equivalent")
+}
+
+// transferAction translates a move or copy. A destination ending in a slash
+// is a directory; any other names the file too, which takes a rename.
+func transferAction(key string, value any) ([]types.Action, string, error) {
+	dest, ok := value.(string)
+	options, isMap := value.(map[string]any)
+	if isMap {
+		dest, ok = options["dest"].(string)
+	}
+	if !ok || dest == "" {
+		return nil, "", fmt.Errorf("expected a destination")
+	}
+	if strings.Contains(dest, "{") {
+		return nil, "", fmt.Errorf("placeholders in %q have no sortd equivalent", dest)
+	}
+
+	actionType := types.MoveAction
+	if key == "copy" {
+		actionType = types.CopyAction
+	}
+	action := types.Action{Type: actionType, Target: dest, Options: map[string]string{"createTargetDir": "true"}}
+
+	var note string
+	switch conflict, _ := options["on_conflict"].(string); conflict {
+	case "", "rename_new":
+	case "overwrite":
+		action.Options["overwrite"] = "true"
+	default:
+		note = fmt.Sprintf("on_conflict %s: sortd keeps both files under a unique name (or skips identical ones with duplicates.compare_content)", conflict)
+	}
+
+	if strings.HasSuffix(dest, "/") || strings.HasSuffix(dest, `\`) {
+		action.Target = strings.TrimRight(dest, `/\`)
+		return []types.Action{action}, note, nil
+	}
+	if key == "copy" {
+		return nil, "", fmt.Errorf("copying to a new name (%s) has no sortd equivalent; end the destination with / to keep the name", dest)
+	}
+	action.Target = path.Dir(dest)
+	return []types.Action{action, {Type: types.RenameAction, Target: path.Base(dest)}}, note, nil
+}
+
+// entry returns a filter's or action's name, lower case, and its options.
+// Bare names, e.g. "empty" or "delete", have no options.
+func entry(item any) (string, any) {
+	switch v := item.(type) {
+	case string:
+		return strings.ToLower(strings.TrimSpace(v)), nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if len(keys) > 0 {
+			return strings.ToLower(strings.TrimSpace(keys[0])), v[keys[0]]
+		}
+	}
+	return fmt.Sprint(item), nil
+}
+
+// stringList returns a value that may be a single string or a list of them
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []any:
+		var list []string
+		for _, item := range v {
+			switch item := item.(type) {
+			case string:
+				list = append(list, item)
+			case map[string]any: // {path: ...} locations
+				if p, ok := item["path"].(string); ok {
+					list = append(list, p)
+				}
+			default:
+				list = append(list, fmt.Sprint(item))
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// number returns a YAML number as a float
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// firstNonNil returns the first of the values that is set
+func firstNonNil(values ...any) any {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// globToRegex translates a glob on a file name into a regular expression
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return sb.String()
+}
+
+// slugify turns a rule name into a workflow ID, e.g. "Sort PDFs" into sort-pdfs
+func slugify(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
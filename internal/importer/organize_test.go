@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const organizeConfig = `
+rules:
+  - name: "Sort invoices"
+    locations: ~/Downloads
+    filters:
+      - extension: [pdf, PDF, docx]
+      - name:
+          startswith: invoice
+          case_sensitive: false
+      - size: ">= 1 KiB, < 5 MB"
+      - lastmodified:
+          days: 2
+          mode: older
+    actions:
+      - echo: "Found {path}"
+      - move:
+          dest: ~/Documents/Invoices/
+          on_conflict: overwrite
+  - name: "Photos by date"
+    filters:
+      - not extension: heic
+      - exif
+    actions:
+      - copy: ~/Pictures/{exif.image.datetime.year}/
+      - macos_tags: [Photo]
+  - filters:
+      - mimetype: image
+    actions:
+      - trash
+`
+
+func TestOrganizeTool(t *testing.T) {
+	imported, err := OrganizeTool([]byte(organizeConfig))
+	require.NoError(t, err)
+	require.Len(t, imported, 3)
+
+	invoices := imported[0]
+	assert.Equal(t, "organize-sort-invoices", invoices.Workflow.ID)
+	assert.True(t, invoices.Workflow.Enabled)
+	assert.Empty(t, invoices.Unsupported)
+	assert.Equal(t, []types.Condition{
+		{Type: types.FileNameCondition, Field: "name", Operator: types.MatchesRegex, Value: `(?i)\.(pdf|docx)$`},
+		{Type: types.FileNameCondition, Field: "name", Operator: types.MatchesRegex, Value: `(?i)^(?:invoice)`},
+		{Type: types.FileSizeCondition, Field: "size", Operator: types.GreaterThan, Value: "1023"},
+		{Type: types.FileSizeCondition, Field: "size", Operator: types.LessThan, Value: "5000000"},
+		{Type: types.FileAgeCondition, Field: "modified", Operator: types.GreaterThan, Value: "2", ValueUnit: "days"},
+	}, invoices.Workflow.Conditions)
+	assert.Equal(t, []types.Action{{Type: types.MoveAction, Target: "~/Documents/Invoices",
+		Options: map[string]string{"createTargetDir": "true", "overwrite": "true"}}}, invoices.Workflow.Actions)
+	assert.Len(t, invoices.Notes, 2, "The location and the dropped echo are noted")
+
+	photos := imported[1]
+	assert.False(t, photos.Workflow.Enabled, "Untranslated filters and actions disable the workflow")
+	assert.Len(t, photos.Unsupported, 2, "exif and the placeholder copy")
+	assert.Equal(t, types.NotEquals, photos.Workflow.Conditions[0].Operator)
+	assert.Equal(t, []types.Action{{Type: types.TagAction, Target: "Photo"}}, photos.Workflow.Actions)
+	assert.Contains(t, photos.Workflow.Description, "not translated")
+
+	assert.True(t, imported[2].Skipped(), "Nothing the third rule does can be translated")
+	assert.Equal(t, "Imported rule 3", imported[2].Workflow.Name)
+}
+
+func TestNameFilter(t *testing.T) {
+	tests := []struct {
+		filter any
+		name   string
+		want   bool
+	}{
+		{"Invoice*", "Invoice 2024.pdf", true},
+		{"Invoice*", "invoice 2024.pdf", false},
+		{map[string]any{"endswith": "final"}, "report-final.docx", true},
+		{map[string]any{"endswith": "pdf"}, "report.pdf", false},
+		{map[string]any{"contains": "draft"}, "draft-notes", true},
+		{map[string]any{"contains": "pd"}, "report.pdf", false},
+	}
+	for _, tt := range tests {
+		conditions, err := nameFilter(tt.filter)
+		require.NoError(t, err)
+		require.Len(t, conditions, 1)
+		matched := regexp.MustCompile(conditions[0].Value).MatchString(tt.name)
+		assert.Equal(t, tt.want, matched, "%v on %s", tt.filter, tt.name)
+	}
+}
+
+func TestTransferActionRenames(t *testing.T) {
+	actions, _, err := transferAction("move", "~/Archive/latest.zip")
+	require.NoError(t, err)
+	require.Len(t, actions, 2)
+	assert.Equal(t, "~/Archive", actions[0].Target)
+	assert.Equal(t, types.Action{Type: types.RenameAction, Target: "latest.zip"}, actions[1])
+}
+
+func TestWorkflows_Hazel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Downloads.hazelrules")
+	require.NoError(t, os.WriteFile(path, []byte("bplist00..."), 0644))
+
+	_, err := Workflows(path)
+	assert.ErrorIs(t, err, ErrHazelArchive)
+}
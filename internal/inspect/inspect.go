@@ -0,0 +1,194 @@
+// Package inspect gathers what sortd knows about a single file: its type and
+// metadata, the words that characterise it, how it scores against each
+// classification, related files, and the rule or workflow that last moved it.
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"sortd/internal/analysis"
+	"sortd/internal/classify"
+	"sortd/internal/config"
+	"sortd/internal/embeddings"
+	"sortd/internal/learning"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+	"sortd/pkg/workflow"
+)
+
+// maxKeywords is how many keywords a report lists
+const maxKeywords = 30
+
+// stopWords are too common to characterise a file
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true, "you": true,
+	"all": true, "any": true, "can": true, "has": true, "her": true, "was": true, "one": true,
+	"our": true, "out": true, "his": true, "how": true, "its": true, "may": true, "new": true,
+	"now": true, "see": true, "who": true, "did": true, "yes": true, "this": true, "that": true,
+	"with": true, "from": true, "have": true, "your": true, "will": true, "they": true, "been": true,
+	"were": true, "what": true, "when": true, "which": true, "their": true, "there": true, "would": true,
+	"about": true, "into": true, "than": true, "then": true, "them": true, "these": true, "some": true,
+}
+
+// Keyword is a word and how often it occurs
+type Keyword struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Touch is the last time sortd moved a file
+type Touch struct {
+	Time     time.Time `json:"time"`
+	Source   string    `json:"source"`             // "rules" or "workflow"
+	Rule     string    `json:"rule,omitempty"`     // Pattern that matched, for rules
+	Workflow string    `json:"workflow,omitempty"` // Workflow name, for workflows
+	From     string    `json:"from,omitempty"`     // Where the file was before
+}
+
+// Report is everything known about a file
+type Report struct {
+	Path            string            `json:"path"`
+	Info            *types.FileInfo   `json:"info"`
+	Keywords        []Keyword         `json:"keywords"`
+	Classifications []classify.Result `json:"classifications"`
+	LastTouched     *Touch            `json:"last_touched,omitempty"`
+}
+
+// Inspect builds the report for a file. Parts that can't be determined, such
+// as text of a PDF without pdftotext installed, are left empty.
+func Inspect(cfg *config.Config, path string) (*Report, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	info, err := analysis.NewWithConfig(cfg).Analyze(path)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Path: path, Info: info}
+
+	text, _ := analysis.ExtractText(path, info.ContentType, cfg.Settings.Documents.OCR)
+	report.Keywords = Keywords(filepath.Base(path)+"\n"+text, maxKeywords)
+
+	if len(cfg.Classifications) > 0 {
+		if evaluator, err := classify.New(cfg.Classifications); err == nil {
+			report.Classifications, _ = evaluator.Evaluate(path)
+		}
+		for _, result := range report.Classifications {
+			if result.Matched {
+				info.Classification = result.Classification
+				break
+			}
+		}
+	}
+
+	report.LastTouched = lastTouched(cfg, path, stat)
+	return report, nil
+}
+
+// Keywords returns the most frequent meaningful words in text, most frequent first
+func Keywords(text string, limit int) []Keyword {
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if len([]rune(word)) < 3 || stopWords[word] {
+			continue
+		}
+		counts[word]++
+	}
+
+	keywords := make([]Keyword, 0, len(counts))
+	for word, count := range counts {
+		keywords = append(keywords, Keyword{Word: word, Count: count})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Count != keywords[j].Count {
+			return keywords[i].Count > keywords[j].Count
+		}
+		return keywords[i].Word < keywords[j].Word
+	})
+	if limit > 0 && len(keywords) > limit {
+		keywords = keywords[:limit]
+	}
+	return keywords
+}
+
+// lastTouched finds the latest move of the file into its current place, from
+// the daemon's activity log and the workflow run history
+func lastTouched(cfg *config.Config, path string, stat os.FileInfo) *Touch {
+	var touch *Touch
+
+	if cfg.Directories.Default != "" {
+		entries, _ := watch.LoadActivity(cfg, time.Time{})
+		for _, entry := range entries {
+			if entry.Error != "" || entry.Destination == "" {
+				continue
+			}
+			if filepath.Join(entry.Destination, filepath.Base(entry.Path)) != path {
+				continue
+			}
+			if touch == nil || entry.Time.After(touch.Time) {
+				touch = &Touch{Time: entry.Time, Source: entry.Source, From: entry.Path}
+			}
+		}
+
+		if touch != nil && touch.Source == "rules" {
+			if store, err := learning.Open(learning.DefaultPath(cfg)); err == nil {
+				if placement, ok := store.PlacementOf(stat); ok {
+					touch.Rule = placement.Rule
+				}
+			}
+		}
+	}
+
+	if dir, err := workflow.DefaultDir(); err == nil {
+		records, _ := workflow.ReadHistory(dir)
+		for _, record := range records {
+			if !record.Success || record.Mode == types.ShadowMode || record.Mode == types.DryRunMode {
+				continue
+			}
+			for _, action := range record.Actions {
+				if !strings.HasSuffix(action, " to "+path) {
+					continue
+				}
+				if touch == nil || !record.Time.Before(touch.Time) {
+					touch = &Touch{Time: record.Time, Source: "workflow", Workflow: record.WorkflowName, From: record.FilePath}
+				}
+			}
+		}
+	}
+
+	return touch
+}
+
+// Related returns the indexed files most similar in meaning to the file, using
+// the configured embedding model
+func Related(ctx context.Context, cfg *config.Config, path string, limit int) ([]embeddings.Match, error) {
+	provider, err := embeddings.NewProvider(cfg.Settings.Embeddings)
+	if err != nil {
+		return nil, err
+	}
+	index, err := embeddings.OpenIndex(embeddings.DefaultIndexPath(cfg))
+	if err != nil {
+		return nil, err
+	}
+	defer index.Close()
+
+	return embeddings.NewIndexer(provider, index).FindSimilar(ctx, path, limit)
+}
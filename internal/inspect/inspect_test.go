@@ -0,0 +1,64 @@
+package inspect_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/inspect"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeywords(t *testing.T) {
+	keywords := inspect.Keywords("Invoice 1001: the invoice total is due. Invoice paid? No, due!", 2)
+	assert.Equal(t, []inspect.Keyword{{Word: "invoice", Count: 3}, {Word: "due", Count: 2}}, keywords)
+}
+
+func TestInspect(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // No workflow history
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Invoices", "invoice.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("Invoice\nAmount due: 12.00"), 0644))
+
+	cfg := config.New()
+	cfg.Directories.Default = dir
+	cfg.Classifications = []types.FileClassification{{
+		Name: "invoice",
+		Criteria: types.ClassificationCriteria{
+			Keywords: []types.WeightedCriterion{{Value: "invoice", Weight: 1}},
+		},
+	}}
+
+	moved := time.Now().Add(-time.Hour).Truncate(time.Second)
+	entry, err := json.Marshal(types.ActivityEntry{
+		Time:        moved,
+		Path:        filepath.Join(dir, "Downloads", "invoice.txt"),
+		Destination: filepath.Dir(path),
+		Source:      "rules",
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(watch.ActivityFilePath(cfg), append(entry, '\n'), 0644))
+
+	report, err := inspect.Inspect(cfg, path)
+	require.NoError(t, err)
+
+	assert.Contains(t, report.Info.ContentType, "text/plain")
+	require.NotEmpty(t, report.Keywords)
+	assert.Equal(t, "invoice", report.Keywords[0].Word)
+
+	require.Len(t, report.Classifications, 1)
+	assert.True(t, report.Classifications[0].Matched)
+
+	require.NotNil(t, report.LastTouched)
+	assert.Equal(t, "rules", report.LastTouched.Source)
+	assert.Equal(t, filepath.Join(dir, "Downloads", "invoice.txt"), report.LastTouched.From)
+	assert.True(t, moved.Equal(report.LastTouched.Time))
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package lastopened
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime isn't used here: Windows updates access times lazily or not at
+// all, and the BSDs disagree on where they keep them
+func accessTime(path string, info os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
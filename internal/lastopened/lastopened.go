@@ -0,0 +1,38 @@
+// Package lastopened tells when a file was last opened, so rules can act on
+// files that have stopped being used rather than just files written long ago.
+// It asks the platform's record of recently used files (Spotlight on macOS,
+// recently-used.xbel on Linux and the BSDs, the Recent folder on Windows) and
+// the access time where the file system keeps it up to date.
+package lastopened
+
+import (
+	"os"
+	"time"
+)
+
+// Source says where a last opened time came from
+type Source string
+
+const (
+	// SourceRecent is the platform's record of recently used files
+	SourceRecent Source = "recent"
+	// SourceAccess is the file's access time
+	SourceAccess Source = "atime"
+	// SourceModified is the modification time, when nothing says the file
+	// was opened since it was written
+	SourceModified Source = "mtime"
+)
+
+// LastOpened returns when the file at path was last opened, and where that
+// came from. A file is taken to have been used when it was last written, so
+// the result is never before its modification time.
+func LastOpened(path string, info os.FileInfo) (time.Time, Source) {
+	last, source := info.ModTime(), SourceModified
+	if at, ok := recentlyUsed(path); ok && at.After(last) {
+		last, source = at, SourceRecent
+	}
+	if at, ok := accessTime(path, info); ok && at.After(last) {
+		last, source = at, SourceAccess
+	}
+	return last, source
+}
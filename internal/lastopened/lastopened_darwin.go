@@ -0,0 +1,32 @@
+package lastopened
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// spotlightLayout is how mdls prints dates
+const spotlightLayout = "2006-01-02 15:04:05 -0700"
+
+// recentlyUsed asks Spotlight when the file was last opened
+func recentlyUsed(path string) (time.Time, bool) {
+	out, err := exec.Command("mdls", "-raw", "-name", "kMDItemLastUsedDate", path).Output()
+	if err != nil {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(spotlightLayout, strings.TrimSpace(string(out)))
+	return at, err == nil
+}
+
+// accessTime returns the file's access time. APFS updates it unless a volume
+// is mounted noatime, which Spotlight makes up for.
+func accessTime(path string, info os.FileInfo) (time.Time, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(stat.Atimespec.Sec), int64(stat.Atimespec.Nsec)), true
+}
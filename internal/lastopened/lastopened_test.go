@@ -0,0 +1,59 @@
+package lastopened
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastOpened(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("recently-used.xbel and the mount table are read on Linux")
+	}
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("report"), 0644))
+	written := time.Now().Add(-200 * 24 * time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(path, written, written))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	last, source := LastOpened(path, info)
+	assert.True(t, last.Equal(written), "Never opened since written, so the modification time")
+	assert.Equal(t, SourceModified, source)
+
+	t.Run("recent files", func(t *testing.T) {
+		opened := time.Now().Add(-10 * 24 * time.Hour).UTC().Truncate(time.Second)
+		xbel := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<xbel version="1.0">
+  <bookmark href="file://%s" added="%s" modified="%s" visited="%s"/>
+</xbel>`, path, written.UTC().Format(time.RFC3339), opened.Format(time.RFC3339), written.UTC().Format(time.RFC3339))
+		require.NoError(t, os.WriteFile(filepath.Join(os.Getenv("XDG_DATA_HOME"), "recently-used.xbel"), []byte(xbel), 0644))
+
+		last, source := LastOpened(path, info)
+		assert.True(t, last.Equal(opened), "got %s", last)
+		assert.Equal(t, SourceRecent, source)
+	})
+
+	t.Run("access time", func(t *testing.T) {
+		if !atimeKept(path) {
+			t.Skip("the temp directory is mounted noatime")
+		}
+		accessed := time.Now().Add(-time.Hour).Truncate(time.Second)
+		require.NoError(t, os.Chtimes(path, accessed, written))
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+
+		last, source := LastOpened(path, info)
+		assert.True(t, last.Equal(accessed), "got %s", last)
+		assert.Equal(t, SourceAccess, source)
+	})
+}
@@ -0,0 +1,22 @@
+package lastopened
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// recentlyUsed looks for the shortcut Windows keeps in the Recent folder for
+// each file opened through the shell; it is rewritten on every open
+func recentlyUsed(path string) (time.Time, bool) {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return time.Time{}, false
+	}
+	link := filepath.Join(appData, "Microsoft", "Windows", "Recent", filepath.Base(path)+".lnk")
+	info, err := os.Stat(link)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
@@ -0,0 +1,98 @@
+//go:build !darwin && !windows
+
+package lastopened
+
+import (
+	"encoding/xml"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// xbel is the part of the freedesktop recently-used.xbel file read here
+type xbel struct {
+	Bookmarks []struct {
+		Href     string `xml:"href,attr"`
+		Modified string `xml:"modified,attr"`
+		Visited  string `xml:"visited,attr"`
+	} `xml:"bookmark"`
+}
+
+// recentCache keeps the parsed recent files until the file changes
+var recentCache struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	used    map[string]time.Time
+}
+
+// recentFilesPath returns the recently-used.xbel desktops keep in the user's
+// data directory
+func recentFilesPath() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "recently-used.xbel")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "recently-used.xbel")
+}
+
+// recentlyUsed looks the file up in recently-used.xbel, which desktop
+// applications add to whenever they open a file
+func recentlyUsed(path string) (time.Time, bool) {
+	used := recentFiles()
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	at, ok := used[abs]
+	return at, ok
+}
+
+// recentFiles returns when each file in recently-used.xbel was last used
+func recentFiles() map[string]time.Time {
+	path := recentFilesPath()
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	recentCache.mu.Lock()
+	defer recentCache.mu.Unlock()
+	if recentCache.path == path && recentCache.modTime.Equal(info.ModTime()) {
+		return recentCache.used
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var doc xbel
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	used := make(map[string]time.Time, len(doc.Bookmarks))
+	for _, bookmark := range doc.Bookmarks {
+		u, err := url.Parse(bookmark.Href)
+		if err != nil || u.Scheme != "file" {
+			continue
+		}
+		var last time.Time
+		for _, stamp := range []string{bookmark.Modified, bookmark.Visited} {
+			if at, err := time.Parse(time.RFC3339, stamp); err == nil && at.After(last) {
+				last = at
+			}
+		}
+		if !last.IsZero() {
+			used[filepath.Clean(u.Path)] = last
+		}
+	}
+
+	recentCache.path, recentCache.modTime, recentCache.used = path, info.ModTime(), used
+	return used
+}
@@ -0,0 +1,292 @@
+package learning
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Placements are remembered for a while so that a later move by the user can
+// be recognised as a correction; older ones are assumed to be accepted.
+const (
+	placementWindow = 30 * 24 * time.Hour
+	maxPlacements   = 1000
+	maxCorrections  = 500
+)
+
+// MinCorrectionsForSuggestion is how many corrections of a rule into the same
+// directory it takes before sortd suggests retargeting the rule
+const MinCorrectionsForSuggestion = 3
+
+// Placement is a file sortd moved, identified by inode so it can be recognised
+// wherever the user moves it next. Size and modification time guard against
+// the inode being reused by an unrelated file.
+type Placement struct {
+	Rule    string    `json:"rule"`
+	Path    string    `json:"path"`
+	Dev     uint64    `json:"dev"`
+	Ino     uint64    `json:"ino"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Time    time.Time `json:"time"`
+}
+
+// matches reports whether the placement is of the file described by info
+func (p Placement) matches(dev, ino uint64, info os.FileInfo) bool {
+	return p.Dev == dev && p.Ino == ino && p.Size == info.Size() && p.ModTime.Equal(info.ModTime())
+}
+
+// Correction is a placed file the user moved somewhere else
+type Correction struct {
+	Rule string    `json:"rule"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	Time time.Time `json:"time"`
+}
+
+// Suggestion proposes a new target for a rule the user keeps correcting
+type Suggestion struct {
+	Rule       string  `json:"rule"`
+	Directory  string  `json:"directory"`
+	Count      int     `json:"count"`
+	Confidence float64 `json:"confidence"`
+}
+
+// String describes the suggestion for logs and the CLI
+func (s Suggestion) String() string {
+	return fmt.Sprintf("files matching %s were moved to %s %d times; consider changing the rule's target",
+		s.Rule, s.Directory, s.Count)
+}
+
+// RecordPlacement remembers that the rule moved a file to path. info describes
+// the file before or after the move; a rename keeps its inode either way.
+func (s *Store) RecordPlacement(rule, path string, info os.FileInfo) error {
+	dev, ino, ok := inodeOf(info)
+	if !ok {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	kept := s.data.Placements[:0]
+	for _, placement := range s.data.Placements {
+		// A newer placement of the same file supersedes the old one
+		if now.Sub(placement.Time) > placementWindow || placement.matches(dev, ino, info) {
+			continue
+		}
+		kept = append(kept, placement)
+	}
+	kept = append(kept, Placement{
+		Rule:    rule,
+		Path:    filepath.Clean(path),
+		Dev:     dev,
+		Ino:     ino,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Time:    now,
+	})
+	if len(kept) > maxPlacements {
+		kept = kept[len(kept)-maxPlacements:]
+	}
+	s.data.Placements = kept
+
+	return s.saveLocked()
+}
+
+// DetectCorrection checks whether the file at path is one sortd placed
+// elsewhere. If so the move is recorded as a correction of the responsible
+// rule, lowering its confidence, and the placement is forgotten.
+func (s *Store) DetectCorrection(path string, info os.FileInfo) (Correction, bool, error) {
+	dev, ino, ok := inodeOf(info)
+	if !ok {
+		return Correction{}, false, nil
+	}
+	path = filepath.Clean(path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return Correction{}, false, err
+	}
+
+	for i, placement := range s.data.Placements {
+		if !placement.matches(dev, ino, info) {
+			continue
+		}
+		if filepath.Dir(placement.Path) == filepath.Dir(path) {
+			// Still where sortd put it, perhaps under a collision-free name
+			return Correction{}, false, nil
+		}
+
+		correction := Correction{Rule: placement.Rule, From: placement.Path, To: path, Time: time.Now()}
+		s.data.Placements = append(s.data.Placements[:i], s.data.Placements[i+1:]...)
+		s.data.Corrections = append(s.data.Corrections, correction)
+		if len(s.data.Corrections) > maxCorrections {
+			s.data.Corrections = s.data.Corrections[len(s.data.Corrections)-maxCorrections:]
+		}
+		s.changeLocked(placement.Rule, func(stats *RuleStats) { stats.Corrections++ })
+
+		return correction, true, s.saveLocked()
+	}
+	return Correction{}, false, nil
+}
+
+// PlacementOf returns the recorded placement of the file described by info,
+// wherever it is now
+func (s *Store) PlacementOf(info os.FileInfo) (Placement, bool) {
+	dev, ino, ok := inodeOf(info)
+	if !ok {
+		return Placement{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loadLocked()
+	for i := len(s.data.Placements) - 1; i >= 0; i-- {
+		if s.data.Placements[i].matches(dev, ino, info) {
+			return s.data.Placements[i], true
+		}
+	}
+	return Placement{}, false
+}
+
+// Corrections returns the recorded corrections, oldest first
+func (s *Store) Corrections() []Correction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loadLocked()
+	return append([]Correction(nil), s.data.Corrections...)
+}
+
+// Suggestions proposes new targets for rules whose files the user keeps moving
+// to the same directory, most corrected first
+func (s *Store) Suggestions() []Suggestion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loadLocked()
+
+	counts := make(map[[2]string]int)
+	for _, correction := range s.data.Corrections {
+		counts[[2]string{correction.Rule, filepath.Dir(correction.To)}]++
+	}
+
+	best := make(map[string]Suggestion)
+	for key, count := range counts {
+		rule, dir := key[0], key[1]
+		if count < MinCorrectionsForSuggestion {
+			continue
+		}
+		if current, ok := best[rule]; ok && (current.Count > count || (current.Count == count && current.Directory < dir)) {
+			continue
+		}
+		confidence := RuleStats{Rule: rule}.Confidence()
+		if stats, ok := s.rules[rule]; ok {
+			confidence = stats.Confidence()
+		}
+		best[rule] = Suggestion{Rule: rule, Directory: dir, Count: count, Confidence: confidence}
+	}
+
+	suggestions := make([]Suggestion, 0, len(best))
+	for _, suggestion := range best {
+		suggestions = append(suggestions, suggestion)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].Rule < suggestions[j].Rule
+	})
+	return suggestions
+}
+
+// SuggestionFor returns the suggestion for a single rule, if there is one
+func (s *Store) SuggestionFor(rule string) (Suggestion, bool) {
+	for _, suggestion := range s.Suggestions() {
+		if suggestion.Rule == rule {
+			return suggestion, true
+		}
+	}
+	return Suggestion{}, false
+}
+
+// RecordCorrection records that the user sent a file the rule would have
+// placed at from to a different path instead, as when picking another
+// destination while triaging
+func (s *Store) RecordCorrection(rule, from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+
+	correction := Correction{Rule: rule, From: filepath.Clean(from), To: filepath.Clean(to), Time: time.Now()}
+	s.data.Corrections = append(s.data.Corrections, correction)
+	if len(s.data.Corrections) > maxCorrections {
+		s.data.Corrections = s.data.Corrections[len(s.data.Corrections)-maxCorrections:]
+	}
+	s.changeLocked(rule, func(stats *RuleStats) { stats.Corrections++ })
+	return s.saveLocked()
+}
+
+// Alternatives returns up to limit directories the user has moved files to
+// instead of where sortd put them: those of the rule's files first, then
+// those of files with the same extension as path, most frequent first.
+// exclude, e.g. the rule's own target, is left out.
+func (s *Store) Alternatives(rule, path, exclude string, limit int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loadLocked()
+
+	ext := strings.ToLower(filepath.Ext(path))
+	byRule := make(map[string]int)
+	byExt := make(map[string]int)
+	for _, correction := range s.data.Corrections {
+		dir := filepath.Dir(correction.To)
+		switch {
+		case rule != "" && correction.Rule == rule:
+			byRule[dir]++
+		case ext != "" && strings.ToLower(filepath.Ext(correction.To)) == ext:
+			byExt[dir]++
+		}
+	}
+
+	var alternatives []string
+	seen := map[string]bool{filepath.Clean(exclude): true}
+	for _, counts := range []map[string]int{byRule, byExt} {
+		dirs := make([]string, 0, len(counts))
+		for dir := range counts {
+			dirs = append(dirs, dir)
+		}
+		sort.Slice(dirs, func(i, j int) bool {
+			if counts[dirs[i]] != counts[dirs[j]] {
+				return counts[dirs[i]] > counts[dirs[j]]
+			}
+			return dirs[i] < dirs[j]
+		})
+		for _, dir := range dirs {
+			if len(alternatives) == limit {
+				return alternatives
+			}
+			if !seen[dir] {
+				seen[dir] = true
+				alternatives = append(alternatives, dir)
+			}
+		}
+	}
+	return alternatives
+}
@@ -0,0 +1,102 @@
+package learning_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/learning"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile creates a file with content and returns its stat
+func writeFile(t *testing.T, path, content string) os.FileInfo {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info
+}
+
+func TestDetectCorrection(t *testing.T) {
+	dir := t.TempDir()
+	store, err := learning.Open(filepath.Join(dir, "learning.json"))
+	require.NoError(t, err)
+
+	placed := filepath.Join(dir, "sorted", "a.pdf")
+	info := writeFile(t, placed, "a")
+	require.NoError(t, store.RecordPlacement("*.pdf", placed, info))
+
+	_, found, err := store.DetectCorrection(placed, info)
+	require.NoError(t, err)
+	assert.False(t, found, "A file where sortd put it is not a correction")
+
+	moved := filepath.Join(dir, "invoices", "a.pdf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(moved), 0755))
+	require.NoError(t, os.Rename(placed, moved))
+	info, err = os.Stat(moved)
+	require.NoError(t, err)
+
+	correction, found, err := store.DetectCorrection(moved, info)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "*.pdf", correction.Rule)
+	assert.Equal(t, placed, correction.From)
+	assert.Equal(t, moved, correction.To)
+	assert.Equal(t, 1, store.Stats("*.pdf").Corrections)
+	assert.Less(t, store.Confidence("*.pdf"), 0.5, "A correction should lower confidence")
+
+	_, found, err = store.DetectCorrection(moved, info)
+	require.NoError(t, err)
+	assert.False(t, found, "A correction should only be recorded once")
+}
+
+func TestSuggestions(t *testing.T) {
+	dir := t.TempDir()
+	store, err := learning.Open(filepath.Join(dir, "learning.json"))
+	require.NoError(t, err)
+
+	invoices := filepath.Join(dir, "invoices")
+	for i, name := range []string{"a.pdf", "b.pdf", "c.pdf"} {
+		assert.Empty(t, store.Suggestions(), "No suggestion expected after %d corrections", i)
+
+		path := filepath.Join(invoices, name)
+		info := writeFile(t, path, name)
+		require.NoError(t, store.RecordPlacement("*.pdf", filepath.Join(dir, "docs", name), info))
+		_, found, err := store.DetectCorrection(path, info)
+		require.NoError(t, err)
+		require.True(t, found)
+	}
+
+	suggestion, ok := store.SuggestionFor("*.pdf")
+	require.True(t, ok, "Expected a suggestion after %d corrections", learning.MinCorrectionsForSuggestion)
+	assert.Equal(t, invoices, suggestion.Directory)
+	assert.Equal(t, 3, suggestion.Count)
+
+	reopened, err := learning.Open(filepath.Join(dir, "learning.json"))
+	require.NoError(t, err)
+	assert.Len(t, reopened.Corrections(), 3, "Corrections should be persisted")
+}
+
+func TestAlternatives(t *testing.T) {
+	store, err := learning.Open(filepath.Join(t.TempDir(), "learning.json"))
+	require.NoError(t, err)
+
+	require.NoError(t, store.RecordCorrection("*.pdf", "/docs/a.pdf", "/invoices/a.pdf"))
+	require.NoError(t, store.RecordCorrection("*.pdf", "/docs/b.pdf", "/invoices/b.pdf"))
+	require.NoError(t, store.RecordCorrection("*.pdf", "/docs/c.pdf", "/manuals/c.pdf"))
+	require.NoError(t, store.RecordCorrection("report*", "/reports/r.pdf", "/archive/r.pdf"))
+	require.NoError(t, store.RecordCorrection("*.jpg", "/pictures/p.jpg", "/wallpapers/p.jpg"))
+	assert.Equal(t, 3, store.Stats("*.pdf").Corrections)
+
+	assert.Equal(t, []string{"/invoices", "/manuals", "/archive"},
+		store.Alternatives("*.pdf", "/downloads/x.PDF", "/docs", 3),
+		"The rule's corrections come first, then those of files with the same extension")
+	assert.Equal(t, []string{"/manuals"}, store.Alternatives("*.pdf", "/downloads/x.pdf", "/invoices", 1))
+	assert.Equal(t, []string{"/invoices", "/archive", "/manuals"},
+		store.Alternatives("", "/downloads/x.pdf", "", 3), "Unmatched files get alternatives by extension")
+	assert.Empty(t, store.Alternatives("", "/downloads/notes", "", 3))
+}
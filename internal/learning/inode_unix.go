@@ -0,0 +1,20 @@
+//go:build !windows
+
+package learning
+
+import (
+	"os"
+	"syscall"
+)
+
+// inodeOf returns the device and inode of a stat result
+func inodeOf(info os.FileInfo) (uint64, uint64, bool) {
+	if info == nil {
+		return 0, 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return uint64(st.Dev), uint64(st.Ino), true
+}
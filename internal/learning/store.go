@@ -0,0 +1,170 @@
+// Package learning keeps track of how users respond to the moves sortd makes,
+// so that each organization rule carries a measure of how much it can be trusted.
+package learning
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+)
+
+// storeFile is the file, in the data directory, the learning data is kept in
+const storeFile = "learning.json"
+
+// RuleStats counts the outcomes of the moves proposed by one rule
+type RuleStats struct {
+	Rule        string    `json:"rule"`
+	Approvals   int       `json:"approvals"`
+	Rejections  int       `json:"rejections"`
+	Corrections int       `json:"corrections"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// Confidence estimates how likely a move proposed by the rule is to be accepted.
+// A rule without history starts at 0.5 and moves towards its approval rate.
+// Corrections weigh double: the user went out of their way to undo the move.
+func (s RuleStats) Confidence() float64 {
+	total := s.Approvals + s.Rejections + 2*s.Corrections
+	return float64(s.Approvals+1) / float64(total+2)
+}
+
+// storeData is the on-disk form of the store
+type storeData struct {
+	Rules       []*RuleStats `json:"rules"`
+	Placements  []Placement  `json:"placements,omitempty"`
+	Corrections []Correction `json:"corrections,omitempty"`
+}
+
+// Store persists rule statistics as JSON. Like the pending queue, the file is
+// re-read before every change so several processes can share it.
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	rules map[string]*RuleStats
+	data  storeData
+}
+
+// DefaultPath returns the path of the learning store
+func DefaultPath(cfg *config.Config) string {
+	return cfg.DataFile(storeFile)
+}
+
+// Open loads the store at path. A missing file yields an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := s.loadLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RecordApproval notes that a move proposed by the rule was accepted
+func (s *Store) RecordApproval(rule string) error {
+	return s.update(rule, func(stats *RuleStats) { stats.Approvals++ })
+}
+
+// RecordRejection notes that a move proposed by the rule was refused
+func (s *Store) RecordRejection(rule string) error {
+	return s.update(rule, func(stats *RuleStats) { stats.Rejections++ })
+}
+
+// Stats returns the statistics of a rule; unknown rules have zero counts
+func (s *Store) Stats(rule string) RuleStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Reading is best effort: an unreadable store behaves like an empty one
+	s.loadLocked()
+	if stats, ok := s.rules[rule]; ok {
+		return *stats
+	}
+	return RuleStats{Rule: rule}
+}
+
+// Confidence returns the confidence of a rule
+func (s *Store) Confidence(rule string) float64 {
+	return s.Stats(rule).Confidence()
+}
+
+// All returns the statistics of every rule with history, sorted by rule
+func (s *Store) All() []RuleStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loadLocked()
+	all := make([]RuleStats, 0, len(s.rules))
+	for _, stats := range s.rules {
+		all = append(all, *stats)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Rule < all[j].Rule })
+	return all
+}
+
+// update applies a change to a rule's statistics and saves the store
+func (s *Store) update(rule string, change func(*RuleStats)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+	s.changeLocked(rule, change)
+	return s.saveLocked()
+}
+
+// changeLocked applies a change to a rule's statistics. The caller must hold s.mu.
+func (s *Store) changeLocked(rule string, change func(*RuleStats)) {
+	stats, ok := s.rules[rule]
+	if !ok {
+		stats = &RuleStats{Rule: rule}
+		s.rules[rule] = stats
+	}
+	change(stats)
+	stats.LastUpdated = time.Now()
+}
+
+// loadLocked re-reads the store from disk. The caller must hold s.mu.
+func (s *Store) loadLocked() error {
+	s.rules = make(map[string]*RuleStats)
+	s.data = storeData{}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read learning data: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.data); err != nil {
+		return fmt.Errorf("failed to parse learning data: %w", err)
+	}
+	for _, stats := range s.data.Rules {
+		s.rules[stats.Rule] = stats
+	}
+	return nil
+}
+
+// saveLocked writes the store to disk. The caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	s.data.Rules = make([]*RuleStats, 0, len(s.rules))
+	for _, stats := range s.rules {
+		s.data.Rules = append(s.data.Rules, stats)
+	}
+	sort.Slice(s.data.Rules, func(i, j int) bool { return s.data.Rules[i].Rule < s.data.Rules[j].Rule })
+
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode learning data: %w", err)
+	}
+	if err := atomicfile.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save learning data: %w", err)
+	}
+	return nil
+}
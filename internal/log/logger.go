@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"sortd/internal/errors"
+	"sortd/internal/trace"
 )
 
 // Log levels
@@ -28,6 +29,7 @@ var (
 	isDebug  = false
 	logger   = NewLogger()
 	logMutex sync.Mutex
+	sink     io.Writer // Gets every entry as JSON, whatever the logger writes
 )
 
 // Field represents a key-value pair for structured logging
@@ -141,10 +143,12 @@ func (l *Logger) With(fields ...Field) *Logger {
 	return newLogger
 }
 
-// WithContext creates a new logger with context information
+// WithContext creates a new logger with context information: the trace ID
+// of the file being handled, if ctx carries one
 func (l *Logger) WithContext(ctx context.Context) *Logger {
-	// Here you can extract values from context and add them as fields
-	// This is a placeholder for future context-aware logging
+	if id := trace.From(ctx); id != "" {
+		return l.With(F(trace.Field, id))
+	}
 	return l.With()
 }
 
@@ -161,6 +165,14 @@ func SetDebug(debug bool) {
 	isDebug = debug
 }
 
+// SetSink sends a JSON copy of every entry, from any logger, to w as well,
+// e.g. to keep a log file the log viewer reads. A nil w stops it.
+func SetSink(w io.Writer) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	sink = w
+}
+
 // Configure configures the global logger
 func Configure(opts ...LoggerOption) {
 	logMutex.Lock()
@@ -265,6 +277,10 @@ func extractErrorFields(err error) []Field {
 		)
 	}
 
+	if suggestion := errors.Suggestion(err); suggestion != "" {
+		fields = append(fields, Field{Key: "suggestion", Value: suggestion})
+	}
+
 	return fields
 }
 
@@ -277,10 +293,13 @@ func (l *Logger) log(level, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
 
 	if l.useJSON {
-		l.logJSON(level, msg)
+		l.logJSON(l.out, level, msg)
 	} else {
 		l.logText(level, msg)
 	}
+	if sink != nil && (!l.useJSON || sink != l.out) {
+		l.logJSON(sink, level, msg)
+	}
 }
 
 // logText logs a message in text format
@@ -310,8 +329,8 @@ func (l *Logger) logText(level, msg string) {
 	fmt.Fprintln(l.out, logMsg)
 }
 
-// logJSON logs a message in JSON format
-func (l *Logger) logJSON(level, msg string) {
+// logJSON logs a message in JSON format to out
+func (l *Logger) logJSON(out io.Writer, level, msg string) {
 	// Create a map for JSON logging
 	logMap := map[string]interface{}{
 		"timestamp": time.Now().Format(time.RFC3339),
@@ -337,7 +356,7 @@ func (l *Logger) logJSON(level, msg string) {
 		return
 	}
 
-	fmt.Fprintln(l.out, string(jsonBytes))
+	fmt.Fprintln(out, string(jsonBytes))
 }
 
 // Info logs an info message with fields
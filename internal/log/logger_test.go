@@ -310,6 +310,22 @@ func TestConfigure(t *testing.T) {
 	logger = originalLogger
 }
 
+func TestSink(t *testing.T) {
+	var out, copied bytes.Buffer
+	l := NewLogger(WithOutput(&out))
+	SetSink(&copied)
+	defer SetSink(nil)
+
+	l.With(F("file", "report.pdf")).Warn("no rule matched")
+	assert.Contains(t, out.String(), "WARN", "The logger should still write its text")
+
+	var logEntry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(copied.String())), &logEntry))
+	assert.Equal(t, "WARN", logEntry["level"])
+	assert.Equal(t, "no rule matched", logEntry["message"])
+	assert.Equal(t, "report.pdf", logEntry["file"])
+}
+
 // Test that we correctly handle nil errors
 func TestNilErrorHandling(t *testing.T) {
 	var buf bytes.Buffer
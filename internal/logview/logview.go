@@ -0,0 +1,334 @@
+// Package logview keeps sortd's structured log file and reads it back for the
+// log viewers, so why a file wasn't organized can be looked up without
+// hunting for log files: entries filtered by level and text, and new entries
+// as they are written.
+package logview
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sortd/internal/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	fileName = "sortd.log"
+	// maxSize is how large the log grows before it is moved to sortd.log.1,
+	// replacing the one before
+	maxSize = 5 << 20
+)
+
+// Levels from least to most severe, as the log viewers offer them
+var Levels = []string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+// levelAliases maps the names logrus and others use onto Levels
+var levelAliases = map[string]string{
+	"TRACE":   "DEBUG",
+	"WARNING": "WARN",
+	"PANIC":   "FATAL",
+}
+
+// DefaultPath returns the log file in sortd's state directory
+func DefaultPath() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Entry is a line of the log
+type Entry struct {
+	Time    time.Time
+	Level   string // One of Levels, or empty for lines that aren't JSON
+	Message string
+	Fields  map[string]string // Everything else on the line, e.g. the file
+}
+
+// String formats the entry as a line for a terminal or a list
+func (e Entry) String() string {
+	var b strings.Builder
+	if !e.Time.IsZero() {
+		b.WriteString(e.Time.Format("2006-01-02 15:04:05") + " ")
+	}
+	if e.Level != "" {
+		fmt.Fprintf(&b, "%-5s ", e.Level)
+	}
+	b.WriteString(e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%s", key, e.Fields[key])
+	}
+	return b.String()
+}
+
+// Parse reads a line of the log. Both loggers' JSON is understood; anything
+// else becomes an entry with the line as its message.
+func Parse(line string) Entry {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return Entry{Message: line}
+	}
+
+	entry := Entry{Fields: make(map[string]string)}
+	for key, value := range raw {
+		text := fmt.Sprint(value)
+		switch key {
+		case "time", "timestamp":
+			entry.Time, _ = time.Parse(time.RFC3339, text)
+		case "level":
+			entry.Level = normalizeLevel(text)
+		case "msg", "message":
+			entry.Message = text
+		default:
+			entry.Fields[key] = text
+		}
+	}
+	return entry
+}
+
+// normalizeLevel maps a level name onto Levels
+func normalizeLevel(level string) string {
+	level = strings.ToUpper(level)
+	if alias, ok := levelAliases[level]; ok {
+		return alias
+	}
+	return level
+}
+
+// levelRank orders levels; unknown ones rank with INFO
+func levelRank(level string) int {
+	for i, known := range Levels {
+		if known == level {
+			return i
+		}
+	}
+	return 1
+}
+
+// Filter picks the entries a viewer shows
+type Filter struct {
+	Level  string // Least severe level shown; empty shows all
+	Search string // Text the message or a field must contain, ignoring case
+}
+
+// Match reports whether the filter lets entry through
+func (f Filter) Match(entry Entry) bool {
+	if f.Level != "" && entry.Level != "" && levelRank(entry.Level) < levelRank(normalizeLevel(f.Level)) {
+		return false
+	}
+	if f.Search == "" {
+		return true
+	}
+	search := strings.ToLower(f.Search)
+	if strings.Contains(strings.ToLower(entry.Message), search) {
+		return true
+	}
+	for key, value := range entry.Fields {
+		if strings.Contains(strings.ToLower(key+"="+value), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// Read returns the last limit entries of the log at path that match filter,
+// oldest first, including those rotated to sortd.log.1. A limit of 0 returns
+// them all; a missing log has none.
+func Read(path string, filter Filter, limit int) ([]Entry, error) {
+	var entries []Entry
+	for _, file := range []string{path + ".1", path} {
+		err := readLines(file, func(line string) {
+			if entry := Parse(line); filter.Match(entry) {
+				entries = append(entries, entry)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// readLines calls fn with each non-empty line of the file at path
+func readLines(path string, fn func(line string)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			fn(line)
+		}
+	}
+	return scanner.Err()
+}
+
+// Tail follows the log at a path, returning what was added since the last look
+type Tail struct {
+	path    string
+	offset  int64
+	partial string // A line still being written at the last look
+}
+
+// NewTail starts following the log at path from its current end
+func NewTail(path string) *Tail {
+	t := &Tail{path: path}
+	if info, err := os.Stat(path); err == nil {
+		t.offset = info.Size()
+	}
+	return t
+}
+
+// Next returns the entries written since the last call. After the log is
+// rotated it starts again from the top of the new file.
+func (t *Tail) Next() ([]Entry, error) {
+	file, err := os.Open(t.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < t.offset {
+		t.offset, t.partial = 0, ""
+	}
+	if _, err := file.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	t.offset += int64(len(data))
+
+	text := t.partial + string(data)
+	lines := strings.Split(text, "\n")
+	t.partial = lines[len(lines)-1]
+
+	var entries []Entry
+	for _, line := range lines[:len(lines)-1] {
+		if line = strings.TrimSpace(line); line != "" {
+			entries = append(entries, Parse(line))
+		}
+	}
+	return entries, nil
+}
+
+// File is the log file being written, moved aside when it grows past maxSize
+type File struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenFile opens the log at path for appending, creating its directory
+func OpenFile(path string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f := &File{path: path}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open (re)opens the file at f.path
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, rotating the file first when it is full
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.size+int64(len(p)) > maxSize && f.size > 0 {
+		f.file.Close()
+		if err := os.Rename(f.path, f.path+".1"); err != nil {
+			return 0, err
+		}
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// Hook copies logrus entries to a log as JSON, whatever logrus itself writes
+type Hook struct {
+	out       io.Writer
+	formatter logrus.JSONFormatter
+}
+
+// NewHook creates a hook writing to out
+func NewHook(out io.Writer) *Hook {
+	return &Hook{out: out}
+}
+
+// Levels is every level; the viewers filter
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire writes the entry
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	data, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = h.out.Write(data)
+	return err
+}
@@ -0,0 +1,90 @@
+package logview
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	entry := Parse(`{"level":"warning","msg":"No rule matched","time":"2024-05-01T10:00:00Z","file":"/home/me/Downloads/report.pdf"}`)
+	assert.Equal(t, "WARN", entry.Level)
+	assert.Equal(t, "No rule matched", entry.Message)
+	assert.Equal(t, 2024, entry.Time.Year())
+	assert.Equal(t, "/home/me/Downloads/report.pdf", entry.Fields["file"])
+
+	entry = Parse(`{"level":"ERROR","message":"Failed to move file","timestamp":"2024-05-01T10:00:00Z","caller":"engine.go:210"}`)
+	assert.Equal(t, "ERROR", entry.Level)
+	assert.Equal(t, "Failed to move file", entry.Message)
+	assert.Equal(t, "engine.go:210", entry.Fields["caller"])
+
+	entry = Parse("plain text from an older log")
+	assert.Empty(t, entry.Level)
+	assert.Equal(t, "plain text from an older log", entry.Message)
+}
+
+func TestFilter(t *testing.T) {
+	info := Entry{Level: "INFO", Message: "Moved file", Fields: map[string]string{"file": "Invoice.pdf"}}
+	warn := Entry{Level: "WARN", Message: "No rule matched"}
+
+	assert.True(t, Filter{}.Match(info))
+	assert.False(t, Filter{Level: "warn"}.Match(info))
+	assert.True(t, Filter{Level: "warn"}.Match(warn))
+	assert.True(t, Filter{Search: "invoice"}.Match(info), "Fields should be searched too")
+	assert.False(t, Filter{Search: "invoice"}.Match(warn))
+}
+
+func TestWriteAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", fileName)
+	file, err := OpenFile(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(&strings.Builder{})
+	logger.AddHook(NewHook(file))
+
+	tail := NewTail(path)
+	logger.WithField("file", "a.pdf").Info("Moved file")
+	logger.WithField("file", "b.zip").Warn("No rule matched")
+	logger.WithField("file", "c.txt").Error("Failed to move file")
+
+	entries, err := Read(path, Filter{Level: "WARN"}, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "b.zip", entries[0].Fields["file"])
+
+	entries, err = Read(path, Filter{}, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Failed to move file", entries[0].Message, "The limit should keep the newest entries")
+
+	added, err := tail.Next()
+	require.NoError(t, err)
+	assert.Len(t, added, 3)
+	added, err = tail.Next()
+	require.NoError(t, err)
+	assert.Empty(t, added)
+
+	t.Run("rotation", func(t *testing.T) {
+		// Pretend the file is full so the next entry starts a new one
+		file.size = maxSize
+		logger.Info("After rotation")
+
+		_, err := os.Stat(path + ".1")
+		assert.NoError(t, err)
+		entries, err := Read(path, Filter{}, 0)
+		require.NoError(t, err)
+		assert.Len(t, entries, 4, "Rotated entries should still be read")
+
+		added, err := tail.Next()
+		require.NoError(t, err)
+		require.Len(t, added, 1)
+		assert.Equal(t, "After rotation", added[0].Message)
+	})
+}
@@ -0,0 +1,277 @@
+// Package manifest keeps SHA256SUMS files in archive directories: each folder
+// that files are moved or copied into lists their checksums, in the format
+// sha256sum writes and checks, so that bit rot in cold storage shows up when
+// the archive is verified, with sortd or with 'sha256sum -c'.
+package manifest
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"sortd/internal/atomicfile"
+)
+
+// FileName is the name of the manifest in each folder
+const FileName = "SHA256SUMS"
+
+// Keeper updates the manifests of the configured directories, and their
+// subdirectories, as files arrive and leave. It is safe for concurrent use,
+// and a nil Keeper keeps nothing.
+type Keeper struct {
+	dirs []string
+
+	mu sync.Mutex
+}
+
+// New returns a Keeper for dirs, or nil if there are none
+func New(dirs []string) *Keeper {
+	if len(dirs) == 0 {
+		return nil
+	}
+	k := &Keeper{}
+	for _, dir := range dirs {
+		k.dirs = append(k.dirs, absolute(dir))
+	}
+	return k
+}
+
+// Dirs returns the directories whose manifests are kept
+func (k *Keeper) Dirs() []string {
+	if k == nil {
+		return nil
+	}
+	return append([]string(nil), k.dirs...)
+}
+
+// Covers reports whether a file at path is listed in a manifest
+func (k *Keeper) Covers(path string) bool {
+	if k == nil {
+		return false
+	}
+	path = absolute(path)
+	for _, dir := range k.dirs {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// Added records a file that arrived at dest from src ("" for a copy): its
+// checksum goes into the manifest of dest's folder, and a move out of a
+// kept directory takes src off its manifest
+func (k *Keeper) Added(src, dest string) error {
+	if k == nil {
+		return nil
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if src != "" && k.Covers(src) {
+		if err := update(filepath.Dir(src), func(entries map[string]string) {
+			delete(entries, filepath.Base(src))
+		}); err != nil {
+			return err
+		}
+	}
+	if !k.Covers(dest) {
+		return nil
+	}
+	sum, err := Sum(dest)
+	if err != nil {
+		return err
+	}
+	return update(filepath.Dir(dest), func(entries map[string]string) {
+		entries[filepath.Base(dest)] = sum
+	})
+}
+
+// Sum returns the SHA-256 of the file at path, in hex
+func Sum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Read returns the checksums listed in dir's manifest by file name; a folder
+// without one lists nothing
+func Read(dir string) (map[string]string, error) {
+	entries := make(map[string]string)
+	f, err := os.Open(filepath.Join(dir, FileName))
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		// "<sum>  <name>", or "<sum> *<name>" for files hashed in binary mode
+		sum, name, ok := strings.Cut(text, " ")
+		if !ok || len(sum) != sha256.Size*2 || len(name) < 2 {
+			return nil, fmt.Errorf("%s line %d: not a checksum line", filepath.Join(dir, FileName), line)
+		}
+		entries[name[1:]] = strings.ToLower(sum)
+	}
+	return entries, scanner.Err()
+}
+
+// write replaces dir's manifest with entries, sorted by name. A folder left
+// with no entries loses its manifest.
+func write(dir string, entries map[string]string) error {
+	path := filepath.Join(dir, FileName)
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s  %s\n", entries[name], name)
+	}
+	return atomicfile.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// update changes the entries of dir's manifest and writes it back
+func update(dir string, change func(map[string]string)) error {
+	entries, err := Read(dir)
+	if err != nil {
+		return err
+	}
+	change(entries)
+	return write(dir, entries)
+}
+
+// Status is the outcome of verifying one file
+type Status string
+
+const (
+	StatusOK       Status = "ok"       // The file matches its checksum
+	StatusChanged  Status = "changed"  // The file no longer matches its checksum
+	StatusMissing  Status = "missing"  // The manifest lists a file that is gone
+	StatusUnlisted Status = "unlisted" // The file isn't in its folder's manifest
+	StatusRecorded Status = "recorded" // The file wasn't listed and has been added
+	StatusError    Status = "error"    // The file couldn't be read
+)
+
+// Result is the outcome of verifying one file
+type Result struct {
+	Path   string
+	Status Status
+	Err    error // Why the file couldn't be read, for StatusError
+}
+
+// Verify checks every file under root against the manifest of its folder.
+// With record, files not listed yet are added to it, creating manifests
+// where there are none; files that changed are never re-recorded.
+func Verify(root string, record bool) ([]Result, error) {
+	var results []Result
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		dirResults, err := verifyDir(path, record)
+		results = append(results, dirResults...)
+		return err
+	})
+	return results, err
+}
+
+// verifyDir checks the files directly in dir
+func verifyDir(dir string, record bool) ([]Result, error) {
+	entries, err := Read(dir)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	present := make(map[string]bool)
+	recorded := false
+	for _, file := range files {
+		name := file.Name()
+		if !file.Type().IsRegular() || name == FileName || atomicfile.IsTemp(name) {
+			continue
+		}
+		present[name] = true
+		path := filepath.Join(dir, name)
+
+		want, listed := entries[name]
+		if !listed && !record {
+			results = append(results, Result{Path: path, Status: StatusUnlisted})
+			continue
+		}
+		sum, err := Sum(path)
+		switch {
+		case err != nil:
+			results = append(results, Result{Path: path, Status: StatusError, Err: err})
+		case !listed:
+			entries[name] = sum
+			recorded = true
+			results = append(results, Result{Path: path, Status: StatusRecorded})
+		case sum != want:
+			results = append(results, Result{Path: path, Status: StatusChanged})
+		default:
+			results = append(results, Result{Path: path, Status: StatusOK})
+		}
+	}
+
+	for name := range entries {
+		if !present[name] {
+			results = append(results, Result{Path: filepath.Join(dir, name), Status: StatusMissing})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+
+	if recorded {
+		if err := write(dir, entries); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// absolute returns path made absolute and clean
+func absolute(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,102 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeeperAdded(t *testing.T) {
+	archive := t.TempDir()
+	inbox := t.TempDir()
+	k := New([]string{archive})
+
+	photo := filepath.Join(archive, "2024", "photo.jpg")
+	require.NoError(t, os.MkdirAll(filepath.Dir(photo), 0755))
+	require.NoError(t, os.WriteFile(photo, []byte("pixels"), 0644))
+	require.NoError(t, k.Added(filepath.Join(inbox, "photo.jpg"), photo))
+
+	entries, err := Read(filepath.Dir(photo))
+	require.NoError(t, err)
+	sum, err := Sum(photo)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"photo.jpg": sum}, entries)
+
+	// Files outside the archive aren't listed anywhere
+	other := filepath.Join(inbox, "note.txt")
+	require.NoError(t, os.WriteFile(other, []byte("note"), 0644))
+	require.NoError(t, k.Added("", other))
+	assert.NoFileExists(t, filepath.Join(inbox, FileName))
+
+	// Moving the file out takes it off the manifest, which goes when empty
+	require.NoError(t, os.Rename(photo, filepath.Join(inbox, "photo.jpg")))
+	require.NoError(t, k.Added(photo, filepath.Join(inbox, "photo.jpg")))
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(photo), FileName))
+
+	var none *Keeper
+	assert.NoError(t, none.Added("", photo))
+}
+
+func TestVerify(t *testing.T) {
+	archive := t.TempDir()
+	k := New([]string{archive})
+	write := func(name, content string) string {
+		path := filepath.Join(archive, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	intact := write("intact.txt", "same")
+	rotten := write("rotten.txt", "before")
+	gone := write("gone.txt", "gone")
+	for _, path := range []string{intact, rotten, gone} {
+		require.NoError(t, k.Added("", path))
+	}
+	write("rotten.txt", "after")
+	require.NoError(t, os.Remove(gone))
+	unlisted := write("unlisted.txt", "new")
+
+	statuses := func(results []Result) map[string]Status {
+		byPath := make(map[string]Status)
+		for _, result := range results {
+			byPath[result.Path] = result.Status
+		}
+		return byPath
+	}
+
+	results, err := Verify(archive, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Status{
+		intact:   StatusOK,
+		rotten:   StatusChanged,
+		gone:     StatusMissing,
+		unlisted: StatusUnlisted,
+	}, statuses(results))
+
+	results, err = Verify(archive, true)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRecorded, statuses(results)[unlisted])
+	assert.Equal(t, StatusChanged, statuses(results)[rotten], "Changed files aren't re-recorded")
+
+	results, err = Verify(archive, false)
+	require.NoError(t, err)
+	assert.Equal(t, StatusOK, statuses(results)[unlisted])
+}
+
+func TestReadSha256sumFormat(t *testing.T) {
+	dir := t.TempDir()
+	sum := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	manifest := "# made by sha256sum\n" + sum + "  empty file.txt\n" + sum + " *binary.bin\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(manifest), 0644))
+
+	entries, err := Read(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"empty file.txt": sum, "binary.bin": sum}, entries)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte("not a checksum\n"), 0644))
+	_, err = Read(dir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package music
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"os/exec"
+)
+
+// Thresholds for treating two acoustic fingerprints as the same recording
+const (
+	minFingerprintSimilarity = 0.9
+	maxDurationDifference    = 2.0 // seconds
+)
+
+// Fingerprinter computes an acoustic fingerprint of an audio file
+type Fingerprinter interface {
+	// Fingerprint returns the fingerprint and the duration in seconds
+	Fingerprint(path string) ([]uint32, float64, error)
+}
+
+// Signature identifies the recording in an audio file. The checksum covers
+// the audio data only, so retagged copies match; the optional fingerprint
+// also matches re-encodes of the same recording.
+type Signature struct {
+	Checksum    string
+	Fingerprint []uint32
+	Duration    float64
+}
+
+// SameRecording reports whether two signatures belong to the same recording
+func (s Signature) SameRecording(other Signature) bool {
+	if s.Checksum != "" && s.Checksum == other.Checksum {
+		return true
+	}
+	if len(s.Fingerprint) == 0 || len(other.Fingerprint) == 0 {
+		return false
+	}
+	if math.Abs(s.Duration-other.Duration) > maxDurationDifference {
+		return false
+	}
+	return FingerprintSimilarity(s.Fingerprint, other.Fingerprint) >= minFingerprintSimilarity
+}
+
+// FingerprintSimilarity returns the share of matching bits over the common
+// length of two fingerprints, from 0 to 1
+func FingerprintSimilarity(a, b []uint32) float64 {
+	n := min(len(a), len(b))
+	if n == 0 {
+		return 0
+	}
+	differing := 0
+	for i := 0; i < n; i++ {
+		differing += bits.OnesCount32(a[i] ^ b[i])
+	}
+	return 1 - float64(differing)/float64(32*n)
+}
+
+// Chromaprint fingerprints with the fpcalc tool from the Chromaprint project
+type Chromaprint struct {
+	path string
+}
+
+// NewChromaprint finds fpcalc on the PATH
+func NewChromaprint() (*Chromaprint, error) {
+	path, err := exec.LookPath("fpcalc")
+	if err != nil {
+		return nil, fmt.Errorf("fpcalc not found; install Chromaprint to enable acoustic fingerprints")
+	}
+	return &Chromaprint{path: path}, nil
+}
+
+// Fingerprint runs fpcalc and returns its raw fingerprint
+func (c *Chromaprint) Fingerprint(path string) ([]uint32, float64, error) {
+	out, err := exec.Command(c.path, "-raw", "-json", path).Output()
+	if err != nil {
+		return nil, 0, fmt.Errorf("fpcalc failed: %w", err)
+	}
+
+	var result struct {
+		Duration    float64  `json:"duration"`
+		Fingerprint []uint32 `json:"fingerprint"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse fpcalc output: %w", err)
+	}
+	return result.Fingerprint, result.Duration, nil
+}
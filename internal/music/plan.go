@@ -0,0 +1,108 @@
+package music
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DuplicatesFolder is the library folder re-downloads are moved into
+const DuplicatesFolder = "Duplicates"
+
+// Move is one planned step of organizing music
+type Move struct {
+	Source      string
+	Destination string // Full destination path
+	Track       *Track
+	DuplicateOf string // Set when the track is a copy of a recording already in the library
+}
+
+// PlanResult is the outcome of planning
+type PlanResult struct {
+	Moves   []Move
+	InPlace int               // Tracks already in their Artist/Album folder
+	Skipped map[string]string // Files that couldn't be read, with the reason
+}
+
+// Plan works out where each audio file belongs in the library. Files already
+// in the library are indexed first, so re-downloads of tracks it has are
+// planned into the Duplicates folder instead of next to the original.
+func Plan(files []string, library string, fingerprinter Fingerprinter) (*PlanResult, error) {
+	library, err := filepath.Abs(library)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PlanResult{Skipped: make(map[string]string)}
+	var incoming []*Track
+	var known []*Track
+
+	inputs := make(map[string]bool)
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil || !IsAudioFile(abs) {
+			continue
+		}
+		inputs[abs] = true
+
+		track, err := ReadTrack(abs, fingerprinter)
+		if err != nil {
+			result.Skipped[abs] = err.Error()
+			continue
+		}
+		if filepath.Dir(abs) == filepath.Join(library, track.Folder()) {
+			result.InPlace++
+			known = append(known, track)
+			continue
+		}
+		incoming = append(incoming, track)
+	}
+
+	// Index what the library already holds, apart from the files being organized
+	duplicates := filepath.Join(library, DuplicatesFolder)
+	filepath.Walk(library, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path == duplicates || (path != library && strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if inputs[path] || !IsAudioFile(path) {
+			return nil
+		}
+		if track, err := ReadTrack(path, fingerprinter); err == nil {
+			known = append(known, track)
+		}
+		return nil
+	})
+
+	sort.Slice(incoming, func(i, j int) bool { return incoming[i].Path < incoming[j].Path })
+	for _, track := range incoming {
+		move := Move{Source: track.Path, Track: track}
+		if original := findRecording(known, track.Signature); original != nil {
+			move.DuplicateOf = original.Path
+			move.Destination = filepath.Join(duplicates, filepath.Base(track.Path))
+		} else {
+			move.Destination = filepath.Join(library, track.Folder(), filepath.Base(track.Path))
+			// Later copies in the same batch are duplicates of this one
+			known = append(known, &Track{Path: move.Destination, Signature: track.Signature})
+		}
+		result.Moves = append(result.Moves, move)
+	}
+
+	return result, nil
+}
+
+// findRecording returns the first known track with the same recording
+func findRecording(known []*Track, signature Signature) *Track {
+	for _, track := range known {
+		if track.Signature.SameRecording(signature) {
+			return track
+		}
+	}
+	return nil
+}
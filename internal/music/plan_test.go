@@ -0,0 +1,101 @@
+package music_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/music"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMP3 writes an ID3v2.3-tagged file whose "audio" is the given bytes
+func writeMP3(t *testing.T, path, artist, album, title string, audio []byte) {
+	t.Helper()
+
+	var frames bytes.Buffer
+	for id, value := range map[string]string{"TPE1": artist, "TALB": album, "TIT2": title} {
+		if value == "" {
+			continue
+		}
+		size := len(value) + 1
+		frames.WriteString(id)
+		frames.Write([]byte{byte(size >> 24), byte(size >> 16), byte(size >> 8), byte(size), 0, 0})
+		frames.WriteByte(0) // ISO-8859-1
+		frames.WriteString(value)
+	}
+
+	size := frames.Len()
+	var file bytes.Buffer
+	file.WriteString("ID3")
+	file.Write([]byte{3, 0, 0})
+	file.Write([]byte{byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)})
+	file.Write(frames.Bytes())
+	file.Write(audio)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, file.Bytes(), 0644))
+}
+
+func TestReadTrack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "01.mp3")
+	writeMP3(t, path, "Nina Simone", "Pastel Blues", "Sinnerman", []byte("audio-1"))
+
+	track, err := music.ReadTrack(path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Nina Simone", track.Artist)
+	assert.Equal(t, "Pastel Blues", track.Album)
+	assert.Equal(t, "Sinnerman", track.Title)
+	assert.Equal(t, filepath.Join("Nina Simone", "Pastel Blues"), track.Folder())
+	assert.NotEmpty(t, track.Signature.Checksum)
+}
+
+func TestPlan(t *testing.T) {
+	library := t.TempDir()
+	downloads := t.TempDir()
+
+	writeMP3(t, filepath.Join(library, "AC_DC", "Back in Black", "Hells Bells.mp3"), "AC/DC", "Back in Black", "Hells Bells", []byte("bells"))
+	writeMP3(t, filepath.Join(downloads, "hells_bells (1).mp3"), "ACDC", "Back In Black [Remaster]", "Hells Bells", []byte("bells"))
+	writeMP3(t, filepath.Join(downloads, "shoot.mp3"), "AC/DC", "Back in Black", "Shoot to Thrill", []byte("shoot"))
+	writeMP3(t, filepath.Join(downloads, "shoot-again.mp3"), "AC/DC", "Back in Black", "Shoot to Thrill", []byte("shoot"))
+	writeMP3(t, filepath.Join(downloads, "untagged.mp3"), "", "", "", []byte("mystery"))
+	require.NoError(t, os.WriteFile(filepath.Join(downloads, "notes.txt"), []byte("not audio"), 0644))
+
+	entries, err := os.ReadDir(downloads)
+	require.NoError(t, err)
+	var files []string
+	for _, entry := range entries {
+		files = append(files, filepath.Join(downloads, entry.Name()))
+	}
+
+	plan, err := music.Plan(files, library, nil)
+	require.NoError(t, err)
+	require.Len(t, plan.Moves, 4, "Only audio files should be planned")
+
+	destinations := make(map[string]string)
+	for _, move := range plan.Moves {
+		destinations[filepath.Base(move.Source)] = move.Destination
+	}
+	assert.Equal(t, filepath.Join(library, music.DuplicatesFolder, "hells_bells (1).mp3"), destinations["hells_bells (1).mp3"],
+		"A retagged copy of a library track is a duplicate")
+	assert.Equal(t, filepath.Join(library, "AC_DC", "Back in Black", "shoot-again.mp3"), destinations["shoot-again.mp3"])
+	assert.Equal(t, filepath.Join(library, music.DuplicatesFolder, "shoot.mp3"), destinations["shoot.mp3"],
+		"The second copy in a batch is a duplicate of the first")
+	assert.Equal(t, filepath.Join(library, music.UnknownArtist, music.UnknownAlbum, "untagged.mp3"), destinations["untagged.mp3"])
+}
+
+func TestSignatureSameRecording(t *testing.T) {
+	base := []uint32{0xdeadbeef, 0x12345678, 0x0f0f0f0f, 0xcafebabe}
+	reencoded := []uint32{0xdeadbeef, 0x12345679, 0x0f0f0f0f, 0xcafebabe}
+	other := []uint32{0x00000000, 0xffffffff, 0xf0f0f0f0, 0x11111111}
+
+	a := music.Signature{Checksum: "a", Fingerprint: base, Duration: 200}
+	assert.True(t, a.SameRecording(music.Signature{Checksum: "b", Fingerprint: reencoded, Duration: 201}))
+	assert.False(t, a.SameRecording(music.Signature{Checksum: "c", Fingerprint: other, Duration: 200}))
+	assert.False(t, a.SameRecording(music.Signature{Checksum: "d", Fingerprint: reencoded, Duration: 260}),
+		"Different lengths are different recordings")
+	assert.True(t, a.SameRecording(music.Signature{Checksum: "a"}))
+}
@@ -0,0 +1,164 @@
+// Package music reads audio tags and signatures so tracks can be filed into
+// Artist/Album folders and re-downloads of the same recording recognised.
+package music
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dhowden/tag"
+)
+
+// audioExtensions are the formats whose tags can be read
+var audioExtensions = map[string]bool{
+	".mp3": true, ".m4a": true, ".m4b": true, ".mp4": true, ".aac": true,
+	".flac": true, ".ogg": true, ".opus": true, ".dsf": true,
+}
+
+// Folder names used when a tag is missing
+const (
+	UnknownArtist = "Unknown Artist"
+	UnknownAlbum  = "Unknown Album"
+)
+
+// Track is an audio file with the tags used to file it
+type Track struct {
+	Path        string
+	Artist      string
+	Album       string
+	Title       string
+	TrackNumber int
+	Year        int
+	Signature   Signature
+}
+
+// IsAudioFile reports whether the file has an audio extension sortd can read
+func IsAudioFile(path string) bool {
+	return audioExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// ReadTrack reads the tags of an audio file (ID3v1/v2, MP4, FLAC or Ogg) and
+// computes its signature. A nil fingerprinter skips acoustic fingerprinting.
+func ReadTrack(path string, fingerprinter Fingerprinter) (*Track, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	track := &Track{Path: path}
+	if meta, err := tag.ReadFrom(f); err == nil {
+		track.Artist = strings.TrimSpace(meta.AlbumArtist())
+		if track.Artist == "" {
+			track.Artist = strings.TrimSpace(meta.Artist())
+		}
+		track.Album = strings.TrimSpace(meta.Album())
+		track.Title = strings.TrimSpace(meta.Title())
+		track.TrackNumber, _ = meta.Track()
+		track.Year = meta.Year()
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	sum, err := audioChecksum(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum audio in %s: %w", path, err)
+	}
+	track.Signature.Checksum = sum
+
+	if fingerprinter != nil {
+		fp, duration, err := fingerprinter.Fingerprint(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fingerprint %s: %w", path, err)
+		}
+		track.Signature.Fingerprint = fp
+		track.Signature.Duration = duration
+	}
+
+	return track, nil
+}
+
+// audioChecksum hashes the audio data of a file, leaving out its tags, so
+// copies that differ only in their tags have the same checksum
+func audioChecksum(f *os.File) (string, error) {
+	header := make([]byte, 11)
+	if _, err := io.ReadFull(f, header); err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	switch {
+	case string(header[0:4]) == "fLaC":
+		return tag.SumFLAC(f)
+	case string(header[4:11]) == "ftypM4A":
+		return tag.SumAtoms(f)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	start, end := int64(0), info.Size()
+
+	// Skip a leading ID3v2 tag: 10-byte header, syncsafe size, optional footer
+	if string(header[0:3]) == "ID3" && len(header) >= 10 {
+		size := int64(header[6]&0x7f)<<21 | int64(header[7]&0x7f)<<14 | int64(header[8]&0x7f)<<7 | int64(header[9]&0x7f)
+		start = 10 + size
+		if header[5]&0x10 != 0 {
+			start += 10
+		}
+	}
+
+	// Drop a trailing 128-byte ID3v1 tag
+	if end-start >= 128 {
+		trailer := make([]byte, 3)
+		if _, err := f.ReadAt(trailer, end-128); err == nil && string(trailer) == "TAG" {
+			end -= 128
+		}
+	}
+	if start > end {
+		start = end
+	}
+
+	h := sha1.New()
+	if _, err := io.Copy(h, io.NewSectionReader(f, start, end-start)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Folder returns the Artist/Album folder, relative to the library, the track belongs in
+func (t *Track) Folder() string {
+	artist := sanitizeName(t.Artist)
+	if artist == "" {
+		artist = UnknownArtist
+	}
+	album := sanitizeName(t.Album)
+	if album == "" {
+		album = UnknownAlbum
+	}
+	return filepath.Join(artist, album)
+}
+
+// sanitizeName makes a tag value safe to use as a single folder name
+func sanitizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, name)
+	return strings.Trim(strings.TrimSpace(name), ".")
+}
@@ -0,0 +1,14 @@
+// Package openfiles tells whether other processes have a file open, so the
+// watcher can leave files alone while another program is still writing them.
+package openfiles
+
+import "errors"
+
+// ErrUnsupported is returned where open files can't be detected, e.g. when
+// lsof isn't installed
+var ErrUnsupported = errors.New("detecting open files is not supported on this system")
+
+// IsOpen reports whether a process other than this one has the file open
+func IsOpen(path string) (bool, error) {
+	return isOpen(path)
+}
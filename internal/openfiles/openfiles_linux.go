@@ -0,0 +1,47 @@
+package openfiles
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// isOpen looks for the file among the descriptors in /proc/<pid>/fd. Processes
+// of other users can't be inspected without privileges and are passed over.
+func isOpen(path string) (bool, error) {
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	if resolved, err := filepath.EvalSymlinks(target); err == nil {
+		target = resolved
+	}
+
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return false, ErrUnsupported
+	}
+	self := strconv.Itoa(os.Getpid())
+	for _, proc := range procs {
+		if proc.Name() == self || !isPID(proc.Name()) {
+			continue
+		}
+		fdDir := filepath.Join("/proc", proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if link, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && link == target {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// isPID reports whether a /proc entry is a process directory
+func isPID(name string) bool {
+	return name != "" && strings.Trim(name, "0123456789") == ""
+}
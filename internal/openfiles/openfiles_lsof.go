@@ -0,0 +1,32 @@
+//go:build !linux && !windows
+
+package openfiles
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// isOpen asks lsof for the processes holding the file open. lsof exits with
+// status 1 and prints nothing when there are none.
+func isOpen(path string) (bool, error) {
+	out, err := exec.Command("lsof", "-t", "--", path).Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return false, ErrUnsupported
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && len(out) == 0) {
+		return false, err
+	}
+
+	self := strconv.Itoa(os.Getpid())
+	for _, pid := range strings.Fields(string(out)) {
+		if pid != self {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,50 @@
+package openfiles
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsOpen(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Holding the file open in a child process needs a Unix shell")
+	}
+	path := filepath.Join(t.TempDir(), "scan.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("pdf"), 0644))
+
+	open, err := IsOpen(path)
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	assert.False(t, open, "Nobody has the file open")
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	open, err = IsOpen(path)
+	require.NoError(t, err)
+	assert.False(t, open, "Our own descriptors don't count")
+
+	// A child process holds the file open as its standard input
+	cmd := exec.Command("sleep", "30")
+	cmd.Stdin = f
+	if err := cmd.Start(); err != nil {
+		t.Skipf("Can't start sleep: %v", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	open, err = IsOpen(path)
+	require.NoError(t, err)
+	assert.True(t, open)
+}
@@ -0,0 +1,26 @@
+package openfiles
+
+import "syscall"
+
+// errorSharingViolation is ERROR_SHARING_VIOLATION: another process has the
+// file open in a way that excludes us
+const errorSharingViolation = syscall.Errno(32)
+
+// isOpen tries to open the file without sharing it; Windows refuses while any
+// other process has it open
+func isOpen(path string) (bool, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return false, err
+	}
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ, 0, nil,
+		syscall.OPEN_EXISTING, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err == errorSharingViolation {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	syscall.CloseHandle(handle)
+	return false, nil
+}
@@ -0,0 +1,66 @@
+package organize
+
+import (
+	"fmt"
+	"os"
+
+	"sortd/internal/log"
+)
+
+// Collision strategies, as set in settings.collision
+const (
+	CollisionSkip      = "skip"      // Leave the file where it is
+	CollisionOverwrite = "overwrite" // Replace the existing file
+	CollisionRename    = "rename"    // Move under a free name, e.g. "report_(1).pdf"
+	CollisionAsk       = "ask"       // Let a CollisionResolver decide for each collision
+)
+
+// CollisionResolver decides what to do when dest already exists and the
+// strategy is "ask". It returns CollisionSkip, CollisionOverwrite or
+// CollisionRename. Resolvers run before the engine takes its directory locks,
+// so they can take as long as the user needs.
+type CollisionResolver func(src, dest string) (string, error)
+
+// SetCollisionResolver sets who decides collisions under the "ask" strategy.
+// Without one, such collisions are skipped.
+func (e *Engine) SetCollisionResolver(resolver CollisionResolver) {
+	e.resolver = resolver
+}
+
+// answerCollision asks about a collision at dest under the "ask" strategy. It
+// returns "" when there is nothing to ask: another strategy, no file at dest,
+// or an identical one that is skipped anyway.
+func (e *Engine) answerCollision(src, dest string) (string, error) {
+	if e.collision != CollisionAsk {
+		return "", nil
+	}
+	if _, err := os.Stat(dest); err != nil {
+		return "", nil
+	}
+	if e.duplicateSettings().CompareContent {
+		if same, err := SameContent(src, dest); err == nil && same {
+			return "", nil
+		}
+	}
+	return e.askCollision(src, dest)
+}
+
+// askCollision asks the resolver about a collision
+func (e *Engine) askCollision(src, dest string) (string, error) {
+	if e.resolver == nil {
+		log.LogWithFields(log.F("source", src), log.F("destination", dest)).
+			Warn("Collision strategy 'ask' has no one to ask, skipping")
+		return CollisionSkip, nil
+	}
+
+	choice, err := e.resolver(src, dest)
+	if err != nil {
+		return "", err
+	}
+	switch choice {
+	case CollisionSkip, CollisionOverwrite, CollisionRename:
+		return choice, nil
+	default:
+		return "", fmt.Errorf("invalid answer %q to collision of %s: must be skip, overwrite or rename", choice, dest)
+	}
+}
@@ -0,0 +1,122 @@
+package organize
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollisionAsk(t *testing.T) {
+	// setup creates a source file whose destination is already taken
+	setup := func(t *testing.T) (*Engine, string, string) {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "report.pdf")
+		dest := filepath.Join(dir, "docs", "report.pdf")
+		require.NoError(t, os.WriteFile(src, []byte("new"), 0644))
+		require.NoError(t, os.MkdirAll(filepath.Dir(dest), 0755))
+		require.NoError(t, os.WriteFile(dest, []byte("old"), 0644))
+
+		cfg := &config.Config{}
+		cfg.Settings.Collision = CollisionAsk
+		return NewWithConfig(cfg), src, dest
+	}
+
+	t.Run("no resolver skips", func(t *testing.T) {
+		engine, src, dest := setup(t)
+		require.NoError(t, engine.MoveFile(src, dest))
+
+		assert.FileExists(t, src)
+		content, _ := os.ReadFile(dest)
+		assert.Equal(t, "old", string(content))
+	})
+
+	t.Run("answers are carried out", func(t *testing.T) {
+		for choice, want := range map[string]string{
+			CollisionSkip:      "old",
+			CollisionOverwrite: "new",
+			CollisionRename:    "old",
+		} {
+			engine, src, dest := setup(t)
+			var asked []string
+			engine.SetCollisionResolver(func(s, d string) (string, error) {
+				asked = append(asked, s, d)
+				return choice, nil
+			})
+
+			require.NoError(t, engine.MoveFile(src, dest), choice)
+			assert.Equal(t, []string{src, dest}, asked, choice)
+
+			content, _ := os.ReadFile(dest)
+			assert.Equal(t, want, string(content), choice)
+			if choice == CollisionRename {
+				assert.FileExists(t, filepath.Join(filepath.Dir(dest), "report_(1).pdf"))
+			}
+		}
+	})
+
+	t.Run("resolvers run without the directory locks", func(t *testing.T) {
+		old := lockTimeout
+		lockTimeout = 100 * time.Millisecond
+		t.Cleanup(func() { lockTimeout = old })
+
+		engine, src, dest := setup(t)
+		engine.SetCollisionResolver(func(s, d string) (string, error) {
+			// Another process moving into the same directory meanwhile
+			unlock, err := lockDirectories(filepath.Dir(s), filepath.Dir(d))
+			if err != nil {
+				return "", err
+			}
+			unlock()
+			return CollisionOverwrite, nil
+		})
+
+		require.NoError(t, engine.MoveFile(src, dest))
+		content, _ := os.ReadFile(dest)
+		assert.Equal(t, "new", string(content))
+	})
+
+	t.Run("collisions appearing while waiting for the locks fail the move", func(t *testing.T) {
+		engine, src, dest := setup(t)
+		require.NoError(t, os.Remove(dest))
+		engine.SetCollisionResolver(func(string, string) (string, error) {
+			t.Error("Nothing to ask about before the locks")
+			return "", nil
+		})
+
+		unlock, err := lockDirectories(filepath.Dir(dest))
+		require.NoError(t, err)
+		done := make(chan error, 1)
+		go func() { done <- engine.MoveFile(src, dest) }()
+		time.Sleep(200 * time.Millisecond)
+		require.NoError(t, os.WriteFile(dest, []byte("old"), 0644))
+		unlock()
+
+		assert.ErrorContains(t, <-done, "appeared at the destination")
+		assert.FileExists(t, src)
+	})
+
+	t.Run("resolver errors fail the move", func(t *testing.T) {
+		engine, src, dest := setup(t)
+		engine.SetCollisionResolver(func(string, string) (string, error) {
+			return "", errors.New("no answer")
+		})
+		assert.Error(t, engine.MoveFile(src, dest))
+		assert.FileExists(t, src)
+	})
+
+	t.Run("invalid answers fail the move", func(t *testing.T) {
+		engine, src, dest := setup(t)
+		engine.SetCollisionResolver(func(string, string) (string, error) {
+			return CollisionAsk, nil
+		})
+		assert.Error(t, engine.MoveFile(src, dest))
+		assert.FileExists(t, src)
+	})
+}
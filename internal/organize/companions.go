@@ -0,0 +1,139 @@
+package organize
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sortd/pkg/types"
+)
+
+// checksumSuffixes end the names of checksum and signature files that belong
+// to the file named by the rest, e.g. foo.iso.sha256
+var checksumSuffixes = []string{
+	".sha1", ".sha1sum", ".sha256", ".sha256sum", ".sha512", ".sha512sum",
+	".md5", ".md5sum", ".asc", ".sig", ".minisig",
+}
+
+// checksumList matches the names of files listing the checksums of several
+// downloads, e.g. SHA256SUMS, checksums.txt or terraform_1.6.0_SHA256SUMS
+var checksumList = regexp.MustCompile(`(?i)^(?:.*[-_.])?(?:sha(?:1|256|512)?sums|shasums(?:256|512)?|md5sums|checksums?)(?:\.txt)?$`)
+
+// maxChecksumList bounds how much of a checksum list is read for the names in it
+const maxChecksumList = 1 << 20
+
+// bsdChecksum matches a line in the BSD style, "SHA256 (foo.iso) = 9f86..."
+var bsdChecksum = regexp.MustCompile(`^\w+ \((.+)\) = [0-9A-Fa-f]+$`)
+
+// companionArtifacts returns the files a checksum or signature file at path
+// belongs to, those that exist first, or nil when it isn't one
+func companionArtifacts(path string) []string {
+	dir, name := filepath.Dir(path), filepath.Base(path)
+
+	var artifacts []string
+	if artifact, ok := stripChecksumSuffix(name); ok {
+		if checksumList.MatchString(artifact) {
+			// The signature of a checksum list goes with what the list covers
+			artifacts = listedArtifacts(filepath.Join(dir, artifact))
+		} else {
+			artifacts = []string{artifact}
+		}
+	} else if checksumList.MatchString(name) {
+		artifacts = listedArtifacts(path)
+	}
+
+	paths := make([]string, 0, len(artifacts))
+	var missing []string
+	for _, artifact := range artifacts {
+		artifactPath := filepath.Join(dir, artifact)
+		if _, err := os.Stat(artifactPath); err == nil {
+			paths = append(paths, artifactPath)
+		} else {
+			missing = append(missing, artifactPath)
+		}
+	}
+	return append(paths, missing...)
+}
+
+// stripChecksumSuffix returns the name a checksum or signature file is for
+func stripChecksumSuffix(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	for _, suffix := range checksumSuffixes {
+		if strings.HasSuffix(lower, suffix) && len(name) > len(suffix) {
+			return name[:len(name)-len(suffix)], true
+		}
+	}
+	return "", false
+}
+
+// listedArtifacts returns the file names a checksum list gives checksums for,
+// in either the "9f86...  foo.iso" or the BSD "SHA256 (foo.iso) = 9f86..." style.
+// Names with a directory are left out: they aren't next to the list.
+func listedArtifacts(path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(io.LimitReader(file, maxChecksumList))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		name := ""
+		if match := bsdChecksum.FindStringSubmatch(line); match != nil {
+			name = match[1]
+		} else if fields := strings.Fields(line); len(fields) == 2 && isHex(fields[0]) {
+			// A leading * marks a checksum of the file read in binary mode
+			name = strings.TrimPrefix(fields[1], "*")
+		}
+		if name != "" && !strings.ContainsAny(name, `/\`) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// isHex reports whether s is a hex digest
+func isHex(s string) bool {
+	if len(s) < 32 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// companionPattern returns the pattern a checksum or signature file follows,
+// and its index: the first active pattern with companions set that matches
+// the file it belongs to, with the target filled in from that file's name
+func (e *Engine) companionPattern(path string) (types.Pattern, int, bool) {
+	artifacts := companionArtifacts(path)
+	if len(artifacts) == 0 {
+		return types.Pattern{}, -1, false
+	}
+
+	depth := e.depth(path)
+	for _, artifact := range artifacts {
+		for i, pattern := range e.patterns {
+			if !pattern.Companions || !e.profiles.Active(pattern.Profile) {
+				continue
+			}
+			expanded, matched, err := MatchPattern(pattern, artifact)
+			if err != nil || !matched {
+				continue
+			}
+			if (pattern.MinDepth > 0 || pattern.MaxDepth > 0) && !InDepth(pattern, depth) {
+				continue
+			}
+			return expanded, i, true
+		}
+	}
+	return types.Pattern{}, -1, false
+}
@@ -0,0 +1,58 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	digest := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	write("tool-1.0-linux-amd64.tar.gz", "")
+	write("ubuntu.iso", "")
+	sums := write("SHA256SUMS", digest+"  notes.txt\n"+digest+" *tool-1.0-linux-amd64.tar.gz\n")
+	bsd := write("CHECKSUMS.txt", "SHA256 (ubuntu.iso) = "+digest+"\n")
+
+	cfg := &config.Config{}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: `(?P<tool>[a-z]+)-.*\.tar\.gz`, Type: types.RegexPattern, Target: "Tools/{tool}", Companions: true},
+		{Match: "*.iso", Target: "Images", Companions: true},
+		{Match: "*.txt", Target: "Text"},
+		{Match: "*", Target: "Other"},
+	}
+	engine := NewWithConfig(cfg)
+
+	for path, want := range map[string]string{
+		filepath.Join(dir, "ubuntu.iso.sha256"):                     "Images",
+		filepath.Join(dir, "ubuntu.ISO.asc"):                        "Other", // Matching is case sensitive without ignore_case
+		filepath.Join(dir, "tool-1.0-linux-amd64.tar.gz.sha256sum"): "Tools/tool",
+		sums:                                 "Tools/tool",
+		filepath.Join(dir, "SHA256SUMS.asc"): "Tools/tool",
+		bsd:                                  "Images",
+		filepath.Join(dir, "moved-already.iso.sig"): "Images",
+		filepath.Join(dir, "notes.txt.sha256"):      "Other", // notes.txt's pattern doesn't take companions
+	} {
+		pattern, found := engine.MatchingPattern(path)
+		if assert.True(t, found, path) {
+			assert.Equal(t, want, pattern.Target, path)
+		}
+	}
+
+	matches := engine.ExplainPatterns(filepath.Join(dir, "ubuntu.iso.sha256"))
+	require.Len(t, matches, 4)
+	assert.True(t, matches[1].Applies)
+	assert.Equal(t, filepath.Join(dir, "Images", "ubuntu.iso.sha256"), matches[1].Destination)
+	assert.False(t, matches[3].Applies)
+}
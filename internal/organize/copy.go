@@ -0,0 +1,84 @@
+package organize
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/fsops"
+)
+
+// SetCopy sets whether files are copied to their destinations instead of
+// moved, as settings.copy does for every rule
+func (e *Engine) SetCopy(copy bool) {
+	e.copy = copy
+}
+
+// Copies reports whether the engine copies src instead of moving it: copy
+// mode is on for every file, or the rule that matches src has copy set. The
+// original is then never changed, which suits append-only sources such as a
+// camera card or a shared drive.
+func (e *Engine) Copies(src string) bool {
+	if e.copy {
+		return true
+	}
+	pattern, found := e.MatchingPattern(src)
+	return found && pattern.Copy
+}
+
+// copyFile copies src to dest through a staged temp file, keeping src's
+// modification time so the copy sorts and ages like the original
+func copyFile(files fsops.FS, src, dest string, info os.FileInfo) error {
+	if err := files.CopyFile(src, dest); err != nil {
+		return err
+	}
+	return files.Chtimes(dest, info.ModTime(), info.ModTime())
+}
+
+// existingCopy returns a file in dir holding the same content as src, found
+// by size and then SHA-256, or "" when there is none. Copying src again would
+// only make a copy of a copy. With recursive set, dir's subdirectories are
+// searched too, as for the unsorted folder's dated folders.
+func existingCopy(src string, size int64, dir string, recursive bool) (string, error) {
+	var srcSum []byte
+	found := ""
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || atomicfile.IsTemp(path) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() != size {
+			return nil
+		}
+
+		if srcSum == nil {
+			if srcSum, err = fileSHA256(src); err != nil {
+				return err
+			}
+		}
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return nil
+		}
+		if bytes.Equal(sum, srcSum) {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found, err
+}
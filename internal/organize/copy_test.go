@@ -0,0 +1,87 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveFile_Copy(t *testing.T) {
+	// setup creates a camera card with a photo and an empty library
+	setup := func(t *testing.T, cfg *config.Config) (*Engine, string, string) {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "card", "IMG_0001.jpg")
+		library := filepath.Join(dir, "library")
+		require.NoError(t, os.MkdirAll(filepath.Dir(src), 0755))
+		require.NoError(t, os.WriteFile(src, []byte("photo"), 0644))
+		taken := time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)
+		require.NoError(t, os.Chtimes(src, taken, taken))
+
+		cfg.Settings.CreateDirs = true
+		cfg.Settings.Collision = CollisionRename
+		return NewWithConfig(cfg), src, library
+	}
+
+	t.Run("originals stay and repeated runs don't copy again", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Settings.Copy = true
+		engine, src, library := setup(t, cfg)
+		dest := filepath.Join(library, "IMG_0001.jpg")
+
+		require.NoError(t, engine.MoveFile(src, dest))
+		assert.FileExists(t, src)
+		content, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "photo", string(content))
+		srcInfo, _ := os.Stat(src)
+		destInfo, _ := os.Stat(dest)
+		assert.True(t, destInfo.ModTime().Equal(srcInfo.ModTime()), "The copy should keep the original's time")
+
+		// Even under another name, the same content isn't copied twice
+		require.NoError(t, os.Rename(dest, filepath.Join(library, "renamed.jpg")))
+		require.NoError(t, engine.MoveFile(src, dest))
+		entries, _ := os.ReadDir(library)
+		assert.Len(t, entries, 1, "No copy of a copy should be made")
+	})
+
+	t.Run("rules can copy on their own", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Organize.Patterns = []types.Pattern{{Match: "*.jpg", Target: "photos", Copy: true}, {Match: "*.txt", Target: "notes"}}
+		engine, src, library := setup(t, cfg)
+
+		assert.True(t, engine.Copies(src))
+		assert.False(t, engine.Copies(filepath.Join(filepath.Dir(src), "todo.txt")))
+		require.NoError(t, engine.MoveFile(src, filepath.Join(library, "IMG_0001.jpg")))
+		assert.FileExists(t, src)
+	})
+
+	t.Run("originals of identical files are never deleted", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Settings.Duplicates = config.DuplicateSettings{CompareContent: true, DeleteIdentical: true}
+		engine, src, library := setup(t, cfg)
+		engine.SetCopy(true)
+		dest := filepath.Join(library, "IMG_0001.jpg")
+		require.NoError(t, os.MkdirAll(library, 0755))
+		require.NoError(t, os.WriteFile(dest, []byte("photo"), 0644))
+
+		require.NoError(t, engine.MoveFile(src, dest))
+		assert.FileExists(t, src)
+	})
+
+	t.Run("dry runs copy nothing", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Settings.Copy = true
+		cfg.Settings.DryRun = true
+		engine, src, library := setup(t, cfg)
+
+		require.NoError(t, engine.MoveFile(src, filepath.Join(library, "IMG_0001.jpg")))
+		assert.NoDirExists(t, library)
+	})
+}
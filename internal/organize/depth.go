@@ -0,0 +1,73 @@
+package organize
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sortd/pkg/types"
+)
+
+// Depth returns how deep path lies below the deepest of roots holding it: 1
+// directly in the root, 2 in one of its subfolders, and so on. A file in none
+// of the roots counts as top-level.
+func Depth(roots []string, path string) int {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return 1
+	}
+	depth, best := 1, -1
+	for _, root := range roots {
+		rootAbs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(rootAbs, filepath.Dir(abs))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(rootAbs) > best {
+			best = len(rootAbs)
+			depth = 1
+			if rel != "." {
+				depth += strings.Count(rel, string(filepath.Separator)) + 1
+			}
+		}
+	}
+	return depth
+}
+
+// InDepth reports whether a file at depth is within the pattern's min_depth
+// and max_depth
+func InDepth(pattern types.Pattern, depth int) bool {
+	return (pattern.MinDepth <= 0 || depth >= pattern.MinDepth) &&
+		(pattern.MaxDepth <= 0 || depth <= pattern.MaxDepth)
+}
+
+// depthReason explains why a pattern doesn't apply to a file at depth, or
+// returns "" when it does
+func depthReason(pattern types.Pattern, depth int) string {
+	if InDepth(pattern, depth) {
+		return ""
+	}
+	if pattern.MaxDepth > 0 && depth > pattern.MaxDepth {
+		return fmt.Sprintf("at depth %d, deeper than max_depth %d", depth, pattern.MaxDepth)
+	}
+	return fmt.Sprintf("at depth %d, shallower than min_depth %d", depth, pattern.MinDepth)
+}
+
+// SetRoots sets the directories the depth of files is counted from, for
+// patterns with min_depth or max_depth. Engines built from a config start out
+// with the watch directories.
+func (e *Engine) SetRoots(roots []string) {
+	e.rootsMu.Lock()
+	defer e.rootsMu.Unlock()
+	e.roots = append([]string(nil), roots...)
+}
+
+// depth returns how deep path lies below the engine's roots
+func (e *Engine) depth(path string) int {
+	e.rootsMu.RLock()
+	defer e.rootsMu.RUnlock()
+	return Depth(e.roots, path)
+}
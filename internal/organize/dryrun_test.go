@@ -0,0 +1,135 @@
+package organize
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/internal/fsops"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshot returns every file and directory under root with its content
+func snapshot(t *testing.T, root string) map[string]string {
+	t.Helper()
+	tree := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		if d.IsDir() {
+			tree[rel] = "(dir)"
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		tree[rel] = string(data)
+		return err
+	})
+	require.NoError(t, err)
+	return tree
+}
+
+// newDryRunFixture sets up files that take every write path of MoveFile:
+// created directories, copies, backups of overwritten files and deleted
+// duplicates. It returns the engine, the inbox and the root of the tree.
+func newDryRunFixture(t *testing.T, dryRun bool) (*Engine, *fsops.Recorder, string, string) {
+	t.Helper()
+	t.Setenv(config.StateDirEnv, t.TempDir())
+	root := t.TempDir()
+	inbox := filepath.Join(root, "inbox")
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("inbox/report.pdf", "report")
+	write("inbox/photo.jpg", "photo")
+	write("inbox/notes.txt", "new notes")
+	write("notes/notes.txt", "old notes")
+	write("inbox/same.csv", "1,2,3")
+	write("data/same.csv", "1,2,3")
+
+	cfg := &config.Config{}
+	cfg.Settings.DryRun = dryRun
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.Backup = true
+	cfg.Settings.Collision = CollisionOverwrite
+	cfg.Settings.Duplicates = config.DuplicateSettings{CompareContent: true, DeleteIdentical: true}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: filepath.Join(root, "docs")},
+		{Match: "*.jpg", Target: filepath.Join(root, "photos"), Copy: true},
+		{Match: "*.txt", Target: filepath.Join(root, "notes")},
+		{Match: "*.csv", Target: filepath.Join(root, "data")},
+	}
+
+	engine := NewWithConfig(cfg)
+	recorder := fsops.NewRecorder(fsops.OS)
+	engine.fs = recorder
+	return engine, recorder, inbox, root
+}
+
+func TestDryRunWritesNothing(t *testing.T) {
+	runs := map[string]func(e *Engine, inbox string) error{
+		"OrganizeDirectory": func(e *Engine, inbox string) error {
+			_, err := e.OrganizeDirectory(inbox)
+			return err
+		},
+		"OrganizeByPatterns": func(e *Engine, inbox string) error {
+			files, err := filepath.Glob(filepath.Join(inbox, "*"))
+			require.NoError(t, err)
+			return e.OrganizeByPatterns(files)
+		},
+		"MoveFile": func(e *Engine, inbox string) error {
+			for _, name := range []string{"report.pdf", "photo.jpg", "notes.txt", "same.csv"} {
+				src := filepath.Join(inbox, name)
+				dir, _ := e.DestinationDir(src)
+				if err := e.MoveFile(src, filepath.Join(dir, name)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	for name, run := range runs {
+		t.Run(name, func(t *testing.T) {
+			engine, recorder, inbox, root := newDryRunFixture(t, true)
+			before := snapshot(t, root)
+			require.NoError(t, run(engine, inbox))
+			assert.Empty(t, recorder.Ops(), "A dry run shouldn't reach the file system")
+			assert.Equal(t, before, snapshot(t, root))
+
+			// The same run for real goes through the recorder, so the check
+			// above would have seen the writes
+			engine, recorder, inbox, root = newDryRunFixture(t, false)
+			before = snapshot(t, root)
+			require.NoError(t, run(engine, inbox))
+			assert.NotEmpty(t, recorder.Ops())
+			assert.NotEqual(t, before, snapshot(t, root))
+		})
+	}
+}
+
+func TestDryRunLayerStopsUncheckedWrites(t *testing.T) {
+	engine, recorder, inbox, root := newDryRunFixture(t, true)
+	before := snapshot(t, root)
+
+	// Even paths that don't check for a dry run themselves change nothing
+	require.NoError(t, engine.createBackup(filepath.Join(root, "notes", "notes.txt")))
+	require.NoError(t, copyFile(engine.fsys(), filepath.Join(inbox, "photo.jpg"), filepath.Join(root, "copy.jpg"), mustStat(t, filepath.Join(inbox, "photo.jpg"))))
+	assert.Empty(t, recorder.Ops())
+	assert.Equal(t, before, snapshot(t, root))
+}
+
+func mustStat(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info
+}
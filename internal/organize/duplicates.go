@@ -0,0 +1,99 @@
+package organize
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sortd/internal/errors"
+)
+
+// Naming schemes for copies kept under a new name, as set in settings.duplicates.versioning
+const (
+	VersionSuffix  = ""        // report_(1).pdf, report_(2).pdf (the default)
+	VersionCounter = "counter" // report (2).pdf, report (3).pdf
+	VersionDate    = "date"    // report_2024-05-01.pdf, from the incoming file's modification date
+)
+
+// maxVersions is how many names are tried before giving up
+const maxVersions = 1000
+
+// SameContent reports whether two files hold the same bytes. Sizes are
+// compared first, so files that differ in size are never read.
+func SameContent(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	sumA, err := fileSHA256(a)
+	if err != nil {
+		return false, err
+	}
+	sumB, err := fileSHA256(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(sumA, sumB), nil
+}
+
+// fileSHA256 hashes a file's content
+func fileSHA256(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// VersionedName returns a free path to keep a file under when path is taken,
+// named by the versioning scheme. The date scheme uses modTime, falling back
+// to counters when that date is taken too.
+func VersionedName(path, versioning string, modTime time.Time) (string, error) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+
+	var candidate func(n int) string
+	switch versioning {
+	case VersionSuffix:
+		candidate = func(n int) string { return fmt.Sprintf("%s_(%d)%s", base, n, ext) }
+	case VersionCounter:
+		candidate = func(n int) string { return fmt.Sprintf("%s (%d)%s", base, n+1, ext) }
+	case VersionDate:
+		dated := base + "_" + modTime.Format("2006-01-02")
+		candidate = func(n int) string {
+			if n == 1 {
+				return dated + ext
+			}
+			return fmt.Sprintf("%s (%d)%s", dated, n, ext)
+		}
+	default:
+		return "", fmt.Errorf("unknown versioning %q: must be counter or date", versioning)
+	}
+
+	for n := 1; n <= maxVersions; n++ {
+		name := candidate(n)
+		if _, err := os.Lstat(name); os.IsNotExist(err) {
+			return name, nil
+		}
+	}
+	return "", errors.New(fmt.Sprintf("couldn't find a unique name after %d attempts", maxVersions))
+}
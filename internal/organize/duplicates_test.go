@@ -0,0 +1,125 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSameContent(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	a := write("a", "hello")
+	b := write("b", "hello")
+	c := write("c", "hellO")
+	d := write("d", "hello world")
+
+	same, err := SameContent(a, b)
+	require.NoError(t, err)
+	assert.True(t, same)
+
+	same, err = SameContent(a, c)
+	require.NoError(t, err)
+	assert.False(t, same, "Same size, different bytes")
+
+	same, err = SameContent(a, d)
+	require.NoError(t, err)
+	assert.False(t, same)
+
+	_, err = SameContent(a, filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestVersionedName(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.pdf")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		versioning string
+		taken      []string
+		want       string
+	}{
+		{VersionSuffix, nil, "report_(1).pdf"},
+		{VersionSuffix, []string{"report_(1).pdf"}, "report_(2).pdf"},
+		{VersionCounter, nil, "report (2).pdf"},
+		{VersionCounter, []string{"report (2).pdf"}, "report (3).pdf"},
+		{VersionDate, nil, "report_2024-05-01.pdf"},
+		{VersionDate, []string{"report_2024-05-01.pdf"}, "report_2024-05-01 (2).pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.versioning+"/"+tt.want, func(t *testing.T) {
+			for _, name := range tt.taken {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+				defer os.Remove(filepath.Join(dir, name))
+			}
+			got, err := VersionedName(path, tt.versioning, modTime)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tt.want), got)
+		})
+	}
+
+	_, err := VersionedName(path, "bogus", modTime)
+	assert.Error(t, err)
+}
+
+func TestMoveFile_Duplicates(t *testing.T) {
+	// setup creates an incoming file and an existing one at its destination
+	setup := func(t *testing.T, incoming, existing string, duplicates config.DuplicateSettings) (*Engine, string, string) {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "report.pdf")
+		dest := filepath.Join(dir, "docs", "report.pdf")
+		require.NoError(t, os.WriteFile(src, []byte(incoming), 0644))
+		require.NoError(t, os.MkdirAll(filepath.Dir(dest), 0755))
+		require.NoError(t, os.WriteFile(dest, []byte(existing), 0644))
+
+		cfg := &config.Config{}
+		cfg.Settings.Collision = CollisionRename
+		cfg.Settings.Duplicates = duplicates
+		return NewWithConfig(cfg), src, dest
+	}
+
+	t.Run("identical files are skipped", func(t *testing.T) {
+		engine, src, dest := setup(t, "same", "same", config.DuplicateSettings{CompareContent: true})
+		require.NoError(t, engine.MoveFile(src, dest))
+
+		assert.FileExists(t, src)
+		entries, _ := os.ReadDir(filepath.Dir(dest))
+		assert.Len(t, entries, 1, "No renamed copy should be made")
+	})
+
+	t.Run("identical sources can be deleted", func(t *testing.T) {
+		engine, src, dest := setup(t, "same", "same", config.DuplicateSettings{CompareContent: true, DeleteIdentical: true})
+		require.NoError(t, engine.MoveFile(src, dest))
+
+		assert.NoFileExists(t, src)
+		assert.FileExists(t, dest)
+	})
+
+	t.Run("different files get a versioned name", func(t *testing.T) {
+		engine, src, dest := setup(t, "new", "old", config.DuplicateSettings{CompareContent: true, Versioning: VersionCounter})
+		require.NoError(t, engine.MoveFile(src, dest))
+
+		assert.NoFileExists(t, src)
+		content, err := os.ReadFile(filepath.Join(filepath.Dir(dest), "report (2).pdf"))
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(content))
+	})
+
+	t.Run("without comparison identical files are renamed", func(t *testing.T) {
+		engine, src, dest := setup(t, "same", "same", config.DuplicateSettings{})
+		require.NoError(t, engine.MoveFile(src, dest))
+		assert.FileExists(t, filepath.Join(filepath.Dir(dest), "report_(1).pdf"))
+	})
+}
@@ -1,17 +1,23 @@
 package organize
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"sortd/internal/atomicfile"
 	"sortd/internal/config"
 	"sortd/internal/errors"
+	"sortd/internal/fsops"
 	"sortd/internal/log"
+	"sortd/internal/manifest"
+	"sortd/internal/profile"
+	"sortd/internal/quota"
+	"sortd/internal/screenshot"
 	"sortd/pkg/types"
 )
 
@@ -23,8 +29,35 @@ type Engine struct {
 	mu         sync.RWMutex // Protects files map
 	createDirs bool
 	backup     bool
+	copy       bool // Copy every file instead of moving it
 	collision  string
 	config     *config.Config
+
+	// Decides collisions under the "ask" strategy; nil skips them
+	resolver CollisionResolver
+
+	// Refuses every change while set; can be toggled while moves are running
+	readOnly atomic.Bool
+
+	// Refuses every change for good, as set in settings.audit
+	audit bool
+
+	// Limits on destination directories; nil when none are configured
+	quotas *quota.Checker
+
+	// Checksum manifests of archive directories; nil when none are configured
+	manifests *manifest.Keeper
+
+	// Which config profiles are active; patterns of inactive ones are skipped
+	profiles *profile.Checker
+
+	// Where moves, copies and deletes go; nil for the real file system. Dry
+	// runs replace it with one that changes nothing (see fsys).
+	fs fsops.FS
+
+	// Directories the depth of files is counted from (see SetRoots)
+	roots   []string
+	rootsMu sync.RWMutex
 }
 
 func (e *Engine) OrganizeFile(path string) error {
@@ -58,15 +91,24 @@ func New() *Engine {
 
 // NewWithConfig creates a new Organization Engine instance with configuration
 func NewWithConfig(cfg *config.Config) *Engine {
-	return &Engine{
+	e := &Engine{
 		files:      make(map[string]types.FileInfo),
-		patterns:   cfg.Organize.Patterns,
+		patterns:   OrderPatterns(cfg.Organize.Patterns),
 		dryRun:     cfg.Settings.DryRun,
 		createDirs: cfg.Settings.CreateDirs,
 		backup:     cfg.Settings.Backup,
+		copy:       cfg.Settings.Copy,
 		collision:  cfg.Settings.Collision,
 		config:     cfg,
-	}
+		quotas:     quota.New(cfg.Settings.Quotas),
+		manifests:  manifest.New(cfg.Settings.Manifests),
+		profiles:   profile.NewChecker(cfg.Profiles),
+		fs:         fsops.Throttled(cfg.Settings.Priority.BandwidthLimit()),
+		roots:      cfg.WatchPaths(),
+	}
+	e.readOnly.Store(cfg.Settings.ReadOnly)
+	e.audit = cfg.Settings.Audit
+	return e
 }
 
 // SetDryRun sets whether operations should be performed or just simulated
@@ -74,24 +116,102 @@ func (e *Engine) SetDryRun(dryRun bool) {
 	e.dryRun = dryRun
 }
 
+// SetCreateDirs sets whether missing destination directories are created
+func (e *Engine) SetCreateDirs(createDirs bool) {
+	e.createDirs = createDirs
+}
+
+// fsys returns the layer the engine changes files through, which changes
+// nothing in dry runs
+func (e *Engine) fsys() fsops.FS {
+	if e.fs == nil {
+		return fsops.For(fsops.OS, e.dryRun)
+	}
+	return fsops.For(e.fs, e.dryRun)
+}
+
 // IsDryRun returns whether the engine is in dry run mode
 func (e *Engine) IsDryRun() bool {
 	return e.dryRun
 }
 
+// SetReadOnly sets whether the engine refuses to change anything. Dry runs
+// still work in read-only mode; real moves fail with a ReadOnlyMode error.
+func (e *Engine) SetReadOnly(readOnly bool) {
+	e.readOnly.Store(readOnly)
+}
+
+// IsReadOnly returns whether the engine is in read-only mode, which audit
+// mode implies
+func (e *Engine) IsReadOnly() bool {
+	return e.readOnly.Load() || e.audit
+}
+
+// IsAudit returns whether the engine was built for audit mode, in which it
+// changes nothing whatever SetReadOnly says
+func (e *Engine) IsAudit() bool {
+	return e.audit
+}
+
+// Quotas returns the checker of the configured quotas, nil when there are
+// none, so that other movers such as workflows can share its usage counts
+func (e *Engine) Quotas() *quota.Checker {
+	return e.quotas
+}
+
+// Manifests returns the keeper of the archive directories' checksum
+// manifests, nil when there are none, so that workflows can share it
+func (e *Engine) Manifests() *manifest.Keeper {
+	return e.manifests
+}
+
+// Profiles returns the checker for which config profiles are active, so the
+// daemon and workflows can share it
+func (e *Engine) Profiles() *profile.Checker {
+	return e.profiles
+}
+
+// addToManifest records a file that arrived at dest in its folder's checksum
+// manifest, if it has one. A failure is logged: the file itself is in place.
+func (e *Engine) addToManifest(src, dest string) {
+	if err := e.manifests.Added(src, dest); err != nil {
+		log.LogWithFields(log.F("destination", dest), log.F("error", err.Error())).Warn("Failed to update checksum manifest")
+	}
+}
+
 // AddPattern adds a new organization pattern
 func (e *Engine) AddPattern(pattern types.Pattern) {
-	e.patterns = append(e.patterns, pattern)
+	e.patterns = OrderPatterns(append(e.patterns, pattern))
 	log.Debugf("Added pattern: match=%s, target=%s", pattern.Match, pattern.Target)
 }
 
 // findDestination determines where a file should go based on patterns
 func (e *Engine) findDestination(filename string) (string, bool) {
+	pattern, found := e.MatchingPattern(filename)
+	if !found {
+		return "", false
+	}
+	// Return the pattern target as is - path joining will be handled in the calling function
+	// This allows proper handling of both absolute and relative paths
+	return pattern.Target, true
+}
+
+// MatchingPattern returns the first configured pattern that matches the file,
+// with any capture groups filled into its target. Checksum and signature files
+// take the pattern of the file they belong to when it sets companions.
+func (e *Engine) MatchingPattern(filename string) (types.Pattern, bool) {
 	logger := log.LogWithFields(log.F("file", filename))
 
+	if pattern, _, found := e.companionPattern(filename); found {
+		logger.With(
+			log.F("pattern", pattern.Match),
+			log.F("target", pattern.Target),
+		).Debug("Checksum or signature follows its file")
+		return pattern, true
+	}
+
 	for _, pattern := range e.patterns {
-		// Check glob pattern
-		matched, err := filepath.Match(pattern.Match, filepath.Base(filename))
+		pattern, matched, err := MatchPattern(pattern, filename)
 		if err != nil {
 			logger.With(
 				log.F("pattern", pattern.Match),
@@ -103,28 +223,70 @@ func (e *Engine) findDestination(filename string) (string, bool) {
 		if !matched {
 			continue
 		}
+		if !e.profiles.Active(pattern.Profile) {
+			logger.With(log.F("pattern", pattern.Match), log.F("profile", pattern.Profile)).Debug("Pattern matched but its profile isn't active")
+			continue
+		}
+		if (pattern.MinDepth > 0 || pattern.MaxDepth > 0) && !InDepth(pattern, e.depth(filename)) {
+			logger.With(log.F("pattern", pattern.Match)).Debug("Pattern matched outside its depths")
+			continue
+		}
 
-		// Return the pattern target as is - path joining will be handled in the calling function
-		// This allows proper handling of both absolute and relative paths
 		logger.With(
 			log.F("pattern", pattern.Match),
 			log.F("target", pattern.Target),
 		).Debug("Pattern matched")
 
-		return pattern.Target, true
+		return pattern, true
 	}
 
 	logger.Debug("No matching pattern found")
-	return "", false
+	return types.Pattern{}, false
+}
+
+// HasMatchingPattern reports whether any configured pattern matches the file
+func (e *Engine) HasMatchingPattern(filename string) bool {
+	_, found := e.findDestination(filename)
+	return found
+}
+
+// DestinationDir returns the directory a file would be moved to by the configured
+// patterns. Relative targets are resolved against the file's own directory.
+func (e *Engine) DestinationDir(file string) (string, bool) {
+	destDir, found := e.findDestination(file)
+	if !found {
+		return "", false
+	}
+	if filepath.IsAbs(destDir) {
+		return filepath.Clean(destDir), true
+	}
+	return filepath.Join(filepath.Dir(file), destDir), true
 }
 
 // MoveFile moves a file from source to destination, handling collisions based on config.
+// Files the engine copies (see Copies) are copied instead, unless the
+// destination directory already holds one with the same content.
 func (e *Engine) MoveFile(src, dest string) error {
+	return e.MoveFileContext(context.Background(), src, dest)
+}
+
+// MoveFileContext is MoveFile whose log lines carry the trace ID in ctx
+func (e *Engine) MoveFileContext(ctx context.Context, src, dest string) error {
+	_, err := e.MoveFileTo(ctx, src, dest)
+	return err
+}
+
+// MoveFileTo is MoveFileContext that also returns where the file ended up,
+// which collision handling may have renamed. It is "" when the file was left
+// where it was: in a dry run, or when it was skipped.
+func (e *Engine) MoveFileTo(ctx context.Context, src, dest string) (string, error) {
+	copying := e.Copies(src)
 	logger := log.LogWithFields(
 		log.F("source", src),
 		log.F("destination", dest),
 		log.F("dry_run", e.dryRun),
-	)
+		log.F("copy", copying),
+	).WithContext(ctx)
 
 	// Clean paths for comparison
 	cleanSrc := filepath.Clean(src)
@@ -134,57 +296,130 @@ func (e *Engine) MoveFile(src, dest string) error {
 	if cleanSrc == cleanDest {
 		// Moving to the same place is not an error, just do nothing.
 		logger.Debug("Source and destination are the same, skipping")
-		return nil
+		return "", nil
 	}
 
 	// Verify source exists and get info
 	srcInfo, err := os.Stat(cleanSrc)
+	if os.IsNotExist(err) && !e.dryRun && e.movedByAnotherActor(cleanSrc) {
+		logger.Info("File was already moved by another process, skipping")
+		return "", nil
+	}
 	if err != nil {
-		return errors.NewFileError("source file error", cleanSrc, errors.FileAccessDenied, err)
+		return "", errors.NewOSFileError("source file error", cleanSrc, errors.FileAccessDenied, err)
 	}
 	if srcInfo.IsDir() {
-		return errors.NewFileError("cannot move directory as file", cleanSrc, errors.InvalidOperation, nil)
+		return "", errors.NewFileError("cannot move directory as file", cleanSrc, errors.InvalidOperation, nil)
+	}
+
+	if e.IsReadOnly() && !e.dryRun {
+		if e.audit {
+			return "", errors.NewFileError("audit mode is on, not moving", cleanSrc, errors.ReadOnlyMode, nil)
+		}
+		return "", errors.NewFileError("read-only mode is on, not moving", cleanSrc, errors.ReadOnlyMode, nil)
 	}
 
 	// Check if destination directory exists
 	destDir := filepath.Dir(cleanDest)
 	if _, err := os.Stat(destDir); os.IsNotExist(err) {
-		// If createDirs is false, return an error
-		if !e.createDirs {
-			return errors.NewFileError("destination directory does not exist", destDir, errors.FileAccessDenied, nil)
+		// If createDirs is false, return an error. The unsorted and
+		// screenshots folders are sortd's own, so their dated folders are
+		// created regardless.
+		if !e.createDirs && !InUnsorted(e.config, destDir) && !(screenshot.Enabled(e.config) && screenshot.InFolder(e.config, destDir)) {
+			return "", errors.NewFileError("destination directory does not exist", destDir, errors.FileAccessDenied, nil)
 		}
 
 		// Create directory if createDirs is true
 		if !e.dryRun {
-			if err := os.MkdirAll(destDir, 0755); err != nil {
-				return errors.NewFileError("failed to create destination directory", destDir, errors.FileCreateFailed, err)
+			if err := e.fsys().MkdirAll(destDir, 0755); err != nil {
+				return "", errors.NewOSFileError("failed to create destination directory", destDir, errors.FileCreateFailed, err)
 			}
 		}
 	} else if err != nil {
-		return errors.NewFileError("error checking destination directory", destDir, errors.FileAccessDenied, err)
+		return "", errors.NewOSFileError("error checking destination directory", destDir, errors.FileAccessDenied, err)
+	}
+
+	// A copy leaves the source in place, so it counts against quotas as new
+	// data, and repeated runs find the copy they made before
+	quotaSrc := cleanSrc
+	if copying {
+		quotaSrc = ""
+		// Unmatched files land in a new dated folder each day, so look in all of them
+		searchDir, recursive := destDir, false
+		if InUnsorted(e.config, destDir) {
+			searchDir, recursive = UnsortedFolder(e.config), true
+		}
+		existing, err := existingCopy(cleanSrc, srcInfo.Size(), searchDir, recursive)
+		if err != nil {
+			return "", errors.NewOSFileError("error looking for an earlier copy", searchDir, errors.FileAccessDenied, err)
+		}
+		if existing != "" {
+			logger.With(log.F("earlier_copy", existing)).Info("Already copied, skipping")
+			return "", nil
+		}
 	}
 
 	// Check for dry run mode first
 	if e.dryRun {
-		logger.Info("Would move file (dry run)")
-		return nil
+		if err := quota.Refusal(e.quotas.Check(quotaSrc, cleanDest, srcInfo.Size())); err != nil {
+			return "", err
+		}
+		if copying {
+			logger.Info("Would copy file (dry run)")
+		} else {
+			logger.Info("Would move file (dry run)")
+		}
+		return "", nil
+	}
+
+	if err := e.quotas.Enforce(quotaSrc, cleanDest, srcInfo.Size()); err != nil {
+		return "", err
+	}
+
+	// Under "ask", a collision is answered before the locks are taken: the
+	// user may take longer to answer than other sortd processes wait for them
+	answer, err := e.answerCollision(cleanSrc, cleanDest)
+	if err != nil {
+		return "", err
+	}
+
+	// Coordinate with other sortd processes (e.g. the watch daemon and a manual
+	// organize) working on the same source or destination directory
+	unlock, err := lockDirectories(filepath.Dir(cleanSrc), destDir)
+	if err != nil {
+		return "", errors.NewOSFileError("failed to lock directory", filepath.Dir(cleanSrc), errors.FileOperationFailed, err)
+	}
+	defer unlock()
+
+	// Only move the file we planned to move: another actor may have moved,
+	// replaced or rewritten it while we waited for the lock
+	currentInfo, err := os.Stat(cleanSrc)
+	if os.IsNotExist(err) && movedRecently(cleanSrc) {
+		logger.Info("File was already moved by another process, skipping")
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.NewOSFileError("source file error", cleanSrc, errors.FileAccessDenied, err)
+	}
+	if fileKeyOf(currentInfo) != fileKeyOf(srcInfo) {
+		return "", errors.NewFileError("file changed while waiting for the directory lock, not moving it", cleanSrc, errors.InvalidOperation, nil)
 	}
 
 	// Determine final destination path with collision handling
 	// This needs to be atomic with the actual move operation
 	e.mu.Lock()
-	finalDest, err := e.handleCollision(cleanSrc, cleanDest)
+	finalDest, err := e.handleCollision(cleanSrc, cleanDest, answer)
 	e.mu.Unlock()
 
 	if err != nil {
 		log.LogError(err, "Collision handling failed")
-		return err
+		return "", err
 	}
 
 	// If finalDest is empty, it means we're skipping the move
 	if finalDest == "" {
 		logger.Info("Skipping file move due to collision handling")
-		return nil
+		return "", nil
 	}
 
 	// Create backup if needed
@@ -195,25 +430,55 @@ func (e *Engine) MoveFile(src, dest string) error {
 		if _, err := os.Stat(finalDest); err == nil {
 			// File exists, create backup
 			if err := e.createBackup(finalDest); err != nil {
-				return errors.Wrap(err, "backup failed")
+				return "", errors.Wrap(err, "backup failed")
 			}
 		}
 	}
 
+	if copying {
+		logger.With(log.F("final_destination", finalDest)).Debug("Copying file")
+		if err := copyFile(e.fsys(), cleanSrc, finalDest, srcInfo); err != nil {
+			return "", errors.NewOSFileError("failed to copy file", cleanSrc, errors.FileOperationFailed, err)
+		}
+		e.quotas.Added("", finalDest, srcInfo.Size())
+		e.addToManifest("", finalDest)
+		logger.With(log.F("final_destination", finalDest)).Info("Copied file successfully")
+		return finalDest, nil
+	}
+
 	// Move the file
 	logger.With(log.F("final_destination", finalDest)).Debug("Moving file")
-	if err := os.Rename(cleanSrc, finalDest); err != nil {
-		return errors.NewFileError("failed to move file", cleanSrc, errors.FileOperationFailed, err)
+	if err := e.fsys().Rename(cleanSrc, finalDest); err != nil {
+		return "", errors.NewOSFileError("failed to move file", cleanSrc, errors.FileOperationFailed, err)
+	}
+
+	e.quotas.Added(cleanSrc, finalDest, srcInfo.Size())
+	e.addToManifest(cleanSrc, finalDest)
+
+	// Remember the move so a late second actor skips it instead of failing
+	if err := recordMove(fileKeyOf(srcInfo), cleanSrc, finalDest); err != nil {
+		logger.With(log.F("error", err.Error())).Warn("Failed to record move in journal")
 	}
 
 	logger.With(log.F("final_destination", finalDest)).Info("Moved file successfully")
-	return nil
+	return finalDest, nil
+}
+
+// movedByAnotherActor reports whether a missing source file was recently moved
+// away by a sortd process, rather than never having existed
+func (e *Engine) movedByAnotherActor(src string) bool {
+	unlock, err := lockDirectories(filepath.Dir(src))
+	if err != nil {
+		return false
+	}
+	defer unlock()
+	return movedRecently(src)
 }
 
 // handleCollision implements collision resolution strategies.
 // It returns the final destination path and an error if any.
 // If the file should be skipped, it returns an empty string and nil error.
-func (e *Engine) handleCollision(src, dest string) (string, error) {
+func (e *Engine) handleCollision(src, dest, answer string) (string, error) {
 	logger := log.LogWithFields(
 		log.F("source", src),
 		log.F("destination", dest),
@@ -228,7 +493,27 @@ func (e *Engine) handleCollision(src, dest string) (string, error) {
 	}
 	if err != nil {
 		// Some other error occurred
-		return "", errors.NewFileError("error checking destination", dest, errors.FileAccessDenied, err)
+		return "", errors.NewOSFileError("error checking destination", dest, errors.FileAccessDenied, err)
+	}
+
+	// An identical copy is already there: nothing to keep, rename or ask about
+	duplicates := e.duplicateSettings()
+	if duplicates.CompareContent {
+		same, err := SameContent(src, dest)
+		if err != nil {
+			return "", errors.NewFileError("error comparing with destination", dest, errors.FileAccessDenied, err)
+		}
+		if same {
+			logger.Info("Destination already holds an identical file, skipping")
+			// Copied files' originals are never touched
+			if duplicates.DeleteIdentical && !e.Copies(src) {
+				if err := e.fsys().Remove(src); err != nil {
+					return "", errors.NewFileError("failed to delete identical duplicate", src, errors.FileOperationFailed, err)
+				}
+				logger.Info("Deleted identical duplicate")
+			}
+			return "", nil
+		}
 	}
 
 	// Handle collision based on strategy
@@ -241,49 +526,58 @@ func (e *Engine) handleCollision(src, dest string) (string, error) {
 		logger.Info("Using default collision strategy: skip")
 	}
 
+	// Carry out the answer the frontend got from the user. A file that
+	// appeared while the move waited for the locks wasn't asked about; the
+	// move fails, and the next attempt asks.
+	if collisionStrategy == CollisionAsk {
+		if answer == "" {
+			return "", errors.NewFileError("a file appeared at the destination while waiting for the directory lock, not moving", dest, errors.InvalidOperation, nil)
+		}
+		collisionStrategy = answer
+		logger = logger.With(log.F("choice", answer))
+	}
+
 	switch collisionStrategy {
-	case "skip":
+	case CollisionSkip:
 		logger.Info("Skipping move due to collision")
 		return "", nil // Empty string signals skip
 
-	case "overwrite":
+	case CollisionOverwrite:
 		logger.Warn("Overwriting destination file")
 		return dest, nil // Return original dest for overwriting
 
-	case "rename":
+	case CollisionRename:
 		// Find a new name by incrementing counter
-		return e.findUniqueDestName(dest)
-
-	case "ask":
-		// For now, skip when ask is specified
-		logger.Warn("Collision strategy 'ask' not implemented, treating as 'skip'")
-		return "", nil
+		return e.findUniqueDestName(src, dest)
 
 	default:
 		return "", errors.NewConfigError("unknown collision strategy: "+collisionStrategy, collisionStrategy, errors.InvalidConfig, nil)
 	}
 }
 
-// findUniqueDestName finds a unique filename by adding counter to the basename
-func (e *Engine) findUniqueDestName(originalPath string) (string, error) {
-	logger := log.LogWithFields(log.F("original_path", originalPath))
-
-	ext := filepath.Ext(originalPath)
-	base := strings.TrimSuffix(originalPath, ext)
+// findUniqueDestName finds a free name to keep the incoming file under, named
+// per settings.duplicates.versioning
+func (e *Engine) findUniqueDestName(src, originalPath string) (string, error) {
+	var modTime time.Time
+	if info, err := os.Stat(src); err == nil {
+		modTime = info.ModTime()
+	}
 
-	for counter := 1; counter <= 1000; counter++ {
-		newName := fmt.Sprintf("%s_(%d)%s", base, counter, ext)
+	newName, err := VersionedName(originalPath, e.duplicateSettings().Versioning, modTime)
+	if err != nil {
+		log.LogWithFields(log.F("original_path", originalPath)).Warn("Could not find unique filename")
+		return "", err
+	}
+	log.LogWithFields(log.F("original_path", originalPath), log.F("new_name", newName)).Debug("Found unique destination name")
+	return newName, nil
+}
 
-		// Check if this name exists
-		_, err := os.Stat(newName)
-		if os.IsNotExist(err) {
-			// Found a name that doesn't exist
-			logger.With(log.F("new_name", newName)).Debug("Found unique destination name")
-			return newName, nil
-		}
+// duplicateSettings returns how collisions with existing files are resolved
+func (e *Engine) duplicateSettings() config.DuplicateSettings {
+	if e.config == nil {
+		return config.DuplicateSettings{}
 	}
-	logger.Warn("Could not find unique filename after 1000 attempts")
-	return "", errors.New("couldn't find a unique name after 1000 attempts")
+	return e.config.Settings.Duplicates
 }
 
 // createBackup creates a backup of the destination file if it exists
@@ -300,7 +594,7 @@ func (e *Engine) createBackup(dest string) error {
 
 	// Create backup directory if it doesn't exist
 	backupDir := filepath.Dir(dest)
-	if err := os.MkdirAll(backupDir, 0755); err != nil {
+	if err := e.fsys().MkdirAll(backupDir, 0755); err != nil {
 		return errors.NewFileError("failed to create backup directory", backupDir, errors.FileCreateFailed, err)
 	}
 
@@ -327,20 +621,8 @@ func (e *Engine) createBackup(dest string) error {
 		}
 	}
 
-	srcFile, err := os.Open(dest)
-	if err != nil {
-		return err
-	}
-	defer srcFile.Close()
-
-	destFile, err := os.Create(backupPath)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
-
-	_, err = io.Copy(destFile, srcFile)
-	if err != nil {
+	// Staged so a crash never leaves a truncated backup behind
+	if err := e.fsys().CopyFile(dest, backupPath); err != nil {
 		return err
 	}
 
@@ -367,11 +649,26 @@ func (e *Engine) OrganizeFiles(files []string, destDir string) error {
 
 // OrganizeByPatterns organizes files according to defined patterns
 func (e *Engine) OrganizeByPatterns(files []string) error {
-	logger := log.LogWithFields(log.F("file_count", len(files)))
+	return e.OrganizeByPatternsContext(context.Background(), files)
+}
+
+// OrganizeByPatternsContext is OrganizeByPatterns that stops before the next
+// file once ctx is cancelled. Files already moved stay moved.
+func (e *Engine) OrganizeByPatternsContext(ctx context.Context, files []string) error {
+	logger := log.LogWithFields(log.F("file_count", len(files))).WithContext(ctx)
 	logger.Info("Organizing files using patterns")
 	var firstError error // Keep track of the first error encountered
 
 	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Another sortd process is still writing this one
+		if atomicfile.IsTemp(file) {
+			continue
+		}
+
 		if destDir, found := e.findDestination(file); found {
 			// Construct proper destination path - use absolute path if destDir is absolute
 			var dest string
@@ -382,7 +679,7 @@ func (e *Engine) OrganizeByPatterns(files []string) error {
 				dest = filepath.Join(filepath.Dir(file), destDir, filepath.Base(file))
 			}
 
-			if err := e.MoveFile(file, dest); err != nil {
+			if err := e.MoveFileContext(ctx, file, dest); err != nil {
 				wrappedErr := errors.Wrapf(err, "failed to move %s", file)
 				log.LogError(wrappedErr, "Error during pattern organization") // Log the specific error
 				if firstError == nil {
@@ -419,13 +716,19 @@ func (e *Engine) OrganizeDir(dir string) ([]string, error) {
 
 // OrganizeDirectory organizes all files in a directory according to the configured patterns
 func (e *Engine) OrganizeDirectory(directory string) ([]types.OrganizeResult, error) {
+	return e.OrganizeDirectoryContext(context.Background(), directory)
+}
+
+// OrganizeDirectoryContext is OrganizeDirectory that stops before the next
+// file once ctx is cancelled, returning the results so far with ctx's error
+func (e *Engine) OrganizeDirectoryContext(ctx context.Context, directory string) ([]types.OrganizeResult, error) {
 	logger := log.LogWithFields(log.F("directory", directory))
 	var results []types.OrganizeResult
 
 	// Check if directory exists
 	dirInfo, err := os.Stat(directory)
 	if err != nil {
-		return nil, errors.NewFileError("failed to access directory", directory, errors.FileAccessDenied, err)
+		return nil, errors.NewOSFileError("failed to access directory", directory, errors.FileAccessDenied, err)
 	}
 
 	if !dirInfo.IsDir() {
@@ -435,57 +738,54 @@ func (e *Engine) OrganizeDirectory(directory string) ([]types.OrganizeResult, er
 	// Read directory contents
 	entries, err := os.ReadDir(directory)
 	if err != nil {
-		return nil, errors.NewFileError("failed to read directory", directory, errors.FileAccessDenied, err)
+		return nil, errors.NewOSFileError("failed to read directory", directory, errors.FileAccessDenied, err)
 	}
 
 	logger.With(log.F("file_count", len(entries))).Info("Organizing directory")
 
 	// Process each file
 	for _, entry := range entries {
-		// Skip directories
-		if entry.IsDir() {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		// Skip directories and files still being staged
+		if entry.IsDir() || atomicfile.IsTemp(entry.Name()) {
 			continue
 		}
 
 		// Get full file path
 		filePath := filepath.Join(directory, entry.Name())
 
-		// For each file, check all patterns
-		for _, pattern := range e.patterns {
-			// Check glob pattern
-			matched, err := filepath.Match(pattern.Match, entry.Name())
-			if err != nil || !matched {
-				continue
-			}
-
-			// Create destination path, handling both absolute and relative paths
-			var destPath string
-			if filepath.IsAbs(pattern.Target) {
-				// If target is absolute, use it directly
-				destPath = filepath.Join(pattern.Target, entry.Name())
-			} else {
-				// If target is relative, join with the source directory
-				destPath = filepath.Join(directory, pattern.Target, entry.Name())
-			}
+		// The first matching pattern decides where the file goes
+		pattern, found := e.MatchingPattern(filePath)
+		if !found {
+			continue
+		}
 
-			// Create result object
-			result := types.OrganizeResult{
-				SourcePath:      filePath,
-				DestinationPath: destPath,
-			}
+		// Create destination path, handling both absolute and relative paths
+		var destPath string
+		if filepath.IsAbs(pattern.Target) {
+			// If target is absolute, use it directly
+			destPath = filepath.Join(pattern.Target, entry.Name())
+		} else {
+			// If target is relative, join with the source directory
+			destPath = filepath.Join(directory, pattern.Target, entry.Name())
+		}
 
-			// Try to move the file
-			err = e.MoveFile(filePath, destPath)
-			if err != nil {
-				result.Error = err
-			} else {
-				result.Moved = !e.dryRun
-			}
+		// Create result object
+		result := types.OrganizeResult{
+			SourcePath:      filePath,
+			DestinationPath: destPath,
+		}
 
-			// Add to results and break out of the pattern loop
-			results = append(results, result)
-			break
+		// Try to move the file
+		if err := e.MoveFile(filePath, destPath); err != nil {
+			result.Error = err
+		} else {
+			result.Moved = !e.dryRun
 		}
+		results = append(results, result)
 	}
 
 	return results, nil
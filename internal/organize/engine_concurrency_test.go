@@ -279,3 +279,91 @@ func countFiles(dir string, ext string) int {
 	}
 	return count
 }
+
+// TestConcurrentActorsMoveFileOnce checks that independent engines (standing in for
+// the daemon and a manual organize run) coordinate so each file is moved exactly once
+func TestConcurrentActorsMoveFileOnce(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "downloads")
+	require.NoError(t, os.MkdirAll(srcDir, 0755))
+
+	cfg := config.New()
+	cfg.Settings.DryRun = false
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.Collision = "rename"
+
+	for i := 0; i < 20; i++ {
+		src := filepath.Join(srcDir, "report"+string(rune('a'+i))+".txt")
+		require.NoError(t, os.WriteFile(src, []byte("report"), 0644))
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for actor := 0; actor < 2; actor++ {
+			wg.Add(1)
+			go func(actor int) {
+				defer wg.Done()
+				engine := NewWithConfig(cfg)
+				errs[actor] = engine.MoveFile(src, filepath.Join(tmpDir, "docs", filepath.Base(src)))
+			}(actor)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			assert.NoError(t, err, "Losing actor should skip, not fail")
+		}
+	}
+
+	moved, err := os.ReadDir(filepath.Join(tmpDir, "docs"))
+	require.NoError(t, err)
+	assert.Len(t, moved, 20, "Each file should be moved exactly once, without renamed duplicates")
+}
+
+// TestFileKeyChangesWhenRewritten checks that a file rewritten after planning no
+// longer matches the planned version, so MoveFile leaves it alone
+func TestFileKeyChangesWhenRewritten(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "notes.txt")
+	require.NoError(t, os.WriteFile(src, []byte("v1"), 0644))
+
+	planned, err := os.Stat(src)
+	require.NoError(t, err)
+
+	// Rewrite the file so it is a different version than the one planned
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(src, later, later))
+	current, err := os.Stat(src)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, fileKeyOf(planned), fileKeyOf(current))
+}
+
+// TestMoveFileRefusesFileChangedWhileWaiting checks that a file rewritten while
+// MoveFile waited for the directory lock is reported, not silently skipped
+func TestMoveFileRefusesFileChangedWhileWaiting(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "notes.txt")
+	require.NoError(t, os.WriteFile(src, []byte("v1"), 0644))
+
+	cfg := config.New()
+	cfg.Settings.DryRun = false
+	cfg.Settings.CreateDirs = true
+	engine := NewWithConfig(cfg)
+
+	// Another process holds the source directory while MoveFile plans the move
+	unlock, err := lockDirectories(tmpDir)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.MoveFile(src, filepath.Join(tmpDir, "docs", "notes.txt"))
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(src, later, later))
+	unlock()
+
+	err = <-done
+	assert.ErrorContains(t, err, "changed while waiting")
+	assert.FileExists(t, src, "The rewritten file stays where it is")
+}
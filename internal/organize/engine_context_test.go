@@ -0,0 +1,36 @@
+package organize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizeStopsWhenCancelled(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "report.pdf")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+
+	cfg := &config.Config{}
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.Collision = "rename"
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: "Documents"}}
+	engine := NewWithConfig(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := engine.OrganizeDirectoryContext(ctx, dir)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, results)
+
+	assert.ErrorIs(t, engine.OrganizeByPatternsContext(ctx, []string{file}), context.Canceled)
+	assert.FileExists(t, file, "Nothing is moved after cancellation")
+}
@@ -0,0 +1,79 @@
+package organize
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sortd/internal/globs"
+	"sortd/pkg/types"
+)
+
+// PatternMatch is how one pattern judged a file
+type PatternMatch struct {
+	Pattern     types.Pattern
+	Matched     bool   // The pattern matches the file's name
+	Applies     bool   // The pattern is the one the file would be organized by
+	Destination string // Where the file would go if this pattern applied
+	Reason      string // Why the pattern doesn't apply; empty when it does
+}
+
+// ExplainPatterns judges a file against every pattern in order, the way
+// OrganizeByPatterns would. The first matching pattern applies, unless the
+// file is a checksum or signature following the file it belongs to.
+func (e *Engine) ExplainPatterns(path string) []PatternMatch {
+	name := filepath.Base(path)
+	applied := -1
+	companion, companionIndex, _ := e.companionPattern(path)
+	matches := make([]PatternMatch, 0, len(e.patterns))
+	for i, pattern := range e.patterns {
+		match := PatternMatch{Pattern: pattern}
+		if i == companionIndex {
+			match.Matched, match.Applies = true, true
+			if filepath.IsAbs(companion.Target) {
+				match.Destination = filepath.Join(companion.Target, name)
+			} else {
+				match.Destination = filepath.Join(filepath.Dir(path), companion.Target, name)
+			}
+			matches = append(matches, match)
+			applied = i
+			continue
+		}
+		expanded, matched, err := MatchPattern(pattern, path)
+		exclude, _ := ExcludedBy(pattern, path)
+		outside := depthReason(pattern, e.depth(path))
+		if filepath.IsAbs(expanded.Target) {
+			match.Destination = filepath.Join(expanded.Target, name)
+		} else {
+			match.Destination = filepath.Join(filepath.Dir(path), expanded.Target, name)
+		}
+
+		switch {
+		case err != nil:
+			match.Reason = err.Error()
+		case exclude != "":
+			match.Reason = fmt.Sprintf("excluded by %q", exclude)
+		case !matched && globs.IsPathPattern(pattern.Match):
+			match.Reason = fmt.Sprintf("%q doesn't match the path", pattern.Match)
+		case !matched:
+			match.Reason = fmt.Sprintf("%q doesn't match %q", pattern.Match, name)
+		case !e.profiles.Active(pattern.Profile):
+			match.Matched = true
+			match.Reason = fmt.Sprintf("profile %q isn't active", pattern.Profile)
+		case outside != "":
+			match.Matched = true
+			match.Reason = outside
+		case companionIndex >= 0:
+			match.Matched = true
+			match.Reason = fmt.Sprintf("checksum or signature follows its file to pattern %d", companionIndex+1)
+		case applied >= 0:
+			match.Matched = true
+			match.Reason = fmt.Sprintf("pattern %d matched first", applied+1)
+		default:
+			match.Matched = true
+			match.Applies = true
+			applied = i
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
@@ -0,0 +1,57 @@
+package organize
+
+import (
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainPatterns(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "Documents"},
+		{Match: "report*", Target: "/reports"},
+		{Match: "*.jpg", Target: "Images"},
+		{Match: "[", Target: "Broken"},
+	}
+	engine := NewWithConfig(cfg)
+
+	matches := engine.ExplainPatterns("/inbox/report.pdf")
+	require.Len(t, matches, 4)
+
+	assert.True(t, matches[0].Applies)
+	assert.Equal(t, "/inbox/Documents/report.pdf", matches[0].Destination)
+
+	assert.True(t, matches[1].Matched)
+	assert.False(t, matches[1].Applies)
+	assert.Equal(t, "pattern 1 matched first", matches[1].Reason)
+	assert.Equal(t, "/reports/report.pdf", matches[1].Destination)
+
+	assert.False(t, matches[2].Matched)
+	assert.Contains(t, matches[3].Reason, "invalid glob")
+}
+
+func TestExplainPatternsProfile(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Profiles = []config.Profile{{Name: "elsewhere", Hostnames: []string{"no-such-host-*"}}}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "/nas/Documents", Profile: "elsewhere"},
+		{Match: "*.pdf", Target: "Documents"},
+	}
+	engine := NewWithConfig(cfg)
+
+	matches := engine.ExplainPatterns("/inbox/report.pdf")
+	require.Len(t, matches, 2)
+	assert.True(t, matches[0].Matched)
+	assert.False(t, matches[0].Applies)
+	assert.Equal(t, `profile "elsewhere" isn't active`, matches[0].Reason)
+	assert.True(t, matches[1].Applies)
+
+	pattern, ok := engine.MatchingPattern("/inbox/report.pdf")
+	require.True(t, ok)
+	assert.Equal(t, "Documents", pattern.Target)
+}
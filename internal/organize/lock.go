@@ -0,0 +1,209 @@
+package organize
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+)
+
+// lockTimeout bounds how long a move waits for another process to release a directory
+var lockTimeout = 30 * time.Second
+
+// lockPollInterval is how often a busy directory lock is retried
+const lockPollInterval = 50 * time.Millisecond
+
+// Completed moves are remembered per source directory for a while so that a
+// second actor arriving late can tell "already moved" apart from "missing"
+const (
+	moveJournalWindow = 10 * time.Minute
+	moveJournalMax    = 256
+)
+
+// fileKey identifies a particular version of a file: the same inode with the
+// same modification time. A file that was moved away, replaced or rewritten
+// since it was planned no longer has the same key.
+type fileKey struct {
+	dev     uint64
+	ino     uint64
+	modTime int64
+}
+
+// lockDirectories takes advisory locks on the given directories so that
+// concurrent sortd processes (e.g. the watch daemon and a manual organize)
+// don't move the same file or race on the same destination name. Locks are
+// taken in a fixed order to avoid deadlocks. The returned function releases them.
+func lockDirectories(dirs ...string) (func(), error) {
+	unique := make(map[string]bool)
+	var ordered []string
+	for _, dir := range dirs {
+		clean := filepath.Clean(dir)
+		if !unique[clean] {
+			unique[clean] = true
+			ordered = append(ordered, clean)
+		}
+	}
+	sort.Strings(ordered)
+
+	var held []*os.File
+	release := func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			unlockFile(held[i])
+		}
+	}
+
+	for _, dir := range ordered {
+		f, err := lockDirectory(dir)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		held = append(held, f)
+	}
+
+	return release, nil
+}
+
+// lockDirectory acquires the lock for a single directory, waiting up to lockTimeout
+func lockDirectory(dir string) (*os.File, error) {
+	base, err := lockBase(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, busy, err := tryLockFile(base + ".lock")
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock %s: %w", dir, err)
+		}
+		if !busy {
+			return f, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to lock %s: still locked by another process after %s", dir, lockTimeout)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// lockBase returns the path, without extension, of the lock and journal files
+// for a directory, creating the directory they live in. They are kept per
+// user, in the runtime directory or else the state directory, rather than
+// next to the user's files or anywhere another user could get at them.
+func lockBase(dir string) (string, error) {
+	root := os.Getenv("XDG_RUNTIME_DIR")
+	if filepath.IsAbs(root) {
+		root = filepath.Join(root, "sortd", "locks")
+	} else {
+		state, err := config.StateDir()
+		if err != nil {
+			return "", err
+		}
+		root = filepath.Join(state, "locks")
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum([]byte(filepath.Clean(dir)))
+	return filepath.Join(root, hex.EncodeToString(sum[:])), nil
+}
+
+// journalEntry is a completed move remembered for deduplication
+type journalEntry struct {
+	at   time.Time
+	key  fileKey
+	src  string
+	dest string
+}
+
+// recordMove remembers a completed move in the source directory's journal.
+// The caller must hold the source directory's lock.
+func recordMove(key fileKey, src, dest string) error {
+	base, err := lockBase(filepath.Dir(src))
+	if err != nil {
+		return err
+	}
+	path := base + ".journal"
+
+	entries := readMoveJournal(path)
+	entries = append(entries, journalEntry{at: time.Now(), key: key, src: src, dest: dest})
+	if len(entries) > moveJournalMax {
+		entries = entries[len(entries)-moveJournalMax:]
+	}
+
+	var b strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%d\t%d\t%d\t%d\t%s\t%s\n", entry.at.UnixNano(), entry.key.dev, entry.key.ino, entry.key.modTime, entry.src, entry.dest)
+	}
+	return atomicfile.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// movedRecently reports whether src was moved away by some sortd process within
+// the journal window. The caller must hold the source directory's lock.
+func movedRecently(src string) bool {
+	base, err := lockBase(filepath.Dir(src))
+	if err != nil {
+		return false
+	}
+	for _, entry := range readMoveJournal(base + ".journal") {
+		if entry.src == src {
+			return true
+		}
+	}
+	return false
+}
+
+// readMoveJournal loads the journal entries that are still within the window
+func readMoveJournal(path string) []journalEntry {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	cutoff := time.Now().Add(-moveJournalWindow)
+	var entries []journalEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 6)
+		if len(fields) != 6 {
+			continue
+		}
+		nums := make([]int64, 4)
+		valid := true
+		for i := range nums {
+			n, err := strconv.ParseInt(fields[i], 10, 64)
+			if err != nil {
+				valid = false
+				break
+			}
+			nums[i] = n
+		}
+		if !valid {
+			continue
+		}
+
+		entry := journalEntry{
+			at:   time.Unix(0, nums[0]),
+			key:  fileKey{dev: uint64(nums[1]), ino: uint64(nums[2]), modTime: nums[3]},
+			src:  fields[4],
+			dest: fields[5],
+		}
+		if entry.at.Before(cutoff) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
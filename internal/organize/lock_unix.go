@@ -0,0 +1,41 @@
+//go:build !windows
+
+package organize
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileKeyOf returns the key of a stat result
+func fileKeyOf(info os.FileInfo) fileKey {
+	key := fileKey{modTime: info.ModTime().UnixNano()}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		key.dev = uint64(st.Dev)
+		key.ino = uint64(st.Ino)
+	}
+	return key
+}
+
+// tryLockFile opens the lock file at path and takes an exclusive flock on it
+// without waiting. busy reports that another process holds it.
+func tryLockFile(path string) (f *os.File, busy bool, err error) {
+	f, err = os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, true, nil
+		}
+		return nil, false, err
+	}
+	return f, false, nil
+}
+
+// unlockFile releases a lock taken by tryLockFile
+func unlockFile(f *os.File) {
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	f.Close()
+}
@@ -0,0 +1,39 @@
+package organize
+
+import (
+	"os"
+	"syscall"
+)
+
+// errorSharingViolation is ERROR_SHARING_VIOLATION: another process has the
+// file open without sharing it
+const errorSharingViolation = syscall.Errno(32)
+
+// fileKeyOf returns the key of a stat result. Windows stat results carry no
+// file ID, so a version is told apart by its modification time alone.
+func fileKeyOf(info os.FileInfo) fileKey {
+	return fileKey{modTime: info.ModTime().UnixNano()}
+}
+
+// tryLockFile opens the lock file at path without sharing it, which Windows
+// refuses while another process has it open. busy reports that one does.
+func tryLockFile(path string) (f *os.File, busy bool, err error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, false, err
+	}
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil,
+		syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err == errorSharingViolation {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return os.NewFile(uintptr(handle), path), false, nil
+}
+
+// unlockFile releases a lock taken by tryLockFile
+func unlockFile(f *os.File) {
+	f.Close()
+}
@@ -0,0 +1,141 @@
+package organize
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sortd/internal/globs"
+	"sortd/internal/units"
+	"sortd/pkg/types"
+)
+
+// OrderPatterns returns patterns in the order they are tried: by priority,
+// highest first, and in config order among equal priorities
+func OrderPatterns(patterns []types.Pattern) []types.Pattern {
+	ordered := append([]types.Pattern(nil), patterns...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority > ordered[j].Priority
+	})
+	return ordered
+}
+
+// MatchPattern reports whether the file at path matches pattern and isn't
+// excluded by it. For regex patterns the returned copy has the {name}
+// references in its target filled in from the named capture groups.
+func MatchPattern(pattern types.Pattern, path string) (types.Pattern, bool, error) {
+	exclude, err := ExcludedBy(pattern, path)
+	if err != nil || exclude != "" {
+		return pattern, false, err
+	}
+
+	candidates := []string{path}
+	if pattern.ExtensionAliases {
+		candidates = append(candidates, globs.AliasPaths(path)...)
+	}
+
+	for _, candidate := range candidates {
+		expanded, matched, err := matchPatternOnce(pattern, candidate)
+		if err != nil {
+			return expanded, false, err
+		}
+		if !matched {
+			continue
+		}
+
+		// Only files the pattern matches are looked at for their size
+		if len(pattern.Tiers) > 0 {
+			target, err := TierTarget(pattern, path)
+			if err != nil {
+				return pattern, false, err
+			}
+			if target != pattern.Target {
+				pattern.Target = target
+				return matchPatternOnce(pattern, candidate)
+			}
+		}
+		return expanded, true, nil
+	}
+	return pattern, false, nil
+}
+
+// PatternTargets returns every target a pattern can send files to: its own
+// and its size tiers'
+func PatternTargets(pattern types.Pattern) []string {
+	targets := []string{pattern.Target}
+	for _, tier := range pattern.Tiers {
+		targets = append(targets, tier.Target)
+	}
+	return targets
+}
+
+// TierTarget returns the target for the file at path among the pattern's own
+// and its size tiers' targets. Files that can't be read, or that aren't there
+// yet, go to the pattern's own target. A tier with a min_size that isn't a
+// size is an error; the config refuses to load one.
+func TierTarget(pattern types.Pattern, path string) (string, error) {
+	minSizes := make([]int64, len(pattern.Tiers))
+	for i, tier := range pattern.Tiers {
+		minSize, err := units.ParseSize(tier.MinSize)
+		if err != nil {
+			return "", fmt.Errorf("tier %d: min_size: %w", i, err)
+		}
+		minSizes[i] = minSize
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return pattern.Target, nil
+	}
+
+	target, reached := pattern.Target, int64(-1)
+	for i, tier := range pattern.Tiers {
+		if info.Size() >= minSizes[i] && minSizes[i] > reached {
+			target, reached = tier.Target, minSizes[i]
+		}
+	}
+	return target, nil
+}
+
+// ExcludedBy returns the first of the pattern's exclude globs that matches the
+// file, or "" if none does. Excludes honor the pattern's ignore_case.
+func ExcludedBy(pattern types.Pattern, path string) (string, error) {
+	for _, exclude := range pattern.Exclude {
+		expr, subject := exclude, path
+		if pattern.IgnoreCase {
+			expr, subject = strings.ToLower(expr), strings.ToLower(path)
+		}
+		matched, err := globs.Match(expr, subject)
+		if err != nil {
+			return "", err
+		}
+		if matched {
+			return exclude, nil
+		}
+	}
+	return "", nil
+}
+
+// matchPatternOnce matches a single spelling of the path
+func matchPatternOnce(pattern types.Pattern, path string) (types.Pattern, bool, error) {
+	if pattern.Type != types.RegexPattern {
+		expr := pattern.Match
+		if pattern.IgnoreCase {
+			expr, path = strings.ToLower(expr), strings.ToLower(path)
+		}
+		matched, err := globs.Match(expr, path)
+		return pattern, matched, err
+	}
+
+	expr := pattern.Match
+	if pattern.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	groups, matched, err := globs.MatchRegex(expr, path)
+	if err != nil || !matched {
+		return pattern, false, err
+	}
+	pattern.Target = globs.ExpandGroups(pattern.Target, groups)
+	return pattern, true, nil
+}
@@ -0,0 +1,214 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPattern(t *testing.T) {
+	episode := types.Pattern{
+		Match:  `(?P<show>.+)\.S(?P<season>\d+)E\d+.*`,
+		Target: "TV/{show}/Season {season}",
+		Type:   types.RegexPattern,
+	}
+
+	expanded, matched, err := MatchPattern(episode, "/downloads/Severance.S02E03.1080p.mkv")
+	require.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, "TV/Severance/Season 02", expanded.Target)
+
+	_, matched, err = MatchPattern(episode, "/downloads/holiday.jpg")
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	// A regex has to match the whole name, not just part of it
+	_, matched, err = MatchPattern(types.Pattern{Match: `S\d+E\d+`, Type: types.RegexPattern}, "/tv/Show.S01E01.mkv")
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	glob, matched, err := MatchPattern(types.Pattern{Match: "*.mkv", Target: "Videos/{show}"}, "/tv/Show.S01E01.mkv")
+	require.NoError(t, err)
+	assert.True(t, matched)
+	assert.Equal(t, "Videos/{show}", glob.Target, "Glob targets are left alone")
+}
+
+func TestOrganizeDirectoryRegexTarget(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Severance.S02E03.mkv"), nil, 0644))
+
+	cfg := &config.Config{}
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.Collision = "rename"
+	cfg.Organize.Patterns = []types.Pattern{{
+		Match:  `(?P<show>.+)\.S(?P<season>\d+)E\d+.*`,
+		Target: "TV/{show}/Season {season}",
+		Type:   types.RegexPattern,
+	}}
+
+	results, err := NewWithConfig(cfg).OrganizeDirectory(dir)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.NoError(t, results[0].Error)
+	assert.FileExists(t, filepath.Join(dir, "TV", "Severance", "Season 02", "Severance.S02E03.mkv"))
+}
+
+func TestMatchPatternCaseAndAliases(t *testing.T) {
+	tests := []struct {
+		pattern types.Pattern
+		path    string
+		want    bool
+	}{
+		{types.Pattern{Match: "*.jpg"}, "/in/a.JPG", false},
+		{types.Pattern{Match: "*.jpg", IgnoreCase: true}, "/in/a.JPG", true},
+		{types.Pattern{Match: "*.jpg"}, "/in/a.jpeg", false},
+		{types.Pattern{Match: "*.jpg", ExtensionAliases: true}, "/in/a.jpeg", true},
+		{types.Pattern{Match: "*.jpg", ExtensionAliases: true}, "/in/a.JPEG", false},
+		{types.Pattern{Match: "*.jpg", ExtensionAliases: true, IgnoreCase: true}, "/in/a.JPEG", true},
+		{types.Pattern{Match: "*.tiff", ExtensionAliases: true}, "/in/scan.tif", true},
+		{types.Pattern{Match: "*.html", ExtensionAliases: true}, "/in/page.htm", true},
+		{types.Pattern{Match: "*.jpg", ExtensionAliases: true}, "/in/a.png", false},
+		{types.Pattern{Match: `IMG_\d+\.jpg`, Type: types.RegexPattern, IgnoreCase: true}, "/in/img_0042.JPG", true},
+	}
+	for _, tt := range tests {
+		_, matched, err := MatchPattern(tt.pattern, tt.path)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, matched, "%+v vs %s", tt.pattern, tt.path)
+	}
+}
+
+func TestMatchPatternExclude(t *testing.T) {
+	documents := types.Pattern{Match: "*.pdf", Target: "Documents", Exclude: []string{"*invoice*"}, IgnoreCase: true}
+
+	_, matched, err := MatchPattern(documents, "/in/manual.pdf")
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	_, matched, err = MatchPattern(documents, "/in/ACME-Invoice-42.PDF")
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	// The file falls through to the pattern meant for it, whatever the order
+	cfg := &config.Config{}
+	cfg.Organize.Patterns = []types.Pattern{documents, {Match: "*invoice*.pdf", Target: "Invoices"}}
+	engine := NewWithConfig(cfg)
+	pattern, found := engine.MatchingPattern("/in/invoice-42.pdf")
+	require.True(t, found)
+	assert.Equal(t, "Invoices", pattern.Target)
+
+	explained := engine.ExplainPatterns("/in/invoice-42.pdf")
+	assert.Equal(t, `excluded by "*invoice*"`, explained[0].Reason)
+	assert.True(t, explained[1].Applies)
+}
+
+func TestOrderPatterns(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "Documents"},
+		{Match: "*invoice*", Target: "Invoices", Priority: 10},
+		{Match: "*.txt", Target: "Notes"},
+		{Match: "*.tmp", Target: "Trash", Priority: -1},
+		{Match: "*receipt*", Target: "Receipts", Priority: 10},
+	}
+
+	var order []string
+	for _, p := range OrderPatterns(cfg.Organize.Patterns) {
+		order = append(order, p.Target)
+	}
+	assert.Equal(t, []string{"Invoices", "Receipts", "Documents", "Notes", "Trash"}, order)
+	assert.Equal(t, "Documents", cfg.Organize.Patterns[0].Target, "The config keeps its order")
+
+	pattern, found := NewWithConfig(cfg).MatchingPattern("/in/invoice-42.pdf")
+	require.True(t, found)
+	assert.Equal(t, "Invoices", pattern.Target)
+}
+
+func TestMatchPatternTiers(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "clip.mp4")
+	large := filepath.Join(dir, "film.mp4")
+	huge := filepath.Join(dir, "archive.mp4")
+	require.NoError(t, os.WriteFile(small, make([]byte, 10), 0644))
+	require.NoError(t, os.WriteFile(large, make([]byte, 2048), 0644))
+	require.NoError(t, os.WriteFile(huge, make([]byte, 4096), 0644))
+
+	videos := types.Pattern{
+		Match:  "*.mp4",
+		Target: "/ssd/Videos",
+		Tiers: []types.SizeTier{
+			{MinSize: "4KiB", Target: "/nas/Archive"},
+			{MinSize: "2KiB", Target: "/hdd/Videos"},
+		},
+	}
+	for path, want := range map[string]string{
+		small:                         "/ssd/Videos",
+		large:                         "/hdd/Videos",
+		huge:                          "/nas/Archive",
+		filepath.Join(dir, "new.mp4"): "/ssd/Videos", // Not there yet
+	} {
+		expanded, matched, err := MatchPattern(videos, path)
+		require.NoError(t, err)
+		assert.True(t, matched)
+		assert.Equal(t, want, expanded.Target, filepath.Base(path))
+	}
+
+	episode := types.Pattern{
+		Match:  `(?P<show>.+)\.mkv`,
+		Target: "/ssd/{show}",
+		Type:   types.RegexPattern,
+		Tiers:  []types.SizeTier{{MinSize: "1KB", Target: "/nas/{show}"}},
+	}
+	path := filepath.Join(dir, "Severance.mkv")
+	require.NoError(t, os.WriteFile(path, make([]byte, 2000), 0644))
+	expanded, _, err := MatchPattern(episode, path)
+	require.NoError(t, err)
+	assert.Equal(t, "/nas/Severance", expanded.Target, "Tier targets take capture groups too")
+
+	broken := types.Pattern{Match: "*.mp4", Target: "/ssd", Tiers: []types.SizeTier{{MinSize: "huge", Target: "/nas"}}}
+	_, matched, err := MatchPattern(broken, small)
+	assert.Error(t, err, "A min_size that isn't a size is an error, not a skipped tier")
+	assert.False(t, matched)
+	_, matched, err = MatchPattern(broken, path)
+	assert.NoError(t, err, "Files the pattern doesn't match never get to the tiers")
+	assert.False(t, matched)
+}
+
+func TestDepth(t *testing.T) {
+	roots := []string{"/home/me/Downloads", "/home/me/Downloads/Sorted"}
+	assert.Equal(t, 1, Depth(roots, "/home/me/Downloads/a.pdf"))
+	assert.Equal(t, 3, Depth(roots, "/home/me/Downloads/Taxes/2023/a.pdf"))
+	assert.Equal(t, 2, Depth(roots, "/home/me/Downloads/Sorted/pdf/a.pdf"), "The deepest root counts")
+	assert.Equal(t, 1, Depth(roots, "/home/me/Downloadsx/a/b.pdf"), "Outside every root is top-level")
+	assert.Equal(t, 1, Depth(nil, "/a/b/c.pdf"))
+
+	topLevel := types.Pattern{MaxDepth: 1}
+	nested := types.Pattern{MinDepth: 2}
+	assert.True(t, InDepth(topLevel, 1))
+	assert.False(t, InDepth(topLevel, 2))
+	assert.False(t, InDepth(nested, 1))
+	assert.True(t, InDepth(nested, 5))
+	assert.True(t, InDepth(types.Pattern{}, 9))
+}
+
+func TestMatchingPatternDepth(t *testing.T) {
+	engine := New()
+	engine.AddPattern(types.Pattern{Match: "*.pdf", Target: "/docs", MaxDepth: 1})
+	engine.SetRoots([]string{"/in"})
+
+	_, found := engine.MatchingPattern("/in/a.pdf")
+	assert.True(t, found)
+	_, found = engine.MatchingPattern("/in/sub/a.pdf")
+	assert.False(t, found, "Subfolders are below max_depth")
+
+	explained := engine.ExplainPatterns("/in/sub/a.pdf")
+	require.Len(t, explained, 1)
+	assert.True(t, explained[0].Matched)
+	assert.False(t, explained[0].Applies)
+	assert.Equal(t, "at depth 2, deeper than max_depth 1", explained[0].Reason)
+}
@@ -0,0 +1,128 @@
+package organize
+
+import (
+	"sort"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+)
+
+// Preset is a built-in set of patterns for a common kind of download folder
+type Preset struct {
+	Name        string
+	Description string
+	Patterns    []types.Pattern
+}
+
+// buildOutputs are the folders build tools write into. Preset patterns never
+// touch what's inside them, so organizing a directory with projects in it
+// leaves their builds alone.
+var buildOutputs = []string{
+	"**/node_modules/**", "**/target/**", "**/build/**", "**/dist/**", "**/out/**",
+	"**/bin/**", "**/obj/**", "**/_build/**", "**/__pycache__/**", "**/.venv/**",
+	"**/venv/**", "**/vendor/**", "**/.gradle/**", "**/.next/**",
+	"**/cmake-build-*/**", "**/zig-out/**", "**/.build/**",
+}
+
+// Pieces of the developer preset's regexes. A tool's name is words joined by
+// - or _ (ripgrep, docker-compose), its version starts with a digit and has a
+// dot (1.22.0, v20.11.0), and a platform is an OS or architecture token.
+const (
+	toolName    = `(?P<tool>[A-Za-z][A-Za-z0-9+]*?(?:[-_][A-Za-z][A-Za-z0-9+]*?)*?)`
+	toolVersion = `[-_.]?v?\d+(?:\.\d+)+`
+	platform    = `(?:linux|linux64|darwin|macos|osx|mac|windows|win|win32|win64|freebsd|openbsd|amd64|arm64|x86_64|x86-64|x64|aarch64|i386|i686|386|armv6|armv7|armhf|universal)`
+	archive     = `\.(?:tar\.(?:gz|xz|bz2|zst)|tgz|txz|tbz2|zip|7z)`
+	tarball     = `\.(?:tar\.(?:gz|xz|bz2|zst)|tgz|txz|tbz2)`
+)
+
+// developerPatterns sends installers, disk images, tool downloads and source
+// tarballs to folders under Dev, with their checksums and signatures
+func developerPatterns() []types.Pattern {
+	patterns := []types.Pattern{
+		// Release archives built for a platform, e.g. go1.22.0.linux-amd64.tar.gz
+		{
+			Match:    toolName + toolVersion + `[-_.](?:.*[-_.])?` + platform + `(?:[-_.].*)?` + archive,
+			Target:   "Dev/Tools/{tool}",
+			Type:     types.RegexPattern,
+			Priority: 30,
+		},
+		// Bare binaries named for their platform, e.g. kubectl-linux-amd64 or
+		// yq_windows_amd64.exe
+		{
+			Match:    toolName + `(?:` + toolVersion + `)?[-_.](?:.*[-_.])?` + platform + `(?:\.exe)?`,
+			Target:   "Dev/Tools/{tool}",
+			Type:     types.RegexPattern,
+			Priority: 25,
+		},
+		// Source tarballs, e.g. Python-3.12.1.tgz or foo-1.2-src.zip
+		{
+			Match:    toolName + toolVersion + `(?:(?:[-_.](?:src|source|orig))?` + tarball + `|[-_.](?:src|source)\.zip)`,
+			Target:   "Dev/Source/{tool}",
+			Type:     types.RegexPattern,
+			Priority: 20,
+		},
+		// AppImages, e.g. nvim.appimage or Obsidian-1.5.3.AppImage
+		{
+			Match:    toolName + `(?:[-_.](?:v?\d|` + platform + `).*)?\.appimage`,
+			Target:   "Dev/Tools/{tool}",
+			Type:     types.RegexPattern,
+			Priority: 20,
+		},
+		{Match: "*.{iso,img}", Target: "Dev/Disk Images", Priority: 10},
+		{Match: "*.{dmg,pkg}", Target: "Dev/Installers/macOS", Priority: 10},
+		{Match: "*.{deb,rpm,snap,flatpakref,appimage}", Target: "Dev/Installers/Linux", Priority: 10},
+		{Match: "*.{exe,msi,msix}", Target: "Dev/Installers/Windows", Priority: 10},
+	}
+	for i := range patterns {
+		patterns[i].IgnoreCase = true
+		patterns[i].Companions = true
+		patterns[i].Exclude = append([]string(nil), buildOutputs...)
+	}
+	return patterns
+}
+
+// presets are the built-in presets by name
+var presets = map[string]Preset{
+	"developer": {
+		Name:        "developer",
+		Description: "Installers, disk images, tool downloads and source tarballs, with their checksums, into Dev; build outputs are left alone",
+		Patterns:    developerPatterns(),
+	},
+}
+
+// Presets returns the built-in presets, sorted by name
+func Presets() []Preset {
+	list := make([]Preset, 0, len(presets))
+	for _, preset := range presets {
+		list = append(list, preset)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// PresetNamed returns the built-in preset with the given name
+func PresetNamed(name string) (Preset, bool) {
+	preset, ok := presets[name]
+	return preset, ok
+}
+
+// AddPreset adds the preset's patterns to the config, skipping those it
+// already has with the same match and target. It returns how many were added.
+func AddPreset(cfg *config.Config, preset Preset) int {
+	added := 0
+	for _, pattern := range preset.Patterns {
+		found := false
+		for _, existing := range cfg.Organize.Patterns {
+			if existing.Match == pattern.Match && existing.Target == pattern.Target {
+				found = true
+				break
+			}
+		}
+		if !found {
+			pattern.Exclude = append([]string(nil), pattern.Exclude...)
+			cfg.Organize.Patterns = append(cfg.Organize.Patterns, pattern)
+			added++
+		}
+	}
+	return added
+}
@@ -0,0 +1,68 @@
+package organize
+
+import (
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeveloperPreset(t *testing.T) {
+	preset, ok := PresetNamed("developer")
+	require.True(t, ok)
+
+	cfg := config.New()
+	cfg.Organize.Patterns = nil
+	assert.Equal(t, len(preset.Patterns), AddPreset(cfg, preset))
+	assert.Zero(t, AddPreset(cfg, preset), "Adding the preset again adds nothing")
+	require.NoError(t, cfg.Validate())
+
+	engine := NewWithConfig(cfg)
+	for name, want := range map[string]string{
+		"go1.22.0.linux-amd64.tar.gz":                     "Dev/Tools/go",
+		"node-v20.11.0-linux-x64.tar.xz":                  "Dev/Tools/node",
+		"ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz": "Dev/Tools/ripgrep",
+		"k9s_0.32.4_Linux_amd64.tar.gz":                   "Dev/Tools/k9s",
+		"kubectl-linux-amd64":                             "Dev/Tools/kubectl",
+		"yq_windows_amd64.exe":                            "Dev/Tools/yq",
+		"Obsidian-1.5.3.AppImage":                         "Dev/Tools/Obsidian",
+		"nvim.appimage":                                   "Dev/Tools/nvim",
+		"Python-3.12.1.tgz":                               "Dev/Source/Python",
+		"foo-1.2.tar.gz":                                  "Dev/Source/foo",
+		"ubuntu-24.04-desktop-amd64.iso":                  "Dev/Disk Images",
+		"Firefox 124.0.dmg":                               "Dev/Installers/macOS",
+		"code_1.87.0-1709078641_amd64.deb":                "Dev/Installers/Linux",
+		"Setup.EXE":                                       "Dev/Installers/Windows",
+	} {
+		pattern, found := engine.MatchingPattern(filepath.Join("/home/me/Downloads", name))
+		if assert.True(t, found, name) {
+			assert.Equal(t, want, pattern.Target, name)
+		}
+	}
+
+	for _, path := range []string{
+		"/home/me/Downloads/report.pdf",
+		"/home/me/Downloads/project/node_modules/esbuild/bin/esbuild-linux-x64",
+		"/home/me/Downloads/project/target/release/tool.exe",
+		"/home/me/Downloads/project/build/app.img",
+	} {
+		_, found := engine.MatchingPattern(path)
+		assert.False(t, found, path)
+	}
+}
+
+func TestPresets(t *testing.T) {
+	presets := Presets()
+	require.NotEmpty(t, presets)
+	for _, preset := range presets {
+		assert.NotEmpty(t, preset.Description, preset.Name)
+		named, ok := PresetNamed(preset.Name)
+		assert.True(t, ok)
+		assert.Equal(t, preset.Name, named.Name)
+	}
+	_, ok := PresetNamed("nope")
+	assert.False(t, ok)
+}
@@ -0,0 +1,145 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+	"sortd/pkg/types"
+)
+
+// QuickSetupLimit is how many extensions quick setup offers to map
+const QuickSetupLimit = 10
+
+// ExtensionCount is how many files in a directory share an extension, with
+// the destination quick setup suggests for them
+type ExtensionCount struct {
+	Extension   string   // Lower case, without the dot
+	Variants    []string // Spellings seen in file names, e.g. "jpg" and "JPG"
+	Count       int
+	Destination string
+}
+
+// defaultDestinations maps common extensions to the folder they usually belong in
+var defaultDestinations = map[string]string{
+	"jpg": "Images", "jpeg": "Images", "png": "Images", "gif": "Images", "webp": "Images",
+	"heic": "Images", "svg": "Images", "bmp": "Images", "tiff": "Images", "raw": "Images",
+	"pdf": "Documents", "doc": "Documents", "docx": "Documents", "odt": "Documents",
+	"txt": "Documents", "md": "Documents", "rtf": "Documents", "epub": "Documents",
+	"xls": "Spreadsheets", "xlsx": "Spreadsheets", "ods": "Spreadsheets", "csv": "Spreadsheets",
+	"ppt": "Presentations", "pptx": "Presentations", "odp": "Presentations", "key": "Presentations",
+	"mp3": "Music", "flac": "Music", "wav": "Music", "aac": "Music", "ogg": "Music", "m4a": "Music",
+	"mp4": "Videos", "mkv": "Videos", "mov": "Videos", "avi": "Videos", "webm": "Videos",
+	"zip": "Archives", "rar": "Archives", "7z": "Archives", "tar": "Archives", "gz": "Archives",
+	"dmg": "Installers", "iso": "Installers", "deb": "Installers", "rpm": "Installers",
+	"exe": "Installers", "msi": "Installers", "pkg": "Installers", "appimage": "Installers",
+	"go": "Code", "py": "Code", "js": "Code", "ts": "Code", "json": "Code", "yaml": "Code",
+	"ics": "Calendar", "vcf": "Contacts", "ttf": "Fonts", "otf": "Fonts",
+}
+
+// SuggestDestination returns the folder quick setup proposes for an
+// extension, or "" when it has no opinion
+func SuggestDestination(ext string) string {
+	return defaultDestinations[strings.ToLower(strings.TrimPrefix(ext, "."))]
+}
+
+// TopExtensions counts the extensions of the files directly in dir and returns
+// the most common, up to limit (all of them if limit <= 0). Hidden files,
+// files without an extension and files still being written are ignored.
+func TopExtensions(dir string, limit int) ([]ExtensionCount, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]*ExtensionCount)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || atomicfile.IsTemp(name) {
+			continue
+		}
+		variant := strings.TrimPrefix(filepath.Ext(name), ".")
+		if variant == "" {
+			continue
+		}
+
+		ext := strings.ToLower(variant)
+		count, ok := counts[ext]
+		if !ok {
+			count = &ExtensionCount{Extension: ext, Destination: SuggestDestination(ext)}
+			counts[ext] = count
+		}
+		count.Count++
+		if !containsString(count.Variants, variant) {
+			count.Variants = append(count.Variants, variant)
+		}
+	}
+
+	result := make([]ExtensionCount, 0, len(counts))
+	for _, count := range counts {
+		sort.Strings(count.Variants)
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Extension < result[j].Extension
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// AddExtensionPatterns adds a pattern sending each extension to its
+// destination, one per spelling seen. Extensions without a destination are
+// skipped, and an existing pattern for the same match is retargeted rather
+// than duplicated. It returns how many patterns were added or changed.
+func AddExtensionPatterns(cfg *config.Config, mapping []ExtensionCount) int {
+	changed := 0
+	for _, ext := range mapping {
+		dest := strings.TrimSpace(ext.Destination)
+		if dest == "" {
+			continue
+		}
+
+		variants := ext.Variants
+		if len(variants) == 0 {
+			variants = []string{ext.Extension}
+		}
+		for _, variant := range variants {
+			match := "*." + variant
+			found := false
+			for i := range cfg.Organize.Patterns {
+				if cfg.Organize.Patterns[i].Match != match {
+					continue
+				}
+				found = true
+				if cfg.Organize.Patterns[i].Target != dest {
+					cfg.Organize.Patterns[i].Target = dest
+					changed++
+				}
+				break
+			}
+			if !found {
+				cfg.Organize.Patterns = append(cfg.Organize.Patterns, types.Pattern{Match: match, Target: dest})
+				changed++
+			}
+		}
+	}
+	return changed
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
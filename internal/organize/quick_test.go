@@ -0,0 +1,57 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopExtensions(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"a.pdf", "b.pdf", "C.PDF", "d.jpg", "e.jpg", "f.xyz", "README", ".hidden.pdf",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "folder.pdf"), 0755))
+
+	exts, err := TopExtensions(dir, 2)
+	require.NoError(t, err)
+	require.Len(t, exts, 2)
+
+	assert.Equal(t, ExtensionCount{Extension: "pdf", Variants: []string{"PDF", "pdf"}, Count: 3, Destination: "Documents"}, exts[0])
+	assert.Equal(t, "jpg", exts[1].Extension)
+	assert.Equal(t, "Images", exts[1].Destination)
+
+	all, err := TopExtensions(dir, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, "", all[2].Destination, "Unknown extensions get no suggestion")
+}
+
+func TestAddExtensionPatterns(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.jpg", Target: "Pictures"}}
+
+	changed := AddExtensionPatterns(cfg, []ExtensionCount{
+		{Extension: "pdf", Variants: []string{"PDF", "pdf"}, Destination: "Documents"},
+		{Extension: "jpg", Variants: []string{"jpg"}, Destination: "Images"},
+		{Extension: "xyz", Variants: []string{"xyz"}},
+	})
+
+	assert.Equal(t, 3, changed)
+	assert.Equal(t, []types.Pattern{
+		{Match: "*.jpg", Target: "Images"},
+		{Match: "*.PDF", Target: "Documents"},
+		{Match: "*.pdf", Target: "Documents"},
+	}, cfg.Organize.Patterns)
+
+	assert.Zero(t, AddExtensionPatterns(cfg, []ExtensionCount{{Extension: "pdf", Destination: "Documents"}}),
+		"Applying the same mapping again changes nothing")
+}
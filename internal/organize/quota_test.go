@@ -0,0 +1,61 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/internal/errors"
+	"sortd/internal/quota"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveFile_Quotas(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "archive")
+	notified := filepath.Join(dir, "notified")
+	require.NoError(t, os.MkdirAll(archive, 0755))
+	require.NoError(t, os.MkdirAll(notified, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(archive, "old.bin"), make([]byte, 600), 0644))
+
+	cfg := &config.Config{}
+	cfg.Settings.Collision = CollisionRename
+	cfg.Settings.Quotas = []config.Quota{
+		{Directory: archive, MaxSize: "1KB"},
+		{Directory: notified, MaxFiles: 1, Action: quota.ActionNotify},
+	}
+	engine := NewWithConfig(cfg)
+
+	var violations []quota.Violation
+	engine.Quotas().SetHandler(func(v quota.Violation) { violations = append(violations, v) })
+
+	small := filepath.Join(dir, "small.bin")
+	require.NoError(t, os.WriteFile(small, make([]byte, 100), 0644))
+	require.NoError(t, engine.MoveFile(small, filepath.Join(archive, "small.bin")))
+
+	big := filepath.Join(dir, "big.bin")
+	require.NoError(t, os.WriteFile(big, make([]byte, 500), 0644))
+	err := engine.MoveFile(big, filepath.Join(archive, "big.bin"))
+	require.Error(t, err, "Counted moves take the archive over 1KB")
+	assert.True(t, errors.IsQuotaExceeded(err))
+	assert.FileExists(t, big, "Refused files stay where they are")
+
+	engine.SetDryRun(true)
+	assert.True(t, errors.IsQuotaExceeded(engine.MoveFile(big, filepath.Join(archive, "big.bin"))),
+		"Dry runs show the refusal too")
+	engine.SetDryRun(false)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		src := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(src, []byte(name), 0644))
+		require.NoError(t, engine.MoveFile(src, filepath.Join(notified, name)), "Notify quotas let files through")
+	}
+	assert.FileExists(t, filepath.Join(notified, "b.txt"))
+
+	require.Len(t, violations, 2, "Each directory is reported once while it stays over")
+	assert.Equal(t, archive, violations[0].Limit.Path)
+	assert.Equal(t, notified, violations[1].Limit.Path)
+}
@@ -0,0 +1,56 @@
+package organize
+
+import (
+	"path/filepath"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/log"
+)
+
+// CleanStaging removes temp files orphaned by a crash from each directory and
+// from the destinations the engine's patterns route its files to. It returns
+// how many it removed. Dry runs and audit mode leave them alone.
+func (e *Engine) CleanStaging(dirs ...string) int {
+	if e.dryRun || e.audit {
+		return 0
+	}
+
+	seen := make(map[string]bool)
+	var targets []string
+	add := func(dir string) {
+		dir = filepath.Clean(dir)
+		if !seen[dir] {
+			seen[dir] = true
+			targets = append(targets, dir)
+		}
+	}
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		add(dir)
+		for _, pattern := range e.patterns {
+			for _, target := range PatternTargets(pattern) {
+				if filepath.IsAbs(target) {
+					add(target)
+				} else {
+					add(filepath.Join(dir, target))
+				}
+			}
+		}
+	}
+
+	removed := 0
+	for _, dir := range targets {
+		n, err := atomicfile.CleanOrphans(dir, atomicfile.OrphanAge)
+		if err != nil {
+			log.LogWithFields(log.F("dir", dir)).Warnf("Failed to clean up staged files: %v", err)
+		}
+		removed += n
+	}
+	if removed > 0 {
+		log.LogWithFields(log.F("count", removed)).Info("Removed temp files left behind by an interrupted write")
+	}
+	return removed
+}
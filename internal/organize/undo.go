@@ -0,0 +1,63 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+
+	"sortd/internal/errors"
+	"sortd/internal/log"
+	"sortd/pkg/types"
+)
+
+// Undo reverses a move reported in result: the file goes back where it came
+// from, or, when it was copied, the copy is removed. Nothing is overwritten;
+// undoing fails when something else now sits at the original path, or the
+// file is no longer where the move left it. Results that moved nothing are
+// left alone.
+func (e *Engine) Undo(result types.OrganizeResult) error {
+	if !result.Moved || result.Error != nil {
+		return nil
+	}
+	src := filepath.Clean(result.SourcePath)
+	dest := filepath.Clean(result.DestinationPath)
+
+	if e.IsReadOnly() && !e.dryRun {
+		return errors.NewFileError("read-only mode is on, not undoing", dest, errors.ReadOnlyMode, nil)
+	}
+
+	unlock, err := lockDirectories(filepath.Dir(src), filepath.Dir(dest))
+	if err != nil {
+		return errors.NewOSFileError("failed to lock directory", filepath.Dir(dest), errors.FileOperationFailed, err)
+	}
+	defer unlock()
+
+	if _, err := os.Stat(dest); err != nil {
+		return errors.NewOSFileError("the organized file is no longer there", dest, errors.FileNotFound, err)
+	}
+
+	logger := log.LogWithFields(log.F("source", src), log.F("destination", dest), log.F("dry_run", e.dryRun))
+
+	if result.Copied {
+		// The original stayed put; only remove the copy while it's still there
+		if _, err := os.Stat(src); err != nil {
+			return errors.NewOSFileError("the original of the copy is gone, keeping the copy", src, errors.FileNotFound, err)
+		}
+		if err := e.fsys().Remove(dest); err != nil {
+			return errors.NewOSFileError("failed to remove copy", dest, errors.FileOperationFailed, err)
+		}
+		logger.Info("Undid copy")
+		return nil
+	}
+
+	if _, err := os.Lstat(src); err == nil {
+		return errors.NewFileError("another file now has the original name", src, errors.InvalidOperation, nil)
+	}
+	if err := e.fsys().MkdirAll(filepath.Dir(src), 0755); err != nil {
+		return errors.NewOSFileError("failed to recreate original directory", filepath.Dir(src), errors.FileCreateFailed, err)
+	}
+	if err := e.fsys().Rename(dest, src); err != nil {
+		return errors.NewOSFileError("failed to move file back", dest, errors.FileOperationFailed, err)
+	}
+	logger.Info("Undid move")
+	return nil
+}
@@ -0,0 +1,62 @@
+package organize
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sortd/internal/config"
+)
+
+// Policies for files no rule matches, as set in settings.unmatched.policy
+const (
+	UnmatchedLeave  = "leave"  // Leave them where they are (the default)
+	UnmatchedMove   = "move"   // Move them to a folder per day under the unsorted folder
+	UnmatchedReview = "review" // Queue a move to the unsorted folder in the pending queue
+)
+
+// DefaultUnsortedFolder is where unmatched files go when settings.unmatched.folder is empty
+const DefaultUnsortedFolder = "Unsorted"
+
+// UnmatchedPolicy returns cfg's policy for unmatched files
+func UnmatchedPolicy(cfg *config.Config) string {
+	if cfg == nil || cfg.Settings.Unmatched.Policy == "" {
+		return UnmatchedLeave
+	}
+	return cfg.Settings.Unmatched.Policy
+}
+
+// UnsortedFolder returns the absolute folder unmatched files are collected
+// in. A relative folder is taken from the default directory.
+func UnsortedFolder(cfg *config.Config) string {
+	folder, base := DefaultUnsortedFolder, ""
+	if cfg != nil {
+		if cfg.Settings.Unmatched.Folder != "" {
+			folder = cfg.Settings.Unmatched.Folder
+		}
+		base = cfg.Directories.Default
+	}
+	if !filepath.IsAbs(folder) {
+		folder = filepath.Join(base, folder)
+	}
+	if abs, err := filepath.Abs(folder); err == nil {
+		return abs
+	}
+	return filepath.Clean(folder)
+}
+
+// UnsortedDestination returns where the move policy puts file: the unsorted
+// folder's subfolder for the day, e.g. Unsorted/2024-05-01/file
+func UnsortedDestination(cfg *config.Config, file string, now time.Time) string {
+	return filepath.Join(UnsortedFolder(cfg), now.Format("2006-01-02"), filepath.Base(file))
+}
+
+// InUnsorted reports whether path is inside the unsorted folder, where
+// unmatched files already are and must not be moved again
+func InUnsorted(cfg *config.Config, path string) bool {
+	folder := UnsortedFolder(cfg)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path == folder || strings.HasPrefix(path, folder+string(filepath.Separator))
+}
@@ -0,0 +1,49 @@
+package organize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsortedDestination(t *testing.T) {
+	home := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Directories.Default = home
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+
+	assert.Equal(t, UnmatchedLeave, UnmatchedPolicy(cfg))
+	assert.Equal(t, filepath.Join(home, "Unsorted", "2024-05-01", "a.bin"),
+		UnsortedDestination(cfg, "/dl/a.bin", now))
+	assert.True(t, InUnsorted(cfg, filepath.Join(home, "Unsorted", "2024-05-01", "a.bin")))
+	assert.False(t, InUnsorted(cfg, filepath.Join(home, "Unsorted-old", "a.bin")))
+
+	cfg.Settings.Unmatched.Folder = "/inbox/leftovers"
+	assert.Equal(t, filepath.Join("/inbox/leftovers", "2024-05-01", "a.bin"),
+		UnsortedDestination(cfg, "/dl/a.bin", now), "Absolute folders are used as they are")
+}
+
+func TestMoveFile_UnsortedFolderIsCreated(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.bin")
+	require.NoError(t, os.WriteFile(src, []byte("a"), 0644))
+
+	cfg := &config.Config{}
+	cfg.Directories.Default = dir
+	cfg.Settings.Collision = CollisionRename
+	engine := NewWithConfig(cfg)
+
+	dest := UnsortedDestination(cfg, src, time.Now())
+	require.NoError(t, engine.MoveFile(src, dest), "The unsorted folder doesn't need create_dirs")
+	assert.FileExists(t, dest)
+
+	other := filepath.Join(dir, "b.bin")
+	require.NoError(t, os.WriteFile(other, []byte("b"), 0644))
+	assert.Error(t, engine.MoveFile(other, filepath.Join(dir, "Elsewhere", "b.bin")))
+}
@@ -0,0 +1,113 @@
+package organize
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+)
+
+const (
+	DefaultMaxDepth = 10    // Levels a recursive run descends when settings.max_depth is 0
+	DefaultMaxFiles = 10000 // Files a run takes in when settings.max_files is 0
+)
+
+// Limits bound how much of a directory tree a scan or organize run takes in,
+// so pointing sortd at / by mistake doesn't walk the whole filesystem
+type Limits struct {
+	MaxDepth int // Directory levels to search, the root being the first; negative means no limit
+	MaxFiles int // Files to collect before stopping; negative means no limit
+}
+
+// LimitsFromConfig reads the limits from settings.max_depth and
+// settings.max_files, using the defaults for zero values
+func LimitsFromConfig(cfg *config.Config) Limits {
+	limits := Limits{MaxDepth: DefaultMaxDepth, MaxFiles: DefaultMaxFiles}
+	if cfg == nil {
+		return limits
+	}
+	if cfg.Settings.MaxDepth != 0 {
+		limits.MaxDepth = cfg.Settings.MaxDepth
+	}
+	if cfg.Settings.MaxFiles != 0 {
+		limits.MaxFiles = cfg.Settings.MaxFiles
+	}
+	return limits
+}
+
+// Listing is the files a walk found and whether a limit cut it short
+type Listing struct {
+	Files       []string
+	HitMaxFiles bool // Stopped after Limits.MaxFiles files
+	HitMaxDepth bool // Skipped directories deeper than Limits.MaxDepth
+}
+
+// Truncated reports whether a limit left files out
+func (l Listing) Truncated() bool {
+	return l.HitMaxFiles || l.HitMaxDepth
+}
+
+// errFileLimit stops the walk once enough files have been collected
+var errFileLimit = errors.New("file limit reached")
+
+// ListFiles returns the regular files in root, and in its subdirectories when
+// recursive is set, up to the limits. Files still being staged by sortd are
+// left out, as are subdirectories that can't be read.
+func ListFiles(ctx context.Context, root string, recursive bool, limits Limits) (Listing, error) {
+	var listing Listing
+	root = filepath.Clean(root)
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // Skip what can't be read
+		}
+
+		if entry.IsDir() {
+			if path == root {
+				return nil
+			}
+			if !recursive {
+				return filepath.SkipDir
+			}
+			if limits.MaxDepth >= 0 && depthBelow(root, path) >= limits.MaxDepth {
+				listing.HitMaxDepth = true
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !entry.Type().IsRegular() || atomicfile.IsTemp(path) {
+			return nil
+		}
+		if limits.MaxFiles >= 0 && len(listing.Files) >= limits.MaxFiles {
+			listing.HitMaxFiles = true
+			return errFileLimit
+		}
+		listing.Files = append(listing.Files, path)
+		return nil
+	})
+	if errors.Is(err, errFileLimit) {
+		err = nil
+	}
+	return listing, err
+}
+
+// depthBelow counts the directory levels from root down to path; a direct
+// child of root is at depth 1
+func depthBelow(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
@@ -0,0 +1,52 @@
+package organize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "one/c.txt", "one/two/d.txt", "one/two/three/e.txt"} {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+	ctx := context.Background()
+	unlimited := Limits{MaxDepth: -1, MaxFiles: -1}
+
+	listing, err := ListFiles(ctx, root, false, unlimited)
+	require.NoError(t, err)
+	assert.Len(t, listing.Files, 2, "Without recursion only the root's files are listed")
+	assert.False(t, listing.Truncated())
+
+	listing, err = ListFiles(ctx, root, true, unlimited)
+	require.NoError(t, err)
+	assert.Len(t, listing.Files, 5)
+
+	listing, err = ListFiles(ctx, root, true, Limits{MaxDepth: 2, MaxFiles: -1})
+	require.NoError(t, err)
+	assert.Len(t, listing.Files, 3, "Two levels: the root and one/")
+	assert.True(t, listing.HitMaxDepth)
+
+	listing, err = ListFiles(ctx, root, true, Limits{MaxDepth: -1, MaxFiles: 4})
+	require.NoError(t, err)
+	assert.Len(t, listing.Files, 4)
+	assert.True(t, listing.HitMaxFiles)
+}
+
+func TestLimitsFromConfig(t *testing.T) {
+	assert.Equal(t, Limits{MaxDepth: DefaultMaxDepth, MaxFiles: DefaultMaxFiles}, LimitsFromConfig(&config.Config{}))
+
+	cfg := &config.Config{}
+	cfg.Settings.MaxDepth = 3
+	cfg.Settings.MaxFiles = -1
+	assert.Equal(t, Limits{MaxDepth: 3, MaxFiles: -1}, LimitsFromConfig(cfg))
+}
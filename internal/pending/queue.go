@@ -0,0 +1,258 @@
+// Package pending holds moves that sortd has decided on but not yet carried
+// out, waiting for the user to approve or reject them.
+package pending
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+	"sortd/internal/learning"
+	"sortd/pkg/types"
+)
+
+// queueFile is the file, in the state directory, the queue is kept in
+const queueFile = "pending.json"
+
+// Reasons a move is staged rather than executed
+const (
+	ReasonTraining      = "training"       // The training period is running
+	ReasonLowConfidence = "low_confidence" // The rule's confidence is below the auto-apply threshold
+	ReasonCollision     = "collision"      // The destination is taken and settings.collision is "ask"
+	ReasonUnmatched     = "unmatched"      // No rule matches and settings.unmatched.policy is "review"
+)
+
+// Item is a staged move
+type Item struct {
+	ID          string    `json:"id"`
+	Operation   string    `json:"operation,omitempty"` // Stable ID of the proposed move, see types.OperationID
+	Path        string    `json:"path"`
+	Destination string    `json:"destination"` // Full destination path of the file
+	Rule        string    `json:"rule"`        // Pattern that proposed the move
+	Reason      string    `json:"reason"`
+	Created     time.Time `json:"created"`
+}
+
+// queueData is the on-disk form of the queue
+type queueData struct {
+	TrainingStarted time.Time `json:"training_started,omitempty"`
+	Items           []Item    `json:"items"`
+}
+
+// Queue is a persistent list of staged moves. The file is re-read before and
+// rewritten after every change, so the daemon, the GUI and the CLI can each hold
+// their own Queue for the same file.
+type Queue struct {
+	path string
+	mu   sync.Mutex
+	data queueData
+}
+
+// DefaultPath returns the path of the pending queue
+func DefaultPath(cfg *config.Config) string {
+	return cfg.StateFile(queueFile)
+}
+
+// Open loads the queue at path. A missing file yields an empty queue.
+func Open(path string) (*Queue, error) {
+	q := &Queue{path: path}
+	if err := q.loadLocked(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// TrainingStarted returns when the training period began, recording now as the
+// start if it has not begun yet
+func (q *Queue) TrainingStarted(now time.Time) (time.Time, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return now, err
+	}
+
+	if q.data.TrainingStarted.IsZero() {
+		q.data.TrainingStarted = now
+		if err := q.saveLocked(); err != nil {
+			return now, err
+		}
+	}
+	return q.data.TrainingStarted, nil
+}
+
+// TrainingStart returns when the training period began, or the zero time if
+// nothing has been staged yet
+func (q *Queue) TrainingStart() time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return time.Time{}
+	}
+	return q.data.TrainingStarted
+}
+
+// Add stages a move. A file that is already staged keeps a single entry,
+// updated to the latest proposal.
+func (q *Queue) Add(item Item) (Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return item, err
+	}
+
+	if item.Created.IsZero() {
+		item.Created = time.Now()
+	}
+	item.ID = itemID(item.Path)
+	item.Operation = types.OperationID(item.Path, item.Destination, item.Rule)
+
+	for i, existing := range q.data.Items {
+		if existing.ID == item.ID {
+			q.data.Items[i] = item
+			return item, q.saveLocked()
+		}
+	}
+	q.data.Items = append(q.data.Items, item)
+	return item, q.saveLocked()
+}
+
+// List returns the staged moves, oldest first
+func (q *Queue) List() ([]Item, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return nil, err
+	}
+	return append([]Item(nil), q.data.Items...), nil
+}
+
+// Get returns the staged move with the given ID or operation ID
+func (q *Queue) Get(id string) (Item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return Item{}, false
+	}
+	for _, item := range q.data.Items {
+		if item.matches(id) {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+// Remove drops a staged move without acting on it
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.loadLocked(); err != nil {
+		return err
+	}
+
+	for i, item := range q.data.Items {
+		if item.matches(id) {
+			q.data.Items = append(q.data.Items[:i], q.data.Items[i+1:]...)
+			return q.saveLocked()
+		}
+	}
+	return fmt.Errorf("no pending item with ID %s", id)
+}
+
+// Approve carries out a staged move with move, records the approval against the
+// proposing rule and removes the item. The item stays queued if the move fails.
+func (q *Queue) Approve(id string, move func(src, dest string) error, store *learning.Store) error {
+	item, ok := q.Get(id)
+	if !ok {
+		return fmt.Errorf("no pending item with ID %s", id)
+	}
+
+	info, statErr := os.Stat(item.Path)
+	if err := move(item.Path, item.Destination); err != nil {
+		return fmt.Errorf("failed to move %s: %w", item.Path, err)
+	}
+	if store != nil && item.Rule != "" {
+		if err := store.RecordApproval(item.Rule); err != nil {
+			return err
+		}
+		// Remember the move so that undoing it later counts as a correction
+		if statErr == nil {
+			if err := store.RecordPlacement(item.Rule, item.Destination, info); err != nil {
+				return err
+			}
+		}
+	}
+	return q.Remove(id)
+}
+
+// Reject leaves the file where it is, records the rejection against the
+// proposing rule and removes the item
+func (q *Queue) Reject(id string, store *learning.Store) error {
+	item, ok := q.Get(id)
+	if !ok {
+		return fmt.Errorf("no pending item with ID %s", id)
+	}
+
+	if store != nil && item.Rule != "" {
+		if err := store.RecordRejection(item.Rule); err != nil {
+			return err
+		}
+	}
+	return q.Remove(id)
+}
+
+// matches reports whether id names the item, by its ID or its operation ID
+func (i Item) matches(id string) bool {
+	return id != "" && (i.ID == id || i.Operation == id)
+}
+
+// itemID derives a short stable ID from the file path, so that re-staging the
+// same file replaces its entry
+func itemID(path string) string {
+	sum := sha1.Sum([]byte(filepath.Clean(path)))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+// loadLocked re-reads the queue from disk. The caller must hold q.mu.
+func (q *Queue) loadLocked() error {
+	data, err := os.ReadFile(q.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			q.data = queueData{}
+			return nil
+		}
+		return fmt.Errorf("failed to read pending queue: %w", err)
+	}
+
+	var loaded queueData
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed to parse pending queue: %w", err)
+	}
+	q.data = loaded
+	return nil
+}
+
+// saveLocked writes the queue to disk. The caller must hold q.mu.
+func (q *Queue) saveLocked() error {
+	data, err := json.MarshalIndent(q.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode pending queue: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(q.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save pending queue: %w", err)
+	}
+	return nil
+}
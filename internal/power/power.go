@@ -0,0 +1,31 @@
+// Package power tells whether the machine is running on battery, saving
+// power or on a metered network, so the watch daemon can hold back work that
+// drains the battery or the data plan until it's back on AC or an unmetered
+// connection.
+package power
+
+// State is what the machine is running on
+type State struct {
+	OnBattery bool // Discharging with no AC adapter online
+	Saver     bool // A battery saver or low-power profile is on
+	Metered   bool // The network connection is metered
+}
+
+// Current returns the machine's state now. Whatever can't be told on this
+// system reads as false: on AC, no saver, unmetered.
+func Current() State {
+	return current()
+}
+
+// IsRemote reports whether path lies on a network file system, such as NFS,
+// SMB or sshfs, whose writes go over the network
+func IsRemote(path string) bool {
+	return isRemote(path)
+}
+
+// remoteTypes are the file system types whose data lives across the network
+var remoteTypes = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb3": true, "smbfs": true,
+	"9p": true, "afs": true, "ceph": true, "glusterfs": true, "davfs": true,
+	"fuse.sshfs": true, "fuse.rclone": true, "fuse.s3fs": true, "fuse.gcsfuse": true,
+}
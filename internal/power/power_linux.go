@@ -0,0 +1,127 @@
+package power
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Where the kernel reports power supplies and the platform power profile;
+// variables so tests can point them at a fake tree
+var (
+	supplyDir   = "/sys/class/power_supply"
+	profilePath = "/sys/firmware/acpi/platform_profile"
+	mountsPath  = "/proc/self/mounts"
+)
+
+// meteredTimeout bounds the query to NetworkManager
+const meteredTimeout = 2 * time.Second
+
+func current() State {
+	return State{
+		OnBattery: onBattery(supplyDir),
+		Saver:     saverOn(profilePath),
+		Metered:   metered(),
+	}
+}
+
+// onBattery reports whether a battery is discharging and no AC adapter is
+// online. Machines without a battery are always on AC.
+func onBattery(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	discharging := false
+	for _, entry := range entries {
+		supply := filepath.Join(dir, entry.Name())
+		switch readValue(filepath.Join(supply, "type")) {
+		case "Mains", "USB":
+			if readValue(filepath.Join(supply, "online")) == "1" {
+				return false
+			}
+		case "Battery":
+			if readValue(filepath.Join(supply, "status")) == "Discharging" {
+				discharging = true
+			}
+		}
+	}
+	return discharging
+}
+
+// saverOn reports whether the platform runs the low-power profile that
+// battery saver modes switch to
+func saverOn(path string) bool {
+	return readValue(path) == "low-power"
+}
+
+// metered asks NetworkManager whether the primary connection is metered
+func metered() bool {
+	busctl, err := exec.LookPath("busctl")
+	if err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), meteredTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, busctl, "--system", "get-property",
+		"org.freedesktop.NetworkManager", "/org/freedesktop/NetworkManager",
+		"org.freedesktop.NetworkManager", "Metered")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return parseMetered(string(out))
+}
+
+// parseMetered reads busctl's "u <n>" answer. NetworkManager reports 1 for
+// metered and 3 for guessed metered, e.g. a phone hotspot.
+func parseMetered(out string) bool {
+	fields := strings.Fields(out)
+	if len(fields) != 2 || fields[0] != "u" {
+		return false
+	}
+	return fields[1] == "1" || fields[1] == "3"
+}
+
+// isRemote looks up the file system type of the deepest mount point holding
+// path in the mount table
+func isRemote(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	file, err := os.Open(mountsPath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	best, fsType := "", ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		point := fields[1]
+		if (abs == point || strings.HasPrefix(abs, strings.TrimSuffix(point, "/")+"/")) && len(point) >= len(best) {
+			best, fsType = point, fields[2]
+		}
+	}
+	return remoteTypes[fsType]
+}
+
+// readValue returns the trimmed content of a sysfs attribute, or ""
+func readValue(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,69 @@
+package power
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSupply adds a power supply with the given sysfs attributes to dir
+func writeSupply(t *testing.T, dir, name string, attrs map[string]string) {
+	t.Helper()
+	supply := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(supply, 0755))
+	for attr, value := range attrs {
+		require.NoError(t, os.WriteFile(filepath.Join(supply, attr), []byte(value+"\n"), 0644))
+	}
+}
+
+func TestOnBattery(t *testing.T) {
+	dir := t.TempDir()
+	assert.False(t, onBattery(dir), "No battery means AC")
+
+	writeSupply(t, dir, "BAT0", map[string]string{"type": "Battery", "status": "Discharging"})
+	writeSupply(t, dir, "AC", map[string]string{"type": "Mains", "online": "0"})
+	assert.True(t, onBattery(dir))
+
+	writeSupply(t, dir, "AC", map[string]string{"type": "Mains", "online": "1"})
+	assert.False(t, onBattery(dir), "Plugged in")
+
+	assert.False(t, onBattery(filepath.Join(dir, "missing")))
+}
+
+func TestSaverOn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "platform_profile")
+	assert.False(t, saverOn(path))
+	require.NoError(t, os.WriteFile(path, []byte("low-power\n"), 0644))
+	assert.True(t, saverOn(path))
+	require.NoError(t, os.WriteFile(path, []byte("balanced\n"), 0644))
+	assert.False(t, saverOn(path))
+}
+
+func TestParseMetered(t *testing.T) {
+	assert.True(t, parseMetered("u 1\n"))
+	assert.True(t, parseMetered("u 3\n"), "Guessed metered, e.g. a hotspot")
+	assert.False(t, parseMetered("u 2\n"))
+	assert.False(t, parseMetered("u 4\n"))
+	assert.False(t, parseMetered(""))
+}
+
+func TestIsRemote(t *testing.T) {
+	mounts := filepath.Join(t.TempDir(), "mounts")
+	require.NoError(t, os.WriteFile(mounts, []byte(
+		"/dev/sda1 / ext4 rw 0 0\n"+
+			"nas:/export /mnt/nas nfs4 rw 0 0\n"+
+			"/dev/sdb1 /mnt/nas/local ext4 rw 0 0\n"+
+			"me@host:/ /home/me/remote fuse.sshfs rw 0 0\n"), 0644))
+	old := mountsPath
+	mountsPath = mounts
+	t.Cleanup(func() { mountsPath = old })
+
+	assert.False(t, IsRemote("/home/me/Documents"))
+	assert.True(t, IsRemote("/mnt/nas/photos"))
+	assert.False(t, IsRemote("/mnt/nas/local/photos"), "The deepest mount point wins")
+	assert.True(t, IsRemote("/home/me/remote/backup"))
+	assert.False(t, IsRemote("/mnt/nasty"))
+}
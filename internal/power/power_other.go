@@ -0,0 +1,8 @@
+//go:build !linux
+
+package power
+
+// Battery, power profile and metered state aren't read on this system yet
+func current() State { return State{} }
+
+func isRemote(string) bool { return false }
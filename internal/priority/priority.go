@@ -0,0 +1,27 @@
+// Package priority lowers the CPU and disk priority of the threads doing
+// background organization, so large moves don't make the desktop stutter
+package priority
+
+import (
+	"errors"
+
+	"sortd/internal/config"
+)
+
+// ErrUnsupported is returned where thread priorities can't be lowered
+var ErrUnsupported = errors.New("lowering CPU and disk priority is only supported on Linux")
+
+// Lowers reports whether settings ask for anything below normal priority
+func Lowers(settings config.PrioritySettings) bool {
+	return settings.Nice > 0 || settings.IOClass != "" || settings.IOLevel > 0
+}
+
+// LowerThread lowers the priority of the calling OS thread. Callers lock
+// their goroutine to the thread with runtime.LockOSThread first, and never
+// unlock it, so no other goroutine inherits the lower priority.
+func LowerThread(settings config.PrioritySettings) error {
+	if !Lowers(settings) {
+		return nil
+	}
+	return lowerThread(settings)
+}
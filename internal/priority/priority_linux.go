@@ -0,0 +1,40 @@
+package priority
+
+import (
+	"fmt"
+	"syscall"
+
+	"sortd/internal/config"
+)
+
+// ioprio_set arguments, from linux/ioprio.h
+const (
+	ioprioWhoProcess = 1 // A thread ID
+	ioprioClassShift = 13
+	ioprioClassBE    = 2
+	ioprioClassIdle  = 3
+)
+
+// lowerThread sets the thread's niceness and IO priority. Linux keeps both
+// per thread, so the rest of the process runs as before.
+func lowerThread(settings config.PrioritySettings) error {
+	tid := syscall.Gettid()
+	if settings.Nice > 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, settings.Nice); err != nil {
+			return fmt.Errorf("failed to set nice level %d: %w", settings.Nice, err)
+		}
+	}
+
+	if settings.IOClass == "" && settings.IOLevel == 0 {
+		return nil
+	}
+	class, level := ioprioClassBE, settings.IOLevel
+	if settings.IOClass == config.IOClassIdle {
+		class, level = ioprioClassIdle, 0
+	}
+	prio := class<<ioprioClassShift | level
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(prio)); errno != 0 {
+		return fmt.Errorf("failed to set IO priority: %w", errno)
+	}
+	return nil
+}
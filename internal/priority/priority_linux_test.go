@@ -0,0 +1,38 @@
+package priority
+
+import (
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sortd/internal/config"
+)
+
+func TestLowerThread(t *testing.T) {
+	require.NoError(t, LowerThread(config.PrioritySettings{}), "Nothing to lower")
+
+	done := make(chan struct{})
+	var nice int
+	var err error
+	go func() {
+		defer close(done)
+		runtime.LockOSThread()
+		if err = LowerThread(config.PrioritySettings{Nice: 5, IOClass: config.IOClassIdle}); err == nil {
+			// getpriority(2) returns 20 - nice
+			var prio int
+			prio, err = syscall.Getpriority(syscall.PRIO_PROCESS, syscall.Gettid())
+			nice = 20 - prio
+		}
+	}()
+	<-done
+	require.NoError(t, err)
+	assert.Equal(t, 5, nice)
+
+	// Other threads keep their priority
+	prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, 15, prio)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package priority
+
+import "sortd/internal/config"
+
+func lowerThread(config.PrioritySettings) error {
+	return ErrUnsupported
+}
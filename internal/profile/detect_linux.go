@@ -0,0 +1,71 @@
+package profile
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Where the kernel lists display connectors and mounts; variables so tests
+// can point them at a fake tree
+var (
+	drmDir     = "/sys/class/drm"
+	mountsPath = "/proc/self/mounts"
+)
+
+// displays counts the connectors with a display plugged in. A machine
+// without graphics has none.
+func displays() int {
+	connectors, _ := filepath.Glob(filepath.Join(drmDir, "card*-*", "status"))
+	count := 0
+	for _, status := range connectors {
+		data, err := os.ReadFile(status)
+		if err == nil && strings.TrimSpace(string(data)) == "connected" {
+			count++
+		}
+	}
+	return count
+}
+
+// mounts lists the mount points in the mount table. Automount placeholders
+// are left out: the share behind one isn't necessarily there.
+func mounts() []string {
+	file, err := os.Open(mountsPath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	points := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[2] == "autofs" {
+			continue
+		}
+		points = append(points, unescapeMount(fields[1]))
+	}
+	return points
+}
+
+// unescapeMount decodes the octal escapes the mount table uses for spaces,
+// tabs and backslashes in paths, e.g. "\040"
+func unescapeMount(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+4 <= len(field) {
+			if n, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
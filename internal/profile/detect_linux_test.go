@@ -0,0 +1,40 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisplays(t *testing.T) {
+	dir := t.TempDir()
+	defer func(old string) { drmDir = old }(drmDir)
+	drmDir = dir
+	assert.Equal(t, 0, displays())
+
+	for connector, status := range map[string]string{
+		"card0-eDP-1":  "connected",
+		"card0-HDMI-1": "disconnected",
+		"card1-DP-2":   "connected",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, connector), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, connector, "status"), []byte(status+"\n"), 0644))
+	}
+	assert.Equal(t, 2, displays())
+}
+
+func TestMounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mounts")
+	defer func(old string) { mountsPath = old }(mountsPath)
+	mountsPath = path
+	assert.Nil(t, mounts(), "No mount table")
+
+	table := "/dev/sda1 / ext4 rw 0 0\n" +
+		"//nas/share /mnt/my\\040nas cifs rw 0 0\n" +
+		"systemd-1 /mnt/auto autofs rw 0 0\n"
+	require.NoError(t, os.WriteFile(path, []byte(table), 0644))
+	assert.Equal(t, []string{"/", "/mnt/my nas"}, mounts())
+}
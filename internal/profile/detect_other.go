@@ -0,0 +1,9 @@
+//go:build !linux
+
+package profile
+
+// Connected displays and the mount table aren't read on this system yet;
+// mounted conditions check that the directory exists instead
+func displays() int { return -1 }
+
+func mounts() []string { return nil }
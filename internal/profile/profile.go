@@ -0,0 +1,228 @@
+// Package profile tells which config profiles are active: it detects the
+// machine's context (hostname, connected displays, mounted drives) and checks
+// it against each profile's conditions, so patterns, watch directories and
+// workflows belonging to a profile only apply in the right place.
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sortd/internal/config"
+)
+
+// Context is what the machine looks like right now
+type Context struct {
+	Hostname string
+	Displays int      // Connected displays, or -1 when that can't be told on this system
+	Mounts   []string // Mount points, or nil when the mount table can't be read
+}
+
+// Detect returns the machine's context now
+func Detect() Context {
+	hostname, _ := os.Hostname()
+	return Context{Hostname: hostname, Displays: displays(), Mounts: mounts()}
+}
+
+// IsMounted reports whether path is one of the context's mount points. Without
+// a mount table, a directory that exists counts as mounted.
+func (c Context) IsMounted(path string) bool {
+	path = filepath.Clean(config.ExpandHome(path))
+	if c.Mounts == nil {
+		info, err := os.Stat(path)
+		return err == nil && info.IsDir()
+	}
+	for _, point := range c.Mounts {
+		if filepath.Clean(point) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Check is one condition of a profile and whether it holds
+type Check struct {
+	Met    bool   `json:"met"`
+	Reason string `json:"reason"`
+}
+
+// Status says whether a profile is active and why
+type Status struct {
+	Name    string  `json:"name"`
+	Active  bool    `json:"active"`
+	Defined bool    `json:"defined"` // Listed under profiles; profiles only named by a pattern or directory are always active
+	Checks  []Check `json:"checks,omitempty"`
+}
+
+// Evaluate checks a profile's conditions against the context. The profile is
+// active when every one of them holds.
+func Evaluate(profile config.Profile, ctx Context) Status {
+	status := Status{Name: profile.Name, Defined: true}
+
+	if len(profile.Hostnames) > 0 {
+		status.Checks = append(status.Checks, checkHostname(profile.Hostnames, ctx.Hostname))
+	}
+	if profile.MinDisplays > 0 || profile.MaxDisplays > 0 {
+		status.Checks = append(status.Checks, checkDisplays(profile.MinDisplays, profile.MaxDisplays, ctx.Displays))
+	}
+	for _, path := range profile.Mounted {
+		if ctx.IsMounted(path) {
+			status.Checks = append(status.Checks, Check{Met: true, Reason: path + " is mounted"})
+		} else {
+			status.Checks = append(status.Checks, Check{Reason: path + " isn't mounted"})
+		}
+	}
+	for _, path := range profile.NotMounted {
+		if ctx.IsMounted(path) {
+			status.Checks = append(status.Checks, Check{Reason: path + " is mounted"})
+		} else {
+			status.Checks = append(status.Checks, Check{Met: true, Reason: path + " isn't mounted"})
+		}
+	}
+
+	status.Active = true
+	for _, check := range status.Checks {
+		status.Active = status.Active && check.Met
+	}
+	return status
+}
+
+// checkHostname matches the hostname, and its first label, against the globs
+func checkHostname(globs []string, hostname string) Check {
+	short, _, _ := strings.Cut(hostname, ".")
+	for _, glob := range globs {
+		glob = strings.ToLower(glob)
+		for _, name := range []string{strings.ToLower(hostname), strings.ToLower(short)} {
+			if ok, _ := filepath.Match(glob, name); ok {
+				return Check{Met: true, Reason: fmt.Sprintf("hostname %q matches %q", hostname, glob)}
+			}
+		}
+	}
+	return Check{Reason: fmt.Sprintf("hostname %q doesn't match %s", hostname, strings.Join(globs, ", "))}
+}
+
+// checkDisplays compares the connected displays with the bounds; 0 leaves
+// either end open
+func checkDisplays(min, max, connected int) Check {
+	if connected < 0 {
+		return Check{Reason: "connected displays can't be told on this system"}
+	}
+	want := fmt.Sprintf("at least %d", min)
+	switch {
+	case min > 0 && max > 0:
+		want = fmt.Sprintf("%d to %d", min, max)
+	case max > 0:
+		want = fmt.Sprintf("at most %d", max)
+	}
+	met := connected >= min && (max == 0 || connected <= max)
+	return Check{Met: met, Reason: fmt.Sprintf("%d %s connected, needs %s", connected, plural(connected, "display"), want)}
+}
+
+// Statuses evaluates every profile the config defines or names, sorted by
+// name. Names used by patterns, watch directories or workflows without a
+// definition under profiles are always active.
+func Statuses(cfg *config.Config, workflows []string, ctx Context) []Status {
+	statuses := make([]Status, 0, len(cfg.Profiles))
+	seen := make(map[string]bool)
+	for _, profile := range cfg.Profiles {
+		statuses = append(statuses, Evaluate(profile, ctx))
+		seen[profile.Name] = true
+	}
+	for _, name := range append(Names(cfg), workflows...) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			statuses = append(statuses, Status{Name: name, Active: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Names returns the profiles the config's patterns and watch directories belong to
+func Names(cfg *config.Config) []string {
+	var names []string
+	for _, pattern := range cfg.Organize.Patterns {
+		names = append(names, pattern.Profile)
+	}
+	for _, dir := range cfg.WatchDirs() {
+		names = append(names, dir.Profile)
+	}
+	return names
+}
+
+// checkInterval is how long a Checker trusts what it last detected
+var checkInterval = 30 * time.Second
+
+// detect reads the machine's context; a variable so tests can fake it
+var detect = Detect
+
+// Checker answers whether profiles are active, detecting the context at most
+// once per check interval. A nil Checker treats every profile as active.
+type Checker struct {
+	profiles []config.Profile
+
+	mu      sync.Mutex
+	checked time.Time
+	active  map[string]bool
+}
+
+// NewChecker returns a Checker for the given profile definitions
+func NewChecker(profiles []config.Profile) *Checker {
+	return &Checker{profiles: profiles}
+}
+
+// Active reports whether the named profile is active. Everything that belongs
+// to no profile ("") is, and so is a profile without a definition.
+func (c *Checker) Active(name string) bool {
+	if c == nil || name == "" {
+		return true
+	}
+	active, defined := c.current()[name]
+	return active || !defined
+}
+
+// ActiveNames returns the defined profiles that are active, sorted
+func (c *Checker) ActiveNames() []string {
+	if c == nil {
+		return nil
+	}
+	var names []string
+	for name, active := range c.current() {
+		if active {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// current returns whether each defined profile is active, detecting the
+// context again once the last check is older than the interval
+func (c *Checker) current() map[string]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active != nil && time.Since(c.checked) < checkInterval {
+		return c.active
+	}
+
+	ctx := detect()
+	c.active = make(map[string]bool, len(c.profiles))
+	for _, profile := range c.profiles {
+		c.active[profile.Name] = Evaluate(profile, ctx).Active
+	}
+	c.checked = time.Now()
+	return c.active
+}
+
+// plural adds an s to word unless n is 1
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,89 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	docked := config.Profile{Name: "docked", MinDisplays: 2, Mounted: []string{"/mnt/nas"}}
+	laptop := config.Profile{Name: "laptop", Hostnames: []string{"ThinkPad*"}, MaxDisplays: 1, NotMounted: []string{"/mnt/nas"}}
+
+	atDesk := Context{Hostname: "thinkpad-x1.lan", Displays: 3, Mounts: []string{"/", "/mnt/nas"}}
+	status := Evaluate(docked, atDesk)
+	assert.True(t, status.Active)
+	require.Len(t, status.Checks, 2)
+	assert.Equal(t, "3 displays connected, needs at least 2", status.Checks[0].Reason)
+	assert.Equal(t, "/mnt/nas is mounted", status.Checks[1].Reason)
+	assert.False(t, Evaluate(laptop, atDesk).Active)
+
+	onTheGo := Context{Hostname: "thinkpad-x1.lan", Displays: 1, Mounts: []string{"/"}}
+	status = Evaluate(docked, onTheGo)
+	assert.False(t, status.Active)
+	assert.False(t, status.Checks[1].Met)
+	status = Evaluate(laptop, onTheGo)
+	assert.True(t, status.Active, "%+v", status.Checks)
+	assert.Equal(t, `hostname "thinkpad-x1.lan" matches "thinkpad*"`, status.Checks[0].Reason)
+
+	// Displays that can't be counted never satisfy a display condition
+	status = Evaluate(docked, Context{Displays: -1, Mounts: []string{"/mnt/nas"}})
+	assert.False(t, status.Active)
+	assert.Equal(t, "connected displays can't be told on this system", status.Checks[0].Reason)
+
+	assert.True(t, Evaluate(config.Profile{Name: "always"}, onTheGo).Active)
+}
+
+func TestChecker(t *testing.T) {
+	ctx := Context{Displays: 1, Mounts: []string{"/"}}
+	calls := 0
+	detect = func() Context {
+		calls++
+		return ctx
+	}
+	defer func() { detect = Detect }()
+
+	checker := NewChecker([]config.Profile{{Name: "docked", MinDisplays: 2}})
+	assert.False(t, checker.Active("docked"))
+	assert.True(t, checker.Active(""), "Belonging to no profile always applies")
+	assert.True(t, checker.Active("undefined"), "Profiles without a definition always apply")
+	assert.Empty(t, checker.ActiveNames())
+	assert.Equal(t, 1, calls, "The context is detected once per interval")
+
+	ctx.Displays = 2
+	checker.checked = time.Now().Add(-checkInterval)
+	assert.True(t, checker.Active("docked"))
+	assert.Equal(t, []string{"docked"}, checker.ActiveNames())
+
+	var none *Checker
+	assert.True(t, none.Active("docked"))
+}
+
+func TestStatuses(t *testing.T) {
+	cfg := &config.Config{Profiles: []config.Profile{{Name: "docked", MinDisplays: 2}}}
+	cfg.Directories.Watch = []config.WatchDir{{Path: "/mnt/nas/inbox", Profile: "work"}}
+
+	statuses := Statuses(cfg, []string{"home", "work"}, Context{Displays: 0})
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "docked", statuses[0].Name)
+	assert.False(t, statuses[0].Active)
+	assert.Equal(t, Status{Name: "home", Active: true}, statuses[1])
+	assert.Equal(t, Status{Name: "work", Active: true}, statuses[2])
+}
+
+func TestIsMountedWithoutMountTable(t *testing.T) {
+	dir := t.TempDir()
+	ctx := Context{}
+	assert.True(t, ctx.IsMounted(dir))
+	assert.False(t, ctx.IsMounted(filepath.Join(dir, "missing")))
+
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	assert.False(t, ctx.IsMounted(file))
+}
@@ -0,0 +1,296 @@
+// Package quota keeps destination directories within the limits set in
+// settings.quotas, e.g. an archive on a drive of limited size.
+package quota
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/errors"
+	"sortd/internal/units"
+)
+
+// Responses to a directory going over its quota, as set in a quota's action
+const (
+	ActionStop     = "stop"     // Refuse further moves and copies into it (the default)
+	ActionNotify   = "notify"   // Let them through and notify
+	ActionWorkflow = "workflow" // Let them through and run a workflow on the oldest files
+)
+
+// usageTTL is how long a measured usage is trusted before the directory is
+// walked again. Moves recorded with Added count towards it in the meantime.
+var usageTTL = time.Minute
+
+// Usage is what a directory holds, its subdirectories included
+type Usage struct {
+	Size  int64
+	Files int
+}
+
+// Measure walks dir and adds up its files. A missing directory holds nothing.
+func Measure(dir string) (Usage, error) {
+	var usage Usage
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		usage.Size += info.Size()
+		usage.Files++
+		return nil
+	})
+	return usage, err
+}
+
+// Limit is a configured quota, resolved for checking
+type Limit struct {
+	config.Quota
+	Path    string // The absolute directory
+	MaxSize int64  // 0 for no size limit
+}
+
+// Action returns the response to the directory going over its quota
+func (l Limit) Action() string {
+	if l.Quota.Action == "" {
+		return ActionStop
+	}
+	return l.Quota.Action
+}
+
+// Exceeded reports whether usage is over the limit
+func (l Limit) Exceeded(usage Usage) bool {
+	return (l.MaxSize > 0 && usage.Size > l.MaxSize) || (l.MaxFiles > 0 && usage.Files > l.MaxFiles)
+}
+
+// Contains reports whether path is in the limited directory
+func (l Limit) Contains(path string) bool {
+	return path == l.Path || strings.HasPrefix(path, l.Path+string(filepath.Separator))
+}
+
+// holds reports whether the source of a move is already in the limited
+// directory; "" is the source of a copy, which is in no directory
+func (l Limit) holds(src string) bool {
+	return src != "" && l.Contains(absolute(src))
+}
+
+// Describe renders usage against the limit, e.g. "51.2 GB of 50.0 GB, 1200 files"
+func (l Limit) Describe(usage Usage) string {
+	size := units.FormatSize(usage.Size)
+	if l.MaxSize > 0 {
+		size += " of " + units.FormatSize(l.MaxSize)
+	}
+	files := fmt.Sprintf("%d files", usage.Files)
+	if l.MaxFiles > 0 {
+		files = fmt.Sprintf("%d of %d files", usage.Files, l.MaxFiles)
+	}
+	return size + ", " + files
+}
+
+// Violation is a move or copy taking a directory over its quota
+type Violation struct {
+	Limit Limit
+	Usage Usage  // What the directory holds with the incoming file
+	File  string // The incoming file
+}
+
+// String describes the violation for logs and notifications
+func (v Violation) String() string {
+	return fmt.Sprintf("%s is over its quota (%s)", v.Limit.Path, v.Limit.Describe(v.Usage))
+}
+
+// measured is a directory's usage and when it was measured
+type measured struct {
+	usage Usage
+	at    time.Time
+}
+
+// Checker checks moves and copies against the quotas. It is safe for
+// concurrent use, and a nil Checker allows everything.
+type Checker struct {
+	limits []Limit
+
+	mu      sync.Mutex
+	usage   map[string]measured
+	over    map[string]bool // Directories reported over quota and not yet back under
+	handler func(Violation)
+}
+
+// New returns a Checker for quotas. Quotas that don't parse are skipped;
+// Config.Validate reports them.
+func New(quotas []config.Quota) *Checker {
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	c := &Checker{usage: make(map[string]measured), over: make(map[string]bool)}
+	for _, quota := range quotas {
+		limit := Limit{Quota: quota, Path: absolute(quota.Directory)}
+		if quota.MaxSize != "" {
+			size, err := units.ParseSize(quota.MaxSize)
+			if err != nil {
+				continue
+			}
+			limit.MaxSize = size
+		}
+		c.limits = append(c.limits, limit)
+	}
+	return c
+}
+
+// SetHandler sets the function told when a directory goes over its quota.
+// It is called once per directory until the directory is back under quota.
+func (c *Checker) SetHandler(handler func(Violation)) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.handler = handler
+	c.mu.Unlock()
+}
+
+// Limits returns the quotas being checked
+func (c *Checker) Limits() []Limit {
+	if c == nil {
+		return nil
+	}
+	return append([]Limit(nil), c.limits...)
+}
+
+// Usage measures a limited directory afresh
+func (c *Checker) Usage(limit Limit) (Usage, error) {
+	usage, err := Measure(limit.Path)
+	if err != nil || c == nil {
+		return usage, err
+	}
+	c.mu.Lock()
+	c.usage[limit.Path] = measured{usage: usage, at: time.Now()}
+	if !limit.Exceeded(usage) {
+		delete(c.over, limit.Path)
+	}
+	c.mu.Unlock()
+	return usage, nil
+}
+
+// Check returns the quotas a file of size bytes arriving at dest from src
+// would take over their limit. Directories already holding src don't grow;
+// pass "" as src for copies. It changes nothing and tells no one; see Enforce.
+func (c *Checker) Check(src, dest string, size int64) []Violation {
+	if c == nil {
+		return nil
+	}
+	dest = absolute(dest)
+
+	var violations []Violation
+	for _, limit := range c.limits {
+		if !limit.Contains(dest) || limit.holds(src) {
+			continue
+		}
+		usage, err := c.cachedUsage(limit)
+		if err != nil {
+			continue
+		}
+		usage.Size += size
+		usage.Files++
+		if limit.Exceeded(usage) {
+			violations = append(violations, Violation{Limit: limit, Usage: usage, File: dest})
+		}
+	}
+	return violations
+}
+
+// Enforce checks a file of size bytes arriving at dest from src, tells the handler
+// about directories newly over quota, and returns a QuotaExceeded error when
+// one of them stops routing there
+func (c *Checker) Enforce(src, dest string, size int64) error {
+	violations := c.Check(src, dest, size)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	handler := c.handler
+	var fresh []Violation
+	for _, violation := range violations {
+		if !c.over[violation.Limit.Path] {
+			c.over[violation.Limit.Path] = true
+			fresh = append(fresh, violation)
+		}
+	}
+	c.mu.Unlock()
+
+	if handler != nil {
+		for _, violation := range fresh {
+			handler(violation)
+		}
+	}
+	return Refusal(violations)
+}
+
+// Added records a file of size bytes arriving at dest from src, so that
+// cached usages stay current between measurements
+func (c *Checker) Added(src, dest string, size int64) {
+	if c == nil {
+		return
+	}
+	dest = absolute(dest)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, limit := range c.limits {
+		if m, ok := c.usage[limit.Path]; ok && limit.Contains(dest) && !limit.holds(src) {
+			m.usage.Size += size
+			m.usage.Files++
+			c.usage[limit.Path] = m
+		}
+	}
+}
+
+// Refusal returns the error for the first violation whose quota stops
+// routing, or nil when all of them let files through
+func Refusal(violations []Violation) error {
+	for _, violation := range violations {
+		if violation.Limit.Action() == ActionStop {
+			return errors.NewFileError("destination is over its quota ("+violation.Limit.Describe(violation.Usage)+")",
+				violation.Limit.Path, errors.QuotaExceeded, nil)
+		}
+	}
+	return nil
+}
+
+// cachedUsage returns the limited directory's usage, measuring it when the
+// last measurement is older than usageTTL
+func (c *Checker) cachedUsage(limit Limit) (Usage, error) {
+	c.mu.Lock()
+	m, ok := c.usage[limit.Path]
+	c.mu.Unlock()
+	if ok && time.Since(m.at) < usageTTL {
+		return m.usage, nil
+	}
+	return c.Usage(limit)
+}
+
+// absolute returns path made absolute and clean
+func absolute(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,124 @@
+package screenshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// maxTextChunk bounds the text chunks read; screenshot metadata is small, and
+// a larger chunk is more likely an embedded profile or thumbnail
+const maxTextChunk = 64 << 10
+
+// pngText returns the text chunks (tEXt, zTXt and iTXt) of a PNG file by
+// keyword, e.g. "Software" or "Creation Time". Only the chunks before the
+// image data are read, which is where screenshot tools put them.
+func pngText(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, signature); err != nil || !bytes.Equal(signature, pngSignature) {
+		return nil, fmt.Errorf("%s is not a PNG file", path)
+	}
+
+	text := make(map[string]string)
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return text, nil
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		kind := string(header[4:])
+
+		if kind == "IDAT" || kind == "IEND" {
+			return text, nil
+		}
+		if (kind != "tEXt" && kind != "zTXt" && kind != "iTXt") || length > maxTextChunk {
+			// Skip the chunk and its CRC
+			if _, err := r.Discard(int(length) + 4); err != nil {
+				return text, nil
+			}
+			continue
+		}
+
+		data := make([]byte, length+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return text, nil
+		}
+		if keyword, value, ok := parseTextChunk(kind, data[:length]); ok {
+			text[keyword] = value
+		}
+	}
+}
+
+// parseTextChunk decodes a tEXt, zTXt or iTXt chunk into its keyword and text
+func parseTextChunk(kind string, data []byte) (string, string, bool) {
+	keyword, rest, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return "", "", false
+	}
+
+	switch kind {
+	case "tEXt":
+		return string(keyword), latin1(rest), true
+
+	case "zTXt":
+		if len(rest) < 1 {
+			return "", "", false
+		}
+		value, err := inflate(rest[1:])
+		return string(keyword), latin1(value), err == nil
+
+	default: // iTXt: compression flag and method, language tag, translated keyword, text
+		if len(rest) < 2 {
+			return "", "", false
+		}
+		compressed := rest[0] == 1
+		_, rest, ok = bytes.Cut(rest[2:], []byte{0})
+		if !ok {
+			return "", "", false
+		}
+		_, value, ok := bytes.Cut(rest, []byte{0})
+		if !ok {
+			return "", "", false
+		}
+		if compressed {
+			var err error
+			if value, err = inflate(value); err != nil {
+				return "", "", false
+			}
+		}
+		return string(keyword), string(value), true
+	}
+}
+
+// inflate decompresses zlib data, up to the text chunk limit
+func inflate(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(io.LimitReader(zr, maxTextChunk))
+}
+
+// latin1 converts ISO 8859-1 text, which tEXt and zTXt chunks hold, to UTF-8
+func latin1(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -0,0 +1,330 @@
+// Package screenshot recognises screenshots by the names operating systems
+// and screenshot tools give them, or by their PNG metadata, and works out the
+// {date}-{app or keyword} name and month folder settings.screenshots files
+// them under.
+package screenshot
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"sortd/internal/analysis"
+	"sortd/internal/config"
+)
+
+// DefaultFolder is where screenshots are filed when settings.screenshots.folder is empty
+const DefaultFolder = "Screenshots"
+
+// Fallback is the name part used when nothing tells which app a screenshot shows
+const Fallback = "screenshot"
+
+// Info is what was found out about a screenshot
+type Info struct {
+	Path  string
+	Taken time.Time
+	App   string // App or keyword for the name, e.g. "chrome" or "invoice"
+	By    string // What gave it away: "name" or "metadata"
+}
+
+// Name returns the name the screenshot is filed under, e.g. 2024-03-01-chrome.png
+func (i Info) Name() string {
+	return i.Taken.Format("2006-01-02") + "-" + i.App + strings.ToLower(filepath.Ext(i.Path))
+}
+
+// namePatterns match the names screenshot tools give, without the extension.
+// Named groups give the time taken and, for some, the app.
+var namePatterns = []*regexp.Regexp{
+	// macOS: "Screenshot 2024-03-01 at 10.22.33" (older: "Screen Shot ... at 10.22.33 AM")
+	regexp.MustCompile(`(?i)^screen ?shot (?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2}) at (?P<hour>\d{1,2})[.:](?P<min>\d{2})[.:](?P<sec>\d{2})(?:[\s\x{202F}]*(?P<ampm>[ap]m))?`),
+	// GNOME: "Screenshot from 2024-03-01 10-22-33"
+	regexp.MustCompile(`(?i)^screenshot from (?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})[ _](?P<hour>\d{2})-(?P<min>\d{2})-(?P<sec>\d{2})`),
+	// Windows Snipping Tool: "Screenshot 2024-03-01 102233"
+	regexp.MustCompile(`(?i)^screenshot (?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2}) (?P<hour>\d{2})(?P<min>\d{2})(?P<sec>\d{2})`),
+	// KDE Spectacle "Screenshot_20240301_102233", Android "Screenshot_20240301-102233_Chrome"
+	regexp.MustCompile(`(?i)^screenshot_(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})[-_](?P<hour>\d{2})(?P<min>\d{2})(?P<sec>\d{2})\d*(?:[-_](?P<app>.+))?$`),
+	// Xfce: "Screenshot_2024-03-01_10-22-33"
+	regexp.MustCompile(`(?i)^screenshot_(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})_(?P<hour>\d{2})-(?P<min>\d{2})-(?P<sec>\d{2})`),
+	// scrot: "2024-03-01-102233_1920x1080_scrot"
+	regexp.MustCompile(`(?i)^(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})-(?P<hour>\d{2})(?P<min>\d{2})(?P<sec>\d{2})_\d+x\d+_scrot`),
+	// Greenshot: "2024-03-01 10_22_33-Inbox - Mozilla Thunderbird"
+	regexp.MustCompile(`(?i)^(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2}) (?P<hour>\d{2})_(?P<min>\d{2})_(?P<sec>\d{2})-(?P<app>.+)$`),
+	// Windows Print Screen "Screenshot (12)", and anything else calling itself one
+	regexp.MustCompile(`(?i)screen ?shot`),
+}
+
+// nameDate finds a date in a name no pattern gave the time of, e.g. "screenshot-20240301"
+var nameDate = regexp.MustCompile(`(?P<year>20\d{2})-?(?P<month>[01]\d)-?(?P<day>[0-3]\d)`)
+
+// screenshotTools appear in the metadata of images screenshot tools save
+var screenshotTools = regexp.MustCompile(`(?i)screen ?shot|screen ?captur|spectacle|greenshot|sharex|flameshot|shutter|ksnip|snipping|scrot|\bgrim\b|screenshooter|lightshot|snagit|cleanshot`)
+
+// xmpDate finds when an image was made in its XMP metadata
+var xmpDate = regexp.MustCompile(`<(?:photoshop:DateCreated|xmp:CreateDate|exif:DateTimeOriginal)>([^<]+)<`)
+
+// metadataTimes are the layouts PNG "Creation Time" chunks are written in
+var metadataTimes = []string{
+	time.RFC1123, time.RFC1123Z, time.RFC3339, "2006-01-02T15:04:05",
+	"2006:01:02 15:04:05", "2006-01-02 15:04:05", "Mon Jan 2 15:04:05 2006",
+}
+
+// readText reads the text in an image with OCR; a variable so tests can fake it
+var readText = func(path string) (string, error) {
+	return analysis.ExtractText(path, "image/png", true)
+}
+
+// Detect reports whether the file at path is a screenshot, and when it was
+// taken and of which app. The app comes from the name, a window title in the
+// metadata or, with ocr set, the screenshot's most frequent word. Of a window
+// title, as Greenshot puts in names, the app is the last part.
+func Detect(path string, ocr bool) (Info, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+		return Info{}, false
+	}
+
+	info := Info{Path: path}
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, pattern := range namePatterns {
+		if groups := matchGroups(pattern, base); groups != nil {
+			info.By = "name"
+			info.Taken = groupTime(groups)
+			info.App = slug(appFromTitle(groups["app"]))
+			break
+		}
+	}
+
+	var text map[string]string
+	if ext == ".png" {
+		text, _ = pngText(path)
+	}
+	if info.By == "" {
+		if !fromScreenshotTool(text) {
+			return Info{}, false
+		}
+		info.By = "metadata"
+	}
+
+	if info.Taken.IsZero() {
+		if groups := matchGroups(nameDate, base); groups != nil {
+			info.Taken = groupTime(groups)
+		}
+	}
+	if info.Taken.IsZero() {
+		info.Taken = metadataTime(text)
+	}
+	if info.Taken.IsZero() {
+		if stat, err := os.Stat(path); err == nil {
+			info.Taken = stat.ModTime()
+		}
+	}
+
+	if info.App == "" {
+		info.App = slug(appFromTitle(text["Title"]))
+	}
+	if info.App == "" && ocr {
+		if content, err := readText(path); err == nil {
+			info.App = slug(keyword(content))
+		}
+	}
+	if info.App == "" {
+		info.App = Fallback
+	}
+	return info, true
+}
+
+// matchGroups returns the named groups of the pattern's match of s, or nil
+func matchGroups(pattern *regexp.Regexp, s string) map[string]string {
+	match := pattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil
+	}
+	groups := make(map[string]string)
+	for i, name := range pattern.SubexpNames() {
+		if name != "" {
+			groups[name] = match[i]
+		}
+	}
+	return groups
+}
+
+// groupTime builds the local time in the groups, or the zero time when they
+// hold no valid date
+func groupTime(groups map[string]string) time.Time {
+	number := func(name string) int {
+		n, _ := strconv.Atoi(groups[name])
+		return n
+	}
+	year, month, day := number("year"), number("month"), number("day")
+	if year == 0 || month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}
+	}
+	hour := number("hour")
+	switch strings.ToLower(groups["ampm"]) {
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	}
+	return time.Date(year, time.Month(month), day, hour, number("min"), number("sec"), 0, time.Local)
+}
+
+// fromScreenshotTool reports whether PNG metadata says a screenshot tool
+// made the image: its software, or macOS's "Screenshot" user comment in XMP
+func fromScreenshotTool(text map[string]string) bool {
+	for _, key := range []string{"Software", "Source", "Description", "Comment"} {
+		if screenshotTools.MatchString(text[key]) {
+			return true
+		}
+	}
+	return strings.Contains(strings.ToLower(text["XML:com.adobe.xmp"]), "screenshot")
+}
+
+// metadataTime returns when PNG metadata says the image was made
+func metadataTime(text map[string]string) time.Time {
+	values := []string{strings.TrimSpace(text["Creation Time"])}
+	if match := xmpDate.FindStringSubmatch(text["XML:com.adobe.xmp"]); match != nil {
+		values = append(values, strings.TrimSpace(match[1]))
+	}
+	for _, value := range values {
+		for _, layout := range metadataTimes {
+			if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// appFromTitle takes the app from a window title, which names it last, as in
+// "Inbox - Mozilla Thunderbird"
+func appFromTitle(title string) string {
+	for _, separator := range []string{" - ", " — ", " – ", " | "} {
+		if i := strings.LastIndex(title, separator); i >= 0 {
+			title = title[i+len(separator):]
+		}
+	}
+	return strings.TrimSpace(title)
+}
+
+// stopWords are left out when picking a keyword from a screenshot's text
+var stopWords = map[string]bool{
+	"that": true, "this": true, "with": true, "from": true, "have": true, "your": true,
+	"will": true, "what": true, "when": true, "there": true, "their": true, "which": true,
+	"about": true, "would": true, "they": true, "been": true, "were": true, "into": true,
+	"more": true, "than": true, "then": true, "them": true, "only": true, "also": true,
+	"here": true, "some": true, "just": true, "like": true, "file": true, "edit": true,
+	"view": true, "help": true, "window": true, "close": true, "search": true,
+}
+
+// keyword returns the most frequent word of four or more letters in text,
+// the first one on a tie, or "" when there is none
+func keyword(text string) string {
+	counts := make(map[string]int)
+	best, bestCount := "", 0
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		word = strings.ToLower(word)
+		if len([]rune(word)) < 4 || stopWords[word] {
+			continue
+		}
+		counts[word]++
+		if counts[word] > bestCount {
+			best, bestCount = word, counts[word]
+		}
+	}
+	return best
+}
+
+// maxSlug bounds the app or keyword part of a name
+const maxSlug = 40
+
+// slug turns s into a lower-case name part of letters, digits and dashes
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	result := []rune(b.String())
+	if len(result) > maxSlug {
+		result = result[:maxSlug]
+	}
+	return strings.Trim(string(result), "-")
+}
+
+// Folder returns the absolute folder screenshots are filed under. A relative
+// folder is taken from the default directory.
+func Folder(cfg *config.Config) string {
+	folder, base := DefaultFolder, ""
+	if cfg != nil {
+		if cfg.Settings.Screenshots.Folder != "" {
+			folder = cfg.Settings.Screenshots.Folder
+		}
+		base = cfg.Directories.Default
+	}
+	if !filepath.IsAbs(folder) {
+		folder = filepath.Join(base, folder)
+	}
+	if abs, err := filepath.Abs(folder); err == nil {
+		return abs
+	}
+	return filepath.Clean(folder)
+}
+
+// InFolder reports whether path is inside the screenshots folder, where
+// screenshots already filed are left alone
+func InFolder(cfg *config.Config, path string) bool {
+	folder := Folder(cfg)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path == folder || strings.HasPrefix(path, folder+string(filepath.Separator))
+}
+
+// Enabled reports whether cfg turns screenshot handling on
+func Enabled(cfg *config.Config) bool {
+	return cfg != nil && cfg.Settings.Screenshots.Enabled
+}
+
+// Destination returns where a screenshot is filed: its month folder under the
+// screenshots folder, e.g. Screenshots/2024-03/2024-03-01-chrome.png. When
+// that name is taken, on disk or in reserved, a number is added, as in
+// 2024-03-01-chrome-2.png. The name returned is added to reserved if it isn't nil.
+func Destination(cfg *config.Config, info Info, reserved map[string]bool) string {
+	dir := filepath.Join(Folder(cfg), info.Taken.Format("2006-01"))
+	name := info.Name()
+	ext := filepath.Ext(name)
+	dest := filepath.Join(dir, name)
+	for n := 2; taken(dest, reserved); n++ {
+		dest = filepath.Join(dir, strings.TrimSuffix(name, ext)+"-"+strconv.Itoa(n)+ext)
+	}
+	if reserved != nil {
+		reserved[dest] = true
+	}
+	return dest
+}
+
+// taken reports whether dest is already on disk or reserved
+func taken(dest string, reserved map[string]bool) bool {
+	if reserved[dest] {
+		return true
+	}
+	_, err := os.Lstat(dest)
+	return err == nil
+}
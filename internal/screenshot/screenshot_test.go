@@ -0,0 +1,143 @@
+package screenshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePNG writes a small PNG with the given tEXt chunks after its header
+func writePNG(t *testing.T, path string, text map[string]string) {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	require.NoError(t, png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 4, 4))))
+	data := encoded.Bytes()
+	headerEnd := len(pngSignature) + 25 // Signature, then the IHDR chunk
+
+	var chunks bytes.Buffer
+	for keyword, value := range text {
+		body := append([]byte("tEXt"+keyword+"\x00"), value...)
+		binary.Write(&chunks, binary.BigEndian, uint32(len(body)-4))
+		chunks.Write(body)
+		binary.Write(&chunks, binary.BigEndian, crc32.ChecksumIEEE(body))
+	}
+
+	out := append(append(append([]byte{}, data[:headerEnd]...), chunks.Bytes()...), data[headerEnd:]...)
+	require.NoError(t, os.WriteFile(path, out, 0644))
+}
+
+func TestDetectByName(t *testing.T) {
+	dir := t.TempDir()
+	for name, want := range map[string]string{
+		"Screenshot 2024-03-01 at 10.22.33.png":           "2024-03-01 10:22:33 screenshot",
+		"Screen Shot 2019-06-30 at 9.05.01 PM.png":        "2019-06-30 21:05:01 screenshot",
+		"Screenshot from 2024-03-01 10-22-33.png":         "2024-03-01 10:22:33 screenshot",
+		"Screenshot 2024-03-01 102233.png":                "2024-03-01 10:22:33 screenshot",
+		"Screenshot_20240301_102233.png":                  "2024-03-01 10:22:33 screenshot",
+		"Screenshot_20240301-102233_Chrome.jpg":           "2024-03-01 10:22:33 chrome",
+		"Screenshot_2024-03-01_10-22-33.png":              "2024-03-01 10:22:33 screenshot",
+		"2024-03-01-102233_1920x1080_scrot.png":           "2024-03-01 10:22:33 screenshot",
+		"2024-03-01 10_22_33-Inbox - Mozilla Firefox.png": "2024-03-01 10:22:33 mozilla-firefox",
+		"my screenshot 20240301.png":                      "2024-03-01 00:00:00 screenshot",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+
+		info, ok := Detect(path, false)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, "name", info.By, name)
+			assert.Equal(t, want, info.Taken.Format("2006-01-02 15:04:05")+" "+info.App, name)
+		}
+	}
+
+	for _, name := range []string{"holiday.png", "Screenshot 2024-03-01 at 10.22.33.pdf", "IMG_0001.jpg"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+		_, ok := Detect(path, false)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestDetectByMetadata(t *testing.T) {
+	dir := t.TempDir()
+
+	tool := filepath.Join(dir, "capture.png")
+	writePNG(t, tool, map[string]string{
+		"Software":      "Greenshot",
+		"Title":         "Quarterly report.xlsx - Excel",
+		"Creation Time": "Fri, 01 Mar 2024 10:22:33 +0000",
+	})
+	info, ok := Detect(tool, false)
+	require.True(t, ok)
+	assert.Equal(t, "metadata", info.By)
+	assert.Equal(t, "excel", info.App)
+	assert.Equal(t, "2024-03-01", info.Taken.UTC().Format("2006-01-02"))
+
+	mac := filepath.Join(dir, "image.png")
+	writePNG(t, mac, map[string]string{
+		"XML:com.adobe.xmp": `<x:xmpmeta><exif:UserComment>Screenshot</exif:UserComment></x:xmpmeta>`,
+	})
+	_, ok = Detect(mac, false)
+	assert.True(t, ok)
+
+	photo := filepath.Join(dir, "photo.png")
+	writePNG(t, photo, map[string]string{"Software": "GIMP 2.10"})
+	_, ok = Detect(photo, false)
+	assert.False(t, ok)
+}
+
+func TestDetectOCR(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Screenshot_20240301_102233.png")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+
+	original := readText
+	defer func() { readText = original }()
+	readText = func(string) (string, error) {
+		return "Invoice #42\nInvoice date: 1 March\nTotal due with invoice", nil
+	}
+
+	info, ok := Detect(path, false)
+	require.True(t, ok)
+	assert.Equal(t, Fallback, info.App, "Without ocr the text isn't read")
+
+	info, ok = Detect(path, true)
+	require.True(t, ok)
+	assert.Equal(t, "invoice", info.App)
+}
+
+func TestDestination(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Directories.Default = root
+
+	info := Info{Path: "/in/Screenshot.PNG", Taken: time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local), App: "chrome"}
+	month := filepath.Join(root, DefaultFolder, "2024-03")
+	reserved := make(map[string]bool)
+
+	assert.Equal(t, filepath.Join(month, "2024-03-01-chrome.png"), Destination(cfg, info, reserved))
+	assert.Equal(t, filepath.Join(month, "2024-03-01-chrome-2.png"), Destination(cfg, info, reserved),
+		"A name planned already gets a number")
+
+	require.NoError(t, os.MkdirAll(month, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(month, "2024-03-01-chrome.png"), nil, 0644))
+	assert.Equal(t, filepath.Join(month, "2024-03-01-chrome-2.png"), Destination(cfg, info, nil),
+		"A name taken on disk gets a number")
+
+	assert.True(t, InFolder(cfg, filepath.Join(month, "2024-03-01-chrome.png")))
+	assert.False(t, InFolder(cfg, filepath.Join(root, "Desktop", "x.png")))
+
+	cfg.Settings.Screenshots.Folder = "/shots"
+	assert.Equal(t, "/shots", Folder(cfg))
+}
@@ -0,0 +1,42 @@
+// Package trace gives each file the daemon or a workflow handles an ID that
+// follows it from the watcher through the engine to workflow actions. The ID
+// appears in log lines, the activity log, workflow run records and webhook
+// payloads, so 'sortd logs --grep <id>' shows a single file's journey.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Field is the name of the log field holding the ID
+const Field = "trace"
+
+type key struct{}
+
+// New returns a fresh ID
+func New() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// With returns a copy of ctx carrying id
+func With(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, key{}, id)
+}
+
+// From returns the ID carried by ctx, or "" when there is none
+func From(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(key{}).(string)
+	return id
+}
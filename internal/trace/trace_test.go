@@ -0,0 +1,19 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace(t *testing.T) {
+	id := New()
+	assert.Len(t, id, 12)
+	assert.NotEqual(t, id, New())
+
+	ctx := With(context.Background(), id)
+	assert.Equal(t, id, From(ctx))
+	assert.Empty(t, From(context.Background()))
+	assert.Equal(t, context.Background(), With(context.Background(), ""))
+}
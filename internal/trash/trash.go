@@ -0,0 +1,124 @@
+// Package trash sends files to the desktop trash rather than deleting them,
+// so a hasty decision can be taken back from the file manager: the
+// freedesktop.org trash on Linux and the BSDs, ~/.Trash on macOS.
+package trash
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"sortd/internal/atomicfile"
+)
+
+// Dir returns the trash directory of the current user
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, ".Trash"), nil
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "Trash"), nil
+}
+
+// Move sends the file at path to the trash and returns where it went. On
+// freedesktop systems a .trashinfo entry records the original location, so
+// the file manager can restore it.
+func Move(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	files := dir
+	freedesktop := runtime.GOOS != "darwin"
+	if freedesktop {
+		files = filepath.Join(dir, "files")
+		if err := os.MkdirAll(filepath.Join(dir, "info"), 0700); err != nil {
+			return "", fmt.Errorf("failed to create trash: %w", err)
+		}
+	}
+	if err := os.MkdirAll(files, 0700); err != nil {
+		return "", fmt.Errorf("failed to create trash: %w", err)
+	}
+
+	name, err := reserve(dir, files, filepath.Base(path), freedesktop, path)
+	if err != nil {
+		return "", err
+	}
+	dest := filepath.Join(files, name)
+	if err := move(path, dest); err != nil {
+		if freedesktop {
+			os.Remove(infoPath(dir, name))
+		}
+		return "", fmt.Errorf("failed to move %s to the trash: %w", path, err)
+	}
+	return dest, nil
+}
+
+// reserve picks a name not yet in the trash. On freedesktop systems the name
+// is claimed by creating its .trashinfo file first, as the spec asks.
+func reserve(dir, files, base string, freedesktop bool, original string) (string, error) {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, n, ext)
+		}
+		if _, err := os.Lstat(filepath.Join(files, name)); err == nil {
+			continue
+		}
+		if !freedesktop {
+			return name, nil
+		}
+
+		info, err := os.OpenFile(infoPath(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to record trashed file: %w", err)
+		}
+		_, err = fmt.Fprintf(info, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+			(&url.URL{Path: original}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+		if closeErr := info.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(infoPath(dir, name))
+			return "", fmt.Errorf("failed to record trashed file: %w", err)
+		}
+		return name, nil
+	}
+}
+
+// infoPath returns the .trashinfo file for a name in a freedesktop trash
+func infoPath(dir, name string) string {
+	return filepath.Join(dir, "info", name+".trashinfo")
+}
+
+// move renames src to dest, copying when they're on different filesystems
+func move(src, dest string) error {
+	if err := os.Rename(src, dest); err == nil {
+		return nil
+	}
+	if err := atomicfile.CopyFile(src, dest); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,39 @@
+//go:build !darwin
+
+package trash_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/trash"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMove(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		path := filepath.Join(dir, "old download.zip")
+		require.NoError(t, os.WriteFile(path, []byte("zip"), 0644))
+
+		dest, err := trash.Move(path)
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+		assert.FileExists(t, dest)
+	}
+
+	files := filepath.Join(dataHome, "Trash", "files")
+	assert.FileExists(t, filepath.Join(files, "old download.zip"))
+	assert.FileExists(t, filepath.Join(files, "old download.2.zip"), "A second file of the same name shouldn't replace the first")
+
+	info, err := os.ReadFile(filepath.Join(dataHome, "Trash", "info", "old download.zip.trashinfo"))
+	require.NoError(t, err)
+	assert.Contains(t, string(info), "Path="+filepath.ToSlash(dir)+"/old%20download.zip\n")
+	assert.Contains(t, string(info), "DeletionDate=")
+}
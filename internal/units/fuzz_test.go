@@ -0,0 +1,35 @@
+package units_test
+
+import (
+	"testing"
+	"time"
+
+	"sortd/internal/units"
+)
+
+func FuzzParseSize(f *testing.F) {
+	for _, seed := range []string{"512", "10KB", "1.5 MB", "2g", "2,5 GiB", "1e30TB", "NaN", "Inf", "-1", "KB", "1,500", ""} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		size, err := units.ParseSize(s)
+		if err == nil && size < 0 {
+			t.Fatalf("ParseSize(%q) = %d, a negative size", s, size)
+		}
+	})
+}
+
+func FuzzParseAge(f *testing.F) {
+	for _, seed := range []string{"30d", "2w", "90m", "1.5h", "3 weeks ago", "yesterday", "2024-01-31", "1e300w", "NaNd", "Infh", "-1d", "d", ""} {
+		f.Add(seed)
+	}
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	f.Fuzz(func(t *testing.T, s string) {
+		age, err := units.ParseAge(s, now)
+		if err == nil && age < 0 {
+			t.Fatalf("ParseAge(%q) = %v, a negative age", s, age)
+		}
+	})
+}
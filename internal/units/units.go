@@ -0,0 +1,197 @@
+// Package units reads the sizes and ages people write, like "10MB",
+// "2.5 GiB", "3 weeks" or "yesterday", so workflow conditions and config
+// values all understand the same strings, and writes sizes back the same way.
+//
+// Sizes count in powers of 1024 whether or not the unit has an i (KB and KiB
+// are the same). Durations take m for minutes and mo for months; a month is 30
+// days and a year 365. A comma can stand in for the decimal point ("1,5 MB"),
+// but a comma followed by three digits is refused, since "1,500" means
+// fifteen hundred in some places and one and a half in others.
+package units
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unit is a suffix and what one of it is worth
+type unit struct {
+	name   string
+	factor float64
+}
+
+const (
+	day   = 24 * time.Hour
+	week  = 7 * day
+	month = 30 * day
+	year  = 365 * day
+)
+
+// sizeUnits are the size suffixes, longest first so that "kb" wins over "b"
+var sizeUnits = byLength([]unit{
+	{"b", 1}, {"byte", 1}, {"bytes", 1},
+	{"k", 1 << 10}, {"kb", 1 << 10}, {"kib", 1 << 10}, {"kilobyte", 1 << 10}, {"kilobytes", 1 << 10},
+	{"m", 1 << 20}, {"mb", 1 << 20}, {"mib", 1 << 20}, {"megabyte", 1 << 20}, {"megabytes", 1 << 20},
+	{"g", 1 << 30}, {"gb", 1 << 30}, {"gib", 1 << 30}, {"gigabyte", 1 << 30}, {"gigabytes", 1 << 30},
+	{"t", 1 << 40}, {"tb", 1 << 40}, {"tib", 1 << 40}, {"terabyte", 1 << 40}, {"terabytes", 1 << 40},
+	{"p", 1 << 50}, {"pb", 1 << 50}, {"pib", 1 << 50}, {"petabyte", 1 << 50}, {"petabytes", 1 << 50},
+	// No bare "e": "1e" is more likely a mangled exponent than exabytes
+	{"eb", 1 << 60}, {"eib", 1 << 60}, {"exabyte", 1 << 60}, {"exabytes", 1 << 60},
+})
+
+// durationUnits are the duration suffixes, longest first so that "mins" wins over "s"
+var durationUnits = byLength([]unit{
+	{"s", float64(time.Second)}, {"sec", float64(time.Second)}, {"secs", float64(time.Second)},
+	{"second", float64(time.Second)}, {"seconds", float64(time.Second)},
+	{"m", float64(time.Minute)}, {"min", float64(time.Minute)}, {"mins", float64(time.Minute)},
+	{"minute", float64(time.Minute)}, {"minutes", float64(time.Minute)},
+	{"h", float64(time.Hour)}, {"hr", float64(time.Hour)}, {"hrs", float64(time.Hour)},
+	{"hour", float64(time.Hour)}, {"hours", float64(time.Hour)},
+	{"d", float64(day)}, {"day", float64(day)}, {"days", float64(day)},
+	{"w", float64(week)}, {"wk", float64(week)}, {"wks", float64(week)}, {"week", float64(week)}, {"weeks", float64(week)},
+	{"mo", float64(month)}, {"month", float64(month)}, {"months", float64(month)},
+	{"y", float64(year)}, {"yr", float64(year)}, {"yrs", float64(year)}, {"year", float64(year)}, {"years", float64(year)},
+})
+
+// dateLayouts are the ways ParseAge accepts a date or time to be written
+var dateLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"}
+
+// byLength sorts units longest name first
+func byLength(units []unit) []unit {
+	sort.SliceStable(units, func(i, j int) bool { return len(units[i].name) > len(units[j].name) })
+	return units
+}
+
+// ParseSize reads sizes like "512", "10KB", "2.5 GiB" or "1,5 megabytes"
+func ParseSize(s string) (int64, error) {
+	n, factor, err := split(s, sizeUnits, false)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a size: %w", s, err)
+	}
+	if n*factor >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+	return int64(n * factor), nil
+}
+
+// FormatSize writes a size in bytes the way ParseSize reads it, like "512 B"
+// or "1.5 MB", with one decimal in the largest unit that fits
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// ParseDuration reads lengths of time like "30d", "90m", "1.5 hours" or "3 weeks"
+func ParseDuration(s string) (time.Duration, error) {
+	n, factor, err := split(s, durationUnits, true)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration: %w", s, err)
+	}
+	if n*factor >= math.MaxInt64 {
+		return 0, fmt.Errorf("duration %q is too long", s)
+	}
+	return time.Duration(n * factor), nil
+}
+
+// ParseAge reads how long before now something happened: a duration, with or
+// without "ago" ("3 weeks", "2 days ago"), "today" or "yesterday" (since the
+// start of the day), or a date ("2024-01-31", "2024-01-31 18:00"). Dates are in
+// now's time zone, and ages in the future are refused.
+func ParseAge(s string, now time.Time) (time.Duration, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var since time.Time
+	switch text {
+	case "":
+		return 0, fmt.Errorf("empty age")
+	case "now":
+		return 0, nil
+	case "today":
+		since = midnight
+	case "yesterday":
+		since = midnight.AddDate(0, 0, -1)
+	default:
+		if at, ok := parseDate(strings.TrimSpace(s), now.Location()); ok {
+			since = at
+			break
+		}
+		return ParseDuration(strings.TrimSpace(strings.TrimSuffix(text, "ago")))
+	}
+
+	age := now.Sub(since)
+	if age < 0 {
+		return 0, fmt.Errorf("age %q is in the future", s)
+	}
+	return age, nil
+}
+
+// parseDate reads s in one of the date layouts
+func parseDate(s string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if at, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// split reads a number and the unit after it, returning the number and the
+// unit's factor. A missing unit counts as 1 unless needUnit is set.
+func split(s string, units []unit, needUnit bool) (float64, float64, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	factor := 1.0
+	found := false
+	for _, u := range units {
+		if strings.HasSuffix(text, u.name) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, u.name))
+			factor, found = u.factor, true
+			break
+		}
+	}
+	if !found && needUnit {
+		return 0, 0, fmt.Errorf("needs a unit, e.g. 30d or 3 weeks")
+	}
+
+	n, err := parseNumber(text)
+	if err != nil {
+		return 0, 0, err
+	}
+	return n, factor, nil
+}
+
+// parseNumber reads a non-negative number, with a point or a comma before
+// any decimals
+func parseNumber(s string) (float64, error) {
+	if comma := strings.IndexByte(s, ','); comma >= 0 {
+		decimals := s[comma+1:]
+		if strings.ContainsAny(decimals, ",.") || strings.Contains(s[:comma], ".") {
+			return 0, fmt.Errorf("use one decimal separator and no thousands separators")
+		}
+		if len(decimals) == 3 {
+			return 0, fmt.Errorf("%q could mean thousands or decimals; leave out the comma or use a point", s)
+		}
+		s = s[:comma] + "." + decimals
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%q is negative", s)
+	}
+	return n, nil
+}
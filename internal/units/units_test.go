@@ -0,0 +1,103 @@
+package units_test
+
+import (
+	"testing"
+	"time"
+
+	"sortd/internal/units"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	for input, want := range map[string]int64{
+		"512":           512,
+		"10KB":          10 << 10,
+		"1.5 MB":        3 << 19,
+		"2g":            2 << 30,
+		"2.5 GiB":       5 << 29,
+		"1,5 MB":        3 << 19,
+		"3 megabytes":   3 << 20,
+		"100 bytes":     100,
+		" 1 TB ":        1 << 40,
+		"2 PiB":         2 << 50,
+		"4 EB":          4 << 60,
+		"1e3":           1000,
+		"0.5 kilobytes": 512,
+	} {
+		got, err := units.ParseSize(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "KB", "ten MB", "-1KB", "NaN", "Inf GB", "1,500 MB", "1.5,2 MB", "1e30TB", "10 XB", "8 EB", "1e"} {
+		_, err := units.ParseSize(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for size, want := range map[int64]string{
+		0:         "0 B",
+		1023:      "1023 B",
+		1024:      "1.0 KB",
+		3 << 19:   "1.5 MB",
+		5 << 29:   "2.5 GB",
+		1 << 40:   "1.0 TB",
+		1<<62 + 1: "4.0 EB",
+	} {
+		got := units.FormatSize(size)
+		assert.Equal(t, want, got, size)
+
+		// What FormatSize writes, ParseSize reads back
+		_, err := units.ParseSize(got)
+		assert.NoError(t, err, got)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for input, want := range map[string]time.Duration{
+		"30d":       30 * 24 * time.Hour,
+		"90m":       90 * time.Minute,
+		"1.5 hours": 90 * time.Minute,
+		"3 weeks":   21 * 24 * time.Hour,
+		"2 mins":    2 * time.Minute,
+		"1 month":   30 * 24 * time.Hour,
+		"1 year":    365 * 24 * time.Hour,
+		"45s":       45 * time.Second,
+		"0,5 DAYS":  12 * time.Hour,
+	} {
+		got, err := units.ParseDuration(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "30", "d", "-1d", "NaNd", "1e300w", "5 ms", "3 fortnights"} {
+		_, err := units.ParseDuration(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+
+	for input, want := range map[string]time.Duration{
+		"3 weeks":          21 * 24 * time.Hour,
+		"2 days ago":       48 * time.Hour,
+		"now":              0,
+		"today":            15*time.Hour + 30*time.Minute,
+		"Yesterday":        39*time.Hour + 30*time.Minute,
+		"2024-03-09":       39*time.Hour + 30*time.Minute,
+		"2024-03-10 12:00": 3*time.Hour + 30*time.Minute,
+	} {
+		got, err := units.ParseAge(input, now)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "ago", "tomorrow", "2024-03-11", "2024-13-01", "30"} {
+		_, err := units.ParseAge(input, now)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,327 @@
+// Package update finds newer sortd releases, downloads the binary for this
+// platform, checks it against the release's SHA256 checksums and swaps it in
+// for the running executable.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"sortd/internal/atomicfile"
+)
+
+// DefaultEndpoint lists sortd's releases, newest first
+const DefaultEndpoint = "https://api.github.com/repos/Jeff-Barlow-Spady/sortd/releases"
+
+// EndpointEnv overrides the release endpoint, e.g. for a mirror
+const EndpointEnv = "SORTD_UPDATE_URL"
+
+// ChecksumSuffix names the asset holding a binary's SHA256, in sha256sum's
+// format, e.g. "sortd-linux-amd64.sha256"
+const ChecksumSuffix = ".sha256"
+
+// Release channels
+const (
+	ChannelStable = "stable" // Full releases only
+	ChannelBeta   = "beta"   // Pre-releases too
+)
+
+// maxBinarySize bounds a download, so a bad endpoint can't fill the disk
+const maxBinarySize = 256 << 20
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a published version of sortd
+type Release struct {
+	Tag        string  `json:"tag_name"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Version returns the release's version without a leading "v"
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// asset returns the release asset with the given name
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Updater talks to a release endpoint
+type Updater struct {
+	endpoint string
+	client   *http.Client
+	goos     string
+	goarch   string
+}
+
+// New creates an updater for this platform, using $SORTD_UPDATE_URL when set
+func New() *Updater {
+	endpoint := os.Getenv(EndpointEnv)
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	return NewWithEndpoint(endpoint)
+}
+
+// NewWithEndpoint creates an updater for this platform using the given endpoint
+func NewWithEndpoint(endpoint string) *Updater {
+	return &Updater{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+		goos:     runtime.GOOS,
+		goarch:   runtime.GOARCH,
+	}
+}
+
+// AssetName returns the name the release workflow publishes a platform's
+// binary under, e.g. "sortd-linux-amd64" or "sortd-windows-amd64.exe"
+func AssetName(goos, goarch string) string {
+	if goos == "darwin" {
+		goos = "macos"
+	}
+	name := fmt.Sprintf("sortd-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Latest returns the newest release on the channel that has a binary for this platform
+func (u *Updater) Latest(ctx context.Context, channel string) (*Release, error) {
+	if channel != ChannelStable && channel != ChannelBeta {
+		return nil, fmt.Errorf("unknown channel %q: must be %s or %s", channel, ChannelStable, ChannelBeta)
+	}
+
+	body, err := u.get(ctx, u.endpoint, 10<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	var releases []Release
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("failed to read releases: %w", err)
+	}
+
+	var latest *Release
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || (r.Prerelease && channel == ChannelStable) {
+			continue
+		}
+		if _, ok := r.asset(AssetName(u.goos, u.goarch)); !ok {
+			continue
+		}
+		if latest == nil || Newer(r.Version(), latest.Version()) {
+			latest = r
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no %s release has a binary for %s/%s", channel, u.goos, u.goarch)
+	}
+	return latest, nil
+}
+
+// Download fetches the release's binary for this platform and verifies it
+// against the SHA256 published with it. A binary without a checksum is refused.
+func (u *Updater) Download(ctx context.Context, release *Release) ([]byte, error) {
+	name := AssetName(u.goos, u.goarch)
+	binaryAsset, ok := release.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no binary for %s/%s", release.Tag, u.goos, u.goarch)
+	}
+	checksumAsset, ok := release.asset(name + ChecksumSuffix)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s to verify the download with", release.Tag, name+ChecksumSuffix)
+	}
+
+	checksum, err := u.get(ctx, checksumAsset.URL, 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download checksum: %w", err)
+	}
+	want, err := checksumFor(checksum, name)
+	if err != nil {
+		return nil, err
+	}
+
+	binary, err := u.get(ctx, binaryAsset.URL, maxBinarySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	sum := sha256.Sum256(binary)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, want, got)
+	}
+	return binary, nil
+}
+
+// get fetches a URL, reading at most limit bytes
+func (u *Updater) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, limit)
+	}
+	return body, nil
+}
+
+// checksumFor finds a file's SHA256 in sha256sum output, or takes a lone hash
+func checksumFor(checksum []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksum))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 1 || (len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name) {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s%s doesn't list a checksum for %s", name, ChecksumSuffix, name)
+}
+
+// rename and writeFile put the new binary in place; tests swap them out
+var (
+	rename    = os.Rename
+	writeFile = atomicfile.WriteFile
+)
+
+// Replace swaps the executable at path for binary. The new binary is staged
+// next to it and renamed into place, so an interrupted update leaves the old
+// one working. Windows can't replace a running executable, so there the old
+// one is moved aside to path.old first.
+func Replace(path string, binary []byte) error {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(resolved); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return replace(resolved, binary, mode, runtime.GOOS == "windows")
+}
+
+// replace writes binary to path. With moveAside, the file at path is renamed
+// to path.old first and renamed back if the new one can't be put in place,
+// so a failed update never leaves path missing.
+func replace(path string, binary []byte, mode os.FileMode, moveAside bool) error {
+	if !moveAside {
+		return writeFile(path, binary, mode)
+	}
+
+	old := path + ".old"
+	os.Remove(old)
+	if err := rename(path, old); err != nil {
+		return err
+	}
+	if err := writeFile(path, binary, mode); err != nil {
+		if restoreErr := rename(old, path); restoreErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to restore %s from %s: %w", path, old, restoreErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// Newer reports whether version a is newer than b. Versions are compared
+// number by number ("1.10.0" > "1.9.2"); a pre-release ("1.2.0-beta.1") comes
+// before its release. Unparseable versions, like "dev", are older than any other.
+func Newer(a, b string) bool {
+	return compareVersions(strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")) > 0
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, the same as or newer than b
+func compareVersions(a, b string) int {
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+	aNums, aOK := parseCore(aCore)
+	bNums, bOK := parseCore(bCore)
+	switch {
+	case !aOK && !bOK:
+		return 0
+	case !aOK:
+		return -1
+	case !bOK:
+		return 1
+	}
+
+	for i := 0; i < len(aNums) || i < len(bNums); i++ {
+		var x, y int
+		if i < len(aNums) {
+			x = aNums[i]
+		}
+		if i < len(bNums) {
+			y = bNums[i]
+		}
+		if x != y {
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre > bPre:
+		return 1
+	}
+	return -1
+}
+
+// parseCore parses "1.2.3" into its numbers
+func parseCore(core string) ([]int, bool) {
+	parts := strings.Split(core, ".")
+	nums := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		nums[i] = n
+	}
+	return nums, true
+}
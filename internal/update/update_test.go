@@ -0,0 +1,163 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/atomicfile"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer serves a stable and a beta release of a binary for linux/amd64
+func newTestServer(t *testing.T, binary []byte, checksum string) *Updater {
+	t.Helper()
+	name := AssetName("linux", "amd64")
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/releases":
+			asset := func(tag, file string) Asset {
+				return Asset{Name: file, URL: fmt.Sprintf("%s/download/%s/%s", server.URL, tag, file)}
+			}
+			releases := []Release{
+				{Tag: "v1.3.0-beta.1", Prerelease: true, Assets: []Asset{asset("v1.3.0-beta.1", name), asset("v1.3.0-beta.1", name+ChecksumSuffix)}},
+				{Tag: "v1.4.0", Draft: true, Assets: []Asset{asset("v1.4.0", name), asset("v1.4.0", name+ChecksumSuffix)}},
+				{Tag: "v1.2.0", Assets: []Asset{asset("v1.2.0", name), asset("v1.2.0", name+ChecksumSuffix)}},
+				{Tag: "v1.1.0", Assets: []Asset{asset("v1.1.0", "sortd-plan9-386")}},
+			}
+			_ = json.NewEncoder(w).Encode(releases)
+		default:
+			if filepath.Base(r.URL.Path) == name+ChecksumSuffix {
+				fmt.Fprintf(w, "%s  %s\n", checksum, name)
+				return
+			}
+			_, _ = w.Write(binary)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	u := NewWithEndpoint(server.URL + "/releases")
+	u.goos, u.goarch = "linux", "amd64"
+	return u
+}
+
+func TestLatestAndDownload(t *testing.T) {
+	binary := []byte("new sortd")
+	sum := sha256.Sum256(binary)
+	u := newTestServer(t, binary, hex.EncodeToString(sum[:]))
+
+	stable, err := u.Latest(context.Background(), ChannelStable)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", stable.Version(), "Drafts and pre-releases are not stable")
+
+	beta, err := u.Latest(context.Background(), ChannelBeta)
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0-beta.1", beta.Version())
+
+	_, err = u.Latest(context.Background(), "nightly")
+	assert.Error(t, err)
+
+	got, err := u.Download(context.Background(), stable)
+	require.NoError(t, err)
+	assert.Equal(t, binary, got)
+}
+
+func TestDownload_ChecksumMismatch(t *testing.T) {
+	u := newTestServer(t, []byte("tampered"), hex.EncodeToString(make([]byte, sha256.Size)))
+
+	release, err := u.Latest(context.Background(), ChannelStable)
+	require.NoError(t, err)
+	_, err = u.Download(context.Background(), release)
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestAssetName(t *testing.T) {
+	assert.Equal(t, "sortd-linux-amd64", AssetName("linux", "amd64"))
+	assert.Equal(t, "sortd-macos-arm64", AssetName("darwin", "arm64"))
+	assert.Equal(t, "sortd-windows-amd64.exe", AssetName("windows", "amd64"))
+}
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "sortd")
+	require.NoError(t, os.WriteFile(exe, []byte("old"), 0750))
+	link := filepath.Join(dir, "sortd-link")
+	require.NoError(t, os.Symlink(exe, link))
+
+	require.NoError(t, Replace(link, []byte("new")))
+
+	content, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content), "The link's target should be replaced")
+	info, err := os.Stat(exe)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+	linkInfo, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.True(t, linkInfo.Mode()&os.ModeSymlink != 0)
+}
+
+func TestReplaceRestoresOldBinary(t *testing.T) {
+	defer func() { rename, writeFile = os.Rename, atomicfile.WriteFile }()
+	writeFile = func(string, []byte, os.FileMode) error {
+		return errors.New("disk full")
+	}
+
+	t.Run("moved back", func(t *testing.T) {
+		exe := filepath.Join(t.TempDir(), "sortd.exe")
+		require.NoError(t, os.WriteFile(exe, []byte("old"), 0755))
+
+		err := replace(exe, []byte("new"), 0755, true)
+		assert.ErrorContains(t, err, "disk full")
+		content, err := os.ReadFile(exe)
+		require.NoError(t, err, "The old binary should be back in place")
+		assert.Equal(t, "old", string(content))
+	})
+
+	t.Run("moving back fails too", func(t *testing.T) {
+		exe := filepath.Join(t.TempDir(), "sortd.exe")
+		require.NoError(t, os.WriteFile(exe, []byte("old"), 0755))
+		rename = func(oldpath, newpath string) error {
+			if newpath == exe {
+				return errors.New("access denied")
+			}
+			return os.Rename(oldpath, newpath)
+		}
+
+		err := replace(exe, []byte("new"), 0755, true)
+		assert.ErrorContains(t, err, "disk full")
+		assert.ErrorContains(t, err, "access denied")
+	})
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.2.0", "1.1.9", true},
+		{"1.10.0", "1.9.2", true},
+		{"v1.2.0", "1.2.0", false},
+		{"1.2", "1.2.0", false},
+		{"1.2.0", "1.2.0-beta.1", true},
+		{"1.2.0-beta.2", "1.2.0-beta.1", true},
+		{"1.2.0-beta.1", "1.1.0", true},
+		{"0.0.1", "dev", true},
+		{"dev", "0.0.1", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Newer(tt.a, tt.b), "%s newer than %s", tt.a, tt.b)
+	}
+}
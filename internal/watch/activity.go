@@ -0,0 +1,131 @@
+package watch
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+)
+
+// activityFile is the JSON-lines log of files the daemon has handled
+const activityFile = "activity.jsonl"
+
+// Sources recorded in activity entries
+const (
+	activitySourceRules      = "rules"
+	activitySourceWorkflow   = "workflow"
+	activitySourceUnmatched  = "unmatched"  // Moved to the unsorted folder by settings.unmatched.policy
+	activitySourceScreenshot = "screenshot" // Renamed and filed by month by settings.screenshots
+)
+
+// SetActivityFile sets a file the daemon appends an entry to for every file it
+// organizes or fails to organize. An empty path disables the log.
+func (d *Daemon) SetActivityFile(path string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.activityPath = path
+}
+
+// recordActivity appends an entry to the activity log, if one is configured.
+// Moves to a known destination carry the operation ID of moving the file
+// there under rule, matching the ID a plan or the pending queue gives them.
+func (d *Daemon) recordActivity(path, destination, source, rule string, err error) {
+	entry := types.ActivityEntry{
+		Time:        time.Now(),
+		Path:        path,
+		Destination: destination,
+		Source:      source,
+		Trace:       d.traceOf(path),
+	}
+	if destination != "" {
+		entry.Operation = types.OperationID(path, filepath.Join(destination, filepath.Base(path)), rule)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	d.writeActivity(entry)
+}
+
+// recordWorkflowResult records a workflow run the daemon didn't start for an
+// event, such as a scheduled or webhook run, under the run's own trace ID
+func (d *Daemon) recordWorkflowResult(result types.WorkflowResult) {
+	entry := types.ActivityEntry{
+		Time:   time.Now(),
+		Path:   result.FilePath,
+		Source: activitySourceWorkflow,
+		Trace:  result.Trace,
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+	}
+	d.writeActivity(entry)
+}
+
+// writeActivity appends entry to the activity log, if one is configured
+func (d *Daemon) writeActivity(entry types.ActivityEntry) {
+	d.mutex.RLock()
+	activityPath := d.activityPath
+	d.mutex.RUnlock()
+
+	if activityPath == "" {
+		return
+	}
+
+	d.activityWriteMu.Lock()
+	defer d.activityWriteMu.Unlock()
+
+	unlock, lockErr := lockJournal(activityPath)
+	if lockErr != nil {
+		log.Warnf("Failed to lock activity log %s: %v", activityPath, lockErr)
+		return
+	}
+	defer unlock()
+
+	if writeErr := appendActivity(activityPath, entry); writeErr != nil {
+		log.Warnf("Failed to write activity log %s: %v", activityPath, writeErr)
+	}
+}
+
+// ActivityFilePath returns the path of the daemon's activity log, in the state
+// directory. The inbox and digest state kept next to it move there with it.
+func ActivityFilePath(cfg *config.Config) string {
+	cfg.StateFile(inboxStateFile)
+	cfg.StateFile(digestStateFile)
+	return cfg.StateFile(activityFile)
+}
+
+// LoadActivity reads the entries the daemon has appended to its activity log
+// since the given time
+func LoadActivity(cfg *config.Config, since time.Time) ([]types.ActivityEntry, error) {
+	return readActivityFile(ActivityFilePath(cfg), since)
+}
+
+// readActivityFile reads activity entries recorded at or after since
+func readActivityFile(path string, since time.Time) ([]types.ActivityEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []types.ActivityEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry types.ActivityEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// Skip a torn final line rather than losing the whole log
+			continue
+		}
+		if entry.Time.Before(since) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,26 @@
+package watch
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// setupAudit keeps the workflows in dry run mode when settings.audit is on,
+// so that nothing the daemon drives can change a file
+func (d *Daemon) setupAudit() {
+	if d.config.Settings.Audit && d.workflowManager != nil {
+		d.workflowManager.SetAudit(true)
+	}
+}
+
+// auditFile reports what the daemon would do with a file in audit mode,
+// leaving it where it is
+func (d *Daemon) auditFile(filePath string) {
+	destDir, ok := d.engine.DestinationDir(filePath)
+	if !ok {
+		log.Infof("Audit: no rule matches %s", filePath)
+		d.recordStat(filePath, statUnmatched)
+		return
+	}
+	log.Infof("Audit: would move %s to %s", filePath, destDir)
+	d.recordStat(filePath, statSkipped)
+}
@@ -0,0 +1,119 @@
+package watch
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/analysis"
+	"sortd/internal/classify"
+	"sortd/internal/config"
+	"sortd/pkg/workflow"
+)
+
+// classificationStore opens the classification store and evaluator on first
+// use. Without classifications, or in configs without a default directory
+// like bare ones built in code, it returns nil.
+func (d *Daemon) classificationStore() (*classify.Store, *classify.Evaluator, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.config.Directories.Default == "" || len(d.config.Classifications) == 0 {
+		return nil, nil, nil
+	}
+	if d.classifier == nil {
+		evaluator, err := classify.New(d.config.Classifications)
+		if err != nil {
+			return nil, nil, err
+		}
+		d.classifier = evaluator
+	}
+	if d.classifications == nil {
+		store, err := classify.OpenStore(d.config.DataFile(classify.StoreFile))
+		if err != nil {
+			return nil, nil, err
+		}
+		d.classifications = store
+	}
+	return d.classifications, d.classifier, nil
+}
+
+// WorkflowMetadata returns what workflows see as a file's metadata: what the
+// analyzers extract, plus the best classification the file meets as
+// "classification", for {classification} in workflow targets
+func WorkflowMetadata(cfg *config.Config) workflow.MetadataFunc {
+	analyzer := analysis.NewWithConfig(cfg)
+	evaluator, err := classify.New(cfg.Classifications)
+	if err != nil {
+		log.Warnf("Classifications unavailable to workflows: %v", err)
+		evaluator = nil
+	}
+
+	return func(path string) (map[string]string, error) {
+		info, err := analyzer.Analyze(path)
+		if err != nil {
+			return nil, err
+		}
+		metadata := info.Metadata
+		if evaluator == nil {
+			return metadata, nil
+		}
+		if result, matched, err := evaluator.Classify(path); err == nil && matched {
+			if metadata == nil {
+				metadata = make(map[string]string)
+			}
+			metadata["classification"] = result.Classification
+		}
+		return metadata, nil
+	}
+}
+
+// recordClassification classifies a file sortd just organized and stores the
+// result, so it can be re-evaluated when the classifications change
+func (d *Daemon) recordClassification(path string) {
+	store, evaluator, err := d.classificationStore()
+	if err != nil {
+		log.Warnf("Failed to open classification store: %v", err)
+		return
+	}
+	if store == nil {
+		return
+	}
+	if _, err := classify.Reindex(context.Background(), evaluator, store, []string{path}); err != nil {
+		log.Warnf("Failed to classify %s: %v", path, err)
+	}
+}
+
+// reindexIfClassificationsChanged re-evaluates every stored file when the
+// classifications differ from the ones the stored matches were computed with
+func (d *Daemon) reindexIfClassificationsChanged() {
+	store, evaluator, err := d.classificationStore()
+	if err != nil {
+		log.Warnf("Failed to open classification store: %v", err)
+		return
+	}
+	if store == nil {
+		return
+	}
+
+	fingerprint, err := store.Fingerprint()
+	if err != nil || fingerprint == evaluator.Fingerprint() {
+		return
+	}
+	paths, err := store.Paths()
+	if err != nil {
+		log.Warnf("Failed to list classified files: %v", err)
+		return
+	}
+
+	log.Infof("Classifications changed; re-evaluating %d files", len(paths))
+	result, err := classify.Reindex(context.Background(), evaluator, store, paths)
+	if err != nil {
+		log.Warnf("Reclassification stopped: %v", err)
+		return
+	}
+	for _, change := range result.Changed {
+		log.Infof("Reclassified %s: %q -> %q", change.Path, change.From, change.To)
+	}
+	log.Infof("Reclassification done: %d changed, %d unchanged, %d removed", len(result.Changed), result.Unchanged, result.Removed)
+}
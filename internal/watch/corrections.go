@@ -0,0 +1,79 @@
+package watch
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/learning"
+)
+
+// learningStore opens the learning store on first use. Configs without a
+// default directory, like bare ones built in code, leave learning disabled
+// and the store nil.
+func (d *Daemon) learningStore() (*learning.Store, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.config.Directories.Default == "" {
+		return nil, nil
+	}
+	if d.learning == nil {
+		store, err := learning.Open(learning.DefaultPath(d.config))
+		if err != nil {
+			return nil, err
+		}
+		d.learning = store
+	}
+	return d.learning, nil
+}
+
+// recordPlacement remembers where a rule put a file so that the user moving it
+// somewhere else can be recognised later. info is the file's stat from before the move.
+func (d *Daemon) recordPlacement(rule, destPath string, info os.FileInfo) {
+	store, err := d.learningStore()
+	if err != nil {
+		log.Warnf("Failed to open learning data: %v", err)
+		return
+	}
+	if store == nil {
+		return
+	}
+	if err := store.RecordPlacement(rule, destPath, info); err != nil {
+		log.Warnf("Failed to record placement of %s: %v", destPath, err)
+	}
+}
+
+// detectCorrection reports whether the file is one sortd organized that the
+// user has since moved here. The correction lowers the confidence of the rule
+// responsible, and the file is left where the user put it.
+func (d *Daemon) detectCorrection(filePath string) bool {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false
+	}
+
+	store, err := d.learningStore()
+	if err != nil {
+		log.Warnf("Failed to open learning data: %v", err)
+		return false
+	}
+	if store == nil {
+		return false
+	}
+
+	correction, found, err := store.DetectCorrection(filePath, info)
+	if err != nil {
+		log.Warnf("Failed to record correction for %s: %v", filePath, err)
+	}
+	if !found {
+		return false
+	}
+
+	log.Infof("%s was moved from %s by the user; rule %s confidence is now %.0f%%",
+		filePath, correction.From, correction.Rule, store.Confidence(correction.Rule)*100)
+	if suggestion, ok := store.SuggestionFor(correction.Rule); ok {
+		log.Warnf("Suggestion: %s (sortd rules suggest --apply)", suggestion)
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package watch
+
+import (
+	"fmt"
+
+	"sortd/internal/crash"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// guard runs a background task of the daemon, turning a panic into a
+// diagnostic bundle and an error in the log instead of taking down the daemon
+func (d *Daemon) guard(task string, run func()) {
+	defer d.recoverCrash(task, "")
+	run()
+}
+
+// handleFileGuarded handles a file for a worker. A panic over one file is
+// reported, and the file recorded as failed, while the worker goes on with
+// the next.
+func (d *Daemon) handleFileGuarded(filePath string) {
+	defer d.recoverCrash("handling a file", filePath)
+	d.handleFile(filePath)
+}
+
+// recoverCrash writes a diagnostic bundle for a panic in task, if there is
+// one. It must be deferred. path is the file being handled, if any.
+func (d *Daemon) recoverCrash(task, path string) {
+	value := recover()
+	if value == nil {
+		return
+	}
+
+	bundle, err := crash.Capture("watch daemon: "+task, value, d.config)
+	log.Error(crash.Instructions(value, bundle, err))
+	if path != "" {
+		d.recordStat(path, statError)
+		d.recordFailure(path, activitySourceRules, fmt.Errorf("sortd crashed: %v", value), 0)
+	}
+}
@@ -1,17 +1,31 @@
 package watch
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	log "github.com/sirupsen/logrus"
 
+	"sortd/internal/analysis"
+	"sortd/internal/classify"
 	"sortd/internal/config"
+	"sortd/internal/errors"
+	"sortd/internal/failures"
+	"sortd/internal/learning"
 	"sortd/internal/organize"
+	"sortd/internal/pending"
+	"sortd/internal/power"
+	"sortd/internal/priority"
+	"sortd/internal/trace"
+	"sortd/pkg/types"
 	"sortd/pkg/workflow"
 )
 
@@ -21,6 +35,10 @@ type DaemonStatus struct {
 	WatchDirectories []string
 	LastActivity     time.Time
 	FilesProcessed   int
+	Directories      []types.DirectoryStats
+	Watchers         []WatcherStatus // Per-directory watcher state from the supervisor
+	ReadOnly         bool            // Whether files are left alone for now
+	Paused           string          // Why work is held back for the battery or the network, or ""
 }
 
 // Daemon manages a background file organization service
@@ -41,6 +59,19 @@ type Daemon struct {
 	processed    int
 	lastActivity time.Time
 
+	// Per-directory statistics, keyed by cleaned watch directory path
+	dirStats map[string]*types.DirectoryStats
+
+	// Optional file the statistics are persisted to, a lock serialising writes
+	// to it, and the pending write batching counter updates
+	statsPath    string
+	statsWriteMu sync.Mutex
+	statsTimer   *time.Timer
+
+	// Optional activity log of handled files, and a lock serialising appends to it
+	activityPath    string
+	activityWriteMu sync.Mutex
+
 	// Callback for when a file is processed
 	callback func(string, string, error)
 
@@ -53,10 +84,82 @@ type Daemon struct {
 	// Whether the daemon is running
 	running bool
 
-	// Event processing channel and workers
-	eventChan  chan string
-	workerWg   sync.WaitGroup
-	numWorkers int
+	// Whether this daemon was paused with SetReadOnly
+	readOnly atomic.Bool
+
+	// Event processing channel and workers, and the channel telling the
+	// workers to finish what is queued and exit
+	eventChan   chan string
+	workerWg    sync.WaitGroup
+	numWorkers  int
+	workersStop chan struct{}
+
+	// Cancelled on Stop, so workflows stop waiting to retry their actions
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+
+	// Files waiting for their stability window, and the goroutines doing the waiting
+	settling map[string]bool
+	settleWg sync.WaitGroup
+	stopCh   chan struct{}
+
+	// Files currently being handled by a worker
+	inFlight map[string]bool
+
+	// Trace IDs of files between their event and the end of their handling
+	traces map[string]string
+
+	// What the machine last ran on, and the files held back until that changes
+	power    power.State
+	heldBack map[string]bool
+
+	// Optional HTTP endpoint external systems use to trigger workflows
+	webhookServer *http.Server
+	webhookAddr   string
+
+	// Optional health endpoint for container orchestrators
+	healthServer *http.Server
+	healthAddr   string
+
+	// Optional read-only web UI
+	webServer *http.Server
+	webAddr   string
+
+	// Directories rescanned by the poll backend, and the goroutines doing it
+	// and the periodic rescans of directories watched through change events
+	pollDirs []string
+	pollWg   sync.WaitGroup
+
+	// Files seen by change events or the last rescan, so rescans only pick up
+	// what events missed
+	rescanSeen map[string]polledFile
+	rescanMu   sync.Mutex
+
+	// Watch directories under supervision, and the goroutine re-adding lost ones
+	watchers    map[string]*supervisedDir
+	superviseWg sync.WaitGroup
+
+	// Warns once that open files can't be detected on this system
+	openCheckWarning sync.Once
+
+	// Warns once that the workers' priority can't be lowered
+	priorityWarning sync.Once
+
+	// Files in landing zones already alerted about as overdue
+	overdueAlerted map[string]bool
+
+	// Queue moves are staged in during the training period, opened on first use
+	pending *pending.Queue
+
+	// Dead-letter queue of files that failed after retries, opened on first use
+	failures *failures.Queue
+
+	// Where rules placed files and how users responded, opened on first use
+	learning *learning.Store
+
+	// Classification matches of organized files, opened on first use
+	classifier      *classify.Evaluator
+	classifications *classify.Store
 }
 
 // NewDaemon creates a new background file organization service
@@ -71,15 +174,12 @@ func NewDaemon(cfg *config.Config) (*Daemon, error) {
 	// Create the organization engine using the correct constructor
 	engine := organize.NewWithConfig(cfg)
 
-	// Initialize the workflow manager
-	home, err := os.UserHomeDir()
+	// Initialize the workflow manager from the configured workflows directory
+	workflowsDir, err := workflow.DefaultDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		return nil, err
 	}
 
-	// Use a fixed path under the .config/sortd directory for workflows
-	workflowsDir := filepath.Join(home, ".config", "sortd", "workflows")
-
 	// Create workflows directory if it doesn't exist
 	if err := os.MkdirAll(workflowsDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create workflows directory: %w", err)
@@ -93,19 +193,60 @@ func NewDaemon(cfg *config.Config) (*Daemon, error) {
 		workflowManager = nil
 	}
 
-	return &Daemon{
+	// Let file_tag conditions see image content tags
+	if workflowManager != nil && cfg.Settings.ImageTagging.Enabled {
+		if tagger, err := analysis.NewImageTagger(cfg.Settings.ImageTagging); err != nil {
+			log.Warnf("Image tagging disabled: %v", err)
+		} else {
+			workflowManager.SetTagger(tagger.TagImage)
+		}
+	}
+
+	// Workflow moves and copies resolve taken targets like the rules do
+	if workflowManager != nil {
+		workflowManager.SetDuplicates(cfg.Settings.Duplicates)
+		workflowManager.SetRetry(cfg.Settings.Retry)
+		workflowManager.SetManifests(engine.Manifests())
+		workflowManager.SetProfiles(engine.Profiles())
+		workflowManager.SetRemoteBandwidth(cfg.Settings.Priority.BandwidthLimit())
+	}
+
+	// Let metadata conditions and {key} targets see what the analyzers extract
+	if workflowManager != nil {
+		workflowManager.SetMetadata(WorkflowMetadata(cfg))
+	}
+
+	d := &Daemon{
 		config:              cfg,
 		watcher:             watcher,
 		engine:              engine,
 		workflowManager:     workflowManager,
 		processed:           0,
 		lastActivity:        time.Now(), // Initialize lastActivity
+		dirStats:            make(map[string]*types.DirectoryStats),
 		callback:            nil,
 		requireConfirmation: false,
 		running:             false,
 		eventChan:           make(chan string, 100), // Buffer for 100 events
-		numWorkers:          4,                      // Default to 4 workers
-	}, nil // Return nil error on success
+		numWorkers:          cfg.WatchMode.Workers(),
+		settling:            make(map[string]bool),
+		inFlight:            make(map[string]bool),
+		watchers:            make(map[string]*supervisedDir),
+		stopCh:              make(chan struct{}),
+		workersStop:         make(chan struct{}),
+	}
+	d.runCtx, d.cancelRuns = context.WithCancel(context.Background())
+
+	// Collisions under the "ask" strategy wait in the pending queue
+	d.engine.SetCollisionResolver(d.askCollision)
+
+	// Destinations over their quota are reported, or relieved by a workflow
+	d.setupQuotas()
+
+	// Audit mode only reports what workflows would do
+	d.setupAudit()
+
+	return d, nil
 }
 
 // Start initiates the daemon process
@@ -114,11 +255,22 @@ func (d *Daemon) Start() error {
 		return fmt.Errorf("daemon is already running")
 	}
 
-	// Add the watch directories from config
-	// Use config.WatchDirectories instead of config.Directories.Watch
-	if len(d.config.WatchDirectories) > 0 {
-		for _, dir := range d.config.WatchDirectories {
-			if err := d.watcher.Add(dir); err != nil {
+	// Files sent back into a watched directory would keep moving
+	if err := d.checkFeedbackLoops(); err != nil {
+		return err
+	}
+
+	// Add the watch directories from config, both directories.watch entries
+	// and the plain watch_directories list
+	if dirs := d.config.WatchPaths(); len(dirs) > 0 {
+		for _, dir := range dirs {
+			if profile := d.directoryProfile(dir); !d.engine.Profiles().Active(profile) {
+				d.trackDirectory(dir)
+				d.deactivateDirectory(dir, time.Now())
+				log.Infof("Not watching %s while profile %s isn't active", dir, profile)
+				continue
+			}
+			if err := d.addDirectory(dir); err != nil {
 				// Use the config path for context in the error message?
 				// Format error for logging *without* %w for custom logger (and logrus)
 				log.Errorf("Error adding watch directory %s: %v", dir, err)
@@ -126,26 +278,98 @@ func (d *Daemon) Start() error {
 				// Use fmt.Errorf with %w here for proper error wrapping in the return value
 				return fmt.Errorf("error adding watch directory %s: %w", dir, err)
 			}
+			d.trackDirectory(dir)
+			d.superviseDirectory(dir)
 			log.Infof("Watching directory: %s", dir)
 		}
 	} else {
 		log.Info("No watch directories specified in configuration.")
 	}
 
-	// Make sure we have directories to watch
-	// Use WatchList() for fsnotify
-	if len(d.watcher.WatchList()) == 0 {
+	// Make sure we have directories to watch, now or once their profile is active
+	if len(d.watchList()) == 0 && !d.hasInactiveDirectories() {
 		return fmt.Errorf("no valid directories to watch")
 	}
 
+	// Clear out half-written files from a previous run that was cut short
+	d.engine.CleanStaging(append(d.watchList(), d.config.Directories.Default)...)
+
+	// Let external systems trigger workflows if configured
+	if err := d.startWebhookServer(); err != nil {
+		log.Errorf("Error starting webhook server: %v", err)
+		return fmt.Errorf("error starting webhook server: %w", err)
+	}
+
+	// Answer health checks if configured
+	if err := d.startHealthServer(); err != nil {
+		d.stopWebhookServer()
+		log.Errorf("Error starting health server: %v", err)
+		return fmt.Errorf("error starting health server: %w", err)
+	}
+
+	// Show status and activity in a browser if configured
+	if err := d.startWebServer(); err != nil {
+		d.stopWebhookServer()
+		d.stopHealthServer()
+		log.Errorf("Error starting web UI: %v", err)
+		return fmt.Errorf("error starting web UI: %w", err)
+	}
+
+	// Hold back work on battery or a metered connection if configured
+	if d.watchesPower() {
+		d.updatePower(currentPower())
+		go d.guard("checking power", d.runPowerChecks)
+	}
+
 	// Start worker pool for file processing
 	for i := 0; i < d.numWorkers; i++ {
 		d.workerWg.Add(1)
 		go d.fileProcessWorker()
 	}
 
-	// Start processing file events from the single watcher
-	go d.processEvents()
+	// Start processing file events from the single watcher, or rescanning
+	go d.guard("processing events", d.processEvents)
+	if d.usePolling() || len(d.pollList()) > 0 || d.pollsInactiveDirectory() {
+		d.startPolling()
+		log.Infof("Polling watch directories every %s", d.pollInterval())
+	}
+
+	// Catch files change events missed, e.g. when the event queue was full
+	if interval := d.rescanInterval(); interval > 0 {
+		d.startRescans()
+		log.Infof("Rescanning watch directories every %s", interval)
+	}
+
+	// Re-add watchers that die when their directory is deleted or unmounted
+	d.startSupervisor()
+
+	// Catch up with edited classifications in the background
+	go d.guard("reindexing classifications", d.reindexIfClassificationsChanged)
+
+	// Send periodic activity digests if configured
+	if d.config.Settings.Digest.Enabled {
+		go d.guard("sending digests", d.runDigests)
+	}
+
+	// Nudge about files left in the inboxes if configured
+	if d.config.Settings.Inbox.Enabled {
+		go d.guard("sending inbox reports", d.runInboxReports)
+	}
+
+	// Alert about files that stay in landing zones past their deadline
+	if len(d.config.WatchMode.LandingZones) > 0 {
+		go d.guard("checking landing zones", d.runLandingZones)
+	}
+
+	// Run workflows with scheduled triggers, e.g. catch-up sweeps
+	if d.workflowManager != nil && d.workflowManager.HasScheduledTriggers() {
+		go d.guard("running scheduled workflows", d.runScheduledWorkflows)
+	}
+
+	// Keep the activity log within its configured retention
+	if journal := d.config.Settings.Journal; journal.KeepDays > 0 || journal.KeepEntries > 0 {
+		go d.guard("compacting the activity log", d.runJournalCompaction)
+	}
 
 	d.running = true
 	log.Info("Watch daemon started.")
@@ -164,12 +388,27 @@ func (d *Daemon) Stop() {
 		log.Errorf("Error closing watcher: %v", err)
 	}
 
-	// Close the event channel to signal workers to stop
-	close(d.eventChan)
-
-	// Wait for all workers to finish
+	// Stop accepting webhook triggers and health checks, and serving the web UI
+	d.stopWebhookServer()
+	d.stopHealthServer()
+	d.stopWebServer()
+
+	// Abandon files still waiting to settle, and stop polling and supervising, before the workers stop
+	close(d.stopCh)
+	d.settleWg.Wait()
+	d.pollWg.Wait()
+	d.superviseWg.Wait()
+
+	// Let the workers finish what is queued and exit. The event channel stays
+	// open: the event loop may still be handing over a file, and sending on a
+	// closed channel panics.
+	d.cancelRuns()
+	close(d.workersStop)
 	d.workerWg.Wait()
 
+	// Write the counters of the last events
+	d.flushStats()
+
 	d.running = false
 	log.Info("Watch daemon stopped.")
 }
@@ -178,34 +417,132 @@ func (d *Daemon) Stop() {
 func (d *Daemon) fileProcessWorker() {
 	defer d.workerWg.Done()
 
-	for filePath := range d.eventChan {
-		// First try workflow processing
-		var workflowHandled bool = false
-		if d.workflowManager != nil {
-			// Create a minimal event to pass to the workflow manager
-			event := fsnotify.Event{
-				Name: filePath,
-				Op:   fsnotify.Create, // Treat as a create event
+	// Workers run at the configured lower priority. The thread stays locked,
+	// and ends with the worker, so no other goroutine inherits it.
+	if settings := d.config.Settings.Priority; priority.Lowers(settings) {
+		runtime.LockOSThread()
+		if err := priority.LowerThread(settings); err != nil {
+			d.priorityWarning.Do(func() {
+				log.Warnf("Workers run at normal priority: %v", err)
+			})
+		}
+	}
+
+	for {
+		select {
+		case filePath := <-d.eventChan:
+			d.processQueued(filePath)
+		case <-d.workersStop:
+			// Finish the files queued before the stop
+			for {
+				select {
+				case filePath := <-d.eventChan:
+					d.processQueued(filePath)
+				default:
+					return
+				}
 			}
+		}
+	}
+}
+
+// processQueued handles a file taken from the event channel
+func (d *Daemon) processQueued(filePath string) {
+	// A single save often produces several events; handle each file once at a time
+	if !d.claimFile(filePath) {
+		log.Debugf("File already being processed, skipping duplicate event: %s", filePath)
+		return
+	}
+	d.beginTrace(filePath)
+	d.handleFileGuarded(filePath)
+	d.endTrace(filePath)
+	d.releaseFile(filePath)
+}
+
+// claimFile marks a file as being processed, returning false if a worker already has it
+func (d *Daemon) claimFile(filePath string) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.inFlight[filePath] {
+		return false
+	}
+	d.inFlight[filePath] = true
+	return true
+}
+
+// releaseFile marks a file as no longer being processed
+func (d *Daemon) releaseFile(filePath string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.inFlight, filePath)
+}
+
+// handleFile runs a file through the workflows, falling back to config patterns
+func (d *Daemon) handleFile(filePath string) {
+	// Events queued before an earlier one moved the file are stale
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		log.Debugf("File no longer exists, skipping stale event: %s", filePath)
+		return
+	}
+
+	// Nothing is moved while paused; the file is picked up again when it changes
+	if d.ReadOnly() {
+		if d.config.Settings.Audit {
+			d.auditFile(filePath)
+			return
+		}
+		d.logFor(filePath).Infof("Read-only mode: leaving %s in place", filePath)
+		d.recordStat(filePath, statSkipped)
+		return
+	}
+
+	// On battery, files wait until the machine is back on AC power
+	if reason := d.batteryPause(); reason != "" {
+		d.holdBack(filePath, reason)
+		return
+	}
+
+	// A file the user moved out of where sortd put it stays where they put it
+	if d.detectCorrection(filePath) {
+		d.recordStat(filePath, statSkipped)
+		return
+	}
 
-			processed, wfErr := d.workflowManager.ProcessEvent(event)
+	// First try workflow processing
+	var workflowHandled bool = false
+	if d.workflowManager != nil {
+		// Create a minimal event to pass to the workflow manager
+		event := fsnotify.Event{
+			Name: filePath,
+			Op:   fsnotify.Create, // Treat as a create event
+		}
+
+		ctx := trace.With(d.runCtx, d.traceOf(filePath))
+		processed, wfErr := d.workflowManager.ProcessEventContext(ctx, event)
+		if wfErr != nil {
+			d.logFor(filePath).Errorf("Error processing event with workflow manager for %s: %v", filePath, wfErr)
+			// Decide if error means we should still try patterns. For now, assume yes.
+		}
+		if processed {
+			d.logFor(filePath).Debugf("Event for %s was handled by a workflow.", filePath)
 			if wfErr != nil {
-				log.Errorf("Error processing event with workflow manager for %s: %v", filePath, wfErr)
-				// Decide if error means we should still try patterns. For now, assume yes.
-			}
-			if processed {
-				log.Debugf("Event for %s was handled by a workflow.", filePath)
-				workflowHandled = true
-				// Explicitly skip pattern processing if workflow handled it
-				continue
+				d.recordStat(filePath, statError)
+				d.recordFailure(filePath, activitySourceWorkflow, wfErr, 0)
+			} else {
+				d.recordStat(filePath, statOrganized)
 			}
+			d.recordActivity(filePath, "", activitySourceWorkflow, "", wfErr)
+			workflowHandled = true
+			// Explicitly skip pattern processing if workflow handled it
+			return
 		}
+	}
 
-		// If no workflow handled it, try config patterns
-		if !workflowHandled {
-			log.Debugf("Event for %s not handled by workflow, trying config patterns.", filePath)
-			d.organizeFile(filePath)
-		}
+	// If no workflow handled it, try config patterns
+	if !workflowHandled {
+		log.Debugf("Event for %s not handled by workflow, trying config patterns.", filePath)
+		d.organizeFile(filePath)
 	}
 }
 
@@ -226,30 +563,7 @@ func (d *Daemon) processEvents() {
 			// Note: RENAMED files trigger REMOVE on old name, CREATE on new name.
 			// WRITE might occur multiple times for one save operation.
 			if event.Op&fsnotify.Create == fsnotify.Create || event.Op&fsnotify.Write == fsnotify.Write {
-				// Check if it's a file (fsnotify doesn't guarantee IsDir reliably)
-				info, err := os.Stat(event.Name)
-				if err != nil {
-					// File might have been removed quickly after event, log and skip
-					log.Debugf("Failed to stat file from event %s: %v", event.Name, err)
-					continue
-				}
-				if info.IsDir() {
-					log.Debugf("Skipping directory event: %s", event.Name)
-					continue // Skip directories
-				}
-
-				// Update last activity time
-				d.mutex.Lock()
-				d.lastActivity = time.Now()
-				d.mutex.Unlock()
-
-				// Send file to worker pool for processing
-				select {
-				case d.eventChan <- event.Name:
-					log.Debugf("Queued event for processing: %s", event.Name)
-				default:
-					log.Warnf("Event channel full, dropping event for: %s", event.Name)
-				}
+				d.handleEvent(event.Name)
 			}
 
 		case err, ok := <-d.watcher.Errors:
@@ -262,14 +576,70 @@ func (d *Daemon) processEvents() {
 	}
 }
 
+// handleEvent passes a created or written file through the filters and
+// stability windows to the workers. Both watch backends report changes here.
+func (d *Daemon) handleEvent(path string) {
+	// sortd's own state files, and hidden files when they are ignored, never
+	// reach the rules; organizing or even counting them would feed back into
+	// more events
+	if d.ignoredName(path) {
+		return
+	}
+
+	// Check if it's a file (fsnotify doesn't guarantee IsDir reliably)
+	info, err := os.Stat(path)
+	if err != nil {
+		// File might have been removed quickly after event, log and skip
+		log.Debugf("Failed to stat file from event %s: %v", path, err)
+		return
+	}
+	if info.IsDir() {
+		// New subdirectories of recursive watch directories are watched too
+		d.watchNewDirectory(path)
+		log.Debugf("Skipping directory event: %s", path)
+		return // Skip directories
+	}
+	d.noteSeen(path, info)
+
+	// Drop events excluded by the watch filters before they reach rules or workflows
+	if !d.allowEvent(path) {
+		log.Debugf("Event filtered out by watch filters: %s", path)
+		return
+	}
+
+	// Temporary files are renamed once complete; react to the final name instead
+	if d.isTempFile(path) {
+		log.Debugf("Skipping temporary file: %s", path)
+		return
+	}
+
+	// Update last activity time
+	d.mutex.Lock()
+	d.lastActivity = time.Now()
+	d.mutex.Unlock()
+	d.recordStat(path, statEventSeen)
+
+	// Files covered by a stability window wait until they are fully written
+	if window := d.stabilityWindowFor(path); window != nil {
+		d.queueWhenStable(path, *window)
+		return
+	}
+
+	// Send file to worker pool for processing
+	d.queueEvent(path)
+}
+
 // AddWatchDirectory adds a directory to be watched
 func (d *Daemon) AddWatchDirectory(dir string) error {
-	err := d.watcher.Add(dir)
+	err := d.addDirectory(dir)
 	if err != nil {
 		log.Errorf("Error adding watch directory dynamically %s: %v", dir, err)
 		return err
 	}
 
+	d.trackDirectory(dir)
+	d.superviseDirectory(dir)
+	d.engine.SetRoots(d.watchList())
 	log.Infof("Dynamically added watch directory: %s", dir)
 
 	return nil
@@ -304,11 +674,14 @@ func (d *Daemon) Status() DaemonStatus {
 	defer d.mutex.RUnlock()
 
 	return DaemonStatus{
-		Running: d.running,
-		// Use WatchList() for fsnotify
-		WatchDirectories: d.watcher.WatchList(),
+		Running:          d.running,
+		WatchDirectories: d.watchListLocked(),
 		LastActivity:     d.lastActivity,
 		FilesProcessed:   d.processed,
+		Directories:      d.directoryStatsLocked(),
+		Watchers:         d.watcherStatusesLocked(),
+		ReadOnly:         d.ReadOnly(),
+		Paused:           PauseReason(d.config, d.power),
 	}
 }
 
@@ -316,13 +689,65 @@ func (d *Daemon) Status() DaemonStatus {
 func (d *Daemon) organizeFile(filePath string) {
 	log.Debugf("Attempting to organize file via config patterns: %s", filePath)
 
+	// Screenshots are renamed and filed by month ahead of any pattern
+	if d.organizeScreenshot(filePath) {
+		return
+	}
+
+	// Files no pattern applies to are dealt with by the unmatched policy
+	if !d.engine.HasMatchingPattern(filePath) {
+		d.handleUnmatched(filePath)
+		return
+	}
+
+	destDir, _ := d.engine.DestinationDir(filePath)
+
+	// Moves over the network wait for an unmetered connection
+	if d.meteredPause(destDir) {
+		d.holdBack(filePath, pauseMetered)
+		return
+	}
+
+	// Moves wait in the pending queue during the training period, and while the
+	// responsible pattern hasn't earned enough confidence to act on its own
+	pattern, _ := d.engine.MatchingPattern(filePath)
+	if reason := d.stagingReason(pattern.Match); reason != "" {
+		if err := d.stageFile(filePath, destDir, pattern.Match, reason); err != nil {
+			log.Errorf("Error staging file %s: %v", filePath, err)
+			d.recordStat(filePath, statError)
+			return
+		}
+		d.recordStat(filePath, statSkipped)
+		return
+	}
+
 	// Use OrganizeByPatterns which returns only an error
-	err := d.engine.OrganizeByPatterns([]string{filePath})
+	info, statErr := os.Stat(filePath)
+	ctx := trace.With(context.Background(), d.traceOf(filePath))
+	attempts, err := d.retryTransient(filePath, func() error {
+		return d.engine.OrganizeByPatternsContext(ctx, []string{filePath})
+	})
+
+	// A collision that was skipped or queued for the user leaves the file in place
+	if _, stillThere := os.Stat(filePath); err == nil && stillThere == nil && !d.engine.IsDryRun() {
+		d.logFor(filePath).Infof("Left %s in place: its destination in %s is taken", filePath, destDir)
+		d.recordStat(filePath, statSkipped)
+		return
+	}
+	d.recordActivity(filePath, destDir, activitySourceRules, pattern.Match, err)
+	if err == nil && statErr == nil && !d.engine.IsDryRun() {
+		d.recordPlacement(pattern.Match, filepath.Join(destDir, filepath.Base(filePath)), info)
+		d.recordClassification(filepath.Join(destDir, filepath.Base(filePath)))
+	}
 	log.Debugf("Result from engine.OrganizeByPatterns for %s: error=%v", filePath, err)
 
 	// If error occurred during organization (including no pattern match implicitly? Check engine impl if needed)
 	if err != nil {
-		log.Errorf("Error organizing file %s: %v", filePath, err)
+		details := errors.Describe(err)
+		d.logFor(filePath).WithFields(log.Fields{"kind": details.Kind, "suggestion": details.Suggestion}).
+			Errorf("Error organizing file %s: %v", filePath, err)
+		d.recordStat(filePath, statError)
+		d.recordFailure(filePath, activitySourceRules, err, attempts)
 		// Execute callback with the error
 		d.mutex.RLock()
 		cb := d.callback
@@ -340,8 +765,9 @@ func (d *Daemon) organizeFile(filePath string) {
 	d.mutex.Lock()
 	d.processed++
 	d.mutex.Unlock()
+	d.recordStat(filePath, statOrganized)
 
-	log.Infof("Successfully organized file: %s (or skipped by engine rules)", filePath)
+	d.logFor(filePath).Infof("Successfully organized file: %s (or skipped by engine rules)", filePath)
 
 	// If a callback is registered, notify it of success (nil error)
 	// We don't know the exact destination path from OrganizeByPatterns easily.
@@ -358,6 +784,9 @@ func (d *Daemon) organizeFile(filePath string) {
 // OrganizeFile can be called to manually organize a file through the daemon
 func (d *Daemon) OrganizeFile(filePath string) (string, error) {
 	log.Debugf("Manual organize task triggered for: %s", filePath)
+	if d.ReadOnly() {
+		return "", errors.NewFileError("read-only mode is on, not moving", filePath, errors.ReadOnlyMode, nil)
+	}
 
 	// Delegate directly to the engine using OrganizeByPatterns
 	err := d.engine.OrganizeByPatterns([]string{filePath})
@@ -402,18 +831,43 @@ func NewDaemonWithWorkflowPath(cfg *config.Config, workflowPath string) (*Daemon
 		// Continue without workflow manager - don't fail the daemon initialization
 		workflowManager = nil
 	}
+	if workflowManager != nil {
+		workflowManager.SetDuplicates(cfg.Settings.Duplicates)
+		workflowManager.SetRetry(cfg.Settings.Retry)
+		workflowManager.SetManifests(engine.Manifests())
+		workflowManager.SetProfiles(engine.Profiles())
+		workflowManager.SetRemoteBandwidth(cfg.Settings.Priority.BandwidthLimit())
+	}
 
-	return &Daemon{
+	d := &Daemon{
 		config:              cfg,
 		watcher:             watcher,
 		engine:              engine,
 		workflowManager:     workflowManager,
 		processed:           0,
 		lastActivity:        time.Now(),
+		dirStats:            make(map[string]*types.DirectoryStats),
 		callback:            nil,
 		requireConfirmation: false,
 		running:             false,
 		eventChan:           make(chan string, 100), // Buffer for 100 events
-		numWorkers:          4,                      // Default to 4 workers
-	}, nil
+		numWorkers:          cfg.WatchMode.Workers(),
+		settling:            make(map[string]bool),
+		inFlight:            make(map[string]bool),
+		watchers:            make(map[string]*supervisedDir),
+		stopCh:              make(chan struct{}),
+		workersStop:         make(chan struct{}),
+	}
+	d.runCtx, d.cancelRuns = context.WithCancel(context.Background())
+
+	// Collisions under the "ask" strategy wait in the pending queue
+	d.engine.SetCollisionResolver(d.askCollision)
+
+	// Destinations over their quota are reported, or relieved by a workflow
+	d.setupQuotas()
+
+	// Audit mode only reports what workflows would do
+	d.setupAudit()
+
+	return d, nil
 }
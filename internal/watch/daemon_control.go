@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sortd/internal/config"
 	"sortd/internal/log"
+	"sortd/pkg/types"
 	"strconv"
 	"strings"
 	"syscall"
@@ -16,7 +17,8 @@ import (
 )
 
 const (
-	pidFile = ".sortd.pid"
+	pidFile   = "pid"
+	statsFile = "stats.json"
 )
 
 type daemonContext struct {
@@ -38,7 +40,7 @@ type daemonContext struct {
 func DaemonControl(cfg *config.Config, foreground bool) error {
 	// Create context for daemon
 	context := &daemonContext{
-		PidFileName: filepath.Join(cfg.Directories.Default, pidFile),
+		PidFileName: cfg.StateFile(pidFile),
 		PidFilePerm: 0644,
 		LogFileName: filepath.Join(cfg.Directories.Default, "sortd.log"),
 		LogFilePerm: 0640,
@@ -142,7 +144,7 @@ func stopDaemon() {
 
 // IsDaemonRunning checks if the daemon is currently running
 func IsDaemonRunning(cfg *config.Config) bool {
-	pidPath := filepath.Join(cfg.Directories.Default, pidFile)
+	pidPath := cfg.StateFile(pidFile)
 	if _, err := os.Stat(pidPath); os.IsNotExist(err) {
 		return false
 	}
@@ -151,7 +153,7 @@ func IsDaemonRunning(cfg *config.Config) bool {
 
 // StopDaemon stops a running daemon
 func StopDaemon(cfg *config.Config) error {
-	pidPath := filepath.Join(cfg.Directories.Default, pidFile)
+	pidPath := cfg.StateFile(pidFile)
 	if !IsDaemonRunning(cfg) {
 		return fmt.Errorf("daemon is not running")
 	}
@@ -203,7 +205,7 @@ func parsePid(pidStr string) (int, error) {
 
 // Status returns the status of the daemon
 func Status(cfg *config.Config) (config.DaemonStatus, error) {
-	pidPath := filepath.Join(cfg.Directories.Default, pidFile)
+	pidPath := cfg.StateFile(pidFile)
 	if !IsDaemonRunning(cfg) {
 		return config.DaemonStatus{}, fmt.Errorf("daemon is not running")
 	}
@@ -221,18 +223,46 @@ func Status(cfg *config.Config) (config.DaemonStatus, error) {
 		return config.DaemonStatus{}, fmt.Errorf("failed to parse PID: %w", err)
 	}
 
+	// Per-directory counters are published by the daemon through its stats file
+	dirStats, err := LoadStats(cfg)
+	if err != nil && !os.IsNotExist(err) {
+		log.Warnf("Failed to read daemon stats: %v", err)
+	}
+
 	// Get status from daemon process
 	status := config.DaemonStatus{
-		Running: true,
-		// WatchDirectories will need to be communicated from the daemon
-		// This is a limitation of the current implementation
+		Running:          true,
 		WatchDirectories: []string{},
-		// LastActivity and FilesProcessed would need to be
-		// communicated from the daemon process
-		LastActivity:   time.Time{},
-		FilesProcessed: 0,
+		LastActivity:     time.Time{},
+		FilesProcessed:   0,
+		Directories:      dirStats,
+	}
+
+	// Derive the aggregate figures from the published per-directory counters
+	for _, stats := range dirStats {
+		status.WatchDirectories = append(status.WatchDirectories, stats.Path)
+		status.FilesProcessed += stats.FilesOrganized
+		if stats.LastActivity.After(status.LastActivity) {
+			status.LastActivity = stats.LastActivity
+		}
+	}
+
+	// The daemon pauses for the same battery and network this process sees
+	if cfg.WatchMode.PauseOnBattery || cfg.WatchMode.PauseOnMetered {
+		status.Paused = PauseReason(cfg, currentPower())
 	}
 
 	log.Info("Daemon status retrieved successfully")
 	return status, nil
 }
+
+// StatsFilePath returns the path of the file a daemon publishes its
+// per-directory statistics to, in the state directory
+func StatsFilePath(cfg *config.Config) string {
+	return cfg.StateFile(statsFile)
+}
+
+// LoadStats reads the per-directory statistics last published by the daemon
+func LoadStats(cfg *config.Config) ([]types.DirectoryStats, error) {
+	return readStatsFile(StatsFilePath(cfg))
+}
@@ -1,12 +1,20 @@
 package watch_test
 
 import (
+	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"sortd/internal/config"
+	"sortd/internal/failures"
+	"sortd/internal/learning"
+	"sortd/internal/openfiles"
+	"sortd/internal/organize"
+	"sortd/internal/pending"
 	"sortd/internal/watch"
 	"sortd/pkg/types"
 
@@ -14,6 +22,13 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// keepStateIn points the state and data directories at dir for the test
+func keepStateIn(t *testing.T, dir string) {
+	t.Helper()
+	t.Setenv(config.StateDirEnv, dir)
+	t.Setenv(config.DataDirEnv, dir)
+}
+
 func TestNewDaemon(t *testing.T) {
 	cfg := &config.Config{
 		WatchDirectories: []string{"/tmp/test"},
@@ -191,3 +206,647 @@ actions:
 	_, err = os.Stat(logDestPath)
 	assert.NoError(t, err, "Log file should exist in workflow destination")
 }
+
+func TestDaemon_DirectoryStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	docsDir := filepath.Join(tmpDir, "docs")
+	inboxDir := filepath.Join(tmpDir, "inbox")
+	require.NoError(t, os.Mkdir(docsDir, 0755))
+	require.NoError(t, os.Mkdir(inboxDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{docsDir, inboxDir}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.txt", Target: "../sorted"},
+	}
+	cfg.Settings.CreateDirs = true
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+
+	statsPath := filepath.Join(tmpDir, "stats.json")
+	daemon.SetStatsFile(statsPath)
+
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	// One file that matches a pattern, one that doesn't
+	require.NoError(t, os.WriteFile(filepath.Join(docsDir, "notes.txt"), []byte("notes"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(inboxDir, "photo.raw"), []byte("raw"), 0644))
+
+	time.Sleep(500 * time.Millisecond)
+
+	stats := daemon.DirectoryStats()
+	require.Len(t, stats, 2, "Expected one entry per watched directory")
+
+	byPath := make(map[string]types.DirectoryStats)
+	for _, s := range stats {
+		byPath[s.Path] = s
+	}
+
+	docs := byPath[docsDir]
+	assert.GreaterOrEqual(t, docs.EventsSeen, 1, "Docs dir should have seen events")
+	assert.GreaterOrEqual(t, docs.FilesOrganized, 1, "Docs dir should have organized the txt file")
+	assert.Zero(t, docs.Errors)
+	assert.False(t, docs.LastActivity.IsZero())
+
+	inbox := byPath[inboxDir]
+	assert.GreaterOrEqual(t, inbox.EventsSeen, 1, "Inbox dir should have seen events")
+	assert.Zero(t, inbox.FilesOrganized, "Nothing in inbox matches a pattern")
+	assert.GreaterOrEqual(t, inbox.Skipped, 1, "Unmatched file should count as skipped")
+
+	// Status exposes the same counters
+	assert.Len(t, daemon.Status().Directories, 2)
+
+	// The stats file is kept in sync for other processes
+	_, err = os.Stat(statsPath)
+	assert.NoError(t, err, "Stats file should have been written")
+}
+
+func TestDaemon_IgnoresOwnAndHiddenFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{tmpDir}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*", Target: "sorted"},
+	}
+	cfg.Settings.IgnoreHidden = true
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+
+	// A stats file inside the watched directory must not count its own writes
+	daemon.SetStatsFile(filepath.Join(tmpDir, ".sortd.stats.json"))
+
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".sortd.activity.jsonl"), []byte("{}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".hidden"), []byte("hidden"), 0644))
+
+	time.Sleep(1500 * time.Millisecond)
+
+	for _, stats := range daemon.DirectoryStats() {
+		assert.Zero(t, stats.EventsSeen, "No event should have been counted for %s", stats.Path)
+	}
+	assert.FileExists(t, filepath.Join(tmpDir, ".hidden"))
+	assert.FileExists(t, filepath.Join(tmpDir, ".sortd.activity.jsonl"))
+	assert.Equal(t, "it is one of sortd's own files", watch.IgnoreReason(cfg, filepath.Join(tmpDir, ".sortd.stats.json")))
+	assert.Equal(t, "it is hidden and ignore_hidden is set", watch.IgnoreReason(cfg, filepath.Join(tmpDir, ".hidden")))
+}
+
+func TestDaemon_WatchFilters(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "scans")
+	destDir := filepath.Join(tmpDir, "sorted")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*", Target: "../sorted"},
+	}
+	cfg.Settings.CreateDirs = true
+	cfg.WatchMode.Filters = []config.WatchFilter{
+		{Directory: watchDir, Include: []string{"*.pdf", "*.part"}},
+		{Exclude: []string{"*.part"}},
+	}
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "scan.pdf"), []byte("pdf"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "scan.pdf.part"), []byte("partial"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "notes.txt"), []byte("notes"), 0644))
+
+	time.Sleep(500 * time.Millisecond)
+
+	_, err = os.Stat(filepath.Join(destDir, "scan.pdf"))
+	assert.NoError(t, err, "Included file should be organized")
+
+	_, err = os.Stat(filepath.Join(watchDir, "scan.pdf.part"))
+	assert.NoError(t, err, "Excluded temp file should be left alone")
+
+	_, err = os.Stat(filepath.Join(watchDir, "notes.txt"))
+	assert.NoError(t, err, "File outside the include list should be left alone")
+}
+
+func TestDaemon_StabilityWindow(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	destDir := filepath.Join(tmpDir, "sorted")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "../sorted"},
+	}
+	cfg.Settings.CreateDirs = true
+	cfg.WatchMode.Stability = []config.StabilityWindow{
+		{Pattern: "*.pdf", StableSeconds: 1, TempSuffixes: []string{".part"}},
+	}
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	// Browsers create the final name early and keep writing to a .part sibling
+	finalPath := filepath.Join(watchDir, "report.pdf")
+	partPath := finalPath + ".part"
+	require.NoError(t, os.WriteFile(partPath, []byte("partial"), 0644))
+	require.NoError(t, os.WriteFile(finalPath, []byte{}, 0644))
+
+	time.Sleep(1500 * time.Millisecond)
+	_, err = os.Stat(finalPath)
+	assert.NoError(t, err, "File should wait while its .part sibling exists")
+
+	require.NoError(t, os.Remove(partPath))
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(destDir, "report.pdf"))
+		return err == nil
+	}, 3*time.Second, 100*time.Millisecond, "File should be organized once it settles")
+}
+
+func TestDaemon_WaitForClose(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	destDir := filepath.Join(tmpDir, "sorted")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	// Another program still has the file open
+	finalPath := filepath.Join(watchDir, "report.pdf")
+	require.NoError(t, os.WriteFile(finalPath, []byte("pdf"), 0644))
+	if open, err := openfiles.IsOpen(finalPath); err != nil || open {
+		t.Skipf("Open files can't be detected here: %v", err)
+	}
+	f, err := os.Open(finalPath)
+	require.NoError(t, err)
+	defer f.Close()
+	writer := exec.Command("sleep", "30")
+	writer.Stdin = f
+	require.NoError(t, writer.Start())
+	defer writer.Process.Kill()
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "../sorted"},
+	}
+	cfg.Settings.CreateDirs = true
+	cfg.WatchMode.WaitForClose = true
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	require.NoError(t, os.WriteFile(finalPath, []byte("pdf, more of it"), 0644))
+	time.Sleep(1500 * time.Millisecond)
+	_, err = os.Stat(finalPath)
+	assert.NoError(t, err, "File should wait while another process has it open")
+
+	require.NoError(t, writer.Process.Kill())
+	writer.Wait()
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(destDir, "report.pdf"))
+		return err == nil
+	}, 3*time.Second, 100*time.Millisecond, "File should be organized once it is closed")
+}
+
+func TestDaemon_WebhookTrigger(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "inbox")
+	destDir := filepath.Join(tmpDir, "paperless")
+	workflowDir := filepath.Join(tmpDir, "workflows")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+	require.NoError(t, os.Mkdir(workflowDir, 0755))
+
+	workflowYAML := `
+id: ingest
+name: Ingest
+enabled: true
+trigger:
+  type: webhook
+actions:
+  - type: move
+    target: ` + destDir + `
+    options:
+      createTargetDir: "true"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(workflowDir, "ingest.yaml"), []byte(workflowYAML), 0644))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.WatchMode.Webhook = config.WebhookServer{Listen: "127.0.0.1:0", Token: "secret"}
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, workflowDir)
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	// Create the file while the daemon is running; no rules match it
+	filePath := filepath.Join(watchDir, "receipt.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("receipt"), 0644))
+
+	post := func(path, token string) *http.Response {
+		body := strings.NewReader(`{"path": "` + path + `"}`)
+		req, err := http.NewRequest(http.MethodPost, "http://"+daemon.WebhookAddr()+"/hooks/ingest", body)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post(filePath, "wrong").StatusCode)
+	assert.Equal(t, http.StatusForbidden, post("/etc/passwd", "secret").StatusCode)
+
+	resp := post(filePath, "secret")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	_, err = os.Stat(filepath.Join(destDir, "receipt.pdf"))
+	assert.NoError(t, err, "Webhook-triggered workflow should move the file")
+}
+
+func TestDaemon_WebhookOnNetworkWithItsOwnToken(t *testing.T) {
+	tmpDir := t.TempDir()
+	keepStateIn(t, filepath.Join(tmpDir, "state"))
+	watchDir := filepath.Join(tmpDir, "inbox")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	// The webhook's own token is enough to serve it off localhost
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.WatchMode.Webhook = config.WebhookServer{Listen: "0.0.0.0:0", Token: "secret"}
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, filepath.Join(tmpDir, "workflows"))
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	daemon.Stop()
+
+	// Without any token it is refused
+	cfg.WatchMode.Webhook.Token = ""
+	daemon, err = watch.NewDaemonWithWorkflowPath(cfg, filepath.Join(tmpDir, "workflows"))
+	require.NoError(t, err)
+	err = daemon.Start()
+	if err == nil {
+		daemon.Stop()
+	}
+	assert.Error(t, err)
+}
+
+func TestDaemon_TrainingStagesMoves(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	destDir := filepath.Join(tmpDir, "sorted")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Directories.Default = tmpDir
+	keepStateIn(t, tmpDir)
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "../sorted"},
+	}
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.Collision = "rename"
+	cfg.Settings.Training = config.TrainingSettings{Enabled: true, Days: 30}
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	filePath := filepath.Join(watchDir, "invoice.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("pdf"), 0644))
+
+	time.Sleep(500 * time.Millisecond)
+
+	_, err = os.Stat(filePath)
+	assert.NoError(t, err, "File should stay in place during the training period")
+
+	queue, err := pending.Open(daemon.PendingQueuePath())
+	require.NoError(t, err)
+	items, err := queue.List()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, filePath, items[0].Path)
+	assert.Equal(t, filepath.Join(destDir, "invoice.pdf"), items[0].Destination)
+	assert.Equal(t, "*.pdf", items[0].Rule)
+	assert.False(t, queue.TrainingStart().IsZero(), "Staging should start the training clock")
+
+	// Approving carries out the move and builds trust in the rule
+	store, err := learning.Open(learning.DefaultPath(cfg))
+	require.NoError(t, err)
+	engine := organize.NewWithConfig(cfg)
+	require.NoError(t, queue.Approve(items[0].ID, engine.MoveFile, store))
+
+	_, err = os.Stat(filepath.Join(destDir, "invoice.pdf"))
+	assert.NoError(t, err, "Approved move should be carried out")
+	assert.Equal(t, 1, store.Stats("*.pdf").Approvals)
+	assert.Greater(t, store.Confidence("*.pdf"), 0.5)
+
+	items, err = queue.List()
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestDaemon_TrainingPeriodEnds(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	destDir := filepath.Join(tmpDir, "sorted")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Directories.Default = tmpDir
+	keepStateIn(t, tmpDir)
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "../sorted"},
+	}
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.Collision = "rename"
+	cfg.Settings.Training = config.TrainingSettings{
+		Enabled: true,
+		Days:    30,
+		Started: time.Now().Add(-31 * 24 * time.Hour),
+	}
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "invoice.pdf"), []byte("pdf"), 0644))
+
+	time.Sleep(500 * time.Millisecond)
+
+	_, err = os.Stat(filepath.Join(destDir, "invoice.pdf"))
+	assert.NoError(t, err, "Moves should be automatic once the training period is over")
+}
+
+func TestDaemon_UserCorrectionIsRespected(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	destDir := filepath.Join(tmpDir, "sorted")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Directories.Default = tmpDir
+	keepStateIn(t, tmpDir)
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "../sorted"},
+	}
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.Collision = "rename"
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	filePath := filepath.Join(watchDir, "boarding-pass.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("pdf"), 0644))
+
+	time.Sleep(500 * time.Millisecond)
+	sortedPath := filepath.Join(destDir, "boarding-pass.pdf")
+	_, err = os.Stat(sortedPath)
+	require.NoError(t, err, "File should be organized first")
+
+	// The user disagrees and moves it back
+	require.NoError(t, os.Rename(sortedPath, filePath))
+
+	time.Sleep(500 * time.Millisecond)
+	_, err = os.Stat(filePath)
+	assert.NoError(t, err, "File the user moved back should stay where they put it")
+
+	store, err := learning.Open(learning.DefaultPath(cfg))
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Stats("*.pdf").Corrections)
+	assert.Less(t, store.Confidence("*.pdf"), 0.5)
+}
+
+func TestDaemon_LowConfidenceStagesMoves(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	destDir := filepath.Join(tmpDir, "sorted")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Directories.Default = tmpDir
+	keepStateIn(t, tmpDir)
+	cfg.Organize.Patterns = []types.Pattern{
+		{Match: "*.pdf", Target: "../sorted"},
+		{Match: "*.jpg", Target: "../sorted"},
+	}
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.Collision = "rename"
+	cfg.Settings.AutoApplyConfidence = 0.6
+
+	// *.jpg has earned trust; *.pdf has no history and starts at 0.5
+	store, err := learning.Open(learning.DefaultPath(cfg))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.RecordApproval("*.jpg"))
+	}
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "photo.jpg"), []byte("jpg"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "report.pdf"), []byte("pdf"), 0644))
+
+	time.Sleep(500 * time.Millisecond)
+
+	_, err = os.Stat(filepath.Join(destDir, "photo.jpg"))
+	assert.NoError(t, err, "Trusted pattern should apply automatically")
+	_, err = os.Stat(filepath.Join(watchDir, "report.pdf"))
+	assert.NoError(t, err, "Untrusted pattern should leave the file in place")
+
+	queue, err := pending.Open(daemon.PendingQueuePath())
+	require.NoError(t, err)
+	items, err := queue.List()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "*.pdf", items[0].Rule)
+	assert.Equal(t, pending.ReasonLowConfidence, items[0].Reason)
+}
+
+func TestDaemon_AskCollisionQueuesMove(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	destDir := filepath.Join(tmpDir, "sorted")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+	require.NoError(t, os.Mkdir(destDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(destDir, "report.pdf"), []byte("old"), 0644))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Directories.Default = tmpDir
+	keepStateIn(t, tmpDir)
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: destDir}}
+	cfg.Settings.CreateDirs = true
+	cfg.Settings.Collision = "ask"
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	filePath := filepath.Join(watchDir, "report.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("new"), 0644))
+
+	time.Sleep(500 * time.Millisecond)
+
+	_, err = os.Stat(filePath)
+	assert.NoError(t, err, "Colliding file should wait for the user's decision")
+
+	queue, err := pending.Open(daemon.PendingQueuePath())
+	require.NoError(t, err)
+	items, err := queue.List()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, pending.ReasonCollision, items[0].Reason)
+	assert.Equal(t, filepath.Join(destDir, "report.pdf"), items[0].Destination)
+
+	// Approving asks again, this time of someone who can answer
+	engine := organize.NewWithConfig(cfg)
+	engine.SetCollisionResolver(func(src, dest string) (string, error) {
+		return organize.CollisionRename, nil
+	})
+	require.NoError(t, queue.Approve(items[0].ID, engine.MoveFile, nil))
+	_, err = os.Stat(filepath.Join(destDir, "report_(1).pdf"))
+	assert.NoError(t, err, "Approved move should be renamed as answered")
+}
+
+func TestDaemon_ReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	destDir := filepath.Join(tmpDir, "sorted")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Directories.Default = tmpDir
+	keepStateIn(t, tmpDir)
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: destDir}}
+	cfg.Settings.CreateDirs = true
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	// Switched on from another process through the marker file
+	require.NoError(t, watch.SetReadOnly(cfg, true))
+	assert.True(t, daemon.Status().ReadOnly)
+
+	filePath := filepath.Join(watchDir, "report.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("report"), 0644))
+	time.Sleep(500 * time.Millisecond)
+	assert.FileExists(t, filePath, "Nothing should move in read-only mode")
+
+	require.NoError(t, watch.SetReadOnly(cfg, false))
+	assert.False(t, daemon.Status().ReadOnly)
+
+	// The next change to the file is handled as usual
+	require.NoError(t, os.WriteFile(filePath, []byte("report v2"), 0644))
+	time.Sleep(500 * time.Millisecond)
+	assert.FileExists(t, filepath.Join(destDir, "report.pdf"))
+}
+
+func TestDaemon_UnmatchedPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.Directories.Default = tmpDir
+	keepStateIn(t, tmpDir)
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: "../docs"}}
+	cfg.Settings.Collision = organize.CollisionRename
+	cfg.Settings.Unmatched.Policy = organize.UnmatchedMove
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "mystery.bin"), []byte("?"), 0644))
+	time.Sleep(500 * time.Millisecond)
+
+	assert.FileExists(t, organize.UnsortedDestination(cfg, "mystery.bin", time.Now()))
+	stats := daemon.DirectoryStats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].Unmatched)
+	assert.Equal(t, 1, stats[0].FilesOrganized)
+}
+
+func TestDaemon_Screenshots(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "desktop")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.Directories.Default = tmpDir
+	keepStateIn(t, tmpDir)
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.png", Target: "../images"}}
+	cfg.Settings.Collision = organize.CollisionRename
+	cfg.Settings.Screenshots.Enabled = true
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "Screenshot from 2024-03-01 10-22-33.png"), []byte("png"), 0644))
+	time.Sleep(500 * time.Millisecond)
+
+	assert.FileExists(t, filepath.Join(tmpDir, "Screenshots", "2024-03", "2024-03-01-screenshot.png"),
+		"Screenshots are filed by month ahead of the rules, without create_dirs")
+	assert.NoDirExists(t, filepath.Join(tmpDir, "images"))
+}
+
+func TestDaemon_FailureQueue(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "downloads")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.Directories.Default = tmpDir
+	keepStateIn(t, tmpDir)
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: "../missing"}}
+	cfg.Settings.Collision = organize.CollisionRename
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	filePath := filepath.Join(watchDir, "report.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("pdf"), 0644))
+	time.Sleep(500 * time.Millisecond)
+
+	queue, err := failures.Open(daemon.FailureQueuePath())
+	require.NoError(t, err)
+	items, err := queue.List()
+	require.NoError(t, err)
+	require.Len(t, items, 1, "A move that can't succeed is recorded without retries")
+	assert.Equal(t, filePath, items[0].Path)
+	assert.Equal(t, "rules", items[0].Source)
+	assert.Equal(t, 1, items[0].Attempts)
+}
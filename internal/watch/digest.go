@@ -0,0 +1,93 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/digest"
+)
+
+// digestStateFile records when the last digest was sent, next to the activity log
+const digestStateFile = "digest"
+
+// digestCheckInterval is how often the daemon checks whether a digest is due
+var digestCheckInterval = time.Hour
+
+// runDigests periodically sends activity digests until the daemon stops
+func (d *Daemon) runDigests() {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		d.sendDigestIfDue(time.Now())
+
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDigestIfDue delivers a digest covering the activity since the last one once a
+// full period has passed. The first check only starts the clock.
+func (d *Daemon) sendDigestIfDue(now time.Time) {
+	d.mutex.RLock()
+	activityPath := d.activityPath
+	d.mutex.RUnlock()
+
+	if activityPath == "" {
+		return
+	}
+
+	settings := d.config.Settings.Digest
+	statePath := filepath.Join(filepath.Dir(activityPath), digestStateFile)
+
+	last, err := readDigestState(statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("Failed to read digest state %s: %v", statePath, err)
+		}
+		writeDigestState(statePath, now)
+		return
+	}
+	if now.Sub(last) < digest.Period(settings.Frequency) {
+		return
+	}
+
+	entries, err := readActivityFile(activityPath, last)
+	if err != nil && !os.IsNotExist(err) {
+		log.Warnf("Failed to read activity log for digest: %v", err)
+		return
+	}
+
+	summary := digest.Build(entries, last, now)
+	summary.Lingering = d.Overdue(now)
+	if err := digest.Deliver(settings, summary); err != nil {
+		log.Warnf("Failed to deliver digest: %v", err)
+	} else {
+		log.Info("Activity digest delivered")
+	}
+	writeDigestState(statePath, now)
+}
+
+// readDigestState returns the time the last digest was sent
+func readDigestState(path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+}
+
+// writeDigestState records the time a digest was sent
+func writeDigestState(path string, t time.Time) {
+	if err := atomicfile.WriteFile(path, []byte(t.Format(time.RFC3339)), 0644); err != nil {
+		log.Warnf("Failed to write digest state %s: %v", path, err)
+	}
+}
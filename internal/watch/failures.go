@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/errors"
+	"sortd/internal/failures"
+)
+
+// FailureQueuePath returns where the daemon keeps the files it gave up on
+func (d *Daemon) FailureQueuePath() string {
+	return failures.DefaultPath(d.config)
+}
+
+// failureQueue opens the dead-letter queue on first use
+func (d *Daemon) failureQueue() (*failures.Queue, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.failures == nil {
+		queue, err := failures.Open(d.FailureQueuePath())
+		if err != nil {
+			return nil, err
+		}
+		d.failures = queue
+	}
+	return d.failures, nil
+}
+
+// retryTransient runs op, running it again with a growing pause while it fails
+// on transient errors, as settings.retry allows. It returns how many tries
+// were made and the last error. Retrying stops early when the daemon stops.
+func (d *Daemon) retryTransient(path string, op func() error) (int, error) {
+	retry := d.config.Settings.Retry
+	attempts := 1
+	err := op()
+	for ; err != nil && errors.IsTransient(err) && attempts <= retry.MaxRetries(); attempts++ {
+		delay := retry.Delay(attempts)
+		log.Warnf("Transient error handling %s, retrying in %s: %v", path, delay, err)
+		select {
+		case <-d.stopCh:
+			return attempts, err
+		case <-time.After(delay):
+		}
+		err = op()
+	}
+	return attempts, err
+}
+
+// recordFailure adds a file the daemon failed to handle to the dead-letter
+// queue, where 'sortd failures' lists and retries it. attempts is 0 when
+// unknown, as for workflows, which retry their actions themselves.
+func (d *Daemon) recordFailure(path, source string, err error, attempts int) {
+	if d.config.Directories.Default == "" {
+		return
+	}
+
+	queue, openErr := d.failureQueue()
+	if openErr != nil {
+		log.Warnf("Failed to open failure queue: %v", openErr)
+		return
+	}
+
+	item := failures.Item{
+		Path:     path,
+		Source:   source,
+		Error:    err.Error(),
+		Kind:     errors.KindOf(err).Name(),
+		Attempts: attempts,
+		Trace:    d.traceOf(path),
+	}
+	if _, addErr := queue.Add(item); addErr != nil {
+		log.Warnf("Failed to record failure of %s: %v", path, addErr)
+	}
+}
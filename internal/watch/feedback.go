@@ -0,0 +1,177 @@
+package watch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sortd/internal/config"
+	"sortd/internal/globs"
+	"sortd/internal/organize"
+	"sortd/pkg/types"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FeedbackLoop is a rule or workflow that sends files into a watched
+// directory, where the daemon picks them up and sends them on again: files
+// that keep moving deeper, or workflows that keep acting on their own output
+type FeedbackLoop struct {
+	Source  string // The rule or workflow, e.g. `pattern "*.pdf"`
+	Example string // A file it applies to, e.g. "x.pdf"
+	From    string // Watched directory the file starts in, for patterns
+	Target  string // Where the file goes, inside a watched directory
+	Reason  string // What happens to it there
+}
+
+// String describes the loop on one line
+func (l FeedbackLoop) String() string {
+	if l.From == "" {
+		return fmt.Sprintf("%s sends %s to %s, %s", l.Source, l.Example, l.Target, l.Reason)
+	}
+	return fmt.Sprintf("%s sends %s from %s to %s, %s", l.Source, l.Example, l.From, l.Target, l.Reason)
+}
+
+// FeedbackLoops finds patterns and workflows whose targets lie inside a
+// watched directory where the same or a broader pattern matches their files
+// again. Each is checked with a file name its glob matches; regex patterns,
+// size tiers, and targets with placeholders or relative workflow targets,
+// aren't checked.
+func FeedbackLoops(cfg *config.Config, workflows []types.Workflow) []FeedbackLoop {
+	engine := organize.NewWithConfig(cfg)
+	var loops []FeedbackLoop
+
+	for _, watched := range cfg.WatchDirs() {
+		for _, pattern := range cfg.Organize.Patterns {
+			if pattern.Type == types.RegexPattern {
+				continue
+			}
+			example, ok := globs.Example(pattern.Match)
+			if !ok {
+				continue
+			}
+			example = filepath.Base(example)
+			if loop, ok := patternLoop(cfg, engine, pattern, watched.Path, example); ok {
+				loops = append(loops, loop)
+			}
+		}
+	}
+
+	for _, wf := range workflows {
+		if !wf.Enabled {
+			continue
+		}
+		for _, trigger := range wf.AllTriggers() {
+			loops = append(loops, workflowLoops(cfg, wf, trigger)...)
+		}
+	}
+	return loops
+}
+
+// patternLoop checks where a file matching pattern goes from a watched
+// directory. Files that settle, because the rule matching them at their
+// destination leaves them there, are fine.
+func patternLoop(cfg *config.Config, engine *organize.Engine, pattern types.Pattern, from, example string) (FeedbackLoop, bool) {
+	path := filepath.Join(from, example)
+	if matched, ok := engine.MatchingPattern(path); !ok || matched.Match != pattern.Match {
+		return FeedbackLoop{}, false
+	}
+	target, ok := engine.DestinationDir(path)
+	if !ok || !watchedForLoops(cfg, filepath.Join(target, example)) {
+		return FeedbackLoop{}, false
+	}
+
+	arrived := filepath.Join(target, example)
+	next, ok := engine.DestinationDir(arrived)
+	if !ok || filepath.Clean(next) == filepath.Clean(target) {
+		return FeedbackLoop{}, false
+	}
+	again, _ := engine.MatchingPattern(arrived)
+	return FeedbackLoop{
+		Source:  fmt.Sprintf("pattern %q", pattern.Match),
+		Example: example,
+		From:    from,
+		Target:  target,
+		Reason:  fmt.Sprintf("where it is watched and pattern %q sends it on to %s", again.Match, next),
+	}, true
+}
+
+// workflowLoops checks the move and copy actions of a workflow: a file they
+// put in a watched directory fires the workflow again
+func workflowLoops(cfg *config.Config, wf types.Workflow, trigger types.Trigger) []FeedbackLoop {
+	switch trigger.Type {
+	case types.FileCreated, types.FileModified, types.FilePatternMatch:
+	default:
+		return nil
+	}
+	pattern := trigger.Pattern
+	if pattern == "" {
+		pattern = "*"
+	}
+	example, ok := globs.Example(pattern)
+	if !ok {
+		return nil
+	}
+	example = filepath.Base(example)
+
+	var loops []FeedbackLoop
+	for _, action := range wf.Actions {
+		if action.Type != types.MoveAction && action.Type != types.CopyAction {
+			continue
+		}
+		if !filepath.IsAbs(action.Target) || strings.ContainsAny(action.Target, "{$") {
+			continue
+		}
+
+		arrived := filepath.Join(action.Target, example)
+		if matched, err := globs.Match(pattern, arrived); err != nil || !matched {
+			continue
+		}
+		if !watchedForLoops(cfg, arrived) {
+			continue
+		}
+		loops = append(loops, FeedbackLoop{
+			Source:  fmt.Sprintf("workflow %q", wf.ID),
+			Example: example,
+			Target:  action.Target,
+			Reason:  fmt.Sprintf("where it is watched and the workflow's trigger %q fires again", pattern),
+		})
+	}
+	return loops
+}
+
+// watchedForLoops reports whether a file arriving at path is seen by the daemon
+func watchedForLoops(cfg *config.Config, path string) bool {
+	if _, ok := cfg.WatchDirFor(filepath.Dir(path)); !ok {
+		return false
+	}
+	return IgnoreReason(cfg, path) == ""
+}
+
+// checkFeedbackLoops refuses to start the daemon over feedback loops unless
+// they were acknowledged with watch_mode.allow_feedback_loops, which still
+// logs each of them
+func (d *Daemon) checkFeedbackLoops() error {
+	var workflows []types.Workflow
+	if d.workflowManager != nil {
+		workflows = d.workflowManager.GetWorkflows()
+	}
+	loops := FeedbackLoops(d.config, workflows)
+	if len(loops) == 0 {
+		return nil
+	}
+
+	if d.config.WatchMode.AllowFeedbackLoops {
+		for _, loop := range loops {
+			log.Warnf("Feedback loop (allowed by watch_mode.allow_feedback_loops): %s", loop)
+		}
+		return nil
+	}
+
+	lines := make([]string, len(loops))
+	for i, loop := range loops {
+		lines[i] = "  " + loop.String()
+	}
+	return fmt.Errorf("rules or workflows send files back into watched directories:\n%s\nchange their targets or watch directories, or set watch_mode.allow_feedback_loops: true to start anyway",
+		strings.Join(lines, "\n"))
+}
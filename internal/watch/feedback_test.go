@@ -0,0 +1,85 @@
+package watch_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedbackLoops(t *testing.T) {
+	downloads := t.TempDir()
+	documents := t.TempDir()
+
+	t.Run("relative targets in recursive watch directories nest forever", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Directories.Watch = []config.WatchDir{{Path: downloads, Recursive: true}}
+		cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: "PDFs"}}
+
+		loops := watch.FeedbackLoops(cfg, nil)
+		require.Len(t, loops, 1)
+		assert.Equal(t, filepath.Join(downloads, "PDFs"), loops[0].Target)
+		assert.Contains(t, loops[0].String(), `pattern "*.pdf"`)
+	})
+
+	t.Run("files that settle at their target are fine", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Directories.Watch = []config.WatchDir{{Path: downloads, Recursive: true}}
+		cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: filepath.Join(downloads, "PDFs")}}
+		assert.Empty(t, watch.FeedbackLoops(cfg, nil))
+
+		// Not watching subdirectories, relative targets are out of sight
+		cfg.Directories.Watch = []config.WatchDir{{Path: downloads}}
+		cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: "PDFs"}}
+		assert.Empty(t, watch.FeedbackLoops(cfg, nil))
+	})
+
+	t.Run("watch filters keeping the files out break the loop", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Directories.Watch = []config.WatchDir{{Path: downloads, Recursive: true, Exclude: []string{"**/PDFs/*"}}}
+		cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: "PDFs"}}
+		assert.Empty(t, watch.FeedbackLoops(cfg, nil))
+	})
+
+	t.Run("workflows moving files into watched directories fire again", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Directories.Watch = []config.WatchDir{{Path: downloads}, {Path: documents}}
+		workflows := []types.Workflow{{
+			ID:      "invoices",
+			Enabled: true,
+			Trigger: types.Trigger{Type: types.FileCreated, Pattern: "invoice*.pdf"},
+			Actions: []types.Action{{Type: types.CopyAction, Target: documents}},
+		}}
+
+		loops := watch.FeedbackLoops(cfg, workflows)
+		require.Len(t, loops, 1)
+		assert.Contains(t, loops[0].String(), `workflow "invoices"`)
+
+		workflows[0].Enabled = false
+		assert.Empty(t, watch.FeedbackLoops(cfg, workflows))
+	})
+}
+
+func TestDaemon_RefusesFeedbackLoops(t *testing.T) {
+	downloads := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Directories.Watch = []config.WatchDir{{Path: downloads, Recursive: true}}
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: "PDFs"}}
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	err = daemon.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "allow_feedback_loops")
+
+	cfg.WatchMode.AllowFeedbackLoops = true
+	daemon, err = watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	daemon.Stop()
+}
@@ -0,0 +1,104 @@
+package watch
+
+import (
+	"path/filepath"
+	"strings"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+	"sortd/internal/globs"
+	"sortd/internal/manifest"
+)
+
+// allowEvent reports whether a file event passes the configured watch filters.
+// Every filter that applies to the file's directory must allow it.
+func (d *Daemon) allowEvent(path string) bool {
+	if d.config == nil {
+		return true
+	}
+	return filtersAllow(d.config, path)
+}
+
+// ignoredName reports whether the file is ignored for its name alone: sortd's
+// own files always are, hidden files when ignore_hidden is set
+func (d *Daemon) ignoredName(path string) bool {
+	return ownFile(path) || (d.config != nil && hiddenIgnored(d.config, path))
+}
+
+// ownFile reports whether the file is one sortd writes for itself, e.g. a
+// state file or a file being staged, which all start with ".sortd"
+func ownFile(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), ".sortd")
+}
+
+// hiddenIgnored reports whether the file is hidden and ignore_hidden is set
+func hiddenIgnored(cfg *config.Config, path string) bool {
+	return cfg.Settings.IgnoreHidden && strings.HasPrefix(filepath.Base(path), ".")
+}
+
+// filtersAllow reports whether every configured watch filter covering the
+// file's directory allows it, including the include and exclude lists of
+// watch directory entries
+func filtersAllow(cfg *config.Config, path string) bool {
+	for _, filter := range cfg.WatchFilters() {
+		if !filterApplies(filter, path) {
+			continue
+		}
+		if !filterAllows(filter, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// IgnoreReason returns why the watch daemon would ignore a file arriving at
+// path before any workflow or pattern sees it, or "" if it wouldn't
+func IgnoreReason(cfg *config.Config, path string) string {
+	switch {
+	case atomicfile.IsTemp(path):
+		return "sortd is still writing it"
+	case ownFile(path):
+		return "it is one of sortd's own files"
+	case hiddenIgnored(cfg, path):
+		return "it is hidden and ignore_hidden is set"
+	case filepath.Base(path) == manifest.FileName && manifest.New(cfg.Settings.Manifests).Covers(path):
+		return "it is the checksum manifest of an archive folder"
+	case hasTempSuffix(cfg, path):
+		return "it has a temporary suffix from a stability window"
+	case !filtersAllow(cfg, path):
+		return "the watch filters exclude it"
+	}
+	return ""
+}
+
+// filterApplies reports whether the filter covers the given path
+func filterApplies(filter config.WatchFilter, path string) bool {
+	if filter.Directory == "" {
+		return true
+	}
+
+	root := filepath.Clean(filter.Directory)
+	dir := filepath.Clean(filepath.Dir(path))
+	return dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))
+}
+
+// filterAllows checks a file against the filter's include and exclude globs.
+// Excludes take precedence; an empty include list includes everything.
+func filterAllows(filter config.WatchFilter, path string) bool {
+	for _, pattern := range filter.Exclude {
+		if matched, err := globs.Match(pattern, path); err == nil && matched {
+			return false
+		}
+	}
+
+	if len(filter.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range filter.Include {
+		if matched, err := globs.Match(pattern, path); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
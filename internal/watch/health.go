@@ -0,0 +1,121 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/httpsec"
+)
+
+// HealthPath is where the health endpoint answers
+const HealthPath = "/healthz"
+
+// healthResponse is the body of the health endpoint
+type healthResponse struct {
+	Status           string          `json:"status"` // "ok", "degraded" or "stopped"
+	Backend          string          `json:"backend"`
+	WatchDirectories []string        `json:"watch_directories"`
+	Watchers         []WatcherStatus `json:"watchers"`
+	ReadOnly         bool            `json:"read_only"`
+	Paused           string          `json:"paused,omitempty"` // Why work is held back for the battery or the network
+	FilesProcessed   int             `json:"files_processed"`
+	LastActivity     time.Time       `json:"last_activity"`
+}
+
+// startHealthServer serves the health endpoint if an address is configured,
+// for container orchestrators and load balancers
+func (d *Daemon) startHealthServer() error {
+	addr := d.config.WatchMode.HealthListen
+	if addr == "" {
+		return nil
+	}
+
+	listener, err := d.listen("health endpoint", addr, d.config.WatchMode.HTTP.Token)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthPath, d.handleHealth)
+	handler := httpsec.RequireToken(d.config.WatchMode.HTTP.Token, mux)
+	d.healthServer = &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	d.healthAddr = listener.Addr().String()
+
+	// Serve the server just made: Stop may clear the field before this runs
+	server := d.healthServer
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Health server error: %v", err)
+		}
+	}()
+
+	log.Infof("Health endpoint listening on %s%s", d.healthAddr, HealthPath)
+	return nil
+}
+
+// stopHealthServer shuts the health server down, if running
+func (d *Daemon) stopHealthServer() {
+	if d.healthServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.healthServer.Shutdown(ctx); err != nil {
+		log.Errorf("Error stopping health server: %v", err)
+	}
+	d.healthServer = nil
+}
+
+// HealthAddr returns the address the health endpoint is listening on, or ""
+func (d *Daemon) HealthAddr() string {
+	return d.healthAddr
+}
+
+// handleHealth reports whether the daemon is running: 200 when it is, 503 when
+// it is stopped or none of its directories are being watched. Lost directories
+// make it "degraded".
+func (d *Daemon) handleHealth(w http.ResponseWriter, r *http.Request) {
+	status := d.Status()
+	backend := "fsnotify"
+	if d.usePolling() {
+		backend = "poll"
+	}
+
+	resp := healthResponse{
+		Status:           "ok",
+		Backend:          backend,
+		WatchDirectories: status.WatchDirectories,
+		Watchers:         status.Watchers,
+		ReadOnly:         status.ReadOnly,
+		Paused:           status.Paused,
+		FilesProcessed:   status.FilesProcessed,
+		LastActivity:     status.LastActivity,
+	}
+	code := http.StatusOK
+	watching := 0
+	for _, watcher := range status.Watchers {
+		if watcher.State == WatcherWatching {
+			watching++
+		}
+	}
+	switch {
+	case !status.Running:
+		resp.Status = "stopped"
+		code = http.StatusServiceUnavailable
+	case watching == 0:
+		resp.Status = "degraded"
+		code = http.StatusServiceUnavailable
+	case watching < len(status.Watchers):
+		resp.Status = "degraded"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
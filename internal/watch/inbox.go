@@ -0,0 +1,132 @@
+package watch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+	"sortd/internal/digest"
+)
+
+// inboxStateFile records when the last inbox report was sent and the counts
+// it found, next to the activity log
+const inboxStateFile = "inbox"
+
+// inboxState is the content of the inbox state file
+type inboxState struct {
+	Sent   time.Time      `json:"sent"`
+	Counts map[string]int `json:"counts"`
+}
+
+// InboxDirectories returns the directories the inbox zero report counts: the
+// configured ones, or the watch directories when none are set
+func InboxDirectories(cfg *config.Config) []string {
+	if len(cfg.Settings.Inbox.Directories) > 0 {
+		return cfg.Settings.Inbox.Directories
+	}
+	return cfg.WatchPaths()
+}
+
+// InboxReport counts the files waiting in cfg's inboxes, compared with the
+// counts of the last report the daemon sent
+func InboxReport(cfg *config.Config, now time.Time) digest.InboxReport {
+	var previous map[string]int
+	statePath := filepath.Join(filepath.Dir(ActivityFilePath(cfg)), inboxStateFile)
+	if state, err := readInboxState(statePath); err == nil {
+		previous = state.Counts
+	}
+	return digest.CountInboxes(InboxDirectories(cfg), previous, now)
+}
+
+// runInboxReports periodically nudges about files left in the inboxes until
+// the daemon stops. It shares the digest's check interval.
+func (d *Daemon) runInboxReports() {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		d.sendInboxReportIfDue(time.Now())
+
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendInboxReportIfDue delivers the inbox report once a full period has passed
+// since the last one. The first check only starts the clock. A report is only
+// sent when files are waiting, or when the inboxes were just cleared.
+func (d *Daemon) sendInboxReportIfDue(now time.Time) {
+	d.mutex.RLock()
+	activityPath := d.activityPath
+	d.mutex.RUnlock()
+
+	if activityPath == "" {
+		return
+	}
+
+	settings := d.config.Settings
+	statePath := filepath.Join(filepath.Dir(activityPath), inboxStateFile)
+	frequency := settings.Inbox.Frequency
+	if frequency == "" {
+		frequency = "weekly"
+	}
+
+	state, err := readInboxState(statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("Failed to read inbox state %s: %v", statePath, err)
+		}
+		report := digest.CountInboxes(InboxDirectories(d.config), nil, now)
+		writeInboxState(statePath, inboxState{Sent: now, Counts: report.Counts()})
+		return
+	}
+	if now.Sub(state.Sent) < digest.Period(frequency) {
+		return
+	}
+
+	report := digest.CountInboxes(InboxDirectories(d.config), state.Counts, now)
+	wasEmpty := true
+	for _, n := range state.Counts {
+		if n > 0 {
+			wasEmpty = false
+		}
+	}
+	if report.Total > 0 || !wasEmpty {
+		if err := digest.Deliver(settings.Digest, report); err != nil {
+			log.Warnf("Failed to deliver inbox report: %v", err)
+		} else {
+			log.Infof("Inbox report delivered: %d files waiting", report.Total)
+		}
+	}
+	writeInboxState(statePath, inboxState{Sent: now, Counts: report.Counts()})
+}
+
+// readInboxState returns the last report's time and counts
+func readInboxState(path string) (inboxState, error) {
+	var state inboxState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+// writeInboxState records the report just sent
+func writeInboxState(path string, state inboxState) {
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = atomicfile.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		log.Warnf("Failed to write inbox state %s: %v", path, err)
+	}
+}
@@ -0,0 +1,333 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+	"sortd/pkg/types"
+)
+
+// journalCompactInterval is how often a running daemon trims its activity log
+const journalCompactInterval = 24 * time.Hour
+
+// journalTailSize is how much of the end of the activity log is read to find
+// the entry a new one chains to
+const journalTailSize = 64 * 1024
+
+// JournalCheck is the result of checking the activity log's hash chain
+type JournalCheck struct {
+	Entries  int    // Entries read
+	Unsealed int    // Entries written before the log was hash-chained
+	Torn     int    // Lines that couldn't be read, such as a write cut short by a crash
+	Line     int    // Line of the first problem found, if any
+	Problem  string // What the problem is; empty when the chain is intact
+}
+
+// OK reports whether the log shows no sign of tampering
+func (c *JournalCheck) OK() bool {
+	return c.Problem == ""
+}
+
+// activityHash chains an entry to the hash of the entry before it
+func activityHash(prev string, entry types.ActivityEntry) string {
+	entry.Hash = ""
+	data, _ := json.Marshal(entry)
+
+	h := sha256.New()
+	h.Write([]byte(prev))
+	h.Write([]byte{'\n'})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lockJournal takes an exclusive lock on the activity log, shared between the
+// daemon appending to it and commands compacting it. The returned function
+// releases it.
+func lockJournal(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal lock: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock journal: %w", err)
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// appendActivity chains the entry to the last one in the log and appends it.
+// The caller must hold the journal lock.
+func appendActivity(path string, entry types.ActivityEntry) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	prev, torn, err := lastActivityHash(f)
+	if err != nil {
+		return err
+	}
+	entry.Hash = activityHash(prev, entry)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if torn {
+		// Start a fresh line after a write that was cut short
+		data = append([]byte{'\n'}, data...)
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// lastActivityHash returns the hash of the last readable entry in the log and
+// whether the log ends in a partial line
+func lastActivityHash(f *os.File) (string, bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return "", false, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return "", false, nil
+	}
+
+	offset := size - journalTailSize
+	if offset < 0 {
+		offset = 0
+	}
+	tail := make([]byte, size-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return "", false, err
+	}
+
+	torn := tail[len(tail)-1] != '\n'
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte{'\n'})
+	for i := len(lines) - 1; i >= 0; i-- {
+		var entry types.ActivityEntry
+		if err := json.Unmarshal(lines[i], &entry); err == nil {
+			return entry.Hash, torn, nil
+		}
+	}
+	return "", torn, nil
+}
+
+// readJournal reads every entry of the activity log, checking the hash chain
+// as it goes. A missing log has no entries.
+func readJournal(path string) ([]types.ActivityEntry, *JournalCheck, error) {
+	check := &JournalCheck{}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, check, nil
+		}
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var entries []types.ActivityEntry
+	prev := ""
+	sealed := false
+	line := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		// An unreadable line was never chained to, so it can be skipped; any
+		// edit to a chained entry shows up as a broken link below
+		var entry types.ActivityEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			check.Torn++
+			continue
+		}
+		entries = append(entries, entry)
+		check.Entries++
+
+		if check.Problem != "" {
+			continue
+		}
+		switch {
+		case entry.Hash == "" && !sealed:
+			check.Unsealed++
+		case entry.Hash == "":
+			check.Line, check.Problem = line, "entry has no hash after the log was sealed"
+		case entry.Hash != activityHash(prev, entry):
+			check.Line, check.Problem = line, "entry doesn't match its hash or the entry before it was changed or removed"
+		default:
+			sealed = true
+			prev = entry.Hash
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return entries, check, nil
+}
+
+// VerifyActivity checks the activity log for signs of manual editing
+func VerifyActivity(path string) (*JournalCheck, error) {
+	unlock, err := lockJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	_, check, err := readJournal(path)
+	return check, err
+}
+
+// ReadJournal returns every entry in the activity log, oldest first
+func ReadJournal(path string) ([]types.ActivityEntry, error) {
+	entries, _, err := readJournal(path)
+	return entries, err
+}
+
+// CompactActivity drops entries older than the retention allows and re-seals
+// what remains. It refuses to touch a log that fails its integrity check,
+// since re-sealing would hide the tampering, unless force is set. It returns
+// how many entries were kept and removed.
+func CompactActivity(path string, retention config.JournalSettings, now time.Time, force bool) (int, int, error) {
+	unlock, err := lockJournal(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+
+	entries, check, err := readJournal(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !check.OK() && !force {
+		return 0, 0, fmt.Errorf("activity log failed its integrity check at line %d (%s); refusing to compact", check.Line, check.Problem)
+	}
+	if len(entries) == 0 {
+		return 0, 0, nil
+	}
+
+	kept := entries
+	if retention.KeepDays > 0 {
+		cutoff := now.Add(-time.Duration(retention.KeepDays) * 24 * time.Hour)
+		kept = kept[:0:0]
+		for _, entry := range entries {
+			if !entry.Time.Before(cutoff) {
+				kept = append(kept, entry)
+			}
+		}
+	}
+	if retention.KeepEntries > 0 && len(kept) > retention.KeepEntries {
+		kept = kept[len(kept)-retention.KeepEntries:]
+	}
+
+	var buf bytes.Buffer
+	prev := ""
+	for _, entry := range kept {
+		entry.Hash = activityHash(prev, entry)
+		prev = entry.Hash
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return 0, 0, err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	if err := atomicfile.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return 0, 0, fmt.Errorf("failed to write compacted activity log: %w", err)
+	}
+	return len(kept), len(entries) - len(kept), nil
+}
+
+// ExportActivity writes entries as "csv" or "json" for record keeping
+func ExportActivity(w io.Writer, entries []types.ActivityEntry, format string) error {
+	switch format {
+	case "json":
+		if entries == nil {
+			entries = []types.ActivityEntry{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(entries)
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"time", "path", "destination", "source", "error", "operation", "hash", "trace"}); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			record := []string{
+				entry.Time.Format(time.RFC3339),
+				entry.Path,
+				entry.Destination,
+				entry.Source,
+				entry.Error,
+				entry.Operation,
+				entry.Hash,
+				entry.Trace,
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unknown export format %q: must be csv or json", format)
+	}
+}
+
+// runJournalCompaction trims the activity log to the configured retention now
+// and then daily until the daemon stops
+func (d *Daemon) runJournalCompaction() {
+	ticker := time.NewTicker(journalCompactInterval)
+	defer ticker.Stop()
+
+	for {
+		d.compactJournal(time.Now())
+
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// compactJournal applies the configured retention to the activity log
+func (d *Daemon) compactJournal(now time.Time) {
+	d.mutex.RLock()
+	activityPath := d.activityPath
+	d.mutex.RUnlock()
+
+	if activityPath == "" {
+		return
+	}
+
+	kept, removed, err := CompactActivity(activityPath, d.config.Settings.Journal, now, false)
+	if err != nil {
+		log.Warnf("Failed to compact activity log %s: %v", activityPath, err)
+		return
+	}
+	if removed > 0 {
+		log.Infof("Compacted activity log: kept %d entries, removed %d", kept, removed)
+	}
+}
@@ -0,0 +1,146 @@
+package watch_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLegacyJournal writes entries the way the daemon did before the log was sealed
+func writeLegacyJournal(t *testing.T, path string, entries ...types.ActivityEntry) {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		require.NoError(t, err)
+		buf.Write(append(data, '\n'))
+	}
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestJournal_DaemonSealsEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "inbox")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+	activityPath := filepath.Join(tmpDir, "activity.jsonl")
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.txt", Target: filepath.Join(tmpDir, "docs")}}
+	cfg.Settings.CreateDirs = true
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	daemon.SetActivityFile(activityPath)
+	require.NoError(t, daemon.Start())
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(watchDir, name), []byte(name), 0644))
+	}
+	assert.Eventually(t, func() bool {
+		entries, _ := watch.ReadJournal(activityPath)
+		return len(entries) == 2
+	}, 5*time.Second, 50*time.Millisecond)
+	daemon.Stop()
+
+	check, err := watch.VerifyActivity(activityPath)
+	require.NoError(t, err)
+	assert.True(t, check.OK(), check.Problem)
+	assert.Equal(t, 2, check.Entries)
+	assert.Zero(t, check.Unsealed)
+
+	// Each file's entry carries the trace ID it was handled under
+	entries, err := watch.ReadJournal(activityPath)
+	require.NoError(t, err)
+	assert.NotEmpty(t, entries[0].Trace)
+	assert.NotEmpty(t, entries[1].Trace)
+	assert.NotEqual(t, entries[0].Trace, entries[1].Trace)
+}
+
+func TestJournal_CompactVerifyExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "activity.jsonl")
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	writeLegacyJournal(t, path,
+		types.ActivityEntry{Time: now.AddDate(0, 0, -40), Path: "/in/old.pdf", Destination: "/docs", Source: "rules"},
+		types.ActivityEntry{Time: now.AddDate(0, 0, -3), Path: "/in/a.pdf", Destination: "/docs", Source: "rules"},
+		types.ActivityEntry{Time: now.AddDate(0, 0, -2), Path: "/in/b.pdf", Source: "workflow", Error: "permission denied"},
+		types.ActivityEntry{Time: now.AddDate(0, 0, -1), Path: "/in/c, d.pdf", Destination: "/docs", Source: "rules"},
+	)
+
+	check, err := watch.VerifyActivity(path)
+	require.NoError(t, err)
+	assert.True(t, check.OK())
+	assert.Equal(t, 4, check.Unsealed, "Entries from before sealing are reported, not rejected")
+
+	kept, removed, err := watch.CompactActivity(path, config.JournalSettings{KeepDays: 30, KeepEntries: 2}, now, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, kept)
+	assert.Equal(t, 2, removed)
+
+	check, err = watch.VerifyActivity(path)
+	require.NoError(t, err)
+	assert.True(t, check.OK(), check.Problem)
+	assert.Zero(t, check.Unsealed, "Compaction seals what it keeps")
+
+	entries, err := watch.ReadJournal(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "/in/b.pdf", entries[0].Path)
+
+	var csvOut bytes.Buffer
+	require.NoError(t, watch.ExportActivity(&csvOut, entries, "csv"))
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "time,path,destination,source,error,operation,hash,trace", lines[0])
+	assert.Contains(t, lines[2], `"/in/c, d.pdf"`)
+
+	var jsonOut bytes.Buffer
+	require.NoError(t, watch.ExportActivity(&jsonOut, entries, "json"))
+	var exported []types.ActivityEntry
+	require.NoError(t, json.Unmarshal(jsonOut.Bytes(), &exported))
+	assert.Equal(t, entries, exported)
+
+	assert.Error(t, watch.ExportActivity(&jsonOut, entries, "xml"))
+}
+
+func TestJournal_DetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "activity.jsonl")
+	now := time.Now()
+	writeLegacyJournal(t, path,
+		types.ActivityEntry{Time: now.Add(-3 * time.Hour), Path: "/in/a.pdf", Source: "rules"},
+		types.ActivityEntry{Time: now.Add(-2 * time.Hour), Path: "/in/b.pdf", Source: "rules"},
+		types.ActivityEntry{Time: now.Add(-1 * time.Hour), Path: "/in/c.pdf", Source: "rules"},
+	)
+	_, _, err := watch.CompactActivity(path, config.JournalSettings{KeepEntries: 10}, now, false)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, bytes.Replace(data, []byte("/in/b.pdf"), []byte("/in/x.pdf"), 1), 0644))
+
+	check, err := watch.VerifyActivity(path)
+	require.NoError(t, err)
+	assert.False(t, check.OK())
+	assert.Equal(t, 2, check.Line)
+
+	_, _, err = watch.CompactActivity(path, config.JournalSettings{KeepEntries: 10}, now, false)
+	assert.Error(t, err, "A tampered log must not be re-sealed")
+
+	// Dropping a line breaks the chain too
+	lines := strings.SplitAfter(string(data), "\n")
+	require.NoError(t, os.WriteFile(path, []byte(lines[0]+lines[2]), 0644))
+	check, err = watch.VerifyActivity(path)
+	require.NoError(t, err)
+	assert.False(t, check.OK())
+}
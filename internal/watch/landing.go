@@ -0,0 +1,141 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+	"sortd/internal/digest"
+	"sortd/internal/organize"
+	"sortd/pkg/workflow"
+)
+
+// landingCheckInterval is how often landing zones are checked for overdue files
+var landingCheckInterval = 15 * time.Minute
+
+// runLandingZones periodically checks the landing zones until the daemon stops
+func (d *Daemon) runLandingZones() {
+	ticker := time.NewTicker(landingCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.checkLandingZones(time.Now())
+		}
+	}
+}
+
+// Overdue returns the files that have stayed in a landing zone longer than
+// its max_hours, going by their modification time
+func (d *Daemon) Overdue(now time.Time) []digest.Lingering {
+	return overdueFiles(d.config, d.handles, now)
+}
+
+// Overdue returns the files overdue in cfg's landing zones, checking them
+// against the patterns and the workflows in the default workflows directory
+// the way a daemon would
+func Overdue(cfg *config.Config, now time.Time) []digest.Lingering {
+	if len(cfg.WatchMode.LandingZones) == 0 {
+		return nil
+	}
+
+	d := &Daemon{config: cfg, engine: organize.NewWithConfig(cfg)}
+	if dir, err := workflow.DefaultDir(); err == nil {
+		if manager, err := workflow.NewManager(dir); err == nil {
+			d.workflowManager = manager
+		}
+	}
+	return d.Overdue(now)
+}
+
+// overdueFiles lists the files overdue in cfg's landing zones; handles says
+// whether a rule or workflow would act on one
+func overdueFiles(cfg *config.Config, handles func(string, time.Time) bool, now time.Time) []digest.Lingering {
+	var overdue []digest.Lingering
+	for _, zone := range cfg.WatchMode.LandingZones {
+		entries, err := os.ReadDir(zone.Directory)
+		if err != nil {
+			log.Warnf("Failed to check landing zone %s: %v", zone.Directory, err)
+			continue
+		}
+
+		deadline := time.Duration(zone.MaxHours) * time.Hour
+		for _, entry := range entries {
+			path := filepath.Join(zone.Directory, entry.Name())
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || atomicfile.IsTemp(path) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || now.Sub(info.ModTime()) < deadline {
+				continue
+			}
+			overdue = append(overdue, digest.Lingering{
+				Path:      path,
+				Zone:      zone.Directory,
+				Landed:    info.ModTime(),
+				Age:       now.Sub(info.ModTime()),
+				Unmatched: !handles(path, now),
+			})
+		}
+	}
+	return overdue
+}
+
+// handles reports whether a workflow or pattern would act on the file
+func (d *Daemon) handles(path string, now time.Time) bool {
+	if d.engine.HasMatchingPattern(path) {
+		return true
+	}
+	if d.workflowManager != nil {
+		for _, explanation := range d.workflowManager.Explain(path, now) {
+			if explanation.Matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkLandingZones logs overdue files and alerts through the digest's
+// desktop and webhook channels. Each file is alerted about once while it stays.
+func (d *Daemon) checkLandingZones(now time.Time) {
+	overdue := d.Overdue(now)
+
+	d.mutex.Lock()
+	alerted := make(map[string]bool, len(overdue))
+	var fresh []digest.Lingering
+	for _, lingering := range overdue {
+		alerted[lingering.Path] = true
+		if !d.overdueAlerted[lingering.Path] {
+			fresh = append(fresh, lingering)
+		}
+	}
+	d.overdueAlerted = alerted
+	d.mutex.Unlock()
+
+	if len(fresh) == 0 {
+		return
+	}
+
+	lines := make([]string, len(fresh))
+	for i, lingering := range fresh {
+		lines[i] = lingering.String()
+		log.Warnf("Landing zone overdue: %s", lingering)
+	}
+	alert := digest.Alert{
+		Title: fmt.Sprintf("sortd: %d files overdue in landing zones", len(fresh)),
+		Text:  strings.Join(lines, "\n"),
+	}
+	if err := digest.Notify(d.config.Settings.Digest, alert); err != nil {
+		log.Warnf("Failed to send landing zone alert: %v", err)
+	}
+}
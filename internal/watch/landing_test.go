@@ -0,0 +1,47 @@
+package watch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemon_Overdue(t *testing.T) {
+	inbox := t.TempDir()
+	now := time.Now()
+	write := func(name string, age time.Duration) {
+		path := filepath.Join(inbox, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0644))
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+	write("scan.pdf", 30*time.Hour)
+	write("mystery.bin", 30*time.Hour)
+	write("fresh.bin", time.Hour)
+	write(".hidden", 30*time.Hour)
+
+	cfg := &config.Config{}
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.pdf", Target: "docs"}}
+	cfg.WatchMode.LandingZones = []config.LandingZone{{Directory: inbox, MaxHours: 24}}
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+
+	overdue := daemon.Overdue(now)
+	require.Len(t, overdue, 2, "Fresh and hidden files aren't overdue")
+
+	unmatched := map[string]bool{}
+	for _, lingering := range overdue {
+		assert.Equal(t, inbox, lingering.Zone)
+		assert.GreaterOrEqual(t, lingering.Age, 24*time.Hour)
+		unmatched[filepath.Base(lingering.Path)] = lingering.Unmatched
+	}
+	assert.Equal(t, map[string]bool{"scan.pdf": false, "mystery.bin": true}, unmatched)
+}
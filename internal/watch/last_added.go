@@ -0,0 +1,73 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sortd/internal/config"
+)
+
+// downloadSuffixes mark files browsers are still downloading, whether or not
+// a stability window names them
+var downloadSuffixes = []string{".part", ".crdownload", ".download", ".tmp"}
+
+// LastAdded returns the most recently added file directly inside the watch
+// directories, skipping hidden files, unfinished downloads and files the watch
+// filters exclude. Only files added within the given duration count; zero
+// means any age.
+func LastAdded(cfg *config.Config, within time.Duration) (string, error) {
+	if len(cfg.WatchPaths()) == 0 {
+		return "", fmt.Errorf("no watch directories are configured")
+	}
+
+	var newest string
+	var newestTime time.Time
+	for _, dir := range cfg.WatchPaths() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // A missing watch directory shouldn't hide the others
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			path := filepath.Join(dir, name)
+			if entry.IsDir() || strings.HasPrefix(name, ".") || isDownloading(cfg, path) || !filtersAllow(cfg, path) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			if info.ModTime().After(newestTime) {
+				newest, newestTime = path, info.ModTime()
+			}
+		}
+	}
+
+	if newest == "" {
+		return "", fmt.Errorf("no files found in the watch directories")
+	}
+	if within > 0 && time.Since(newestTime) > within {
+		return "", fmt.Errorf("no file was added in the last %s (newest is %s)", within, filepath.Base(newest))
+	}
+	return newest, nil
+}
+
+// isDownloading reports whether the file, or a temporary sibling of it, is
+// still being written
+func isDownloading(cfg *config.Config, path string) bool {
+	if hasTempSuffix(cfg, path) {
+		return true
+	}
+	for _, suffix := range downloadSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+		if _, err := os.Stat(path + suffix); err == nil {
+			return true
+		}
+	}
+	return false
+}
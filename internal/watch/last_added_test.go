@@ -0,0 +1,40 @@
+package watch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/watch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastAdded(t *testing.T) {
+	downloads := t.TempDir()
+	scans := t.TempDir()
+	cfg := config.New()
+	cfg.WatchDirectories = []string{downloads, scans}
+	cfg.WatchMode.Filters = []config.WatchFilter{{Directory: scans, Include: []string{"*.pdf"}}}
+
+	now := time.Now()
+	write := func(path string, age time.Duration) {
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+	write(filepath.Join(downloads, "report.pdf"), 3*time.Minute)
+	write(filepath.Join(scans, "scan.pdf"), 2*time.Minute)
+	write(filepath.Join(scans, "notes.txt"), time.Minute)          // Excluded by the filter
+	write(filepath.Join(downloads, ".hidden"), time.Second)        // Hidden
+	write(filepath.Join(downloads, "movie.mp4.part"), time.Second) // Still downloading
+
+	path, err := watch.LastAdded(cfg, 0)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(scans, "scan.pdf"), path)
+
+	_, err = watch.LastAdded(cfg, time.Minute)
+	assert.Error(t, err, "nothing was added in the last minute")
+}
@@ -0,0 +1,26 @@
+package watch
+
+import (
+	"net"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/httpsec"
+)
+
+// listen opens the listener of one of the daemon's network endpoints, bound
+// and served over TLS as watch_mode.http says. token is the one the endpoint
+// checks. The generated certificate is kept in the state directory. Only
+// allow_insecure lets an endpoint reachable from the network go without
+// authentication.
+func (d *Daemon) listen(name, addr, token string) (net.Listener, error) {
+	settings := d.config.WatchMode.HTTP
+	listener, err := httpsec.Listen(addr, token, settings, d.config.StatePath())
+	if err != nil {
+		return nil, err
+	}
+	if !httpsec.Authenticated(token, settings) && !httpsec.IsLoopback(listener.Addr().String()) {
+		log.Warnf("The %s on %s is reachable from the network without authentication, as allow_insecure allows", name, listener.Addr())
+	}
+	return listener, nil
+}
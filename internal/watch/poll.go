@@ -0,0 +1,147 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultPollInterval is how often the poll backend rescans by default
+const defaultPollInterval = 5 * time.Second
+
+// polledFile is what the poll backend remembers about a file between scans
+type polledFile struct {
+	size    int64
+	modTime time.Time
+}
+
+// usePolling reports whether the daemon rescans directories instead of relying
+// on change events, which network shares and container volume mounts from
+// macOS or Windows hosts often don't deliver
+func (d *Daemon) usePolling() bool {
+	return d.config.WatchMode.Backend == "poll"
+}
+
+// pollsDirectory reports whether a directory is rescanned, as its watch
+// directory entry or, failing that, watch_mode.backend says
+func (d *Daemon) pollsDirectory(dir string) bool {
+	if entry, ok := d.config.WatchDirFor(dir); ok && entry.Backend != "" {
+		return entry.Backend == "poll"
+	}
+	return d.usePolling()
+}
+
+// pollInterval returns the configured rescan interval
+func (d *Daemon) pollInterval() time.Duration {
+	if d.config.WatchMode.PollSeconds > 0 {
+		return time.Duration(d.config.WatchMode.PollSeconds) * time.Second
+	}
+	return defaultPollInterval
+}
+
+// addPollDirectory starts polling a directory, failing if it can't be read
+func (d *Daemon) addPollDirectory(dir string) error {
+	if _, err := os.ReadDir(dir); err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, existing := range d.pollDirs {
+		if existing == dir {
+			return nil
+		}
+	}
+	d.pollDirs = append(d.pollDirs, dir)
+	return nil
+}
+
+// watchList returns the directories being watched by either backend
+func (d *Daemon) watchList() []string {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.watchListLocked()
+}
+
+// watchListLocked is watchList for callers already holding d.mutex
+func (d *Daemon) watchListLocked() []string {
+	return append(d.watcher.WatchList(), d.pollDirs...)
+}
+
+// pollList returns the directories the poll backend rescans
+func (d *Daemon) pollList() []string {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return append([]string(nil), d.pollDirs...)
+}
+
+// startPolling remembers the files already present, without processing them
+// (matching fsnotify), then rescans the watched directories until the daemon
+// stops. The baseline is taken before returning so files created right after
+// Start are reported.
+func (d *Daemon) startPolling() {
+	seen := make(map[string]polledFile)
+	d.pollOnce(seen, false)
+
+	d.pollWg.Add(1)
+	go d.guard("polling", func() { d.pollLoop(seen) })
+}
+
+// pollLoop rescans the watched directories every poll interval
+func (d *Daemon) pollLoop(seen map[string]polledFile) {
+	defer d.pollWg.Done()
+
+	ticker := time.NewTicker(d.pollInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.pollOnce(seen, true)
+		}
+	}
+}
+
+// pollOnce scans the directories, reporting files that are new or changed
+// since the last scan when report is set
+func (d *Daemon) pollOnce(seen map[string]polledFile, report bool) {
+	current := make(map[string]bool)
+	for _, dir := range d.pollList() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			log.Warnf("Error scanning watch directory %s: %v", dir, err)
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				d.watchNewDirectory(filepath.Join(dir, entry.Name()))
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			current[path] = true
+			state := polledFile{size: info.Size(), modTime: info.ModTime()}
+			if previous, ok := seen[path]; ok && previous == state {
+				continue
+			}
+			seen[path] = state
+			if report {
+				log.Debugf("Poll found new or changed file: %s", path)
+				d.handleEvent(path)
+			}
+		}
+	}
+
+	for path := range seen {
+		if !current[path] {
+			delete(seen, path)
+		}
+	}
+}
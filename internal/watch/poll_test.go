@@ -0,0 +1,232 @@
+package watch_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemon_PollBackendMovesNewFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "inbox")
+	destDir := filepath.Join(tmpDir, "docs")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	// Files present at startup are left alone, as with fsnotify
+	existing := filepath.Join(watchDir, "old.txt")
+	require.NoError(t, os.WriteFile(existing, []byte("old"), 0644))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.WatchMode.Backend = "poll"
+	cfg.WatchMode.PollSeconds = 1
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.txt", Target: destDir}}
+	cfg.Settings.CreateDirs = true
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	assert.Equal(t, []string{watchDir}, daemon.Status().WatchDirectories)
+
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "new.txt"), []byte("new"), 0644))
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(destDir, "new.txt"))
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond, "New file should be organized on the next poll")
+
+	_, err = os.Stat(existing)
+	assert.NoError(t, err, "Files present at startup should not be moved")
+}
+
+func TestDaemon_HealthEndpoint(t *testing.T) {
+	watchDir := t.TempDir()
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.WatchMode.Backend = "poll"
+	cfg.WatchMode.HealthListen = "127.0.0.1:0"
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	resp, err := http.Get("http://" + daemon.HealthAddr() + watch.HealthPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var body struct {
+		Status           string   `json:"status"`
+		Backend          string   `json:"backend"`
+		WatchDirectories []string `json:"watch_directories"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "ok", body.Status)
+	assert.Equal(t, "poll", body.Backend)
+	assert.Equal(t, []string{watchDir}, body.WatchDirectories)
+}
+
+func TestDaemon_WebUI(t *testing.T) {
+	watchDir := t.TempDir()
+
+	cfg := &config.Config{}
+	cfg.Directories.Default = t.TempDir()
+	keepStateIn(t, cfg.Directories.Default)
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.WatchMode.Backend = "poll"
+	cfg.WatchMode.WebListen = "127.0.0.1:0"
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+	base := "http://" + daemon.WebAddr()
+
+	// The page itself is embedded
+	resp, err := http.Get(base + "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+
+	resp, err = http.Get(base + "/api/status")
+	require.NoError(t, err)
+	var status struct {
+		Running          bool     `json:"running"`
+		WatchDirectories []string `json:"watch_directories"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	resp.Body.Close()
+	assert.True(t, status.Running)
+	assert.Equal(t, []string{watchDir}, status.WatchDirectories)
+
+	for _, path := range []string{"/api/activity", "/api/pending"} {
+		resp, err = http.Get(base + path)
+		require.NoError(t, err)
+		var items []json.RawMessage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&items), path)
+		resp.Body.Close()
+		assert.NotNil(t, items, "%s should answer with a list", path)
+	}
+
+	// Nothing can be changed through it
+	resp, err = http.Post(base+"/api/pending", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestDaemon_WebUIToken(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Directories.Default = t.TempDir()
+	keepStateIn(t, cfg.Directories.Default)
+	cfg.WatchDirectories = []string{t.TempDir()}
+	cfg.WatchMode.Backend = "poll"
+	cfg.WatchMode.WebListen = ":0"
+	cfg.WatchMode.HealthListen = ":0"
+	cfg.WatchMode.HTTP.Token = "secret"
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	// A bare port binds to localhost only
+	assert.Contains(t, daemon.WebAddr(), "127.0.0.1:")
+	base := "http://" + daemon.WebAddr()
+
+	resp, err := http.Get(base + "/api/status")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = http.Get("http://" + daemon.HealthAddr() + watch.HealthPath)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Opening the page with the token signs the browser in with a cookie
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	browser := &http.Client{Jar: jar}
+	resp, err = browser.Get(base + "/?token=secret")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Request.URL.RawQuery, "the token should be dropped from the address")
+
+	resp, err = browser.Get(base + "/api/status")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = browser.Get(base + "/?token=wrong")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the cookie still counts")
+	fresh, err := http.Get(base + "/?token=wrong")
+	require.NoError(t, err)
+	fresh.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, fresh.StatusCode)
+}
+
+func TestDaemon_WatchDirEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	tree := filepath.Join(tmpDir, "tree")
+	share := filepath.Join(tmpDir, "share")
+	destDir := filepath.Join(tmpDir, "docs")
+	require.NoError(t, os.MkdirAll(filepath.Join(tree, "sub"), 0755))
+	require.NoError(t, os.Mkdir(share, 0755))
+
+	cfg := &config.Config{}
+	cfg.Directories.Watch = []config.WatchDir{
+		{Path: tree, Recursive: true, Exclude: []string{"draft*"}},
+		{Path: share, Backend: "poll"},
+	}
+	cfg.WatchMode.PollSeconds = 1
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.txt", Target: destDir}}
+	cfg.Settings.CreateDirs = true
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	assert.ElementsMatch(t, []string{tree, filepath.Join(tree, "sub"), share}, daemon.Status().WatchDirectories)
+
+	moved := func(name string) func() bool {
+		return func() bool {
+			_, err := os.Stat(filepath.Join(destDir, name))
+			return err == nil
+		}
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(tree, "sub", "a.txt"), []byte("a"), 0644))
+	assert.Eventually(t, moved("a.txt"), 5*time.Second, 100*time.Millisecond, "Subdirectories of recursive entries are watched")
+
+	require.NoError(t, os.Mkdir(filepath.Join(tree, "new"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tree, "new", "b.txt"), []byte("b"), 0644))
+	assert.Eventually(t, moved("b.txt"), 5*time.Second, 100*time.Millisecond, "New subdirectories are watched too")
+
+	require.NoError(t, os.WriteFile(filepath.Join(share, "c.txt"), []byte("c"), 0644))
+	assert.Eventually(t, moved("c.txt"), 5*time.Second, 100*time.Millisecond, "An entry's backend overrides the default")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tree, "draft.txt"), []byte("d"), 0644))
+	time.Sleep(500 * time.Millisecond)
+	assert.FileExists(t, filepath.Join(tree, "draft.txt"), "An entry's excludes apply to its files")
+}
@@ -0,0 +1,128 @@
+package watch
+
+import (
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/config"
+	"sortd/internal/power"
+)
+
+// powerCheckInterval is how often the daemon checks what the machine runs on
+var powerCheckInterval = 30 * time.Second
+
+// currentPower reads the machine's power and network state; a variable so
+// tests can fake it
+var currentPower = power.Current
+
+// Reasons work is held back, as shown by 'sortd daemon status'
+const (
+	pauseBattery = "on battery"
+	pauseSaver   = "battery saver"
+	pauseMetered = "metered connection"
+)
+
+// PauseReason returns why a daemon running cfg holds work back in state, or
+// "" when it doesn't. On battery or in battery saver, watch_mode.pause_on_battery
+// leaves every file alone; on a metered connection, pause_on_metered holds
+// back only moves to network file systems.
+func PauseReason(cfg *config.Config, state power.State) string {
+	switch {
+	case cfg.WatchMode.PauseOnBattery && state.OnBattery:
+		return pauseBattery
+	case cfg.WatchMode.PauseOnBattery && state.Saver:
+		return pauseSaver
+	case cfg.WatchMode.PauseOnMetered && state.Metered:
+		return pauseMetered
+	}
+	return ""
+}
+
+// watchesPower reports whether the daemon pauses for the battery or the network
+func (d *Daemon) watchesPower() bool {
+	return d.config.WatchMode.PauseOnBattery || d.config.WatchMode.PauseOnMetered
+}
+
+// runPowerChecks follows the machine's power and network state until the
+// daemon stops
+func (d *Daemon) runPowerChecks() {
+	ticker := time.NewTicker(powerCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.updatePower(currentPower())
+		}
+	}
+}
+
+// updatePower records the machine's state. When the reason to pause changes,
+// the files held back go to the workers again; those the new state still
+// holds back are simply held again.
+func (d *Daemon) updatePower(state power.State) {
+	d.mutex.Lock()
+	before := PauseReason(d.config, d.power)
+	d.power = state
+	after := PauseReason(d.config, state)
+	var held []string
+	if after != before {
+		for path := range d.heldBack {
+			held = append(held, path)
+		}
+		d.heldBack = nil
+	}
+	d.mutex.Unlock()
+
+	switch {
+	case after == before:
+		return
+	case after == "":
+		log.Infof("No longer %s: resuming, %d held back files to handle", before, len(held))
+	case after == pauseMetered:
+		log.Infof("On a metered connection: holding back moves to network file systems")
+	default:
+		log.Infof("Running %s: leaving files alone until back on AC power", after)
+	}
+
+	for _, path := range held {
+		if _, err := os.Stat(path); err == nil {
+			d.queueEvent(path)
+		}
+	}
+}
+
+// batteryPause returns why every file is left alone for now, or ""
+func (d *Daemon) batteryPause() string {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	if reason := PauseReason(d.config, d.power); reason != pauseMetered {
+		return reason
+	}
+	return ""
+}
+
+// meteredPause reports whether a move to destDir waits for an unmetered connection
+func (d *Daemon) meteredPause(destDir string) bool {
+	d.mutex.RLock()
+	metered := d.config.WatchMode.PauseOnMetered && d.power.Metered
+	d.mutex.RUnlock()
+	return metered && power.IsRemote(destDir)
+}
+
+// holdBack keeps a file until the pause ends
+func (d *Daemon) holdBack(path, reason string) {
+	d.mutex.Lock()
+	if d.heldBack == nil {
+		d.heldBack = make(map[string]bool)
+	}
+	d.heldBack[path] = true
+	d.mutex.Unlock()
+
+	d.logFor(path).Infof("Holding back %s (%s)", path, reason)
+	d.recordStat(path, statSkipped)
+}
@@ -0,0 +1,68 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sortd/internal/config"
+	"sortd/internal/power"
+	"sortd/pkg/types"
+)
+
+func TestPauseReason(t *testing.T) {
+	cfg := &config.Config{}
+	assert.Empty(t, PauseReason(cfg, power.State{OnBattery: true, Metered: true}), "Off unless configured")
+
+	cfg.WatchMode.PauseOnBattery = true
+	cfg.WatchMode.PauseOnMetered = true
+	assert.Equal(t, pauseBattery, PauseReason(cfg, power.State{OnBattery: true, Metered: true}))
+	assert.Equal(t, pauseSaver, PauseReason(cfg, power.State{Saver: true}))
+	assert.Equal(t, pauseMetered, PauseReason(cfg, power.State{Metered: true}))
+	assert.Empty(t, PauseReason(cfg, power.State{}))
+}
+
+func TestDaemonHoldsBackFilesOnBattery(t *testing.T) {
+	state := power.State{OnBattery: true}
+	old := currentPower
+	currentPower = func() power.State { return state }
+	t.Cleanup(func() { currentPower = old })
+
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "inbox")
+	docsDir := filepath.Join(tmpDir, "docs")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir}
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.txt", Target: docsDir}}
+	cfg.Settings.CreateDirs = true
+	cfg.WatchMode.PauseOnBattery = true
+
+	daemon, err := NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+	assert.Equal(t, pauseBattery, daemon.Status().Paused)
+
+	file := filepath.Join(watchDir, "notes.txt")
+	require.NoError(t, os.WriteFile(file, []byte("notes"), 0644))
+	assert.Eventually(t, func() bool {
+		daemon.mutex.RLock()
+		defer daemon.mutex.RUnlock()
+		return daemon.heldBack[file]
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.FileExists(t, file, "Nothing moves on battery")
+
+	// Back on AC, the held back file is organized
+	daemon.updatePower(power.State{})
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(docsDir, "notes.txt"))
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Empty(t, daemon.Status().Paused)
+}
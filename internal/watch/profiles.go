@@ -0,0 +1,80 @@
+package watch
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// directoryProfile returns the config profile a watch directory belongs to,
+// "" for none
+func (d *Daemon) directoryProfile(dir string) string {
+	if entry, ok := d.config.WatchDirFor(dir); ok {
+		return entry.Profile
+	}
+	return ""
+}
+
+// deactivateDirectory marks a directory as not watched until its profile is active
+func (d *Daemon) deactivateDirectory(dir string, now time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.watchers[dir] = &supervisedDir{
+		status: WatcherStatus{Directory: dir, State: WatcherInactive, Since: now},
+	}
+}
+
+// hasInactiveDirectories reports whether any directory waits for its profile
+func (d *Daemon) hasInactiveDirectories() bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	for _, sup := range d.watchers {
+		if sup.status.State == WatcherInactive {
+			return true
+		}
+	}
+	return false
+}
+
+// pollsInactiveDirectory reports whether a directory waiting for its profile
+// uses the poll backend, so polling runs once it is added
+func (d *Daemon) pollsInactiveDirectory() bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	for dir, sup := range d.watchers {
+		if sup.status.State == WatcherInactive && d.pollsDirectory(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// profileChanged follows a supervised directory's profile: once it's no longer
+// active the directory stops being watched, or retried, and once it's active
+// again watching starts over. It reports whether the directory was handled.
+func (d *Daemon) profileChanged(dir, state string, now time.Time) bool {
+	profile := d.directoryProfile(dir)
+	active := d.engine.Profiles().Active(profile)
+
+	switch {
+	case !active && state != WatcherInactive:
+		if state == WatcherWatching {
+			d.removeDirectory(dir)
+		}
+		d.deactivateDirectory(dir, now)
+		log.Infof("Profile %s isn't active anymore: no longer watching %s", profile, dir)
+		return true
+	case active && state == WatcherInactive:
+		if err := d.addDirectory(dir); err != nil {
+			d.watcherLost(dir, err, now)
+			return true
+		}
+		d.superviseDirectory(dir)
+		d.engine.SetRoots(d.watchList())
+		log.Infof("Profile %s is active: watching %s", profile, dir)
+		return true
+	case !active:
+		return true
+	}
+	return false
+}
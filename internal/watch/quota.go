@@ -0,0 +1,116 @@
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/digest"
+	"sortd/internal/quota"
+)
+
+// setupQuotas shares the engine's quota checker with the workflows, so that
+// rules and workflows count towards the same usage, and answers violations
+func (d *Daemon) setupQuotas() {
+	quotas := d.engine.Quotas()
+	if quotas == nil {
+		return
+	}
+	quotas.SetHandler(d.handleQuota)
+	if d.workflowManager != nil {
+		d.workflowManager.SetQuotas(quotas)
+	}
+}
+
+// handleQuota answers a destination directory going over its quota: the
+// workflow action archives its oldest files, the others notify
+func (d *Daemon) handleQuota(violation quota.Violation) {
+	log.Warnf("Quota exceeded: %s", violation)
+
+	if violation.Limit.Action() == quota.ActionWorkflow {
+		go d.relieveQuota(violation.Limit)
+		return
+	}
+
+	text := violation.String()
+	if violation.Limit.Action() == quota.ActionStop {
+		text += "; files routed there are left where they are until it has room"
+	}
+	alert := digest.Alert{Title: "sortd: destination over quota", Text: text}
+	if err := digest.Notify(d.config.Settings.Digest, alert); err != nil {
+		log.Warnf("Failed to send quota alert: %v", err)
+	}
+}
+
+// relieveQuota runs the quota's workflow on the files in its directory,
+// oldest first, until the directory is back under its quota
+func (d *Daemon) relieveQuota(limit quota.Limit) {
+	if d.workflowManager == nil {
+		log.Warnf("Can't run workflow %s for the quota on %s: workflows aren't loaded", limit.Workflow, limit.Path)
+		return
+	}
+
+	quotas := d.engine.Quotas()
+	usage, err := quotas.Usage(limit)
+	if err != nil {
+		log.Warnf("Failed to measure %s: %v", limit.Path, err)
+		return
+	}
+
+	relieved, failed := 0, 0
+	for _, file := range oldestFiles(limit.Path) {
+		if !limit.Exceeded(usage) {
+			break
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if result, err := d.workflowManager.ExecuteWorkflow(limit.Workflow, file); err != nil || !result.Success {
+			failed++
+			continue
+		}
+		// Only files the workflow took out of the directory make room
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			usage.Size -= info.Size()
+			usage.Files--
+			relieved++
+		}
+	}
+
+	// Measure afresh, which also lets the next violation be reported
+	if usage, err = quotas.Usage(limit); err == nil && limit.Exceeded(usage) {
+		log.Warnf("%s is still over its quota after workflow %s (%d files moved out, %d failed)",
+			limit.Path, limit.Workflow, relieved, failed)
+		return
+	}
+	log.Infof("Workflow %s moved %d files out of %s, which is back under its quota", limit.Workflow, relieved, limit.Path)
+}
+
+// oldestFiles lists the files under dir, least recently modified first
+func oldestFiles(dir string) []string {
+	type aged struct {
+		path string
+		info fs.FileInfo
+	}
+	var files []aged
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			files = append(files, aged{path, info})
+		}
+		return nil
+	})
+
+	sort.Slice(files, func(i, j int) bool { return files[i].info.ModTime().Before(files[j].info.ModTime()) })
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.path
+	}
+	return paths
+}
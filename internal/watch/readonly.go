@@ -0,0 +1,67 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/config"
+	"sortd/internal/errors"
+)
+
+// readOnlyFile marks read-only mode for every sortd process sharing a config
+const readOnlyFile = "readonly"
+
+// ReadOnlyPath returns the path of the marker file that turns read-only mode on
+func ReadOnlyPath(cfg *config.Config) string {
+	return cfg.StateFile(readOnlyFile)
+}
+
+// ReadOnly reports whether read-only mode is on, either from settings.read_only
+// or settings.audit or because it was switched on at runtime with SetReadOnly
+func ReadOnly(cfg *config.Config) bool {
+	if cfg.Settings.ReadOnly || cfg.Settings.Audit {
+		return true
+	}
+	_, err := os.Stat(ReadOnlyPath(cfg))
+	return err == nil
+}
+
+// SetReadOnly switches runtime read-only mode on or off for all sortd
+// processes, including running daemons, which check it before every file.
+// It can't switch off read-only mode set in the config file.
+func SetReadOnly(cfg *config.Config, readOnly bool) error {
+	path := ReadOnlyPath(cfg)
+	if !readOnly {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.NewOSFileError("failed to remove read-only marker", path, errors.FileOperationFailed, err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.NewOSFileError("failed to create directory", filepath.Dir(path), errors.FileCreateFailed, err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		return errors.NewOSFileError("failed to write read-only marker", path, errors.FileCreateFailed, err)
+	}
+	return nil
+}
+
+// SetReadOnly pauses or resumes this daemon alone. While paused, events are
+// still seen but no file is moved and no workflow runs.
+func (d *Daemon) SetReadOnly(readOnly bool) {
+	d.readOnly.Store(readOnly)
+	d.engine.SetReadOnly(readOnly)
+	if readOnly {
+		log.Info("Read-only mode on: files will be left where they are")
+	} else {
+		log.Info("Read-only mode off")
+	}
+}
+
+// ReadOnly reports whether the daemon is paused, on its own or for all processes
+func (d *Daemon) ReadOnly() bool {
+	return d.readOnly.Load() || ReadOnly(d.config)
+}
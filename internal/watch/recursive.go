@@ -0,0 +1,84 @@
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// addSubdirectories watches every directory below root, skipping hidden ones
+// when settings.ignore_hidden is on. A subdirectory that can't be watched is
+// logged rather than failing the whole tree.
+func (d *Daemon) addSubdirectories(root string) {
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			log.Warnf("Error walking watch directory %s: %v", path, err)
+			return nil
+		}
+		if !entry.IsDir() || path == root {
+			return nil
+		}
+		if d.config.Settings.IgnoreHidden && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := d.addSingleDirectory(path); err != nil {
+			log.Warnf("Error watching subdirectory %s: %v", path, err)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warnf("Error walking watch directory %s: %v", root, err)
+	}
+}
+
+// watchNewDirectory starts watching a directory that appeared inside a
+// recursive watch directory. Change events don't report files that arrive
+// inside a moved-in directory, so those already there are handled now; the
+// poll backend finds them on its next scan instead.
+func (d *Daemon) watchNewDirectory(dir string) {
+	entry, ok := d.config.WatchDirFor(dir)
+	if !ok || !entry.Recursive || filepath.Clean(entry.Path) == filepath.Clean(dir) || d.isWatching(dir) {
+		return
+	}
+	if d.config.Settings.IgnoreHidden && strings.HasPrefix(filepath.Base(dir), ".") {
+		return
+	}
+
+	if err := d.addDirectory(dir); err != nil {
+		log.Warnf("Error watching new subdirectory %s: %v", dir, err)
+		return
+	}
+	log.Infof("Watching new subdirectory: %s", dir)
+
+	if d.pollsDirectory(dir) {
+		return
+	}
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			d.handleEvent(path)
+		}
+		return nil
+	})
+}
+
+// isWatching reports whether a directory is already watched by either backend
+func (d *Daemon) isWatching(dir string) bool {
+	for _, watched := range d.watchList() {
+		if watched == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// isBelow reports whether path lies somewhere inside dir
+func isBelow(path, dir string) bool {
+	return strings.HasPrefix(filepath.Clean(path), filepath.Clean(dir)+string(os.PathSeparator))
+}
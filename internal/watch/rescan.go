@@ -0,0 +1,103 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// rescanInterval returns how often directories watched through change events
+// are rescanned, or 0 when they aren't
+func (d *Daemon) rescanInterval() time.Duration {
+	return time.Duration(d.config.WatchMode.IntervalSeconds) * time.Second
+}
+
+// startRescans remembers the files already present, without processing them,
+// then rescans the directories watched through change events every interval
+// until the daemon stops
+func (d *Daemon) startRescans() {
+	d.rescanMu.Lock()
+	d.rescanSeen = make(map[string]polledFile)
+	d.rescanMu.Unlock()
+	d.rescanOnce(false)
+
+	d.pollWg.Add(1)
+	go d.guard("rescanning", func() {
+		defer d.pollWg.Done()
+
+		ticker := time.NewTicker(d.rescanInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stopCh:
+				return
+			case <-ticker.C:
+				d.rescanOnce(true)
+			}
+		}
+	})
+}
+
+// noteSeen records the state of a file a change event reported, so the next
+// rescan doesn't handle it again
+func (d *Daemon) noteSeen(path string, info os.FileInfo) {
+	d.rescanMu.Lock()
+	defer d.rescanMu.Unlock()
+	if d.rescanSeen != nil {
+		d.rescanSeen[path] = polledFile{size: info.Size(), modTime: info.ModTime()}
+	}
+}
+
+// rescanOnce lists the directories watched through change events, handling
+// the files that are new or changed since events or the last rescan saw them
+// when report is set
+func (d *Daemon) rescanOnce(report bool) {
+	d.mutex.RLock()
+	dirs := d.watcher.WatchList()
+	d.mutex.RUnlock()
+
+	var found []string
+	current := make(map[string]bool)
+	d.rescanMu.Lock()
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			log.Warnf("Error rescanning watch directory %s: %v", dir, err)
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			current[path] = true
+			state := polledFile{size: info.Size(), modTime: info.ModTime()}
+			if previous, ok := d.rescanSeen[path]; ok && previous == state {
+				continue
+			}
+			d.rescanSeen[path] = state
+			found = append(found, path)
+		}
+	}
+	for path := range d.rescanSeen {
+		if !current[path] {
+			delete(d.rescanSeen, path)
+		}
+	}
+	d.rescanMu.Unlock()
+
+	if !report {
+		return
+	}
+	for _, path := range found {
+		log.Debugf("Rescan found a file change events missed: %s", path)
+		d.handleEvent(path)
+	}
+}
@@ -0,0 +1,41 @@
+package watch
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/trace"
+)
+
+// runScheduledWorkflows runs workflows with scheduled triggers at the start of
+// every minute until the daemon stops
+func (d *Daemon) runScheduledWorkflows() {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(next.Sub(now))
+
+		select {
+		case <-d.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+			d.sweepScheduled(next)
+		}
+	}
+}
+
+// sweepScheduled runs the scheduled workflows due at now over the watched
+// directories and records what they did
+func (d *Daemon) sweepScheduled(now time.Time) {
+	if reason := d.batteryPause(); reason != "" {
+		log.Debugf("Skipping scheduled workflows: %s", reason)
+		return
+	}
+	for _, result := range d.workflowManager.RunScheduled(now, d.config.WatchPaths()) {
+		log.WithField(trace.Field, result.Trace).
+			Infof("Scheduled workflow %s on %s: %s", result.WorkflowID, result.FilePath, result.Message)
+		d.recordWorkflowResult(result)
+	}
+}
@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/screenshot"
+	"sortd/internal/trace"
+	"sortd/pkg/types"
+)
+
+// organizeScreenshot files a screenshot under its {date}-{app} name in its
+// month folder when settings.screenshots is on, ahead of any pattern. It
+// reports whether it took care of the file; screenshots already in the
+// screenshots folder are left where they are.
+func (d *Daemon) organizeScreenshot(filePath string) bool {
+	if !screenshot.Enabled(d.config) {
+		return false
+	}
+	if screenshot.InFolder(d.config, filePath) {
+		d.recordStat(filePath, statSkipped)
+		return true
+	}
+	info, ok := screenshot.Detect(filePath, d.config.Settings.Screenshots.OCR)
+	if !ok {
+		return false
+	}
+
+	dest := screenshot.Destination(d.config, info, nil)
+	ctx := trace.With(context.Background(), d.traceOf(filePath))
+	final := ""
+	attempts, err := d.retryTransient(filePath, func() error {
+		var err error
+		final, err = d.engine.MoveFileTo(ctx, filePath, dest)
+		return err
+	})
+
+	entry := types.ActivityEntry{
+		Time:        time.Now(),
+		Path:        filePath,
+		Destination: filepath.Dir(dest),
+		Source:      activitySourceScreenshot,
+		Trace:       d.traceOf(filePath),
+		Operation:   types.OperationID(filePath, dest, ""),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	d.writeActivity(entry)
+
+	switch {
+	case err != nil:
+		log.Errorf("Error filing screenshot %s: %v", filePath, err)
+		d.recordStat(filePath, statError)
+		d.recordFailure(filePath, activitySourceScreenshot, err, attempts)
+	case final == "":
+		// A collision that was skipped or queued for the user
+		d.recordStat(filePath, statSkipped)
+	default:
+		d.logFor(filePath).Infof("Filed screenshot %s as %s", filePath, final)
+		d.mutex.Lock()
+		d.processed++
+		d.mutex.Unlock()
+		d.recordStat(filePath, statOrganized)
+	}
+
+	d.mutex.RLock()
+	cb := d.callback
+	d.mutex.RUnlock()
+	if cb != nil {
+		cb(filePath, final, err)
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+	"sortd/internal/globs"
+	"sortd/internal/openfiles"
+	"sortd/internal/trace"
+)
+
+// defaultStabilityMaxWait bounds how long a file may take to settle when its
+// stability window doesn't set max_wait_seconds
+const defaultStabilityMaxWait = 10 * time.Minute
+
+// stabilityPollInterval is how often a settling file is re-checked
+var stabilityPollInterval = 250 * time.Millisecond
+
+// openCheckInterval spaces out the checks for other processes having a
+// settling file open, which look through every process
+var openCheckInterval = time.Second
+
+// stabilityWindowFor returns the first stability window matching the file name,
+// or nil. Files in a watch directory with debounce_seconds wait in a window of
+// their own when none matches, as do all files with watch_mode.wait_for_close.
+func (d *Daemon) stabilityWindowFor(path string) *config.StabilityWindow {
+	if d.config == nil {
+		return nil
+	}
+
+	for _, window := range d.config.WatchMode.Stability {
+		if matched, err := globs.Match(window.Pattern, path); err == nil && matched {
+			window.WaitForClose = window.WaitForClose || d.config.WatchMode.WaitForClose
+			return &window
+		}
+	}
+	if dir, ok := d.config.WatchDirFor(filepath.Dir(path)); ok && dir.DebounceSeconds > 0 {
+		return &config.StabilityWindow{Pattern: "*", StableSeconds: dir.DebounceSeconds, WaitForClose: d.config.WatchMode.WaitForClose}
+	}
+	if d.config.WatchMode.WaitForClose {
+		return &config.StabilityWindow{Pattern: "*", WaitForClose: true}
+	}
+	return nil
+}
+
+// isTempFile reports whether the file is being staged by sortd or carries a
+// temporary suffix from any stability window. Such files are still being written
+// and will be renamed when complete.
+func (d *Daemon) isTempFile(path string) bool {
+	if atomicfile.IsTemp(path) {
+		return true
+	}
+	if d.config == nil {
+		return false
+	}
+	return hasTempSuffix(d.config, path)
+}
+
+// hasTempSuffix reports whether the path ends in a temporary suffix named by
+// a stability window
+func hasTempSuffix(cfg *config.Config, path string) bool {
+	for _, window := range cfg.WatchMode.Stability {
+		for _, suffix := range window.TempSuffixes {
+			if suffix != "" && strings.HasSuffix(path, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// queueWhenStable waits in the background for the file to satisfy its stability
+// window before handing it to the workers. Repeated events for a file that is
+// already settling are absorbed by the running check.
+func (d *Daemon) queueWhenStable(path string, window config.StabilityWindow) {
+	d.mutex.Lock()
+	if d.settling[path] {
+		d.mutex.Unlock()
+		log.Debugf("File already waiting to settle: %s", path)
+		return
+	}
+	d.settling[path] = true
+	d.mutex.Unlock()
+
+	d.settleWg.Add(1)
+	go func() {
+		defer d.settleWg.Done()
+		defer func() {
+			d.mutex.Lock()
+			delete(d.settling, path)
+			d.mutex.Unlock()
+		}()
+
+		if d.waitUntilStable(path, window) {
+			d.queueEvent(path)
+		}
+	}()
+}
+
+// waitUntilStable polls the file until it has been unchanged for the window's
+// stable period, no temporary sibling remains and (optionally) no lock is held
+// and no other process has it open.
+// It returns false if the file disappears, the wait times out or the daemon stops.
+func (d *Daemon) waitUntilStable(path string, window config.StabilityWindow) bool {
+	maxWait := time.Duration(window.MaxWaitSeconds) * time.Second
+	if maxWait <= 0 {
+		maxWait = defaultStabilityMaxWait
+	}
+	stableFor := time.Duration(window.StableSeconds) * time.Second
+	deadline := time.Now().Add(maxWait)
+
+	ticker := time.NewTicker(stabilityPollInterval)
+	defer ticker.Stop()
+
+	var lastSize int64 = -1
+	var lastMod, unchangedSince, openChecked time.Time
+	open := false
+	for {
+		info, err := os.Stat(path)
+		if err != nil {
+			// Most likely renamed or removed by its producer; a new event will follow
+			log.Debugf("Settling file vanished %s: %v", path, err)
+			return false
+		}
+
+		if info.Size() != lastSize || !info.ModTime().Equal(lastMod) {
+			lastSize = info.Size()
+			lastMod = info.ModTime()
+			unchangedSince = time.Now()
+		}
+
+		if window.WaitForClose && time.Since(openChecked) >= openCheckInterval {
+			open = d.isOpenElsewhere(path)
+			openChecked = time.Now()
+		}
+
+		if time.Since(unchangedSince) >= stableFor &&
+			!tempSiblingExists(path, window.TempSuffixes) &&
+			(!window.WaitForLock || !isLocked(path)) &&
+			!open {
+			log.Debugf("File settled: %s", path)
+			return true
+		}
+
+		if time.Now().After(deadline) {
+			log.Warnf("File did not settle within %s, skipping: %s", maxWait, path)
+			d.recordStat(path, statSkipped)
+			return false
+		}
+
+		select {
+		case <-d.stopCh:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// queueEvent hands a file to the worker pool, dropping it if the queue is full
+func (d *Daemon) queueEvent(path string) {
+	logger := log.WithField(trace.Field, d.beginTrace(path))
+	select {
+	case d.eventChan <- path:
+		logger.Debugf("Queued event for processing: %s", path)
+	default:
+		logger.Warnf("Event channel full, dropping event for: %s", path)
+		d.recordStat(path, statSkipped)
+		d.endTrace(path)
+	}
+}
+
+// tempSiblingExists reports whether a temporary version of the file (path plus
+// one of the suffixes) is still present
+func tempSiblingExists(path string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if suffix == "" {
+			continue
+		}
+		if _, err := os.Stat(path + suffix); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocked reports whether another process holds an advisory lock on the file
+func isLocked(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return err == syscall.EWOULDBLOCK
+	}
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return false
+}
+
+// isOpenElsewhere reports whether another process has the file open. Where
+// that can't be told, files are treated as closed, with a warning logged once.
+func (d *Daemon) isOpenElsewhere(path string) bool {
+	open, err := openfiles.IsOpen(path)
+	if err != nil {
+		d.openCheckWarning.Do(func() {
+			log.Warnf("Can't tell whether files are open elsewhere, not waiting for them to close: %v", err)
+		})
+		return false
+	}
+	if open {
+		log.Debugf("File is open in another process, waiting: %s", path)
+	}
+	return open
+}
@@ -0,0 +1,193 @@
+package watch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/atomicfile"
+	"sortd/pkg/types"
+)
+
+// statKind identifies which per-directory counter an outcome increments
+type statKind int
+
+const (
+	statEventSeen statKind = iota
+	statOrganized
+	statSkipped
+	statError
+	statUnmatched
+)
+
+// trackDirectory registers a watched directory so events below it are attributed to it
+func (d *Daemon) trackDirectory(dir string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	clean := filepath.Clean(dir)
+	if _, exists := d.dirStats[clean]; !exists {
+		d.dirStats[clean] = &types.DirectoryStats{Path: clean}
+	}
+}
+
+// statsForLocked returns the counters of the watched directory containing path.
+// The most specific watched directory wins; unknown paths get an entry of their own.
+// The caller must hold d.mutex for writing.
+func (d *Daemon) statsForLocked(path string) *types.DirectoryStats {
+	dir := filepath.Clean(filepath.Dir(path))
+
+	var best *types.DirectoryStats
+	for root, stats := range d.dirStats {
+		if dir != root && !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			continue
+		}
+		if best == nil || len(root) > len(best.Path) {
+			best = stats
+		}
+	}
+
+	if best == nil {
+		best = &types.DirectoryStats{Path: dir}
+		d.dirStats[dir] = best
+	}
+	return best
+}
+
+// recordStat increments the counter of the given kind for the directory containing path
+func (d *Daemon) recordStat(path string, kind statKind) {
+	d.mutex.Lock()
+	stats := d.statsForLocked(path)
+	switch kind {
+	case statEventSeen:
+		stats.EventsSeen++
+	case statOrganized:
+		stats.FilesOrganized++
+	case statSkipped:
+		stats.Skipped++
+	case statError:
+		stats.Errors++
+	case statUnmatched:
+		stats.Unmatched++
+	}
+	stats.LastActivity = time.Now()
+	d.mutex.Unlock()
+
+	d.scheduleStatsWrite()
+}
+
+// statsWriteDelay is how long counter updates collect before the stats file is
+// rewritten, so a burst of events costs one write
+var statsWriteDelay = time.Second
+
+// scheduleStatsWrite writes the stats file after statsWriteDelay, unless a
+// write is already due
+func (d *Daemon) scheduleStatsWrite() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.statsPath == "" || d.statsTimer != nil {
+		return
+	}
+	d.statsTimer = time.AfterFunc(statsWriteDelay, func() {
+		d.mutex.Lock()
+		d.statsTimer = nil
+		d.mutex.Unlock()
+
+		d.persistStats()
+	})
+}
+
+// flushStats writes the stats file now if a write is due
+func (d *Daemon) flushStats() {
+	d.mutex.Lock()
+	timer := d.statsTimer
+	d.statsTimer = nil
+	d.mutex.Unlock()
+
+	if timer != nil && timer.Stop() {
+		d.persistStats()
+	}
+}
+
+// DirectoryStats returns a snapshot of the per-directory counters, sorted by path
+func (d *Daemon) DirectoryStats() []types.DirectoryStats {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.directoryStatsLocked()
+}
+
+// directoryStatsLocked builds the sorted snapshot. The caller must hold d.mutex.
+func (d *Daemon) directoryStatsLocked() []types.DirectoryStats {
+	result := make([]types.DirectoryStats, 0, len(d.dirStats))
+	for _, stats := range d.dirStats {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// SetStatsFile sets a file the daemon keeps updated with its per-directory counters,
+// so that other processes (e.g. 'sortd daemon stats') can read them.
+// An empty path disables persistence.
+func (d *Daemon) SetStatsFile(path string) {
+	d.mutex.Lock()
+	d.statsPath = path
+	d.mutex.Unlock()
+
+	d.persistStats()
+}
+
+// persistStats writes the current counters to the stats file, if one is configured
+func (d *Daemon) persistStats() {
+	// Snapshot under the write lock so an older snapshot never overwrites a newer one
+	d.statsWriteMu.Lock()
+	defer d.statsWriteMu.Unlock()
+
+	d.mutex.RLock()
+	path := d.statsPath
+	snapshot := d.directoryStatsLocked()
+	d.mutex.RUnlock()
+
+	if path == "" {
+		return
+	}
+
+	if err := writeStatsFile(path, snapshot); err != nil {
+		log.Warnf("Failed to write daemon stats to %s: %v", path, err)
+	}
+}
+
+// writeStatsFile atomically replaces the stats file with the given snapshot
+func writeStatsFile(path string, stats []types.DirectoryStats) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, data, 0644)
+}
+
+// readStatsFile loads a snapshot previously written by writeStatsFile
+func readStatsFile(path string) ([]types.DirectoryStats, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []types.DirectoryStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
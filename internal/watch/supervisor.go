@@ -0,0 +1,246 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Watcher states reported in DaemonStatus.Watchers
+const (
+	WatcherWatching = "watching" // Events are being received for the directory
+	WatcherRetrying = "retrying" // The directory was lost and is being re-added with backoff
+	WatcherDropped  = "dropped"  // The directory stayed missing and is no longer retried
+	WatcherInactive = "inactive" // The directory's profile isn't active, so it isn't watched
+)
+
+const (
+	// defaultSuperviseInterval is how often watchers are checked by default
+	defaultSuperviseInterval = 5 * time.Second
+
+	// defaultDropAfter is how long a missing directory is retried by default
+	defaultDropAfter = time.Hour
+
+	// maxRestartBackoff caps the delay between attempts to re-add a directory
+	maxRestartBackoff = 5 * time.Minute
+)
+
+// WatcherStatus describes the watcher of a single watch directory
+type WatcherStatus struct {
+	Directory string    `json:"directory"`
+	State     string    `json:"state"`
+	Attempts  int       `json:"attempts,omitempty"`   // Failed attempts to re-add the directory
+	LastError string    `json:"last_error,omitempty"` // Why the directory was lost or the last attempt failed
+	Since     time.Time `json:"since"`                // When the directory entered its current state
+}
+
+// supervisedDir is what the supervisor remembers about a watch directory
+type supervisedDir struct {
+	status    WatcherStatus
+	backoff   time.Duration
+	nextRetry time.Time
+}
+
+// superviseInterval returns the configured interval between watcher checks
+func (d *Daemon) superviseInterval() time.Duration {
+	if d.config.WatchMode.SuperviseSeconds > 0 {
+		return time.Duration(d.config.WatchMode.SuperviseSeconds) * time.Second
+	}
+	return defaultSuperviseInterval
+}
+
+// dropAfter returns how long a missing directory is retried before it is dropped
+func (d *Daemon) dropAfter() time.Duration {
+	if d.config.WatchMode.DropAfterSeconds > 0 {
+		return time.Duration(d.config.WatchMode.DropAfterSeconds) * time.Second
+	}
+	return defaultDropAfter
+}
+
+// addDirectory starts watching a directory with the backend its watch
+// directory entry selects, along with its subdirectories when the entry is
+// recursive
+func (d *Daemon) addDirectory(dir string) error {
+	if err := d.addSingleDirectory(dir); err != nil {
+		return err
+	}
+	if entry, ok := d.config.WatchDirFor(dir); ok && entry.Recursive {
+		d.addSubdirectories(dir)
+	}
+	return nil
+}
+
+// addSingleDirectory starts watching one directory, without its subdirectories
+func (d *Daemon) addSingleDirectory(dir string) error {
+	if d.pollsDirectory(dir) {
+		return d.addPollDirectory(dir)
+	}
+	return d.watcher.Add(dir)
+}
+
+// removeDirectory stops watching a directory and any subdirectories watched
+// along with it, ignoring watches already gone
+func (d *Daemon) removeDirectory(dir string) {
+	for _, watched := range d.watchList() {
+		if watched != dir && !isBelow(watched, dir) {
+			continue
+		}
+		if !d.pollsDirectory(watched) {
+			_ = d.watcher.Remove(watched)
+			continue
+		}
+
+		d.mutex.Lock()
+		for i, existing := range d.pollDirs {
+			if existing == watched {
+				d.pollDirs = append(d.pollDirs[:i], d.pollDirs[i+1:]...)
+				break
+			}
+		}
+		d.mutex.Unlock()
+	}
+}
+
+// superviseDirectory puts a directory that is being watched under supervision
+func (d *Daemon) superviseDirectory(dir string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.watchers[dir] = &supervisedDir{
+		status: WatcherStatus{Directory: dir, State: WatcherWatching, Since: time.Now()},
+	}
+}
+
+// startSupervisor checks the watchers every supervise interval until the daemon stops
+func (d *Daemon) startSupervisor() {
+	d.superviseWg.Add(1)
+	go func() {
+		defer d.superviseWg.Done()
+
+		ticker := time.NewTicker(d.superviseInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stopCh:
+				return
+			case now := <-ticker.C:
+				d.superviseOnce(now)
+			}
+		}
+	}()
+}
+
+// superviseOnce notices watch directories that were deleted or unmounted and
+// re-adds lost ones whose backoff has passed. One directory failing never
+// affects the others; directories missing for longer than drop_after_seconds
+// are dropped with a warning.
+func (d *Daemon) superviseOnce(now time.Time) {
+	watching := make(map[string]bool)
+	for _, dir := range d.watchList() {
+		watching[dir] = true
+	}
+
+	d.mutex.RLock()
+	dirs := make([]string, 0, len(d.watchers))
+	for dir := range d.watchers {
+		dirs = append(dirs, dir)
+	}
+	d.mutex.RUnlock()
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		d.mutex.RLock()
+		state := d.watchers[dir].status.State
+		nextRetry := d.watchers[dir].nextRetry
+		d.mutex.RUnlock()
+
+		if d.profileChanged(dir, state, now) {
+			continue
+		}
+
+		switch state {
+		case WatcherWatching:
+			if err := checkWatched(dir, watching[dir]); err != nil {
+				d.removeDirectory(dir)
+				d.watcherLost(dir, err, now)
+			}
+		case WatcherRetrying:
+			if now.Before(nextRetry) {
+				continue
+			}
+			if err := d.addDirectory(dir); err != nil {
+				d.watcherRetryFailed(dir, err, now)
+				continue
+			}
+			d.superviseDirectory(dir)
+			log.Infof("Watching directory again: %s", dir)
+		}
+	}
+}
+
+// checkWatched returns why a directory that should be watched isn't
+func checkWatched(dir string, watched bool) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is no longer a directory", dir)
+	}
+	if !watched {
+		return fmt.Errorf("watch on %s was removed", dir)
+	}
+	return nil
+}
+
+// watcherLost marks a directory for re-adding after the first backoff
+func (d *Daemon) watcherLost(dir string, err error, now time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	backoff := d.superviseInterval()
+	d.watchers[dir] = &supervisedDir{
+		status:    WatcherStatus{Directory: dir, State: WatcherRetrying, LastError: err.Error(), Since: now},
+		backoff:   backoff,
+		nextRetry: now.Add(backoff),
+	}
+	log.Warnf("Lost watch on %s: %v; retrying", dir, err)
+}
+
+// watcherRetryFailed doubles the backoff of a lost directory, or drops it once
+// it has been missing for too long
+func (d *Daemon) watcherRetryFailed(dir string, err error, now time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	sup := d.watchers[dir]
+	sup.status.Attempts++
+	sup.status.LastError = err.Error()
+
+	if now.Sub(sup.status.Since) >= d.dropAfter() {
+		sup.status.State = WatcherDropped
+		sup.status.Since = now
+		log.Warnf("Dropping watch directory %s after %d failed attempts: %v", dir, sup.status.Attempts, err)
+		return
+	}
+
+	sup.backoff *= 2
+	if sup.backoff > maxRestartBackoff {
+		sup.backoff = maxRestartBackoff
+	}
+	sup.nextRetry = now.Add(sup.backoff)
+	log.Debugf("Re-adding watch directory %s failed (attempt %d): %v; next try in %s", dir, sup.status.Attempts, err, sup.backoff)
+}
+
+// watcherStatusesLocked returns the supervised directories sorted by path.
+// The caller must hold d.mutex.
+func (d *Daemon) watcherStatusesLocked() []WatcherStatus {
+	statuses := make([]WatcherStatus, 0, len(d.watchers))
+	for _, sup := range d.watchers {
+		statuses = append(statuses, sup.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Directory < statuses[j].Directory })
+	return statuses
+}
@@ -0,0 +1,115 @@
+package watch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/internal/watch"
+	"sortd/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// watcherState returns the supervisor's state for dir, or "" if it isn't supervised
+func watcherState(daemon *watch.Daemon, dir string) string {
+	for _, w := range daemon.Status().Watchers {
+		if w.Directory == dir {
+			return w.State
+		}
+	}
+	return ""
+}
+
+func TestDaemon_SupervisorRestartsLostWatcher(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "inbox")
+	otherDir := filepath.Join(tmpDir, "other")
+	destDir := filepath.Join(tmpDir, "docs")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+	require.NoError(t, os.Mkdir(otherDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir, otherDir}
+	cfg.WatchMode.SuperviseSeconds = 1
+	cfg.Organize.Patterns = []types.Pattern{{Match: "*.txt", Target: destDir}}
+	cfg.Settings.CreateDirs = true
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	assert.Equal(t, watch.WatcherWatching, watcherState(daemon, watchDir))
+
+	// Losing one directory leaves the others alone
+	require.NoError(t, os.RemoveAll(watchDir))
+	assert.Eventually(t, func() bool {
+		return watcherState(daemon, watchDir) == watch.WatcherRetrying
+	}, 5*time.Second, 100*time.Millisecond, "Deleted directory should be retried")
+	assert.Equal(t, watch.WatcherWatching, watcherState(daemon, otherDir))
+
+	// Once it's back, it's watched again and files in it are organized
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+	assert.Eventually(t, func() bool {
+		return watcherState(daemon, watchDir) == watch.WatcherWatching
+	}, 10*time.Second, 100*time.Millisecond, "Recreated directory should be watched again")
+
+	require.NoError(t, os.WriteFile(filepath.Join(watchDir, "new.txt"), []byte("new"), 0644))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(destDir, "new.txt"))
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond, "Files in the restored directory should be organized")
+}
+
+func TestDaemon_SupervisorDropsMissingDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "mount")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.WatchDirectories = []string{watchDir, tmpDir}
+	cfg.WatchMode.Backend = "poll"
+	cfg.WatchMode.SuperviseSeconds = 1
+	cfg.WatchMode.DropAfterSeconds = 1
+
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	require.NoError(t, os.RemoveAll(watchDir))
+	assert.Eventually(t, func() bool {
+		return watcherState(daemon, watchDir) == watch.WatcherDropped
+	}, 10*time.Second, 100*time.Millisecond, "Directory missing past drop_after_seconds should be dropped")
+
+	assert.Equal(t, []string{tmpDir}, daemon.Status().WatchDirectories)
+	assert.Equal(t, watch.WatcherWatching, watcherState(daemon, tmpDir))
+}
+
+func TestDaemon_InactiveProfileDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "inbox")
+	nasDir := filepath.Join(tmpDir, "nas")
+	require.NoError(t, os.Mkdir(watchDir, 0755))
+
+	cfg := &config.Config{}
+	cfg.Profiles = []config.Profile{{Name: "docked", Mounted: []string{nasDir}}}
+	cfg.Directories.Watch = []config.WatchDir{{Path: watchDir}, {Path: nasDir, Profile: "docked"}}
+	cfg.WatchMode.SuperviseSeconds = 1
+
+	// The NAS isn't there, so its directory waits rather than failing the start
+	daemon, err := watch.NewDaemonWithWorkflowPath(cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	assert.Equal(t, watch.WatcherWatching, watcherState(daemon, watchDir))
+	assert.Equal(t, watch.WatcherInactive, watcherState(daemon, nasDir))
+
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, watch.WatcherInactive, watcherState(daemon, nasDir), "Inactive directories aren't retried or dropped")
+}
@@ -0,0 +1,44 @@
+package watch
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/trace"
+)
+
+// beginTrace returns the trace ID of the file at path, giving it a new one
+// if it has none. The ID lasts until endTrace, so the event queueing the file,
+// the worker handling it and everything recorded about it share it.
+func (d *Daemon) beginTrace(path string) string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if id := d.traces[path]; id != "" {
+		return id
+	}
+	if d.traces == nil {
+		d.traces = make(map[string]string)
+	}
+	id := trace.New()
+	d.traces[path] = id
+	return id
+}
+
+// endTrace forgets the trace ID of the file at path once it has been handled
+func (d *Daemon) endTrace(path string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.traces, path)
+}
+
+// traceOf returns the trace ID of the file at path, or "" outside its handling
+func (d *Daemon) traceOf(path string) string {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.traces[path]
+}
+
+// logFor returns a logger whose lines carry the trace ID of the file at path
+func (d *Daemon) logFor(path string) *log.Entry {
+	return log.WithField(trace.Field, d.traceOf(path))
+}
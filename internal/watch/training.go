@@ -0,0 +1,110 @@
+package watch
+
+import (
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/organize"
+	"sortd/internal/pending"
+)
+
+// PendingQueuePath returns the path of the queue moves are staged in during training
+func (d *Daemon) PendingQueuePath() string {
+	return pending.DefaultPath(d.config)
+}
+
+// trainingActive reports whether automatic moves should be staged rather than
+// executed. The period starts the first time it is consulted unless the
+// configuration fixes a start date.
+func (d *Daemon) trainingActive() bool {
+	training := d.config.Settings.Training
+	if !training.Enabled {
+		return false
+	}
+
+	queue, err := d.pendingQueue()
+	if err != nil {
+		// Staging is the safe side: keep treating the period as running
+		log.Errorf("Error opening pending queue: %v", err)
+		return true
+	}
+
+	now := time.Now()
+	started, err := queue.TrainingStarted(now)
+	if err != nil {
+		log.Warnf("Failed to record training start: %v", err)
+	}
+	return training.ActiveAt(started, now)
+}
+
+// pendingQueue opens the pending queue on first use
+func (d *Daemon) pendingQueue() (*pending.Queue, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.pending == nil {
+		queue, err := pending.Open(d.PendingQueuePath())
+		if err != nil {
+			return nil, err
+		}
+		d.pending = queue
+	}
+	return d.pending, nil
+}
+
+// stagingReason returns why a move proposed by the rule should be staged for
+// review rather than carried out, or "" if it may go ahead
+func (d *Daemon) stagingReason(rule string) string {
+	if d.trainingActive() {
+		return pending.ReasonTraining
+	}
+
+	threshold := d.config.Settings.AutoApplyConfidence
+	if threshold <= 0 {
+		return ""
+	}
+	store, err := d.learningStore()
+	if err != nil {
+		log.Warnf("Failed to open learning data: %v", err)
+		return ""
+	}
+	if store != nil && store.Confidence(rule) < threshold {
+		return pending.ReasonLowConfidence
+	}
+	return ""
+}
+
+// stageFile adds the move the rule proposes for a file to the pending queue
+// instead of carrying it out
+func (d *Daemon) stageFile(filePath, destDir, rule, reason string) error {
+	queue, err := d.pendingQueue()
+	if err != nil {
+		return err
+	}
+
+	item, err := queue.Add(pending.Item{
+		Path:        filePath,
+		Destination: filepath.Join(destDir, filepath.Base(filePath)),
+		Rule:        rule,
+		Reason:      reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Staged %s -> %s for review (%s, pending item %s, operation %s)", filePath, item.Destination, reason, item.ID, item.Operation)
+	return nil
+}
+
+// askCollision is the daemon's answer to collisions under the "ask" strategy:
+// there is no one to ask while watching, so the move is queued for the user to
+// approve later, when sortd pending or the GUI asks them how to resolve it
+func (d *Daemon) askCollision(src, dest string) (string, error) {
+	pattern, _ := d.engine.MatchingPattern(src)
+	if err := d.stageFile(src, filepath.Dir(dest), pattern.Match, pending.ReasonCollision); err != nil {
+		return "", err
+	}
+	return organize.CollisionSkip, nil
+}
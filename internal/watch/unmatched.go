@@ -0,0 +1,47 @@
+package watch
+
+import (
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/organize"
+	"sortd/internal/pending"
+)
+
+// handleUnmatched applies settings.unmatched.policy to a file that no
+// workflow or pattern matched. Every such file is counted as unmatched, so
+// they show up in the stats whatever happens to them.
+func (d *Daemon) handleUnmatched(filePath string) {
+	d.recordStat(filePath, statUnmatched)
+
+	policy := organize.UnmatchedPolicy(d.config)
+	if policy == organize.UnmatchedLeave || organize.InUnsorted(d.config, filePath) {
+		log.Debugf("No pattern matches %s, leaving it in place", filePath)
+		d.recordStat(filePath, statSkipped)
+		return
+	}
+
+	dest := organize.UnsortedDestination(d.config, filePath, time.Now())
+	if policy == organize.UnmatchedReview {
+		if err := d.stageFile(filePath, filepath.Dir(dest), "", pending.ReasonUnmatched); err != nil {
+			log.Errorf("Error staging unmatched file %s: %v", filePath, err)
+			d.recordStat(filePath, statError)
+			return
+		}
+		d.recordStat(filePath, statSkipped)
+		return
+	}
+
+	attempts, err := d.retryTransient(filePath, func() error { return d.engine.MoveFile(filePath, dest) })
+	d.recordActivity(filePath, filepath.Dir(dest), activitySourceUnmatched, "", err)
+	if err != nil {
+		log.Errorf("Error moving unmatched file %s: %v", filePath, err)
+		d.recordStat(filePath, statError)
+		d.recordFailure(filePath, activitySourceUnmatched, err, attempts)
+		return
+	}
+	log.Infof("No rule matches %s; moved it to %s", filePath, filepath.Dir(dest))
+	d.recordStat(filePath, statOrganized)
+}
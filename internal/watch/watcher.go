@@ -3,10 +3,13 @@ package watch
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	"sortd/internal/config"
 )
 
 // FileModification represents a file event detected by the watcher
@@ -36,10 +39,30 @@ type Watcher struct {
 
 	// Whether the watcher is running
 	running bool
+
+	// Whether subdirectories are watched too, and how long a file must go
+	// unchanged before it is reported
+	recursive bool
+	debounce  time.Duration
+
+	// Files waiting out the debounce, with the operations seen so far
+	pending map[string]*pendingMod
+}
+
+// pendingMod is a file event held back until the file stops changing
+type pendingMod struct {
+	timer *time.Timer
+	op    fsnotify.Op
 }
 
 // New creates a new directory watcher using fsnotify
 func New() (*Watcher, error) {
+	return NewWithSettings(config.WatchSettings{})
+}
+
+// NewWithSettings creates a directory watcher following the watch_mode
+// settings: recursion, the debounce, and a channel buffer sized for the batch
+func NewWithSettings(settings config.WatchSettings) (*Watcher, error) {
 	fsWatcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
@@ -47,10 +70,13 @@ func New() (*Watcher, error) {
 
 	return &Watcher{
 		directories: []string{},
-		fileModChan: make(chan FileModification, 10),
+		fileModChan: make(chan FileModification, 10+settings.Workers()),
 		stopChan:    make(chan struct{}),
 		fsWatcher:   fsWatcher,
 		running:     false,
+		recursive:   settings.Recursive,
+		debounce:    time.Duration(settings.DebounceSeconds) * time.Second,
+		pending:     make(map[string]*pendingMod),
 	}, nil
 }
 
@@ -72,6 +98,19 @@ func (w *Watcher) AddDirectory(dir string) error {
 		return fmt.Errorf("failed to add directory %s to watcher: %w", dir, err)
 	}
 
+	// Recursive watchers follow the subdirectories too
+	if w.recursive {
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				if entry.IsDir() {
+					if err := w.AddDirectory(filepath.Join(dir, entry.Name())); err != nil {
+						fmt.Fprintf(os.Stderr, "Error watching subdirectory: %v\n", err)
+					}
+				}
+			}
+		}
+	}
+
 	// Keep track of directories added (optional, but useful for GetDirectories)
 	w.mutex.Lock()
 	// Check if already present to avoid duplicates in the list (fsnotify handles duplicates itself)
@@ -137,24 +176,25 @@ func (w *Watcher) Start() error {
 
 					// Ensure it's not a directory change event if we don't want those
 					if info.IsDir() {
+						// New subdirectories of recursive watchers are watched too
+						if w.recursive && event.Op.Has(fsnotify.Create) {
+							if err := w.AddDirectory(event.Name); err != nil {
+								fmt.Fprintf(os.Stderr, "Error watching new directory: %v\n", err)
+							}
+						}
 						continue
 					}
 
-					mod := FileModification{
+					if w.debounce > 0 {
+						w.hold(event.Name, event.Op)
+						continue
+					}
+					w.send(FileModification{
 						Path:      event.Name,
 						Info:      info,
 						Timestamp: time.Now(),
 						Op:        event.Op,
-					}
-
-					// Send event non-blockingly to avoid goroutine getting stuck if channel full
-					select {
-					case w.fileModChan <- mod:
-						// fmt.Fprintf(os.Stdout, "Sent modification to channel: %+v\n", mod) // Debug print
-					default:
-						// Use proper logging
-						fmt.Fprintf(os.Stderr, "Warning: event channel is full, dropped event for %s\n", event.Name)
-					}
+					})
 				}
 
 			case err, ok := <-w.fsWatcher.Errors:
@@ -185,8 +225,12 @@ func (w *Watcher) Stop() {
 		return // Already stopped
 	}
 
-	// Signal the event processing goroutine to stop
+	// Signal the event processing goroutine to stop, and drop debounced events
 	close(w.stopChan)
+	for path, held := range w.pending {
+		held.timer.Stop()
+		delete(w.pending, path)
+	}
 
 	// Close the underlying fsnotify watcher
 	if err := w.fsWatcher.Close(); err != nil {
@@ -203,6 +247,53 @@ func (w *Watcher) Stop() {
 	fmt.Fprintln(os.Stdout, "Watcher stopped.") // Debug print
 }
 
+// send delivers a modification without blocking, so the event loop never
+// gets stuck behind a full channel
+func (w *Watcher) send(mod FileModification) {
+	select {
+	case w.fileModChan <- mod:
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: event channel is full, dropped event for %s\n", mod.Path)
+	}
+}
+
+// hold delays a file's event until it has gone unchanged for the debounce,
+// restarting the wait on every further event for it
+func (w *Watcher) hold(path string, op fsnotify.Op) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if held, ok := w.pending[path]; ok {
+		held.op |= op
+		held.timer.Reset(w.debounce)
+		return
+	}
+	w.pending[path] = &pendingMod{op: op, timer: time.AfterFunc(w.debounce, func() { w.release(path) })}
+}
+
+// release reports a file once its debounce is over, if it is still there.
+// The read lock keeps Stop from closing the channel during the send.
+func (w *Watcher) release(path string) {
+	w.mutex.Lock()
+	held, ok := w.pending[path]
+	delete(w.pending, path)
+	w.mutex.Unlock()
+	if !ok {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return
+	}
+
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	if w.running {
+		w.send(FileModification{Path: path, Info: info, Timestamp: time.Now(), Op: held.op})
+	}
+}
+
 // IsRunning returns whether the watcher is currently active
 func (w *Watcher) IsRunning() bool {
 	w.mutex.RLock()
@@ -9,6 +9,8 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"sortd/internal/config"
 )
 
 func TestWatcherFsnotify(t *testing.T) {
@@ -122,3 +124,38 @@ DrainLoop:
 		t.Error("Timeout waiting for event channel to close after stop")
 	}
 }
+
+func TestWatcherSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	subDir := filepath.Join(tempDir, "sub")
+	require.NoError(t, os.Mkdir(subDir, 0755))
+
+	w, err := NewWithSettings(config.WatchSettings{DebounceSeconds: 1, Recursive: true})
+	require.NoError(t, err)
+	require.NoError(t, w.AddDirectory(tempDir))
+	assert.Contains(t, w.GetDirectories(), subDir, "Recursive watchers follow subdirectories")
+	require.NoError(t, w.Start())
+	defer w.Stop()
+	time.Sleep(100 * time.Millisecond)
+
+	// Writes in quick succession are reported once, after the file settles
+	path := filepath.Join(subDir, "report.pdf")
+	start := time.Now()
+	require.NoError(t, os.WriteFile(path, []byte("draft"), 0644))
+	time.Sleep(300 * time.Millisecond)
+	require.NoError(t, os.WriteFile(path, []byte("final"), 0644))
+
+	select {
+	case event := <-w.FileChannel():
+		assert.Equal(t, path, event.Path)
+		assert.True(t, event.Op.Has(fsnotify.Create), "Held events keep every operation seen")
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timeout waiting for the debounced event")
+	}
+	select {
+	case event := <-w.FileChannel():
+		t.Fatalf("Unexpected second event: %+v", event)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
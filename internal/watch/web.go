@@ -0,0 +1,212 @@
+package watch
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/httpsec"
+	"sortd/internal/pending"
+	"sortd/pkg/types"
+)
+
+//go:embed web
+var webAssets embed.FS
+
+// webActivityLimit bounds how many recent activity entries the web UI shows
+const webActivityLimit = 50
+
+// webActivityWindow is how far back the web UI looks for recent activity
+const webActivityWindow = 7 * 24 * time.Hour
+
+// webTokenCookie keeps the token of a browser that opened the UI with ?token=
+const webTokenCookie = "sortd_token"
+
+// webTokenCookieAge is how long a browser stays signed in
+const webTokenCookieAge = 90 * 24 * time.Hour
+
+// webStatus is the body of the web UI's status endpoint
+type webStatus struct {
+	Running          bool                   `json:"running"`
+	ReadOnly         bool                   `json:"read_only"`
+	Paused           string                 `json:"paused,omitempty"`
+	WatchDirectories []string               `json:"watch_directories"`
+	Watchers         []WatcherStatus        `json:"watchers"`
+	FilesProcessed   int                    `json:"files_processed"`
+	LastActivity     time.Time              `json:"last_activity"`
+	Directories      []types.DirectoryStats `json:"directories"`
+}
+
+// startWebServer serves the read-only web UI if an address is configured, so
+// the daemon can be checked on from a phone without installing anything
+func (d *Daemon) startWebServer() error {
+	addr := d.config.WatchMode.WebListen
+	if addr == "" {
+		return nil
+	}
+
+	listener, err := d.listen("web UI", addr, d.config.WatchMode.HTTP.Token)
+	if err != nil {
+		return err
+	}
+
+	d.webServer = &http.Server{Handler: d.webHandler(), ReadHeaderTimeout: 10 * time.Second}
+	d.webAddr = listener.Addr().String()
+
+	// Serve the server just made: Stop may clear the field before this runs
+	server := d.webServer
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Web UI server error: %v", err)
+		}
+	}()
+
+	log.Infof("Web UI listening on %s://%s/", httpsec.Scheme(d.config.WatchMode.HTTP), d.webAddr)
+	return nil
+}
+
+// stopWebServer shuts the web UI down, if running
+func (d *Daemon) stopWebServer() {
+	if d.webServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.webServer.Shutdown(ctx); err != nil {
+		log.Errorf("Error stopping web UI server: %v", err)
+	}
+	d.webServer = nil
+}
+
+// WebAddr returns the address the web UI is listening on, or ""
+func (d *Daemon) WebAddr() string {
+	return d.webAddr
+}
+
+// webHandler routes the web UI: the embedded page and the JSON it reads.
+// Nothing here changes anything; every route answers GET only.
+func (d *Daemon) webHandler() http.Handler {
+	assets, _ := fs.Sub(webAssets, "web")
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(assets)))
+	mux.HandleFunc("/api/status", d.handleWebStatus)
+	mux.HandleFunc("/api/activity", d.handleWebActivity)
+	mux.HandleFunc("/api/pending", d.handleWebPending)
+
+	return d.requireWebToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "the web UI is read-only", http.StatusMethodNotAllowed)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+// requireWebToken lets through requests with the watch_mode.http token. A
+// browser can't send a bearer header, so opening the UI once with ?token=
+// stores the token in a cookie and drops it from the address.
+func (d *Daemon) requireWebToken(next http.Handler) http.Handler {
+	settings := d.config.WatchMode.HTTP
+	if settings.Token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if httpsec.Authorized(r, settings.Token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if cookie, err := r.Cookie(webTokenCookie); err == nil && httpsec.Equal(cookie.Value, settings.Token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		if given := query.Get("token"); given != "" && httpsec.Equal(given, settings.Token) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     webTokenCookie,
+				Value:    settings.Token,
+				Path:     "/",
+				MaxAge:   int(webTokenCookieAge.Seconds()),
+				HttpOnly: true,
+				Secure:   settings.TLS,
+				SameSite: http.SameSiteStrictMode,
+			})
+			query.Del("token")
+			target := *r.URL
+			target.RawQuery = query.Encode()
+			http.Redirect(w, r, target.String(), http.StatusSeeOther)
+			return
+		}
+
+		http.Error(w, "open the web UI with ?token=<watch_mode.http.token>", http.StatusUnauthorized)
+	})
+}
+
+// handleWebStatus answers with the daemon status, including the per-directory stats
+func (d *Daemon) handleWebStatus(w http.ResponseWriter, r *http.Request) {
+	status := d.Status()
+	writeWebJSON(w, webStatus{
+		Running:          status.Running,
+		ReadOnly:         status.ReadOnly,
+		Paused:           status.Paused,
+		WatchDirectories: status.WatchDirectories,
+		Watchers:         status.Watchers,
+		FilesProcessed:   status.FilesProcessed,
+		LastActivity:     status.LastActivity,
+		Directories:      status.Directories,
+	})
+}
+
+// handleWebActivity answers with the most recent activity entries, newest first
+func (d *Daemon) handleWebActivity(w http.ResponseWriter, r *http.Request) {
+	d.mutex.RLock()
+	activityPath := d.activityPath
+	d.mutex.RUnlock()
+
+	entries := []types.ActivityEntry{}
+	if activityPath != "" {
+		recent, err := readActivityFile(activityPath, time.Now().Add(-webActivityWindow))
+		if err != nil && !os.IsNotExist(err) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i := len(recent) - 1; i >= 0 && len(entries) < webActivityLimit; i-- {
+			entries = append(entries, recent[i])
+		}
+	}
+	writeWebJSON(w, entries)
+}
+
+// handleWebPending answers with the moves waiting for approval
+func (d *Daemon) handleWebPending(w http.ResponseWriter, r *http.Request) {
+	items := []pending.Item{}
+	queue, err := pending.Open(d.PendingQueuePath())
+	if err == nil {
+		var listed []pending.Item
+		listed, err = queue.List()
+		items = append(items, listed...)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeWebJSON(w, items)
+}
+
+// writeWebJSON writes v as the JSON response
+func writeWebJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,115 @@
+// Read-only view of the sortd watch daemon. Everything is fetched from the
+// daemon's /api endpoints and rendered as text, never as HTML.
+"use strict";
+
+const refreshMillis = 10000;
+
+function el(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined) node.textContent = text;
+  if (className) node.className = className;
+  return node;
+}
+
+function base(path) {
+  return path.split(/[\\/]/).filter(Boolean).pop() || path;
+}
+
+function when(time) {
+  const t = new Date(time);
+  if (isNaN(t) || t.getFullYear() < 2000) return "never";
+  return t.toLocaleString();
+}
+
+async function getJSON(path) {
+  const resp = await fetch(path, { cache: "no-store" });
+  if (!resp.ok) throw new Error(path + ": " + resp.status);
+  return resp.json();
+}
+
+function renderStatus(status) {
+  const state = document.getElementById("state");
+  let label = "running", className = "ok";
+  if (!status.running) {
+    label = "stopped"; className = "bad";
+  } else if (status.paused) {
+    label = "paused"; className = "warn";
+  } else if (status.read_only) {
+    label = "read-only"; className = "warn";
+  }
+  state.textContent = label;
+  state.className = "badge " + className;
+
+  const list = document.getElementById("status");
+  list.replaceChildren();
+  const rows = [
+    ["Files processed", String(status.files_processed)],
+    ["Last activity", when(status.last_activity)],
+    ["Watching", (status.watch_directories || []).join(", ") || "nothing"],
+  ];
+  if (status.paused) rows.push(["Paused", status.paused]);
+  for (const watcher of status.watchers || []) {
+    if (watcher.state !== "watching") {
+      rows.push([base(watcher.directory), watcher.state + (watcher.last_error ? ": " + watcher.last_error : "")]);
+    }
+  }
+  for (const [term, detail] of rows) {
+    list.append(el("dt", term), el("dd", detail));
+  }
+
+  const body = document.getElementById("directories");
+  body.replaceChildren();
+  for (const dir of status.directories || []) {
+    const row = el("tr");
+    row.append(el("td", dir.path), el("td", String(dir.files_organized)),
+      el("td", String(dir.unmatched)), el("td", String(dir.errors)));
+    body.append(row);
+  }
+}
+
+function renderPending(items) {
+  const list = document.getElementById("pending");
+  list.replaceChildren();
+  if (items.length === 0) {
+    list.append(el("li", "Nothing waiting.", "meta"));
+    return;
+  }
+  for (const item of items) {
+    const li = el("li", base(item.path) + " → " + item.destination);
+    li.append(el("span", item.reason + " · " + when(item.created), "meta"));
+    list.append(li);
+  }
+}
+
+function renderActivity(entries) {
+  const list = document.getElementById("activity");
+  list.replaceChildren();
+  if (entries.length === 0) {
+    list.append(el("li", "Nothing this week.", "meta"));
+    return;
+  }
+  for (const entry of entries) {
+    const li = el("li", base(entry.path) + (entry.destination ? " → " + entry.destination : ""));
+    if (entry.error) li.append(el("span", entry.error, "meta error"));
+    li.append(el("span", entry.source + " · " + when(entry.time), "meta"));
+    list.append(li);
+  }
+}
+
+async function refresh() {
+  try {
+    const [status, pending, activity] = await Promise.all([
+      getJSON("api/status"), getJSON("api/pending"), getJSON("api/activity"),
+    ]);
+    renderStatus(status);
+    renderPending(pending);
+    renderActivity(activity);
+  } catch (err) {
+    const state = document.getElementById("state");
+    state.textContent = "unreachable";
+    state.className = "badge bad";
+  }
+}
+
+refresh();
+setInterval(refresh, refreshMillis);
@@ -0,0 +1,157 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sortd/internal/httpsec"
+)
+
+// webhookPathPrefix is the URL prefix of the trigger endpoint: POST /hooks/<workflow-id>
+const webhookPathPrefix = "/hooks/"
+
+// webhookRequest is the body external systems send to trigger a workflow
+type webhookRequest struct {
+	Path string `json:"path"`
+}
+
+// webhookResponse reports the outcome of a triggered workflow
+type webhookResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Trace   string `json:"trace,omitempty"` // ID of the run, for finding it in the logs
+}
+
+// webhookToken returns the token webhook callers present: the endpoint's own,
+// or the one every endpoint shares
+func (d *Daemon) webhookToken() string {
+	if token := d.config.WatchMode.Webhook.Token; token != "" {
+		return token
+	}
+	return d.config.WatchMode.HTTP.Token
+}
+
+// startWebhookServer listens for webhook triggers if an address is configured
+func (d *Daemon) startWebhookServer() error {
+	addr := d.config.WatchMode.Webhook.Listen
+	if addr == "" {
+		return nil
+	}
+
+	listener, err := d.listen("webhook trigger endpoint", addr, d.webhookToken())
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(webhookPathPrefix, d.handleWebhook)
+	d.webhookServer = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	d.webhookAddr = listener.Addr().String()
+
+	// Serve the server just made: Stop may clear the field before this runs
+	server := d.webhookServer
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Webhook server error: %v", err)
+		}
+	}()
+
+	log.Infof("Webhook trigger endpoint listening on %s", d.webhookAddr)
+	return nil
+}
+
+// stopWebhookServer shuts the webhook server down, if running
+func (d *Daemon) stopWebhookServer() {
+	if d.webhookServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.webhookServer.Shutdown(ctx); err != nil {
+		log.Errorf("Error stopping webhook server: %v", err)
+	}
+	d.webhookServer = nil
+}
+
+// WebhookAddr returns the address the webhook endpoint is listening on, or ""
+func (d *Daemon) WebhookAddr() string {
+	return d.webhookAddr
+}
+
+// handleWebhook runs the workflow named in the URL on the file named in the body
+func (d *Daemon) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeWebhookResponse(w, http.StatusMethodNotAllowed, webhookResponse{Error: "use POST"})
+		return
+	}
+
+	if !httpsec.Authorized(r, d.webhookToken()) {
+		writeWebhookResponse(w, http.StatusUnauthorized, webhookResponse{Error: "invalid token"})
+		return
+	}
+
+	workflowID := strings.TrimPrefix(r.URL.Path, webhookPathPrefix)
+	if workflowID == "" {
+		writeWebhookResponse(w, http.StatusNotFound, webhookResponse{Error: "missing workflow ID"})
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil || req.Path == "" {
+		writeWebhookResponse(w, http.StatusBadRequest, webhookResponse{Error: "body must be JSON with a \"path\""})
+		return
+	}
+
+	// Only files inside watched directories may be acted on from outside
+	filePath := filepath.Clean(req.Path)
+	if !d.isWatchedPath(filePath) {
+		writeWebhookResponse(w, http.StatusForbidden, webhookResponse{Error: "path is outside the watched directories"})
+		return
+	}
+
+	if d.workflowManager == nil {
+		writeWebhookResponse(w, http.StatusServiceUnavailable, webhookResponse{Error: "workflows are unavailable"})
+		return
+	}
+
+	log.Infof("Webhook triggered workflow %s for %s", workflowID, filePath)
+	result, err := d.workflowManager.TriggerWebhook(workflowID, filePath)
+	if err != nil {
+		writeWebhookResponse(w, http.StatusUnprocessableEntity, webhookResponse{Error: err.Error()})
+		return
+	}
+
+	d.recordWorkflowResult(*result)
+	resp := webhookResponse{Success: result.Success, Message: result.Message, Trace: result.Trace}
+	if result.Error != nil {
+		resp.Error = result.Error.Error()
+	}
+	writeWebhookResponse(w, http.StatusOK, resp)
+}
+
+// isWatchedPath reports whether the path lies inside one of the configured watch directories
+func (d *Daemon) isWatchedPath(path string) bool {
+	for _, dir := range d.config.WatchPaths() {
+		root := filepath.Clean(dir)
+		if strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeWebhookResponse writes a JSON response with the given status
+func writeWebhookResponse(w http.ResponseWriter, status int, resp webhookResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// ActivityEntry records the outcome of the watch daemon handling a single file
+type ActivityEntry struct {
+	Time        time.Time `json:"time"`
+	Path        string    `json:"path"`
+	Destination string    `json:"destination,omitempty"` // Directory the file was moved to, when known
+	Source      string    `json:"source"`                // "rules" or "workflow"
+	Error       string    `json:"error,omitempty"`
+	Operation   string    `json:"operation,omitempty"` // Operation ID of the move, see OperationID
+	Trace       string    `json:"trace,omitempty"`     // ID following the file across subsystems, see package trace
+
+	// Hash chains the entry to the one before it so that edits to the log can
+	// be detected. Entries written before the chain existed have none.
+	Hash string `json:"hash,omitempty"`
+}
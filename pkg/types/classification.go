@@ -0,0 +1,36 @@
+package types
+
+// FileClassification describes a kind of file, such as "invoice" or "tax
+// document", by weighted criteria. A file belongs to the classification when
+// the weights of the criteria it meets add up to at least ConfidenceThreshold
+// of the total weight.
+type FileClassification struct {
+	Name                string                 `yaml:"name" json:"name"`
+	Description         string                 `yaml:"description,omitempty" json:"description,omitempty"`
+	ConfidenceThreshold float64                `yaml:"confidence_threshold" json:"confidence_threshold"` // 0-1; 0 means 0.5
+	Target              string                 `yaml:"target,omitempty" json:"target,omitempty"`         // Where files of this kind belong
+	Criteria            ClassificationCriteria `yaml:"criteria" json:"criteria"`
+}
+
+// ClassificationCriteria are the weighted tests a file is scored on. A
+// negative weight counts against the classification when its test matches.
+type ClassificationCriteria struct {
+	Keywords         []WeightedCriterion `yaml:"keywords,omitempty" json:"keywords,omitempty"`                   // Words in the name or text, case-insensitive
+	MimePrefixes     []WeightedCriterion `yaml:"mime_prefixes,omitempty" json:"mime_prefixes,omitempty"`         // e.g. "application/pdf", "image/"
+	FilenamePatterns []WeightedCriterion `yaml:"filename_patterns,omitempty" json:"filename_patterns,omitempty"` // Regular expressions on the file name
+	SizeRanges       []SizeRange         `yaml:"size_ranges,omitempty" json:"size_ranges,omitempty"`
+}
+
+// WeightedCriterion is a value to test for and how much a match counts
+type WeightedCriterion struct {
+	Value  string  `yaml:"value" json:"value"`
+	Weight float64 `yaml:"weight" json:"weight"`
+}
+
+// SizeRange matches files between Min and Max, written like "10KB" or "2MB".
+// An empty bound is open.
+type SizeRange struct {
+	Min    string  `yaml:"min,omitempty" json:"min,omitempty"`
+	Max    string  `yaml:"max,omitempty" json:"max,omitempty"`
+	Weight float64 `yaml:"weight" json:"weight"`
+}
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// DirectoryStats holds the watch daemon counters for a single watched directory
+type DirectoryStats struct {
+	Path           string    `json:"path"`
+	EventsSeen     int       `json:"events_seen"`
+	FilesOrganized int       `json:"files_organized"`
+	Skipped        int       `json:"skipped"`
+	Unmatched      int       `json:"unmatched"` // Files no rule or workflow matched, whatever the unmatched policy did with them
+	Errors         int       `json:"errors"`
+	LastActivity   time.Time `json:"last_activity,omitempty"`
+}
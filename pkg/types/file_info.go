@@ -9,14 +9,37 @@ import (
 	"time"
 )
 
-// FileInfo represents analyzed file information
+// DirectoryContentType is the content type given to directories
+const DirectoryContentType = "inode/directory"
+
+// FileInfo represents a file or directory as analysis, organize, watch and
+// the interfaces see it
 type FileInfo struct {
-	Path        string            `json:"path"`
-	ContentType string            `json:"type"`
-	Size        int64             `json:"size"`
-	ModTime     time.Time         `json:"mod_time,omitempty"`
-	Tags        []string          `json:"tags,omitempty"`
-	Metadata    map[string]string `json:"metadata,omitempty"`
+	Path           string            `json:"path"`
+	ContentType    string            `json:"type"`
+	Size           int64             `json:"size"`
+	ModTime        time.Time         `json:"mod_time,omitempty"`
+	IsDir          bool              `json:"is_dir,omitempty"`
+	Tags           []string          `json:"tags,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Classification string            `json:"classification,omitempty"` // Best matching classification, when one was evaluated
+}
+
+// NewFileInfo fills in what a stat already tells about the file at path, so
+// callers holding an os.FileInfo don't need to stat it again
+func NewFileInfo(path string, info os.FileInfo) *FileInfo {
+	f := &FileInfo{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+	}
+	if f.IsDir {
+		f.ContentType = DirectoryContentType
+		f.Size = 0
+		f.Tags = []string{"directory"}
+	}
+	return f
 }
 
 // Name returns the base name of the file
@@ -44,6 +67,9 @@ func (f *FileInfo) String() string {
 	if len(f.Tags) > 0 {
 		sb.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(f.Tags, ", ")))
 	}
+	if f.Classification != "" {
+		sb.WriteString(fmt.Sprintf("Classification: %s\n", f.Classification))
+	}
 	return sb.String()
 }
 
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+)
+
+// OperationID derives a short stable ID for moving source to destination
+// under a rule, so the same operation carries the same ID in plans, the
+// pending queue and the activity log from one run to the next
+func OperationID(source, destination, rule string) string {
+	h := sha256.New()
+	h.Write([]byte(filepath.Clean(source)))
+	h.Write([]byte{0})
+	if destination != "" {
+		h.Write([]byte(filepath.Clean(destination)))
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(rule))
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
@@ -2,9 +2,10 @@ package types
 
 // OrganizeResult holds the outcome of an organization attempt for a single file
 type OrganizeResult struct {
+	ID              string `json:"id,omitempty"` // Operation ID of the planned move, see OperationID
 	SourcePath      string `json:"source_path"`
 	DestinationPath string `json:"destination_path"`
 	Moved           bool   `json:"moved"`
+	Copied          bool   `json:"copied,omitempty"` // Moved by copying; the original is still in place
 	Error           error  `json:"error,omitempty"`
 }
-
@@ -1,10 +1,50 @@
 package types
 
+// PatternType says how a pattern's Match is interpreted
+type PatternType string
+
+const (
+	GlobPattern  PatternType = "glob"  // Shell-style glob (the default)
+	RegexPattern PatternType = "regex" // Regular expression; named groups can be used in the target
+)
+
 // Pattern defines a rule for matching files and specifying their target directory.
 // It is used within the application's configuration.
 type Pattern struct {
-	Match  string `yaml:"match"`  // Glob pattern to match filenames (e.g., "*.pdf", "report_*.docx").
-	Target string `yaml:"target"` // Target directory path where matched files should be moved (e.g., "Documents/Reports", "Images/Screenshots").
+	Match  string      `yaml:"match"`                  // Glob pattern to match filenames (e.g., "*.pdf", "report_*.docx").
+	Target string      `yaml:"target"`                 // Target directory path where matched files should be moved (e.g., "Documents/Reports", "Images/Screenshots").
+	Type   PatternType `yaml:"pattern_type,omitempty"` // "glob" (default) or "regex"; regex targets can refer to named groups as {name}
+
+	IgnoreCase       bool `yaml:"ignore_case,omitempty"`       // Match regardless of case, so *.jpg also matches .JPG
+	ExtensionAliases bool `yaml:"extension_aliases,omitempty"` // Treat alternative spellings of an extension (jpeg/jpg, tif/tiff, htm/html) as the same
+
+	Exclude  []string `yaml:"exclude,omitempty"`  // Globs for files the pattern skips even though they match, e.g. "*invoice*.pdf"
+	Priority int      `yaml:"priority,omitempty"` // Higher priorities are tried first; equal ones keep their order
+
+	Copy bool `yaml:"copy,omitempty"` // Copy matching files instead of moving them, leaving the originals untouched
+
+	// Checksum and signature files of matching files, such as foo.iso.sha256,
+	// foo.iso.asc or a SHA256SUMS listing foo.iso, go where the file goes
+	Companions bool `yaml:"companions,omitempty"`
+
+	Profile string `yaml:"profile,omitempty"` // Only apply while this config profile is active, e.g. "docked"
+
+	// Depths of the files the pattern applies to, counted from the directory
+	// being organized or watched: 1 is its top level, 2 its subfolders, and so
+	// on. 0 leaves either end open, so max_depth: 1 keeps a pattern to the top
+	// level of a recursive run.
+	MinDepth int `yaml:"min_depth,omitempty"`
+	MaxDepth int `yaml:"max_depth,omitempty"`
+
+	Tiers []SizeTier `yaml:"tiers,omitempty"` // Other targets for large files, e.g. videos over 2GB to a NAS
+}
+
+// SizeTier routes the files a pattern matches to another target once they
+// reach a size. Of a pattern's tiers, the one with the largest MinSize the
+// file reaches applies; smaller files go to the pattern's own Target.
+type SizeTier struct {
+	MinSize string `yaml:"min_size"` // e.g. "2GB" or "500 MiB"
+	Target  string `yaml:"target"`   // Directory for files of at least MinSize, like Pattern.Target
 }
 
 // Note: Removed redundant fields Glob, Prefixes, Suffixes, DestDir for clarity
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // TriggerType defines what causes a workflow to be executed
 type TriggerType string
 
@@ -14,6 +16,8 @@ const (
 	ManualTrigger TriggerType = "manual"
 	// ScheduledTrigger runs on a defined schedule
 	ScheduledTrigger TriggerType = "scheduled"
+	// WebhookTrigger runs when an external system calls the daemon's webhook endpoint
+	WebhookTrigger TriggerType = "webhook"
 )
 
 // ActionType defines what kind of operation the workflow performs
@@ -32,6 +36,32 @@ const (
 	DeleteAction ActionType = "delete"
 	// ExecuteAction runs a specified command
 	ExecuteAction ActionType = "execute"
+	// WebhookAction posts file metadata to a URL
+	WebhookAction ActionType = "webhook"
+)
+
+// WorkflowMode controls whether a workflow's actions are carried out
+type WorkflowMode string
+
+const (
+	// ActiveMode performs the workflow's actions (the default)
+	ActiveMode WorkflowMode = "active"
+	// DryRunMode only reports the actions, but still claims the event
+	DryRunMode WorkflowMode = "dry_run"
+	// ShadowMode reports the actions and records them to history without
+	// claiming the event, so other workflows and rules still process the file
+	ShadowMode WorkflowMode = "shadow"
+)
+
+// FailurePolicy controls what becomes of a workflow's completed actions when a later one fails
+type FailurePolicy string
+
+const (
+	// StopOnFailure stops at the failed action and keeps what was done (the default)
+	StopOnFailure FailurePolicy = "stop"
+	// RollbackOnFailure undoes the completed actions, last first. Deletes wait
+	// until every other action has succeeded, so a rolled back file still exists.
+	RollbackOnFailure FailurePolicy = "rollback"
 )
 
 // ConditionType defines what type of condition to evaluate
@@ -46,6 +76,17 @@ const (
 	FileNameCondition ConditionType = "file_name"
 	// FileAgeCondition evaluates based on file creation/modification time
 	FileAgeCondition ConditionType = "file_age"
+	// LastOpenedCondition evaluates how long ago a file was last opened, from
+	// the platform's recent files or access times, e.g. "not opened in 90 days"
+	LastOpenedCondition ConditionType = "last_opened"
+	// FileTagCondition evaluates based on content tags such as "screenshot"
+	FileTagCondition ConditionType = "file_tag"
+	// MetadataCondition evaluates a metadata value named by the field, e.g. "resolution"
+	MetadataCondition ConditionType = "metadata"
+	// DirectoryCountCondition evaluates how many items a directory holds: the
+	// one named by the field, or the file's own, e.g. "greater_than 200" to
+	// clean up once Downloads gets cluttered
+	DirectoryCountCondition ConditionType = "directory_count"
 	// CustomCondition evaluates a custom expression
 	CustomCondition ConditionType = "custom"
 )
@@ -90,21 +131,69 @@ type Action struct {
 
 // Trigger defines what causes a workflow to run
 type Trigger struct {
-	Type     TriggerType `yaml:"type" json:"type"`                             // Type of trigger
-	Pattern  string      `yaml:"pattern,omitempty" json:"pattern,omitempty"`   // File pattern for pattern-based triggers
-	Schedule string      `yaml:"schedule,omitempty" json:"schedule,omitempty"` // Cron-like schedule for scheduled triggers
+	Type     TriggerType  `yaml:"type" json:"type"`                             // Type of trigger
+	Pattern  string       `yaml:"pattern,omitempty" json:"pattern,omitempty"`   // File pattern for pattern-based triggers
+	Schedule string       `yaml:"schedule,omitempty" json:"schedule,omitempty"` // Cron-like schedule for scheduled triggers
+	Windows  []TimeWindow `yaml:"windows,omitempty" json:"windows,omitempty"`   // Optional times the trigger may fire (any window allows)
+}
+
+// TimeWindow restricts a trigger to certain days and times of day (local time)
+type TimeWindow struct {
+	Days  []string `yaml:"days,omitempty" json:"days,omitempty"`   // Weekdays ("mon", "tuesday"); empty allows every day
+	Start string   `yaml:"start,omitempty" json:"start,omitempty"` // Start time "HH:MM"; empty means midnight
+	End   string   `yaml:"end,omitempty" json:"end,omitempty"`     // End time "HH:MM"; may be before Start to span midnight
 }
 
 // Workflow defines a complete workflow with trigger, conditions, and actions
 type Workflow struct {
-	ID          string      `yaml:"id" json:"id"`                                       // Unique identifier for the workflow
-	Name        string      `yaml:"name" json:"name"`                                   // Human-readable name
-	Description string      `yaml:"description,omitempty" json:"description,omitempty"` // Optional description
-	Enabled     bool        `yaml:"enabled" json:"enabled"`                             // Whether the workflow is active
-	Trigger     Trigger     `yaml:"trigger" json:"trigger"`                             // What activates this workflow
-	Conditions  []Condition `yaml:"conditions,omitempty" json:"conditions,omitempty"`   // Optional conditions that must be met
-	Actions     []Action    `yaml:"actions" json:"actions"`                             // Actions to perform
-	Priority    int         `yaml:"priority,omitempty" json:"priority,omitempty"`       // Optional execution priority (higher runs first)
+	ID          string       `yaml:"id" json:"id"`                                       // Unique identifier for the workflow
+	Name        string       `yaml:"name" json:"name"`                                   // Human-readable name
+	Description string       `yaml:"description,omitempty" json:"description,omitempty"` // Optional description
+	Enabled     bool         `yaml:"enabled" json:"enabled"`                             // Whether the workflow is active
+	Trigger     Trigger      `yaml:"trigger,omitempty" json:"trigger"`                   // What activates this workflow
+	Triggers    []Trigger    `yaml:"triggers,omitempty" json:"triggers,omitempty"`       // Further triggers; any one of them activates the workflow too
+	Conditions  []Condition  `yaml:"conditions,omitempty" json:"conditions,omitempty"`   // Optional conditions that must be met
+	Actions     []Action     `yaml:"actions" json:"actions"`                             // Actions to perform
+	Priority    int          `yaml:"priority,omitempty" json:"priority,omitempty"`       // Optional execution priority (higher runs first)
+	Mode        WorkflowMode `yaml:"mode,omitempty" json:"mode,omitempty"`               // active (default), dry_run or shadow
+	Profile     string       `yaml:"profile,omitempty" json:"profile,omitempty"`         // Only run automatically while this config profile is active
+
+	OnFailure FailurePolicy `yaml:"on_failure,omitempty" json:"on_failure,omitempty"` // stop (default) or rollback
+	Retries   int           `yaml:"retries,omitempty" json:"retries,omitempty"`       // Extra attempts at a failing action before on_failure applies
+
+	Vars          map[string]string `yaml:"vars,omitempty" json:"vars,omitempty"`                     // Variables referenced as ${name}; override shared ones
+	UseConditions []string          `yaml:"use_conditions,omitempty" json:"use_conditions,omitempty"` // Shared condition blocks appended to Conditions
+}
+
+// AllTriggers returns every trigger of the workflow: Trigger, when it is set,
+// followed by Triggers
+func (w Workflow) AllTriggers() []Trigger {
+	triggers := make([]Trigger, 0, len(w.Triggers)+1)
+	if w.Trigger.Type != "" {
+		triggers = append(triggers, w.Trigger)
+	}
+	return append(triggers, w.Triggers...)
+}
+
+// WorkflowFragment holds variables and named condition blocks shared by every
+// workflow in a directory. Fragments live in files whose names start with "_".
+type WorkflowFragment struct {
+	Vars       map[string]string      `yaml:"vars,omitempty" json:"vars,omitempty"`             // Shared variables
+	Conditions map[string][]Condition `yaml:"conditions,omitempty" json:"conditions,omitempty"` // Named condition blocks
+}
+
+// WorkflowRunRecord is a history entry describing one workflow execution
+type WorkflowRunRecord struct {
+	Time         time.Time    `json:"time"`
+	WorkflowID   string       `json:"workflow_id"`
+	WorkflowName string       `json:"workflow_name"`
+	Mode         WorkflowMode `json:"mode"`
+	FilePath     string       `json:"file_path"`
+	Actions      []string     `json:"actions"`
+	Success      bool         `json:"success"`
+	Error        string       `json:"error,omitempty"`
+	RolledBack   []string     `json:"rolled_back,omitempty"` // Actions undone after the failure
+	Trace        string       `json:"trace,omitempty"`       // ID following the file across subsystems
 }
 
 // WorkflowResult represents the result of executing a workflow
@@ -115,4 +204,5 @@ type WorkflowResult struct {
 	FilePath     string `json:"file_path,omitempty"`
 	Message      string `json:"message,omitempty"`
 	Error        error  `json:"error,omitempty"`
+	Trace        string `json:"trace,omitempty"`
 }
@@ -0,0 +1,125 @@
+package workflow
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"sortd/internal/units"
+	"sortd/pkg/types"
+)
+
+// conditionSize reads a size condition's bound. The value can carry its own
+// unit ("10MB", "2.5 GiB") or take value_unit's.
+func conditionSize(condition types.Condition) (int64, error) {
+	return units.ParseSize(strings.TrimSpace(condition.Value + " " + condition.ValueUnit))
+}
+
+// conditionAge reads an age condition's bound: "3 weeks", "yesterday", a date,
+// or a number of value_units. A bare number is seconds, as it always was.
+func conditionAge(condition types.Condition, now time.Time) (time.Duration, error) {
+	if condition.ValueUnit == "" {
+		if seconds, err := strconv.ParseFloat(strings.TrimSpace(condition.Value), 64); err == nil && seconds >= 0 {
+			if seconds*float64(time.Second) >= math.MaxInt64 {
+				return 0, fmt.Errorf("age %q is too long", condition.Value)
+			}
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+	}
+	return units.ParseAge(strings.TrimSpace(condition.Value+" "+condition.ValueUnit), now)
+}
+
+// conditionCount reads a directory count condition's bound, a whole number of items
+func conditionCount(condition types.Condition) (int, error) {
+	count, err := strconv.Atoi(strings.TrimSpace(condition.Value))
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("invalid item count %q", condition.Value)
+	}
+	return count, nil
+}
+
+// directoryCount counts the items directly inside dir, files and folders
+// alike, leaving out hidden and backup files
+func directoryCount(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// countedDirectory returns the directory a directory count condition counts
+// for a file: the condition's field, or the directory holding the file
+func countedDirectory(condition types.Condition, filePath string) string {
+	if condition.Field != "" {
+		return condition.Field
+	}
+	return filepath.Dir(filePath)
+}
+
+// compareDirectoryCount checks the number of items in dir against a
+// directory count condition
+func compareDirectoryCount(condition types.Condition, dir string) bool {
+	target, err := conditionCount(condition)
+	if err != nil {
+		return false
+	}
+	count, err := directoryCount(dir)
+	if err != nil {
+		return false
+	}
+
+	switch condition.Operator {
+	case types.Equals:
+		return count == target
+	case types.NotEquals:
+		return count != target
+	case types.GreaterThan:
+		return count > target
+	case types.LessThan:
+		return count < target
+	default:
+		return false
+	}
+}
+
+// ValidateCondition checks that a size, age or count condition's value can be
+// read, so a typo is reported instead of the condition quietly never matching.
+// Values with ${variables} should be checked once they are expanded.
+func ValidateCondition(condition types.Condition) error {
+	var err error
+	switch condition.Type {
+	case types.FileSizeCondition:
+		_, err = conditionSize(condition)
+	case types.FileAgeCondition, types.LastOpenedCondition:
+		_, err = conditionAge(condition, time.Now())
+	case types.DirectoryCountCondition:
+		_, err = conditionCount(condition)
+	}
+	if err != nil {
+		return fmt.Errorf("%s condition: %w", condition.Type, err)
+	}
+	return nil
+}
+
+// validateConditions checks every condition of a resolved workflow
+func validateConditions(conditions []types.Condition) error {
+	for _, condition := range conditions {
+		if err := ValidateCondition(condition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/internal/fsops"
+	"sortd/pkg/types"
+)
+
+// snapshot returns every file and directory under root with its content
+func snapshot(t *testing.T, root string) map[string]string {
+	t.Helper()
+	tree := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		if d.IsDir() {
+			tree[rel] = "(dir)"
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		tree[rel] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to snapshot %s: %v", root, err)
+	}
+	return tree
+}
+
+// TestDryRunWritesNothing runs workflows through every action that changes
+// files, in dry-run mode and for real, and checks that only the real runs
+// reach the file system
+func TestDryRunWritesNothing(t *testing.T) {
+	createDir := map[string]string{"createTargetDir": "true"}
+	overwrite := map[string]string{"overwrite": "true"}
+
+	workflows := []types.Workflow{
+		{ID: "move", Actions: []types.Action{{Type: types.MoveAction, Target: "{root}/new", Options: createDir}}},
+		{ID: "move-over", Actions: []types.Action{{Type: types.MoveAction, Target: "{root}/taken", Options: overwrite}}},
+		{ID: "move-identical", Actions: []types.Action{{Type: types.MoveAction, Target: "{root}/identical"}}},
+		{ID: "copy", Actions: []types.Action{{Type: types.CopyAction, Target: "{root}/new", Options: createDir}}},
+		{ID: "copy-over", Actions: []types.Action{{Type: types.CopyAction, Target: "{root}/taken", Options: overwrite}}},
+		{ID: "rename", Actions: []types.Action{{Type: types.RenameAction, Target: "renamed.txt"}}},
+		{ID: "rename-over", Actions: []types.Action{{Type: types.RenameAction, Target: "other.txt", Options: overwrite}}},
+		{ID: "delete", Actions: []types.Action{{Type: types.DeleteAction}}},
+		{ID: "rollback", OnFailure: types.RollbackOnFailure, Actions: []types.Action{
+			{Type: types.CopyAction, Target: "{root}/new", Options: createDir},
+			{Type: types.MoveAction, Target: "{root}/taken"},
+			{Type: types.DeleteAction},
+		}},
+	}
+
+	setup := func(t *testing.T, dryRun bool) (*Manager, *fsops.Recorder, string, string) {
+		t.Helper()
+		root := t.TempDir()
+		write := func(rel, content string) {
+			path := filepath.Join(root, rel)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+		}
+		write("inbox/notes.txt", "notes")
+		write("inbox/other.txt", "other")
+		write("taken/notes.txt", "older notes")
+		write("identical/notes.txt", "notes")
+
+		manager, err := NewManager(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create manager: %v", err)
+		}
+		manager.SetDuplicates(config.DuplicateSettings{CompareContent: true, DeleteIdentical: true})
+		manager.SetDryRun(dryRun)
+		recorder := fsops.NewRecorder(fsops.OS)
+		manager.fs = recorder
+		return manager, recorder, root, filepath.Join(root, "inbox", "notes.txt")
+	}
+
+	for _, wf := range workflows {
+		t.Run(wf.ID, func(t *testing.T) {
+			for _, dryRun := range []bool{true, false} {
+				manager, recorder, root, file := setup(t, dryRun)
+				resolved := wf
+				resolved.Actions = nil
+				for _, action := range wf.Actions {
+					if action.Target != "" {
+						action.Target = filepath.Join(root, filepath.Base(action.Target))
+						if action.Type == types.RenameAction {
+							action.Target = filepath.Base(action.Target)
+						}
+					}
+					resolved.Actions = append(resolved.Actions, action)
+				}
+
+				before := snapshot(t, root)
+				result := manager.executeWorkflow(resolved, file)
+				if !result.Success {
+					t.Fatalf("dry run %v: workflow failed: %v", dryRun, result.Error)
+				}
+				ops := recorder.Ops()
+				changed := !reflect.DeepEqual(before, snapshot(t, root))
+
+				if dryRun && (len(ops) > 0 || changed) {
+					t.Errorf("Dry run changed files: %+v", ops)
+				}
+				if !dryRun && (len(ops) == 0 || !changed) {
+					t.Errorf("The real run should have changed files through the recorder")
+				}
+			}
+		})
+	}
+}
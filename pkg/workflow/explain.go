@@ -0,0 +1,156 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sortd/internal/globs"
+	"sortd/pkg/types"
+)
+
+// Explanation says whether a workflow would act on a newly arrived file, and why
+type Explanation struct {
+	Workflow types.Workflow
+	Matched  bool
+	Reason   string   // Why the workflow doesn't apply; empty when it does
+	Actions  []string // What it would do, with placeholders filled in
+}
+
+// Explain evaluates every workflow against a file the way the watch daemon
+// would when the file arrives, without running anything. The file need not
+// exist; conditions that need its contents then report that they can't be
+// checked.
+func (m *Manager) Explain(filePath string, now time.Time) []Explanation {
+	fileInfo, statErr := os.Stat(filePath)
+	if statErr != nil {
+		fileInfo = nil
+	}
+
+	explanations := make([]Explanation, 0, len(m.workflows))
+	for _, wf := range m.workflows {
+		explanation := Explanation{Workflow: wf}
+		explanation.Reason = m.explainMismatch(&explanation.Workflow, filePath, fileInfo, now)
+		if explanation.Reason == "" {
+			explanation.Matched = true
+			explanation.Actions = m.describeActions(explanation.Workflow, filePath)
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations
+}
+
+// explainMismatch returns why the workflow wouldn't act on the file, or "" if
+// it would. The workflow is replaced by its resolved form.
+func (m *Manager) explainMismatch(wf *types.Workflow, filePath string, fileInfo os.FileInfo, now time.Time) string {
+	if !wf.Enabled {
+		return "disabled"
+	}
+	if !m.profiles.Active(wf.Profile) {
+		return fmt.Sprintf("profile %q isn't active", wf.Profile)
+	}
+	if name := filepath.Base(filePath); strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+		return "hidden and backup files are never handed to workflows"
+	}
+
+	resolved, err := m.resolveWorkflow(*wf)
+	if err != nil {
+		return fmt.Sprintf("can't be resolved: %v", err)
+	}
+	*wf = resolved
+
+	triggers := wf.AllTriggers()
+	if len(triggers) == 0 {
+		return "has no trigger"
+	}
+	// One trigger firing is enough; otherwise report why each one doesn't
+	reasons := make([]string, 0, len(triggers))
+	for _, trigger := range triggers {
+		reason := explainTrigger(trigger, filePath, now)
+		if reason == "" {
+			reasons = nil
+			break
+		}
+		reasons = append(reasons, reason)
+	}
+	if len(reasons) > 0 {
+		return strings.Join(reasons, "; ")
+	}
+
+	for _, condition := range wf.Conditions {
+		if fileInfo == nil && conditionNeedsFile(condition.Type) {
+			return fmt.Sprintf("condition %s can't be checked without the file", describeCondition(condition))
+		}
+		if !m.evaluateCondition(condition, filePath, fileInfo) {
+			return fmt.Sprintf("condition %s not met", describeCondition(condition))
+		}
+	}
+	return ""
+}
+
+// explainTrigger returns why a trigger wouldn't fire for a newly arrived
+// file, or "" if it would
+func explainTrigger(trigger types.Trigger, filePath string, now time.Time) string {
+	switch trigger.Type {
+	case types.FileCreated, types.FilePatternMatch:
+	default:
+		return fmt.Sprintf("triggered by %s, not by new files", trigger.Type)
+	}
+	if !TriggerAllowedAt(trigger, now) {
+		return "outside its time windows right now"
+	}
+
+	if trigger.Pattern != "" {
+		matched, err := globs.Match(trigger.Pattern, filePath)
+		if err != nil {
+			return fmt.Sprintf("invalid trigger pattern: %v", err)
+		}
+		if !matched && globs.IsPathPattern(trigger.Pattern) {
+			return fmt.Sprintf("trigger pattern %q doesn't match the path", trigger.Pattern)
+		}
+		if !matched {
+			return fmt.Sprintf("trigger pattern %q doesn't match the name", trigger.Pattern)
+		}
+	}
+	return ""
+}
+
+// describeActions lists what a workflow would do to a file
+func (m *Manager) describeActions(wf types.Workflow, filePath string) []string {
+	var actions []string
+	for _, action := range wf.Actions {
+		note := ""
+		if expanded, err := m.expandActionTemplates(action, filePath); err == nil {
+			action = expanded
+		} else {
+			note = fmt.Sprintf(" (placeholders unresolved: %v)", err)
+		}
+		actions = append(actions, describeAction(action, filePath)+note)
+	}
+	return actions
+}
+
+// conditionNeedsFile reports whether a condition reads more than the file's name
+func conditionNeedsFile(conditionType types.ConditionType) bool {
+	switch conditionType {
+	case types.FileNameCondition, types.FileTypeCondition, types.DirectoryCountCondition:
+		return false
+	default:
+		return true
+	}
+}
+
+// describeCondition renders a condition the way it reads in a workflow file
+func describeCondition(condition types.Condition) string {
+	parts := []string{string(condition.Type)}
+	if condition.Field != "" {
+		parts = append(parts, condition.Field)
+	}
+	parts = append(parts, string(condition.Operator), fmt.Sprintf("%q", condition.Value))
+	if condition.ValueUnit != "" {
+		parts = append(parts, condition.ValueUnit)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
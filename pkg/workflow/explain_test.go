@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sortd/pkg/types"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "invoice-7.pdf")
+	require.NoError(t, os.WriteFile(existing, make([]byte, 2048), 0644))
+
+	manager := &Manager{}
+	manager.SetMetadata(func(path string) (map[string]string, error) {
+		return map[string]string{"year": "2024"}, nil
+	})
+	manager.workflows = []types.Workflow{
+		{
+			ID: "invoices", Name: "Invoices", Enabled: true,
+			Trigger: types.Trigger{Type: types.FileCreated, Pattern: "**/invoice*"},
+			Conditions: []types.Condition{
+				{Type: types.FileSizeCondition, Operator: types.GreaterThan, Value: "1", ValueUnit: "KB"},
+			},
+			Actions: []types.Action{{Type: types.MoveAction, Target: "/archive/{year}"}},
+		},
+		{ID: "off", Name: "Off", Trigger: types.Trigger{Type: types.FileCreated}},
+		{ID: "hook", Name: "Hook", Enabled: true, Trigger: types.Trigger{Type: types.WebhookTrigger}},
+	}
+
+	explanations := manager.Explain(existing, time.Now())
+	require.Len(t, explanations, 3)
+	assert.True(t, explanations[0].Matched, explanations[0].Reason)
+	assert.Equal(t, []string{"move " + existing + " to /archive/2024/invoice-7.pdf"}, explanations[0].Actions)
+	assert.Equal(t, "disabled", explanations[1].Reason)
+	assert.Contains(t, explanations[2].Reason, "not by new files")
+
+	// A name alone can be checked against the trigger, but not the size condition
+	missing := manager.Explain(filepath.Join(dir, "invoice-8.pdf"), time.Now())
+	assert.False(t, missing[0].Matched)
+	assert.Contains(t, missing[0].Reason, "can't be checked without the file")
+
+	other := manager.Explain(filepath.Join(dir, "photo.jpg"), time.Now())
+	assert.Contains(t, other[0].Reason, "trigger pattern")
+}
@@ -0,0 +1,138 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+)
+
+// varPattern matches ${name} references in workflow fields
+var varPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// isFragmentFile reports whether a file in the workflow directory holds shared
+// fragments rather than a workflow
+func isFragmentFile(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+// loadFragment merges a fragment file's variables and condition blocks into the manager
+func (m *Manager) loadFragment(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read fragment file %s: %w", path, err)
+	}
+
+	var fragment types.WorkflowFragment
+	if err := yaml.Unmarshal(data, &fragment); err != nil {
+		return fmt.Errorf("failed to parse fragment file %s: %w", path, err)
+	}
+
+	for name, value := range fragment.Vars {
+		if _, exists := m.vars[name]; exists {
+			return fmt.Errorf("variable %s in %s is already defined by another fragment", name, path)
+		}
+		m.vars[name] = config.ExpandHome(value)
+	}
+
+	for name, conditions := range fragment.Conditions {
+		if _, exists := m.conditionSets[name]; exists {
+			return fmt.Errorf("condition block %s in %s is already defined by another fragment", name, path)
+		}
+		m.conditionSets[name] = conditions
+	}
+
+	return nil
+}
+
+// resolveWorkflow returns a copy of the workflow with shared condition blocks
+// appended and ${name} variables substituted. Workflow variables take precedence
+// over fragment variables. The stored workflow is left untouched so it can be
+// saved back with its references intact.
+func (m *Manager) resolveWorkflow(workflow types.Workflow) (types.Workflow, error) {
+	vars := make(map[string]string, len(m.vars)+len(workflow.Vars))
+	for name, value := range m.vars {
+		vars[name] = value
+	}
+	for name, value := range workflow.Vars {
+		vars[name] = config.ExpandHome(value)
+	}
+
+	resolved := workflow
+	var err error
+	expand := func(s string) string {
+		out, expandErr := expandVars(s, vars)
+		if expandErr != nil && err == nil {
+			err = expandErr
+		}
+		return out
+	}
+
+	resolved.Trigger.Pattern = expand(workflow.Trigger.Pattern)
+	if workflow.Triggers != nil {
+		resolved.Triggers = make([]types.Trigger, len(workflow.Triggers))
+		for i, trigger := range workflow.Triggers {
+			trigger.Pattern = expand(trigger.Pattern)
+			resolved.Triggers[i] = trigger
+		}
+	}
+
+	resolved.Conditions = append([]types.Condition{}, workflow.Conditions...)
+	for _, name := range workflow.UseConditions {
+		conditions, ok := m.conditionSets[name]
+		if !ok {
+			return workflow, fmt.Errorf("unknown condition block: %s", name)
+		}
+		resolved.Conditions = append(resolved.Conditions, conditions...)
+	}
+	for i := range resolved.Conditions {
+		resolved.Conditions[i].Value = expand(resolved.Conditions[i].Value)
+		if resolved.Conditions[i].Type == types.DirectoryCountCondition {
+			resolved.Conditions[i].Field = config.ExpandHome(expand(resolved.Conditions[i].Field))
+		}
+	}
+
+	resolved.Actions = make([]types.Action, len(workflow.Actions))
+	for i, action := range workflow.Actions {
+		action.Target = expand(action.Target)
+		if action.Options != nil {
+			options := make(map[string]string, len(action.Options))
+			for key, value := range action.Options {
+				options[key] = expand(value)
+			}
+			action.Options = options
+		}
+		resolved.Actions[i] = action
+	}
+
+	if err != nil {
+		return workflow, err
+	}
+	return resolved, nil
+}
+
+// expandVars substitutes ${name} references, failing on undefined variables
+func expandVars(s string, vars map[string]string) (string, error) {
+	var missing string
+	out := varPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := varPattern.FindStringSubmatch(ref)[1]
+		value, ok := vars[name]
+		if !ok {
+			if missing == "" {
+				missing = name
+			}
+			return ref
+		}
+		return value
+	})
+
+	if missing != "" {
+		return s, fmt.Errorf("undefined variable: %s", missing)
+	}
+	return out, nil
+}
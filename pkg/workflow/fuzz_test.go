@@ -0,0 +1,124 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"sortd/internal/globs"
+	"sortd/pkg/types"
+)
+
+func FuzzEvaluateCondition(f *testing.F) {
+	for _, seed := range []types.Condition{
+		{Type: types.FileSizeCondition, Operator: types.GreaterThan, Value: "1", ValueUnit: "KB"},
+		{Type: types.FileSizeCondition, Operator: types.LessThan, Value: "9223372036854775807", ValueUnit: "GB"},
+		{Type: types.FileSizeCondition, Operator: types.GreaterThan, Value: "-9223372036854775808", ValueUnit: "mb"},
+		{Type: types.FileAgeCondition, Operator: types.GreaterThan, Value: "NaN", ValueUnit: "days"},
+		{Type: types.FileAgeCondition, Operator: types.LessThan, Value: "1e308", ValueUnit: "hours"},
+		{Type: types.FileNameCondition, Operator: types.MatchesRegex, Value: "(?i)^report"},
+		{Type: types.FileTypeCondition, Operator: types.Contains, Value: "txt"},
+		{Type: types.MetadataCondition, Field: "resolution", Operator: types.Equals, Value: "1920x1080"},
+	} {
+		f.Add(string(seed.Type), seed.Field, string(seed.Operator), seed.Value, seed.ValueUnit)
+	}
+
+	file := filepath.Join(f.TempDir(), "report.txt")
+	if err := os.WriteFile(file, make([]byte, 2048), 0644); err != nil {
+		f.Fatal(err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		f.Fatal(err)
+	}
+	manager := &Manager{}
+
+	f.Fuzz(func(t *testing.T, conditionType, field, operator, value, unit string) {
+		condition := types.Condition{
+			Type:      types.ConditionType(conditionType),
+			Field:     field,
+			Operator:  types.OperatorType(operator),
+			Value:     value,
+			ValueUnit: unit,
+		}
+		manager.evaluateCondition(condition, file, info)
+
+		// Sizes compare the way the numbers do, however large the unit makes them
+		n, err := strconv.ParseInt(value, 10, 64)
+		if condition.Type != types.FileSizeCondition || err != nil {
+			return
+		}
+		multiplier := 1.0
+		switch strings.ToUpper(unit) {
+		case "KB":
+			multiplier = 1 << 10
+		case "MB":
+			multiplier = 1 << 20
+		case "GB":
+			multiplier = 1 << 30
+		}
+		bound := float64(n) * multiplier
+		condition.Operator = types.GreaterThan
+		if greater := manager.evaluateCondition(condition, file, info); greater && float64(info.Size()) <= bound {
+			t.Fatalf("size %d counted as greater than %q %q", info.Size(), value, unit)
+		}
+	})
+}
+
+func FuzzParseWorkflow(f *testing.F) {
+	for _, seed := range []string{
+		`id: pdfs
+name: PDFs
+enabled: true
+trigger: {type: file_created, pattern: "*.pdf"}
+actions: [{type: move, target: "${docs}/PDF"}]
+vars: {docs: ~/Documents}
+`,
+		`id: night
+name: Night
+trigger:
+  type: file_pattern_match
+  pattern: "**/{a,b}*"
+  windows: [{days: [mon, fri], start: "22:00", end: "06:00"}]
+conditions: [{type: file_size, operator: greater_than, value: "10", value_unit: MB}]
+actions: [{type: copy, target: /tmp}]
+on_failure: rollback
+retries: 2
+`,
+		`id: x
+name: x
+use_conditions: [missing]
+actions: [{type: delete}]
+`,
+		`{id: [}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var workflow types.Workflow
+		if err := yaml.Unmarshal(data, &workflow); err != nil {
+			return
+		}
+		if err := validateWorkflow(&workflow); err != nil {
+			return
+		}
+
+		manager := &Manager{vars: map[string]string{}, conditionSets: map[string][]types.Condition{}}
+		resolved, err := manager.resolveWorkflow(workflow)
+		if err != nil {
+			return
+		}
+		if resolved.Trigger.Pattern != "" {
+			if m, err := globs.Compile(resolved.Trigger.Pattern); err == nil {
+				m.Match("/home/me/Downloads/report.pdf")
+			}
+		}
+		TriggerAllowedAt(resolved.Trigger, time.Date(2024, 5, 3, 23, 30, 0, 0, time.Local))
+	})
+}
@@ -0,0 +1,13 @@
+package workflow_test
+
+import (
+	"testing"
+
+	"sortd/pkg/workflow/testutil"
+)
+
+// TestGolden plays the scenarios in testdata/golden; add a file there to pin
+// down a workflow behaviour
+func TestGolden(t *testing.T) {
+	testutil.RunGoldenDir(t, "testdata/golden")
+}
@@ -0,0 +1,107 @@
+package workflow
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+)
+
+// historyFile is the JSON-lines file in the workflow directory that records runs
+const historyFile = "history.jsonl"
+
+// DefaultDir returns the directory workflows are loaded from by default
+func DefaultDir() (string, error) {
+	return config.WorkflowsDir()
+}
+
+// recordRun appends a run to the history file. History is best effort: a
+// failure to write it never fails the workflow itself.
+func (m *Manager) recordRun(record types.WorkflowRunRecord) {
+	if m.configPath == "" {
+		return
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding workflow history: %v\n", err)
+		return
+	}
+
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(m.configPath, historyFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening workflow history: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing workflow history: %v\n", err)
+	}
+}
+
+// History returns the recorded workflow runs, oldest first
+func (m *Manager) History() ([]types.WorkflowRunRecord, error) {
+	return ReadHistory(m.configPath)
+}
+
+// ReadHistory reads the workflow run history kept in a workflow directory.
+// A missing history file yields no records.
+func ReadHistory(dir string) ([]types.WorkflowRunRecord, error) {
+	f, err := os.Open(filepath.Join(dir, historyFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open workflow history: %w", err)
+	}
+	defer f.Close()
+
+	var records []types.WorkflowRunRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record types.WorkflowRunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse workflow history: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read workflow history: %w", err)
+	}
+
+	return records, nil
+}
+
+// describeAction summarises what an action does to a file, for logs and history
+func describeAction(action types.Action, filePath string) string {
+	fileName := filepath.Base(filePath)
+	switch action.Type {
+	case types.MoveAction:
+		return fmt.Sprintf("move %s to %s", filePath, filepath.Join(action.Target, fileName))
+	case types.CopyAction:
+		return fmt.Sprintf("copy %s to %s", filePath, filepath.Join(action.Target, fileName))
+	case types.RenameAction:
+		return fmt.Sprintf("rename %s to %s", filePath, filepath.Join(filepath.Dir(filePath), action.Target))
+	case types.TagAction:
+		return fmt.Sprintf("tag %s with '%s'", filePath, action.Target)
+	case types.DeleteAction:
+		return fmt.Sprintf("delete %s", filePath)
+	case types.ExecuteAction:
+		return fmt.Sprintf("execute %s (with file: %s)", action.Target, filePath)
+	case types.WebhookAction:
+		return fmt.Sprintf("post metadata of %s to %s", filePath, action.Target)
+	default:
+		return fmt.Sprintf("%s %s", action.Type, filePath)
+	}
+}
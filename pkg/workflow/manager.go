@@ -1,19 +1,29 @@
 package workflow
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/gobwas/glob"
 	"gopkg.in/yaml.v3"
 
+	"sortd/internal/atomicfile"
+	"sortd/internal/config"
+	"sortd/internal/fsops"
+	"sortd/internal/globs"
+	"sortd/internal/lastopened"
+	"sortd/internal/manifest"
+	"sortd/internal/organize"
+	"sortd/internal/profile"
+	"sortd/internal/quota"
+	"sortd/internal/trace"
 	"sortd/pkg/types"
 )
 
@@ -22,8 +32,49 @@ type Manager struct {
 	workflows  []types.Workflow
 	configPath string
 	dryRun     bool
+
+	// Shared variables and condition blocks loaded from fragment files
+	vars          map[string]string
+	conditionSets map[string][]types.Condition
+
+	// Serialises appends to the run history file
+	historyMu sync.Mutex
+
+	// Supplies content tags for file_tag conditions; nil means no file has tags
+	tagger TagFunc
+
+	// Supplies file metadata for metadata conditions and target placeholders
+	metadata      MetadataFunc
+	metadataCache map[string]cachedMetadata
+	metadataMu    sync.Mutex
+
+	// How taken targets are compared and renamed; the zero value keeps timestamped names
+	duplicates config.DuplicateSettings
+
+	// How actions failing on transient errors are retried; nil doesn't retry them
+	retry *config.RetrySettings
+
+	// Limits on target directories, shared with the organize engine; nil checks nothing
+	quotas *quota.Checker
+
+	// Checksum manifests of archive directories; nil keeps none
+	manifests *manifest.Keeper
+
+	// Which config profiles are active; workflows of inactive ones don't run
+	// on their own. nil treats every profile as active.
+	profiles *profile.Checker
+
+	// Keeps the manager in dry run mode, whatever SetDryRun says
+	audit bool
+
+	// Where actions move, copy and delete files; nil for the real file
+	// system. Dry runs replace it with one that changes nothing (see fsys).
+	fs fsops.FS
 }
 
+// TagFunc returns the content tags of a file, e.g. "receipt" or "screenshot"
+type TagFunc func(path string) ([]string, error)
+
 // NewManager creates a new workflow manager instance
 func NewManager(configPath string) (*Manager, error) {
 	manager := &Manager{
@@ -42,6 +93,8 @@ func NewManager(configPath string) (*Manager, error) {
 // LoadWorkflows loads workflow definitions from the config directory
 func (m *Manager) LoadWorkflows() error {
 	m.workflows = []types.Workflow{}
+	m.vars = make(map[string]string)
+	m.conditionSets = make(map[string][]types.Condition)
 
 	// Ensure the config directory exists
 	if err := os.MkdirAll(m.configPath, 0755); err != nil {
@@ -54,24 +107,34 @@ func (m *Manager) LoadWorkflows() error {
 		return fmt.Errorf("failed to read config directory: %w", err)
 	}
 
+	// Load shared fragments first so every workflow can reference them
+	var workflowFiles []string
 	for _, entry := range entries {
 		if entry.IsDir() || (!strings.HasSuffix(entry.Name(), ".yaml") && !strings.HasSuffix(entry.Name(), ".yml")) {
 			continue
 		}
 
 		path := filepath.Join(m.configPath, entry.Name())
+		if isFragmentFile(entry.Name()) {
+			if err := m.loadFragment(path); err != nil {
+				return err
+			}
+			continue
+		}
+		workflowFiles = append(workflowFiles, path)
+	}
+
+	for _, path := range workflowFiles {
 		data, err := os.ReadFile(path)
 		if err != nil {
 			return fmt.Errorf("failed to read workflow file %s: %w", path, err)
 		}
 
-		var workflow types.Workflow
-		if err := yaml.Unmarshal(data, &workflow); err != nil {
+		workflow, err := ParseWorkflow(data)
+		if err != nil {
 			return fmt.Errorf("failed to parse workflow file %s: %w", path, err)
 		}
-
-		// Validate the workflow
-		if err := validateWorkflow(&workflow); err != nil {
+		if err := m.Validate(workflow); err != nil {
 			return fmt.Errorf("invalid workflow in %s: %w", path, err)
 		}
 
@@ -81,6 +144,51 @@ func (m *Manager) LoadWorkflows() error {
 	return nil
 }
 
+// ParseWorkflow reads a workflow definition from YAML, without validating it
+func ParseWorkflow(data []byte) (types.Workflow, error) {
+	var workflow types.Workflow
+	err := yaml.Unmarshal(data, &workflow)
+	return workflow, err
+}
+
+// Validate checks a workflow the way loading it would: its fields, schedules
+// and time windows, that every variable and condition block it references
+// resolves against the shared fragments, and its trigger patterns and
+// conditions once resolved
+func (m *Manager) Validate(workflow types.Workflow) error {
+	if err := validateWorkflow(&workflow); err != nil {
+		return err
+	}
+
+	resolved, err := m.resolveWorkflow(workflow)
+	if err != nil {
+		return err
+	}
+	for _, trigger := range resolved.AllTriggers() {
+		if trigger.Pattern == "" {
+			continue
+		}
+		if err := globs.Validate(trigger.Pattern); err != nil {
+			return fmt.Errorf("trigger pattern: %w", err)
+		}
+	}
+	return validateConditions(resolved.Conditions)
+}
+
+// UniqueID returns id, or id with a number appended when a loaded workflow
+// already has it
+func (m *Manager) UniqueID(id string) string {
+	taken := make(map[string]bool)
+	for _, wf := range m.workflows {
+		taken[wf.ID] = true
+	}
+	unique := id
+	for n := 2; taken[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", id, n)
+	}
+	return unique
+}
+
 // validateWorkflow performs basic validation on a workflow definition
 func validateWorkflow(workflow *types.Workflow) error {
 	if workflow.ID == "" {
@@ -95,6 +203,32 @@ func validateWorkflow(workflow *types.Workflow) error {
 		return errors.New("workflow must have at least one action")
 	}
 
+	switch workflow.Mode {
+	case "", types.ActiveMode, types.DryRunMode, types.ShadowMode:
+	default:
+		return fmt.Errorf("invalid workflow mode: %s", workflow.Mode)
+	}
+
+	switch workflow.OnFailure {
+	case "", types.StopOnFailure, types.RollbackOnFailure:
+	default:
+		return fmt.Errorf("invalid on_failure policy: %s", workflow.OnFailure)
+	}
+	if workflow.Retries < 0 {
+		return errors.New("retries cannot be negative")
+	}
+
+	for _, trigger := range workflow.AllTriggers() {
+		if err := validateTimeWindows(trigger.Windows); err != nil {
+			return err
+		}
+		if trigger.Type == types.ScheduledTrigger {
+			if err := ValidateSchedule(trigger.Schedule); err != nil {
+				return fmt.Errorf("trigger schedule: %w", err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -103,6 +237,12 @@ func validateWorkflow(workflow *types.Workflow) error {
 // type, pattern, and conditions. If a matching workflow is found and executed,
 // it returns processed=true. If execution fails, it returns processed=false and the error.
 func (m *Manager) ProcessEvent(event fsnotify.Event) (processed bool, err error) {
+	return m.ProcessEventContext(context.Background(), event)
+}
+
+// ProcessEventContext is ProcessEvent for a file whose trace ID ctx carries
+// (see package trace). Without one, each workflow run gets a new ID.
+func (m *Manager) ProcessEventContext(ctx context.Context, event fsnotify.Event) (processed bool, err error) {
 	// Skip temporary and hidden files
 	fileName := filepath.Base(event.Name)
 	if strings.HasPrefix(fileName, ".") || strings.HasSuffix(fileName, "~") {
@@ -131,32 +271,27 @@ func (m *Manager) ProcessEvent(event fsnotify.Event) (processed bool, err error)
 	var workflowProcessed bool = false // Track if any workflow handled this
 
 	for _, workflow := range m.workflows {
-		if !workflow.Enabled {
+		if !workflow.Enabled || !m.profiles.Active(workflow.Profile) {
 			continue
 		}
 
-		// Check if trigger type matches
-		// Allow FilePatternMatch to trigger on Create or Write events
-		triggerMatches := (workflow.Trigger.Type == triggerType) ||
-			(workflow.Trigger.Type == types.FilePatternMatch && (triggerType == types.FileCreated || triggerType == types.FileModified))
-
-		if !triggerMatches {
+		workflow, resolveErr := m.resolveWorkflow(workflow)
+		if resolveErr != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving workflow %s: %v\n", workflow.ID, resolveErr)
 			continue
 		}
 
-		// --- Trigger Type Matches ---
-		// Now, always check the pattern if one is defined in the trigger
-		if workflow.Trigger.Pattern != "" {
-			patternMatcher, compileErr := glob.Compile(workflow.Trigger.Pattern)
-			if compileErr != nil {
-				fmt.Fprintf(os.Stderr, "Error compiling workflow pattern '%s' for %s: %v\n", workflow.Trigger.Pattern, workflow.ID, compileErr)
-				continue // Skip workflow with invalid pattern
-			}
-			// Match against the full path of the event
-			if !patternMatcher.Match(event.Name) {
-				continue // Pattern doesn't match
+		// Any one of the workflow's triggers can fire for the event
+		fired := false
+		for _, trigger := range workflow.AllTriggers() {
+			if triggerFires(workflow.ID, trigger, triggerType, event.Name, time.Now()) {
+				fired = true
+				break
 			}
 		}
+		if !fired {
+			continue
+		}
 		// At this point, the trigger type and pattern (if applicable) match
 
 		// Evaluate conditions using the fileInfo we got earlier
@@ -166,11 +301,17 @@ func (m *Manager) ProcessEvent(event fsnotify.Event) (processed bool, err error)
 
 		// --- Trigger and Conditions Met ---
 		// Execute the workflow actions
-		result := m.executeWorkflow(workflow, event.Name)
+		result := m.runWorkflow(ctx, workflow, event.Name)
+
+		// Shadow workflows only observe; leave the event for other workflows and rules
+		if workflow.Mode == types.ShadowMode {
+			fmt.Printf("Workflow %s (%s) shadow run [trace %s]: %s\n", workflow.Name, workflow.ID, result.Trace, result.Message)
+			continue
+		}
 		workflowProcessed = true // Mark that at least one workflow was triggered
 
 		// Log the result
-		fmt.Printf("Workflow %s (%s) execution: %v\n", workflow.Name, workflow.ID, result.Success)
+		fmt.Printf("Workflow %s (%s) execution [trace %s]: %v\n", workflow.Name, workflow.ID, result.Trace, result.Success)
 		if !result.Success && result.Error != nil {
 			fmt.Printf("  Error: %v\n", result.Error)
 			// If a workflow fails, return processed=true (it was attempted) but also return the error
@@ -187,6 +328,35 @@ func (m *Manager) ProcessEvent(event fsnotify.Event) (processed bool, err error)
 	return workflowProcessed, nil
 }
 
+// triggerFires reports whether a trigger fires for an event of the given type
+// on path at now
+func triggerFires(workflowID string, trigger types.Trigger, eventType types.TriggerType, path string, now time.Time) bool {
+	// Allow FilePatternMatch to trigger on Create or Write events
+	typeMatches := trigger.Type == eventType ||
+		(trigger.Type == types.FilePatternMatch && (eventType == types.FileCreated || eventType == types.FileModified))
+	if !typeMatches {
+		return false
+	}
+
+	// Respect the trigger's time windows (e.g. only reorganize overnight)
+	if !TriggerAllowedAt(trigger, now) {
+		return false
+	}
+
+	// Always check the pattern if one is defined in the trigger
+	if trigger.Pattern != "" {
+		patternMatcher, compileErr := globs.Compile(trigger.Pattern)
+		if compileErr != nil {
+			fmt.Fprintf(os.Stderr, "Error compiling workflow pattern '%s' for %s: %v\n", trigger.Pattern, workflowID, compileErr)
+			return false // Skip a trigger with an invalid pattern
+		}
+		if !patternMatcher.Match(path) {
+			return false
+		}
+	}
+	return true
+}
+
 // evaluateConditions checks if a file meets all the conditions
 func (m *Manager) evaluateConditions(conditions []types.Condition, filePath string, fileInfo os.FileInfo) bool {
 	if len(conditions) == 0 {
@@ -213,6 +383,14 @@ func (m *Manager) evaluateCondition(condition types.Condition, filePath string,
 		return m.evaluateFileTypeCondition(condition, filePath)
 	case types.FileAgeCondition:
 		return m.evaluateFileAgeCondition(condition, fileInfo)
+	case types.LastOpenedCondition:
+		return m.evaluateLastOpenedCondition(condition, filePath, fileInfo)
+	case types.FileTagCondition:
+		return m.evaluateFileTagCondition(condition, filePath)
+	case types.MetadataCondition:
+		return m.evaluateMetadataCondition(condition, filePath)
+	case types.DirectoryCountCondition:
+		return compareDirectoryCount(condition, countedDirectory(condition, filePath))
 	default:
 		return false
 	}
@@ -221,21 +399,11 @@ func (m *Manager) evaluateCondition(condition types.Condition, filePath string,
 // evaluateFileSizeCondition checks if a file's size meets the condition
 func (m *Manager) evaluateFileSizeCondition(condition types.Condition, fileInfo os.FileInfo) bool {
 	size := fileInfo.Size()
-	targetSize, err := strconv.ParseInt(condition.Value, 10, 64)
+	targetSize, err := conditionSize(condition)
 	if err != nil {
 		return false
 	}
 
-	// Apply unit multiplier if specified
-	switch strings.ToUpper(condition.ValueUnit) {
-	case "KB":
-		targetSize *= 1024
-	case "MB":
-		targetSize *= 1024 * 1024
-	case "GB":
-		targetSize *= 1024 * 1024 * 1024
-	}
-
 	switch condition.Operator {
 	case types.Equals:
 		return size == targetSize
@@ -296,23 +464,26 @@ func (m *Manager) evaluateFileTypeCondition(condition types.Condition, filePath
 
 // evaluateFileAgeCondition checks if a file's age meets the condition
 func (m *Manager) evaluateFileAgeCondition(condition types.Condition, fileInfo os.FileInfo) bool {
-	modTime := fileInfo.ModTime()
-	ageInSeconds := time.Since(modTime).Seconds()
+	return compareAge(condition, fileInfo.ModTime())
+}
+
+// evaluateLastOpenedCondition checks how long ago the file was last opened:
+// greater_than "90 days" holds for files not opened in 90 days
+func (m *Manager) evaluateLastOpenedCondition(condition types.Condition, filePath string, fileInfo os.FileInfo) bool {
+	opened, _ := lastopened.LastOpened(filePath, fileInfo)
+	return compareAge(condition, opened)
+}
 
-	targetAge, err := strconv.ParseFloat(condition.Value, 64)
+// compareAge checks the time since since against an age condition
+func compareAge(condition types.Condition, since time.Time) bool {
+	now := time.Now()
+	ageInSeconds := now.Sub(since).Seconds()
+
+	target, err := conditionAge(condition, now)
 	if err != nil {
 		return false
 	}
-
-	// Apply unit multiplier if specified
-	switch strings.ToLower(condition.ValueUnit) {
-	case "minutes":
-		targetAge *= 60
-	case "hours":
-		targetAge *= 3600
-	case "days":
-		targetAge *= 86400
-	}
+	targetAge := target.Seconds()
 
 	switch condition.Operator {
 	case types.Equals:
@@ -328,22 +499,151 @@ func (m *Manager) evaluateFileAgeCondition(condition types.Condition, fileInfo o
 	}
 }
 
+// evaluateFileTagCondition checks if a file's content tags meet the condition.
+// Equals and contains require the tag, not_equals requires its absence.
+func (m *Manager) evaluateFileTagCondition(condition types.Condition, filePath string) bool {
+	var tags []string
+	if m.tagger != nil {
+		var err error
+		if tags, err = m.tagger(filePath); err != nil && len(tags) == 0 {
+			return false
+		}
+	}
+
+	want := strings.ToLower(condition.Value)
+	hasTag := false
+	for _, tag := range tags {
+		if strings.ToLower(tag) == want {
+			hasTag = true
+			break
+		}
+	}
+
+	switch condition.Operator {
+	case types.Equals, types.Contains:
+		return hasTag
+	case types.NotEquals:
+		return !hasTag
+	default:
+		return false
+	}
+}
+
 // executeWorkflow performs the actions defined in a workflow
 func (m *Manager) executeWorkflow(workflow types.Workflow, filePath string) types.WorkflowResult {
+	return m.runWorkflow(context.Background(), workflow, filePath)
+}
+
+// runWorkflow is executeWorkflow under the trace ID in ctx, or a new one when
+// ctx has none. The ID is kept in the result and the run record and passed
+// on to the actions.
+func (m *Manager) runWorkflow(ctx context.Context, workflow types.Workflow, filePath string) types.WorkflowResult {
+	traceID := trace.From(ctx)
+	if traceID == "" {
+		traceID = trace.New()
+		ctx = trace.With(ctx, traceID)
+	}
+
 	result := types.WorkflowResult{
 		WorkflowID:   workflow.ID,
 		WorkflowName: workflow.Name,
 		FilePath:     filePath,
 		Success:      true,
+		Trace:        traceID,
+	}
+
+	mode := workflow.Mode
+	if mode == "" {
+		mode = types.ActiveMode
+	}
+	if mode == types.ActiveMode && m.dryRun {
+		mode = types.DryRunMode
+	}
+
+	record := types.WorkflowRunRecord{
+		Time:         time.Now(),
+		WorkflowID:   workflow.ID,
+		WorkflowName: workflow.Name,
+		Mode:         mode,
+		FilePath:     filePath,
+		Success:      true,
+		Trace:        traceID,
+	}
+	defer func() { m.recordRun(record) }()
+
+	// Workflows in dry-run or shadow mode only report what they would do
+	simulate := workflow.Mode == types.DryRunMode || workflow.Mode == types.ShadowMode
+
+	// Under the rollback policy, completed actions are undone when a later one
+	// fails, and deletes are held back until everything else has succeeded
+	transactional := workflow.OnFailure == types.RollbackOnFailure && !simulate && !m.dryRun
+	var completed []completedAction
+	var deferred []types.Action
+	current := filePath
+
+	fail := func(err error) types.WorkflowResult {
+		result.Success = false
+		result.Error = err
+		result.Message = fmt.Sprintf("Failed to execute action: %v", err)
+		record.Success = false
+		record.Error = err.Error()
+		if transactional && len(completed) > 0 {
+			reversed, failed := rollback(completed)
+			record.RolledBack = reversed
+			result.Message += fmt.Sprintf("; rolled back %d action(s)", len(reversed))
+			if len(failed) > 0 {
+				result.Message += fmt.Sprintf(", could not undo: %s", strings.Join(failed, "; "))
+			}
+		}
+		return result
 	}
 
 	for _, action := range workflow.Actions {
-		if err := m.executeAction(action, filePath); err != nil {
-			result.Success = false
-			result.Error = err
-			result.Message = fmt.Sprintf("Failed to execute action: %v", err)
+		// Fill {key} placeholders in targets and options for this file
+		action, err := m.expandActionTemplates(action, current)
+		if err != nil {
+			return fail(err)
+		}
+
+		description := describeAction(action, current)
+		if simulate {
+			fmt.Printf("[%s] Would %s\n", strings.ToUpper(string(workflow.Mode)), description)
+			record.Actions = append(record.Actions, description)
+			continue
+		}
+
+		if transactional && action.Type == types.DeleteAction {
+			deferred = append(deferred, action)
+			continue
+		}
+
+		next, undo, err := m.runActionWithRetries(ctx, action, current, workflow.Retries)
+		if errors.As(err, new(droppedDuplicate)) {
+			// The file is gone, so the rest of the workflow has nothing to do
+			record.Actions = append(record.Actions, description)
+			result.Message = fmt.Sprintf("Stopped: %v", err)
 			return result
 		}
+		if err != nil {
+			return fail(err)
+		}
+		completed = append(completed, completedAction{description: description, undo: undo, irreversible: irreversible(action)})
+		record.Actions = append(record.Actions, description)
+		current = next
+	}
+
+	// Commit: carry out the held back deletes
+	for _, action := range deferred {
+		description := describeAction(action, current)
+		if _, _, err := m.runActionWithRetries(ctx, action, current, workflow.Retries); err != nil {
+			return fail(err)
+		}
+		record.Actions = append(record.Actions, description)
+	}
+
+	if simulate {
+		result.Message = fmt.Sprintf("Would perform %d action(s)", len(workflow.Actions))
+		return result
 	}
 
 	result.Message = "All actions completed successfully"
@@ -352,30 +652,17 @@ func (m *Manager) executeWorkflow(workflow types.Workflow, filePath string) type
 
 // executeAction performs a single action
 func (m *Manager) executeAction(action types.Action, filePath string) error {
-	switch action.Type {
-	case types.MoveAction:
-		return m.executeMoveAction(action, filePath)
-	case types.CopyAction:
-		return m.executeCopyAction(action, filePath)
-	case types.RenameAction:
-		return m.executeRenameAction(action, filePath)
-	case types.TagAction:
-		return m.executeTagAction(action, filePath)
-	case types.DeleteAction:
-		return m.executeDeleteAction(action, filePath)
-	case types.ExecuteAction:
-		return m.executeCommandAction(action, filePath)
-	default:
-		return fmt.Errorf("unsupported action type: %s", action.Type)
-	}
+	_, _, err := m.runAction(context.Background(), action, filePath)
+	return err
 }
 
-// executeMoveAction moves a file to a target directory
-func (m *Manager) executeMoveAction(action types.Action, filePath string) error {
+// executeMoveAction moves a file to a target directory, returning where it
+// ended up, or "" when it was left alone because the target is identical
+func (m *Manager) executeMoveAction(action types.Action, filePath string) (string, error) {
 	// Create target directory if it doesn't exist
 	if action.Options["createTargetDir"] == "true" {
-		if err := os.MkdirAll(action.Target, 0755); err != nil {
-			return fmt.Errorf("failed to create target directory: %w", err)
+		if err := m.fsys().MkdirAll(action.Target, 0755); err != nil {
+			return "", fmt.Errorf("failed to create target directory: %w", err)
 		}
 	}
 
@@ -390,39 +677,53 @@ func (m *Manager) executeMoveAction(action types.Action, filePath string) error
 		if action.Options["overwrite"] == "true" {
 			// Remove existing file (in non-dry run mode)
 			if !m.dryRun {
-				if err := os.Remove(targetPath); err != nil {
-					return fmt.Errorf("failed to remove existing file: %w", err)
+				if err := m.fsys().Remove(targetPath); err != nil {
+					return "", fmt.Errorf("failed to remove existing file: %w", err)
 				}
 			}
 		} else {
-			// Rename with a unique suffix
-			targetPath = m.generateUniqueFilePath(targetPath)
+			// Skip identical copies, or keep both under a unique name
+			unique, err := m.uniqueTarget(filePath, targetPath)
+			if err != nil {
+				return "", err
+			}
+			if unique == "" {
+				return "", m.dropIdentical(filePath, targetPath)
+			}
+			targetPath = unique
 		}
 	}
 
+	if err := m.checkQuota(filePath, targetPath, filePath); err != nil {
+		return "", err
+	}
+
 	// In dry run mode, just log what would happen
 	if m.dryRun {
 		if targetExists && action.Options["overwrite"] == "true" {
 			fmt.Printf("[DRY RUN] Would overwrite existing file: %s\n", targetPath)
 		}
 		fmt.Printf("[DRY RUN] Would move file from %s to %s\n", filePath, targetPath)
-		return nil
+		return targetPath, nil
 	}
 
 	// Move the file
-	if err := os.Rename(filePath, targetPath); err != nil {
-		return fmt.Errorf("failed to move file: %w", err)
+	if err := m.fsys().Rename(filePath, targetPath); err != nil {
+		return "", fmt.Errorf("failed to move file: %w", err)
 	}
+	m.addToQuota(filePath, targetPath)
+	m.addToManifest(filePath, targetPath)
 
-	return nil
+	return targetPath, nil
 }
 
-// executeCopyAction copies a file to a target directory
-func (m *Manager) executeCopyAction(action types.Action, filePath string) error {
+// executeCopyAction copies a file to a target directory, returning the path
+// of the copy, or "" when an identical copy is already there
+func (m *Manager) executeCopyAction(action types.Action, filePath string) (string, error) {
 	// Create target directory if it doesn't exist
 	if action.Options["createTargetDir"] == "true" {
-		if err := os.MkdirAll(action.Target, 0755); err != nil {
-			return fmt.Errorf("failed to create target directory: %w", err)
+		if err := m.fsys().MkdirAll(action.Target, 0755); err != nil {
+			return "", fmt.Errorf("failed to create target directory: %w", err)
 		}
 	}
 
@@ -437,47 +738,49 @@ func (m *Manager) executeCopyAction(action types.Action, filePath string) error
 		if action.Options["overwrite"] == "true" {
 			// Remove existing file (in non-dry run mode)
 			if !m.dryRun {
-				if err := os.Remove(targetPath); err != nil {
-					return fmt.Errorf("failed to remove existing file: %w", err)
+				if err := m.fsys().Remove(targetPath); err != nil {
+					return "", fmt.Errorf("failed to remove existing file: %w", err)
 				}
 			}
 		} else {
-			// Rename with a unique suffix
-			targetPath = m.generateUniqueFilePath(targetPath)
+			// Skip identical copies, or keep both under a unique name
+			unique, err := m.uniqueTarget(filePath, targetPath)
+			if err != nil {
+				return "", err
+			}
+			if unique == "" {
+				return "", nil
+			}
+			targetPath = unique
 		}
 	}
 
+	if err := m.checkQuota("", targetPath, filePath); err != nil {
+		return "", err
+	}
+
 	// In dry run mode, just log what would happen
 	if m.dryRun {
 		if targetExists && action.Options["overwrite"] == "true" {
 			fmt.Printf("[DRY RUN] Would overwrite existing file: %s\n", targetPath)
 		}
 		fmt.Printf("[DRY RUN] Would copy file from %s to %s\n", filePath, targetPath)
-		return nil
-	}
-
-	// Copy the file
-	sourceFile, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to open source file: %w", err)
-	}
-	defer sourceFile.Close()
-
-	targetFile, err := os.Create(targetPath)
-	if err != nil {
-		return fmt.Errorf("failed to create target file: %w", err)
+		return targetPath, nil
 	}
-	defer targetFile.Close()
 
-	if _, err := targetFile.ReadFrom(sourceFile); err != nil {
-		return fmt.Errorf("failed to copy file contents: %w", err)
+	// Copy the file, staged so a crash never leaves a partial copy at the target
+	if err := m.fsys().CopyFile(filePath, targetPath); err != nil {
+		return "", fmt.Errorf("failed to copy file: %w", err)
 	}
+	m.addToQuota("", targetPath)
+	m.addToManifest("", targetPath)
 
-	return nil
+	return targetPath, nil
 }
 
-// executeRenameAction renames a file
-func (m *Manager) executeRenameAction(action types.Action, filePath string) error {
+// executeRenameAction renames a file, returning its new path, or "" when it
+// was left alone because the target is identical
+func (m *Manager) executeRenameAction(action types.Action, filePath string) (string, error) {
 	// Get directory and new file name
 	dir := filepath.Dir(filePath)
 	newName := action.Target
@@ -490,13 +793,20 @@ func (m *Manager) executeRenameAction(action types.Action, filePath string) erro
 		if action.Options["overwrite"] == "true" {
 			// Remove existing file (in non-dry run mode)
 			if !m.dryRun {
-				if err := os.Remove(targetPath); err != nil {
-					return fmt.Errorf("failed to remove existing file: %w", err)
+				if err := m.fsys().Remove(targetPath); err != nil {
+					return "", fmt.Errorf("failed to remove existing file: %w", err)
 				}
 			}
 		} else {
-			// Rename with a unique suffix
-			targetPath = m.generateUniqueFilePath(targetPath)
+			// Skip identical copies, or keep both under a unique name
+			unique, err := m.uniqueTarget(filePath, targetPath)
+			if err != nil {
+				return "", err
+			}
+			if unique == "" {
+				return "", m.dropIdentical(filePath, targetPath)
+			}
+			targetPath = unique
 		}
 	}
 
@@ -506,15 +816,15 @@ func (m *Manager) executeRenameAction(action types.Action, filePath string) erro
 			fmt.Printf("[DRY RUN] Would overwrite existing file: %s\n", targetPath)
 		}
 		fmt.Printf("[DRY RUN] Would rename file from %s to %s\n", filePath, targetPath)
-		return nil
+		return targetPath, nil
 	}
 
 	// Rename the file
-	if err := os.Rename(filePath, targetPath); err != nil {
-		return fmt.Errorf("failed to rename file: %w", err)
+	if err := m.fsys().Rename(filePath, targetPath); err != nil {
+		return "", fmt.Errorf("failed to rename file: %w", err)
 	}
 
-	return nil
+	return targetPath, nil
 }
 
 // executeTagAction adds tags to a file
@@ -538,7 +848,7 @@ func (m *Manager) executeDeleteAction(action types.Action, filePath string) erro
 		return nil
 	}
 
-	return os.Remove(filePath)
+	return m.fsys().Remove(filePath)
 }
 
 // executeCommandAction executes a command
@@ -562,6 +872,117 @@ func (m *Manager) generateUniqueFilePath(filePath string) string {
 	return basePath + timestamp + ext
 }
 
+// uniqueTarget returns where to keep filePath when targetPath is taken, or ""
+// when settings.duplicates.compare_content finds the two identical
+func (m *Manager) uniqueTarget(filePath, targetPath string) (string, error) {
+	if m.duplicates.CompareContent {
+		same, err := organize.SameContent(filePath, targetPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to compare with existing file: %w", err)
+		}
+		if same {
+			return "", nil
+		}
+	}
+
+	// Without a versioning scheme, keep the timestamped names workflows have always used
+	if m.duplicates.Versioning == "" {
+		return m.generateUniqueFilePath(targetPath), nil
+	}
+	var modTime time.Time
+	if info, err := os.Stat(filePath); err == nil {
+		modTime = info.ModTime()
+	}
+	return organize.VersionedName(targetPath, m.duplicates.Versioning, modTime)
+}
+
+// dropIdentical leaves a file whose target already holds the same content in
+// place, or deletes it if settings.duplicates.delete_identical is set, which
+// it reports with a droppedDuplicate error
+func (m *Manager) dropIdentical(filePath, targetPath string) error {
+	if !m.duplicates.DeleteIdentical {
+		fmt.Printf("Skipping %s: %s is identical\n", filePath, targetPath)
+		return nil
+	}
+	if m.dryRun {
+		fmt.Printf("[DRY RUN] Would delete %s: %s is identical\n", filePath, targetPath)
+		return nil
+	}
+	if err := m.fsys().Remove(filePath); err != nil {
+		return err
+	}
+	return droppedDuplicate{path: filePath, duplicate: targetPath}
+}
+
+// SetDuplicates sets how taken targets are compared and renamed
+func (m *Manager) SetDuplicates(duplicates config.DuplicateSettings) {
+	m.duplicates = duplicates
+}
+
+// SetQuotas sets the quotas move and copy targets are checked against.
+// Sharing the organize engine's checker keeps one usage count per directory.
+func (m *Manager) SetQuotas(quotas *quota.Checker) {
+	m.quotas = quotas
+}
+
+// checkQuota checks file arriving at target from src ("" for a copy) against
+// the quotas. Dry runs only check; real runs also report new violations.
+func (m *Manager) checkQuota(src, target, file string) error {
+	if m.quotas == nil {
+		return nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil
+	}
+	if m.dryRun {
+		return quota.Refusal(m.quotas.Check(src, target, info.Size()))
+	}
+	return m.quotas.Enforce(src, target, info.Size())
+}
+
+// addToQuota counts a file that arrived at target against the quotas
+func (m *Manager) addToQuota(src, target string) {
+	if m.quotas == nil {
+		return
+	}
+	if info, err := os.Stat(target); err == nil {
+		m.quotas.Added(src, target, info.Size())
+	}
+}
+
+// SetManifests sets the keeper of the archive directories' checksum manifests.
+// Sharing the organize engine's keeper serializes updates to a manifest.
+func (m *Manager) SetManifests(manifests *manifest.Keeper) {
+	m.manifests = manifests
+}
+
+// SetProfiles sets the checker for which config profiles are active, usually
+// the organize engine's
+func (m *Manager) SetProfiles(profiles *profile.Checker) {
+	m.profiles = profiles
+}
+
+// SetRemoteBandwidth holds each copy to a network file system to
+// bytesPerSecond; 0 lifts the limit
+func (m *Manager) SetRemoteBandwidth(bytesPerSecond int64) {
+	m.fs = fsops.Throttled(bytesPerSecond)
+}
+
+// addToManifest records a file that arrived at target in its folder's
+// checksum manifest, if it has one
+func (m *Manager) addToManifest(src, target string) {
+	if err := m.manifests.Added(src, target); err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating checksum manifest for %s: %v\n", target, err)
+	}
+}
+
+// SetRetry sets how actions failing on transient errors, such as a busy file,
+// are retried. A workflow's own retries apply to every error.
+func (m *Manager) SetRetry(retry config.RetrySettings) {
+	m.retry = &retry
+}
+
 // GetWorkflows returns the currently loaded workflows
 func (m *Manager) GetWorkflows() []types.Workflow {
 	return m.workflows
@@ -570,7 +991,7 @@ func (m *Manager) GetWorkflows() []types.Workflow {
 // AddWorkflow adds a new workflow to the configuration
 func (m *Manager) AddWorkflow(workflow types.Workflow) error {
 	// Validate the workflow
-	if err := validateWorkflow(&workflow); err != nil {
+	if err := m.Validate(workflow); err != nil {
 		return err
 	}
 
@@ -591,7 +1012,7 @@ func (m *Manager) AddWorkflow(workflow types.Workflow) error {
 // UpdateWorkflow updates an existing workflow
 func (m *Manager) UpdateWorkflow(workflow types.Workflow) error {
 	// Validate the workflow
-	if err := validateWorkflow(&workflow); err != nil {
+	if err := m.Validate(workflow); err != nil {
 		return err
 	}
 
@@ -644,7 +1065,7 @@ func (m *Manager) saveWorkflow(workflow types.Workflow) error {
 
 	// Save to file
 	filePath := filepath.Join(m.configPath, workflow.ID+".yaml")
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := atomicfile.WriteFile(filePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write workflow file: %w", err)
 	}
 
@@ -653,17 +1074,9 @@ func (m *Manager) saveWorkflow(workflow types.Workflow) error {
 
 // ExecuteWorkflow manually executes a workflow on a specific file
 func (m *Manager) ExecuteWorkflow(workflowID, filePath string) (*types.WorkflowResult, error) {
-	// Find the workflow
-	var targetWorkflow *types.Workflow
-	for i := range m.workflows {
-		if m.workflows[i].ID == workflowID {
-			targetWorkflow = &m.workflows[i]
-			break
-		}
-	}
-
-	if targetWorkflow == nil {
-		return nil, fmt.Errorf("workflow with ID %s not found", workflowID)
+	targetWorkflow, err := m.resolvedWorkflow(workflowID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if file exists
@@ -682,9 +1095,47 @@ func (m *Manager) ExecuteWorkflow(workflowID, filePath string) (*types.WorkflowR
 	return &result, nil
 }
 
-// SetDryRun enables or disables dry run mode
+// resolvedWorkflow finds a workflow by ID and resolves its variables and fragments
+func (m *Manager) resolvedWorkflow(workflowID string) (*types.Workflow, error) {
+	for _, wf := range m.workflows {
+		if wf.ID != workflowID {
+			continue
+		}
+		resolved, err := m.resolveWorkflow(wf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve workflow %s: %w", workflowID, err)
+		}
+		return &resolved, nil
+	}
+	return nil, fmt.Errorf("workflow with ID %s not found", workflowID)
+}
+
+// SetDryRun enables or disables dry run mode. Audit mode can't be left this way.
 func (m *Manager) SetDryRun(enabled bool) {
-	m.dryRun = enabled
+	m.dryRun = enabled || m.audit
+}
+
+// SetAudit puts the manager in audit mode, in which workflows only report what
+// they would do, as in dry run mode
+func (m *Manager) SetAudit(enabled bool) {
+	m.audit = enabled
+	if enabled {
+		m.dryRun = true
+	}
+}
+
+// SetTagger sets the source of content tags for file_tag conditions
+func (m *Manager) SetTagger(tagger TagFunc) {
+	m.tagger = tagger
+}
+
+// fsys returns the layer actions change files through, which changes nothing
+// in dry runs
+func (m *Manager) fsys() fsops.FS {
+	if m.fs == nil {
+		return fsops.For(fsops.OS, m.dryRun)
+	}
+	return fsops.For(m.fs, m.dryRun)
 }
 
 // IsDryRun returns the current dry run status
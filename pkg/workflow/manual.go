@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+
+	"sortd/pkg/types"
+)
+
+// ManualRun is what running a workflow by hand did
+type ManualRun struct {
+	Results []types.WorkflowResult // One per file the workflow ran on
+	Skipped []string               // Files that don't meet the workflow's conditions
+}
+
+// Failed counts the files the workflow failed on
+func (r *ManualRun) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Success {
+			failed++
+		}
+	}
+	return failed
+}
+
+// RunProgress is told about each file of a manual run once it is handled;
+// done counts the files handled so far out of total
+type RunProgress func(done, total int, path string)
+
+// RunManual runs a workflow by hand on files, the way `sortd workflow run`
+// and the GUI's Run button do. A folder stands for the files directly inside
+// it. Triggers and their time windows don't apply, but conditions do: files
+// that don't meet them are skipped. progress may be nil.
+func (m *Manager) RunManual(workflowID string, paths []string, progress RunProgress) (*ManualRun, error) {
+	wf, err := m.resolvedWorkflow(workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("file not found: %w", err)
+		}
+		if info.IsDir() {
+			files = append(files, sweepFiles([]string{path})...)
+		} else {
+			files = append(files, path)
+		}
+	}
+
+	run := &ManualRun{}
+	for i, file := range files {
+		fileInfo, err := os.Stat(file)
+		switch {
+		case err != nil:
+			run.Results = append(run.Results, types.WorkflowResult{
+				WorkflowID:   wf.ID,
+				WorkflowName: wf.Name,
+				FilePath:     file,
+				Message:      "File disappeared before the workflow ran",
+				Error:        err,
+			})
+		case !m.evaluateConditions(wf.Conditions, file, fileInfo):
+			run.Skipped = append(run.Skipped, file)
+		default:
+			run.Results = append(run.Results, m.executeWorkflow(*wf, file))
+		}
+		if progress != nil {
+			progress(i+1, len(files), file)
+		}
+	}
+	return run, nil
+}
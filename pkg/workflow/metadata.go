@@ -0,0 +1,193 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+)
+
+// placeholderPattern matches {key} references to file metadata in action targets
+var placeholderPattern = regexp.MustCompile(`\{([a-z_][a-z0-9_]*)\}`)
+
+// maxCachedMetadata bounds the metadata cache; it is cleared when full
+const maxCachedMetadata = 256
+
+// MetadataFunc returns the metadata of a file, e.g. "resolution" or "duration"
+type MetadataFunc func(path string) (map[string]string, error)
+
+// cachedMetadata is a file's metadata with the file state it was read from
+type cachedMetadata struct {
+	size    int64
+	modTime time.Time
+	values  map[string]string
+}
+
+// SetMetadata sets the source of file metadata for metadata conditions and
+// {key} placeholders in action targets and options
+func (m *Manager) SetMetadata(metadata MetadataFunc) {
+	m.metadataMu.Lock()
+	defer m.metadataMu.Unlock()
+	m.metadata = metadata
+	m.metadataCache = nil
+}
+
+// fileMetadata returns a file's metadata, reading it once per file version
+func (m *Manager) fileMetadata(path string) (map[string]string, error) {
+	m.metadataMu.Lock()
+	defer m.metadataMu.Unlock()
+
+	if m.metadata == nil {
+		return nil, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := m.metadataCache[path]; ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.values, nil
+	}
+
+	values, err := m.metadata(path)
+	if err != nil {
+		return values, err
+	}
+	if m.metadataCache == nil || len(m.metadataCache) >= maxCachedMetadata {
+		m.metadataCache = make(map[string]cachedMetadata)
+	}
+	m.metadataCache[path] = cachedMetadata{size: info.Size(), modTime: info.ModTime(), values: values}
+	return values, nil
+}
+
+// HasPlaceholders reports whether a target refers to {key} placeholders,
+// which are only known once a file is handed to the workflow
+func HasPlaceholders(target string) bool {
+	return placeholderPattern.MatchString(target)
+}
+
+// fileKeys are the placeholders every file has: its name without the
+// extension, the extension, the whole file name, and the year, month, day
+// and date it was last modified
+func fileKeys(filePath string) map[string]string {
+	base := filepath.Base(filePath)
+	ext := filepath.Ext(base)
+	keys := map[string]string{
+		"filename": base,
+		"name":     strings.TrimSuffix(base, ext),
+		"ext":      strings.ToLower(strings.TrimPrefix(ext, ".")),
+	}
+	if info, err := os.Stat(filePath); err == nil {
+		modTime := info.ModTime()
+		keys["year"] = modTime.Format("2006")
+		keys["month"] = modTime.Format("01")
+		keys["day"] = modTime.Format("02")
+		keys["date"] = modTime.Format("2006-01-02")
+	}
+	return keys
+}
+
+// expandPlaceholders substitutes {key} references with the file's own keys
+// and its metadata, metadata winning, failing if the file has no value for a key
+func (m *Manager) expandPlaceholders(target, filePath string) (string, error) {
+	if !placeholderPattern.MatchString(target) {
+		return target, nil
+	}
+
+	values := fileKeys(filePath)
+	metadata, metadataErr := m.fileMetadata(filePath)
+	for key, value := range metadata {
+		values[key] = value
+	}
+
+	var missing string
+	out := placeholderPattern.ReplaceAllStringFunc(target, func(ref string) string {
+		key := placeholderPattern.FindStringSubmatch(ref)[1]
+		value, ok := values[key]
+		if !ok || value == "" {
+			if missing == "" {
+				missing = key
+			}
+			return ref
+		}
+		return strings.ReplaceAll(value, string(os.PathSeparator), "_")
+	})
+
+	if missing != "" {
+		if metadataErr != nil && len(metadata) == 0 {
+			return target, fmt.Errorf("failed to read metadata for %s: %w", filePath, metadataErr)
+		}
+		return target, fmt.Errorf("%s has no %s for {%s}", filePath, missing, missing)
+	}
+	return out, nil
+}
+
+// expandActionTemplates fills the placeholders in a move, copy, rename or tag
+// action's target and options for a file, and a leading ~ in its target
+func (m *Manager) expandActionTemplates(action types.Action, filePath string) (types.Action, error) {
+	switch action.Type {
+	case types.MoveAction, types.CopyAction, types.RenameAction, types.TagAction:
+	default:
+		return action, nil
+	}
+
+	target, err := m.expandPlaceholders(action.Target, filePath)
+	if err != nil {
+		return action, err
+	}
+	action.Target = config.ExpandHome(target)
+
+	if len(action.Options) > 0 {
+		options := make(map[string]string, len(action.Options))
+		for key, value := range action.Options {
+			if options[key], err = m.expandPlaceholders(value, filePath); err != nil {
+				return action, fmt.Errorf("option %s: %w", key, err)
+			}
+		}
+		action.Options = options
+	}
+	return action, nil
+}
+
+// evaluateMetadataCondition checks a metadata value named by the condition's
+// field. Greater and less than compare numerically.
+func (m *Manager) evaluateMetadataCondition(condition types.Condition, filePath string) bool {
+	metadata, _ := m.fileMetadata(filePath)
+	value, ok := metadata[condition.Field]
+	if !ok {
+		return condition.Operator == types.NotEquals
+	}
+
+	switch condition.Operator {
+	case types.Equals:
+		return strings.EqualFold(value, condition.Value)
+	case types.NotEquals:
+		return !strings.EqualFold(value, condition.Value)
+	case types.Contains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value))
+	case types.StartsWith:
+		return strings.HasPrefix(value, condition.Value)
+	case types.EndsWith:
+		return strings.HasSuffix(value, condition.Value)
+	case types.GreaterThan, types.LessThan:
+		actual, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		target, err := strconv.ParseFloat(condition.Value, 64)
+		if err != nil {
+			return false
+		}
+		if condition.Operator == types.GreaterThan {
+			return actual > target
+		}
+		return actual < target
+	default:
+		return false
+	}
+}
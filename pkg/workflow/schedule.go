@@ -0,0 +1,164 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleField is one of the five fields of a cron schedule
+type scheduleField struct {
+	name     string
+	min, max int
+	names    []string // Names standing for min, min+1, ..., e.g. jan or sun
+}
+
+var scheduleFields = []scheduleField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// scheduleShorthands are the @ forms a schedule can take instead of five fields
+var scheduleShorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron schedule
+type Schedule struct {
+	fields [5]uint64 // Bit n is set when value n is allowed
+	anyDay bool      // Day of month is *, so only the weekday restricts days
+	anyDow bool      // Day of week is *, so only the day of month restricts days
+}
+
+// ParseSchedule reads a scheduled trigger's cron schedule: five fields
+// (minute, hour, day of month, month, day of week) of numbers, names like
+// mon or jan, ranges, lists and steps, as in "*/15 9-17 * * mon-fri", or a
+// shorthand like @daily
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("schedule is empty")
+	}
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := scheduleShorthands[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown schedule %s (use @hourly, @daily, @weekly, @monthly or @yearly)", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(scheduleFields) {
+		return nil, fmt.Errorf("schedule needs 5 fields (minute hour day month weekday), got %d", len(fields))
+	}
+	schedule := &Schedule{anyDay: fields[2] == "*", anyDow: fields[4] == "*"}
+	for i, field := range fields {
+		bits, err := scheduleFields[i].parse(field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", scheduleFields[i].name, err)
+		}
+		schedule.fields[i] = bits
+	}
+	// Sunday can be written as 7 as well as 0
+	if schedule.fields[4]&(1<<7) != 0 {
+		schedule.fields[4] |= 1
+	}
+	return schedule, nil
+}
+
+// ValidateSchedule checks a scheduled trigger's cron schedule
+func ValidateSchedule(spec string) error {
+	_, err := ParseSchedule(spec)
+	return err
+}
+
+// Matches reports whether the schedule fires in the minute t falls in. As in
+// cron, when both the day of month and the day of week are restricted, either
+// one matching is enough.
+func (s *Schedule) Matches(t time.Time) bool {
+	has := func(field, value int) bool { return s.fields[field]&(1<<uint(value)) != 0 }
+	if !has(0, t.Minute()) || !has(1, t.Hour()) || !has(3, int(t.Month())) {
+		return false
+	}
+
+	day, dow := has(2, t.Day()), has(4, int(t.Weekday()))
+	switch {
+	case s.anyDay && s.anyDow:
+		return true
+	case s.anyDay:
+		return dow
+	case s.anyDow:
+		return day
+	default:
+		return day || dow
+	}
+}
+
+// parse reads a field: a comma-separated list of *, values or ranges, each
+// optionally followed by /step
+func (f scheduleField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		span, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+			step = n
+		}
+
+		from, to := f.min, f.max
+		if span != "*" {
+			low, high, isRange := strings.Cut(span, "-")
+			var err error
+			if from, err = f.value(low); err != nil {
+				return 0, err
+			}
+			to = from
+			if isRange {
+				if to, err = f.value(high); err != nil {
+					return 0, err
+				}
+				if from > to {
+					return 0, fmt.Errorf("range %s runs backwards", span)
+				}
+			} else if hasStep {
+				// "5/15" means from 5 to the end, every 15
+				to = f.max
+			}
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value reads a number or a name within the field's range
+func (f scheduleField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("%d is outside %d-%d", n, f.min, f.max)
+	}
+	return n, nil
+}
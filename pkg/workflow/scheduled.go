@@ -0,0 +1,148 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sortd/internal/globs"
+	"sortd/pkg/types"
+)
+
+// HasScheduledTriggers reports whether any enabled workflow has a scheduled
+// trigger, so the daemon knows whether to run sweeps at all
+func (m *Manager) HasScheduledTriggers() bool {
+	for _, workflow := range m.workflows {
+		if !workflow.Enabled {
+			continue
+		}
+		for _, trigger := range workflow.AllTriggers() {
+			if trigger.Type == types.ScheduledTrigger {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RunScheduled runs the workflows with a scheduled trigger due in the minute
+// now falls in. Each one sweeps the files directly inside dirs, acting on
+// those its trigger pattern and conditions match, which catches up with
+// files its live triggers missed, e.g. while the daemon was stopped.
+// Directory count conditions are checked once per directory before the sweep,
+// so a cleanup triggered by clutter doesn't stop as the count drops.
+func (m *Manager) RunScheduled(now time.Time, dirs []string) []types.WorkflowResult {
+	var results []types.WorkflowResult
+	for _, workflow := range m.workflows {
+		if !workflow.Enabled || !m.profiles.Active(workflow.Profile) {
+			continue
+		}
+
+		workflow, resolveErr := m.resolveWorkflow(workflow)
+		if resolveErr != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving workflow %s: %v\n", workflow.ID, resolveErr)
+			continue
+		}
+
+		trigger, due := dueTrigger(workflow, now)
+		if !due {
+			continue
+		}
+
+		counts, conditions := splitCountConditions(workflow.Conditions)
+		for _, dir := range dirs {
+			if !directoryCountsMet(counts, dir) {
+				continue
+			}
+
+			for _, filePath := range sweepFiles([]string{dir}) {
+				if trigger.Pattern != "" {
+					matched, err := globs.Match(trigger.Pattern, filePath)
+					if err != nil || !matched {
+						continue
+					}
+				}
+				fileInfo, err := os.Stat(filePath)
+				if err != nil || !m.evaluateConditions(conditions, filePath, fileInfo) {
+					continue
+				}
+
+				result := m.executeWorkflow(workflow, filePath)
+				if workflow.Mode == types.ShadowMode {
+					fmt.Printf("Workflow %s (%s) shadow run: %s\n", workflow.Name, workflow.ID, result.Message)
+				}
+				results = append(results, result)
+			}
+		}
+	}
+	return results
+}
+
+// splitCountConditions separates the directory count conditions from the rest
+func splitCountConditions(conditions []types.Condition) (counts, rest []types.Condition) {
+	for _, condition := range conditions {
+		if condition.Type == types.DirectoryCountCondition {
+			counts = append(counts, condition)
+		} else {
+			rest = append(rest, condition)
+		}
+	}
+	return counts, rest
+}
+
+// directoryCountsMet checks directory count conditions for a sweep of dir.
+// Conditions without a field count dir itself.
+func directoryCountsMet(conditions []types.Condition, dir string) bool {
+	for _, condition := range conditions {
+		counted := condition.Field
+		if counted == "" {
+			counted = dir
+		}
+		if !compareDirectoryCount(condition, counted) {
+			return false
+		}
+	}
+	return true
+}
+
+// dueTrigger returns the workflow's scheduled trigger due at now, if any
+func dueTrigger(workflow types.Workflow, now time.Time) (types.Trigger, bool) {
+	for _, trigger := range workflow.AllTriggers() {
+		if trigger.Type != types.ScheduledTrigger || !TriggerAllowedAt(trigger, now) {
+			continue
+		}
+		schedule, err := ParseSchedule(trigger.Schedule)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing schedule '%s' for %s: %v\n", trigger.Schedule, workflow.ID, err)
+			continue
+		}
+		if schedule.Matches(now) {
+			return trigger, true
+		}
+	}
+	return types.Trigger{}, false
+}
+
+// sweepFiles lists the regular files directly inside dirs, leaving out the
+// hidden and backup files workflows never see
+func sweepFiles(dirs []string) []string {
+	var files []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+				continue
+			}
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,234 @@
+// Package testutil runs workflows against scratch directories and compares the
+// files they leave behind with what a test or a golden file expects, so
+// workflow behaviours can be pinned down without a running watcher.
+//
+// A golden file is YAML describing one scenario:
+//
+//	name: invoices go to Documents
+//	workflows:
+//	  - id: invoices
+//	    name: Invoices
+//	    enabled: true
+//	    trigger: {type: file_created, pattern: "*.pdf"}
+//	    actions:
+//	      - type: move
+//	        target: ${root}/Documents
+//	files:
+//	  Downloads/invoice.pdf: "%PDF"
+//	events:
+//	  - create: Downloads/invoice.pdf
+//	    processed: true
+//	expect:
+//	  Documents/invoice.pdf: "%PDF"
+//
+// Paths are relative to the scratch directory, which workflows reach through
+// the ${root} variable. expect lists every file afterwards, so a file that
+// should have moved away must not appear under its old name.
+package testutil
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"sortd/internal/config"
+	"sortd/pkg/types"
+	"sortd/pkg/workflow"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// RootVar is the workflow variable holding the scratch directory
+const RootVar = "root"
+
+// Golden is a scenario: workflows, the files before, the events that arrive,
+// and the files afterwards
+type Golden struct {
+	Name      string           `yaml:"name"`
+	DryRun    bool             `yaml:"dry_run,omitempty"` // Run the manager in dry run mode
+	Workflows []types.Workflow `yaml:"workflows"`
+
+	// How taken targets are compared and renamed. The default names copies
+	// after the time, so scenarios with collisions should pick a versioning.
+	Duplicates config.DuplicateSettings `yaml:"duplicates,omitempty"`
+
+	Files  map[string]string `yaml:"files,omitempty"` // Relative path -> content before the events
+	Events []Event           `yaml:"events"`
+	Expect map[string]string `yaml:"expect"` // Relative path -> content of every file afterwards
+}
+
+// Event is a synthesized file event. Exactly one of Create and Write names
+// the file it happened to.
+type Event struct {
+	Create    string `yaml:"create,omitempty"`
+	Write     string `yaml:"write,omitempty"`
+	Processed *bool  `yaml:"processed,omitempty"` // Whether a workflow should claim the event; unchecked when unset
+	Error     string `yaml:"error,omitempty"`     // Text the processing error should contain; empty expects none
+}
+
+// fsEvent returns the event as the watcher would report it
+func (e Event) fsEvent(root string) (fsnotify.Event, error) {
+	switch {
+	case e.Create != "" && e.Write == "":
+		return fsnotify.Event{Name: filepath.Join(root, filepath.FromSlash(e.Create)), Op: fsnotify.Create}, nil
+	case e.Write != "" && e.Create == "":
+		return fsnotify.Event{Name: filepath.Join(root, filepath.FromSlash(e.Write)), Op: fsnotify.Write}, nil
+	}
+	return fsnotify.Event{}, fmt.Errorf("an event names exactly one of create and write")
+}
+
+// Harness is a workflow manager working in a scratch directory
+type Harness struct {
+	t       testing.TB
+	Root    string            // Scratch directory the files live in
+	Manager *workflow.Manager // Manager loaded with the harness's workflows
+}
+
+// New creates a harness with a fresh scratch directory and a manager holding
+// the given workflows. Each workflow gets the ${root} variable unless it sets
+// its own.
+func New(t testing.TB, workflows ...types.Workflow) *Harness {
+	t.Helper()
+
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	require.NoError(t, os.Mkdir(root, 0755))
+
+	manager, err := workflow.NewManager(filepath.Join(base, "workflows"))
+	require.NoError(t, err)
+	for _, wf := range workflows {
+		vars := make(map[string]string, len(wf.Vars)+1)
+		vars[RootVar] = root
+		for name, value := range wf.Vars {
+			vars[name] = value
+		}
+		wf.Vars = vars
+		require.NoError(t, manager.AddWorkflow(wf), "workflow %s", wf.ID)
+	}
+
+	return &Harness{t: t, Root: root, Manager: manager}
+}
+
+// Path returns the absolute path of a slash-separated path in the scratch directory
+func (h *Harness) Path(rel string) string {
+	return filepath.Join(h.Root, filepath.FromSlash(rel))
+}
+
+// WriteFiles creates files in the scratch directory, with their parent directories
+func (h *Harness) WriteFiles(files map[string]string) {
+	h.t.Helper()
+	for rel, content := range files {
+		path := h.Path(rel)
+		require.NoError(h.t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(h.t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+// Create reports a newly created file to the manager
+func (h *Harness) Create(rel string) (bool, error) {
+	return h.Manager.ProcessEvent(fsnotify.Event{Name: h.Path(rel), Op: fsnotify.Create})
+}
+
+// Write reports a modified file to the manager
+func (h *Harness) Write(rel string) (bool, error) {
+	return h.Manager.ProcessEvent(fsnotify.Event{Name: h.Path(rel), Op: fsnotify.Write})
+}
+
+// Snapshot returns every file in the scratch directory, by slash-separated
+// relative path, with its content
+func (h *Harness) Snapshot() map[string]string {
+	h.t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(h.Root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(h.Root, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	require.NoError(h.t, err)
+	return files
+}
+
+// AssertFiles checks that the scratch directory holds exactly the given files
+func (h *Harness) AssertFiles(want map[string]string) bool {
+	h.t.Helper()
+	if want == nil {
+		want = map[string]string{}
+	}
+	return assert.Equal(h.t, want, h.Snapshot(), "files in %s", h.Root)
+}
+
+// LoadGolden reads a golden file
+func LoadGolden(path string) (*Golden, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var golden Golden
+	if err := yaml.Unmarshal(data, &golden); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if golden.Name == "" {
+		golden.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &golden, nil
+}
+
+// Run plays the scenario in a fresh harness and checks the outcome
+func (g *Golden) Run(t *testing.T) {
+	t.Helper()
+
+	h := New(t, g.Workflows...)
+	h.Manager.SetDryRun(g.DryRun)
+	h.Manager.SetDuplicates(g.Duplicates)
+	h.WriteFiles(g.Files)
+
+	for i, event := range g.Events {
+		fsEvent, err := event.fsEvent(h.Root)
+		require.NoError(t, err, "event %d", i)
+
+		processed, err := h.Manager.ProcessEvent(fsEvent)
+		if event.Error == "" {
+			assert.NoError(t, err, "event %d (%s)", i, fsEvent)
+		} else if assert.Error(t, err, "event %d (%s)", i, fsEvent) {
+			assert.Contains(t, err.Error(), event.Error, "event %d (%s)", i, fsEvent)
+		}
+		if event.Processed != nil {
+			assert.Equal(t, *event.Processed, processed, "event %d (%s) processed", i, fsEvent)
+		}
+	}
+
+	h.AssertFiles(g.Expect)
+}
+
+// RunGoldenDir runs every golden file (*.yaml) in a directory as a subtest
+func RunGoldenDir(t *testing.T, dir string) {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths, "no golden files in %s", dir)
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		golden, err := LoadGolden(path)
+		require.NoError(t, err)
+		t.Run(golden.Name, golden.Run)
+	}
+}
@@ -0,0 +1,108 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"sortd/pkg/types"
+)
+
+// weekdays maps accepted day names to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// TriggerAllowedAt reports whether a trigger may fire at the given time.
+// Triggers without windows may always fire; otherwise any matching window allows it.
+// Windows that span midnight belong to the day they start on.
+func TriggerAllowedAt(trigger types.Trigger, now time.Time) bool {
+	if len(trigger.Windows) == 0 {
+		return true
+	}
+
+	for _, window := range trigger.Windows {
+		if windowContains(window, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// windowContains reports whether the time falls inside the window.
+// Invalid windows never match; validateWorkflow rejects them up front.
+func windowContains(window types.TimeWindow, now time.Time) bool {
+	start, err := parseClock(window.Start, 0)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(window.End, 24*time.Hour)
+	if err != nil {
+		return false
+	}
+
+	clock := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	day := now.Weekday()
+
+	if start <= end {
+		return clock >= start && clock < end && dayAllowed(window.Days, day)
+	}
+
+	// Span midnight: the evening part belongs to today, the morning part to yesterday
+	if clock >= start {
+		return dayAllowed(window.Days, day)
+	}
+	if clock < end {
+		return dayAllowed(window.Days, (day+6)%7)
+	}
+	return false
+}
+
+// dayAllowed reports whether the weekday is in the list (an empty list allows all)
+func dayAllowed(days []string, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, name := range days {
+		if d, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]; ok && d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses "HH:MM" into an offset from midnight, using def when empty
+func parseClock(value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// validateTimeWindows checks that every day name and time in the windows parses
+func validateTimeWindows(windows []types.TimeWindow) error {
+	for i, window := range windows {
+		for _, name := range window.Days {
+			if _, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]; !ok {
+				return fmt.Errorf("time window %d: unknown day %q", i, name)
+			}
+		}
+		if _, err := parseClock(window.Start, 0); err != nil {
+			return fmt.Errorf("time window %d: %w", i, err)
+		}
+		if _, err := parseClock(window.End, 0); err != nil {
+			return fmt.Errorf("time window %d: %w", i, err)
+		}
+	}
+	return nil
+}
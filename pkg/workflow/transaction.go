@@ -0,0 +1,135 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	serr "sortd/internal/errors"
+	"sortd/pkg/types"
+)
+
+// actionRetryDelay is the pause before retrying a failed action, growing with each attempt
+var actionRetryDelay = time.Second
+
+// droppedDuplicate is what a move or rename returns when it deleted the file
+// instead, because its target already held the same content. The workflow
+// stops there for the file: nothing is left for later actions to act on.
+type droppedDuplicate struct {
+	path      string
+	duplicate string
+}
+
+func (d droppedDuplicate) Error() string {
+	return fmt.Sprintf("deleted %s: %s holds the same content", d.path, d.duplicate)
+}
+
+// completedAction is an action that has been carried out, and how to reverse it
+type completedAction struct {
+	description  string
+	undo         func() error // nil when there's nothing to reverse
+	irreversible bool         // The action had effects that can't be reversed, e.g. a posted webhook
+}
+
+// irreversible reports whether an action's effects can't be reversed
+func irreversible(action types.Action) bool {
+	switch action.Type {
+	case types.DeleteAction, types.ExecuteAction, types.WebhookAction:
+		return true
+	}
+	return false
+}
+
+// runAction performs a single action. It returns the path the file is at
+// afterwards, which differs from filePath once it has been moved or renamed,
+// and a function reversing the action, nil when there's nothing to reverse or
+// it can't be reversed. ctx carries the run's trace ID.
+func (m *Manager) runAction(ctx context.Context, action types.Action, filePath string) (string, func() error, error) {
+	switch action.Type {
+	case types.MoveAction:
+		moved, err := m.executeMoveAction(action, filePath)
+		return m.relocated(filePath, moved, err)
+	case types.RenameAction:
+		renamed, err := m.executeRenameAction(action, filePath)
+		return m.relocated(filePath, renamed, err)
+	case types.CopyAction:
+		copied, err := m.executeCopyAction(action, filePath)
+		if err != nil || copied == "" || m.dryRun {
+			return filePath, nil, err
+		}
+		return filePath, func() error { return m.fsys().Remove(copied) }, nil
+	case types.TagAction:
+		return filePath, nil, m.executeTagAction(action, filePath)
+	case types.DeleteAction:
+		return filePath, nil, m.executeDeleteAction(action, filePath)
+	case types.ExecuteAction:
+		return filePath, nil, m.executeCommandAction(action, filePath)
+	case types.WebhookAction:
+		return filePath, nil, m.executeWebhookAction(ctx, action, filePath)
+	default:
+		return filePath, nil, fmt.Errorf("unsupported action type: %s", action.Type)
+	}
+}
+
+// relocated returns the results of a move or rename of filePath to newPath,
+// where "" means the file was left alone
+func (m *Manager) relocated(filePath, newPath string, err error) (string, func() error, error) {
+	if err != nil || newPath == "" {
+		return filePath, nil, err
+	}
+	if m.dryRun {
+		return newPath, nil, nil
+	}
+	return newPath, func() error { return m.fsys().Rename(newPath, filePath) }, nil
+}
+
+// runActionWithRetries performs an action, trying it again up to retries more
+// times while it fails. Transient errors are retried as the manager's retry
+// settings say when those allow more tries, with a pause doubling each time.
+// Cancelling ctx ends the pause and the retries.
+func (m *Manager) runActionWithRetries(ctx context.Context, action types.Action, filePath string, retries int) (string, func() error, error) {
+	for attempt := 0; ; attempt++ {
+		current, undo, err := m.runAction(ctx, action, filePath)
+		if err == nil || errors.As(err, new(droppedDuplicate)) {
+			return current, undo, err
+		}
+
+		limit, delay := retries, time.Duration(attempt+1)*actionRetryDelay
+		if m.retry != nil && serr.IsTransient(err) {
+			if m.retry.MaxRetries() > limit {
+				limit = m.retry.MaxRetries()
+			}
+			delay = m.retry.Delay(attempt + 1)
+		}
+		if attempt >= limit {
+			return current, undo, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return filePath, nil, ctx.Err()
+		}
+	}
+}
+
+// rollback reverses completed actions, last first. It returns the
+// descriptions of the actions reversed and of those that couldn't be.
+func rollback(completed []completedAction) (reversed, failed []string) {
+	for i := len(completed) - 1; i >= 0; i-- {
+		action := completed[i]
+		if action.irreversible {
+			failed = append(failed, action.description+" (can't be undone)")
+			continue
+		}
+		if action.undo == nil {
+			continue
+		}
+		if err := action.undo(); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", action.description, err))
+			continue
+		}
+		reversed = append(reversed, action.description)
+	}
+	return reversed, failed
+}
@@ -0,0 +1,196 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"sortd/internal/trace"
+	"sortd/pkg/types"
+)
+
+// Defaults for webhook actions, overridable through action options
+const (
+	defaultWebhookRetries = 3
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+// TraceHeader is the request header holding the trace ID of the workflow run
+const TraceHeader = "X-Sortd-Trace"
+
+// webhookRetryDelay is the base delay between webhook attempts; it grows linearly
+var webhookRetryDelay = time.Second
+
+// WebhookPayload is the file metadata available to webhook payload templates.
+// Without a template it is posted as JSON.
+type WebhookPayload struct {
+	Path     string    `json:"path"`
+	Name     string    `json:"name"`
+	Dir      string    `json:"dir"`
+	Ext      string    `json:"ext"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Trace    string    `json:"trace,omitempty"` // ID of the workflow run, as in sortd's logs
+}
+
+// executeWebhookAction POSTs file metadata to action.Target.
+// Options: payload (text/template over WebhookPayload), content_type,
+// retries (additional attempts after the first) and timeout (seconds).
+// Requests carry the run's trace ID in the X-Sortd-Trace header.
+func (m *Manager) executeWebhookAction(ctx context.Context, action types.Action, filePath string) error {
+	if action.Target == "" {
+		return fmt.Errorf("webhook action requires a target URL")
+	}
+
+	// In dry run mode, just log what would happen
+	if m.dryRun {
+		fmt.Printf("[DRY RUN] Would post metadata of %s to %s\n", filePath, action.Target)
+		return nil
+	}
+
+	body, contentType, err := buildWebhookBody(action, filePath, trace.From(ctx))
+	if err != nil {
+		return err
+	}
+
+	retries := defaultWebhookRetries
+	if value, ok := action.Options["retries"]; ok {
+		if retries, err = strconv.Atoi(value); err != nil || retries < 0 {
+			return fmt.Errorf("invalid webhook retries: %s", value)
+		}
+	}
+
+	timeout := defaultWebhookTimeout
+	if value, ok := action.Options["timeout"]; ok {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			return fmt.Errorf("invalid webhook timeout: %s", value)
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	client := &http.Client{Timeout: timeout}
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * webhookRetryDelay)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, action.Target, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("invalid webhook target %s: %w", action.Target, err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		if id := trace.From(ctx); id != "" {
+			req.Header.Set(TraceHeader, id)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("unexpected status %s", resp.Status)
+
+		// Client errors won't succeed on retry
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			break
+		}
+	}
+
+	return fmt.Errorf("webhook to %s failed: %w", action.Target, lastErr)
+}
+
+// buildWebhookBody renders the request body and its content type
+func buildWebhookBody(action types.Action, filePath, traceID string) ([]byte, string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	payload := WebhookPayload{
+		Path:     filePath,
+		Name:     filepath.Base(filePath),
+		Dir:      filepath.Dir(filePath),
+		Ext:      strings.TrimPrefix(filepath.Ext(filePath), "."),
+		Size:     info.Size(),
+		Modified: info.ModTime(),
+		Trace:    traceID,
+	}
+
+	contentType := action.Options["content_type"]
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	tmplText, ok := action.Options["payload"]
+	if !ok {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode webhook payload: %w", err)
+		}
+		return body, contentType, nil
+	}
+
+	tmpl, err := template.New("payload").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(tmplText)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid webhook payload template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return nil, "", fmt.Errorf("failed to render webhook payload: %w", err)
+	}
+	return buf.Bytes(), contentType, nil
+}
+
+// TriggerWebhook runs a webhook-triggered workflow on a file on behalf of an
+// external system. The workflow must be enabled and have a webhook trigger
+// inside its time windows; its conditions still apply.
+func (m *Manager) TriggerWebhook(workflowID, filePath string) (*types.WorkflowResult, error) {
+	for _, workflow := range m.workflows {
+		if workflow.ID != workflowID {
+			continue
+		}
+		webhook, allowed := false, false
+		for _, trigger := range workflow.AllTriggers() {
+			if trigger.Type == types.WebhookTrigger {
+				webhook = true
+				allowed = allowed || TriggerAllowedAt(trigger, time.Now())
+			}
+		}
+		if !webhook {
+			return nil, fmt.Errorf("workflow %s is not webhook-triggered", workflowID)
+		}
+		if !workflow.Enabled {
+			return nil, fmt.Errorf("workflow %s is disabled", workflowID)
+		}
+		if !m.profiles.Active(workflow.Profile) {
+			return nil, fmt.Errorf("workflow %s belongs to profile %s, which isn't active", workflowID, workflow.Profile)
+		}
+		if !allowed {
+			return nil, fmt.Errorf("workflow %s is outside its time windows", workflowID)
+		}
+		return m.ExecuteWorkflow(workflowID, filePath)
+	}
+
+	return nil, fmt.Errorf("workflow with ID %s not found", workflowID)
+}
@@ -1,9 +1,23 @@
 package workflow
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
+
+	"sortd/internal/config"
+	"sortd/internal/lastopened"
+	"sortd/internal/trace"
 	"sortd/pkg/types"
 )
 
@@ -57,6 +71,18 @@ func TestValidateWorkflow(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "Unknown failure policy",
+			workflow: types.Workflow{
+				ID:        "test-workflow",
+				Name:      "Test Workflow",
+				OnFailure: "retry",
+				Actions: []types.Action{
+					{Type: types.MoveAction, Target: "/tmp"},
+				},
+			},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -132,6 +158,33 @@ func TestEvaluateFileSizeCondition(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "Human size in the value",
+			condition: types.Condition{
+				Type:     types.FileSizeCondition,
+				Operator: types.GreaterThan,
+				Value:    "0.5 KiB",
+			},
+			want: true,
+		},
+		{
+			name: "Decimal comma",
+			condition: types.Condition{
+				Type:     types.FileSizeCondition,
+				Operator: types.LessThan,
+				Value:    "1,5KB",
+			},
+			want: true,
+		},
+		{
+			name: "Unreadable size never matches",
+			condition: types.Condition{
+				Type:     types.FileSizeCondition,
+				Operator: types.LessThan,
+				Value:    "lots",
+			},
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -144,6 +197,363 @@ func TestEvaluateFileSizeCondition(t *testing.T) {
 	}
 }
 
+func TestValidateSchedule(t *testing.T) {
+	for _, schedule := range []string{"0 * * * *", "*/15 9-17 * * mon-fri", "30 2 1,15 jan-jun,dec 0", "0 0 * * 7", "@daily", " @Hourly "} {
+		if err := ValidateSchedule(schedule); err != nil {
+			t.Errorf("ValidateSchedule(%q) = %v", schedule, err)
+		}
+	}
+	for _, schedule := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * * funday", "@fortnightly", "a b c d e"} {
+		if err := ValidateSchedule(schedule); err == nil {
+			t.Errorf("ValidateSchedule(%q) accepted an invalid schedule", schedule)
+		}
+	}
+}
+
+func TestScheduleMatches(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatalf("Bad time %q: %v", value, err)
+		}
+		return parsed
+	}
+	// 2024-03-04 is a Monday
+	tests := []struct {
+		schedule, at string
+		want         bool
+	}{
+		{"@daily", "2024-03-04 00:00", true},
+		{"@daily", "2024-03-04 00:01", false},
+		{"*/15 9-17 * * mon-fri", "2024-03-04 09:45", true},
+		{"*/15 9-17 * * mon-fri", "2024-03-04 09:50", false},
+		{"*/15 9-17 * * mon-fri", "2024-03-03 09:45", false},
+		{"5/20 * * * *", "2024-03-04 10:45", true},
+		{"0 0 * * 7", "2024-03-03 00:00", true},
+		{"0 0 1 * mon", "2024-03-04 00:00", true}, // Either day field matching is enough
+		{"0 0 1 * mon", "2024-03-05 00:00", false},
+		{"0 0 1 * *", "2024-03-01 00:00", true},
+		{"0 0 * mar *", "2024-04-01 00:00", false},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.schedule)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) error: %v", tt.schedule, err)
+		}
+		if got := schedule.Matches(at(tt.at)); got != tt.want {
+			t.Errorf("%q matches %s = %v, want %v", tt.schedule, tt.at, got, tt.want)
+		}
+	}
+}
+
+// TestMultipleTriggers tests a workflow with a live trigger and a scheduled sweep
+func TestMultipleTriggers(t *testing.T) {
+	tempDir := t.TempDir()
+	workflowDir := filepath.Join(tempDir, "workflows")
+	watchDir := filepath.Join(tempDir, "inbox")
+	targetDir := filepath.Join(tempDir, "target")
+	if err := os.MkdirAll(watchDir, 0755); err != nil {
+		t.Fatalf("Failed to create watch dir: %v", err)
+	}
+
+	manager, err := NewManager(workflowDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if manager.HasScheduledTriggers() {
+		t.Errorf("HasScheduledTriggers() without workflows = true")
+	}
+	wf := types.Workflow{
+		ID:      "pdfs",
+		Name:    "PDFs",
+		Enabled: true,
+		Trigger: types.Trigger{Type: types.FileCreated, Pattern: "*.pdf"},
+		Triggers: []types.Trigger{
+			{Type: types.FileModified, Pattern: "*.pdf"},
+			{Type: types.ScheduledTrigger, Schedule: "0 3 * * *", Pattern: "*.pdf"},
+		},
+		Actions: []types.Action{{Type: types.MoveAction, Target: targetDir, Options: map[string]string{"createTargetDir": "true"}}},
+	}
+	if err := manager.AddWorkflow(wf); err != nil {
+		t.Fatalf("Failed to add workflow: %v", err)
+	}
+
+	// The extra triggers survive saving and loading
+	reloaded, err := NewManager(workflowDir)
+	if err != nil {
+		t.Fatalf("Failed to reload workflows: %v", err)
+	}
+	if got := reloaded.GetWorkflows(); len(got) != 1 || len(got[0].AllTriggers()) != 3 {
+		t.Fatalf("Reloaded workflows = %+v, want one with 3 triggers", got)
+	}
+	if !reloaded.HasScheduledTriggers() {
+		t.Errorf("HasScheduledTriggers() = false")
+	}
+
+	write := func(name string) string {
+		path := filepath.Join(watchDir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	// Any one trigger fires for a live event
+	modified := write("modified.pdf")
+	processed, err := reloaded.ProcessEvent(fsnotify.Event{Name: modified, Op: fsnotify.Write})
+	if err != nil || !processed {
+		t.Fatalf("ProcessEvent() on a modified PDF = %v, %v", processed, err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "modified.pdf")); err != nil {
+		t.Errorf("Modified PDF was not moved: %v", err)
+	}
+
+	// The schedule sweeps files the live triggers missed, only when due
+	missed := write("missed.pdf")
+	write("notes.txt")
+	write(".hidden.pdf")
+	if results := reloaded.RunScheduled(time.Date(2024, 3, 4, 4, 0, 0, 0, time.Local), []string{watchDir}); len(results) != 0 {
+		t.Errorf("RunScheduled() off schedule ran %d times", len(results))
+	}
+	results := reloaded.RunScheduled(time.Date(2024, 3, 4, 3, 0, 0, 0, time.Local), []string{watchDir})
+	if len(results) != 1 || results[0].FilePath != missed || !results[0].Success {
+		t.Fatalf("RunScheduled() = %+v, want one successful run on %s", results, missed)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "missed.pdf")); err != nil {
+		t.Errorf("Missed PDF was not swept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(watchDir, "notes.txt")); err != nil {
+		t.Errorf("Sweep touched a file its pattern doesn't match: %v", err)
+	}
+}
+
+// TestDirectoryCountCondition tests a scheduled cleanup that runs once a
+// directory holds too many items
+func TestDirectoryCountCondition(t *testing.T) {
+	tempDir := t.TempDir()
+	workflowDir := filepath.Join(tempDir, "workflows")
+	downloads := filepath.Join(tempDir, "downloads")
+	targetDir := filepath.Join(tempDir, "target")
+	if err := os.MkdirAll(filepath.Join(downloads, "Sorted"), 0755); err != nil {
+		t.Fatalf("Failed to create downloads: %v", err)
+	}
+
+	manager, err := NewManager(workflowDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.AddWorkflow(types.Workflow{
+		ID:         "clutter",
+		Name:       "Clutter",
+		Enabled:    true,
+		Trigger:    types.Trigger{Type: types.ScheduledTrigger, Schedule: "@hourly"},
+		Conditions: []types.Condition{{Type: types.DirectoryCountCondition, Operator: types.GreaterThan, Value: "3"}},
+		Actions:    []types.Action{{Type: types.MoveAction, Target: targetDir, Options: map[string]string{"createTargetDir": "true"}}},
+	}); err != nil {
+		t.Fatalf("Failed to add workflow: %v", err)
+	}
+	if err := ValidateCondition(types.Condition{Type: types.DirectoryCountCondition, Value: "lots"}); err == nil {
+		t.Errorf("ValidateCondition() accepted a count of %q", "lots")
+	}
+
+	write := func(name string) {
+		if err := os.WriteFile(filepath.Join(downloads, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	write("a.pdf")
+	write("b.zip")
+	write(".hidden")
+
+	// The folder and two files are three items: not cluttered yet
+	now := time.Date(2024, 3, 4, 3, 0, 0, 0, time.Local)
+	if results := manager.RunScheduled(now, []string{downloads}); len(results) != 0 {
+		t.Fatalf("RunScheduled() at 3 items ran %d times", len(results))
+	}
+
+	// Over the limit, every file is swept, even once the count has dropped
+	write("c.txt")
+	if results := manager.RunScheduled(now, []string{downloads}); len(results) != 3 {
+		t.Fatalf("RunScheduled() at 4 items = %+v, want 3 runs", results)
+	}
+	for _, name := range []string{"a.pdf", "b.zip", "c.txt"} {
+		if _, err := os.Stat(filepath.Join(targetDir, name)); err != nil {
+			t.Errorf("%s was not swept: %v", name, err)
+		}
+	}
+}
+
+// TestRunManual tests running a workflow by hand on files and folders
+func TestRunManual(t *testing.T) {
+	tempDir := t.TempDir()
+	inbox := filepath.Join(tempDir, "inbox")
+	targetDir := filepath.Join(tempDir, "target")
+	if err := os.MkdirAll(filepath.Join(inbox, "nested"), 0755); err != nil {
+		t.Fatalf("Failed to create inbox: %v", err)
+	}
+	for _, name := range []string{"a.pdf", "b.pdf", "notes.txt", "nested/c.pdf"} {
+		if err := os.WriteFile(filepath.Join(inbox, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	manager, err := NewManager(filepath.Join(tempDir, "workflows"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetDryRun(true)
+	err = manager.AddWorkflow(types.Workflow{
+		ID:         "pdfs",
+		Name:       "PDFs",
+		Enabled:    true,
+		Trigger:    types.Trigger{Type: types.ManualTrigger},
+		Conditions: []types.Condition{{Type: types.FileTypeCondition, Operator: types.Equals, Value: "pdf"}},
+		Actions:    []types.Action{{Type: types.MoveAction, Target: targetDir}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add workflow: %v", err)
+	}
+
+	var progressed []int
+	run, err := manager.RunManual("pdfs", []string{inbox}, func(done, total int, path string) {
+		if total != 3 {
+			t.Errorf("Progress total = %d, want 3", total)
+		}
+		progressed = append(progressed, done)
+	})
+	if err != nil {
+		t.Fatalf("RunManual() error: %v", err)
+	}
+	if len(run.Results) != 2 || run.Failed() != 0 {
+		t.Errorf("RunManual() results = %+v, want 2 successful runs", run.Results)
+	}
+	if len(run.Skipped) != 1 || run.Skipped[0] != filepath.Join(inbox, "notes.txt") {
+		t.Errorf("RunManual() skipped = %v, want notes.txt", run.Skipped)
+	}
+	if len(progressed) != 3 || progressed[2] != 3 {
+		t.Errorf("Progress reported %v, want 1 to 3", progressed)
+	}
+
+	if _, err := manager.RunManual("pdfs", []string{filepath.Join(inbox, "missing.pdf")}, nil); err == nil {
+		t.Errorf("RunManual() on a missing file should fail")
+	}
+	if _, err := manager.RunManual("nope", []string{inbox}, nil); err == nil {
+		t.Errorf("RunManual() of an unknown workflow should fail")
+	}
+}
+
+func TestEvaluateFileAgeCondition(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "old.txt")
+	if err := os.WriteFile(file, []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	modTime := time.Now().Add(-10 * 24 * time.Hour)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatalf("Failed to age file: %v", err)
+	}
+	fileInfo, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("Failed to get file info: %v", err)
+	}
+
+	manager := &Manager{}
+	tests := []struct {
+		value, unit string
+		operator    types.OperatorType
+		want        bool
+	}{
+		{"7", "days", types.GreaterThan, true},
+		{"3600", "", types.GreaterThan, true}, // A bare number is seconds
+		{"1 week", "", types.GreaterThan, true},
+		{"2 weeks ago", "", types.GreaterThan, false},
+		{"yesterday", "", types.GreaterThan, true},
+		{"2.5", "weeks", types.LessThan, true},
+		{"fortnight", "", types.GreaterThan, false},
+	}
+	for _, tt := range tests {
+		condition := types.Condition{Type: types.FileAgeCondition, Operator: tt.operator, Value: tt.value, ValueUnit: tt.unit}
+		if got := manager.evaluateFileAgeCondition(condition, fileInfo); got != tt.want {
+			t.Errorf("%s %q %q = %v, want %v", tt.operator, tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateLastOpenedCondition(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir()) // No recently used files
+	file := filepath.Join(t.TempDir(), "unused.txt")
+	if err := os.WriteFile(file, []byte("unused"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	old := time.Now().Add(-100 * 24 * time.Hour)
+	if err := os.Chtimes(file, old, old); err != nil {
+		t.Fatalf("Failed to age file: %v", err)
+	}
+	fileInfo, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("Failed to get file info: %v", err)
+	}
+
+	manager := &Manager{}
+	notOpened := types.Condition{Type: types.LastOpenedCondition, Operator: types.GreaterThan, Value: "90 days"}
+	if !manager.evaluateLastOpenedCondition(notOpened, file, fileInfo) {
+		t.Errorf("A file untouched for 100 days should count as not opened in 90 days")
+	}
+
+	// Opening it moves the access time, though not the modification time
+	if err := os.Chtimes(file, time.Now(), old); err != nil {
+		t.Fatalf("Failed to touch file: %v", err)
+	}
+	if fileInfo, err = os.Stat(file); err != nil {
+		t.Fatalf("Failed to get file info: %v", err)
+	}
+	if _, source := lastopened.LastOpened(file, fileInfo); source == lastopened.SourceModified {
+		t.Skip("The file system doesn't keep access times")
+	}
+	if manager.evaluateLastOpenedCondition(notOpened, file, fileInfo) {
+		t.Errorf("A file opened just now shouldn't count as not opened in 90 days")
+	}
+}
+
+func TestValidateCondition(t *testing.T) {
+	valid := []types.Condition{
+		{Type: types.FileSizeCondition, Value: "10MB"},
+		{Type: types.FileSizeCondition, Value: "10", ValueUnit: "MB"},
+		{Type: types.FileAgeCondition, Value: "3 weeks"},
+		{Type: types.FileAgeCondition, Value: "86400"},
+		{Type: types.FileNameCondition, Value: "anything"},
+	}
+	for _, condition := range valid {
+		if err := ValidateCondition(condition); err != nil {
+			t.Errorf("ValidateCondition(%+v) = %v", condition, err)
+		}
+	}
+
+	invalid := []types.Condition{
+		{Type: types.FileSizeCondition, Value: "10", ValueUnit: "parsecs"},
+		{Type: types.FileSizeCondition, Value: "1,500MB"},
+		{Type: types.FileAgeCondition, Value: "30x"},
+		{Type: types.FileAgeCondition, Value: "tomorrow"},
+	}
+	for _, condition := range invalid {
+		if err := ValidateCondition(condition); err == nil {
+			t.Errorf("ValidateCondition(%+v) accepted an unreadable value", condition)
+		}
+	}
+
+	manager, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	err = manager.AddWorkflow(types.Workflow{
+		ID: "big", Name: "Big", Trigger: types.Trigger{Type: types.FileCreated},
+		Conditions: []types.Condition{{Type: types.FileSizeCondition, Operator: types.GreaterThan, Value: "ten MB"}},
+		Actions:    []types.Action{{Type: types.MoveAction, Target: "/tmp"}},
+	})
+	if err == nil {
+		t.Error("AddWorkflow accepted a size condition it can't read")
+	}
+}
+
 func TestEvaluateFileNameCondition(t *testing.T) {
 	manager := &Manager{}
 	testFilePath := "/path/to/test-file.txt"
@@ -215,7 +625,780 @@ func TestEvaluateFileNameCondition(t *testing.T) {
 	}
 }
 
+func TestEvaluateFileTagCondition(t *testing.T) {
+	manager := &Manager{}
+	manager.SetTagger(func(path string) ([]string, error) {
+		return []string{"image", "Receipt"}, nil
+	})
+
+	tests := []struct {
+		name     string
+		operator types.OperatorType
+		value    string
+		want     bool
+	}{
+		{"Equals", types.Equals, "receipt", true},
+		{"Contains", types.Contains, "image", true},
+		{"Missing", types.Equals, "screenshot", false},
+		{"NotEquals", types.NotEquals, "screenshot", true},
+		{"NotEqualsPresent", types.NotEquals, "receipt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := types.Condition{Type: types.FileTagCondition, Operator: tt.operator, Value: tt.value}
+			if got := manager.evaluateFileTagCondition(condition, "/path/to/scan.jpg"); got != tt.want {
+				t.Errorf("evaluateFileTagCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	untagged := &Manager{}
+	condition := types.Condition{Type: types.FileTagCondition, Operator: types.Equals, Value: "receipt"}
+	if untagged.evaluateFileTagCondition(condition, "/path/to/scan.jpg") {
+		t.Error("Without a tagger no file should have tags")
+	}
+}
+
 // TestDryRunExecution tests workflow execution in dry run mode
 func TestDryRunExecution(t *testing.T) {
 	// This will be implemented once we add dry run capability
 }
+
+// TestWorkflowVariablesAndFragments tests ${var} substitution and shared condition blocks
+func TestWorkflowVariablesAndFragments(t *testing.T) {
+	tempDir := t.TempDir()
+	workflowDir := filepath.Join(tempDir, "workflows")
+	archiveRoot := filepath.Join(tempDir, "Archive")
+	if err := os.MkdirAll(workflowDir, 0755); err != nil {
+		t.Fatalf("Failed to create workflow dir: %v", err)
+	}
+
+	shared := `
+vars:
+  archive_root: ` + archiveRoot + `
+conditions:
+  pdfs:
+    - type: file_name
+      field: name
+      operator: ends_with
+      value: .pdf
+`
+	// Anchors work within a single workflow file
+	invoices := `
+x-target: &target "${archive_root}/${kind}"
+id: invoices
+name: Invoices
+enabled: true
+vars:
+  kind: invoices
+trigger:
+  type: manual
+use_conditions: [pdfs]
+actions:
+  - type: move
+    target: *target
+    options:
+      createTargetDir: "true"
+`
+	if err := os.WriteFile(filepath.Join(workflowDir, "_shared.yaml"), []byte(shared), 0644); err != nil {
+		t.Fatalf("Failed to write fragment: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workflowDir, "invoices.yaml"), []byte(invoices), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	manager, err := NewManager(workflowDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	workflows := manager.GetWorkflows()
+	if len(workflows) != 1 {
+		t.Fatalf("Expected 1 workflow (fragments excluded), got %d", len(workflows))
+	}
+	if workflows[0].Actions[0].Target != "${archive_root}/${kind}" {
+		t.Errorf("Stored workflow should keep its references, got %q", workflows[0].Actions[0].Target)
+	}
+
+	// The shared condition block rejects non-PDF files
+	textFile := filepath.Join(tempDir, "notes.txt")
+	if err := os.WriteFile(textFile, []byte("notes"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if _, err := manager.ExecuteWorkflow("invoices", textFile); err == nil {
+		t.Errorf("Expected condition block to reject %s", textFile)
+	}
+
+	pdfFile := filepath.Join(tempDir, "march.pdf")
+	if err := os.WriteFile(pdfFile, []byte("pdf"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	result, err := manager.ExecuteWorkflow("invoices", pdfFile)
+	if err != nil || !result.Success {
+		t.Fatalf("ExecuteWorkflow() failed: %v %+v", err, result)
+	}
+	if _, err := os.Stat(filepath.Join(archiveRoot, "invoices", "march.pdf")); err != nil {
+		t.Errorf("Expected file to be moved to the resolved target: %v", err)
+	}
+}
+
+// TestUndefinedWorkflowVariable tests that unresolved references are rejected at load time
+func TestUndefinedWorkflowVariable(t *testing.T) {
+	workflowDir := t.TempDir()
+	workflow := `
+id: broken
+name: Broken
+enabled: true
+trigger:
+  type: manual
+actions:
+  - type: move
+    target: "${nowhere}/files"
+`
+	if err := os.WriteFile(filepath.Join(workflowDir, "broken.yaml"), []byte(workflow), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	if _, err := NewManager(workflowDir); err == nil {
+		t.Errorf("Expected undefined variable to be rejected")
+	}
+}
+
+// TestTriggerAllowedAt tests time-window restrictions on triggers
+func TestTriggerAllowedAt(t *testing.T) {
+	// 2024-01-05 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.Local)
+	}
+
+	overnight := types.Trigger{
+		Type:    types.FileCreated,
+		Windows: []types.TimeWindow{{Days: []string{"fri"}, Start: "22:00", End: "06:00"}},
+	}
+	weekdayMornings := types.Trigger{
+		Type:    types.FileCreated,
+		Windows: []types.TimeWindow{{Days: []string{"Mon", "tuesday", "wed", "thu", "fri"}, Start: "08:00", End: "09:30"}},
+	}
+
+	tests := []struct {
+		name    string
+		trigger types.Trigger
+		now     time.Time
+		want    bool
+	}{
+		{"No windows", types.Trigger{Type: types.FileCreated}, at(5, 12, 0), true},
+		{"Overnight evening part", overnight, at(5, 23, 0), true},
+		{"Overnight morning part belongs to previous day", overnight, at(6, 5, 59), true},
+		{"Overnight window ends", overnight, at(6, 6, 0), false},
+		{"Overnight wrong day", overnight, at(4, 23, 0), false},
+		{"Daytime inside", weekdayMornings, at(5, 9, 15), true},
+		{"Daytime outside", weekdayMornings, at(5, 9, 30), false},
+		{"Daytime weekend", weekdayMornings, at(6, 8, 30), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TriggerAllowedAt(tt.trigger, tt.now); got != tt.want {
+				t.Errorf("TriggerAllowedAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	invalid := types.Workflow{
+		ID:      "bad-window",
+		Name:    "Bad Window",
+		Trigger: types.Trigger{Type: types.FileCreated, Windows: []types.TimeWindow{{Days: []string{"someday"}}}},
+		Actions: []types.Action{{Type: types.MoveAction, Target: "/tmp"}},
+	}
+	if err := validateWorkflow(&invalid); err == nil {
+		t.Errorf("Expected unknown day to be rejected")
+	}
+}
+
+// TestShadowMode tests that shadow workflows record what they would do without acting
+func TestShadowMode(t *testing.T) {
+	tempDir := t.TempDir()
+	workflowDir := filepath.Join(tempDir, "workflows")
+	targetDir := filepath.Join(tempDir, "target")
+
+	manager, err := NewManager(workflowDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	shadow := types.Workflow{
+		ID:      "trial",
+		Name:    "Trial",
+		Enabled: true,
+		Mode:    types.ShadowMode,
+		Trigger: types.Trigger{Type: types.FileCreated},
+		Actions: []types.Action{{Type: types.MoveAction, Target: targetDir}},
+	}
+	if err := manager.AddWorkflow(shadow); err != nil {
+		t.Fatalf("Failed to add workflow: %v", err)
+	}
+
+	testFile := filepath.Join(tempDir, "report.txt")
+	if err := os.WriteFile(testFile, []byte("report"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	processed, err := manager.ProcessEvent(fsnotify.Event{Name: testFile, Op: fsnotify.Create})
+	if err != nil {
+		t.Fatalf("ProcessEvent() error: %v", err)
+	}
+	if processed {
+		t.Errorf("Shadow workflow should not claim the event")
+	}
+	if _, err := os.Stat(testFile); err != nil {
+		t.Errorf("Shadow workflow should not move the file: %v", err)
+	}
+
+	history, err := manager.History()
+	if err != nil {
+		t.Fatalf("History() error: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 history record, got %d", len(history))
+	}
+	record := history[0]
+	if record.Mode != types.ShadowMode || record.WorkflowID != "trial" || record.FilePath != testFile {
+		t.Errorf("Unexpected history record: %+v", record)
+	}
+	if len(record.Actions) != 1 || record.Actions[0] != "move "+testFile+" to "+filepath.Join(targetDir, "report.txt") {
+		t.Errorf("Unexpected recorded actions: %v", record.Actions)
+	}
+}
+
+// TestMetadataConditionsAndPlaceholders tests metadata conditions and {key} targets
+func TestMetadataConditionsAndPlaceholders(t *testing.T) {
+	tempDir := t.TempDir()
+	manager, err := NewManager(filepath.Join(tempDir, "workflows"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	calls := 0
+	manager.SetMetadata(func(path string) (map[string]string, error) {
+		calls++
+		if filepath.Ext(path) != ".mp4" {
+			return map[string]string{}, nil
+		}
+		return map[string]string{"resolution": "1080p", "duration": "754", "duration_bucket": "medium"}, nil
+	})
+
+	videosDir := filepath.Join(tempDir, "videos")
+	workflow := types.Workflow{
+		ID:      "videos",
+		Name:    "Videos",
+		Enabled: true,
+		Trigger: types.Trigger{Type: types.FileCreated},
+		Conditions: []types.Condition{
+			{Type: types.MetadataCondition, Field: "duration", Operator: types.GreaterThan, Value: "600"},
+			{Type: types.MetadataCondition, Field: "resolution", Operator: types.Equals, Value: "1080P"},
+		},
+		Actions: []types.Action{{
+			Type:    types.MoveAction,
+			Target:  filepath.Join(videosDir, "{resolution}", "{duration_bucket}"),
+			Options: map[string]string{"createTargetDir": "true"},
+		}},
+	}
+	if err := manager.AddWorkflow(workflow); err != nil {
+		t.Fatalf("Failed to add workflow: %v", err)
+	}
+
+	video := filepath.Join(tempDir, "talk.mp4")
+	other := filepath.Join(tempDir, "notes.txt")
+	for _, path := range []string{video, other} {
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+	}
+
+	if processed, err := manager.ProcessEvent(fsnotify.Event{Name: other, Op: fsnotify.Create}); err != nil || processed {
+		t.Errorf("A file without metadata should not match: processed=%v err=%v", processed, err)
+	}
+
+	processed, err := manager.ProcessEvent(fsnotify.Event{Name: video, Op: fsnotify.Create})
+	if err != nil || !processed {
+		t.Fatalf("Expected the video to be processed: processed=%v err=%v", processed, err)
+	}
+	if _, err := os.Stat(filepath.Join(videosDir, "1080p", "medium", "talk.mp4")); err != nil {
+		t.Errorf("Expected the video under its resolution and duration bucket: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected metadata to be read once per file, got %d reads", calls)
+	}
+
+	if _, err := manager.expandPlaceholders("{missing}", other); err == nil {
+		t.Errorf("Expected an error for a placeholder the file has no value for")
+	}
+}
+
+// TestActionTemplates tests the keys every file has, ~ in targets and
+// placeholders in options
+func TestActionTemplates(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	manager, err := NewManager(filepath.Join(tempDir, "workflows"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetMetadata(func(path string) (map[string]string, error) {
+		return map[string]string{"classification": "invoice"}, nil
+	})
+
+	file := filepath.Join(tempDir, "Scan 7.PDF")
+	if err := os.WriteFile(file, []byte("scan"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	modTime := time.Date(2023, 11, 5, 12, 0, 0, 0, time.Local)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatalf("Failed to date test file: %v", err)
+	}
+
+	action, err := manager.expandActionTemplates(types.Action{
+		Type:    types.MoveAction,
+		Target:  "~/Archive/{year}/{month}/{classification}",
+		Options: map[string]string{"createTargetDir": "true", "note": "{name}.{ext} of {date}"},
+	}, file)
+	if err != nil {
+		t.Fatalf("expandActionTemplates() error: %v", err)
+	}
+	if want := filepath.Join(tempDir, "Archive", "2023", "11", "invoice"); action.Target != want {
+		t.Errorf("Target = %q, want %q", action.Target, want)
+	}
+	if action.Options["note"] != "Scan 7.pdf of 2023-11-05" || action.Options["createTargetDir"] != "true" {
+		t.Errorf("Options = %v", action.Options)
+	}
+
+	// Execute commands keep their braces, which belong to the shell
+	command := types.Action{Type: types.ExecuteAction, Target: "awk '{print}' {filename}"}
+	if action, err := manager.expandActionTemplates(command, file); err != nil || action.Target != command.Target {
+		t.Errorf("expandActionTemplates() changed an execute action: %q, %v", action.Target, err)
+	}
+	if _, err := manager.expandActionTemplates(types.Action{Type: types.CopyAction, Target: "{artist}"}, file); err == nil {
+		t.Errorf("Expected an error for a key the file has no value for")
+	}
+}
+
+// TestWebhookAction tests templated payloads and retries on server errors
+func TestWebhookAction(t *testing.T) {
+	webhookRetryDelay = time.Millisecond
+
+	var attempts int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "scan.pdf")
+	if err := os.WriteFile(testFile, []byte("pdf"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	manager := &Manager{}
+	action := types.Action{
+		Type:   types.WebhookAction,
+		Target: server.URL,
+		Options: map[string]string{
+			"payload": `{"document": {{json .Path}}, "ext": "{{.Ext}}", "size": {{.Size}}}`,
+		},
+	}
+	if err := manager.executeAction(action, testFile); err != nil {
+		t.Fatalf("executeAction() error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	want := `{"document": "` + testFile + `", "ext": "pdf", "size": 3}`
+	if body != want {
+		t.Errorf("Payload = %s, want %s", body, want)
+	}
+
+	// Client errors are not retried
+	attempts = 0
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	action = types.Action{Type: types.WebhookAction, Target: rejecting.URL}
+	if err := manager.executeAction(action, testFile); err == nil {
+		t.Errorf("Expected error for rejected webhook")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt for a client error, got %d", attempts)
+	}
+}
+
+// TestWorkflowTrace tests that a run's trace ID reaches its result and webhooks
+func TestWorkflowTrace(t *testing.T) {
+	var header string
+	var payload WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(TraceHeader)
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	testFile := filepath.Join(t.TempDir(), "scan.pdf")
+	if err := os.WriteFile(testFile, []byte("pdf"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	manager := &Manager{}
+	workflow := types.Workflow{ID: "notify", Actions: []types.Action{{Type: types.WebhookAction, Target: server.URL}}}
+	result := manager.runWorkflow(trace.With(context.Background(), "abc123"), workflow, testFile)
+	if !result.Success {
+		t.Fatalf("Workflow failed: %v", result.Error)
+	}
+	if result.Trace != "abc123" || header != "abc123" || payload.Trace != "abc123" {
+		t.Errorf("Trace not passed on: result %q, header %q, payload %q", result.Trace, header, payload.Trace)
+	}
+
+	// Runs not started for a traced file get an ID of their own
+	first := manager.executeWorkflow(workflow, testFile)
+	second := manager.executeWorkflow(workflow, testFile)
+	if first.Trace == "" || first.Trace == second.Trace {
+		t.Errorf("Expected distinct trace IDs, got %q and %q", first.Trace, second.Trace)
+	}
+	if header != second.Trace {
+		t.Errorf("Webhook header %q, want %q", header, second.Trace)
+	}
+}
+
+// TestMoveActionDuplicates tests content comparison and versioned names for taken targets
+func TestMoveActionDuplicates(t *testing.T) {
+	tempDir := t.TempDir()
+	targetDir := filepath.Join(tempDir, "target")
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "report.txt"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := NewManager(filepath.Join(tempDir, "workflows"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	manager.SetDuplicates(config.DuplicateSettings{CompareContent: true, DeleteIdentical: true, Versioning: "counter"})
+	action := types.Action{Type: types.MoveAction, Target: targetDir}
+
+	// An identical file is dropped
+	identical := filepath.Join(tempDir, "report.txt")
+	if err := os.WriteFile(identical, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var dropped droppedDuplicate
+	if err := manager.executeAction(action, identical); !errors.As(err, &dropped) {
+		t.Fatalf("executeAction() error = %v, want the file reported as dropped", err)
+	}
+	if _, err := os.Stat(identical); !os.IsNotExist(err) {
+		t.Errorf("Identical source should be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "report (2).txt")); !os.IsNotExist(err) {
+		t.Errorf("Identical file should not be kept twice")
+	}
+
+	// A different one is kept under a versioned name
+	different := filepath.Join(tempDir, "report.txt")
+	if err := os.WriteFile(different, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.executeAction(action, different); err != nil {
+		t.Fatalf("executeAction() error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(targetDir, "report (2).txt"))
+	if err != nil || string(content) != "new" {
+		t.Errorf("Different file should be moved to report (2).txt, got %q, %v", content, err)
+	}
+}
+
+// TestWorkflowStopsAfterDroppedDuplicate tests that a workflow whose move deletes
+// an identical file doesn't run its later actions on the missing file
+func TestWorkflowStopsAfterDroppedDuplicate(t *testing.T) {
+	tempDir := t.TempDir()
+	targetDir := filepath.Join(tempDir, "target")
+	copyDir := filepath.Join(tempDir, "copies")
+	for _, dir := range []string{targetDir, copyDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(targetDir, "report.txt"), []byte("same"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testFile := filepath.Join(tempDir, "report.txt")
+	if err := os.WriteFile(testFile, []byte("same"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := &Manager{}
+	manager.SetDuplicates(config.DuplicateSettings{CompareContent: true, DeleteIdentical: true})
+	workflow := types.Workflow{
+		ID:      "reports",
+		Name:    "Reports",
+		Retries: 2,
+		Actions: []types.Action{
+			{Type: types.MoveAction, Target: targetDir},
+			{Type: types.CopyAction, Target: copyDir},
+		},
+	}
+	result := manager.executeWorkflow(workflow, testFile)
+	if !result.Success {
+		t.Fatalf("Expected the workflow to stop cleanly, got %v", result.Error)
+	}
+	if !strings.Contains(result.Message, "same content") {
+		t.Errorf("Expected the result to say the file was a duplicate, got %q", result.Message)
+	}
+	if _, err := os.Stat(testFile); !os.IsNotExist(err) {
+		t.Errorf("Identical source should be deleted")
+	}
+	if entries, _ := os.ReadDir(copyDir); len(entries) != 0 {
+		t.Errorf("The copy after the move should not run, got %d files", len(entries))
+	}
+}
+
+// TestRollbackOnFailure tests that completed actions are undone when a later one fails
+func TestRollbackOnFailure(t *testing.T) {
+	tempDir := t.TempDir()
+	targetDir := filepath.Join(tempDir, "target")
+	copyDir := filepath.Join(tempDir, "copies")
+	for _, dir := range []string{targetDir, copyDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	manager, err := NewManager(filepath.Join(tempDir, "workflows"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	testFile := filepath.Join(tempDir, "scan.pdf")
+	if err := os.WriteFile(testFile, []byte("pdf"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	workflow := types.Workflow{
+		ID:        "scans",
+		Name:      "Scans",
+		OnFailure: types.RollbackOnFailure,
+		Actions: []types.Action{
+			{Type: types.CopyAction, Target: copyDir},
+			{Type: types.MoveAction, Target: targetDir},
+			{Type: types.DeleteAction},
+			{Type: types.WebhookAction, Target: rejecting.URL},
+		},
+	}
+	result := manager.executeWorkflow(workflow, testFile)
+	if result.Success {
+		t.Fatalf("Expected the workflow to fail")
+	}
+
+	if _, err := os.Stat(testFile); err != nil {
+		t.Errorf("The move should have been undone: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "scan.pdf")); !os.IsNotExist(err) {
+		t.Errorf("The moved file should be gone from the target")
+	}
+	if _, err := os.Stat(filepath.Join(copyDir, "scan.pdf")); !os.IsNotExist(err) {
+		t.Errorf("The copy should have been removed")
+	}
+
+	history, err := manager.History()
+	if err != nil {
+		t.Fatalf("History() error: %v", err)
+	}
+	if len(history) != 1 || len(history[0].RolledBack) != 2 {
+		t.Errorf("Expected 2 rolled back actions in history, got %+v", history)
+	}
+
+	// Without the rollback policy, the run stops where it failed
+	workflow.OnFailure = ""
+	workflow.Actions = workflow.Actions[1:2:2]
+	workflow.Actions = append(workflow.Actions, types.Action{Type: types.WebhookAction, Target: rejecting.URL})
+	if result := manager.executeWorkflow(workflow, testFile); result.Success {
+		t.Fatalf("Expected the workflow to fail")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "scan.pdf")); err != nil {
+		t.Errorf("The move should have been kept: %v", err)
+	}
+}
+
+// TestActionRetries tests that failing actions are retried before the workflow fails
+func TestActionRetries(t *testing.T) {
+	actionRetryDelay = 0
+	defer func() { actionRetryDelay = time.Second }()
+
+	attempts := 0
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer flaky.Close()
+
+	testFile := filepath.Join(t.TempDir(), "scan.pdf")
+	if err := os.WriteFile(testFile, []byte("pdf"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	manager := &Manager{}
+	workflow := types.Workflow{
+		ID:      "scans",
+		Name:    "Scans",
+		Retries: 1,
+		Actions: []types.Action{{Type: types.WebhookAction, Target: flaky.URL}},
+	}
+	if result := manager.executeWorkflow(workflow, testFile); !result.Success {
+		t.Errorf("Expected the retry to succeed: %v", result.Error)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+// TestRetryBackoffCancelled tests that cancelling a run ends the pause between retries
+func TestRetryBackoffCancelled(t *testing.T) {
+	actionRetryDelay = time.Minute
+	defer func() { actionRetryDelay = time.Second }()
+
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	testFile := filepath.Join(t.TempDir(), "scan.pdf")
+	if err := os.WriteFile(testFile, []byte("pdf"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	action := types.Action{Type: types.WebhookAction, Target: rejecting.URL}
+	_, _, err := (&Manager{}).runActionWithRetries(ctx, action, testFile, 3)
+	if err != context.Canceled {
+		t.Errorf("Expected the run to be cancelled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= actionRetryDelay {
+		t.Errorf("Expected the backoff to end when cancelled, took %s", elapsed)
+	}
+}
+
+// TestTransientRetries tests that actions failing on transient errors are retried
+func TestTransientRetries(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "scan.pdf")
+	if err := os.WriteFile(testFile, []byte("pdf"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	// A server that went away refuses connections
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+	action := types.Action{Type: types.WebhookAction, Target: server.URL, Options: map[string]string{"retries": "0"}}
+
+	// Without retry settings the action fails straight away
+	start := time.Now()
+	if _, _, err := (&Manager{}).runActionWithRetries(context.Background(), action, testFile, 0); err == nil {
+		t.Fatalf("Expected the unreachable webhook to fail")
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("Expected no retries, took %s", elapsed)
+	}
+
+	manager := &Manager{}
+	manager.SetRetry(config.RetrySettings{Attempts: 1})
+	start = time.Now()
+	if _, _, err := manager.runActionWithRetries(context.Background(), action, testFile, 0); err == nil {
+		t.Fatalf("Expected the unreachable webhook to fail")
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected a retry after a 1s pause, took %s", elapsed)
+	}
+}
+
+// TestValidateImportedWorkflow tests checking a workflow from elsewhere against the loaded ones
+func TestValidateImportedWorkflow(t *testing.T) {
+	workflowDir := t.TempDir()
+	shared := `
+conditions:
+  pdfs:
+    - type: file_name
+      field: name
+      operator: ends_with
+      value: .pdf
+`
+	existing := `
+id: invoices
+name: Invoices
+enabled: true
+trigger:
+  type: manual
+actions:
+  - type: move
+    target: /archive
+`
+	if err := os.WriteFile(filepath.Join(workflowDir, "_shared.yaml"), []byte(shared), 0644); err != nil {
+		t.Fatalf("Failed to write fragment: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workflowDir, "invoices.yaml"), []byte(existing), 0644); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+	manager, err := NewManager(workflowDir)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	imported, err := ParseWorkflow([]byte(existing + "use_conditions: [pdfs]\n"))
+	if err != nil {
+		t.Fatalf("ParseWorkflow() failed: %v", err)
+	}
+	if err := manager.Validate(imported); err != nil {
+		t.Errorf("Workflow using a shared condition block should be valid: %v", err)
+	}
+	if id := manager.UniqueID(imported.ID); id != "invoices-2" {
+		t.Errorf("Expected a taken ID to get a number, got %q", id)
+	}
+	if id := manager.UniqueID("receipts"); id != "receipts" {
+		t.Errorf("Expected a free ID to be kept, got %q", id)
+	}
+
+	for name, broken := range map[string]string{
+		"unknown block": existing + "use_conditions: [nowhere]\n",
+		"bad pattern":   strings.Replace(existing, "type: manual", "type: file_created\n  pattern: \"[\"", 1),
+		"no actions":    "id: empty\nname: Empty\n",
+	} {
+		wf, err := ParseWorkflow([]byte(broken))
+		if err != nil {
+			t.Fatalf("%s: ParseWorkflow() failed: %v", name, err)
+		}
+		if err := manager.Validate(wf); err == nil {
+			t.Errorf("%s: expected Validate() to fail", name)
+		}
+	}
+
+	if _, err := ParseWorkflow([]byte("actions: [")); err == nil {
+		t.Error("Expected ParseWorkflow() to reject malformed YAML")
+	}
+}
@@ -1,7 +1,9 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -81,9 +83,12 @@ func TestScanModeOperations(t *testing.T) {
 		require.NotNil(t, result)
 		assert.Contains(t, result.ContentType, "text/plain")
 		assert.Contains(t, result.Tags, "document")
+		assert.False(t, result.ModTime.IsZero(), "Scan should carry the modification time")
+		assert.False(t, result.IsDir)
 	})
 
 	t.Run("directory scan", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(filepath.Join(testDataDir, "nested"), 0755))
 		results, err := engine.ScanDirectory(testDataDir)
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, len(results), 2, "Should find at least two files")
@@ -97,12 +102,44 @@ func TestScanModeOperations(t *testing.T) {
 			} else if strings.HasSuffix(res.Path, "photo.jpg") {
 				foundJpg = true
 				assert.Contains(t, res.ContentType, "image/jpeg")
+			} else if strings.HasSuffix(res.Path, "nested") {
+				assert.True(t, res.IsDir)
+				assert.Equal(t, types.DirectoryContentType, res.ContentType)
 			}
 		}
 		assert.True(t, foundTxt, "Did not find sample.txt in directory scan")
 		assert.True(t, foundJpg, "Did not find photo.jpg in directory scan")
 	})
 
+	t.Run("cancelled directory scan", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results, err := engine.ScanDirectoryContext(ctx, testDataDir)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, results)
+	})
+
+	t.Run("streaming directory scan", func(t *testing.T) {
+		var streamed []string
+		err := engine.ScanDirectoryFunc(context.Background(), testDataDir, func(info *types.FileInfo) error {
+			streamed = append(streamed, info.Name())
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Contains(t, streamed, "sample.txt")
+		assert.Contains(t, streamed, "photo.jpg")
+
+		// Returning an error from the callback stops the scan
+		stop := errors.New("stop")
+		calls := 0
+		err = engine.ScanDirectoryFunc(context.Background(), testDataDir, func(*types.FileInfo) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+
 	t.Run("invalid path handling", func(t *testing.T) {
 		_, err := engine.Scan("non_existent.file")
 		assert.Error(t, err, "Expected error for non-existent file")